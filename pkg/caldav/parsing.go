@@ -18,6 +18,7 @@ package caldav
 
 import (
 	"errors"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -64,7 +65,7 @@ func GetCaldavTodosForTasks(project *models.ProjectWithTasksAndBuckets, projectT
 			Timestamp:   t.Updated,
 			UID:         t.UID,
 			Summary:     t.Title,
-			Description: t.Description,
+			Description: addChecklistToDescription(t.Description, t.ChecklistItems),
 			Completed:   t.DoneAt,
 			// Organizer:     &t.CreatedBy, // Disabled until we figure out how this works
 			Categories:  categories,
@@ -90,6 +91,57 @@ func GetCaldavTodosForTasks(project *models.ProjectWithTasksAndBuckets, projectT
 	return ParseTodos(caldavConfig, caldavtodos)
 }
 
+// Matches a checklist item in markdown, like "- [x] Buy milk"
+var checklistItemRegex = regexp.MustCompile(`^- \[([ xX])\] (.+)$`)
+
+// addChecklistToDescription appends the checklist of a task to its description as markdown checkboxes, since
+// VTODOs don't have checklists.
+func addChecklistToDescription(description string, items []*models.TaskChecklistItem) string {
+	if len(items) == 0 {
+		return description
+	}
+
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		check := " "
+		if item.Done {
+			check = "x"
+		}
+		lines = append(lines, "- ["+check+"] "+item.Title)
+	}
+
+	checklist := strings.Join(lines, "\n")
+	if description == "" {
+		return checklist
+	}
+	return description + "\n\n" + checklist
+}
+
+// splitChecklistFromDescription takes the markdown checkboxes at the end of a description and returns them as
+// checklist items, together with the remaining description.
+func splitChecklistFromDescription(description string) (string, []*models.TaskChecklistItem) {
+	lines := strings.Split(strings.TrimRight(description, "\r\n "), "\n")
+
+	start := len(lines)
+	for start > 0 && checklistItemRegex.MatchString(strings.TrimRight(lines[start-1], "\r")) {
+		start--
+	}
+	if start == len(lines) {
+		return description, nil
+	}
+
+	items := make([]*models.TaskChecklistItem, 0, len(lines)-start)
+	for _, line := range lines[start:] {
+		match := checklistItemRegex.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		items = append(items, &models.TaskChecklistItem{
+			Title: strings.TrimSpace(match[2]),
+			Done:  match[1] != " ",
+		})
+	}
+
+	return strings.TrimRight(strings.Join(lines[:start], "\n"), "\r\n "), items
+}
+
 func ParseTaskFromVTODO(content string) (vTask *models.Task, err error) {
 	parsed, err := ics.ParseCalendar(strings.NewReader(content))
 	if err != nil {
@@ -128,6 +180,7 @@ func ParseTaskFromVTODO(content string) (vTask *models.Task, err error) {
 
 	description := strings.ReplaceAll(task["DESCRIPTION"].Value, "\\,", ",")
 	description = strings.ReplaceAll(description, "\\n", "\n")
+	description, checklistItems := splitChecklistFromDescription(description)
 
 	var labels []*models.Label
 	if val, ok := task["CATEGORIES"]; ok {
@@ -150,6 +203,8 @@ func ParseTaskFromVTODO(content string) (vTask *models.Task, err error) {
 		Updated:     caldavTimeToTimestamp(task["DTSTAMP"]),
 		StartDate:   caldavTimeToTimestamp(task["DTSTART"]),
 		DoneAt:      caldavTimeToTimestamp(task["COMPLETED"]),
+
+		ChecklistItems: checklistItems,
 	}
 
 	for _, c := range relations {
@@ -59,6 +59,39 @@ END:VCALENDAR`,
 				Updated:     time.Unix(1543626724, 0).In(config.GetTimeZone()),
 			},
 		},
+		{
+			name: "With checklist",
+			args: args{content: `BEGIN:VCALENDAR
+VERSION:2.0
+METHOD:PUBLISH
+X-PUBLISHED-TTL:PT4H
+X-WR-CALNAME:test
+PRODID:-//RandomProdID which is not random//EN
+BEGIN:VTODO
+UID:randomuid
+DTSTAMP:20181201T011204
+SUMMARY:Todo #1
+DESCRIPTION:Lorem Ipsum\n- [ ] not a checklist item\nbecause it is not at the end\n\n- [x] First\n- [ ] Second
+LAST-MODIFIED:00010101T000000
+END:VTODO
+END:VCALENDAR`,
+			},
+			wantVTask: &models.Task{
+				Title:       "Todo #1",
+				UID:         "randomuid",
+				Description: "Lorem Ipsum\n- [ ] not a checklist item\nbecause it is not at the end",
+				Updated:     time.Unix(1543626724, 0).In(config.GetTimeZone()),
+				ChecklistItems: []*models.TaskChecklistItem{
+					{
+						Title: "First",
+						Done:  true,
+					},
+					{
+						Title: "Second",
+					},
+				},
+			},
+		},
 		{
 			name: "With priority",
 			args: args{content: `BEGIN:VCALENDAR
@@ -456,6 +489,51 @@ ACTION:DISPLAY
 DESCRIPTION:Task 1
 END:VALARM
 END:VTODO
+END:VCALENDAR`,
+		},
+		{
+			name: "Format Task with checklist as CalDAV",
+			args: args{
+				list: &models.ProjectWithTasksAndBuckets{
+					Project: models.Project{
+						Title: "List title",
+					},
+				},
+				tasks: []*models.TaskWithComments{
+					{
+						Task: models.Task{
+							Title:       "Task 1",
+							UID:         "randomuid",
+							Description: "Description",
+							Created:     time.Unix(1543626721, 0).In(config.GetTimeZone()),
+							Updated:     time.Unix(1543626725, 0).In(config.GetTimeZone()),
+							ChecklistItems: []*models.TaskChecklistItem{
+								{
+									Title: "First",
+									Done:  true,
+								},
+								{
+									Title: "Second",
+								},
+							},
+						},
+					},
+				},
+			},
+			wantCaldav: `BEGIN:VCALENDAR
+VERSION:2.0
+METHOD:PUBLISH
+X-PUBLISHED-TTL:PT4H
+X-WR-CALNAME:List title
+PRODID:-//Vikunja Todo App//EN
+BEGIN:VTODO
+UID:randomuid
+DTSTAMP:20181201T011205Z
+SUMMARY:Task 1
+DESCRIPTION:Description\n\n- [x] First\n- [ ] Second
+CREATED:20181201T011201Z
+LAST-MODIFIED:20181201T011205Z
+END:VTODO
 END:VCALENDAR`,
 		},
 		{
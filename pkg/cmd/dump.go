@@ -17,7 +17,7 @@
 package cmd
 
 import (
-	"time"
+	"os"
 
 	"code.vikunja.io/api/pkg/initialize"
 	"code.vikunja.io/api/pkg/log"
@@ -25,7 +25,16 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var (
+	dumpPathFlag   string
+	dumpKeepFlag   int
+	dumpVerifyFlag bool
+)
+
 func init() {
+	dumpCmd.Flags().StringVarP(&dumpPathFlag, "path", "p", "", "The folder where the dump will be saved. Defaults to the current folder.")
+	dumpCmd.Flags().IntVarP(&dumpKeepFlag, "keep", "k", 0, "If provided, only the newest n dumps in the folder are kept, older ones are removed.")
+	dumpCmd.Flags().BoolVarP(&dumpVerifyFlag, "verify", "v", false, "If provided, the checksums of all tables and files in the dump are verified after it was created.")
 	rootCmd.AddCommand(dumpCmd)
 }
 
@@ -36,9 +45,37 @@ var dumpCmd = &cobra.Command{
 		initialize.FullInitWithoutAsync()
 	},
 	Run: func(_ *cobra.Command, _ []string) {
-		filename := "vikunja-dump_" + time.Now().Format("2006-01-02_15-03-05") + ".zip"
+		if dumpPathFlag != "" {
+			if err := os.MkdirAll(dumpPathFlag, 0700); err != nil {
+				log.Critical(err.Error())
+				return
+			}
+		}
+
+		filename := dump.NewFileName(dumpPathFlag)
 		if err := dump.Dump(filename); err != nil {
 			log.Critical(err.Error())
+			return
+		}
+
+		if dumpVerifyFlag {
+			if err := dump.Verify(filename); err != nil {
+				log.Critical(err.Error())
+				return
+			}
+			log.Info("Verified checksums of all tables and files.")
+		}
+
+		if dumpKeepFlag > 0 {
+			dir := dumpPathFlag
+			if dir == "" {
+				dir = "."
+			}
+			removed, err := dump.Rotate(dir, dumpKeepFlag)
+			if err != nil {
+				log.Critical(err.Error())
+			}
+			log.Infof("Removed %d old dumps", len(removed))
 		}
 	},
 }
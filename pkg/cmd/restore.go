@@ -17,13 +17,18 @@
 package cmd
 
 import (
+	"sort"
+
 	"code.vikunja.io/api/pkg/initialize"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/modules/dump"
 	"github.com/spf13/cobra"
 )
 
+var restoreDryRunFlag bool
+
 func init() {
+	restoreCmd.Flags().BoolVarP(&restoreDryRunFlag, "dry-run", "d", false, "If provided, only checks if the dump can be restored without changing anything.")
 	rootCmd.AddCommand(restoreCmd)
 }
 
@@ -35,8 +40,51 @@ var restoreCmd = &cobra.Command{
 		initialize.FullInitWithoutAsync()
 	},
 	Run: func(_ *cobra.Command, args []string) {
+		if restoreDryRunFlag {
+			checkRestore(args[0])
+			return
+		}
+
 		if err := dump.Restore(args[0]); err != nil {
 			log.Critical(err.Error())
 		}
 	},
 }
+
+func checkRestore(filename string) {
+	report, err := dump.CheckRestore(filename)
+	if err != nil {
+		log.Critical(err.Error())
+		return
+	}
+
+	log.Infof("Dump version: %s (this is %s)", report.DumpVersion, report.CurrentVersion)
+	if report.DumpDatabaseType != "" {
+		log.Infof("Database: %s (restoring into %s)", report.DumpDatabaseType, report.CurrentDatabaseType)
+	}
+	log.Infof("Last migration: %s (%d migrations will run after restoring)", report.LastMigration, report.PendingMigrations)
+
+	tables := make([]string, 0, len(report.Tables))
+	for table := range report.Tables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	for _, table := range tables {
+		log.Infof("Table %s: %d rows", table, report.Tables[table])
+	}
+	log.Infof("Files: %d", report.Files)
+
+	if !report.HasChecksums {
+		log.Warning("Dump does not contain checksums, unable to verify it.")
+	}
+
+	if report.CanRestore() {
+		log.Info("The dump can be restored.")
+		return
+	}
+
+	for _, problem := range report.Problems {
+		log.Error(problem)
+	}
+	log.Critical("The dump can not be restored.")
+}
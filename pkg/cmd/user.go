@@ -47,6 +47,7 @@ var (
 	userFlagDisableUser           bool
 	userFlagDeleteNow             bool
 	userFlagDeleteConfirm         bool
+	userFlagAdmin                 bool
 )
 
 func init() {
@@ -57,11 +58,13 @@ func init() {
 	_ = userCreateCmd.MarkFlagRequired("email")
 	userCreateCmd.Flags().StringVarP(&userFlagPassword, "password", "p", "", "The password of the new user. You will be asked to enter it if not provided through the flag.")
 	userCreateCmd.Flags().StringVarP(&userFlagAvatar, "avatar-provider", "a", "", "The avatar provider of the new user. Optional.")
+	userCreateCmd.Flags().BoolVar(&userFlagAdmin, "admin", false, "Make the new user an instance admin.")
 
 	// User update flags
 	userUpdateCmd.Flags().StringVarP(&userFlagUsername, "username", "u", "", "The new username of the user.")
 	userUpdateCmd.Flags().StringVarP(&userFlagEmail, "email", "e", "", "The new email address of the user.")
 	userUpdateCmd.Flags().StringVarP(&userFlagAvatar, "avatar-provider", "a", "", "The new avatar provider of the new user.")
+	userUpdateCmd.Flags().BoolVar(&userFlagAdmin, "admin", false, "Whether the user is an instance admin. Use --admin=false to revoke it.")
 
 	// Reset PW flags
 	userResetPasswordCmd.Flags().BoolVarP(&userFlagResetPasswordDirectly, "direct", "d", false, "If provided, reset the password directly instead of sending the user a reset mail.")
@@ -77,7 +80,7 @@ func init() {
 	// Bypass confirm prompt
 	userDeleteCmd.Flags().BoolVarP(&userFlagDeleteConfirm, "confirm", "c", false, "Bypasses any prompts confirming the deletion request, use with caution!")
 
-	userCmd.AddCommand(userListCmd, userCreateCmd, userUpdateCmd, userResetPasswordCmd, userChangeStatusCmd, userDeleteCmd)
+	userCmd.AddCommand(userListCmd, userCreateCmd, userUpdateCmd, userResetPasswordCmd, userResetTwoFactorCmd, userChangeStatusCmd, userDeleteCmd)
 	rootCmd.AddCommand(userCmd)
 }
 
@@ -151,6 +154,7 @@ var userListCmd = &cobra.Command{
 			"Username",
 			"Email",
 			"Status",
+			"Admin",
 			"Created",
 			"Updated",
 		})
@@ -161,6 +165,7 @@ var userListCmd = &cobra.Command{
 				u.Username,
 				u.Email,
 				u.Status.String(),
+				strconv.FormatBool(u.IsAdmin),
 				u.Created.Format(time.RFC3339),
 				u.Updated.Format(time.RFC3339),
 			})
@@ -184,6 +189,7 @@ var userCreateCmd = &cobra.Command{
 			Username: userFlagUsername,
 			Email:    userFlagEmail,
 			Password: getPasswordFromFlagOrInput(),
+			IsAdmin:  userFlagAdmin,
 		}
 
 		if !govalidator.IsEmail(userFlagEmail) {
@@ -217,7 +223,7 @@ var userUpdateCmd = &cobra.Command{
 	PreRun: func(_ *cobra.Command, _ []string) {
 		initialize.FullInit()
 	},
-	Run: func(_ *cobra.Command, args []string) {
+	Run: func(cmd *cobra.Command, args []string) {
 		s := db.NewSession()
 		defer s.Close()
 
@@ -239,6 +245,14 @@ var userUpdateCmd = &cobra.Command{
 			log.Fatalf("Error updating the user: %s", err)
 		}
 
+		if cmd.Flags().Changed("admin") {
+			err = u.SetIsAdmin(s, userFlagAdmin)
+			if err != nil {
+				_ = s.Rollback()
+				log.Fatalf("Error updating the user: %s", err)
+			}
+		}
+
 		if err := s.Commit(); err != nil {
 			log.Fatalf("Error saving everything: %s", err)
 		}
@@ -283,6 +297,34 @@ var userResetPasswordCmd = &cobra.Command{
 	},
 }
 
+var userResetTwoFactorCmd = &cobra.Command{
+	Use:   "reset-2fa [user id]",
+	Short: "Remove all second factors of a user so that they can log in with only their password again.",
+	Long:  "Removes the totp setting, all security keys and recovery codes of a user. Use this to help users who lost access to all of their second factors. All sessions of the user are logged out.",
+	PreRun: func(_ *cobra.Command, _ []string) {
+		initialize.FullInit()
+	},
+	Args: cobra.ExactArgs(1),
+	Run: func(_ *cobra.Command, args []string) {
+		s := db.NewSession()
+		defer s.Close()
+
+		u := getUserFromArg(s, args[0])
+
+		err := user.ResetTwoFactor(s, u, "Reset from the command line")
+		if err != nil {
+			_ = s.Rollback()
+			log.Fatalf("Could not reset the two factor authentication: %s", err)
+		}
+
+		if err := s.Commit(); err != nil {
+			log.Fatalf("Error saving everything: %s", err)
+		}
+
+		fmt.Println("Two factor authentication reset successfully.")
+	},
+}
+
 var userChangeStatusCmd = &cobra.Command{
 	Use:   "change-status [user id]",
 	Short: "Enable or disable a user. Will toggle the current status if no flag (--enable or --disable) is provided.",
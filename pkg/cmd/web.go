@@ -27,6 +27,7 @@ import (
 	"code.vikunja.io/api/pkg/cron"
 	"code.vikunja.io/api/pkg/initialize"
 	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/modules/dump"
 	"code.vikunja.io/api/pkg/routes"
 	"code.vikunja.io/api/pkg/utils"
 	"code.vikunja.io/api/pkg/version"
@@ -69,6 +70,8 @@ var webCmd = &cobra.Command{
 	Short: "Starts the rest api web server",
 	PreRun: func(_ *cobra.Command, _ []string) {
 		initialize.FullInit()
+		// Registered here because the dump module depends on initialize for restores
+		dump.RegisterDumpCron()
 	},
 	Run: func(_ *cobra.Command, _ []string) {
 
@@ -58,6 +58,7 @@ const (
 	ServiceTimeZone              Key = `service.timezone`
 	ServiceEnableTaskComments    Key = `service.enabletaskcomments`
 	ServiceEnableTotp            Key = `service.enabletotp`
+	ServiceEnableWebAuthn        Key = `service.enablewebauthn`
 	ServiceTestingtoken          Key = `service.testingtoken`
 	ServiceEnableEmailReminders  Key = `service.enableemailreminders`
 	ServiceEnableUserDeletion    Key = `service.enableuserdeletion`
@@ -75,6 +76,25 @@ const (
 	AuthOpenIDEnabled   Key = `auth.openid.enabled`
 	AuthOpenIDProviders Key = `auth.openid.providers`
 
+	AuthLDAPEnabled              Key = `auth.ldap.enabled`
+	AuthLDAPHost                 Key = `auth.ldap.host`
+	AuthLDAPPort                 Key = `auth.ldap.port`
+	AuthLDAPUseTLS               Key = `auth.ldap.usetls`
+	AuthLDAPVerifyTLS            Key = `auth.ldap.verifytls`
+	AuthLDAPBaseDN               Key = `auth.ldap.basedn`
+	AuthLDAPUserFilter           Key = `auth.ldap.userfilter`
+	AuthLDAPBindDN               Key = `auth.ldap.binddn`
+	AuthLDAPBindPassword         Key = `auth.ldap.bindpassword`
+	AuthLDAPAttributeUsername    Key = `auth.ldap.attribute.username`
+	AuthLDAPAttributeEmail       Key = `auth.ldap.attribute.email`
+	AuthLDAPAttributeDisplayName Key = `auth.ldap.attribute.displayname`
+	AuthLDAPGroupSyncEnabled     Key = `auth.ldap.groupsyncenabled`
+	AuthLDAPGroupFilter          Key = `auth.ldap.groupfilter`
+
+	AuthSCIMEnabled Key = `auth.scim.enabled`
+	AuthSCIMToken   Key = `auth.scim.token`
+	AuthSCIMIssuer  Key = `auth.scim.issuer`
+
 	LegalImprintURL Key = `legal.imprinturl`
 	LegalPrivacyURL Key = `legal.privacyurl`
 
@@ -148,6 +168,9 @@ const (
 	MigrationMicrosoftTodoClientID     Key = `migration.microsofttodo.clientid`
 	MigrationMicrosoftTodoClientSecret Key = `migration.microsofttodo.clientsecret`
 	MigrationMicrosoftTodoRedirectURL  Key = `migration.microsofttodo.redirecturl`
+	MigrationSyncEnable                Key = `migration.sync.enable`
+	MigrationSyncSchedule              Key = `migration.sync.schedule`
+	MigrationAllowPrivateHosts         Key = `migration.allowprivatehosts`
 
 	CorsEnable  Key = `cors.enable`
 	CorsOrigins Key = `cors.origins`
@@ -183,6 +206,16 @@ const (
 	WebhooksTimeoutSeconds Key = `webhooks.timeoutseconds`
 	WebhooksProxyURL       Key = `webhooks.proxyurl`
 	WebhooksProxyPassword  Key = `webhooks.proxypassword`
+
+	AutomationsEnabled Key = `automations.enabled`
+
+	ExportSyncLimit         Key = `export.synclimit`
+	ExportAllowPrivateHosts Key = `export.allowprivatehosts`
+
+	DumpScheduleEnabled Key = `dump.enabled`
+	DumpSchedule        Key = `dump.schedule`
+	DumpPath            Key = `dump.path`
+	DumpRetention       Key = `dump.retention`
 )
 
 // GetString returns a string config value
@@ -308,6 +341,7 @@ func InitDefaultConfig() {
 	ServiceTimeZone.setDefault("GMT")
 	ServiceEnableTaskComments.setDefault(true)
 	ServiceEnableTotp.setDefault(true)
+	ServiceEnableWebAuthn.setDefault(true)
 	ServiceEnableEmailReminders.setDefault(true)
 	ServiceEnableUserDeletion.setDefault(true)
 	ServiceMaxAvatarSize.setDefault(1024)
@@ -322,6 +356,23 @@ func InitDefaultConfig() {
 	// Auth
 	AuthLocalEnabled.setDefault(true)
 	AuthOpenIDEnabled.setDefault(false)
+	AuthLDAPEnabled.setDefault(false)
+	AuthLDAPHost.setDefault("")
+	AuthLDAPPort.setDefault(389)
+	AuthLDAPUseTLS.setDefault(false)
+	AuthLDAPVerifyTLS.setDefault(true)
+	AuthLDAPBaseDN.setDefault("")
+	AuthLDAPUserFilter.setDefault("(&(objectclass=inetOrgPerson)(uid=%s))")
+	AuthLDAPBindDN.setDefault("")
+	AuthLDAPBindPassword.setDefault("")
+	AuthLDAPAttributeUsername.setDefault("uid")
+	AuthLDAPAttributeEmail.setDefault("mail")
+	AuthLDAPAttributeDisplayName.setDefault("displayName")
+	AuthLDAPGroupSyncEnabled.setDefault(false)
+	AuthLDAPGroupFilter.setDefault("(&(objectclass=groupOfNames)(member=%s))")
+	AuthSCIMEnabled.setDefault(false)
+	AuthSCIMToken.setDefault("")
+	AuthSCIMIssuer.setDefault("")
 
 	// Database
 	DatabaseType.setDefault("sqlite")
@@ -390,6 +441,9 @@ func InitDefaultConfig() {
 	MigrationTodoistEnable.setDefault(false)
 	MigrationTrelloEnable.setDefault(false)
 	MigrationMicrosoftTodoEnable.setDefault(false)
+	MigrationSyncEnable.setDefault(false)
+	MigrationSyncSchedule.setDefault("*/15 * * * *")
+	MigrationAllowPrivateHosts.setDefault(false)
 	// Avatar
 	AvatarGravaterExpiration.setDefault(3600)
 	// Project Backgrounds
@@ -407,6 +461,16 @@ func InitDefaultConfig() {
 	// Webhook
 	WebhooksEnabled.setDefault(true)
 	WebhooksTimeoutSeconds.setDefault(30)
+	// Automations
+	AutomationsEnabled.setDefault(true)
+	// Export
+	ExportSyncLimit.setDefault(1000)
+	ExportAllowPrivateHosts.setDefault(false)
+	// Dump
+	DumpScheduleEnabled.setDefault(false)
+	DumpSchedule.setDefault("0 3 * * *")
+	DumpPath.setDefault("")
+	DumpRetention.setDefault(7)
 }
 
 // InitConfig initializes the config, sets defaults etc.
@@ -17,6 +17,8 @@
 package cron
 
 import (
+	"time"
+
 	"github.com/robfig/cron/v3"
 )
 
@@ -38,3 +40,12 @@ func Schedule(schedule string, f func()) (err error) {
 func Stop() {
 	c.Stop()
 }
+
+// NextRun returns the next time a cron schedule is due after the given time
+func NextRun(schedule string, after time.Time) (next time.Time, err error) {
+	sched, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return
+	}
+	return sched.Next(after), nil
+}
@@ -84,6 +84,10 @@ func CreateDBEngine() (engine *xorm.Engine, err error) {
 	logger := log.NewXormLogger(config.LogEnabled.GetBool(), config.LogDatabase.GetString(), config.LogDatabaseLevel.GetString())
 	engine.SetLogger(logger)
 
+	if config.MetricsEnabled.GetBool() {
+		setupMetrics(engine)
+	}
+
 	x = engine
 	return
 }
@@ -17,87 +17,241 @@
 package db
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
 	"strings"
+	"time"
 
 	"code.vikunja.io/api/pkg/log"
 
 	"xorm.io/xorm/schemas"
 )
 
-// Dump dumps all database tables
-func Dump() (data map[string][]byte, err error) {
+// dumpBatchSize is the number of rows fetched at once when dumping a table
+const dumpBatchSize = 1000
+
+// Tables returns the definitions of all tables in the database
+func Tables() ([]*schemas.Table, error) {
+	return x.DBMetas()
+}
+
+// GetTable returns the definition of a table in the database
+func GetTable(name string) (*schemas.Table, error) {
 	tables, err := x.DBMetas()
 	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tables {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+
+	return nil, fmt.Errorf("could not find table definition for table %s", name)
+}
+
+// DumpTable writes all rows of a table as json array to w. The rows are fetched in batches so that
+// large tables never have to be loaded into memory at once.
+func DumpTable(table *schemas.Table, w io.Writer) (rows int64, err error) {
+	if _, err = io.WriteString(w, "["); err != nil {
 		return
 	}
 
-	data = make(map[string][]byte, len(tables))
-	for _, table := range tables {
+	for {
 		entries := []map[string]interface{}{}
-		err := x.Table(table.Name).Find(&entries)
-		if err != nil {
-			return nil, err
+		query := x.Table(table.Name).Limit(dumpBatchSize, int(rows))
+		if len(table.PrimaryKeys) > 0 {
+			query = query.OrderBy(strings.Join(quoteColumns(table.PrimaryKeys), ", "))
 		}
-		data[table.Name], err = json.Marshal(entries)
+		err = query.Find(&entries)
 		if err != nil {
-			return nil, err
+			return rows, err
+		}
+
+		for _, entry := range entries {
+			for colName, value := range entry {
+				// Some drivers return text columns as bytes which would end up base64 encoded in the dump.
+				col := table.GetColumn(colName)
+				if b, is := value.([]byte); is && col != nil && (col.SQLType.IsText() || col.SQLType.IsJson()) {
+					entry[colName] = string(b)
+				}
+			}
+
+			if rows > 0 {
+				if _, err = io.WriteString(w, ","); err != nil {
+					return
+				}
+			}
+
+			var row []byte
+			row, err = json.Marshal(entry)
+			if err != nil {
+				return
+			}
+			if _, err = w.Write(row); err != nil {
+				return
+			}
+			rows++
+		}
+
+		if len(entries) < dumpBatchSize {
+			break
 		}
 	}
 
+	_, err = io.WriteString(w, "]")
 	return
 }
 
+func quoteColumns(cols []string) []string {
+	quoted := make([]string, 0, len(cols))
+	for _, c := range cols {
+		quoted = append(quoted, x.Dialect().Quoter().Quote(c))
+	}
+	return quoted
+}
+
 // Restore restores a table with all its entries
 func Restore(table string, contents []map[string]interface{}) (err error) {
 	if _, err := x.IsTableExist(table); err != nil {
 		return err
 	}
 
-	meta, err := x.DBMetas()
+	meta, err := GetTable(table)
 	if err != nil {
 		return err
 	}
 
-	var metaForCurrentTable *schemas.Table
-	for _, m := range meta {
-		if m.Name == table {
-			metaForCurrentTable = m
-			break
-		}
+	err = RestoreRows(meta, contents)
+	if err != nil {
+		return err
 	}
 
-	if metaForCurrentTable == nil {
-		log.Fatalf("Could not find table definition for table %s", table)
+	return ResetIDSequence(meta)
+}
+
+// RestoreRows inserts rows into a table in one transaction. The rows may come from a dump of another database type,
+// all values are converted to the types of the current database.
+func RestoreRows(table *schemas.Table, contents []map[string]interface{}) (err error) {
+	s := x.NewSession()
+	defer s.Close()
+
+	err = s.Begin()
+	if err != nil {
+		return err
 	}
 
 	for _, content := range contents {
 		for colName, value := range content {
-			// Date fields might get restored as 0001-01-01 from null dates. This can have unintended side-effects like
-			// users being scheduled for deletion after a restore.
-			// To avoid this, we set these dates to nil so that they'll end up as null in the db.
-			col := metaForCurrentTable.GetColumn(colName)
-			strVal, is := value.(string)
-			if is && col.SQLType.IsTime() && (strVal == "" || strings.HasPrefix(strVal, "0001-")) {
-				content[colName] = nil
+			col := table.GetColumn(colName)
+			if col == nil {
+				_ = s.Rollback()
+				return fmt.Errorf("column %s does not exist in table %s", colName, table.Name)
+			}
+
+			content[colName], err = convertRestoredValue(col, value)
+			if err != nil {
+				_ = s.Rollback()
+				return fmt.Errorf("could not convert value of column %s in table %s: %w", colName, table.Name, err)
 			}
 		}
 
-		if _, err := x.Table(table).Insert(content); err != nil {
+		if _, err := s.Table(table.Name).Insert(content); err != nil {
+			_ = s.Rollback()
 			return err
 		}
 	}
 
-	if Type() == schemas.POSTGRES {
-		idSequence := table + "_id_seq"
-		_, err = x.Query("SELECT setval('" + idSequence + "', COALESCE(MAX(id), 1) )")
-		if err != nil {
-			log.Warningf("Could not reset id sequence for %s: %s", idSequence, err)
-			err = nil
+	return s.Commit()
+}
+
+// Dates with a time zone are parsed so that they can be inserted into every database type.
+// Dates without a time zone are already in a format all databases understand.
+var restoredTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+}
+
+// convertRestoredValue converts a value from a dump to the type of the column it is restored into.
+// This is required because every database type encodes booleans, numbers and dates differently.
+func convertRestoredValue(col *schemas.Column, value interface{}) (interface{}, error) {
+	if n, is := value.(json.Number); is {
+		if i, err := n.Int64(); err == nil {
+			value = i
+		} else {
+			f, err := n.Float64()
+			if err != nil {
+				return nil, err
+			}
+			value = f
 		}
 	}
 
-	return
+	switch {
+	case col.SQLType.IsTime():
+		strVal, is := value.(string)
+		if !is {
+			return value, nil
+		}
+		// Date fields might get restored as 0001-01-01 from null dates. This can have unintended side-effects like
+		// users being scheduled for deletion after a restore.
+		// To avoid this, we set these dates to nil so that they'll end up as null in the db.
+		if strVal == "" || strings.HasPrefix(strVal, "0001-") {
+			return nil, nil
+		}
+		for _, layout := range restoredTimeLayouts {
+			if t, err := time.Parse(layout, strVal); err == nil {
+				return t, nil
+			}
+		}
+		return value, nil
+	case col.SQLType.IsBlob():
+		if strVal, is := value.(string); is {
+			return base64.StdEncoding.DecodeString(strVal)
+		}
+	case col.SQLType.IsBool():
+		switch v := value.(type) {
+		case int64:
+			return v != 0, nil
+		case float64:
+			return v != 0, nil
+		case string:
+			return strconv.ParseBool(v)
+		}
+	}
+
+	return value, nil
+}
+
+// ResetIDSequence sets the id sequence of a table to the highest id in it. This is only needed for Postgres,
+// other databases take care of this on their own.
+func ResetIDSequence(table *schemas.Table) (err error) {
+	if Type() != schemas.POSTGRES {
+		return nil
+	}
+
+	col := table.AutoIncrement
+	if col == "" {
+		if table.GetColumn("id") == nil {
+			return nil
+		}
+		col = "id"
+	}
+
+	_, err = x.Exec(
+		"SELECT setval(pg_get_serial_sequence(?, ?), COALESCE(MAX("+x.Dialect().Quoter().Quote(col)+"), 1), MAX("+x.Dialect().Quoter().Quote(col)+") IS NOT NULL) FROM "+x.Dialect().Quoter().Quote(table.Name),
+		table.Name,
+		col,
+	)
+	if err != nil {
+		log.Warningf("Could not reset id sequence for %s: %s", table.Name, err)
+	}
+
+	return nil
 }
 
 // RestoreAndTruncate removes all content from the table before restoring it from the contents map
@@ -0,0 +1,105 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm/schemas"
+)
+
+func TestConvertRestoredValue(t *testing.T) {
+	column := func(sqlType string) *schemas.Column {
+		return &schemas.Column{Name: "col", SQLType: schemas.SQLType{Name: sqlType}}
+	}
+
+	t.Run("bool", func(t *testing.T) {
+		col := column(schemas.Bool)
+		for name, value := range map[string]interface{}{
+			"mysql":    json.Number("1"),
+			"sqlite":   float64(1),
+			"postgres": true,
+			"string":   "true",
+		} {
+			t.Run(name, func(t *testing.T) {
+				converted, err := convertRestoredValue(col, value)
+				require.NoError(t, err)
+				assert.Equal(t, true, converted)
+			})
+		}
+
+		converted, err := convertRestoredValue(col, json.Number("0"))
+		require.NoError(t, err)
+		assert.Equal(t, false, converted)
+
+		_, err = convertRestoredValue(col, "maybe")
+		require.Error(t, err)
+	})
+	t.Run("blob", func(t *testing.T) {
+		col := column(schemas.Blob)
+
+		converted, err := convertRestoredValue(col, "aGVsbG8=")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("hello"), converted)
+
+		_, err = convertRestoredValue(col, "not base64!")
+		require.Error(t, err)
+	})
+	t.Run("time", func(t *testing.T) {
+		col := column(schemas.DateTime)
+		expected := time.Date(2018, 12, 1, 15, 13, 12, 0, time.UTC)
+
+		for name, value := range map[string]string{
+			"rfc3339":          "2018-12-01T15:13:12Z",
+			"with time zone":   "2018-12-01T16:13:12+01:00",
+			"postgres":         "2018-12-01 16:13:12+01:00",
+			"with nanoseconds": "2018-12-01T15:13:12.000000000Z",
+		} {
+			t.Run(name, func(t *testing.T) {
+				converted, err := convertRestoredValue(col, value)
+				require.NoError(t, err)
+				require.IsType(t, time.Time{}, converted)
+				assert.True(t, expected.Equal(converted.(time.Time)), "got %s", converted)
+			})
+		}
+	})
+	t.Run("time without time zone", func(t *testing.T) {
+		converted, err := convertRestoredValue(column(schemas.DateTime), "2018-12-01 15:13:12")
+		require.NoError(t, err)
+		assert.Equal(t, "2018-12-01 15:13:12", converted)
+	})
+	t.Run("null time", func(t *testing.T) {
+		for _, value := range []string{"", "0001-01-01T00:00:00Z", "0001-01-01 00:00:00"} {
+			converted, err := convertRestoredValue(column(schemas.DateTime), value)
+			require.NoError(t, err)
+			assert.Nil(t, converted)
+		}
+	})
+	t.Run("numbers", func(t *testing.T) {
+		converted, err := convertRestoredValue(column(schemas.BigInt), json.Number("42"))
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), converted)
+
+		converted, err = convertRestoredValue(column(schemas.Double), json.Number("1.5"))
+		require.NoError(t, err)
+		assert.InDelta(t, 1.5, converted, 0)
+	})
+}
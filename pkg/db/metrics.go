@@ -0,0 +1,47 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package db
+
+import (
+	"context"
+
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/metrics"
+
+	"xorm.io/xorm"
+	"xorm.io/xorm/contexts"
+)
+
+// metricsHook records the duration of all queries run through xorm.
+type metricsHook struct{}
+
+func (metricsHook) BeforeProcess(c *contexts.ContextHook) (context.Context, error) {
+	return c.Ctx, nil
+}
+
+func (metricsHook) AfterProcess(c *contexts.ContextHook) error {
+	metrics.ObserveDBQuery(c.SQL, c.ExecuteTime)
+	return nil
+}
+
+func setupMetrics(engine *xorm.Engine) {
+	engine.AddHook(metricsHook{})
+	err := metrics.RegisterDBStats(engine.DB().DB)
+	if err != nil {
+		log.Criticalf("Could not register database metrics: %s", err)
+	}
+}
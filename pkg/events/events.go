@@ -89,13 +89,23 @@ func InitEvents() (err error) {
 
 	for topic, funcs := range listeners {
 		for _, handler := range funcs {
-			router.AddNoPublisherHandler(topic+"."+handler.Name(), topic, pubsub, handler.Handle)
+			router.AddNoPublisherHandler(topic+"."+handler.Name(), topic, pubsub, observeHandler(topic, handler))
 		}
 	}
 
 	return router.Run(context.Background())
 }
 
+// observeHandler records how long a listener takes to handle each event.
+func observeHandler(topic string, listener Listener) message.NoPublishHandlerFunc {
+	return func(msg *message.Message) error {
+		start := time.Now()
+		err := listener.Handle(msg)
+		vmetrics.ObserveEventHandler(topic, listener.Name(), time.Since(start))
+		return err
+	}
+}
+
 // Dispatch dispatches an event
 func Dispatch(event Event) error {
 	if isUnderTest {
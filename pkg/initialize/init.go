@@ -28,7 +28,9 @@ import (
 	"code.vikunja.io/api/pkg/migration"
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/modules/auth/openid"
+	"code.vikunja.io/api/pkg/modules/export"
 	"code.vikunja.io/api/pkg/modules/keyvalue"
+	migrationModule "code.vikunja.io/api/pkg/modules/migration"
 	migrationHandler "code.vikunja.io/api/pkg/modules/migration/handler"
 	"code.vikunja.io/api/pkg/red"
 	"code.vikunja.io/api/pkg/user"
@@ -91,9 +93,15 @@ func FullInit() {
 	models.RegisterReminderCron()
 	models.RegisterOverdueReminderCron()
 	user.RegisterTokenCleanupCron()
+	user.RegisterSessionCleanupCron()
 	user.RegisterDeletionNotificationCron()
 	models.RegisterUserDeletionCron()
 	models.RegisterOldExportCleanupCron()
+	export.RegisterOldExportCleanupCron()
+	models.RegisterUserExportScheduleCron()
+	models.RegisterAutomationCron()
+	models.RegisterLinkShareCleanupCron()
+	migrationModule.RegisterSyncCron()
 	openid.CleanupSavedOpenIDProviders()
 	openid.RegisterEmptyOpenIDTeamCleanupCron()
 
@@ -102,6 +110,7 @@ func FullInit() {
 		models.RegisterListeners()
 		user.RegisterListeners()
 		migrationHandler.RegisterListeners()
+		export.RegisterListeners()
 		err := events.InitEvents()
 		if err != nil {
 			log.Fatal(err.Error())
@@ -0,0 +1,52 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	apiv1 "code.vikunja.io/api/pkg/routes/api/v1"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminResetUserTwoFactor(t *testing.T) {
+	t.Run("admin", func(t *testing.T) {
+		_, err := newTestRequestWithUser(t, http.MethodPost, apiv1.AdminResetUserTwoFactor, &testuser16, "", nil, map[string]string{"user": "15"})
+		require.NoError(t, err)
+		db.AssertMissing(t, "user_webauthn_credentials", map[string]interface{}{"user_id": 15})
+		db.AssertMissing(t, "user_recovery_codes", map[string]interface{}{"user_id": 15})
+		db.AssertExists(t, "user_audit_log", map[string]interface{}{
+			"user_id": 15,
+			"action":  user.AuditActionTwoFactorReset,
+			"details": "Reset by user16",
+		}, false)
+	})
+	t.Run("not an admin", func(t *testing.T) {
+		_, err := newTestRequestWithUser(t, http.MethodPost, apiv1.AdminResetUserTwoFactor, &testuser15, "", nil, map[string]string{"user": "15"})
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, user.ErrCodeUserIsNotAdmin)
+	})
+	t.Run("user does not exist", func(t *testing.T) {
+		_, err := newTestRequestWithUser(t, http.MethodPost, apiv1.AdminResetUserTwoFactor, &testuser16, "", nil, map[string]string{"user": "9999"})
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, user.ErrCodeUserDoesNotExist)
+	})
+}
@@ -20,10 +20,13 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/modules/auth"
 	"code.vikunja.io/api/pkg/routes"
+	apiv1 "code.vikunja.io/api/pkg/routes/api/v1"
 	"code.vikunja.io/api/pkg/user"
 
 	"github.com/labstack/echo/v4"
@@ -51,6 +54,83 @@ func TestAPIToken(t *testing.T) {
 		require.NoError(t, h(c))
 		// check if the request handlers "see" the request as if it came directly from that user
 		assert.Contains(t, res.Body.String(), `"username":"user1"`)
+		db.AssertExists(t, "api_tokens", map[string]interface{}{
+			"id":           1,
+			"last_used_ip": "192.0.2.1",
+		}, false)
+	})
+	t.Run("token limited to a project", func(t *testing.T) {
+		e, err := setupTestEnv()
+		require.NoError(t, err)
+
+		s := db.NewSession()
+		defer s.Close()
+		token := &models.APIToken{
+			Title:       "limited",
+			Permissions: models.APIPermissions{"tasks": []string{"read_all"}},
+			ExpiresAt:   time.Now().Add(time.Hour),
+			ProjectIDs:  []int64{22},
+			ReadOnly:    true,
+		}
+		require.NoError(t, token.Create(s, &testuser1))
+		require.NoError(t, s.Commit())
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/all", nil)
+		res := httptest.NewRecorder()
+		c := e.NewContext(req, res)
+		h := routes.SetupTokenMiddleware()(func(c echo.Context) error {
+			a, err := auth.GetAuthFromClaims(c)
+			if err != nil {
+				return err
+			}
+
+			s := db.NewSession()
+			defer s.Close()
+			canReadScoped, _, err := (&models.Project{ID: 21}).CanRead(s, a)
+			if err != nil {
+				return err
+			}
+			canReadOther, _, err := (&models.Project{ID: 1}).CanRead(s, a)
+			if err != nil {
+				return err
+			}
+			return c.JSON(http.StatusOK, []bool{canReadScoped, canReadOther})
+		})
+
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token.Token)
+		require.NoError(t, h(c))
+		assert.Equal(t, "[true,false]\n", res.Body.String())
+	})
+	t.Run("service account token", func(t *testing.T) {
+		e, err := setupTestEnv()
+		require.NoError(t, err)
+
+		s := db.NewSession()
+		defer s.Close()
+		token := &models.ServiceAccountToken{ServiceAccountID: 17, APIToken: models.APIToken{
+			Title:       "bot",
+			Permissions: models.APIPermissions{"tasks": []string{"read_all"}},
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}}
+		require.NoError(t, token.Create(s, &testuser1))
+		require.NoError(t, s.Commit())
+
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/all", nil)
+		res := httptest.NewRecorder()
+		c := e.NewContext(req, res)
+		h := routes.SetupTokenMiddleware()(func(c echo.Context) error {
+			u, err := auth.GetAuthFromClaims(c)
+			if err != nil {
+				return c.String(http.StatusInternalServerError, err.Error())
+			}
+
+			return c.JSON(http.StatusOK, u)
+		})
+
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token.Token)
+		require.NoError(t, h(c))
+		assert.Contains(t, res.Body.String(), `"username":"bot17"`)
+		assert.Contains(t, res.Body.String(), `"is_bot":true`)
 	})
 	t.Run("invalid token", func(t *testing.T) {
 		e, err := setupTestEnv()
@@ -105,10 +185,56 @@ func TestAPIToken(t *testing.T) {
 		defer s.Close()
 		u, err := user.GetUserByID(s, 1)
 		require.NoError(t, err)
-		jwt, err := auth.NewUserJWTAuthtoken(u, false)
+		session, err := user.CreateSession(s, u, "test", "127.0.0.1", false)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+		jwt, err := auth.NewUserJWTAuthtoken(u, session)
 		require.NoError(t, err)
 
 		req.Header.Set(echo.HeaderAuthorization, "Bearer "+jwt)
 		require.NoError(t, h(c))
 	})
+	t.Run("jwt with ended session", func(t *testing.T) {
+		e, err := setupTestEnv()
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/all", nil)
+		res := httptest.NewRecorder()
+		c := e.NewContext(req, res)
+		h := routes.SetupTokenMiddleware()(func(c echo.Context) error {
+			return c.String(http.StatusOK, "test")
+		})
+
+		s := db.NewSession()
+		defer s.Close()
+		u, err := user.GetUserByID(s, 1)
+		require.NoError(t, err)
+		session, err := user.CreateSession(s, u, "test", "127.0.0.1", false)
+		require.NoError(t, err)
+		jwt, err := auth.NewUserJWTAuthtoken(u, session)
+		require.NoError(t, err)
+		err = user.DeleteSession(s, u, session.ID)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+jwt)
+		require.Error(t, h(c))
+	})
+}
+
+func TestRotateAPIToken(t *testing.T) {
+	t.Run("own token", func(t *testing.T) {
+		rec, err := newTestRequestWithUser(t, http.MethodPost, apiv1.RotateAPIToken, &testuser1, "", nil, map[string]string{"token": "1"})
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"token":"tk_`)
+		assert.Contains(t, rec.Body.String(), `"title":"test token 1"`)
+		db.AssertMissing(t, "api_tokens", map[string]interface{}{
+			"id":               1,
+			"token_last_eight": "75f29d2e",
+		})
+	})
+	t.Run("token of another user", func(t *testing.T) {
+		_, err := newTestRequestWithUser(t, http.MethodPost, apiv1.RotateAPIToken, &testuser1, "", nil, map[string]string{"token": "3"})
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, models.ErrorCodeGenericForbidden)
+	})
 }
@@ -58,6 +58,12 @@ var (
 		Password: "$2a$14$dcadBoMBL9jQoOcZK8Fju.cy0Ptx2oZECkKLnaa8ekRoTFe1w7To.",
 		Email:    "user15@example.com",
 	}
+	testuser16 = user.User{
+		ID:       16,
+		Username: "user16",
+		Password: "$2a$14$dcadBoMBL9jQoOcZK8Fju.cy0Ptx2oZECkKLnaa8ekRoTFe1w7To.",
+		Email:    "user16@example.com",
+	}
 )
 
 func setupTestEnv() (e *echo.Echo, err error) {
@@ -115,9 +121,15 @@ func newTestRequest(t *testing.T, method string, handler func(ctx echo.Context)
 	return
 }
 
-func addUserTokenToContext(t *testing.T, user *user.User, c echo.Context) {
+func addUserTokenToContext(t *testing.T, u *user.User, c echo.Context) {
+	s := db.NewSession()
+	defer s.Close()
+	session, err := user.CreateSession(s, u, "test", "127.0.0.1", false)
+	require.NoError(t, err)
+	require.NoError(t, s.Commit())
+
 	// Get the token as a string
-	token, err := auth.NewUserJWTAuthtoken(user, false)
+	token, err := auth.NewUserJWTAuthtoken(u, session)
 	require.NoError(t, err)
 	// We send the string token through the parsing function to get a valid jwt.Token
 	tken, err := jwt.Parse(token, func(_ *jwt.Token) (interface{}, error) {
@@ -20,6 +20,7 @@ import (
 	"net/http"
 	"testing"
 
+	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/models"
 	apiv1 "code.vikunja.io/api/pkg/routes/api/v1"
 
@@ -59,4 +60,36 @@ func TestLinkSharingAuth(t *testing.T) {
 		require.Error(t, err)
 		assertHandlerErrorCode(t, err, models.ErrCodeLinkSharePasswordInvalid)
 	})
+	t.Run("Task share", func(t *testing.T) {
+		rec, err := newTestRequest(t, http.MethodPost, apiv1.AuthenticateLinkShare, ``, nil, map[string]string{"share": "testTask"})
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"token":"`)
+		assert.Contains(t, rec.Body.String(), `"task_id":13`)
+		assert.Contains(t, rec.Body.String(), `"project_view_id":0`)
+	})
+	t.Run("Access is logged", func(t *testing.T) {
+		rec, err := newTestRequest(t, http.MethodPost, apiv1.AuthenticateLinkShare, ``, nil, map[string]string{"share": "test2"})
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"uses":1`)
+		db.AssertExists(t, "link_share_access_log", map[string]interface{}{
+			"link_share_id": 2,
+			"ip_address":    "192.0.2.1",
+		}, false)
+	})
+	t.Run("Expired", func(t *testing.T) {
+		_, err := newTestRequest(t, http.MethodPost, apiv1.AuthenticateLinkShare, ``, nil, map[string]string{"share": "testExpired"})
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, models.ErrCodeLinkShareExpired)
+	})
+	t.Run("Max uses reached", func(t *testing.T) {
+		_, err := newTestRequest(t, http.MethodPost, apiv1.AuthenticateLinkShare, ``, nil, map[string]string{"share": "testMaxUses"})
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, models.ErrCodeLinkShareMaxUsesReached)
+	})
+	t.Run("IP not allowed", func(t *testing.T) {
+		_, err := newTestRequest(t, http.MethodPost, apiv1.AuthenticateLinkShare, ``, nil, map[string]string{"share": "testAllowedIPs"})
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, models.ErrCodeLinkShareIPNotAllowed)
+		db.AssertMissing(t, "link_share_access_log", map[string]interface{}{"link_share_id": 7})
+	})
 }
@@ -35,6 +35,7 @@ func TestLogin(t *testing.T) {
 }`, nil, nil)
 		require.NoError(t, err)
 		assert.Contains(t, rec.Body.String(), "token")
+		assert.Contains(t, rec.Body.String(), "refresh_token")
 	})
 	t.Run("Empty payload", func(t *testing.T) {
 		_, err := newTestRequest(t, http.MethodPost, apiv1.Login, `{}`, nil, nil)
@@ -66,3 +67,23 @@ func TestLogin(t *testing.T) {
 		assertHandlerErrorCode(t, err, user.ErrCodeEmailNotConfirmed)
 	})
 }
+
+func TestRefreshToken(t *testing.T) {
+	t.Run("Normal refresh", func(t *testing.T) {
+		rec, err := newTestRequest(t, http.MethodPost, apiv1.RefreshToken, `{"refresh_token": "sessionrefreshtoken1"}`, nil, nil)
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"token":`)
+		assert.Contains(t, rec.Body.String(), `"refresh_token":`)
+		assert.NotContains(t, rec.Body.String(), "sessionrefreshtoken1")
+	})
+	t.Run("Expired session", func(t *testing.T) {
+		_, err := newTestRequest(t, http.MethodPost, apiv1.RefreshToken, `{"refresh_token": "sessionrefreshtoken3"}`, nil, nil)
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, user.ErrCodeInvalidRefreshToken)
+	})
+	t.Run("Empty payload", func(t *testing.T) {
+		rec, err := newTestRequest(t, http.MethodPost, apiv1.RefreshToken, `{}`, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
@@ -0,0 +1,211 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package integrations
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/routes"
+	apiv1 "code.vikunja.io/api/pkg/routes/api/v1"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOAuthCodeChallenge = "cj69Py3d20ZCwCLVAzPasaJGQLCli6qpjvfoFga8auU"
+
+func TestOAuthAuthorize(t *testing.T) {
+	authorizeParams := func() url.Values {
+		return url.Values{
+			"response_type":         []string{"code"},
+			"client_id":             []string{"testclient1"},
+			"redirect_uri":          []string{"https://app.example.com/callback"},
+			"scope":                 []string{"tasks:read_all"},
+			"state":                 []string{"xyz"},
+			"code_challenge":        []string{testOAuthCodeChallenge},
+			"code_challenge_method": []string{"S256"},
+		}
+	}
+
+	t.Run("consent", func(t *testing.T) {
+		rec, err := newTestRequestWithUser(t, http.MethodGet, apiv1.GetOAuthConsent, &testuser1, "", authorizeParams(), nil)
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"name":"Test app"`)
+		assert.Contains(t, rec.Body.String(), `"scopes":{"tasks":["read_all"]}`)
+		assert.Contains(t, rec.Body.String(), `"already_authorized":true`)
+		assert.NotContains(t, rec.Body.String(), `client_secret`)
+	})
+	t.Run("consent without pkce", func(t *testing.T) {
+		params := authorizeParams()
+		params.Del("code_challenge")
+		_, err := newTestRequestWithUser(t, http.MethodGet, apiv1.GetOAuthConsent, &testuser1, "", params, nil)
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, models.ErrCodeOAuthPKCERequired)
+	})
+	t.Run("consent with unregistered redirect uri", func(t *testing.T) {
+		params := authorizeParams()
+		params.Set("redirect_uri", "https://evil.example.com/callback")
+		_, err := newTestRequestWithUser(t, http.MethodGet, apiv1.GetOAuthConsent, &testuser1, "", params, nil)
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, models.ErrCodeOAuthInvalidRedirectURI)
+	})
+	t.Run("authorize", func(t *testing.T) {
+		rec, err := newTestRequestWithUser(t, http.MethodPost, apiv1.AuthorizeOAuthClient, &testuser15, `{
+  "response_type": "code",
+  "client_id": "testclient1",
+  "redirect_uri": "https://app.example.com/callback",
+  "scope": "tasks:read_all",
+  "state": "xyz",
+  "code_challenge": "`+testOAuthCodeChallenge+`",
+  "code_challenge_method": "S256"
+}`, nil, nil)
+		require.NoError(t, err)
+
+		response := &models.OAuthAuthorizationResponse{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), response))
+		redirect, err := url.Parse(response.RedirectURL)
+		require.NoError(t, err)
+		assert.Equal(t, "app.example.com", redirect.Host)
+		assert.Equal(t, "xyz", redirect.Query().Get("state"))
+		assert.NotEmpty(t, redirect.Query().Get("code"))
+		db.AssertExists(t, "oauth_authorizations", map[string]interface{}{
+			"user_id":   15,
+			"client_id": 1,
+		}, false)
+	})
+}
+
+func TestOAuthToken(t *testing.T) {
+	t.Run("authorization code", func(t *testing.T) {
+		e, err := setupTestEnv()
+		require.NoError(t, err)
+
+		c, rec := createRequest(e, http.MethodPost, `{
+  "grant_type": "authorization_code",
+  "code": "testcode1",
+  "redirect_uri": "https://app.example.com/callback",
+  "code_verifier": "testverifier-0123456789-0123456789-0123456789",
+  "client_id": "testclient1",
+  "client_secret": "testsecret1"
+}`, nil, nil)
+		require.NoError(t, apiv1.OAuthToken(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+		tokens := &models.OAuthTokenResponse{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), tokens))
+		assert.Equal(t, "Bearer", tokens.TokenType)
+		assert.Equal(t, "tasks:read_all", tokens.Scope)
+		assert.NotEmpty(t, tokens.RefreshToken)
+
+		// The access token works like an api token limited to the granted scopes
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/all", nil)
+		res := httptest.NewRecorder()
+		h := routes.SetupTokenMiddleware()(func(c echo.Context) error {
+			u, err := auth.GetAuthFromClaims(c)
+			if err != nil {
+				return c.String(http.StatusInternalServerError, err.Error())
+			}
+
+			return c.JSON(http.StatusOK, u)
+		})
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+tokens.AccessToken)
+		require.NoError(t, h(e.NewContext(req, res)))
+		assert.Contains(t, res.Body.String(), `"username":"user1"`)
+
+		req = httptest.NewRequest(http.MethodGet, "/api/v1/projects", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+tokens.AccessToken)
+		require.Error(t, h(e.NewContext(req, httptest.NewRecorder())))
+	})
+	t.Run("client credentials via basic auth", func(t *testing.T) {
+		e, err := setupTestEnv()
+		require.NoError(t, err)
+
+		c, rec := createRequest(e, http.MethodPost, `{
+  "grant_type": "refresh_token",
+  "refresh_token": "testrefreshtoken1"
+}`, nil, nil)
+		c.Request().SetBasicAuth("testclient1", "testsecret1")
+		require.NoError(t, apiv1.OAuthToken(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"access_token":"tk_`)
+	})
+	t.Run("code used twice", func(t *testing.T) {
+		e, err := setupTestEnv()
+		require.NoError(t, err)
+
+		payload := `{
+  "grant_type": "authorization_code",
+  "code": "testcode1",
+  "redirect_uri": "https://app.example.com/callback",
+  "code_verifier": "testverifier-0123456789-0123456789-0123456789",
+  "client_id": "testclient1",
+  "client_secret": "testsecret1"
+}`
+		c, rec := createRequest(e, http.MethodPost, payload, nil, nil)
+		require.NoError(t, apiv1.OAuthToken(c))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		c, rec = createRequest(e, http.MethodPost, payload, nil, nil)
+		require.NoError(t, apiv1.OAuthToken(c))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"error":"invalid_grant"`)
+	})
+	t.Run("wrong code verifier", func(t *testing.T) {
+		rec, err := newTestRequest(t, http.MethodPost, apiv1.OAuthToken, `{
+  "grant_type": "authorization_code",
+  "code": "testcode1",
+  "redirect_uri": "https://app.example.com/callback",
+  "code_verifier": "wrong",
+  "client_id": "testclient1",
+  "client_secret": "testsecret1"
+}`, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"error":"invalid_grant"`)
+		db.AssertMissing(t, "oauth_authorization_codes", map[string]interface{}{"id": 1})
+	})
+	t.Run("wrong client secret", func(t *testing.T) {
+		rec, err := newTestRequest(t, http.MethodPost, apiv1.OAuthToken, `{
+  "grant_type": "refresh_token",
+  "refresh_token": "testrefreshtoken1",
+  "client_id": "testclient1",
+  "client_secret": "wrong"
+}`, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"error":"invalid_client"`)
+	})
+	t.Run("unsupported grant type", func(t *testing.T) {
+		rec, err := newTestRequest(t, http.MethodPost, apiv1.OAuthToken, `{
+  "grant_type": "password",
+  "client_id": "testclient1",
+  "client_secret": "testsecret1"
+}`, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"error":"unsupported_grant_type"`)
+	})
+}
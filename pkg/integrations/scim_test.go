@@ -0,0 +1,296 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package integrations
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const scimTestToken = "scim-test-token"
+
+// newSCIMTestEnv returns a function to send requests to the scim endpoints.
+// Unlike the other integration tests, all requests share the same database state.
+func newSCIMTestEnv(t *testing.T) func(method, path, body string) *httptest.ResponseRecorder {
+	config.AuthSCIMEnabled.Set(true)
+	config.AuthSCIMToken.Set(scimTestToken)
+	t.Cleanup(func() {
+		config.AuthSCIMEnabled.Set(false)
+		config.AuthSCIMToken.Set("")
+	})
+
+	e, err := setupTestEnv()
+	require.NoError(t, err)
+
+	return func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, "application/scim+json")
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+scimTestToken)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+}
+
+func scimResourceID(t *testing.T, rec *httptest.ResponseRecorder) string {
+	resource := map[string]interface{}{}
+	err := json.Unmarshal(rec.Body.Bytes(), &resource)
+	require.NoError(t, err)
+	return resource["id"].(string)
+}
+
+func TestSCIMAuthentication(t *testing.T) {
+	newSCIMTestEnv(t)
+	e, err := setupTestEnv()
+	require.NoError(t, err)
+
+	for _, token := range []string{"", "Bearer wrong"} {
+		req := httptest.NewRequest(http.MethodGet, "/scim/v2/Users", nil)
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, token)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"status":"401"`)
+	}
+}
+
+func TestSCIMUsers(t *testing.T) {
+	const newUser = `{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+  "externalId": "00u1abcd",
+  "userName": "jdoe",
+  "name": {"givenName": "John", "familyName": "Doe"},
+  "emails": [{"value": "jdoe@example.com", "type": "work", "primary": true}],
+  "active": true
+}`
+
+	t.Run("lifecycle", func(t *testing.T) {
+		do := newSCIMTestEnv(t)
+
+		rec := do(http.MethodPost, "/scim/v2/Users", newUser)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		assert.Equal(t, "application/scim+json", rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Body.String(), `"userName":"jdoe"`)
+		id := scimResourceID(t, rec)
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":       id,
+			"username": "jdoe",
+			"email":    "jdoe@example.com",
+			"name":     "John Doe",
+			"issuer":   user.IssuerSCIM,
+			"subject":  "00u1abcd",
+		}, false)
+
+		rec = do(http.MethodGet, `/scim/v2/Users?filter=userName+eq+%22jdoe%22`, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"totalResults":1`)
+		assert.Contains(t, rec.Body.String(), `"id":"`+id+`"`)
+
+		rec = do(http.MethodPatch, "/scim/v2/Users/"+id, `{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+  "Operations": [{"op": "Replace", "path": "active", "value": "False"}]
+}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"active":false`)
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":     id,
+			"status": user.StatusDisabled,
+		}, false)
+
+		rec = do(http.MethodPut, "/scim/v2/Users/"+id, strings.ReplaceAll(newUser, "jdoe@example.com", "john@example.com"))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":     id,
+			"email":  "john@example.com",
+			"status": user.StatusActive,
+		}, false)
+
+		rec = do(http.MethodDelete, "/scim/v2/Users/"+id, "")
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		db.AssertMissing(t, "users", map[string]interface{}{"id": id})
+	})
+	t.Run("deactivated user with api token", func(t *testing.T) {
+		do := newSCIMTestEnv(t)
+		e, err := setupTestEnv()
+		require.NoError(t, err)
+
+		rec := do(http.MethodPost, "/scim/v2/Users", newUser)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		id, err := strconv.ParseInt(scimResourceID(t, rec), 10, 64)
+		require.NoError(t, err)
+
+		s := db.NewSession()
+		defer s.Close()
+		token := &models.APIToken{
+			Title:       "scim user",
+			Permissions: models.APIPermissions{"tasks": []string{"read_all"}},
+			ExpiresAt:   time.Now().Add(time.Hour),
+		}
+		require.NoError(t, token.Create(s, &user.User{ID: id}))
+		require.NoError(t, s.Commit())
+
+		requestWithToken := func() int {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/tasks/all", nil)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token.Token)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			return rec.Code
+		}
+		assert.Equal(t, http.StatusOK, requestWithToken())
+
+		rec = do(http.MethodPatch, "/scim/v2/Users/"+strconv.FormatInt(id, 10), `{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+  "Operations": [{"op": "Replace", "path": "active", "value": false}]
+}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		assert.Equal(t, http.StatusUnauthorized, requestWithToken())
+	})
+	t.Run("users from other issuers", func(t *testing.T) {
+		do := newSCIMTestEnv(t)
+
+		rec := do(http.MethodGet, "/scim/v2/Users/1", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = do(http.MethodDelete, "/scim/v2/Users/1", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		db.AssertExists(t, "users", map[string]interface{}{"id": 1}, false)
+
+		rec = do(http.MethodGet, "/scim/v2/Users", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"totalResults":0`)
+	})
+	t.Run("username taken", func(t *testing.T) {
+		do := newSCIMTestEnv(t)
+
+		rec := do(http.MethodPost, "/scim/v2/Users", strings.ReplaceAll(newUser, `"jdoe"`, `"user1"`))
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"scimType":"uniqueness"`)
+	})
+	t.Run("unsupported filter", func(t *testing.T) {
+		do := newSCIMTestEnv(t)
+
+		rec := do(http.MethodGet, `/scim/v2/Users?filter=userName+sw+%22j%22`, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"scimType":"invalidFilter"`)
+	})
+}
+
+func TestSCIMGroups(t *testing.T) {
+	createUser := func(t *testing.T, do func(method, path, body string) *httptest.ResponseRecorder, username string) string {
+		rec := do(http.MethodPost, "/scim/v2/Users", `{
+  "userName": "`+username+`",
+  "emails": [{"value": "`+username+`@example.com"}]
+}`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		return scimResourceID(t, rec)
+	}
+
+	t.Run("lifecycle", func(t *testing.T) {
+		do := newSCIMTestEnv(t)
+		jdoe := createUser(t, do, "jdoe")
+		jane := createUser(t, do, "jane")
+
+		rec := do(http.MethodPost, "/scim/v2/Groups", `{
+  "schemas": ["urn:ietf:params:scim:schemas:core:2.0:Group"],
+  "externalId": "developers",
+  "displayName": "Developers",
+  "members": [{"value": "`+jdoe+`"}]
+}`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		id := scimResourceID(t, rec)
+		assert.Contains(t, rec.Body.String(), `"display":"jdoe"`)
+		db.AssertExists(t, "teams", map[string]interface{}{
+			"id":      id,
+			"name":    "Developers",
+			"oidc_id": "developers",
+			"issuer":  user.IssuerSCIM,
+		}, false)
+		db.AssertExists(t, "team_members", map[string]interface{}{
+			"team_id": id,
+			"user_id": jdoe,
+		}, false)
+
+		rec = do(http.MethodGet, "/scim/v2/Users/"+jdoe, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"groups":[{"value":"`+id+`","display":"Developers"}]`)
+
+		rec = do(http.MethodPatch, "/scim/v2/Groups/"+id, `{
+  "schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+  "Operations": [
+    {"op": "add", "path": "members", "value": [{"value": "`+jane+`"}]},
+    {"op": "remove", "path": "members[value eq \"`+jdoe+`\"]"},
+    {"op": "replace", "path": "displayName", "value": "Engineering"}
+  ]
+}`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		db.AssertExists(t, "teams", map[string]interface{}{
+			"id":   id,
+			"name": "Engineering",
+		}, false)
+		db.AssertExists(t, "team_members", map[string]interface{}{
+			"team_id": id,
+			"user_id": jane,
+		}, false)
+		db.AssertMissing(t, "team_members", map[string]interface{}{
+			"team_id": id,
+			"user_id": jdoe,
+		})
+
+		rec = do(http.MethodGet, `/scim/v2/Groups?filter=displayName+eq+%22Engineering%22`, "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Contains(t, rec.Body.String(), `"totalResults":1`)
+
+		rec = do(http.MethodDelete, "/scim/v2/Groups/"+id, "")
+		require.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+		db.AssertMissing(t, "teams", map[string]interface{}{"id": id})
+		db.AssertMissing(t, "team_members", map[string]interface{}{"team_id": id})
+	})
+	t.Run("teams from other issuers", func(t *testing.T) {
+		do := newSCIMTestEnv(t)
+
+		rec := do(http.MethodGet, "/scim/v2/Groups/1", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+
+		rec = do(http.MethodDelete, "/scim/v2/Groups/1", "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		db.AssertExists(t, "teams", map[string]interface{}{"id": 1}, false)
+	})
+	t.Run("unknown member", func(t *testing.T) {
+		do := newSCIMTestEnv(t)
+
+		rec := do(http.MethodPost, "/scim/v2/Groups", `{"displayName": "Developers", "members": [{"value": "9999"}]}`)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"scimType":"invalidValue"`)
+	})
+}
@@ -0,0 +1,59 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	apiv1 "code.vikunja.io/api/pkg/routes/api/v1"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserSessions(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		rec, err := newTestRequestWithUser(t, http.MethodGet, apiv1.GetUserSessions, &testuser1, "", nil, nil)
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"device_info":"Vikunja Desktop"`)
+		assert.Contains(t, rec.Body.String(), `"is_current":true`)
+		assert.NotContains(t, rec.Body.String(), `Expired session`)
+		assert.NotContains(t, rec.Body.String(), `Safari`)
+		assert.NotContains(t, rec.Body.String(), `refresh_token`)
+	})
+	t.Run("delete", func(t *testing.T) {
+		_, err := newTestRequestWithUser(t, http.MethodDelete, apiv1.DeleteUserSession, &testuser1, "", nil, map[string]string{"session": "2"})
+		require.NoError(t, err)
+		db.AssertMissing(t, "sessions", map[string]interface{}{"id": 2})
+	})
+	t.Run("delete session of another user", func(t *testing.T) {
+		_, err := newTestRequestWithUser(t, http.MethodDelete, apiv1.DeleteUserSession, &testuser1, "", nil, map[string]string{"session": "4"})
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, user.ErrCodeSessionDoesNotExist)
+	})
+	t.Run("delete all others", func(t *testing.T) {
+		_, err := newTestRequestWithUser(t, http.MethodDelete, apiv1.DeleteAllUserSessions, &testuser1, "", nil, nil)
+		require.NoError(t, err)
+		db.AssertMissing(t, "sessions", map[string]interface{}{"id": 1})
+		db.AssertMissing(t, "sessions", map[string]interface{}{"id": 2})
+		db.AssertExists(t, "sessions", map[string]interface{}{"user_id": 1, "device_info": "test"}, false)
+		db.AssertExists(t, "sessions", map[string]interface{}{"id": 4}, false)
+	})
+}
@@ -0,0 +1,143 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	apiv1 "code.vikunja.io/api/pkg/routes/api/v1"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginWithWebAuthn(t *testing.T) {
+	config.ServicePublicURL.Set("https://vikunja.example.com/")
+	defer config.ServicePublicURL.Set("")
+
+	t.Run("security key required", func(t *testing.T) {
+		_, err := newTestRequest(t, http.MethodPost, apiv1.Login, `{
+  "username": "user15",
+  "password": "1234"
+}`, nil, nil)
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, user.ErrCodeWebAuthnRequired)
+	})
+	t.Run("recovery code", func(t *testing.T) {
+		rec, err := newTestRequest(t, http.MethodPost, apiv1.Login, `{
+  "username": "user15",
+  "password": "1234",
+  "recovery_code": "abcde-12345"
+}`, nil, nil)
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"token":`)
+		db.AssertMissing(t, "user_recovery_codes", map[string]interface{}{"id": 1})
+		db.AssertExists(t, "user_audit_log", map[string]interface{}{
+			"user_id": 15,
+			"action":  user.AuditActionRecoveryCodeUsed,
+		}, false)
+	})
+	t.Run("invalid recovery code", func(t *testing.T) {
+		_, err := newTestRequest(t, http.MethodPost, apiv1.Login, `{
+  "username": "user15",
+  "password": "1234",
+  "recovery_code": "wrong"
+}`, nil, nil)
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, user.ErrCodeInvalidRecoveryCode)
+	})
+	t.Run("user without second factor", func(t *testing.T) {
+		rec, err := newTestRequest(t, http.MethodPost, apiv1.Login, `{
+  "username": "user1",
+  "password": "1234"
+}`, nil, nil)
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"token":`)
+	})
+}
+
+func TestUserWebAuthnCredentials(t *testing.T) {
+	t.Run("list", func(t *testing.T) {
+		rec, err := newTestRequestWithUser(t, http.MethodGet, apiv1.GetUserWebAuthnCredentials, &testuser15, "", nil, nil)
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"name":"Test key"`)
+		assert.NotContains(t, rec.Body.String(), `public_key`)
+	})
+	t.Run("rename", func(t *testing.T) {
+		rec, err := newTestRequestWithUser(t, http.MethodPost, apiv1.UpdateUserWebAuthnCredential, &testuser15, `{"name":"Renamed key"}`, nil, map[string]string{"credential": "1"})
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"name":"Renamed key"`)
+	})
+	t.Run("delete", func(t *testing.T) {
+		_, err := newTestRequestWithUser(t, http.MethodDelete, apiv1.DeleteUserWebAuthnCredential, &testuser15, `{"password":"1234"}`, nil, map[string]string{"credential": "1"})
+		require.NoError(t, err)
+		db.AssertMissing(t, "user_webauthn_credentials", map[string]interface{}{"id": 1})
+		db.AssertExists(t, "user_audit_log", map[string]interface{}{
+			"user_id": 15,
+			"action":  user.AuditActionWebAuthnRemoved,
+			"details": "Test key",
+		}, false)
+	})
+	t.Run("delete with wrong password", func(t *testing.T) {
+		_, err := newTestRequestWithUser(t, http.MethodDelete, apiv1.DeleteUserWebAuthnCredential, &testuser15, `{"password":"wrong"}`, nil, map[string]string{"credential": "1"})
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, user.ErrCodeWrongUsernameOrPassword)
+	})
+	t.Run("delete credential of another user", func(t *testing.T) {
+		_, err := newTestRequestWithUser(t, http.MethodDelete, apiv1.DeleteUserWebAuthnCredential, &testuser1, `{"password":"1234"}`, nil, map[string]string{"credential": "1"})
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, user.ErrCodeWebAuthnCredentialDoesNotExist)
+	})
+}
+
+func TestUserRecoveryCodes(t *testing.T) {
+	t.Run("status", func(t *testing.T) {
+		rec, err := newTestRequestWithUser(t, http.MethodGet, apiv1.GetUserRecoveryCodesStatus, &testuser15, "", nil, nil)
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"remaining":2`)
+	})
+	t.Run("generate", func(t *testing.T) {
+		rec, err := newTestRequestWithUser(t, http.MethodPost, apiv1.GenerateUserRecoveryCodes, &testuser15, `{"password":"1234"}`, nil, nil)
+		require.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"codes":[`)
+		db.AssertMissing(t, "user_recovery_codes", map[string]interface{}{"id": 1})
+		db.AssertExists(t, "user_audit_log", map[string]interface{}{
+			"user_id": 15,
+			"action":  user.AuditActionRecoveryCodesGenerated,
+		}, false)
+	})
+	t.Run("generate with wrong password", func(t *testing.T) {
+		_, err := newTestRequestWithUser(t, http.MethodPost, apiv1.GenerateUserRecoveryCodes, &testuser15, `{"password":"wrong"}`, nil, nil)
+		require.Error(t, err)
+		assertHandlerErrorCode(t, err, user.ErrCodeWrongUsernameOrPassword)
+	})
+}
+
+func TestUserAuditLog(t *testing.T) {
+	rec, err := newTestRequestWithUser(t, http.MethodGet, apiv1.GetUserAuditLog, &testuser15, "", nil, nil)
+	require.NoError(t, err)
+	assert.Contains(t, rec.Body.String(), `"action":"webauthn.registered"`)
+	assert.Contains(t, rec.Body.String(), `"details":"Test key"`)
+
+	rec, err = newTestRequestWithUser(t, http.MethodGet, apiv1.GetUserAuditLog, &testuser1, "", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", rec.Body.String())
+}
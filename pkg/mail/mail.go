@@ -23,6 +23,7 @@ import (
 
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/metrics"
 
 	"github.com/wneessen/go-mail"
 )
@@ -109,10 +110,12 @@ func StartMailDaemon() {
 				if !ok {
 					return
 				}
+				metrics.SetMailQueueLength(len(Queue))
 				if !open {
 					err = c.DialWithContext(context.Background())
 					if err != nil {
 						log.Errorf("Error during connect to smtp server: %s", err)
+						metrics.MailSendFailed()
 						break
 					}
 					open = true
@@ -120,8 +123,10 @@ func StartMailDaemon() {
 				err = c.Send(m)
 				if err != nil {
 					log.Errorf("Error when sending mail: %s", err)
+					metrics.MailSendFailed()
 					break
 				}
+				metrics.MailSent()
 				// Close the connection to the SMTP server if no email was sent in
 				// the last 30 seconds.
 			case <-time.After(config.MailerQueueTimeout.GetDuration() * time.Second):
@@ -23,6 +23,7 @@ import (
 
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/metrics"
 	"code.vikunja.io/api/pkg/version"
 
 	"github.com/wneessen/go-mail"
@@ -40,6 +41,7 @@ type Opts struct {
 	Headers     []*header
 	Embeds      map[string]io.Reader
 	EmbedFS     map[string]*embed.FS
+	Attachments map[string]io.Reader
 }
 
 // ContentType represents mail content types
@@ -102,6 +104,13 @@ func getMessage(opts *Opts) *mail.Msg {
 		}
 	}
 
+	for name, content := range opts.Attachments {
+		err := m.AttachReader(name, content)
+		if err != nil {
+			log.Errorf("Could not attach %s to mail: %s", name, err)
+		}
+	}
+
 	switch opts.ContentType {
 	case ContentTypePlain:
 		m.SetBodyString("text/plain", opts.Message)
@@ -124,4 +133,5 @@ func SendMail(opts *Opts) {
 
 	m := getMessage(opts)
 	Queue <- m
+	metrics.SetMailQueueLength(len(Queue))
 }
@@ -0,0 +1,187 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/log"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// These metrics are always recorded, but only exposed once they are registered with InitMetrics.
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "vikunja_http_request_duration_seconds",
+		Help:    "The time it took to handle http requests, by route and status code",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "vikunja_db_query_duration_seconds",
+		Help:    "The time it took to run database queries, by kind of query",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"operation"})
+
+	mailQueueLength = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "vikunja_mail_queue_length",
+		Help: "The number of mails waiting in the queue to be sent",
+	})
+	mailsSent = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "vikunja_mails_sent_total",
+		Help: "The number of mails sent successfully",
+	})
+	mailSendFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "vikunja_mail_send_failures_total",
+		Help: "The number of mails which could not be sent",
+	})
+
+	webhookDeliveryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "vikunja_webhook_delivery_duration_seconds",
+		Help:    "The time it took to deliver webhook payloads, by event",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"event"})
+	webhookDeliveryFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "vikunja_webhook_delivery_failures_total",
+		Help: "The number of webhook payloads which could not be delivered or were answered with an error status, by event",
+	}, []string{"event"})
+
+	reminderCronLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "vikunja_reminder_cron_lag_seconds",
+		Help: "The time between the scheduled start of the last reminder cron run and the moment all its reminders were sent",
+	})
+
+	eventHandlerDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "vikunja_event_handler_duration_seconds",
+		Help:    "The time it took event listeners to handle an event, by event and listener",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"event", "listener"})
+)
+
+func registerInstrumentation() {
+	for _, c := range []prometheus.Collector{
+		httpRequestDuration,
+		dbQueryDuration,
+		mailQueueLength,
+		mailsSent,
+		mailSendFailures,
+		webhookDeliveryDuration,
+		webhookDeliveryFailures,
+		reminderCronLag,
+		eventHandlerDuration,
+	} {
+		err := registry.Register(c)
+		if err != nil {
+			log.Criticalf("Could not register metrics: %s", err)
+		}
+	}
+}
+
+// dbStats holds the collector of the database connection pool which is currently registered.
+var dbStats prometheus.Collector
+
+// RegisterDBStats registers metrics about the connection pool of the database.
+// The database engine is created more than once, for example during initialization or when restoring a dump.
+// Only the connection pool of the engine created last is reported, since that is the one all new sessions use.
+func RegisterDBStats(db *sql.DB) error {
+	if dbStats != nil {
+		GetRegistry().Unregister(dbStats)
+	}
+
+	dbStats = collectors.NewDBStatsCollector(db, "vikunja")
+	return GetRegistry().Register(dbStats)
+}
+
+// ObserveHTTPRequest records the duration of a handled http request.
+// The route is the path the request was matched with, not the actual path, to keep the number of labels small.
+func ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	if route == "" {
+		route = "unknown"
+	}
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// RecordHTTPRequests is a middleware which records the duration and status code of all requests.
+func RecordHTTPRequests(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		err := next(c)
+		if err != nil {
+			// The status code is only known once the error was handled.
+			c.Error(err)
+		}
+		ObserveHTTPRequest(c.Request().Method, c.Path(), c.Response().Status, time.Since(start))
+		return nil
+	}
+}
+
+// ObserveDBQuery records the duration of a database query.
+func ObserveDBQuery(query string, duration time.Duration) {
+	dbQueryDuration.WithLabelValues(queryOperation(query)).Observe(duration.Seconds())
+}
+
+// queryOperation returns the kind of the query, for example select or insert.
+func queryOperation(query string) string {
+	query = strings.TrimSpace(query)
+	if i := strings.IndexAny(query, " \n\t("); i > 0 {
+		query = query[:i]
+	}
+	switch op := strings.ToLower(query); op {
+	case "select", "insert", "update", "delete", "with", "create", "alter", "drop":
+		return op
+	}
+	return "other"
+}
+
+// SetMailQueueLength sets the number of mails currently waiting to be sent.
+func SetMailQueueLength(length int) {
+	mailQueueLength.Set(float64(length))
+}
+
+// MailSent counts a successfully sent mail.
+func MailSent() {
+	mailsSent.Inc()
+}
+
+// MailSendFailed counts a mail which could not be sent.
+func MailSendFailed() {
+	mailSendFailures.Inc()
+}
+
+// ObserveWebhookDelivery records the duration of a webhook delivery and whether it failed.
+func ObserveWebhookDelivery(event string, duration time.Duration, failed bool) {
+	webhookDeliveryDuration.WithLabelValues(event).Observe(duration.Seconds())
+	if failed {
+		webhookDeliveryFailures.WithLabelValues(event).Inc()
+	}
+}
+
+// SetReminderCronLag sets how late the last run of the reminder cron finished sending reminders.
+func SetReminderCronLag(lag time.Duration) {
+	reminderCronLag.Set(lag.Seconds())
+}
+
+// ObserveEventHandler records the time it took a listener to handle an event.
+func ObserveEventHandler(event, listener string, duration time.Duration) {
+	eventHandlerDuration.WithLabelValues(event, listener).Observe(duration.Seconds())
+}
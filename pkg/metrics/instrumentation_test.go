@@ -0,0 +1,173 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// gatherMetric reads a metric with the given name and labels back from the registry.
+// It returns the sample count for histograms and the value for gauges.
+func gatherMetric(t *testing.T, name string, labels map[string]string) (sampleCount uint64, value float64, found bool) {
+	families, err := GetRegistry().Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			matches := 0
+			for _, label := range m.GetLabel() {
+				if v, has := labels[label.GetName()]; has && v == label.GetValue() {
+					matches++
+				}
+			}
+			if matches == len(labels) {
+				return m.GetHistogram().GetSampleCount(), m.GetGauge().GetValue(), true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+func TestQueryOperation(t *testing.T) {
+	for query, expected := range map[string]string{
+		"SELECT * FROM `tasks` WHERE id = ?":     "select",
+		"  insert INTO tasks (title) VALUES (?)": "insert",
+		"UPDATE\n`tasks` SET done = ?":           "update",
+		"DELETE FROM tasks":                      "delete",
+		"WITH RECURSIVE all_projects AS (...)":   "with",
+		"CREATE TABLE foo (id INT)":              "create",
+		"ALTER TABLE foo ADD bar INT":            "alter",
+		"DROP TABLE foo":                         "drop",
+		"select(1)":                              "select",
+		"PRAGMA foreign_keys = ON":               "other",
+		"SELECTED":                               "other",
+		"":                                       "other",
+	} {
+		assert.Equal(t, expected, queryOperation(query), query)
+	}
+}
+
+func TestObserveDBQuery(t *testing.T) {
+	before, _, _ := gatherMetric(t, "vikunja_db_query_duration_seconds", map[string]string{"operation": "drop"})
+
+	ObserveDBQuery("DROP TABLE foo", time.Millisecond)
+
+	after, _, found := gatherMetric(t, "vikunja_db_query_duration_seconds", map[string]string{"operation": "drop"})
+	require.True(t, found)
+	assert.Equal(t, before+1, after)
+}
+
+func TestObserveHTTPRequest(t *testing.T) {
+	t.Run("route", func(t *testing.T) {
+		ObserveHTTPRequest("TEST", "/api/v1/tasks/:task", http.StatusOK, time.Second)
+
+		count, _, found := gatherMetric(t, "vikunja_http_request_duration_seconds", map[string]string{
+			"method": "TEST",
+			"route":  "/api/v1/tasks/:task",
+			"status": "200",
+		})
+		require.True(t, found)
+		assert.Equal(t, uint64(1), count)
+	})
+	t.Run("empty route", func(t *testing.T) {
+		ObserveHTTPRequest("TEST", "", http.StatusNotFound, time.Second)
+
+		count, _, found := gatherMetric(t, "vikunja_http_request_duration_seconds", map[string]string{
+			"method": "TEST",
+			"route":  "unknown",
+			"status": "404",
+		})
+		require.True(t, found)
+		assert.Equal(t, uint64(1), count)
+	})
+}
+
+func TestRecordHTTPRequests(t *testing.T) {
+	e := echo.New()
+	e.Use(RecordHTTPRequests)
+	e.PATCH("/things/:thing", func(c echo.Context) error {
+		if c.Param("thing") == "missing" {
+			return echo.NewHTTPError(http.StatusNotFound)
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	for thing, status := range map[string]int{
+		"1":       http.StatusNoContent,
+		"missing": http.StatusNotFound,
+	} {
+		req := httptest.NewRequest(http.MethodPatch, "/things/"+thing, nil)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		// The error must still be sent to the client
+		assert.Equal(t, status, rec.Code, thing)
+	}
+
+	// Requests are recorded by the route they matched, errors with the status code they were answered with
+	for status, expected := range map[string]uint64{"204": 1, "404": 1, "200": 0} {
+		count, _, _ := gatherMetric(t, "vikunja_http_request_duration_seconds", map[string]string{
+			"method": http.MethodPatch,
+			"route":  "/things/:thing",
+			"status": status,
+		})
+		assert.Equal(t, expected, count, status)
+	}
+}
+
+// fakeConnector allows creating a *sql.DB without a database driver. It never connects.
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("not connected")
+}
+
+func (fakeConnector) Driver() driver.Driver {
+	return nil
+}
+
+func TestRegisterDBStats(t *testing.T) {
+	first := sql.OpenDB(fakeConnector{})
+	defer first.Close()
+	first.SetMaxOpenConns(3)
+	second := sql.OpenDB(fakeConnector{})
+	defer second.Close()
+	second.SetMaxOpenConns(7)
+
+	require.NoError(t, RegisterDBStats(first))
+	require.NoError(t, RegisterDBStats(second))
+
+	// Only the database registered last is reported
+	_, value, found := gatherMetric(t, "go_sql_max_open_connections", map[string]string{"db_name": "vikunja"})
+	require.True(t, found)
+	assert.InDelta(t, 7, value, 0)
+}
@@ -74,6 +74,8 @@ func InitMetrics() {
 
 	setupActiveUsersMetric()
 	setupActiveLinkSharesMetric()
+
+	registerInstrumentation()
 }
 
 // GetCount returns the current count from keyvalue
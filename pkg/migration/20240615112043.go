@@ -0,0 +1,51 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type projectViewSwimlaneConfiguration20240615112043 struct {
+	Title  string `json:"title"`
+	Filter string `json:"filter"`
+	Limit  int64  `json:"limit"`
+}
+
+type projectView20240615112043 struct {
+	SwimlaneConfigurationMode int                                               `xorm:"default 0"`
+	SwimlaneConfiguration     []*projectViewSwimlaneConfiguration20240615112043 `xorm:"json"`
+	SwimlaneLimit             int64                                             `xorm:"default 0"`
+}
+
+func (projectView20240615112043) TableName() string {
+	return "project_views"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20240615112043",
+		Description: "Add swimlane configuration to project views",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(projectView20240615112043{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,53 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type bucketAutomationRule20240620193512 struct {
+	Action      string  `json:"action"`
+	UserIDs     []int64 `json:"user_ids,omitempty"`
+	LabelIDs    []int64 `json:"label_ids,omitempty"`
+	Priority    int64   `json:"priority,omitempty"`
+	PercentDone float64 `json:"percent_done,omitempty"`
+	DueAfter    int64   `json:"due_after,omitempty"`
+	Comment     string  `json:"comment,omitempty"`
+}
+
+type bucket20240620193512 struct {
+	AutomationRules []*bucketAutomationRule20240620193512 `xorm:"json null"`
+}
+
+func (bucket20240620193512) TableName() string {
+	return "buckets"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20240620193512",
+		Description: "Add automation rules to buckets",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(bucket20240620193512{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,74 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type projectAutomations20240624181047 struct {
+	ID              int64                    `xorm:"bigint autoincr not null unique pk"`
+	ProjectID       int64                    `xorm:"bigint not null index"`
+	Title           string                   `xorm:"varchar(250) not null"`
+	Disabled        bool                     `xorm:"not null default false"`
+	TriggerEvent    string                   `xorm:"varchar(250) null"`
+	TriggerSchedule string                   `xorm:"varchar(250) null"`
+	Filter          string                   `xorm:"text null"`
+	FilterTimezone  string                   `xorm:"varchar(250) null"`
+	Actions         []map[string]interface{} `xorm:"json not null"`
+	LastRunAt       time.Time                `xorm:"DATETIME null"`
+	CreatedByID     int64                    `xorm:"bigint not null"`
+	Created         time.Time                `xorm:"created not null"`
+	Updated         time.Time                `xorm:"updated not null"`
+}
+
+func (projectAutomations20240624181047) TableName() string {
+	return "project_automations"
+}
+
+type projectAutomationLogs20240624181047 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk"`
+	AutomationID int64     `xorm:"bigint not null index"`
+	TaskID       int64     `xorm:"bigint null"`
+	TriggeredBy  string    `xorm:"varchar(250) not null"`
+	Status       string    `xorm:"varchar(50) not null"`
+	Message      string    `xorm:"text null"`
+	Created      time.Time `xorm:"created not null"`
+}
+
+func (projectAutomationLogs20240624181047) TableName() string {
+	return "project_automation_logs"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20240624181047",
+		Description: "Add project automations",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(
+				projectAutomations20240624181047{},
+				projectAutomationLogs20240624181047{},
+			)
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
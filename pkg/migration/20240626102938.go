@@ -0,0 +1,55 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type taskChecklistItems20240626102938 struct {
+	ID          int64     `xorm:"autoincr pk unique not null"`
+	TaskID      int64     `xorm:"bigint not null INDEX"`
+	Title       string    `xorm:"text not null"`
+	Done        bool      `xorm:"null"`
+	DoneAt      time.Time `xorm:"null 'done_at'"`
+	Position    float64   `xorm:"double null"`
+	AssigneeID  int64     `xorm:"bigint null"`
+	DueDate     time.Time `xorm:"DATETIME null 'due_date'"`
+	CreatedByID int64     `xorm:"bigint not null"`
+	Created     time.Time `xorm:"created not null"`
+	Updated     time.Time `xorm:"updated not null"`
+}
+
+func (taskChecklistItems20240626102938) TableName() string {
+	return "task_checklist_items"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20240626102938",
+		Description: "Add task checklist items",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(taskChecklistItems20240626102938{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(taskChecklistItems20240626102938{})
+		},
+	})
+}
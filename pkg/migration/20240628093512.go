@@ -0,0 +1,71 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type migrationStatus20240628093512 struct {
+	ProjectsTotal    int64  `xorm:"bigint not null default 0"`
+	ProjectsDone     int64  `xorm:"bigint not null default 0"`
+	TasksTotal       int64  `xorm:"bigint not null default 0"`
+	TasksDone        int64  `xorm:"bigint not null default 0"`
+	AttachmentsTotal int64  `xorm:"bigint not null default 0"`
+	AttachmentsDone  int64  `xorm:"bigint not null default 0"`
+	FailedItems      int64  `xorm:"bigint not null default 0"`
+	SkippedItems     int64  `xorm:"bigint not null default 0"`
+	Error            string `xorm:"text null"`
+}
+
+func (migrationStatus20240628093512) TableName() string {
+	return "migration_status"
+}
+
+type migrationItems20240628093512 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk"`
+	StatusID     int64     `xorm:"bigint not null INDEX"`
+	UserID       int64     `xorm:"bigint not null INDEX"`
+	MigratorName string    `xorm:"varchar(255) not null"`
+	Kind         string    `xorm:"varchar(50) not null"`
+	SourceID     string    `xorm:"varchar(250) null"`
+	TargetID     int64     `xorm:"bigint null"`
+	State        string    `xorm:"varchar(20) not null"`
+	Title        string    `xorm:"text null"`
+	Message      string    `xorm:"text null"`
+	Created      time.Time `xorm:"created not null"`
+}
+
+func (migrationItems20240628093512) TableName() string {
+	return "migration_items"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20240628093512",
+		Description: "Add migration progress and migration items",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(migrationStatus20240628093512{}, migrationItems20240628093512{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
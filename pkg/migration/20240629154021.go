@@ -0,0 +1,69 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type migrationSyncs20240629154021 struct {
+	ID           int64     `xorm:"bigint autoincr not null unique pk"`
+	UserID       int64     `xorm:"bigint not null INDEX"`
+	MigratorName string    `xorm:"varchar(255) not null"`
+	AccessToken  string    `xorm:"text null"`
+	RefreshToken string    `xorm:"text null"`
+	TokenExpiry  time.Time `xorm:"null"`
+	LastSyncAt   time.Time `xorm:"null"`
+	LastError    string    `xorm:"text null"`
+	Created      time.Time `xorm:"created not null"`
+	Updated      time.Time `xorm:"updated not null"`
+}
+
+func (migrationSyncs20240629154021) TableName() string {
+	return "migration_syncs"
+}
+
+type migrationSyncMappings20240629154021 struct {
+	ID       int64     `xorm:"bigint autoincr not null unique pk"`
+	SyncID   int64     `xorm:"bigint not null INDEX"`
+	Kind     string    `xorm:"varchar(50) not null"`
+	SourceID string    `xorm:"varchar(250) not null"`
+	TargetID int64     `xorm:"bigint not null"`
+	Hash     string    `xorm:"varchar(64) null"`
+	Created  time.Time `xorm:"created not null"`
+	Updated  time.Time `xorm:"updated not null"`
+}
+
+func (migrationSyncMappings20240629154021) TableName() string {
+	return "migration_sync_mappings"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20240629154021",
+		Description: "Add migration syncs",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(migrationSyncs20240629154021{}, migrationSyncMappings20240629154021{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(migrationSyncs20240629154021{}, migrationSyncMappings20240629154021{})
+		},
+	})
+}
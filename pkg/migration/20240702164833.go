@@ -0,0 +1,69 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type userExportSchedules20240702164833 struct {
+	ID             int64     `xorm:"bigint autoincr not null unique pk"`
+	UserID         int64     `xorm:"bigint not null unique"`
+	Interval       string    `xorm:"varchar(20) not null"`
+	RetentionCount int64     `xorm:"bigint not null default 7"`
+	SendEmail      bool      `xorm:"not null default false"`
+	WebdavURL      string    `xorm:"text null"`
+	WebdavUsername string    `xorm:"text null"`
+	WebdavPassword string    `xorm:"text null"`
+	LastRunAt      time.Time `xorm:"null"`
+	NextRunAt      time.Time `xorm:"not null INDEX"`
+	LastError      string    `xorm:"text null"`
+	Created        time.Time `xorm:"created not null"`
+	Updated        time.Time `xorm:"updated not null"`
+}
+
+func (userExportSchedules20240702164833) TableName() string {
+	return "user_export_schedules"
+}
+
+type userExportBackups20240702164833 struct {
+	ID      int64     `xorm:"bigint autoincr not null unique pk"`
+	UserID  int64     `xorm:"bigint not null INDEX"`
+	FileID  int64     `xorm:"bigint not null"`
+	Size    uint64    `xorm:"bigint not null"`
+	Created time.Time `xorm:"created not null"`
+}
+
+func (userExportBackups20240702164833) TableName() string {
+	return "user_export_backups"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20240702164833",
+		Description: "Add user export schedules and backups",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(userExportSchedules20240702164833{}, userExportBackups20240702164833{})
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(userExportSchedules20240702164833{}, userExportBackups20240702164833{})
+		},
+	})
+}
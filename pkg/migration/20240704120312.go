@@ -0,0 +1,91 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type userWebauthnCredentials20240704120312 struct {
+	ID              int64      `xorm:"bigint autoincr not null unique pk"`
+	UserID          int64      `xorm:"bigint not null INDEX"`
+	Name            string     `xorm:"varchar(250) not null"`
+	CredentialID    []byte     `xorm:"blob not null"`
+	PublicKey       []byte     `xorm:"blob not null"`
+	AttestationType string     `xorm:"varchar(50) null"`
+	Transports      []string   `xorm:"json null"`
+	AAGUID          []byte     `xorm:"blob null"`
+	SignCount       uint32     `xorm:"bigint not null default 0"`
+	CloneWarning    bool       `xorm:"not null default false"`
+	BackupEligible  bool       `xorm:"not null default false"`
+	BackupState     bool       `xorm:"not null default false"`
+	LastUsed        *time.Time `xorm:"null"`
+	Created         time.Time  `xorm:"created not null"`
+}
+
+func (userWebauthnCredentials20240704120312) TableName() string {
+	return "user_webauthn_credentials"
+}
+
+type userRecoveryCodes20240704120312 struct {
+	ID       int64     `xorm:"bigint autoincr not null unique pk"`
+	UserID   int64     `xorm:"bigint not null INDEX"`
+	CodeHash string    `xorm:"varchar(64) not null"`
+	Created  time.Time `xorm:"created not null"`
+}
+
+func (userRecoveryCodes20240704120312) TableName() string {
+	return "user_recovery_codes"
+}
+
+type userAuditLog20240704120312 struct {
+	ID        int64     `xorm:"bigint autoincr not null unique pk"`
+	UserID    int64     `xorm:"bigint not null INDEX"`
+	Action    string    `xorm:"varchar(100) not null"`
+	Details   string    `xorm:"text null"`
+	IPAddress string    `xorm:"varchar(100) null"`
+	UserAgent string    `xorm:"text null"`
+	Created   time.Time `xorm:"created not null INDEX"`
+}
+
+func (userAuditLog20240704120312) TableName() string {
+	return "user_audit_log"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20240704120312",
+		Description: "Add webauthn credentials, recovery codes and the user audit log",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(
+				userWebauthnCredentials20240704120312{},
+				userRecoveryCodes20240704120312{},
+				userAuditLog20240704120312{},
+			)
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return tx.DropTables(
+				userWebauthnCredentials20240704120312{},
+				userRecoveryCodes20240704120312{},
+				userAuditLog20240704120312{},
+			)
+		},
+	})
+}
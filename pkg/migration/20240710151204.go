@@ -0,0 +1,107 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type oauthClients20240710151204 struct {
+	ID               int64     `xorm:"bigint autoincr not null unique pk"`
+	ClientID         string    `xorm:"varchar(50) not null unique"`
+	ClientSecretHash string    `xorm:"varchar(64) null"`
+	Name             string    `xorm:"varchar(250) not null"`
+	RedirectURIs     []string  `xorm:"json not null 'redirect_uris'"`
+	Public           bool      `xorm:"bool default false"`
+	OwnerID          int64     `xorm:"bigint not null index"`
+	Created          time.Time `xorm:"created not null"`
+	Updated          time.Time `xorm:"updated not null"`
+}
+
+func (oauthClients20240710151204) TableName() string {
+	return "oauth_clients"
+}
+
+type oauthAuthorizations20240710151204 struct {
+	ID       int64               `xorm:"bigint autoincr not null unique pk"`
+	UserID   int64               `xorm:"bigint not null index"`
+	ClientID int64               `xorm:"bigint not null index"`
+	Scopes   map[string][]string `xorm:"json not null"`
+	Created  time.Time           `xorm:"created not null"`
+	Updated  time.Time           `xorm:"updated not null"`
+}
+
+func (oauthAuthorizations20240710151204) TableName() string {
+	return "oauth_authorizations"
+}
+
+type oauthAuthorizationCodes20240710151204 struct {
+	ID              int64               `xorm:"bigint autoincr not null unique pk"`
+	CodeHash        string              `xorm:"varchar(64) not null unique"`
+	AuthorizationID int64               `xorm:"bigint not null index"`
+	RedirectURI     string              `xorm:"text not null"`
+	CodeChallenge   string              `xorm:"varchar(128) not null"`
+	Scopes          map[string][]string `xorm:"json not null"`
+	ExpiresAt       time.Time           `xorm:"not null"`
+	Created         time.Time           `xorm:"created not null"`
+}
+
+func (oauthAuthorizationCodes20240710151204) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+type oauthRefreshTokens20240710151204 struct {
+	ID              int64               `xorm:"bigint autoincr not null unique pk"`
+	TokenHash       string              `xorm:"varchar(64) not null unique"`
+	AuthorizationID int64               `xorm:"bigint not null index"`
+	Scopes          map[string][]string `xorm:"json not null"`
+	Created         time.Time           `xorm:"created not null"`
+}
+
+func (oauthRefreshTokens20240710151204) TableName() string {
+	return "oauth_refresh_tokens"
+}
+
+type apiTokens20240710151204 struct {
+	OAuthAuthorizationID int64 `xorm:"bigint null index 'oauth_authorization_id'"`
+}
+
+func (apiTokens20240710151204) TableName() string {
+	return "api_tokens"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20240710151204",
+		Description: "Add oauth clients, authorizations and tokens",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(
+				oauthClients20240710151204{},
+				oauthAuthorizations20240710151204{},
+				oauthAuthorizationCodes20240710151204{},
+				oauthRefreshTokens20240710151204{},
+				apiTokens20240710151204{},
+			)
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
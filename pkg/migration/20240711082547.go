@@ -0,0 +1,63 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type linkShares20240711082547 struct {
+	ExpiresAt  time.Time `xorm:"DATETIME null INDEX 'expires_at'"`
+	MaxUses    int64     `xorm:"bigint not null default 0"`
+	Uses       int64     `xorm:"bigint not null default 0"`
+	AllowedIPs []string  `xorm:"json null 'allowed_ips'"`
+}
+
+func (linkShares20240711082547) TableName() string {
+	return "link_shares"
+}
+
+type linkShareAccessLog20240711082547 struct {
+	ID          int64     `xorm:"bigint autoincr not null unique pk"`
+	LinkShareID int64     `xorm:"bigint not null INDEX"`
+	IPAddress   string    `xorm:"varchar(100) null"`
+	UserAgent   string    `xorm:"text null"`
+	Created     time.Time `xorm:"created not null INDEX"`
+}
+
+func (linkShareAccessLog20240711082547) TableName() string {
+	return "link_share_access_log"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20240711082547",
+		Description: "Add expiry, usage limits, ip allowlist and an access log to link shares",
+		Migrate: func(tx *xorm.Engine) error {
+			return tx.Sync2(
+				linkShares20240711082547{},
+				linkShareAccessLog20240711082547{},
+			)
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -0,0 +1,89 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"time"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+)
+
+type projectView20240715093042 struct {
+	ID        int64     `xorm:"autoincr not null unique pk" json:"id" param:"view"`
+	Title     string    `xorm:"varchar(255) not null" json:"title" valid:"runelength(1|250)"`
+	ProjectID int64     `xorm:"not null index" json:"project_id" param:"project"`
+	ViewKind  int       `xorm:"not null" json:"view_kind"`
+	Position  float64   `xorm:"double null" json:"position"`
+	Updated   time.Time `xorm:"updated not null" json:"updated"`
+	Created   time.Time `xorm:"created not null" json:"created"`
+}
+
+func (projectView20240715093042) TableName() string {
+	return "project_views"
+}
+
+type filters20240715093042 struct {
+	ID int64 `xorm:"autoincr not null unique pk" json:"id" param:"view"`
+}
+
+func (filters20240715093042) TableName() string {
+	return "saved_filters"
+}
+
+func init() {
+	migrations = append(migrations, &xormigrate.Migration{
+		ID:          "20240715093042",
+		Description: "Add a calendar view to all saved filters",
+		Migrate: func(tx *xorm.Engine) error {
+			filters := []*filters20240715093042{}
+			err := tx.Find(&filters)
+			if err != nil {
+				return err
+			}
+
+			for _, filter := range filters {
+				projectID := filter.ID*-1 - 1
+
+				exists, err := tx.
+					Where("project_id = ? AND view_kind = ?", projectID, 4).
+					Exist(&projectView20240715093042{})
+				if err != nil {
+					return err
+				}
+				if exists {
+					continue
+				}
+
+				_, err = tx.Insert(&projectView20240715093042{
+					Title:     "Calendar",
+					ProjectID: projectID,
+					ViewKind:  4, // Calendar view
+					Position:  500,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+		Rollback: func(tx *xorm.Engine) error {
+			return nil
+		},
+	})
+}
@@ -25,6 +25,7 @@ import (
 	"code.vikunja.io/api/pkg/files"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/export"
 	"code.vikunja.io/api/pkg/modules/migration"
 	"code.vikunja.io/api/pkg/notifications"
 	"code.vikunja.io/api/pkg/user"
@@ -97,6 +98,16 @@ func ListMigrations() {
 	table.Render()
 }
 
+// GetMigrationIDs returns the ids of all migrations known to this version of Vikunja in the order they are executed.
+func GetMigrationIDs() []string {
+	ids := make([]string, 0, len(migrations))
+	for _, m := range migrations {
+		ids = append(ids, m.ID)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 // Rollback rolls back all migrations until a certain point.
 func Rollback(migrationID string) {
 	m := initMigration(nil)
@@ -184,6 +195,7 @@ func initSchema(tx *xorm.Engine) error {
 	schemeBeans = append(schemeBeans, models.GetTables()...)
 	schemeBeans = append(schemeBeans, files.GetTables()...)
 	schemeBeans = append(schemeBeans, migration.GetTables()...)
+	schemeBeans = append(schemeBeans, export.GetTables()...)
 	schemeBeans = append(schemeBeans, user.GetTables()...)
 	schemeBeans = append(schemeBeans, notifications.GetTables()...)
 	return tx.Sync2(schemeBeans...)
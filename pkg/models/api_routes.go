@@ -124,8 +124,15 @@ func CollectRoutesForAPITokenUsage(route echo.Route, middlewares []echo.Middlewa
 		routeGroupName == "tokenTest" ||
 		routeGroupName == "subscriptions" ||
 		routeGroupName == "tokens" ||
+		routeGroupName == "service-accounts" ||
+		routeGroupName == "oauth" ||
+		routeGroupName == "admin" ||
 		routeGroupName == "*" ||
-		strings.HasPrefix(routeGroupName, "user_") {
+		strings.HasPrefix(routeGroupName, "user_") ||
+		strings.HasPrefix(routeGroupName, "tokens_") ||
+		strings.HasPrefix(routeGroupName, "service-accounts_") ||
+		strings.HasPrefix(routeGroupName, "oauth_") ||
+		strings.HasPrefix(routeGroupName, "admin_") {
 		return
 	}
 
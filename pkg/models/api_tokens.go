@@ -25,6 +25,7 @@ import (
 	"xorm.io/builder"
 
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/api/pkg/utils"
 
 	"code.vikunja.io/web"
@@ -49,11 +50,22 @@ type APIToken struct {
 	Permissions APIPermissions `xorm:"json not null" json:"permissions" valid:"required"`
 	// The date when this key expires.
 	ExpiresAt time.Time `xorm:"not null" json:"expires_at" valid:"required"`
+	// The ids of the projects this token is limited to. The token can access these projects and all their child projects, but no other ones. If empty, the token can access all projects of its owner. A token limited to projects cannot change labels, teams or saved filters.
+	ProjectIDs []int64 `xorm:"json null" json:"project_ids"`
+	// If true, the token can only read projects, tasks, labels, teams and saved filters, even if the permissions allow changing them.
+	ReadOnly bool `xorm:"bool default false" json:"read_only"`
+
+	// When this token was last used to make a request.
+	LastUsed time.Time `xorm:"datetime null" json:"last_used"`
+	// The ip address of the last request made with this token.
+	LastUsedIP string `xorm:"varchar(100) null" json:"last_used_ip"`
 
 	// A timestamp when this api key was created. You cannot change this value.
 	Created time.Time `xorm:"created not null" json:"created"`
 
 	OwnerID int64 `xorm:"bigint not null" json:"-"`
+	// Set for access tokens issued to an oauth client. These are managed through the authorized apps of a user.
+	OAuthAuthorizationID int64 `xorm:"bigint null index 'oauth_authorization_id'" json:"-"`
 
 	web.Rights   `xorm:"-" json:"-"`
 	web.CRUDable `xorm:"-" json:"-"`
@@ -86,7 +98,38 @@ func GetAPITokenByID(s *xorm.Session, id int64) (token *APIToken, err error) {
 // @Router /tokens [put]
 func (t *APIToken) Create(s *xorm.Session, a web.Auth) (err error) {
 	t.ID = 0
+	t.LastUsed = time.Time{}
+	t.LastUsedIP = ""
+
+	err = t.generateToken()
+	if err != nil {
+		return err
+	}
+
+	t.OwnerID = a.GetID()
+
+	if err := PermissionsAreValid(t.Permissions); err != nil {
+		return err
+	}
+
+	// The owner needs to have access to all projects the token is limited to
+	for _, projectID := range t.ProjectIDs {
+		project := &Project{ID: projectID}
+		can, _, err := project.CanRead(s, a)
+		if err != nil {
+			return err
+		}
+		if !can {
+			return ErrUserDoesNotHaveAccessToProject{ProjectID: projectID, UserID: a.GetID()}
+		}
+	}
+
+	_, err = s.Insert(t)
+	return err
+}
 
+// generateToken creates a new random token string with its hash.
+func (t *APIToken) generateToken() error {
 	salt, err := utils.CryptoRandomString(10)
 	if err != nil {
 		return err
@@ -99,15 +142,50 @@ func (t *APIToken) Create(s *xorm.Session, a web.Auth) (err error) {
 	t.Token = APITokenPrefix + hex.EncodeToString(token)
 	t.TokenHash = HashToken(t.Token, t.TokenSalt)
 	t.TokenLastEight = t.Token[len(t.Token)-8:]
+	return nil
+}
 
-	t.OwnerID = a.GetID()
-
-	if err := PermissionsAreValid(t.Permissions); err != nil {
+// Rotate replaces the token string of an api token with a new one. The old token string stops working
+// immediately, everything else about the token like its permissions and projects stays the same.
+func (t *APIToken) Rotate(s *xorm.Session) (err error) {
+	err = t.generateToken()
+	if err != nil {
 		return err
 	}
 
-	_, err = s.Insert(t)
-	return err
+	_, err = s.
+		Where("id = ?", t.ID).
+		Cols("token_salt", "token_hash", "token_last_eight").
+		Update(t)
+	return
+}
+
+// UpdateLastUsed saves when and from where a token was last used. To avoid writing to the database
+// on every request, this only happens once per minute.
+func (t *APIToken) UpdateLastUsed(s *xorm.Session, ipAddress string) (err error) {
+	if time.Since(t.LastUsed) < time.Minute && t.LastUsedIP == ipAddress {
+		return nil
+	}
+
+	t.LastUsed = time.Now()
+	t.LastUsedIP = ipAddress
+	_, err = s.
+		Where("id = ?", t.ID).
+		Cols("last_used", "last_used_ip").
+		Update(t)
+	return
+}
+
+// Scope returns what the token is limited to or nil if it can access everything its owner can.
+func (t *APIToken) Scope() *user.APITokenScope {
+	if len(t.ProjectIDs) == 0 && !t.ReadOnly {
+		return nil
+	}
+
+	return &user.APITokenScope{
+		ProjectIDs: t.ProjectIDs,
+		ReadOnly:   t.ReadOnly,
+	}
 }
 
 func HashToken(token, salt string) string {
@@ -132,7 +210,13 @@ func (t *APIToken) ReadAll(s *xorm.Session, a web.Auth, search string, page int,
 
 	tokens := []*APIToken{}
 
-	var where builder.Cond = builder.Eq{"owner_id": a.GetID()}
+	var where builder.Cond = builder.And(
+		builder.Eq{"owner_id": a.GetID()},
+		builder.Or(
+			builder.IsNull{"oauth_authorization_id"},
+			builder.Eq{"oauth_authorization_id": 0},
+		),
+	)
 
 	if search != "" {
 		where = builder.And(
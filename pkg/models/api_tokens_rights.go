@@ -17,6 +17,9 @@
 package models
 
 import (
+	"slices"
+
+	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web"
 	"xorm.io/xorm"
 )
@@ -35,6 +38,99 @@ func (t *APIToken) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
 	return true, nil
 }
 
-func (t *APIToken) CanCreate(_ *xorm.Session, _ web.Auth) (bool, error) {
-	return true, nil
+// CanRotate checks if the user can rotate a token. Only the owner of a token can do that.
+func (t *APIToken) CanRotate(s *xorm.Session, a web.Auth) (bool, error) {
+	return t.CanDelete(s, a)
+}
+
+func (t *APIToken) CanCreate(_ *xorm.Session, a web.Auth) (bool, error) {
+	// A limited token must not be able to create a token without these limits
+	return getAPITokenScope(a) == nil, nil
+}
+
+// getAPITokenScope returns the limits of the api token the current request was made with.
+// Returns nil if the request was not made with an api token or the token is not limited.
+func getAPITokenScope(a web.Auth) *user.APITokenScope {
+	u, is := a.(*user.User)
+	if !is {
+		return nil
+	}
+	return u.APITokenScope
+}
+
+// apiTokenCanAccessProject checks if the api token the current request was made with allows accessing a project.
+// Tokens limited to some projects can access these projects and all of their children.
+func apiTokenCanAccessProject(s *xorm.Session, a web.Auth, projectID int64, write bool) (bool, error) {
+	scope := getAPITokenScope(a)
+	if scope == nil {
+		return true, nil
+	}
+
+	if write && scope.ReadOnly {
+		return false, nil
+	}
+
+	if len(scope.ProjectIDs) == 0 {
+		return true, nil
+	}
+
+	parents, err := GetAllParentProjects(s, projectID)
+	if err != nil {
+		return false, err
+	}
+
+	for id := range parents {
+		if slices.Contains(scope.ProjectIDs, id) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// apiTokenCanChangeUnscoped checks if the api token the current request was made with allows changing things which
+// don't belong to a single project, like labels, teams or saved filters. Only tokens without any limits can do that.
+func apiTokenCanChangeUnscoped(a web.Auth) bool {
+	return getAPITokenScope(a) == nil
+}
+
+// apiTokenMaxRight limits the right a user has on a project to read if the request was made with a read-only token.
+func apiTokenMaxRight(a web.Auth, right Right) int {
+	if scope := getAPITokenScope(a); scope != nil && scope.ReadOnly {
+		return int(RightRead)
+	}
+	return int(right)
+}
+
+// filterProjectsForAPITokenScope removes all projects from a list which are not covered by the project scope of a token.
+// The list needs to contain the parents of all projects, like the list of all projects of a user.
+func filterProjectsForAPITokenScope(scope *user.APITokenScope, projects []*Project) []*Project {
+	if scope == nil || len(scope.ProjectIDs) == 0 {
+		return projects
+	}
+
+	projectMap := make(map[int64]*Project, len(projects))
+	for _, p := range projects {
+		projectMap[p.ID] = p
+	}
+
+	var inScope func(p *Project, depth int) bool
+	inScope = func(p *Project, depth int) bool {
+		if slices.Contains(scope.ProjectIDs, p.ID) {
+			return true
+		}
+		parent, has := projectMap[p.ParentProjectID]
+		if !has || depth > len(projects) {
+			return false
+		}
+		return inScope(parent, depth+1)
+	}
+
+	filtered := make([]*Project, 0, len(projects))
+	for _, p := range projects {
+		if inScope(p, 0) {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
 }
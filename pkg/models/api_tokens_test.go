@@ -93,6 +93,31 @@ func TestAPIToken_Create(t *testing.T) {
 		err := token.Create(s, u)
 		require.NoError(t, err)
 	})
+	t.Run("limited to projects", func(t *testing.T) {
+		u := &user.User{ID: 1}
+		token := &APIToken{ProjectIDs: []int64{1}, ReadOnly: true}
+		s := db.NewSession()
+		defer s.Close()
+		db.LoadAndAssertFixtures(t)
+
+		err := token.Create(s, u)
+		require.NoError(t, err)
+		db.AssertExists(t, "api_tokens", map[string]interface{}{
+			"id":        token.ID,
+			"read_only": true,
+		}, false)
+	})
+	t.Run("project without access", func(t *testing.T) {
+		u := &user.User{ID: 1}
+		token := &APIToken{ProjectIDs: []int64{2}}
+		s := db.NewSession()
+		defer s.Close()
+		db.LoadAndAssertFixtures(t)
+
+		err := token.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrUserDoesNotHaveAccessToProject(err))
+	})
 }
 
 func TestAPIToken_GetTokenFromTokenString(t *testing.T) {
@@ -117,3 +142,171 @@ func TestAPIToken_GetTokenFromTokenString(t *testing.T) {
 		assert.True(t, IsErrAPITokenInvalid(err))
 	})
 }
+
+func TestAPIToken_Scope(t *testing.T) {
+	t.Run("limited to a project", func(t *testing.T) {
+		u := &user.User{ID: 1, APITokenScope: &user.APITokenScope{ProjectIDs: []int64{22}}}
+		s := db.NewSession()
+		defer s.Close()
+		db.LoadAndAssertFixtures(t)
+
+		can, _, err := (&Project{ID: 22}).CanRead(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		// Child project
+		can, _, err = (&Project{ID: 21}).CanRead(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		can, _, err = (&Project{ID: 1}).CanRead(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&Project{ID: 1}).CanWrite(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		// Favorites pseudo project
+		can, _, err = (&Project{ID: FavoritesPseudoProjectID}).CanRead(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("read only", func(t *testing.T) {
+		u := &user.User{ID: 1, APITokenScope: &user.APITokenScope{ReadOnly: true}}
+		s := db.NewSession()
+		defer s.Close()
+		db.LoadAndAssertFixtures(t)
+
+		can, maxRight, err := (&Project{ID: 1}).CanRead(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		assert.Equal(t, int(RightRead), maxRight)
+		can, err = (&Project{ID: 1}).CanWrite(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&Task{ID: 1}).CanUpdate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("limited token cannot create tokens", func(t *testing.T) {
+		u := &user.User{ID: 1, APITokenScope: &user.APITokenScope{ReadOnly: true}}
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&APIToken{}).CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("read only token cannot change labels", func(t *testing.T) {
+		u := &user.User{ID: 1, APITokenScope: &user.APITokenScope{ReadOnly: true}}
+		s := db.NewSession()
+		defer s.Close()
+		db.LoadAndAssertFixtures(t)
+
+		can, err := (&Label{}).CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&Label{ID: 1}).CanUpdate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&Label{ID: 1}).CanDelete(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("read only token cannot change teams", func(t *testing.T) {
+		u := &user.User{ID: 1, APITokenScope: &user.APITokenScope{ReadOnly: true}}
+		s := db.NewSession()
+		defer s.Close()
+		db.LoadAndAssertFixtures(t)
+
+		can, err := (&Team{}).CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&Team{ID: 1}).CanUpdate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&Team{ID: 1}).CanDelete(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("read only token cannot change team members", func(t *testing.T) {
+		u := &user.User{ID: 1, APITokenScope: &user.APITokenScope{ReadOnly: true}}
+		s := db.NewSession()
+		defer s.Close()
+		db.LoadAndAssertFixtures(t)
+
+		can, err := (&TeamMember{TeamID: 1, Username: "user2"}).CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&TeamMember{TeamID: 1, Username: "user1"}).CanUpdate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&TeamMember{TeamID: 1, Username: "user1"}).CanDelete(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("read only token cannot change saved filters", func(t *testing.T) {
+		u := &user.User{ID: 1, APITokenScope: &user.APITokenScope{ReadOnly: true}}
+		s := db.NewSession()
+		defer s.Close()
+		db.LoadAndAssertFixtures(t)
+
+		can, err := (&SavedFilter{}).CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&SavedFilter{ID: 1}).CanUpdate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&SavedFilter{ID: 1}).CanDelete(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		// Reading is still allowed
+		can, _, err = (&SavedFilter{ID: 1}).CanRead(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+	})
+	t.Run("project limited token cannot change unscoped entities", func(t *testing.T) {
+		u := &user.User{ID: 1, APITokenScope: &user.APITokenScope{ProjectIDs: []int64{1}}}
+		s := db.NewSession()
+		defer s.Close()
+		db.LoadAndAssertFixtures(t)
+
+		can, err := (&Label{}).CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&Team{}).CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&SavedFilter{}).CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("unlimited token can change labels", func(t *testing.T) {
+		u := &user.User{ID: 1}
+		s := db.NewSession()
+		defer s.Close()
+		db.LoadAndAssertFixtures(t)
+
+		can, err := (&Label{ID: 1}).CanUpdate(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+	})
+}
+
+func TestAPIToken_Rotate(t *testing.T) {
+	s := db.NewSession()
+	defer s.Close()
+	db.LoadAndAssertFixtures(t)
+
+	token, err := GetAPITokenByID(s, 1)
+	require.NoError(t, err)
+	err = token.Rotate(s)
+	require.NoError(t, err)
+	assert.NotEmpty(t, token.Token)
+
+	_, err = GetTokenFromTokenString(s, "tk_2eef46f40ebab3304919ab2e7e39993f75f29d2e")
+	require.Error(t, err)
+	assert.True(t, IsErrAPITokenInvalid(err))
+
+	rotated, err := GetTokenFromTokenString(s, token.Token)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), rotated.ID)
+	assert.Equal(t, token.Permissions, rotated.Permissions)
+}
@@ -1133,6 +1133,87 @@ func (err ErrMustHaveProjectViewToSortByPosition) HTTPError() web.HTTPError {
 	}
 }
 
+// ErrInvalidCalendarDateRange represents an error where the date range for a calendar view is missing or invalid
+type ErrInvalidCalendarDateRange struct {
+	DateFrom string
+	DateTo   string
+}
+
+// IsErrInvalidCalendarDateRange checks if an error is ErrInvalidCalendarDateRange.
+func IsErrInvalidCalendarDateRange(err error) bool {
+	_, ok := err.(*ErrInvalidCalendarDateRange)
+	return ok
+}
+
+func (err *ErrInvalidCalendarDateRange) Error() string {
+	return fmt.Sprintf("Calendar date range is invalid [DateFrom: %s, DateTo: %s]", err.DateFrom, err.DateTo)
+}
+
+// ErrCodeInvalidCalendarDateRange holds the unique world-error code of this error
+const ErrCodeInvalidCalendarDateRange = 4027
+
+// HTTPError holds the http error description
+func (err *ErrInvalidCalendarDateRange) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidCalendarDateRange,
+		Message:  "You must provide a valid date_from and date_to when requesting the tasks of a calendar view. date_from must not be after date_to.",
+	}
+}
+
+// ErrTaskChecklistItemDoesNotExist represents an error where a checklist item does not exist
+type ErrTaskChecklistItemDoesNotExist struct {
+	ID     int64
+	TaskID int64
+}
+
+// IsErrTaskChecklistItemDoesNotExist checks if an error is ErrTaskChecklistItemDoesNotExist.
+func IsErrTaskChecklistItemDoesNotExist(err error) bool {
+	_, ok := err.(*ErrTaskChecklistItemDoesNotExist)
+	return ok
+}
+
+func (err *ErrTaskChecklistItemDoesNotExist) Error() string {
+	return fmt.Sprintf("Task checklist item does not exist [ID: %d, TaskID: %d]", err.ID, err.TaskID)
+}
+
+// ErrCodeTaskChecklistItemDoesNotExist holds the unique world-error code of this error
+const ErrCodeTaskChecklistItemDoesNotExist = 4028
+
+// HTTPError holds the http error description
+func (err *ErrTaskChecklistItemDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeTaskChecklistItemDoesNotExist,
+		Message:  "This checklist item does not exist.",
+	}
+}
+
+// ErrTaskChecklistItemTitleCannotBeEmpty represents an error where a checklist item has no title
+type ErrTaskChecklistItemTitleCannotBeEmpty struct{}
+
+// IsErrTaskChecklistItemTitleCannotBeEmpty checks if an error is ErrTaskChecklistItemTitleCannotBeEmpty.
+func IsErrTaskChecklistItemTitleCannotBeEmpty(err error) bool {
+	_, ok := err.(*ErrTaskChecklistItemTitleCannotBeEmpty)
+	return ok
+}
+
+func (err *ErrTaskChecklistItemTitleCannotBeEmpty) Error() string {
+	return "Task checklist item title cannot be empty"
+}
+
+// ErrCodeTaskChecklistItemTitleCannotBeEmpty holds the unique world-error code of this error
+const ErrCodeTaskChecklistItemTitleCannotBeEmpty = 4029
+
+// HTTPError holds the http error description
+func (err *ErrTaskChecklistItemTitleCannotBeEmpty) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeTaskChecklistItemTitleCannotBeEmpty,
+		Message:  "You must provide a title for the checklist item.",
+	}
+}
+
 // ============
 // Team errors
 // ============
@@ -1641,6 +1722,92 @@ func (err *ErrOnlyOneDoneBucketPerProject) HTTPError() web.HTTPError {
 	}
 }
 
+// ErrSwimlaneLimitExceeded represents an error where a task is moved to a bucket which already holds as many tasks of the task's swimlane as the swimlane allows.
+type ErrSwimlaneLimitExceeded struct {
+	SwimlaneID int64
+	BucketID   int64
+	Limit      int64
+	TaskID     int64
+}
+
+// IsErrSwimlaneLimitExceeded checks if an error is ErrSwimlaneLimitExceeded.
+func IsErrSwimlaneLimitExceeded(err error) bool {
+	_, ok := err.(*ErrSwimlaneLimitExceeded)
+	return ok
+}
+
+func (err *ErrSwimlaneLimitExceeded) Error() string {
+	return fmt.Sprintf("Cannot add a task to this bucket because it would exceed the limit of its swimlane [SwimlaneID: %d, BucketID: %d, Limit: %d, TaskID: %d]", err.SwimlaneID, err.BucketID, err.Limit, err.TaskID)
+}
+
+// ErrCodeSwimlaneLimitExceeded holds the unique world-error code of this error
+const ErrCodeSwimlaneLimitExceeded = 10006
+
+// HTTPError holds the http error description
+func (err *ErrSwimlaneLimitExceeded) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeSwimlaneLimitExceeded,
+		Message:  "You cannot add the task to this bucket as its swimlane already exceeded the limit of tasks it can hold in this bucket.",
+	}
+}
+
+// ErrInvalidBucketAutomationRule represents an error where a bucket automation rule is invalid
+type ErrInvalidBucketAutomationRule struct {
+	Action BucketAutomationAction
+	Reason string
+}
+
+// IsErrInvalidBucketAutomationRule checks if an error is ErrInvalidBucketAutomationRule.
+func IsErrInvalidBucketAutomationRule(err error) bool {
+	_, ok := err.(*ErrInvalidBucketAutomationRule)
+	return ok
+}
+
+func (err *ErrInvalidBucketAutomationRule) Error() string {
+	return fmt.Sprintf("Bucket automation rule is invalid [Action: %s, Reason: %s]", err.Action, err.Reason)
+}
+
+// ErrCodeInvalidBucketAutomationRule holds the unique world-error code of this error
+const ErrCodeInvalidBucketAutomationRule = 10007
+
+// HTTPError holds the http error description
+func (err *ErrInvalidBucketAutomationRule) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidBucketAutomationRule,
+		Message:  "The bucket automation rule is invalid: " + err.Reason,
+	}
+}
+
+// ErrInvalidSwimlaneConfiguration represents an error where the swimlane configuration of a project view is invalid
+type ErrInvalidSwimlaneConfiguration struct {
+	Swimlane string
+	Reason   string
+}
+
+// IsErrInvalidSwimlaneConfiguration checks if an error is ErrInvalidSwimlaneConfiguration.
+func IsErrInvalidSwimlaneConfiguration(err error) bool {
+	_, ok := err.(*ErrInvalidSwimlaneConfiguration)
+	return ok
+}
+
+func (err *ErrInvalidSwimlaneConfiguration) Error() string {
+	return fmt.Sprintf("Swimlane configuration is invalid [Swimlane: %s, Reason: %s]", err.Swimlane, err.Reason)
+}
+
+// ErrCodeInvalidSwimlaneConfiguration holds the unique world-error code of this error
+const ErrCodeInvalidSwimlaneConfiguration = 10008
+
+// HTTPError holds the http error description
+func (err *ErrInvalidSwimlaneConfiguration) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidSwimlaneConfiguration,
+		Message:  "The swimlane configuration is invalid: " + err.Reason,
+	}
+}
+
 // =============
 // Saved Filters
 // =============
@@ -1844,6 +2011,116 @@ func (err ErrLinkShareTokenInvalid) HTTPError() web.HTTPError {
 	}
 }
 
+// ErrLinkShareExpired represents an error where a link share is used after its expiry date
+type ErrLinkShareExpired struct {
+	ShareID int64
+}
+
+// IsErrLinkShareExpired checks if an error is ErrLinkShareExpired.
+func IsErrLinkShareExpired(err error) bool {
+	_, ok := err.(*ErrLinkShareExpired)
+	return ok
+}
+
+func (err *ErrLinkShareExpired) Error() string {
+	return fmt.Sprintf("Link Share has expired [ShareID: %d]", err.ShareID)
+}
+
+// ErrCodeLinkShareExpired holds the unique world-error code of this error
+const ErrCodeLinkShareExpired = 13004
+
+// HTTPError holds the http error description
+func (err ErrLinkShareExpired) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeLinkShareExpired,
+		Message:  "This link share has expired.",
+	}
+}
+
+// ErrLinkShareMaxUsesReached represents an error where a link share was used as often as it is allowed to
+type ErrLinkShareMaxUsesReached struct {
+	ShareID int64
+	MaxUses int64
+}
+
+// IsErrLinkShareMaxUsesReached checks if an error is ErrLinkShareMaxUsesReached.
+func IsErrLinkShareMaxUsesReached(err error) bool {
+	_, ok := err.(*ErrLinkShareMaxUsesReached)
+	return ok
+}
+
+func (err *ErrLinkShareMaxUsesReached) Error() string {
+	return fmt.Sprintf("Link Share has reached its maximum number of uses [ShareID: %d, MaxUses: %d]", err.ShareID, err.MaxUses)
+}
+
+// ErrCodeLinkShareMaxUsesReached holds the unique world-error code of this error
+const ErrCodeLinkShareMaxUsesReached = 13005
+
+// HTTPError holds the http error description
+func (err ErrLinkShareMaxUsesReached) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeLinkShareMaxUsesReached,
+		Message:  "This link share has already been used the maximum number of times.",
+	}
+}
+
+// ErrLinkShareIPNotAllowed represents an error where a link share is used from an ip address which is not on its allowlist
+type ErrLinkShareIPNotAllowed struct {
+	ShareID int64
+	IP      string
+}
+
+// IsErrLinkShareIPNotAllowed checks if an error is ErrLinkShareIPNotAllowed.
+func IsErrLinkShareIPNotAllowed(err error) bool {
+	_, ok := err.(*ErrLinkShareIPNotAllowed)
+	return ok
+}
+
+func (err *ErrLinkShareIPNotAllowed) Error() string {
+	return fmt.Sprintf("Link Share cannot be used from this ip address [ShareID: %d, IP: %s]", err.ShareID, err.IP)
+}
+
+// ErrCodeLinkShareIPNotAllowed holds the unique world-error code of this error
+const ErrCodeLinkShareIPNotAllowed = 13006
+
+// HTTPError holds the http error description
+func (err ErrLinkShareIPNotAllowed) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeLinkShareIPNotAllowed,
+		Message:  "This link share cannot be used from your ip address.",
+	}
+}
+
+// ErrInvalidLinkShareAllowedIP represents an error where an entry of the ip allowlist of a link share is invalid
+type ErrInvalidLinkShareAllowedIP struct {
+	IP string
+}
+
+// IsErrInvalidLinkShareAllowedIP checks if an error is ErrInvalidLinkShareAllowedIP.
+func IsErrInvalidLinkShareAllowedIP(err error) bool {
+	_, ok := err.(*ErrInvalidLinkShareAllowedIP)
+	return ok
+}
+
+func (err *ErrInvalidLinkShareAllowedIP) Error() string {
+	return fmt.Sprintf("Invalid allowed ip address for link share [IP: %s]", err.IP)
+}
+
+// ErrCodeInvalidLinkShareAllowedIP holds the unique world-error code of this error
+const ErrCodeInvalidLinkShareAllowedIP = 13007
+
+// HTTPError holds the http error description
+func (err ErrInvalidLinkShareAllowedIP) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidLinkShareAllowedIP,
+		Message:  fmt.Sprintf("'%s' is not a valid ip address or cidr range.", err.IP),
+	}
+}
+
 // ================
 // API Token Errors
 // ================
@@ -1901,3 +2178,725 @@ func (err ErrInvalidAPITokenPermission) HTTPError() web.HTTPError {
 		Message:  fmt.Sprintf("The permission %s of group %s is invalid.", err.Permission, err.Group),
 	}
 }
+
+// ==========================
+// Project Automation Errors
+// ==========================
+
+// ErrProjectAutomationDoesNotExist represents an error where a project automation does not exist
+type ErrProjectAutomationDoesNotExist struct {
+	AutomationID int64
+}
+
+// IsErrProjectAutomationDoesNotExist checks if an error is ErrProjectAutomationDoesNotExist.
+func IsErrProjectAutomationDoesNotExist(err error) bool {
+	_, ok := err.(*ErrProjectAutomationDoesNotExist)
+	return ok
+}
+
+func (err *ErrProjectAutomationDoesNotExist) Error() string {
+	return fmt.Sprintf("Project automation does not exist [AutomationID: %d]", err.AutomationID)
+}
+
+// ErrCodeProjectAutomationDoesNotExist holds the unique world-error code of this error
+const ErrCodeProjectAutomationDoesNotExist = 15001
+
+// HTTPError holds the http error description
+func (err *ErrProjectAutomationDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeProjectAutomationDoesNotExist,
+		Message:  "This project automation does not exist.",
+	}
+}
+
+// ErrInvalidProjectAutomationTrigger represents an error where the trigger of a project automation is invalid
+type ErrInvalidProjectAutomationTrigger struct {
+	Reason string
+}
+
+// IsErrInvalidProjectAutomationTrigger checks if an error is ErrInvalidProjectAutomationTrigger.
+func IsErrInvalidProjectAutomationTrigger(err error) bool {
+	_, ok := err.(*ErrInvalidProjectAutomationTrigger)
+	return ok
+}
+
+func (err *ErrInvalidProjectAutomationTrigger) Error() string {
+	return fmt.Sprintf("Project automation trigger is invalid [Reason: %s]", err.Reason)
+}
+
+// ErrCodeInvalidProjectAutomationTrigger holds the unique world-error code of this error
+const ErrCodeInvalidProjectAutomationTrigger = 15002
+
+// HTTPError holds the http error description
+func (err *ErrInvalidProjectAutomationTrigger) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidProjectAutomationTrigger,
+		Message:  "The automation trigger is invalid: " + err.Reason,
+	}
+}
+
+// ErrInvalidProjectAutomationAction represents an error where an action of a project automation is invalid
+type ErrInvalidProjectAutomationAction struct {
+	Kind   ProjectAutomationActionKind
+	Reason string
+}
+
+// IsErrInvalidProjectAutomationAction checks if an error is ErrInvalidProjectAutomationAction.
+func IsErrInvalidProjectAutomationAction(err error) bool {
+	_, ok := err.(*ErrInvalidProjectAutomationAction)
+	return ok
+}
+
+func (err *ErrInvalidProjectAutomationAction) Error() string {
+	return fmt.Sprintf("Project automation action is invalid [Kind: %s, Reason: %s]", err.Kind, err.Reason)
+}
+
+// ErrCodeInvalidProjectAutomationAction holds the unique world-error code of this error
+const ErrCodeInvalidProjectAutomationAction = 15003
+
+// HTTPError holds the http error description
+func (err *ErrInvalidProjectAutomationAction) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidProjectAutomationAction,
+		Message:  "The automation action is invalid: " + err.Reason,
+	}
+}
+
+// =================
+// Migration Errors
+// =================
+
+// ErrInvalidImportMapping represents an error where the options or field mapping of a file import are invalid
+type ErrInvalidImportMapping struct {
+	Reason string
+}
+
+// IsErrInvalidImportMapping checks if an error is ErrInvalidImportMapping.
+func IsErrInvalidImportMapping(err error) bool {
+	_, ok := err.(*ErrInvalidImportMapping)
+	return ok
+}
+
+func (err *ErrInvalidImportMapping) Error() string {
+	return fmt.Sprintf("Import mapping is invalid [Reason: %s]", err.Reason)
+}
+
+// ErrCodeInvalidImportMapping holds the unique world-error code of this error
+const ErrCodeInvalidImportMapping = 16001
+
+// HTTPError holds the http error description
+func (err *ErrInvalidImportMapping) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidImportMapping,
+		Message:  "The import options are invalid: " + err.Reason,
+	}
+}
+
+// ErrInvalidImportFile represents an error where a file to import could not be parsed or contains invalid rows
+type ErrInvalidImportFile struct {
+	Reason string
+}
+
+// IsErrInvalidImportFile checks if an error is ErrInvalidImportFile.
+func IsErrInvalidImportFile(err error) bool {
+	_, ok := err.(*ErrInvalidImportFile)
+	return ok
+}
+
+func (err *ErrInvalidImportFile) Error() string {
+	return fmt.Sprintf("Import file is invalid [Reason: %s]", err.Reason)
+}
+
+// ErrCodeInvalidImportFile holds the unique world-error code of this error
+const ErrCodeInvalidImportFile = 16002
+
+// HTTPError holds the http error description
+func (err *ErrInvalidImportFile) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidImportFile,
+		Message:  "The file cannot be imported: " + err.Reason,
+	}
+}
+
+// ErrMigrationSyncDoesNotExist represents an error where a user has not set up a sync with a migrator
+type ErrMigrationSyncDoesNotExist struct {
+	MigratorName string
+	UserID       int64
+}
+
+// IsErrMigrationSyncDoesNotExist checks if an error is ErrMigrationSyncDoesNotExist.
+func IsErrMigrationSyncDoesNotExist(err error) bool {
+	_, ok := err.(*ErrMigrationSyncDoesNotExist)
+	return ok
+}
+
+func (err *ErrMigrationSyncDoesNotExist) Error() string {
+	return fmt.Sprintf("Migration sync does not exist [MigratorName: %s, UserID: %d]", err.MigratorName, err.UserID)
+}
+
+// ErrCodeMigrationSyncDoesNotExist holds the unique world-error code of this error
+const ErrCodeMigrationSyncDoesNotExist = 16003
+
+// HTTPError holds the http error description
+func (err *ErrMigrationSyncDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeMigrationSyncDoesNotExist,
+		Message:  "There is no sync set up with this service.",
+	}
+}
+
+// ErrMigrationAlreadyRunning represents an error where a user starts a migration while another one is still running
+type ErrMigrationAlreadyRunning struct {
+	UserID int64
+}
+
+// IsErrMigrationAlreadyRunning checks if an error is ErrMigrationAlreadyRunning.
+func IsErrMigrationAlreadyRunning(err error) bool {
+	_, ok := err.(*ErrMigrationAlreadyRunning)
+	return ok
+}
+
+func (err *ErrMigrationAlreadyRunning) Error() string {
+	return fmt.Sprintf("Migration is already running [UserID: %d]", err.UserID)
+}
+
+// ErrCodeMigrationAlreadyRunning holds the unique world-error code of this error
+const ErrCodeMigrationAlreadyRunning = 16004
+
+// HTTPError holds the http error description
+func (err *ErrMigrationAlreadyRunning) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusConflict,
+		Code:     ErrCodeMigrationAlreadyRunning,
+		Message:  "Another migration is still running. Please wait until it is done.",
+	}
+}
+
+// ==============
+// Export Errors
+// ==============
+
+// ErrInvalidExportFormat represents an error where an export was requested in an unknown format
+type ErrInvalidExportFormat struct {
+	Format string
+}
+
+// IsErrInvalidExportFormat checks if an error is ErrInvalidExportFormat.
+func IsErrInvalidExportFormat(err error) bool {
+	_, ok := err.(*ErrInvalidExportFormat)
+	return ok
+}
+
+func (err *ErrInvalidExportFormat) Error() string {
+	return fmt.Sprintf("Export format is invalid [Format: %s]", err.Format)
+}
+
+// ErrCodeInvalidExportFormat holds the unique world-error code of this error
+const ErrCodeInvalidExportFormat = 17001
+
+// HTTPError holds the http error description
+func (err *ErrInvalidExportFormat) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidExportFormat,
+		Message:  "The export format is invalid. Possible formats are csv, ics, md and todotxt.",
+	}
+}
+
+// ErrExportTooLarge represents an error where a project has too many tasks to export it directly
+type ErrExportTooLarge struct {
+	TaskCount int64
+	Limit     int64
+}
+
+// IsErrExportTooLarge checks if an error is ErrExportTooLarge.
+func IsErrExportTooLarge(err error) bool {
+	_, ok := err.(*ErrExportTooLarge)
+	return ok
+}
+
+func (err *ErrExportTooLarge) Error() string {
+	return fmt.Sprintf("Export is too large [TaskCount: %d, Limit: %d]", err.TaskCount, err.Limit)
+}
+
+// ErrCodeExportTooLarge holds the unique world-error code of this error
+const ErrCodeExportTooLarge = 17002
+
+// HTTPError holds the http error description
+func (err *ErrExportTooLarge) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusRequestEntityTooLarge,
+		Code:     ErrCodeExportTooLarge,
+		Message:  fmt.Sprintf("This project has more than %d tasks and can only be exported in the background. Please request the export instead.", err.Limit),
+	}
+}
+
+// ErrExportDoesNotExist represents an error where a task export does not exist
+type ErrExportDoesNotExist struct {
+	ExportID int64
+}
+
+// IsErrExportDoesNotExist checks if an error is ErrExportDoesNotExist.
+func IsErrExportDoesNotExist(err error) bool {
+	_, ok := err.(*ErrExportDoesNotExist)
+	return ok
+}
+
+func (err *ErrExportDoesNotExist) Error() string {
+	return fmt.Sprintf("Export does not exist [ExportID: %d]", err.ExportID)
+}
+
+// ErrCodeExportDoesNotExist holds the unique world-error code of this error
+const ErrCodeExportDoesNotExist = 17003
+
+// HTTPError holds the http error description
+func (err *ErrExportDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeExportDoesNotExist,
+		Message:  "This export does not exist.",
+	}
+}
+
+// ErrExportNotReady represents an error where a task export was requested but is not created yet
+type ErrExportNotReady struct {
+	ExportID int64
+}
+
+// IsErrExportNotReady checks if an error is ErrExportNotReady.
+func IsErrExportNotReady(err error) bool {
+	_, ok := err.(*ErrExportNotReady)
+	return ok
+}
+
+func (err *ErrExportNotReady) Error() string {
+	return fmt.Sprintf("Export is not ready [ExportID: %d]", err.ExportID)
+}
+
+// ErrCodeExportNotReady holds the unique world-error code of this error
+const ErrCodeExportNotReady = 17004
+
+// HTTPError holds the http error description
+func (err *ErrExportNotReady) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeExportNotReady,
+		Message:  "This export is not ready yet. You will get an email once it is.",
+	}
+}
+
+// ErrInvalidExportInterval represents an error where a scheduled export has an unknown interval
+type ErrInvalidExportInterval struct {
+	Interval string
+}
+
+// IsErrInvalidExportInterval checks if an error is ErrInvalidExportInterval.
+func IsErrInvalidExportInterval(err error) bool {
+	_, ok := err.(*ErrInvalidExportInterval)
+	return ok
+}
+
+func (err *ErrInvalidExportInterval) Error() string {
+	return fmt.Sprintf("Export interval is invalid [Interval: %s]", err.Interval)
+}
+
+// ErrCodeInvalidExportInterval holds the unique world-error code of this error
+const ErrCodeInvalidExportInterval = 17005
+
+// HTTPError holds the http error description
+func (err *ErrInvalidExportInterval) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidExportInterval,
+		Message:  "The export interval is invalid. Possible intervals are daily and weekly.",
+	}
+}
+
+// ErrInvalidExportRetention represents an error where a scheduled export should keep an invalid number of backups
+type ErrInvalidExportRetention struct {
+	RetentionCount int64
+}
+
+// IsErrInvalidExportRetention checks if an error is ErrInvalidExportRetention.
+func IsErrInvalidExportRetention(err error) bool {
+	_, ok := err.(*ErrInvalidExportRetention)
+	return ok
+}
+
+func (err *ErrInvalidExportRetention) Error() string {
+	return fmt.Sprintf("Export retention count is invalid [RetentionCount: %d]", err.RetentionCount)
+}
+
+// ErrCodeInvalidExportRetention holds the unique world-error code of this error
+const ErrCodeInvalidExportRetention = 17006
+
+// HTTPError holds the http error description
+func (err *ErrInvalidExportRetention) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidExportRetention,
+		Message:  fmt.Sprintf("The number of backups to keep must be between 1 and %d.", maxUserExportRetention),
+	}
+}
+
+// ErrInvalidExportWebDAVURL represents an error where a scheduled export has an invalid WebDAV url
+type ErrInvalidExportWebDAVURL struct {
+	URL string
+}
+
+// IsErrInvalidExportWebDAVURL checks if an error is ErrInvalidExportWebDAVURL.
+func IsErrInvalidExportWebDAVURL(err error) bool {
+	_, ok := err.(*ErrInvalidExportWebDAVURL)
+	return ok
+}
+
+func (err *ErrInvalidExportWebDAVURL) Error() string {
+	return fmt.Sprintf("Export WebDAV url is invalid [URL: %s]", err.URL)
+}
+
+// ErrCodeInvalidExportWebDAVURL holds the unique world-error code of this error
+const ErrCodeInvalidExportWebDAVURL = 17007
+
+// HTTPError holds the http error description
+func (err *ErrInvalidExportWebDAVURL) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeInvalidExportWebDAVURL,
+		Message:  "The WebDAV url is invalid. It must be an absolute http or https url.",
+	}
+}
+
+// ErrUserExportScheduleDoesNotExist represents an error where a user has no scheduled export
+type ErrUserExportScheduleDoesNotExist struct {
+	UserID int64
+}
+
+// IsErrUserExportScheduleDoesNotExist checks if an error is ErrUserExportScheduleDoesNotExist.
+func IsErrUserExportScheduleDoesNotExist(err error) bool {
+	_, ok := err.(*ErrUserExportScheduleDoesNotExist)
+	return ok
+}
+
+func (err *ErrUserExportScheduleDoesNotExist) Error() string {
+	return fmt.Sprintf("User export schedule does not exist [UserID: %d]", err.UserID)
+}
+
+// ErrCodeUserExportScheduleDoesNotExist holds the unique world-error code of this error
+const ErrCodeUserExportScheduleDoesNotExist = 17008
+
+// HTTPError holds the http error description
+func (err *ErrUserExportScheduleDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeUserExportScheduleDoesNotExist,
+		Message:  "You have not scheduled any exports.",
+	}
+}
+
+// ErrUserExportBackupDoesNotExist represents an error where a backup of a scheduled export does not exist
+type ErrUserExportBackupDoesNotExist struct {
+	BackupID int64
+}
+
+// IsErrUserExportBackupDoesNotExist checks if an error is ErrUserExportBackupDoesNotExist.
+func IsErrUserExportBackupDoesNotExist(err error) bool {
+	_, ok := err.(*ErrUserExportBackupDoesNotExist)
+	return ok
+}
+
+func (err *ErrUserExportBackupDoesNotExist) Error() string {
+	return fmt.Sprintf("User export backup does not exist [BackupID: %d]", err.BackupID)
+}
+
+// ErrCodeUserExportBackupDoesNotExist holds the unique world-error code of this error
+const ErrCodeUserExportBackupDoesNotExist = 17009
+
+// HTTPError holds the http error description
+func (err *ErrUserExportBackupDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeUserExportBackupDoesNotExist,
+		Message:  "This backup does not exist.",
+	}
+}
+
+// =======================
+// Service account errors
+// =======================
+
+// ErrServiceAccountDoesNotExist represents an error where a service account does not exist
+type ErrServiceAccountDoesNotExist struct {
+	ServiceAccountID int64
+}
+
+// IsErrServiceAccountDoesNotExist checks if an error is ErrServiceAccountDoesNotExist.
+func IsErrServiceAccountDoesNotExist(err error) bool {
+	_, ok := err.(*ErrServiceAccountDoesNotExist)
+	return ok
+}
+
+func (err *ErrServiceAccountDoesNotExist) Error() string {
+	return fmt.Sprintf("Service account does not exist [ServiceAccountID: %d]", err.ServiceAccountID)
+}
+
+// ErrCodeServiceAccountDoesNotExist holds the unique world-error code of this error
+const ErrCodeServiceAccountDoesNotExist = 18001
+
+// HTTPError holds the http error description
+func (err *ErrServiceAccountDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeServiceAccountDoesNotExist,
+		Message:  "This service account does not exist.",
+	}
+}
+
+// ============
+// OAuth errors
+// ============
+
+// ErrOAuthClientDoesNotExist represents an error where an oauth client does not exist
+type ErrOAuthClientDoesNotExist struct {
+	ClientID string
+}
+
+// IsErrOAuthClientDoesNotExist checks if an error is ErrOAuthClientDoesNotExist.
+func IsErrOAuthClientDoesNotExist(err error) bool {
+	_, ok := err.(*ErrOAuthClientDoesNotExist)
+	return ok
+}
+
+func (err *ErrOAuthClientDoesNotExist) Error() string {
+	return fmt.Sprintf("OAuth client does not exist [ClientID: %s]", err.ClientID)
+}
+
+// ErrCodeOAuthClientDoesNotExist holds the unique world-error code of this error
+const ErrCodeOAuthClientDoesNotExist = 19001
+
+// HTTPError holds the http error description
+func (err *ErrOAuthClientDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeOAuthClientDoesNotExist,
+		Message:  "This oauth client does not exist.",
+	}
+}
+
+// ErrOAuthInvalidRedirectURI represents an error where a redirect uri is not valid or not registered for a client
+type ErrOAuthInvalidRedirectURI struct {
+	RedirectURI string
+}
+
+// IsErrOAuthInvalidRedirectURI checks if an error is ErrOAuthInvalidRedirectURI.
+func IsErrOAuthInvalidRedirectURI(err error) bool {
+	_, ok := err.(*ErrOAuthInvalidRedirectURI)
+	return ok
+}
+
+func (err *ErrOAuthInvalidRedirectURI) Error() string {
+	return fmt.Sprintf("OAuth redirect uri is invalid [RedirectURI: %s]", err.RedirectURI)
+}
+
+// ErrCodeOAuthInvalidRedirectURI holds the unique world-error code of this error
+const ErrCodeOAuthInvalidRedirectURI = 19002
+
+// HTTPError holds the http error description
+func (err *ErrOAuthInvalidRedirectURI) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeOAuthInvalidRedirectURI,
+		Message:  "The redirect uri is invalid or not registered for this client.",
+	}
+}
+
+// ErrOAuthInvalidScope represents an error where a requested oauth scope does not exist
+type ErrOAuthInvalidScope struct {
+	Scope string
+}
+
+// IsErrOAuthInvalidScope checks if an error is ErrOAuthInvalidScope.
+func IsErrOAuthInvalidScope(err error) bool {
+	_, ok := err.(*ErrOAuthInvalidScope)
+	return ok
+}
+
+func (err *ErrOAuthInvalidScope) Error() string {
+	return fmt.Sprintf("OAuth scope is invalid [Scope: %s]", err.Scope)
+}
+
+// ErrCodeOAuthInvalidScope holds the unique world-error code of this error
+const ErrCodeOAuthInvalidScope = 19003
+
+// HTTPError holds the http error description
+func (err *ErrOAuthInvalidScope) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeOAuthInvalidScope,
+		Message:  fmt.Sprintf("The scope %s is invalid. Scopes are the api token permissions in the form group:permission.", err.Scope),
+	}
+}
+
+// ErrOAuthInvalidGrant represents an error where an authorization code or refresh token is invalid, expired or was issued to another client
+type ErrOAuthInvalidGrant struct {
+}
+
+// IsErrOAuthInvalidGrant checks if an error is ErrOAuthInvalidGrant.
+func IsErrOAuthInvalidGrant(err error) bool {
+	_, ok := err.(*ErrOAuthInvalidGrant)
+	return ok
+}
+
+func (err *ErrOAuthInvalidGrant) Error() string {
+	return "OAuth grant is invalid"
+}
+
+// ErrCodeOAuthInvalidGrant holds the unique world-error code of this error
+const ErrCodeOAuthInvalidGrant = 19004
+
+// HTTPError holds the http error description
+func (err *ErrOAuthInvalidGrant) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeOAuthInvalidGrant,
+		Message:  "The authorization code or refresh token is invalid or expired.",
+	}
+}
+
+// ErrOAuthInvalidClientCredentials represents an error where an oauth client could not be authenticated
+type ErrOAuthInvalidClientCredentials struct {
+}
+
+// IsErrOAuthInvalidClientCredentials checks if an error is ErrOAuthInvalidClientCredentials.
+func IsErrOAuthInvalidClientCredentials(err error) bool {
+	_, ok := err.(*ErrOAuthInvalidClientCredentials)
+	return ok
+}
+
+func (err *ErrOAuthInvalidClientCredentials) Error() string {
+	return "OAuth client credentials are invalid"
+}
+
+// ErrCodeOAuthInvalidClientCredentials holds the unique world-error code of this error
+const ErrCodeOAuthInvalidClientCredentials = 19005
+
+// HTTPError holds the http error description
+func (err *ErrOAuthInvalidClientCredentials) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusUnauthorized,
+		Code:     ErrCodeOAuthInvalidClientCredentials,
+		Message:  "The client credentials are invalid.",
+	}
+}
+
+// ErrOAuthPKCERequired represents an error where an authorization request did not use pkce
+type ErrOAuthPKCERequired struct {
+}
+
+// IsErrOAuthPKCERequired checks if an error is ErrOAuthPKCERequired.
+func IsErrOAuthPKCERequired(err error) bool {
+	_, ok := err.(*ErrOAuthPKCERequired)
+	return ok
+}
+
+func (err *ErrOAuthPKCERequired) Error() string {
+	return "OAuth authorization request without pkce"
+}
+
+// ErrCodeOAuthPKCERequired holds the unique world-error code of this error
+const ErrCodeOAuthPKCERequired = 19006
+
+// HTTPError holds the http error description
+func (err *ErrOAuthPKCERequired) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeOAuthPKCERequired,
+		Message:  "A code challenge with the method S256 is required.",
+	}
+}
+
+// ErrOAuthUnsupportedResponseType represents an error where an authorization request asked for another response type than code
+type ErrOAuthUnsupportedResponseType struct {
+	ResponseType string
+}
+
+// IsErrOAuthUnsupportedResponseType checks if an error is ErrOAuthUnsupportedResponseType.
+func IsErrOAuthUnsupportedResponseType(err error) bool {
+	_, ok := err.(*ErrOAuthUnsupportedResponseType)
+	return ok
+}
+
+func (err *ErrOAuthUnsupportedResponseType) Error() string {
+	return fmt.Sprintf("OAuth response type is not supported [ResponseType: %s]", err.ResponseType)
+}
+
+// ErrCodeOAuthUnsupportedResponseType holds the unique world-error code of this error
+const ErrCodeOAuthUnsupportedResponseType = 19007
+
+// HTTPError holds the http error description
+func (err *ErrOAuthUnsupportedResponseType) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeOAuthUnsupportedResponseType,
+		Message:  "Only the response type code is supported.",
+	}
+}
+
+// ErrOAuthUnsupportedGrantType represents an error where a token request used an unsupported grant type
+type ErrOAuthUnsupportedGrantType struct {
+	GrantType string
+}
+
+// IsErrOAuthUnsupportedGrantType checks if an error is ErrOAuthUnsupportedGrantType.
+func IsErrOAuthUnsupportedGrantType(err error) bool {
+	_, ok := err.(*ErrOAuthUnsupportedGrantType)
+	return ok
+}
+
+func (err *ErrOAuthUnsupportedGrantType) Error() string {
+	return fmt.Sprintf("OAuth grant type is not supported [GrantType: %s]", err.GrantType)
+}
+
+// ErrCodeOAuthUnsupportedGrantType holds the unique world-error code of this error
+const ErrCodeOAuthUnsupportedGrantType = 19008
+
+// HTTPError holds the http error description
+func (err *ErrOAuthUnsupportedGrantType) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusBadRequest,
+		Code:     ErrCodeOAuthUnsupportedGrantType,
+		Message:  "Only the grant types authorization_code and refresh_token are supported.",
+	}
+}
+
+// ErrOAuthAuthorizationDoesNotExist represents an error where an authorized app does not exist
+type ErrOAuthAuthorizationDoesNotExist struct {
+	AuthorizationID int64
+}
+
+// IsErrOAuthAuthorizationDoesNotExist checks if an error is ErrOAuthAuthorizationDoesNotExist.
+func IsErrOAuthAuthorizationDoesNotExist(err error) bool {
+	_, ok := err.(*ErrOAuthAuthorizationDoesNotExist)
+	return ok
+}
+
+func (err *ErrOAuthAuthorizationDoesNotExist) Error() string {
+	return fmt.Sprintf("OAuth authorization does not exist [AuthorizationID: %d]", err.AuthorizationID)
+}
+
+// ErrCodeOAuthAuthorizationDoesNotExist holds the unique world-error code of this error
+const ErrCodeOAuthAuthorizationDoesNotExist = 19009
+
+// HTTPError holds the http error description
+func (err *ErrOAuthAuthorizationDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeOAuthAuthorizationDoesNotExist,
+		Message:  "This authorized app does not exist.",
+	}
+}
@@ -176,6 +176,19 @@ func (t *TaskPositionsRecalculatedEvent) Name() string {
 	return "task.positions.recalculated"
 }
 
+// TaskBucketAutomationAppliedEvent represents an event where an automation rule of a bucket was applied to a task moved into it
+type TaskBucketAutomationAppliedEvent struct {
+	Task   *Task                 `json:"task"`
+	Bucket *Bucket               `json:"bucket"`
+	Rule   *BucketAutomationRule `json:"rule"`
+	Doer   *user.User            `json:"doer"`
+}
+
+// Name defines the name for TaskBucketAutomationAppliedEvent
+func (t *TaskBucketAutomationAppliedEvent) Name() string {
+	return "task.bucket.automation.applied"
+}
+
 ////////////////////
 // Project Events //
 ////////////////////
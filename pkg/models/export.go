@@ -40,10 +40,30 @@ import (
 )
 
 func ExportUserData(s *xorm.Session, u *user.User) (err error) {
+	exportFile, err := createUserDataExportFile(s, u)
+	if err != nil {
+		return err
+	}
+
+	// Save the file id with the user
+	u.ExportFileID = exportFile.ID
+	_, err = s.Cols("export_file_id").Update(u)
+	if err != nil {
+		return
+	}
+
+	// Send a notification
+	return notifications.Notify(u, &DataExportReadyNotification{
+		User: u,
+	})
+}
+
+// createUserDataExportFile creates a zip file with all data of the user and saves it as a file in Vikunja.
+func createUserDataExportFile(s *xorm.Session, u *user.User) (exportFile *files.File, err error) {
 	exportDir := config.FilesBasePath.GetString() + "/user-export-tmp/"
 	err = os.MkdirAll(exportDir, 0700)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	tmpFilename := exportDir + strconv.FormatInt(u.ID, 10) + "_" + time.Now().Format("2006-01-02_15-03-05") + ".zip"
@@ -51,7 +71,7 @@ func ExportUserData(s *xorm.Session, u *user.User) (err error) {
 	// Open zip
 	dumpFile, err := os.Create(tmpFilename)
 	if err != nil {
-		return fmt.Errorf("error opening dump file: %w", err)
+		return nil, fmt.Errorf("error opening dump file: %w", err)
 	}
 	defer dumpFile.Close()
 
@@ -61,27 +81,27 @@ func ExportUserData(s *xorm.Session, u *user.User) (err error) {
 	// Get the data
 	taskIDs, err := exportProjectsAndTasks(s, u, dumpWriter)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// Task attachment files
 	err = exportTaskAttachments(s, dumpWriter, taskIDs)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// Saved filters
 	err = exportSavedFilters(s, u, dumpWriter)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// Background files
 	err = exportProjectBackgrounds(s, u, dumpWriter)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	// Vikunja Version
 	err = utils.WriteBytesToZip("VERSION", []byte(version.Version), dumpWriter)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// If we reuse the same file again, saving it as a file in Vikunja will save it as a file with 0 bytes in size.
@@ -91,36 +111,22 @@ func ExportUserData(s *xorm.Session, u *user.User) (err error) {
 
 	exported, err := os.Open(tmpFilename)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer exported.Close()
 
 	stat, err := exported.Stat()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	exportFile, err := files.CreateWithMimeAndSession(s, exported, tmpFilename, uint64(stat.Size()), u, "application/zip", false)
+	exportFile, err = files.CreateWithMimeAndSession(s, exported, tmpFilename, uint64(stat.Size()), u, "application/zip", false)
 	if err != nil {
-		return err
-	}
-
-	// Save the file id with the user
-	u.ExportFileID = exportFile.ID
-	_, err = s.Cols("export_file_id").Update(u)
-	if err != nil {
-		return
+		return nil, err
 	}
 
 	// Remove the old file
-	err = os.Remove(exported.Name())
-	if err != nil {
-		return err
-	}
-
-	// Send a notification
-	return notifications.Notify(u, &DataExportReadyNotification{
-		User: u,
-	})
+	return exportFile, os.Remove(exported.Name())
 }
 
 func exportProjectsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (taskIDs []int64, err error) {
@@ -143,7 +149,23 @@ func exportProjectsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (task
 		return
 	}
 
-	projects := []*ProjectWithTasksAndBuckets{}
+	projects, taskIDs, err := getProjectsWithTasksAndBuckets(s, u, rawProjects)
+	if err != nil {
+		return taskIDs, err
+	}
+
+	data, err := json.Marshal(projects)
+	if err != nil {
+		return taskIDs, err
+	}
+
+	return taskIDs, utils.WriteBytesToZip("data.json", data, wr)
+}
+
+// getProjectsWithTasksAndBuckets loads the views, buckets, tasks and comments of all projects in the format
+// used by the vikunja-file migrator.
+func getProjectsWithTasksAndBuckets(s *xorm.Session, u *user.User, rawProjects []*Project) (projects []*ProjectWithTasksAndBuckets, taskIDs []int64, err error) {
+	projects = []*ProjectWithTasksAndBuckets{}
 	projectsMap := make(map[int64]*ProjectWithTasksAndBuckets, len(rawProjects))
 	projectIDs := []int64{}
 	for _, p := range rawProjects {
@@ -180,7 +202,7 @@ func exportProjectsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (task
 		perPage: -1,
 	}, nil)
 	if err != nil {
-		return taskIDs, err
+		return nil, nil, err
 	}
 
 	taskMap := make(map[int64]*TaskWithComments, len(tasks))
@@ -205,7 +227,17 @@ func exportProjectsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (task
 		return
 	}
 
+	authorIDs := make([]int64, 0, len(comments))
+	for _, c := range comments {
+		authorIDs = append(authorIDs, c.AuthorID)
+	}
+	authors, err := user.GetUsersByIDs(s, authorIDs)
+	if err != nil {
+		return
+	}
+
 	for _, c := range comments {
+		c.Author = authors[c.AuthorID]
 		if _, exists := taskMap[c.TaskID]; !exists {
 			log.Debugf("[User Data Export] Task %d does not exist for comment %d, omitting", c.TaskID, c.ID)
 			continue
@@ -285,12 +317,7 @@ func exportProjectsAndTasks(s *xorm.Session, u *user.User, wr *zip.Writer) (task
 		projectsMap[view.ProjectID].Positions = append(projectsMap[view.ProjectID].Positions, p)
 	}
 
-	data, err := json.Marshal(projects)
-	if err != nil {
-		return taskIDs, err
-	}
-
-	return taskIDs, utils.WriteBytesToZip("data.json", data, wr)
+	return projects, taskIDs, nil
 }
 
 func exportTaskAttachments(s *xorm.Session, wr *zip.Writer, taskIDs []int64) (err error) {
@@ -341,6 +368,10 @@ func exportProjectBackgrounds(s *xorm.Session, u *user.User, wr *zip.Writer) (er
 		return err
 	}
 
+	return writeProjectBackgroundsToZip(projects, wr)
+}
+
+func writeProjectBackgroundsToZip(projects []*Project, wr *zip.Writer) (err error) {
 	backgroundFiles := make(map[int64]io.ReadCloser)
 	for _, l := range projects {
 		if l.BackgroundFileID == 0 {
@@ -46,6 +46,9 @@ type Bucket struct {
 	// The number of tasks currently in this bucket
 	Count int64 `xorm:"-" json:"count"`
 
+	// Actions which are applied to every task moved into this bucket.
+	AutomationRules []*BucketAutomationRule `xorm:"json null" json:"automation_rules"`
+
 	// The position this bucket has when querying all buckets. See the tasks.position property on how to use this.
 	Position float64 `xorm:"double null" json:"position"`
 
@@ -300,6 +303,11 @@ func GetTasksInBucketsForView(s *xorm.Session, view *ProjectView, projects []*Pr
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{id}/views/{view}/buckets [put]
 func (b *Bucket) Create(s *xorm.Session, a web.Auth) (err error) {
+	err = b.validateAutomationRules()
+	if err != nil {
+		return
+	}
+
 	b.CreatedBy, err = GetUserOrLinkShareUser(s, a)
 	if err != nil {
 		return
@@ -333,6 +341,11 @@ func (b *Bucket) Create(s *xorm.Session, a web.Auth) (err error) {
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{projectID}/views/{view}/buckets/{bucketID} [post]
 func (b *Bucket) Update(s *xorm.Session, _ web.Auth) (err error) {
+	err = b.validateAutomationRules()
+	if err != nil {
+		return
+	}
+
 	_, err = s.
 		Where("id = ?", b.ID).
 		Cols(
@@ -340,6 +353,7 @@ func (b *Bucket) Update(s *xorm.Session, _ web.Auth) (err error) {
 			"limit",
 			"position",
 			"project_view_id",
+			"automation_rules",
 		).
 		Update(b)
 	return
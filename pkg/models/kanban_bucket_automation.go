@@ -0,0 +1,232 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// BucketAutomationAction is the kind of action a bucket automation rule performs on a task moved into its bucket.
+type BucketAutomationAction string
+
+const (
+	// BucketAutomationActionSetAssignees replaces all assignees of the task with the users of the rule.
+	BucketAutomationActionSetAssignees BucketAutomationAction = "set_assignees"
+	// BucketAutomationActionClearAssignees removes all assignees from the task.
+	BucketAutomationActionClearAssignees BucketAutomationAction = "clear_assignees"
+	// BucketAutomationActionAddLabels adds the labels of the rule to the task.
+	BucketAutomationActionAddLabels BucketAutomationAction = "add_labels"
+	// BucketAutomationActionRemoveLabels removes the labels of the rule from the task.
+	BucketAutomationActionRemoveLabels BucketAutomationAction = "remove_labels"
+	// BucketAutomationActionSetPriority sets the priority of the task.
+	BucketAutomationActionSetPriority BucketAutomationAction = "set_priority"
+	// BucketAutomationActionSetPercentDone sets the percent done of the task.
+	BucketAutomationActionSetPercentDone BucketAutomationAction = "set_percent_done"
+	// BucketAutomationActionSetDueDate sets the due date of the task to the time it was moved plus the configured amount of seconds.
+	BucketAutomationActionSetDueDate BucketAutomationAction = "set_due_date"
+	// BucketAutomationActionAddComment posts a comment on the task.
+	BucketAutomationActionAddComment BucketAutomationAction = "add_comment"
+)
+
+// BucketAutomationRule is an action which is applied to every task moved into the bucket the rule belongs to.
+type BucketAutomationRule struct {
+	// The action this rule performs. One of set_assignees, clear_assignees, add_labels, remove_labels, set_priority, set_percent_done, set_due_date or add_comment.
+	Action BucketAutomationAction `json:"action"`
+	// The users to assign to the task. Only used with set_assignees.
+	UserIDs []int64 `json:"user_ids,omitempty"`
+	// The labels to add to or remove from the task. Only used with add_labels and remove_labels.
+	LabelIDs []int64 `json:"label_ids,omitempty"`
+	// The priority to set. Only used with set_priority.
+	Priority int64 `json:"priority,omitempty"`
+	// The percent done to set, between 0 and 1. Only used with set_percent_done.
+	PercentDone float64 `json:"percent_done,omitempty"`
+	// The amount of seconds after the task was moved into the bucket it will be due. Only used with set_due_date.
+	DueAfter int64 `json:"due_after,omitempty"`
+	// The text of the comment to post. Only used with add_comment.
+	Comment string `json:"comment,omitempty"`
+}
+
+func (r *BucketAutomationRule) validate() error {
+	invalid := func(reason string) error {
+		return &ErrInvalidBucketAutomationRule{Action: r.Action, Reason: reason}
+	}
+
+	switch r.Action {
+	case BucketAutomationActionSetAssignees:
+		if len(r.UserIDs) == 0 {
+			return invalid("user_ids must not be empty")
+		}
+	case BucketAutomationActionClearAssignees:
+	case BucketAutomationActionAddLabels, BucketAutomationActionRemoveLabels:
+		if len(r.LabelIDs) == 0 {
+			return invalid("label_ids must not be empty")
+		}
+	case BucketAutomationActionSetPriority:
+		if r.Priority < 0 {
+			return invalid("priority must not be negative")
+		}
+	case BucketAutomationActionSetPercentDone:
+		if r.PercentDone < 0 || r.PercentDone > 1 {
+			return invalid("percent_done must be between 0 and 1")
+		}
+	case BucketAutomationActionSetDueDate:
+		if r.DueAfter <= 0 {
+			return invalid("due_after must be greater than 0")
+		}
+	case BucketAutomationActionAddComment:
+		if r.Comment == "" {
+			return invalid("comment must not be empty")
+		}
+	default:
+		return invalid("unknown action")
+	}
+
+	return nil
+}
+
+func (b *Bucket) validateAutomationRules() error {
+	for _, rule := range b.AutomationRules {
+		if err := rule.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *BucketAutomationRule) apply(s *xorm.Session, a web.Auth, task *Task) (err error) {
+	switch r.Action {
+	case BucketAutomationActionSetAssignees:
+		assignees := make([]*user.User, 0, len(r.UserIDs))
+		for _, id := range r.UserIDs {
+			assignees = append(assignees, &user.User{ID: id})
+		}
+		return task.updateTaskAssignees(s, assignees, a)
+	case BucketAutomationActionClearAssignees:
+		return task.updateTaskAssignees(s, nil, a)
+	case BucketAutomationActionAddLabels:
+		return addLabelsToTaskByAutomation(s, a, task, r.LabelIDs)
+	case BucketAutomationActionRemoveLabels:
+		_, err = s.
+			Where(builder.And(
+				builder.Eq{"task_id": task.ID},
+				builder.In("label_id", r.LabelIDs),
+			)).
+			Delete(&LabelTask{})
+		return err
+	case BucketAutomationActionSetPriority:
+		task.Priority = r.Priority
+		_, err = s.Where("id = ?", task.ID).Cols("priority").Update(task)
+		return err
+	case BucketAutomationActionSetPercentDone:
+		task.PercentDone = r.PercentDone
+		_, err = s.Where("id = ?", task.ID).Cols("percent_done").Update(task)
+		return err
+	case BucketAutomationActionSetDueDate:
+		task.DueDate = time.Now().Add(time.Duration(r.DueAfter) * time.Second)
+		_, err = s.Where("id = ?", task.ID).Cols("due_date").Update(task)
+		return err
+	case BucketAutomationActionAddComment:
+		comment := &TaskComment{
+			TaskID:  task.ID,
+			Comment: r.Comment,
+		}
+		return comment.Create(s, a)
+	}
+
+	return nil
+}
+
+func addLabelsToTaskByAutomation(s *xorm.Session, a web.Auth, task *Task, labelIDs []int64) error {
+	existing := []*LabelTask{}
+	err := s.
+		Where(builder.And(
+			builder.Eq{"task_id": task.ID},
+			builder.In("label_id", labelIDs),
+		)).
+		Find(&existing)
+	if err != nil {
+		return err
+	}
+
+	alreadyAdded := make(map[int64]bool, len(existing))
+	for _, lt := range existing {
+		alreadyAdded[lt.LabelID] = true
+	}
+
+	for _, labelID := range labelIDs {
+		if alreadyAdded[labelID] {
+			continue
+		}
+
+		label, err := getLabelByIDSimple(s, labelID)
+		if err != nil {
+			return err
+		}
+
+		// Only add labels the user moving the task has access to
+		has, _, err := label.hasAccessToLabel(s, a)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrUserHasNoAccessToLabel{LabelID: labelID, UserID: a.GetID()}
+		}
+
+		_, err = s.Insert(&LabelTask{LabelID: labelID, TaskID: task.ID})
+		if err != nil {
+			return err
+		}
+		alreadyAdded[labelID] = true
+	}
+
+	return nil
+}
+
+// applyAutomationRules applies all automation rules of the bucket to a task which was just moved into it.
+// An event is dispatched for every rule applied.
+func (b *Bucket) applyAutomationRules(s *xorm.Session, a web.Auth, task *Task) (err error) {
+	if len(b.AutomationRules) == 0 {
+		return nil
+	}
+
+	doer, _ := user.GetFromAuth(a)
+	for _, rule := range b.AutomationRules {
+		err = rule.apply(s, a, task)
+		if err != nil {
+			return err
+		}
+
+		err = events.Dispatch(&TaskBucketAutomationAppliedEvent{
+			Task:   task,
+			Bucket: b,
+			Rule:   rule,
+			Doer:   doer,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return updateProjectByTaskID(s, task.ID)
+}
@@ -0,0 +1,167 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucket_AutomationRules(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	setRules := func(t *testing.T, rules ...*BucketAutomationRule) {
+		s := db.NewSession()
+		defer s.Close()
+		_, err := s.Where("id = ?", 1).
+			Cols("automation_rules").
+			Update(&Bucket{AutomationRules: rules})
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+	}
+
+	t.Run("apply all actions", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setRules(t,
+			&BucketAutomationRule{Action: BucketAutomationActionSetAssignees, UserIDs: []int64{1}},
+			&BucketAutomationRule{Action: BucketAutomationActionAddLabels, LabelIDs: []int64{1, 2}},
+			&BucketAutomationRule{Action: BucketAutomationActionSetPriority, Priority: 3},
+			&BucketAutomationRule{Action: BucketAutomationActionSetPercentDone, PercentDone: 0.5},
+			&BucketAutomationRule{Action: BucketAutomationActionSetDueDate, DueAfter: 3600},
+			&BucketAutomationRule{Action: BucketAutomationActionAddComment, Comment: "Moved into review"},
+		)
+
+		s := db.NewSession()
+		defer s.Close()
+
+		tb := &TaskBucket{
+			TaskID:        4,
+			BucketID:      1,
+			ProjectViewID: 4,
+			ProjectID:     1,
+		}
+		err := tb.Update(s, u)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		db.AssertExists(t, "task_assignees", map[string]interface{}{
+			"task_id": 4,
+			"user_id": 1,
+		}, false)
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  4,
+			"label_id": 1,
+		}, false)
+		db.AssertExists(t, "label_tasks", map[string]interface{}{
+			"task_id":  4,
+			"label_id": 2,
+		}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":           4,
+			"priority":     3,
+			"percent_done": 0.5,
+		}, false)
+		db.AssertExists(t, "task_comments", map[string]interface{}{
+			"task_id":   4,
+			"comment":   "Moved into review",
+			"author_id": 1,
+		}, false)
+
+		task, err := GetTaskByIDSimple(s, 4)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), task.DueDate, time.Minute)
+	})
+	t.Run("remove labels and clear assignees", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setRules(t,
+			&BucketAutomationRule{Action: BucketAutomationActionClearAssignees},
+			&BucketAutomationRule{Action: BucketAutomationActionRemoveLabels, LabelIDs: []int64{1}},
+		)
+
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Insert(&TaskAssginee{TaskID: 4, UserID: 1})
+		require.NoError(t, err)
+		_, err = s.Insert(&LabelTask{TaskID: 4, LabelID: 1})
+		require.NoError(t, err)
+
+		tb := &TaskBucket{
+			TaskID:        4,
+			BucketID:      1,
+			ProjectViewID: 4,
+			ProjectID:     1,
+		}
+		err = tb.Update(s, u)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		db.AssertMissing(t, "task_assignees", map[string]interface{}{
+			"task_id": 4,
+		})
+		db.AssertMissing(t, "label_tasks", map[string]interface{}{
+			"task_id":  4,
+			"label_id": 1,
+		})
+	})
+	t.Run("label without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setRules(t,
+			&BucketAutomationRule{Action: BucketAutomationActionAddLabels, LabelIDs: []int64{3}},
+		)
+
+		s := db.NewSession()
+		defer s.Close()
+
+		tb := &TaskBucket{
+			TaskID:        4,
+			BucketID:      1,
+			ProjectViewID: 4,
+			ProjectID:     1,
+		}
+		err := tb.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrUserHasNoAccessToLabel(err))
+	})
+	t.Run("invalid rule", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		b := &Bucket{
+			ID:            1,
+			Title:         "testbucket1",
+			ProjectViewID: 4,
+			AutomationRules: []*BucketAutomationRule{
+				{Action: BucketAutomationActionSetPercentDone, PercentDone: 2},
+			},
+		}
+		err := b.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidBucketAutomationRule(err))
+
+		b.AutomationRules = []*BucketAutomationRule{{Action: "unknown"}}
+		err = b.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidBucketAutomationRule(err))
+	})
+}
@@ -0,0 +1,423 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"strconv"
+
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// Swimlane groups the tasks of a kanban view horizontally. Every swimlane holds all buckets of the view,
+// but only with the tasks which belong to that swimlane.
+type Swimlane struct {
+	// The id of this swimlane. Depending on the swimlane configuration mode, this is the id of the assignee,
+	// label or parent task, or the priority. For `filter` swimlanes, this is the position of the swimlane in the
+	// configuration, starting at 1. Tasks which do not belong to any swimlane are put in the swimlane with the id 0.
+	ID int64 `json:"id"`
+	// The title of this swimlane.
+	Title string `json:"title"`
+	// How many tasks of this swimlane can be in the same bucket at the same time. 0 means unlimited.
+	Limit int64 `json:"limit"`
+	// The number of tasks in this swimlane.
+	Count int64 `json:"count"`
+	// All buckets of the view with the tasks of this swimlane. The count of each bucket only includes the tasks of this swimlane.
+	Buckets []*Bucket `json:"buckets"`
+
+	bucketMap map[int64]*Bucket
+}
+
+func newSwimlane(id int64, title string, limit int64, buckets []*Bucket) *Swimlane {
+	swimlane := &Swimlane{
+		ID:        id,
+		Title:     title,
+		Limit:     limit,
+		Buckets:   make([]*Bucket, 0, len(buckets)),
+		bucketMap: make(map[int64]*Bucket, len(buckets)),
+	}
+
+	for _, b := range buckets {
+		bucket := *b
+		bucket.Tasks = []*Task{}
+		bucket.Count = 0
+		swimlane.Buckets = append(swimlane.Buckets, &bucket)
+		swimlane.bucketMap[bucket.ID] = &bucket
+	}
+
+	return swimlane
+}
+
+// getFilterSwimlaneTaskIDs returns the ids of all tasks matching the filter of each swimlane configured in the view.
+func getFilterSwimlaneTaskIDs(s *xorm.Session, view *ProjectView, projects []*Project, opts *taskSearchOptions, auth web.Auth) (taskIDs []map[int64]bool, err error) {
+	taskIDs = make([]map[int64]bool, 0, len(view.SwimlaneConfiguration))
+	for _, lane := range view.SwimlaneConfiguration {
+		filter := "(" + lane.Filter + ")"
+		if opts.filter != "" {
+			filter = "(" + opts.filter + ") && " + filter
+		}
+
+		laneOpts := &taskSearchOptions{
+			page:               -1,
+			filter:             filter,
+			filterTimezone:     opts.filterTimezone,
+			filterIncludeNulls: opts.filterIncludeNulls,
+		}
+		laneOpts.parsedFilters, err = getTaskFiltersFromFilterString(filter, opts.filterTimezone)
+		if err != nil {
+			return nil, err
+		}
+
+		tasks, _, _, err := getRawTasksForProjects(s, projects, auth, laneOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		ids := make(map[int64]bool, len(tasks))
+		for _, t := range tasks {
+			ids[t.ID] = true
+		}
+		taskIDs = append(taskIDs, ids)
+	}
+
+	return
+}
+
+// getSwimlanesOfTask returns the id and title of all swimlanes a task belongs to.
+// A task can belong to more than one swimlane, for example when it has multiple assignees.
+func getSwimlanesOfTask(view *ProjectView, t *Task, filterTaskIDs []map[int64]bool) (lanes map[int64]string) {
+	lanes = make(map[int64]string)
+
+	switch view.SwimlaneConfigurationMode {
+	case SwimlaneConfigurationModeAssignee:
+		for _, assignee := range t.Assignees {
+			lanes[assignee.ID] = assignee.GetName()
+		}
+	case SwimlaneConfigurationModeLabel:
+		for _, label := range t.Labels {
+			lanes[label.ID] = label.Title
+		}
+	case SwimlaneConfigurationModePriority:
+		if t.Priority != 0 {
+			lanes[t.Priority] = strconv.FormatInt(t.Priority, 10)
+		}
+	case SwimlaneConfigurationModeParentTask:
+		for _, parent := range t.RelatedTasks[RelationKindParenttask] {
+			lanes[parent.ID] = parent.Title
+		}
+	case SwimlaneConfigurationModeFilter:
+		for i, ids := range filterTaskIDs {
+			if ids[t.ID] {
+				lanes[int64(i+1)] = view.SwimlaneConfiguration[i].Title
+			}
+		}
+	case SwimlaneConfigurationModeNone:
+	}
+
+	return
+}
+
+func getTitleOfDefaultSwimlane(mode SwimlaneConfigurationModeKind) string {
+	switch mode {
+	case SwimlaneConfigurationModeAssignee:
+		return "No assignee"
+	case SwimlaneConfigurationModeLabel:
+		return "No label"
+	case SwimlaneConfigurationModePriority:
+		return "No priority"
+	case SwimlaneConfigurationModeParentTask:
+		return "No parent task"
+	case SwimlaneConfigurationModeFilter,
+		SwimlaneConfigurationModeNone:
+	}
+
+	return "Other"
+}
+
+// GetTasksInSwimlanesForView returns all swimlanes of a kanban view, each of them with all buckets of the view
+// and the tasks of that swimlane in them.
+// Swimlanes are not paginated so that the counts of all swimlanes and buckets are correct.
+func GetTasksInSwimlanesForView(s *xorm.Session, view *ProjectView, projects []*Project, opts *taskSearchOptions, auth web.Auth) (swimlanes []*Swimlane, err error) {
+	opts.page = -1
+
+	var filterTaskIDs []map[int64]bool
+	if view.SwimlaneConfigurationMode == SwimlaneConfigurationModeFilter {
+		filterTaskIDs, err = getFilterSwimlaneTaskIDs(s, view, projects, opts, auth)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	buckets, err := GetTasksInBucketsForView(s, view, projects, opts, auth)
+	if err != nil {
+		return nil, err
+	}
+
+	swimlaneMap := make(map[int64]*Swimlane)
+	swimlanes = []*Swimlane{}
+	if view.SwimlaneConfigurationMode == SwimlaneConfigurationModeFilter {
+		// Filter swimlanes are shown even if they don't hold any tasks, the same way buckets are.
+		for i, lane := range view.SwimlaneConfiguration {
+			swimlane := newSwimlane(int64(i+1), lane.Title, lane.Limit, buckets)
+			swimlaneMap[swimlane.ID] = swimlane
+			swimlanes = append(swimlanes, swimlane)
+		}
+	}
+
+	var defaultSwimlane *Swimlane
+	for _, bucket := range buckets {
+		for _, t := range bucket.Tasks {
+			lanes := getSwimlanesOfTask(view, t, filterTaskIDs)
+			if len(lanes) == 0 {
+				if defaultSwimlane == nil {
+					var limit int64
+					if view.SwimlaneConfigurationMode != SwimlaneConfigurationModeFilter {
+						limit = view.SwimlaneLimit
+					}
+					defaultSwimlane = newSwimlane(0, getTitleOfDefaultSwimlane(view.SwimlaneConfigurationMode), limit, buckets)
+				}
+				defaultSwimlane.bucketMap[bucket.ID].Tasks = append(defaultSwimlane.bucketMap[bucket.ID].Tasks, t)
+				defaultSwimlane.bucketMap[bucket.ID].Count++
+				defaultSwimlane.Count++
+				continue
+			}
+
+			for id, title := range lanes {
+				swimlane, exists := swimlaneMap[id]
+				if !exists {
+					swimlane = newSwimlane(id, title, view.SwimlaneLimit, buckets)
+					swimlaneMap[id] = swimlane
+					swimlanes = append(swimlanes, swimlane)
+				}
+				swimlane.bucketMap[bucket.ID].Tasks = append(swimlane.bucketMap[bucket.ID].Tasks, t)
+				swimlane.bucketMap[bucket.ID].Count++
+				swimlane.Count++
+			}
+		}
+	}
+
+	switch view.SwimlaneConfigurationMode {
+	case SwimlaneConfigurationModePriority:
+		sort.Slice(swimlanes, func(i, j int) bool {
+			return swimlanes[i].ID > swimlanes[j].ID
+		})
+	case SwimlaneConfigurationModeAssignee,
+		SwimlaneConfigurationModeLabel,
+		SwimlaneConfigurationModeParentTask:
+		sort.Slice(swimlanes, func(i, j int) bool {
+			return swimlanes[i].ID < swimlanes[j].ID
+		})
+	case SwimlaneConfigurationModeFilter,
+		SwimlaneConfigurationModeNone:
+		// Already in the configured order
+	}
+
+	// Tasks which don't belong to any swimlane always come last
+	if defaultSwimlane != nil {
+		swimlanes = append(swimlanes, defaultSwimlane)
+	}
+
+	return swimlanes, nil
+}
+
+// validateSwimlaneConfiguration makes sure every configured swimlane has a title and a filter which only uses known task fields.
+func (pv *ProjectView) validateSwimlaneConfiguration() error {
+	if pv.SwimlaneConfigurationMode != SwimlaneConfigurationModeFilter {
+		return nil
+	}
+
+	if len(pv.SwimlaneConfiguration) == 0 {
+		return &ErrInvalidSwimlaneConfiguration{Reason: "at least one swimlane is required"}
+	}
+
+	for _, lane := range pv.SwimlaneConfiguration {
+		invalid := func(reason string) error {
+			return &ErrInvalidSwimlaneConfiguration{Swimlane: lane.Title, Reason: reason}
+		}
+
+		if lane.Title == "" {
+			return invalid("title must not be empty")
+		}
+		if lane.Filter == "" {
+			return invalid("filter must not be empty")
+		}
+		if lane.Limit < 0 {
+			return invalid("limit must not be negative")
+		}
+
+		_, err := getTaskFiltersFromFilterString(lane.Filter, "")
+		if err != nil {
+			return invalid("invalid filter: " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (pv *ProjectView) hasSwimlaneLimits() bool {
+	switch pv.SwimlaneConfigurationMode {
+	case SwimlaneConfigurationModeNone:
+		return false
+	case SwimlaneConfigurationModeFilter:
+		for _, lane := range pv.SwimlaneConfiguration {
+			if lane.Limit > 0 {
+				return true
+			}
+		}
+		return false
+	case SwimlaneConfigurationModeAssignee,
+		SwimlaneConfigurationModeLabel,
+		SwimlaneConfigurationModePriority,
+		SwimlaneConfigurationModeParentTask:
+	}
+
+	return pv.SwimlaneLimit > 0
+}
+
+// swimlaneOfTask is a swimlane a task belongs to, along with a condition matching the ids of all tasks in it.
+type swimlaneOfTask struct {
+	id    int64
+	limit int64
+	tasks builder.Cond
+}
+
+// getSwimlaneLimitsOfTask returns all swimlanes with a limit a task belongs to.
+func getSwimlaneLimitsOfTask(s *xorm.Session, view *ProjectView, t *Task) (lanes []*swimlaneOfTask, err error) {
+	if view.SwimlaneConfigurationMode == SwimlaneConfigurationModeFilter {
+		for i, lane := range view.SwimlaneConfiguration {
+			if lane.Limit == 0 {
+				continue
+			}
+
+			parsedFilters, err := getTaskFiltersFromFilterString(lane.Filter, "")
+			if err != nil {
+				return nil, err
+			}
+			filterCond, err := convertFiltersToDBFilterCond(parsedFilters, false)
+			if err != nil {
+				return nil, err
+			}
+
+			has, err := s.
+				Table("tasks").
+				Where(builder.And(builder.Eq{"tasks.id": t.ID}, filterCond)).
+				Exist()
+			if err != nil {
+				return nil, err
+			}
+			if has {
+				lanes = append(lanes, &swimlaneOfTask{
+					id:    int64(i + 1),
+					limit: lane.Limit,
+					tasks: builder.In("task_buckets.task_id", builder.Select("id").From("tasks").Where(filterCond)),
+				})
+			}
+		}
+		return lanes, nil
+	}
+
+	var ids []int64
+	var laneTasks func(id int64) *builder.Builder
+	var defaultTasks builder.Cond
+
+	switch view.SwimlaneConfigurationMode {
+	case SwimlaneConfigurationModeAssignee:
+		err = s.Table("task_assignees").Where("task_id = ?", t.ID).Cols("user_id").Find(&ids)
+		laneTasks = func(id int64) *builder.Builder {
+			return builder.Select("task_id").From("task_assignees").Where(builder.Eq{"user_id": id})
+		}
+		defaultTasks = builder.NotIn("task_buckets.task_id", builder.Select("task_id").From("task_assignees"))
+	case SwimlaneConfigurationModeLabel:
+		err = s.Table("label_tasks").Where("task_id = ?", t.ID).Cols("label_id").Find(&ids)
+		laneTasks = func(id int64) *builder.Builder {
+			return builder.Select("task_id").From("label_tasks").Where(builder.Eq{"label_id": id})
+		}
+		defaultTasks = builder.NotIn("task_buckets.task_id", builder.Select("task_id").From("label_tasks"))
+	case SwimlaneConfigurationModePriority:
+		if t.Priority != 0 {
+			ids = []int64{t.Priority}
+		}
+		laneTasks = func(id int64) *builder.Builder {
+			return builder.Select("id").From("tasks").Where(builder.Eq{"priority": id})
+		}
+		defaultTasks = builder.In("task_buckets.task_id", laneTasks(0))
+	case SwimlaneConfigurationModeParentTask:
+		err = s.
+			Table("task_relations").
+			Where("task_id = ? AND relation_kind = ?", t.ID, RelationKindParenttask).
+			Cols("other_task_id").
+			Find(&ids)
+		laneTasks = func(id int64) *builder.Builder {
+			return builder.Select("task_id").From("task_relations").Where(builder.Eq{"other_task_id": id, "relation_kind": RelationKindParenttask})
+		}
+		defaultTasks = builder.NotIn("task_buckets.task_id", builder.Select("task_id").From("task_relations").Where(builder.Eq{"relation_kind": RelationKindParenttask}))
+	case SwimlaneConfigurationModeNone,
+		SwimlaneConfigurationModeFilter:
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return []*swimlaneOfTask{{limit: view.SwimlaneLimit, tasks: defaultTasks}}, nil
+	}
+
+	lanes = make([]*swimlaneOfTask, 0, len(ids))
+	for _, id := range ids {
+		lanes = append(lanes, &swimlaneOfTask{
+			id:    id,
+			limit: view.SwimlaneLimit,
+			tasks: builder.In("task_buckets.task_id", laneTasks(id)),
+		})
+	}
+
+	return lanes, nil
+}
+
+// Checks if moving a task into a bucket would exceed the limit of any of the swimlanes the task belongs to
+func checkSwimlaneLimit(s *xorm.Session, view *ProjectView, t *Task, bucket *Bucket) (err error) {
+	if !view.hasSwimlaneLimits() {
+		return nil
+	}
+
+	lanes, err := getSwimlaneLimitsOfTask(s, view, t)
+	if err != nil {
+		return err
+	}
+
+	for _, lane := range lanes {
+		taskCount, err := s.
+			Where(builder.And(builder.Eq{"task_buckets.bucket_id": bucket.ID}, lane.tasks)).
+			Count(&TaskBucket{})
+		if err != nil {
+			return err
+		}
+		if taskCount >= lane.limit {
+			return &ErrSwimlaneLimitExceeded{
+				SwimlaneID: lane.id,
+				BucketID:   bucket.ID,
+				Limit:      lane.limit,
+				TaskID:     t.ID,
+			}
+		}
+	}
+
+	return nil
+}
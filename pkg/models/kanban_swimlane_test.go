@@ -0,0 +1,320 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setSwimlaneConfiguration(t *testing.T, view *ProjectView) {
+	s := db.NewSession()
+	defer s.Close()
+
+	_, err := s.
+		ID(view.ID).
+		Cols("swimlane_configuration_mode", "swimlane_configuration", "swimlane_limit").
+		Update(view)
+	require.NoError(t, err)
+	require.NoError(t, s.Commit())
+}
+
+func getSwimlaneTaskIDs(swimlane *Swimlane) (ids map[int64][]int64) {
+	ids = make(map[int64][]int64)
+	for _, b := range swimlane.Buckets {
+		for _, t := range b.Tasks {
+			ids[b.ID] = append(ids[b.ID], t.ID)
+		}
+	}
+	return
+}
+
+func TestGetTasksInSwimlanesForView(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	getSwimlanes := func(t *testing.T) []*Swimlane {
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{
+			ProjectID:     1,
+			ProjectViewID: 4,
+		}
+		result, _, _, err := tc.ReadAll(s, u, "", 1, 50)
+		require.NoError(t, err)
+		swimlanes, is := result.([]*Swimlane)
+		require.True(t, is)
+		return swimlanes
+	}
+
+	t.Run("by label", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setSwimlaneConfiguration(t, &ProjectView{
+			ID:                        4,
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeLabel,
+		})
+
+		swimlanes := getSwimlanes(t)
+		require.Len(t, swimlanes, 2)
+		assert.Equal(t, int64(4), swimlanes[0].ID)
+		assert.Equal(t, "Label #4 - visible via other task", swimlanes[0].Title)
+		assert.Equal(t, int64(2), swimlanes[0].Count)
+		assert.Equal(t, map[int64][]int64{1: {1}, 3: {2}}, getSwimlaneTaskIDs(swimlanes[0]))
+		require.Len(t, swimlanes[0].Buckets, 3)
+		assert.Equal(t, int64(1), swimlanes[0].Buckets[0].Count)
+		assert.Equal(t, int64(0), swimlanes[0].Buckets[1].Count)
+
+		// All other tasks end up in the last swimlane
+		assert.Equal(t, int64(0), swimlanes[1].ID)
+		assert.Equal(t, "No label", swimlanes[1].Title)
+		assert.Equal(t, int64(16), swimlanes[1].Count)
+	})
+	t.Run("by assignee", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setSwimlaneConfiguration(t, &ProjectView{
+			ID:                        4,
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeAssignee,
+		})
+
+		swimlanes := getSwimlanes(t)
+		require.Len(t, swimlanes, 3)
+		// Task 30 has two assignees and therefore shows up in both of their swimlanes
+		assert.Equal(t, int64(1), swimlanes[0].ID)
+		assert.Equal(t, map[int64][]int64{1: {30}}, getSwimlaneTaskIDs(swimlanes[0]))
+		assert.Equal(t, int64(2), swimlanes[1].ID)
+		assert.Equal(t, map[int64][]int64{1: {30}}, getSwimlaneTaskIDs(swimlanes[1]))
+		assert.Equal(t, int64(0), swimlanes[2].ID)
+	})
+	t.Run("by priority", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setSwimlaneConfiguration(t, &ProjectView{
+			ID:                        4,
+			SwimlaneConfigurationMode: SwimlaneConfigurationModePriority,
+			SwimlaneLimit:             5,
+		})
+
+		swimlanes := getSwimlanes(t)
+		require.Len(t, swimlanes, 3)
+		assert.Equal(t, int64(100), swimlanes[0].ID)
+		assert.Equal(t, map[int64][]int64{2: {3}}, getSwimlaneTaskIDs(swimlanes[0]))
+		assert.Equal(t, int64(5), swimlanes[0].Limit)
+		assert.Equal(t, int64(1), swimlanes[1].ID)
+		assert.Equal(t, map[int64][]int64{2: {4}}, getSwimlaneTaskIDs(swimlanes[1]))
+		assert.Equal(t, int64(0), swimlanes[2].ID)
+	})
+	t.Run("by filter", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setSwimlaneConfiguration(t, &ProjectView{
+			ID:                        4,
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeFilter,
+			SwimlaneConfiguration: []*ProjectViewSwimlaneConfiguration{
+				{Title: "Important", Filter: "priority >= 10", Limit: 2},
+				{Title: "Nothing", Filter: "priority = 50"},
+			},
+		})
+
+		swimlanes := getSwimlanes(t)
+		require.Len(t, swimlanes, 3)
+		assert.Equal(t, int64(1), swimlanes[0].ID)
+		assert.Equal(t, "Important", swimlanes[0].Title)
+		assert.Equal(t, int64(2), swimlanes[0].Limit)
+		assert.Equal(t, map[int64][]int64{2: {3}}, getSwimlaneTaskIDs(swimlanes[0]))
+		// Configured swimlanes are returned even if they are empty
+		assert.Equal(t, int64(2), swimlanes[1].ID)
+		assert.Equal(t, int64(0), swimlanes[1].Count)
+		assert.Equal(t, int64(0), swimlanes[2].ID)
+		assert.Equal(t, int64(17), swimlanes[2].Count)
+	})
+}
+
+func TestTaskBucket_Update_SwimlaneLimit(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("swimlane limit exceeded", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setSwimlaneConfiguration(t, &ProjectView{
+			ID:                        4,
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeLabel,
+			SwimlaneLimit:             1,
+		})
+		s := db.NewSession()
+		defer s.Close()
+
+		tb := &TaskBucket{
+			TaskID:        2,
+			BucketID:      1, // Task 1 in bucket 1 already has the same label
+			ProjectViewID: 4,
+			ProjectID:     1,
+		}
+		err := tb.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrSwimlaneLimitExceeded(err))
+	})
+	t.Run("swimlane limit not exceeded", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setSwimlaneConfiguration(t, &ProjectView{
+			ID:                        4,
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeLabel,
+			SwimlaneLimit:             2,
+		})
+		s := db.NewSession()
+		defer s.Close()
+
+		tb := &TaskBucket{
+			TaskID:        2,
+			BucketID:      1,
+			ProjectViewID: 4,
+			ProjectID:     1,
+		}
+		err := tb.Update(s, u)
+		require.NoError(t, err)
+	})
+	t.Run("default swimlane limit exceeded", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setSwimlaneConfiguration(t, &ProjectView{
+			ID:                        4,
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeAssignee,
+			SwimlaneLimit:             1,
+		})
+		s := db.NewSession()
+		defer s.Close()
+
+		tb := &TaskBucket{
+			TaskID:        3,
+			BucketID:      1, // Task 1 in bucket 1 doesn't have an assignee either
+			ProjectViewID: 4,
+			ProjectID:     1,
+		}
+		err := tb.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrSwimlaneLimitExceeded(err))
+		assert.Equal(t, int64(0), err.(*ErrSwimlaneLimitExceeded).SwimlaneID)
+	})
+	t.Run("filter swimlane limit exceeded", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setSwimlaneConfiguration(t, &ProjectView{
+			ID:                        4,
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeFilter,
+			SwimlaneConfiguration: []*ProjectViewSwimlaneConfiguration{
+				{Title: "Prioritized", Filter: "priority >= 1 || id = 1", Limit: 1},
+			},
+		})
+		s := db.NewSession()
+		defer s.Close()
+
+		tb := &TaskBucket{
+			TaskID:        3,
+			BucketID:      1, // Task 1 in bucket 1 is in the same swimlane
+			ProjectViewID: 4,
+			ProjectID:     1,
+		}
+		err := tb.Update(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrSwimlaneLimitExceeded(err))
+		assert.Equal(t, int64(1), err.(*ErrSwimlaneLimitExceeded).SwimlaneID)
+	})
+	t.Run("task not in the limited filter swimlane", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setSwimlaneConfiguration(t, &ProjectView{
+			ID:                        4,
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeFilter,
+			SwimlaneConfiguration: []*ProjectViewSwimlaneConfiguration{
+				{Title: "Prioritized", Filter: "priority >= 1 || id = 1", Limit: 1},
+			},
+		})
+		s := db.NewSession()
+		defer s.Close()
+
+		tb := &TaskBucket{
+			TaskID:        5,
+			BucketID:      1,
+			ProjectViewID: 4,
+			ProjectID:     1,
+		}
+		err := tb.Update(s, u)
+		require.NoError(t, err)
+	})
+}
+
+func TestProjectView_validateSwimlaneConfiguration(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		view := &ProjectView{
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeFilter,
+			SwimlaneConfiguration: []*ProjectViewSwimlaneConfiguration{
+				{Title: "Important", Filter: "priority >= 10 && done = false", Limit: 2},
+			},
+		}
+		require.NoError(t, view.validateSwimlaneConfiguration())
+	})
+	t.Run("unknown field", func(t *testing.T) {
+		view := &ProjectView{
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeFilter,
+			SwimlaneConfiguration: []*ProjectViewSwimlaneConfiguration{
+				{Title: "Important", Filter: "importance >= 10"},
+			},
+		}
+		err := view.validateSwimlaneConfiguration()
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidSwimlaneConfiguration(err))
+	})
+	t.Run("missing title", func(t *testing.T) {
+		view := &ProjectView{
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeFilter,
+			SwimlaneConfiguration: []*ProjectViewSwimlaneConfiguration{
+				{Filter: "priority >= 10"},
+			},
+		}
+		err := view.validateSwimlaneConfiguration()
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidSwimlaneConfiguration(err))
+	})
+	t.Run("negative limit", func(t *testing.T) {
+		view := &ProjectView{
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeFilter,
+			SwimlaneConfiguration: []*ProjectViewSwimlaneConfiguration{
+				{Title: "Important", Filter: "priority >= 10", Limit: -1},
+			},
+		}
+		err := view.validateSwimlaneConfiguration()
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidSwimlaneConfiguration(err))
+	})
+	t.Run("rejected on update", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		view := &ProjectView{
+			ID:                        4,
+			ProjectID:                 1,
+			Title:                     "Kanban",
+			ViewKind:                  ProjectViewKindKanban,
+			SwimlaneConfigurationMode: SwimlaneConfigurationModeFilter,
+			SwimlaneConfiguration: []*ProjectViewSwimlaneConfiguration{
+				{Title: "Important", Filter: "importance >= 10"},
+			},
+		}
+		err := view.Update(s, &user.User{ID: 1})
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidSwimlaneConfiguration(err))
+	})
+}
@@ -125,6 +125,11 @@ func (b *TaskBucket) Update(s *xorm.Session, a web.Auth) (err error) {
 		if err != nil {
 			return err
 		}
+
+		err = checkSwimlaneLimit(s, view, &task, bucket)
+		if err != nil {
+			return err
+		}
 	}
 
 	var updateBucket = true
@@ -197,6 +202,12 @@ func (b *TaskBucket) Update(s *xorm.Session, a web.Auth) (err error) {
 		}
 	}
 
+	// Rules also apply to repeating tasks moved into the done bucket, even though they stay in their old bucket
+	err = bucket.applyAutomationRules(s, a, &task)
+	if err != nil {
+		return err
+	}
+
 	b.TaskDone = task.Done
 
 	doer, _ := user.GetFromAuth(a)
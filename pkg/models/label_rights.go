@@ -44,7 +44,7 @@ func (l *Label) CanCreate(_ *xorm.Session, a web.Auth) (bool, error) {
 		return false, nil
 	}
 
-	return true, nil
+	return apiTokenCanChangeUnscoped(a), nil
 }
 
 func (l *Label) isLabelOwner(s *xorm.Session, a web.Auth) (bool, error) {
@@ -53,6 +53,10 @@ func (l *Label) isLabelOwner(s *xorm.Session, a web.Auth) (bool, error) {
 		return false, nil
 	}
 
+	if !apiTokenCanChangeUnscoped(a) {
+		return false, nil
+	}
+
 	lorig, err := getLabelByIDSimple(s, l.ID)
 	if err != nil {
 		return false, err
@@ -69,6 +73,9 @@ func (l *Label) hasAccessToLabel(s *xorm.Session, a web.Auth) (has bool, maxRigh
 	var createdByID int64
 	if isLinkShare {
 		where = builder.Eq{"project_id": linkShare.ProjectID}
+		if linkShare.TaskID != 0 {
+			where = builder.Eq{"id": linkShare.TaskID}
+		}
 	} else {
 		where = builder.In("project_id", getUserProjectsStatement(a.GetID(), "", false).Select("l.id"))
 		createdByID = a.GetID()
@@ -210,11 +210,16 @@ func GetLabelsByTaskIDs(s *xorm.Session, opts *LabelByTaskIDsOptions) (ls []*Lab
 			}
 		}
 
+		var taskCond builder.Cond = builder.In("project_id", projectIDs)
+		if isLinkShareAuth && linkShare.TaskID != 0 {
+			taskCond = builder.Eq{"id": linkShare.TaskID}
+		}
+
 		cond = builder.And(builder.In("label_tasks.task_id",
 			builder.
 				Select("id").
 				From("tasks").
-				Where(builder.In("project_id", projectIDs)),
+				Where(taskCond),
 		), cond)
 	}
 	if opts.GetUnusedLabels && !isLinkShareAuth {
@@ -18,9 +18,12 @@ package models
 
 import (
 	"errors"
+	"net"
 	"time"
 
+	"code.vikunja.io/api/pkg/cron"
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
 
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/api/pkg/utils"
@@ -52,6 +55,10 @@ type LinkSharing struct {
 	Name string `xorm:"text null" json:"name"`
 	// The ID of the shared project
 	ProjectID int64 `xorm:"bigint not null" json:"-" param:"project"`
+	// If set, only this task of the project is shared instead of the whole project.
+	TaskID int64 `xorm:"bigint null INDEX" json:"task_id"`
+	// If set, only this view of the project and the tasks shown in it are shared instead of the whole project. View link shares are always read only.
+	ProjectViewID int64 `xorm:"bigint null INDEX" json:"project_view_id"`
 	// The right this project is shared with. 0 = Read only, 1 = Read & Write, 2 = Admin. See the docs for more details.
 	Right Right `xorm:"bigint INDEX not null default 0" json:"right" valid:"length(0|2)" maximum:"2" default:"0"`
 
@@ -61,6 +68,15 @@ type LinkSharing struct {
 	// The password of this link share. You can only set it, not retrieve it after the link share has been created.
 	Password string `xorm:"text null" json:"password"`
 
+	// If set, the link share cannot be used after this date. Expired link shares are removed automatically.
+	ExpiresAt time.Time `xorm:"DATETIME null INDEX 'expires_at'" json:"expires_at"`
+	// How often this link share may be used to authenticate. 0 means unlimited.
+	MaxUses int64 `xorm:"bigint not null default 0" json:"max_uses" minimum:"0"`
+	// How often this link share was used to authenticate. You cannot change this value.
+	Uses int64 `xorm:"bigint not null default 0" json:"uses"`
+	// If set, the link share can only be used from these ip addresses. Each entry can be a single ip address or a cidr range like 192.0.2.0/24.
+	AllowedIPs []string `xorm:"json null 'allowed_ips'" json:"allowed_ips"`
+
 	// The user who shared this project
 	SharedBy   *user.User `xorm:"-" json:"shared_by"`
 	SharedByID int64      `xorm:"bigint INDEX not null" json:"-"`
@@ -97,9 +113,63 @@ func GetLinkShareFromClaims(claims jwt.MapClaims) (share *LinkSharing, err error
 	share.ProjectID = int64(projectID)
 	share.Right = Right(claims["right"].(float64))
 	share.SharedByID = int64(claims["sharedByID"].(float64))
+	// Tokens created before task and view link shares existed don't have these
+	if taskID, is := claims["task_id"].(float64); is {
+		share.TaskID = int64(taskID)
+	}
+	if viewID, is := claims["project_view_id"].(float64); is {
+		share.ProjectViewID = int64(viewID)
+	}
 	return
 }
 
+// targetsProject returns true if the link share gives access to the whole project and not only to a single task or view.
+func (share *LinkSharing) targetsProject() bool {
+	return share.TaskID == 0 && share.ProjectViewID == 0
+}
+
+// filterViews returns only the views a link share for a single task or view may see.
+func (share *LinkSharing) filterViews(views []*ProjectView) []*ProjectView {
+	filtered := []*ProjectView{}
+	for _, v := range views {
+		if share.ProjectViewID != 0 && v.ID == share.ProjectViewID {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+func (share *LinkSharing) validateTarget(s *xorm.Session) error {
+	if share.TaskID != 0 && share.ProjectViewID != 0 {
+		return ErrInvalidData{Message: "A link share can either share a task or a view, not both."}
+	}
+
+	if share.TaskID != 0 {
+		if share.Right == RightAdmin {
+			return ErrInvalidData{Message: "A link share for a task cannot have admin rights."}
+		}
+		task, err := GetTaskByIDSimple(s, share.TaskID)
+		if err != nil {
+			return err
+		}
+		if task.ProjectID != share.ProjectID {
+			return ErrTaskDoesNotExist{ID: share.TaskID}
+		}
+	}
+
+	if share.ProjectViewID != 0 {
+		if share.Right != RightRead {
+			return ErrInvalidData{Message: "A link share for a view can only have read rights."}
+		}
+		_, err := GetProjectViewByIDAndProject(s, share.ProjectViewID, share.ProjectID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (share *LinkSharing) getUserID() int64 {
 	return share.ID * -1
 }
@@ -141,8 +211,27 @@ func (share *LinkSharing) Create(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
+	if share.MaxUses < 0 {
+		return ErrInvalidData{Message: "max_uses must not be negative"}
+	}
+
+	if !share.ExpiresAt.IsZero() && share.ExpiresAt.Before(time.Now()) {
+		return ErrInvalidData{Message: "expires_at must be in the future"}
+	}
+
+	err = validateLinkShareAllowedIPs(share.AllowedIPs)
+	if err != nil {
+		return
+	}
+
+	err = share.validateTarget(s)
+	if err != nil {
+		return
+	}
+
 	share.SharedByID = a.GetID()
 	share.Hash = utils.MakeRandomString(40)
+	share.Uses = 0
 
 	if share.Password != "" {
 		share.SharingType = SharingTypeWithPassword
@@ -275,10 +364,22 @@ func (share *LinkSharing) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{project}/shares/{share} [delete]
 func (share *LinkSharing) Delete(s *xorm.Session, _ web.Auth) (err error) {
-	_, err = s.Where("id = ?", share.ID).Delete(share)
+	_, err = deleteLinkShares(s, builder.Eq{"id": share.ID})
 	return
 }
 
+// deleteLinkShares removes all link shares matching the condition together with their access log.
+func deleteLinkShares(s *xorm.Session, cond builder.Cond) (deleted int64, err error) {
+	_, err = s.
+		Where(builder.In("link_share_id", builder.Select("id").From("link_shares").Where(cond))).
+		Delete(&LinkShareAccess{})
+	if err != nil {
+		return
+	}
+
+	return s.Where(cond).Delete(&LinkSharing{})
+}
+
 // GetLinkShareByHash returns a link share by hash
 func GetLinkShareByHash(s *xorm.Session, hash string) (share *LinkSharing, err error) {
 	share = &LinkSharing{}
@@ -339,3 +440,143 @@ func VerifyLinkSharePassword(share *LinkSharing, password string) (err error) {
 
 	return nil
 }
+
+func validateLinkShareAllowedIPs(allowedIPs []string) error {
+	for _, ip := range allowedIPs {
+		if net.ParseIP(ip) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(ip); err != nil {
+			return &ErrInvalidLinkShareAllowedIP{IP: ip}
+		}
+	}
+	return nil
+}
+
+func (share *LinkSharing) isIPAllowed(ip string) bool {
+	if len(share.AllowedIPs) == 0 {
+		return true
+	}
+
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+
+	for _, allowed := range share.AllowedIPs {
+		if allowedIP := net.ParseIP(allowed); allowedIP != nil {
+			if allowedIP.Equal(parsed) {
+				return true
+			}
+			continue
+		}
+		_, network, err := net.ParseCIDR(allowed)
+		if err == nil && network.Contains(parsed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckLinkShareAccess checks if a link share can still be used from the given ip address.
+// It does not check the password of the share.
+func CheckLinkShareAccess(share *LinkSharing, ip string) error {
+	if share.MaxUses > 0 && share.Uses >= share.MaxUses {
+		return &ErrLinkShareMaxUsesReached{ShareID: share.ID, MaxUses: share.MaxUses}
+	}
+
+	return share.checkExpiryAndIP(ip)
+}
+
+func (share *LinkSharing) checkExpiryAndIP(ip string) error {
+	if !share.ExpiresAt.IsZero() && share.ExpiresAt.Before(time.Now()) {
+		return &ErrLinkShareExpired{ShareID: share.ID}
+	}
+
+	if !share.isIPAllowed(ip) {
+		return &ErrLinkShareIPNotAllowed{ShareID: share.ID, IP: ip}
+	}
+
+	return nil
+}
+
+// CheckLinkShareToken makes sure the link share a token was issued for can still be used from the given
+// ip address. Deleting a link share or letting it expire revokes all of its tokens that way.
+// The uses of a share are only counted when authenticating, a token stays valid once the limit is reached.
+func CheckLinkShareToken(s *xorm.Session, claims jwt.MapClaims, ip string) error {
+	id, is := claims["id"].(float64)
+	if !is {
+		return &ErrLinkShareTokenInvalid{}
+	}
+	hash, is := claims["hash"].(string)
+	if !is {
+		return &ErrLinkShareTokenInvalid{}
+	}
+
+	share, err := GetLinkShareByID(s, int64(id))
+	if err != nil {
+		if IsErrProjectShareDoesNotExist(err) {
+			return &ErrLinkShareTokenInvalid{}
+		}
+		return err
+	}
+	if share.Hash != hash {
+		return &ErrLinkShareTokenInvalid{}
+	}
+
+	return share.checkExpiryAndIP(ip)
+}
+
+// LogLinkShareAccess counts a successful authentication with a link share and records it in the access log of the share.
+func LogLinkShareAccess(s *xorm.Session, share *LinkSharing, ip, userAgent string) (err error) {
+	// Only count the use if the limit was not reached in the meantime
+	updated, err := s.
+		Where("id = ? AND (max_uses = 0 OR uses < max_uses)", share.ID).
+		Incr("uses").
+		Update(&LinkSharing{})
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return &ErrLinkShareMaxUsesReached{ShareID: share.ID, MaxUses: share.MaxUses}
+	}
+	share.Uses++
+
+	_, err = s.Insert(&LinkShareAccess{
+		LinkShareID: share.ID,
+		IPAddress:   ip,
+		UserAgent:   userAgent,
+	})
+	return
+}
+
+func deleteExpiredLinkShares(s *xorm.Session) (deleted int64, err error) {
+	return deleteLinkShares(s, builder.And(
+		builder.NotNull{"expires_at"},
+		builder.Lt{"expires_at": time.Now()},
+	))
+}
+
+// RegisterLinkShareCleanupCron registers a cron function to remove all expired link shares.
+func RegisterLinkShareCleanupCron() {
+	const logPrefix = "[Link Share Cleanup Cron] "
+
+	err := cron.Schedule("45 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		deleted, err := deleteExpiredLinkShares(s)
+		if err != nil {
+			log.Errorf(logPrefix+"Error removing expired link shares: %s", err)
+			return
+		}
+
+		if deleted > 0 {
+			log.Debugf(logPrefix+"Deleted %d expired link shares", deleted)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register link share cleanup cron: %s", err)
+	}
+}
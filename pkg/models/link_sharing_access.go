@@ -0,0 +1,92 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// LinkShareAccess records a successful authentication with a link share.
+type LinkShareAccess struct {
+	// The unique, numeric id of this entry.
+	ID          int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	LinkShareID int64 `xorm:"bigint not null INDEX" json:"-" param:"share"`
+	ProjectID   int64 `xorm:"-" json:"-" param:"project"`
+	// The ip address the link share was used from.
+	IPAddress string `xorm:"varchar(100) null" json:"ip_address"`
+	// The user agent of the device the link share was used with.
+	UserAgent string `xorm:"text null" json:"user_agent"`
+
+	// A timestamp when the link share was used. You cannot change this value.
+	Created time.Time `xorm:"created not null INDEX" json:"created"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for link share access log entries
+func (*LinkShareAccess) TableName() string {
+	return "link_share_access_log"
+}
+
+// ReadAll returns the access log of a link share
+// @Summary Get the access log of a link share
+// @Description Returns every time a link share was used to authenticate, the newest first. Only project admins can see the access log.
+// @tags sharing
+// @Accept json
+// @Produce json
+// @Param project path int true "Project ID"
+// @Param share path int true "Share ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Security JWTKeyAuth
+// @Success 200 {array} models.LinkShareAccess "The access log entries."
+// @Failure 403 {object} web.HTTPError "No admin access to the project."
+// @Failure 404 {object} web.HTTPError "Share Link not found."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/shares/{share}/access-log [get]
+func (access *LinkShareAccess) ReadAll(s *xorm.Session, a web.Auth, _ string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
+	can, _, err := access.CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+
+	entries := []*LinkShareAccess{}
+	query := s.
+		Where("link_share_id = ?", access.LinkShareID).
+		OrderBy("created DESC, id DESC")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+	err = query.Find(&entries)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	totalItems, err = s.
+		Where("link_share_id = ?", access.LinkShareID).
+		Count(&LinkShareAccess{})
+	return entries, len(entries), totalItems, err
+}
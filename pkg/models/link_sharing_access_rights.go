@@ -0,0 +1,41 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanRead checks if the user can see the access log of a link share. Only project admins can do that.
+func (access *LinkShareAccess) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, 0, nil
+	}
+
+	share, err := GetLinkShareByID(s, access.LinkShareID)
+	if err != nil {
+		return false, 0, err
+	}
+	if share.ProjectID != access.ProjectID {
+		return false, 0, ErrProjectShareDoesNotExist{ID: access.LinkShareID}
+	}
+
+	project := &Project{ID: share.ProjectID}
+	can, err := project.IsAdmin(s, a)
+	return can, int(RightAdmin), err
+}
@@ -18,10 +18,12 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/user"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -83,6 +85,156 @@ func TestLinkSharing_Create(t *testing.T) {
 			"sharing_type": SharingTypeWithPassword,
 		}, false)
 	})
+	t.Run("with limits", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ProjectID:  1,
+			Right:      RightRead,
+			ExpiresAt:  time.Now().Add(time.Hour),
+			MaxUses:    5,
+			Uses:       3,
+			AllowedIPs: []string{"192.0.2.1", "2001:db8::/32"},
+		}
+		err := share.Create(s, doer)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), share.Uses)
+		db.AssertExists(t, "link_shares", map[string]interface{}{
+			"id":       share.ID,
+			"max_uses": 5,
+			"uses":     0,
+		}, false)
+	})
+	t.Run("invalid allowed ip", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ProjectID:  1,
+			Right:      RightRead,
+			AllowedIPs: []string{"192.0.2.300"},
+		}
+		err := share.Create(s, doer)
+
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidLinkShareAllowedIP(err))
+	})
+	t.Run("task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ProjectID: 1,
+			TaskID:    1,
+			Right:     RightWrite,
+		}
+		err := share.Create(s, doer)
+
+		require.NoError(t, err)
+		db.AssertExists(t, "link_shares", map[string]interface{}{
+			"id":      share.ID,
+			"task_id": 1,
+		}, false)
+	})
+	t.Run("task of another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ProjectID: 1,
+			TaskID:    13,
+			Right:     RightRead,
+		}
+		err := share.Create(s, doer)
+
+		require.Error(t, err)
+		assert.True(t, IsErrTaskDoesNotExist(err))
+	})
+	t.Run("view", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ProjectID:     1,
+			ProjectViewID: 4,
+			Right:         RightRead,
+		}
+		err := share.Create(s, doer)
+
+		require.NoError(t, err)
+		db.AssertExists(t, "link_shares", map[string]interface{}{
+			"id":              share.ID,
+			"project_view_id": 4,
+		}, false)
+	})
+	t.Run("view with write right", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ProjectID:     1,
+			ProjectViewID: 4,
+			Right:         RightWrite,
+		}
+		err := share.Create(s, doer)
+
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidData(err))
+	})
+	t.Run("view of another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ProjectID:     1,
+			ProjectViewID: 8,
+			Right:         RightRead,
+		}
+		err := share.Create(s, doer)
+
+		require.Error(t, err)
+		assert.True(t, IsErrProjectViewDoesNotExist(err))
+	})
+	t.Run("task and view", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ProjectID:     1,
+			TaskID:        1,
+			ProjectViewID: 4,
+			Right:         RightRead,
+		}
+		err := share.Create(s, doer)
+
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidData(err))
+	})
+	t.Run("expiry in the past", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share := &LinkSharing{
+			ProjectID: 1,
+			Right:     RightRead,
+			ExpiresAt: time.Now().Add(-time.Hour),
+		}
+		err := share.Create(s, doer)
+
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidData(err))
+	})
 }
 
 func TestLinkSharing_ReadAll(t *testing.T) {
@@ -155,3 +307,316 @@ func TestLinkSharing_ReadOne(t *testing.T) {
 		assert.Empty(t, share.Password)
 	})
 }
+
+func TestCheckLinkShareAccess(t *testing.T) {
+	t.Run("no limits", func(t *testing.T) {
+		err := CheckLinkShareAccess(&LinkSharing{ID: 1}, "192.0.2.1")
+		require.NoError(t, err)
+	})
+	t.Run("expired", func(t *testing.T) {
+		err := CheckLinkShareAccess(&LinkSharing{ID: 1, ExpiresAt: time.Now().Add(-time.Minute)}, "192.0.2.1")
+		require.Error(t, err)
+		assert.True(t, IsErrLinkShareExpired(err))
+	})
+	t.Run("max uses reached", func(t *testing.T) {
+		err := CheckLinkShareAccess(&LinkSharing{ID: 1, MaxUses: 2, Uses: 2}, "192.0.2.1")
+		require.Error(t, err)
+		assert.True(t, IsErrLinkShareMaxUsesReached(err))
+	})
+	t.Run("allowed ip", func(t *testing.T) {
+		share := &LinkSharing{ID: 1, AllowedIPs: []string{"198.51.100.0/24", "192.0.2.1", "2001:db8::/32"}}
+		require.NoError(t, CheckLinkShareAccess(share, "192.0.2.1"))
+		require.NoError(t, CheckLinkShareAccess(share, "198.51.100.42"))
+		require.NoError(t, CheckLinkShareAccess(share, "2001:db8::1"))
+	})
+	t.Run("ip not allowed", func(t *testing.T) {
+		share := &LinkSharing{ID: 1, AllowedIPs: []string{"198.51.100.0/24"}}
+		err := CheckLinkShareAccess(share, "192.0.2.1")
+		require.Error(t, err)
+		assert.True(t, IsErrLinkShareIPNotAllowed(err))
+	})
+}
+
+func TestCheckLinkShareToken(t *testing.T) {
+	claims := func(id int64, hash string) jwt.MapClaims {
+		return jwt.MapClaims{"id": float64(id), "hash": hash}
+	}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := CheckLinkShareToken(s, claims(1, "test"), "192.0.2.1")
+		require.NoError(t, err)
+	})
+	t.Run("deleted share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := CheckLinkShareToken(s, claims(9999, "test"), "192.0.2.1")
+		require.Error(t, err)
+		assert.True(t, IsErrLinkShareTokenInvalid(err))
+	})
+	t.Run("hash does not match", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := CheckLinkShareToken(s, claims(1, "test2"), "192.0.2.1")
+		require.Error(t, err)
+		assert.True(t, IsErrLinkShareTokenInvalid(err))
+	})
+	t.Run("expired", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := CheckLinkShareToken(s, claims(5, "testExpired"), "192.0.2.1")
+		require.Error(t, err)
+		assert.True(t, IsErrLinkShareExpired(err))
+	})
+	t.Run("ip not allowed", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := CheckLinkShareToken(s, claims(7, "testAllowedIPs"), "192.0.2.1")
+		require.Error(t, err)
+		assert.True(t, IsErrLinkShareIPNotAllowed(err))
+
+		err = CheckLinkShareToken(s, claims(7, "testAllowedIPs"), "203.0.113.5")
+		require.NoError(t, err)
+	})
+	t.Run("max uses reached", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// Tokens which were already issued stay valid
+		err := CheckLinkShareToken(s, claims(6, "testMaxUses"), "192.0.2.1")
+		require.NoError(t, err)
+	})
+}
+
+func TestLogLinkShareAccess(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share, err := GetLinkShareByID(s, 2)
+		require.NoError(t, err)
+		err = LogLinkShareAccess(s, share, "192.0.2.1", "test agent")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), share.Uses)
+
+		db.AssertExists(t, "link_shares", map[string]interface{}{
+			"id":   2,
+			"uses": 1,
+		}, false)
+		db.AssertExists(t, "link_share_access_log", map[string]interface{}{
+			"link_share_id": 2,
+			"ip_address":    "192.0.2.1",
+			"user_agent":    "test agent",
+		}, false)
+	})
+	t.Run("max uses reached", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		share, err := GetLinkShareByID(s, 6)
+		require.NoError(t, err)
+		err = LogLinkShareAccess(s, share, "192.0.2.1", "test agent")
+		require.Error(t, err)
+		assert.True(t, IsErrLinkShareMaxUsesReached(err))
+		db.AssertMissing(t, "link_share_access_log", map[string]interface{}{
+			"link_share_id": 6,
+		})
+	})
+}
+
+func TestDeleteExpiredLinkShares(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	deleted, err := deleteExpiredLinkShares(s)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	db.AssertMissing(t, "link_shares", map[string]interface{}{"id": 5})
+	db.AssertExists(t, "link_shares", map[string]interface{}{"id": 1}, false)
+}
+
+func TestLinkShareAccess_ReadAll(t *testing.T) {
+	t.Run("project admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		access := &LinkShareAccess{LinkShareID: 1, ProjectID: 1}
+		all, _, total, err := access.ReadAll(s, &user.User{ID: 1}, "", 1, -1)
+		require.NoError(t, err)
+		entries := all.([]*LinkShareAccess)
+		require.Len(t, entries, 1)
+		assert.Equal(t, int64(1), total)
+		assert.Equal(t, "192.0.2.10", entries[0].IPAddress)
+	})
+	t.Run("no admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		access := &LinkShareAccess{LinkShareID: 1, ProjectID: 1}
+		_, _, _, err := access.ReadAll(s, &user.User{ID: 2}, "", 1, -1)
+		require.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+	t.Run("share of another project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		access := &LinkShareAccess{LinkShareID: 2, ProjectID: 1}
+		_, _, _, err := access.ReadAll(s, &user.User{ID: 1}, "", 1, -1)
+		require.Error(t, err)
+		assert.True(t, IsErrProjectShareDoesNotExist(err))
+	})
+	t.Run("link share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		access := &LinkShareAccess{LinkShareID: 1, ProjectID: 1}
+		_, _, _, err := access.ReadAll(s, &LinkSharing{ID: 1, ProjectID: 1, Right: RightAdmin}, "", 1, -1)
+		require.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+}
+
+func TestLinkSharing_TaskAndViewScope(t *testing.T) {
+	taskShare := &LinkSharing{ID: 8, ProjectID: 2, TaskID: 13, Right: RightWrite}
+	viewShare := &LinkSharing{ID: 9, ProjectID: 2, ProjectViewID: 8, Right: RightRead}
+
+	t.Run("task share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, _, err := (&Task{ID: 13}).CanRead(s, taskShare)
+		require.NoError(t, err)
+		assert.True(t, can)
+		can, err = (&Task{ID: 13}).CanUpdate(s, taskShare)
+		require.NoError(t, err)
+		assert.True(t, can)
+		can, err = (&Task{ID: 13}).CanDelete(s, taskShare)
+		require.NoError(t, err)
+		assert.False(t, can)
+
+		can, _, err = (&Task{ID: 37}).CanRead(s, taskShare)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&Task{ID: 37}).CanUpdate(s, taskShare)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&Task{ProjectID: 2}).CanCreate(s, taskShare)
+		require.NoError(t, err)
+		assert.False(t, can)
+
+		can, _, err = (&Project{ID: 2}).CanRead(s, taskShare)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, _, err = (&ProjectView{ID: 8, ProjectID: 2}).CanRead(s, taskShare)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("view share", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, _, err := (&ProjectView{ID: 8, ProjectID: 2}).CanRead(s, viewShare)
+		require.NoError(t, err)
+		assert.True(t, can)
+		can, _, err = (&ProjectView{ID: 5, ProjectID: 2}).CanRead(s, viewShare)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, err = (&ProjectView{ID: 8, ProjectID: 2}).CanUpdate(s, viewShare)
+		require.NoError(t, err)
+		assert.False(t, can)
+
+		can, _, err = (&Task{ID: 13}).CanRead(s, viewShare)
+		require.NoError(t, err)
+		assert.True(t, can)
+		can, err = (&Task{ID: 13}).CanUpdate(s, viewShare)
+		require.NoError(t, err)
+		assert.False(t, can)
+		can, _, err = (&Task{ID: 1}).CanRead(s, viewShare)
+		require.NoError(t, err)
+		assert.False(t, can)
+
+		can, _, err = (&Project{ID: 2}).CanRead(s, viewShare)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("view share with filter", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 8).Cols("filter").Update(&ProjectView{Filter: "id = 37"})
+		require.NoError(t, err)
+
+		can, _, err := (&Task{ID: 37}).CanRead(s, viewShare)
+		require.NoError(t, err)
+		assert.True(t, can)
+		can, _, err = (&Task{ID: 13}).CanRead(s, viewShare)
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("task collection", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, _, err := (&TaskCollection{ProjectID: 2, ProjectViewID: 8}).ReadAll(s, viewShare, "", 1, 50)
+		require.NoError(t, err)
+
+		_, _, _, err = (&TaskCollection{ProjectID: 2, ProjectViewID: 5}).ReadAll(s, viewShare, "", 1, 50)
+		require.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+
+		_, _, _, err = (&TaskCollection{ProjectID: 2, ProjectViewID: 5}).ReadAll(s, taskShare, "", 1, 50)
+		require.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+	t.Run("views", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		all, _, _, err := (&ProjectView{ProjectID: 2}).ReadAll(s, viewShare, "", 1, 50)
+		require.NoError(t, err)
+		views := all.([]*ProjectView)
+		require.Len(t, views, 1)
+		assert.Equal(t, int64(8), views[0].ID)
+
+		all, _, _, err = (&Project{}).ReadAll(s, taskShare, "", 1, 50)
+		require.NoError(t, err)
+		projects := all.([]*Project)
+		require.Len(t, projects, 1)
+		assert.Empty(t, projects[0].Views)
+	})
+	t.Run("deleting the task deletes its link shares", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&Task{ID: 13}).Delete(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		db.AssertMissing(t, "link_shares", map[string]interface{}{"id": 8})
+		db.AssertExists(t, "link_shares", map[string]interface{}{"id": 9}, false)
+	})
+}
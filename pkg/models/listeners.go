@@ -85,11 +85,31 @@ func RegisterListeners() {
 		RegisterEventForWebhook(&TaskAttachmentDeletedEvent{})
 		RegisterEventForWebhook(&TaskRelationCreatedEvent{})
 		RegisterEventForWebhook(&TaskRelationDeletedEvent{})
+		RegisterEventForWebhook(&TaskBucketAutomationAppliedEvent{})
 		RegisterEventForWebhook(&ProjectUpdatedEvent{})
 		RegisterEventForWebhook(&ProjectDeletedEvent{})
 		RegisterEventForWebhook(&ProjectSharedWithUserEvent{})
 		RegisterEventForWebhook(&ProjectSharedWithTeamEvent{})
 	}
+	if config.AutomationsEnabled.GetBool() {
+		// All webhook events except task.deleted and project.deleted: automations run on the task or project of
+		// the event, which does not exist anymore at that point.
+		RegisterEventForAutomation(&TaskCreatedEvent{})
+		RegisterEventForAutomation(&TaskUpdatedEvent{})
+		RegisterEventForAutomation(&TaskAssigneeCreatedEvent{})
+		RegisterEventForAutomation(&TaskAssigneeDeletedEvent{})
+		RegisterEventForAutomation(&TaskCommentCreatedEvent{})
+		RegisterEventForAutomation(&TaskCommentUpdatedEvent{})
+		RegisterEventForAutomation(&TaskCommentDeletedEvent{})
+		RegisterEventForAutomation(&TaskAttachmentCreatedEvent{})
+		RegisterEventForAutomation(&TaskAttachmentDeletedEvent{})
+		RegisterEventForAutomation(&TaskRelationCreatedEvent{})
+		RegisterEventForAutomation(&TaskRelationDeletedEvent{})
+		RegisterEventForAutomation(&TaskBucketAutomationAppliedEvent{})
+		RegisterEventForAutomation(&ProjectUpdatedEvent{})
+		RegisterEventForAutomation(&ProjectSharedWithUserEvent{})
+		RegisterEventForAutomation(&ProjectSharedWithTeamEvent{})
+	}
 }
 
 //////
@@ -853,16 +873,14 @@ func getIDAsInt64(id interface{}) int64 {
 }
 
 func getProjectIDFromAnyEvent(eventPayload map[string]interface{}) int64 {
-	if task, has := eventPayload["task"]; has {
-		t := task.(map[string]interface{})
-		if projectID, has := t["project_id"]; has {
+	if task, has := eventPayload["task"].(map[string]interface{}); has {
+		if projectID, has := task["project_id"]; has {
 			return getIDAsInt64(projectID)
 		}
 	}
 
-	if project, has := eventPayload["project"]; has {
-		t := project.(map[string]interface{})
-		if projectID, has := t["id"]; has {
+	if project, has := eventPayload["project"].(map[string]interface{}); has {
+		if projectID, has := project["id"]; has {
 			return getIDAsInt64(projectID)
 		}
 	}
@@ -870,6 +888,43 @@ func getProjectIDFromAnyEvent(eventPayload map[string]interface{}) int64 {
 	return 0
 }
 
+// AutomationListener represents a listener
+type AutomationListener struct {
+	EventName string
+}
+
+// Name defines the name for the AutomationListener listener
+func (al *AutomationListener) Name() string {
+	return "automation.listener"
+}
+
+// Handle is executed when the event AutomationListener listens on is fired
+func (al *AutomationListener) Handle(msg *message.Message) (err error) {
+	var event map[string]interface{}
+	err = json.Unmarshal(msg.Payload, &event)
+	if err != nil {
+		return err
+	}
+
+	projectID := getProjectIDFromAnyEvent(event)
+	if projectID == 0 {
+		log.Debugf("event %s does not contain a project id, not running automations", al.EventName)
+		return nil
+	}
+
+	var taskID int64
+	if task, has := event["task"].(map[string]interface{}); has {
+		if rawTaskID, has := task["id"]; has {
+			taskID = getIDAsInt64(rawTaskID)
+		}
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	return runAutomationsForEvent(s, al.EventName, projectID, taskID)
+}
+
 // Handle is executed when the event WebhookListener listens on is fired
 func (wl *WebhookListener) Handle(msg *message.Message) (err error) {
 	var event map[string]interface{}
@@ -50,6 +50,7 @@ func GetTables() []interface{} {
 		&LabelTask{},
 		&TaskReminder{},
 		&LinkSharing{},
+		&LinkShareAccess{},
 		&TaskRelation{},
 		&TaskAttachment{},
 		&TaskComment{},
@@ -65,6 +66,15 @@ func GetTables() []interface{} {
 		&ProjectView{},
 		&TaskPosition{},
 		&TaskBucket{},
+		&ProjectAutomation{},
+		&ProjectAutomationLog{},
+		&TaskChecklistItem{},
+		&UserExportSchedule{},
+		&UserExportBackup{},
+		&OAuthClient{},
+		&OAuthAuthorization{},
+		&OAuthAuthorizationCode{},
+		&OAuthRefreshToken{},
 	}
 }
 
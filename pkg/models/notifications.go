@@ -342,3 +342,73 @@ func (n *DataExportReadyNotification) ToDB() interface{} {
 func (n *DataExportReadyNotification) Name() string {
 	return "data.export.ready"
 }
+
+// UserExportBackupCreatedNotification represents a UserExportBackupCreatedNotification notification
+type UserExportBackupCreatedNotification struct {
+	User     *user.User        `json:"user"`
+	Backup   *UserExportBackup `json:"backup"`
+	FileName string            `json:"file_name"`
+
+	content []byte
+}
+
+// ToMail returns the mail notification for UserExportBackupCreatedNotification
+func (n *UserExportBackupCreatedNotification) ToMail() *notifications.Mail {
+	mail := notifications.NewMail().
+		Subject("Your scheduled Vikunja Data Export").
+		Greeting("Hi " + n.User.GetName() + ",")
+
+	if n.content != nil {
+		mail.
+			Line("Your scheduled Vikunja Data Export was created. You can find it attached to this email.").
+			Attachment(n.FileName, n.content)
+	} else {
+		mail.Line("Your scheduled Vikunja Data Export was created. It is too large to attach it to this email, click the button below to download it:")
+	}
+
+	return mail.
+		Action("Download", config.ServicePublicURL.GetString()+"user/export/backups").
+		Line("You can change when exports are created in your settings.").
+		Line("Have a nice day!")
+}
+
+// ToDB returns the UserExportBackupCreatedNotification notification in a format which can be saved in the db
+func (n *UserExportBackupCreatedNotification) ToDB() interface{} {
+	return nil
+}
+
+// Name returns the name of the notification
+func (n *UserExportBackupCreatedNotification) Name() string {
+	return "data.export.backup.created"
+}
+
+// ProjectAutomationNotification represents a ProjectAutomationNotification notification
+type ProjectAutomationNotification struct {
+	Automation *ProjectAutomation `json:"automation"`
+	Project    *Project           `json:"project"`
+	Task       *Task              `json:"task"`
+	Message    string             `json:"message"`
+}
+
+// ToMail returns the mail notification for ProjectAutomationNotification
+func (n *ProjectAutomationNotification) ToMail() *notifications.Mail {
+	mail := notifications.NewMail().
+		Subject(n.Automation.Title + " in " + n.Project.Title).
+		Line(n.Message)
+
+	if n.Task != nil {
+		return mail.Action("View Task", n.Task.GetFrontendURL())
+	}
+
+	return mail.Action("View Project", config.ServicePublicURL.GetString()+"projects/"+strconv.FormatInt(n.Project.ID, 10))
+}
+
+// ToDB returns the ProjectAutomationNotification notification in a format which can be saved in the db
+func (n *ProjectAutomationNotification) ToDB() interface{} {
+	return n
+}
+
+// Name returns the name of the notification
+func (n *ProjectAutomationNotification) Name() string {
+	return "project.automation"
+}
@@ -0,0 +1,419 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+const (
+	oauthAuthorizationCodeTTL = 10 * time.Minute
+	oauthAccessTokenTTL       = time.Hour
+
+	OAuthGrantTypeAuthorizationCode = `authorization_code`
+	OAuthGrantTypeRefreshToken      = `refresh_token`
+)
+
+// ParseOAuthScopes converts the space separated scopes of an oauth request to api token permissions.
+// Every scope has the form group:permission, for example tasks:read_all.
+func ParseOAuthScopes(scope string) (APIPermissions, error) {
+	permissions := APIPermissions{}
+	for _, part := range strings.Fields(scope) {
+		group, permission, found := strings.Cut(part, ":")
+		if !found || group == "" || permission == "" {
+			return nil, &ErrOAuthInvalidScope{Scope: part}
+		}
+		if !slices.Contains(permissions[group], permission) {
+			permissions[group] = append(permissions[group], permission)
+		}
+	}
+
+	if len(permissions) == 0 {
+		return nil, &ErrOAuthInvalidScope{}
+	}
+
+	if err := PermissionsAreValid(permissions); err != nil {
+		if invalid, is := err.(*ErrInvalidAPITokenPermission); is {
+			return nil, &ErrOAuthInvalidScope{Scope: invalid.Group + ":" + invalid.Permission}
+		}
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// scopeString converts api token permissions back to space separated oauth scopes.
+func (p APIPermissions) scopeString() string {
+	scopes := []string{}
+	for group, permissions := range p {
+		for _, permission := range permissions {
+			scopes = append(scopes, group+":"+permission)
+		}
+	}
+	sort.Strings(scopes)
+	return strings.Join(scopes, " ")
+}
+
+// containsAll checks if all permissions of other are also part of p.
+func (p APIPermissions) containsAll(other APIPermissions) bool {
+	for group, permissions := range other {
+		for _, permission := range permissions {
+			if !slices.Contains(p[group], permission) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// OAuthAuthorizationRequest holds the parameters a client sends when it asks a user for access to their account.
+type OAuthAuthorizationRequest struct {
+	// Must be `code`.
+	ResponseType string `query:"response_type" json:"response_type"`
+	// The client id of the app.
+	ClientID string `query:"client_id" json:"client_id"`
+	// Where the user is sent after authorizing the app. Must be one of the redirect uris registered for the app.
+	RedirectURI string `query:"redirect_uri" json:"redirect_uri"`
+	// The permissions the app asks for, separated by spaces. Each one has the form group:permission, using the groups and permissions of api tokens. For example `tasks:read_all projects:read_all`.
+	Scope string `query:"scope" json:"scope"`
+	// An opaque value the app uses to protect against csrf. It is passed back to the app unchanged.
+	State string `query:"state" json:"state"`
+	// The pkce code challenge: the base64url encoded sha256 hash of the code verifier.
+	CodeChallenge string `query:"code_challenge" json:"code_challenge"`
+	// Must be `S256`.
+	CodeChallengeMethod string `query:"code_challenge_method" json:"code_challenge_method"`
+}
+
+func (r *OAuthAuthorizationRequest) validate(s *xorm.Session) (client *OAuthClient, scopes APIPermissions, err error) {
+	client, err = GetOAuthClientByClientID(s, r.ClientID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !client.HasRedirectURI(r.RedirectURI) {
+		return nil, nil, &ErrOAuthInvalidRedirectURI{RedirectURI: r.RedirectURI}
+	}
+
+	if r.ResponseType != "code" {
+		return nil, nil, &ErrOAuthUnsupportedResponseType{ResponseType: r.ResponseType}
+	}
+
+	if r.CodeChallenge == "" || r.CodeChallengeMethod != "S256" {
+		return nil, nil, &ErrOAuthPKCERequired{}
+	}
+
+	scopes, err = ParseOAuthScopes(r.Scope)
+	return client, scopes, err
+}
+
+// OAuthConsent holds everything a user needs to decide if they want to authorize an app.
+type OAuthConsent struct {
+	// The app asking for access.
+	Client *OAuthClient `json:"client"`
+	// The permissions the app asks for.
+	Scopes APIPermissions `json:"scopes"`
+	// True if the user already authorized the app with at least these permissions.
+	AlreadyAuthorized bool `json:"already_authorized"`
+}
+
+// GetOAuthConsent validates an authorization request and returns what the user is asked to consent to.
+func GetOAuthConsent(s *xorm.Session, a web.Auth, r *OAuthAuthorizationRequest) (consent *OAuthConsent, err error) {
+	client, scopes, err := r.validate(s)
+	if err != nil {
+		return nil, err
+	}
+
+	authorization := &OAuthAuthorization{}
+	exists, err := s.
+		Where("user_id = ? AND client_id = ?", a.GetID(), client.ID).
+		Get(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuthConsent{
+		Client:            client,
+		Scopes:            scopes,
+		AlreadyAuthorized: exists && authorization.Scopes.containsAll(scopes),
+	}, nil
+}
+
+// OAuthAuthorizationResponse holds where a user should be sent after authorizing an app.
+type OAuthAuthorizationResponse struct {
+	// The redirect uri of the app with the authorization code and state as query parameters.
+	RedirectURL string `json:"redirect_url"`
+}
+
+// AuthorizeOAuthClient records the consent of a user for an app and creates an authorization code the app
+// can exchange for tokens.
+func AuthorizeOAuthClient(s *xorm.Session, a web.Auth, r *OAuthAuthorizationRequest) (response *OAuthAuthorizationResponse, err error) {
+	client, scopes, err := r.validate(s)
+	if err != nil {
+		return nil, err
+	}
+
+	authorization := &OAuthAuthorization{}
+	exists, err := s.
+		Where("user_id = ? AND client_id = ?", a.GetID(), client.ID).
+		Get(authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		if authorization.Scopes == nil {
+			authorization.Scopes = APIPermissions{}
+		}
+		for group, permissions := range scopes {
+			for _, permission := range permissions {
+				if !slices.Contains(authorization.Scopes[group], permission) {
+					authorization.Scopes[group] = append(authorization.Scopes[group], permission)
+				}
+			}
+		}
+		_, err = s.
+			Where("id = ?", authorization.ID).
+			Cols("scopes").
+			Update(authorization)
+	} else {
+		authorization = &OAuthAuthorization{
+			UserID:   a.GetID(),
+			ClientID: client.ID,
+			Scopes:   scopes,
+		}
+		_, err = s.Insert(authorization)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := utils.CryptoRandomString(40)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.Insert(&OAuthAuthorizationCode{
+		CodeHash:        hashOAuthSecret(code),
+		AuthorizationID: authorization.ID,
+		RedirectURI:     r.RedirectURI,
+		CodeChallenge:   r.CodeChallenge,
+		Scopes:          scopes,
+		ExpiresAt:       time.Now().Add(oauthAuthorizationCodeTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	redirectURL, err := url.Parse(r.RedirectURI)
+	if err != nil {
+		return nil, err
+	}
+	query := redirectURL.Query()
+	query.Set("code", code)
+	if r.State != "" {
+		query.Set("state", r.State)
+	}
+	redirectURL.RawQuery = query.Encode()
+
+	return &OAuthAuthorizationResponse{RedirectURL: redirectURL.String()}, nil
+}
+
+// OAuthTokenRequest holds the parameters of a request to the oauth token endpoint.
+type OAuthTokenRequest struct {
+	// Either `authorization_code` or `refresh_token`.
+	GrantType string `form:"grant_type" json:"grant_type"`
+	// The authorization code, required for the authorization_code grant.
+	Code string `form:"code" json:"code"`
+	// The redirect uri used in the authorization request, required for the authorization_code grant.
+	RedirectURI string `form:"redirect_uri" json:"redirect_uri"`
+	// The pkce code verifier, required for the authorization_code grant.
+	CodeVerifier string `form:"code_verifier" json:"code_verifier"`
+	// The refresh token, required for the refresh_token grant.
+	RefreshToken string `form:"refresh_token" json:"refresh_token"`
+	// The client id of the app.
+	ClientID string `form:"client_id" json:"client_id"`
+	// The client secret of the app. Not needed for public clients.
+	ClientSecret string `form:"client_secret" json:"client_secret"`
+}
+
+// OAuthTokenResponse is returned by the oauth token endpoint.
+type OAuthTokenResponse struct {
+	// The access token. Use it like an api token.
+	AccessToken string `json:"access_token"`
+	// Always `Bearer`.
+	TokenType string `json:"token_type"`
+	// The number of seconds until the access token expires.
+	ExpiresIn int64 `json:"expires_in"`
+	// Can be exchanged once for a new access token and refresh token.
+	RefreshToken string `json:"refresh_token"`
+	// The permissions of the access token, separated by spaces.
+	Scope string `json:"scope"`
+}
+
+// ExchangeOAuthToken authenticates a client and issues new tokens for an authorization code or a refresh token.
+func ExchangeOAuthToken(s *xorm.Session, r *OAuthTokenRequest) (response *OAuthTokenResponse, err error) {
+	client, err := GetOAuthClientByClientID(s, r.ClientID)
+	if err != nil {
+		if IsErrOAuthClientDoesNotExist(err) {
+			return nil, &ErrOAuthInvalidClientCredentials{}
+		}
+		return nil, err
+	}
+
+	err = client.Authenticate(r.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	switch r.GrantType {
+	case OAuthGrantTypeAuthorizationCode:
+		return exchangeOAuthAuthorizationCode(s, client, r)
+	case OAuthGrantTypeRefreshToken:
+		return refreshOAuthToken(s, client, r)
+	}
+
+	return nil, &ErrOAuthUnsupportedGrantType{GrantType: r.GrantType}
+}
+
+func verifyPKCE(challenge, verifier string) bool {
+	hash := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(hash[:])
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
+
+func exchangeOAuthAuthorizationCode(s *xorm.Session, client *OAuthClient, r *OAuthTokenRequest) (*OAuthTokenResponse, error) {
+	code := &OAuthAuthorizationCode{}
+	exists, err := s.Where("code_hash = ?", hashOAuthSecret(r.Code)).Get(code)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || r.Code == "" {
+		return nil, &ErrOAuthInvalidGrant{}
+	}
+
+	// Codes can only be used once, no matter if the exchange succeeds
+	_, err = s.Where("id = ?", code.ID).Delete(&OAuthAuthorizationCode{})
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(code.ExpiresAt) ||
+		code.RedirectURI != r.RedirectURI ||
+		!verifyPKCE(code.CodeChallenge, r.CodeVerifier) {
+		return nil, &ErrOAuthInvalidGrant{}
+	}
+
+	authorization, err := getOAuthAuthorizationByID(s, code.AuthorizationID)
+	if err != nil {
+		if IsErrOAuthAuthorizationDoesNotExist(err) {
+			return nil, &ErrOAuthInvalidGrant{}
+		}
+		return nil, err
+	}
+	if authorization.ClientID != client.ID {
+		return nil, &ErrOAuthInvalidGrant{}
+	}
+
+	return issueOAuthTokens(s, client, authorization, code.Scopes)
+}
+
+func refreshOAuthToken(s *xorm.Session, client *OAuthClient, r *OAuthTokenRequest) (*OAuthTokenResponse, error) {
+	refreshToken := &OAuthRefreshToken{}
+	exists, err := s.Where("token_hash = ?", hashOAuthSecret(r.RefreshToken)).Get(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+	if !exists || r.RefreshToken == "" {
+		return nil, &ErrOAuthInvalidGrant{}
+	}
+
+	authorization, err := getOAuthAuthorizationByID(s, refreshToken.AuthorizationID)
+	if err != nil {
+		if IsErrOAuthAuthorizationDoesNotExist(err) {
+			return nil, &ErrOAuthInvalidGrant{}
+		}
+		return nil, err
+	}
+	if authorization.ClientID != client.ID {
+		return nil, &ErrOAuthInvalidGrant{}
+	}
+
+	_, err = s.Where("id = ?", refreshToken.ID).Delete(&OAuthRefreshToken{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Clean up access tokens which are not valid anymore
+	_, err = s.
+		Where("oauth_authorization_id = ? AND expires_at < ?", authorization.ID, time.Now()).
+		Delete(&APIToken{})
+	if err != nil {
+		return nil, err
+	}
+
+	return issueOAuthTokens(s, client, authorization, refreshToken.Scopes)
+}
+
+func issueOAuthTokens(s *xorm.Session, client *OAuthClient, authorization *OAuthAuthorization, scopes APIPermissions) (*OAuthTokenResponse, error) {
+	accessToken := &APIToken{
+		Title:                client.Name,
+		Permissions:          scopes,
+		ExpiresAt:            time.Now().Add(oauthAccessTokenTTL),
+		OwnerID:              authorization.UserID,
+		OAuthAuthorizationID: authorization.ID,
+	}
+	err := accessToken.generateToken()
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.Insert(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := utils.CryptoRandomString(64)
+	if err != nil {
+		return nil, err
+	}
+	_, err = s.Insert(&OAuthRefreshToken{
+		TokenHash:       hashOAuthSecret(refreshToken),
+		AuthorizationID: authorization.ID,
+		Scopes:          scopes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &OAuthTokenResponse{
+		AccessToken:  accessToken.Token,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(oauthAccessTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        scopes.scopeString(),
+	}, nil
+}
@@ -0,0 +1,176 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// OAuthAuthorization is the consent of a user for an oauth client to access their account.
+// Users see them as the apps they authorized.
+type OAuthAuthorization struct {
+	// The unique, numeric id of this authorization.
+	ID       int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"app"`
+	UserID   int64 `xorm:"bigint not null index" json:"-"`
+	ClientID int64 `xorm:"bigint not null index" json:"-"`
+
+	// The app the user authorized.
+	Client *OAuthClient `xorm:"-" json:"client"`
+	// All permissions the user granted the app.
+	Scopes APIPermissions `xorm:"json not null" json:"scopes"`
+
+	// A timestamp when the user first authorized the app. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when the user last authorized the app. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName holds the table name for oauth authorizations
+func (*OAuthAuthorization) TableName() string {
+	return "oauth_authorizations"
+}
+
+// OAuthAuthorizationCode is the short-lived code a client exchanges for tokens after the user authorized it.
+type OAuthAuthorizationCode struct {
+	ID              int64          `xorm:"bigint autoincr not null unique pk"`
+	CodeHash        string         `xorm:"varchar(64) not null unique"`
+	AuthorizationID int64          `xorm:"bigint not null index"`
+	RedirectURI     string         `xorm:"text not null"`
+	CodeChallenge   string         `xorm:"varchar(128) not null"`
+	Scopes          APIPermissions `xorm:"json not null"`
+	ExpiresAt       time.Time      `xorm:"not null"`
+	Created         time.Time      `xorm:"created not null"`
+}
+
+// TableName holds the table name for oauth authorization codes
+func (*OAuthAuthorizationCode) TableName() string {
+	return "oauth_authorization_codes"
+}
+
+// OAuthRefreshToken can be exchanged for a new access token by the client it was issued to.
+// Every refresh token can only be used once.
+type OAuthRefreshToken struct {
+	ID              int64          `xorm:"bigint autoincr not null unique pk"`
+	TokenHash       string         `xorm:"varchar(64) not null unique"`
+	AuthorizationID int64          `xorm:"bigint not null index"`
+	Scopes          APIPermissions `xorm:"json not null"`
+	Created         time.Time      `xorm:"created not null"`
+}
+
+// TableName holds the table name for oauth refresh tokens
+func (*OAuthRefreshToken) TableName() string {
+	return "oauth_refresh_tokens"
+}
+
+func getOAuthAuthorizationByID(s *xorm.Session, id int64) (authorization *OAuthAuthorization, err error) {
+	authorization = &OAuthAuthorization{}
+	exists, err := s.Where("id = ?", id).Get(authorization)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrOAuthAuthorizationDoesNotExist{AuthorizationID: id}
+	}
+	return
+}
+
+// revoke deletes an authorization together with all codes and tokens issued for it.
+func (authorization *OAuthAuthorization) revoke(s *xorm.Session) (err error) {
+	_, err = s.Where("oauth_authorization_id = ?", authorization.ID).Delete(&APIToken{})
+	if err != nil {
+		return err
+	}
+
+	for _, bean := range []interface{}{&OAuthAuthorizationCode{}, &OAuthRefreshToken{}} {
+		_, err = s.Where("authorization_id = ?", authorization.ID).Delete(bean)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.Where("id = ?", authorization.ID).Delete(&OAuthAuthorization{})
+	return err
+}
+
+// ReadAll returns all apps a user authorized
+// @Summary Get all authorized apps
+// @Description Returns all third-party apps the current user authorized to access their account via oauth.
+// @tags oauth
+// @Produce json
+// @Security JWTKeyAuth
+// @Param page query int false "The page number, used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of apps per page. This parameter is limited by the configured maximum of items per page."
+// @Success 200 {array} models.OAuthAuthorization "The authorized apps"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/authorized-apps [get]
+func (authorization *OAuthAuthorization) ReadAll(s *xorm.Session, a web.Auth, _ string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	authorizations := []*OAuthAuthorization{}
+	err = s.
+		Where("user_id = ?", a.GetID()).
+		OrderBy("id asc").
+		Limit(getLimitFromPageIndex(page, perPage)).
+		Find(&authorizations)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	clientIDs := make([]int64, 0, len(authorizations))
+	for _, auth := range authorizations {
+		clientIDs = append(clientIDs, auth.ClientID)
+	}
+
+	clients := make(map[int64]*OAuthClient)
+	if len(clientIDs) > 0 {
+		err = s.In("id", clientIDs).Find(&clients)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+	}
+
+	for _, auth := range authorizations {
+		auth.Client = clients[auth.ClientID]
+	}
+
+	totalCount, err := s.Where("user_id = ?", a.GetID()).Count(&OAuthAuthorization{})
+	return authorizations, len(authorizations), totalCount, err
+}
+
+// Delete revokes an authorized app
+// @Summary Revoke an authorized app
+// @Description Revokes the access of a third-party app to the account of the current user. All tokens issued to the app stop working immediately.
+// @tags oauth
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Authorization ID"
+// @Success 200 {object} models.Message "The app was successfully revoked."
+// @Failure 403 {object} web.HTTPError "The app was not authorized by the user."
+// @Failure 404 {object} web.HTTPError "The authorized app does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /user/authorized-apps/{id} [delete]
+func (authorization *OAuthAuthorization) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	return authorization.revoke(s)
+}
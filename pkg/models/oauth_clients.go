@@ -0,0 +1,321 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// OAuthClient is a third-party application which can ask users for access to their account through oauth2.
+type OAuthClient struct {
+	// The unique, numeric id of this client.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"client"`
+	// The public identifier of this client. Used as `client_id` in the oauth flows.
+	ClientID string `xorm:"varchar(50) not null unique" json:"client_id"`
+	// The secret of this client. Only visible after creation. Public clients don't have one.
+	ClientSecret     string `xorm:"-" json:"client_secret,omitempty"`
+	ClientSecretHash string `xorm:"varchar(64) null" json:"-"`
+	// The name of the app, shown to users when they are asked to authorize it.
+	Name string `xorm:"varchar(250) not null" json:"name" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// All urls users may be redirected to after authorizing the app. The redirect uri of an authorization request must match one of them exactly. Only https urls, http urls on loopback addresses and reverse domain custom schemes like `com.example.app:/callback` are allowed.
+	RedirectURIs []string `xorm:"json not null 'redirect_uris'" json:"redirect_uris" valid:"required"`
+	// Public clients like mobile or command line apps cannot keep a secret. They don't get a client secret and identify only with their client id.
+	Public bool `xorm:"bool default false" json:"public"`
+
+	OwnerID int64 `xorm:"bigint not null index" json:"-"`
+
+	// A timestamp when this client was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this client was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName holds the table name for oauth clients
+func (*OAuthClient) TableName() string {
+	return "oauth_clients"
+}
+
+func hashOAuthSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+func getOAuthClientByID(s *xorm.Session, id int64) (client *OAuthClient, err error) {
+	client = &OAuthClient{}
+	exists, err := s.Where("id = ?", id).Get(client)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrOAuthClientDoesNotExist{}
+	}
+	return
+}
+
+// GetOAuthClientByClientID returns an oauth client by its public client id.
+func GetOAuthClientByClientID(s *xorm.Session, clientID string) (client *OAuthClient, err error) {
+	client = &OAuthClient{}
+	exists, err := s.Where("client_id = ?", clientID).Get(client)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrOAuthClientDoesNotExist{ClientID: clientID}
+	}
+	return
+}
+
+// Authenticate checks the secret of a client. Public clients have no secret and only need to send their client id.
+func (client *OAuthClient) Authenticate(secret string) error {
+	if client.Public {
+		return nil
+	}
+
+	if secret == "" || subtle.ConstantTimeCompare([]byte(hashOAuthSecret(secret)), []byte(client.ClientSecretHash)) != 1 {
+		return &ErrOAuthInvalidClientCredentials{}
+	}
+
+	return nil
+}
+
+// HasRedirectURI checks if a redirect uri was registered for a client and is still allowed.
+func (client *OAuthClient) HasRedirectURI(redirectURI string) bool {
+	if !isValidOAuthRedirectURI(redirectURI) {
+		return false
+	}
+	for _, uri := range client.RedirectURIs {
+		if uri == redirectURI {
+			return true
+		}
+	}
+	return false
+}
+
+// reverseDomainScheme matches the custom schemes native apps use, which need to be a reverse domain name
+// like com.example.app (RFC 8252, section 7.1).
+var reverseDomainScheme = regexp.MustCompile(`^[a-z][a-z0-9-]*(\.[a-z0-9-]+)+$`)
+
+// isValidOAuthRedirectURI checks if the user can safely be sent to a redirect uri. Only https is allowed,
+// except for http on loopback addresses and the custom schemes of native apps. That rules out schemes like
+// javascript: or data: which would run in the frontend.
+func isValidOAuthRedirectURI(redirectURI string) bool {
+	parsed, err := url.Parse(redirectURI)
+	if err != nil || parsed.Fragment != "" {
+		return false
+	}
+
+	switch parsed.Scheme {
+	case "https":
+		return parsed.Host != ""
+	case "http":
+		// Apps listening on the device of the user (RFC 8252, section 7.3)
+		host := parsed.Hostname()
+		ip := net.ParseIP(host)
+		return strings.EqualFold(host, "localhost") || (ip != nil && ip.IsLoopback())
+	}
+
+	return reverseDomainScheme.MatchString(parsed.Scheme)
+}
+
+func validateOAuthRedirectURIs(redirectURIs []string) error {
+	if len(redirectURIs) == 0 {
+		return &ErrOAuthInvalidRedirectURI{}
+	}
+
+	for _, redirectURI := range redirectURIs {
+		if !isValidOAuthRedirectURI(redirectURI) {
+			return &ErrOAuthInvalidRedirectURI{RedirectURI: redirectURI}
+		}
+	}
+
+	return nil
+}
+
+// Create registers a new oauth client
+// @Summary Register an oauth client
+// @Description Registers a new third-party app which can then ask users for access to their account via oauth2. The client secret is only returned once. Public clients don't get a secret.
+// @tags oauth
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param client body models.OAuthClient true "The client"
+// @Success 201 {object} models.OAuthClient "The registered client with its secret."
+// @Failure 400 {object} web.HTTPError "Invalid client object provided."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /oauth/clients [put]
+func (client *OAuthClient) Create(s *xorm.Session, a web.Auth) (err error) {
+	if err := validateOAuthRedirectURIs(client.RedirectURIs); err != nil {
+		return err
+	}
+
+	client.ID = 0
+	client.OwnerID = a.GetID()
+	client.ClientID, err = utils.CryptoRandomString(32)
+	if err != nil {
+		return err
+	}
+
+	client.ClientSecret = ""
+	client.ClientSecretHash = ""
+	if !client.Public {
+		client.ClientSecret, err = utils.CryptoRandomString(48)
+		if err != nil {
+			return err
+		}
+		client.ClientSecretHash = hashOAuthSecret(client.ClientSecret)
+	}
+
+	_, err = s.Insert(client)
+	return err
+}
+
+// ReadOne returns an oauth client
+// @Summary Get one oauth client
+// @Description Returns one of the oauth clients the user registered.
+// @tags oauth
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Client ID"
+// @Success 200 {object} models.OAuthClient "The client"
+// @Failure 403 {object} web.HTTPError "The user did not register this client."
+// @Failure 404 {object} web.HTTPError "The client does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /oauth/clients/{id} [get]
+func (client *OAuthClient) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	c, err := getOAuthClientByID(s, client.ID)
+	if err != nil {
+		return err
+	}
+	*client = *c
+	return nil
+}
+
+// ReadAll returns all oauth clients a user registered
+// @Summary Get all oauth clients
+// @Description Returns all oauth clients the current user registered.
+// @tags oauth
+// @Produce json
+// @Security JWTKeyAuth
+// @Param page query int false "The page number, used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of clients per page. This parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search clients by their name."
+// @Success 200 {array} models.OAuthClient "The clients"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /oauth/clients [get]
+func (client *OAuthClient) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	clients := []*OAuthClient{}
+
+	var where builder.Cond = builder.Eq{"owner_id": a.GetID()}
+	if search != "" {
+		where = builder.And(
+			where,
+			db.ILIKE("name", search),
+		)
+	}
+
+	err = s.
+		Where(where).
+		OrderBy("id asc").
+		Limit(getLimitFromPageIndex(page, perPage)).
+		Find(&clients)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	totalCount, err := s.Where(where).Count(&OAuthClient{})
+	return clients, len(clients), totalCount, err
+}
+
+// Update updates an oauth client
+// @Summary Update an oauth client
+// @Description Updates the name and redirect uris of an oauth client.
+// @tags oauth
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Client ID"
+// @Param client body models.OAuthClient true "The client with updated values"
+// @Success 200 {object} models.OAuthClient "The updated client."
+// @Failure 400 {object} web.HTTPError "Invalid client object provided."
+// @Failure 403 {object} web.HTTPError "The user did not register this client."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /oauth/clients/{id} [post]
+func (client *OAuthClient) Update(s *xorm.Session, _ web.Auth) (err error) {
+	if err := validateOAuthRedirectURIs(client.RedirectURIs); err != nil {
+		return err
+	}
+
+	_, err = s.
+		Where("id = ?", client.ID).
+		Cols("name", "redirect_uris").
+		Update(client)
+	if err != nil {
+		return err
+	}
+
+	return client.ReadOne(s, nil)
+}
+
+// Delete deletes an oauth client
+// @Summary Delete an oauth client
+// @Description Deletes an oauth client. All users who authorized it lose their authorization and all tokens issued to it stop working.
+// @tags oauth
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Client ID"
+// @Success 200 {object} models.Message "The client was successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user did not register this client."
+// @Failure 404 {object} web.HTTPError "The client does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /oauth/clients/{id} [delete]
+func (client *OAuthClient) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	authorizations := []*OAuthAuthorization{}
+	err = s.Where("client_id = ?", client.ID).Find(&authorizations)
+	if err != nil {
+		return err
+	}
+
+	for _, authorization := range authorizations {
+		err = authorization.revoke(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.Where("id = ?", client.ID).Delete(&OAuthClient{})
+	return err
+}
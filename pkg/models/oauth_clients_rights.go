@@ -0,0 +1,57 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanCreate checks if a user can register an oauth client
+func (client *OAuthClient) CanCreate(_ *xorm.Session, a web.Auth) (bool, error) {
+	_, is := a.(*LinkSharing)
+	return !is, nil
+}
+
+func (client *OAuthClient) isOwner(s *xorm.Session, a web.Auth) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	c, err := getOAuthClientByID(s, client.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return c.OwnerID == a.GetID(), nil
+}
+
+// CanRead checks if a user can see an oauth client. Only the user who registered it can.
+func (client *OAuthClient) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	can, err := client.isOwner(s, a)
+	return can, int(RightAdmin), err
+}
+
+// CanUpdate checks if a user can update an oauth client
+func (client *OAuthClient) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	return client.isOwner(s, a)
+}
+
+// CanDelete checks if a user can delete an oauth client
+func (client *OAuthClient) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return client.isOwner(s, a)
+}
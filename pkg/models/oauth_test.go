@@ -0,0 +1,279 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOAuthCodeVerifier = "testverifier-0123456789-0123456789-0123456789"
+
+func TestOAuthClient(t *testing.T) {
+	t.Run("create confidential", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		client := &OAuthClient{Name: "New app", RedirectURIs: []string{"https://new.example.com/cb"}}
+		err := client.Create(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.NotEmpty(t, client.ClientID)
+		assert.NotEmpty(t, client.ClientSecret)
+		require.NoError(t, client.Authenticate(client.ClientSecret))
+		require.Error(t, client.Authenticate("wrong"))
+	})
+	t.Run("create public", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		client := &OAuthClient{Name: "New cli", RedirectURIs: []string{"com.example.app:/cb"}, Public: true}
+		err := client.Create(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.Empty(t, client.ClientSecret)
+		require.NoError(t, client.Authenticate(""))
+	})
+	t.Run("invalid redirect uri", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		for _, uri := range []string{
+			"/relative",
+			"javascript:alert(document.cookie)//",
+			"JavaScript:alert(1)",
+			"data:text/html,<script>alert(1)</script>",
+			"vbscript:msgbox(1)",
+			"file:///etc/passwd",
+			"http://app.example.com/callback",
+			"https://app.example.com/callback#fragment",
+			"myapp:/callback",
+		} {
+			client := &OAuthClient{Name: "New app", RedirectURIs: []string{uri}}
+			err := client.Create(s, &user.User{ID: 1})
+			require.Error(t, err, uri)
+			assert.True(t, IsErrOAuthInvalidRedirectURI(err), uri)
+		}
+	})
+	t.Run("loopback redirect uri", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		client := &OAuthClient{Name: "New cli", RedirectURIs: []string{"http://127.0.0.1:8080/cb", "http://localhost/cb", "http://[::1]/cb"}, Public: true}
+		err := client.Create(s, &user.User{ID: 1})
+		require.NoError(t, err)
+	})
+	t.Run("rights", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&OAuthClient{ID: 1}).CanUpdate(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.True(t, can)
+		can, err = (&OAuthClient{ID: 1}).CanDelete(s, &user.User{ID: 2})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("delete", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&OAuthClient{ID: 1}).Delete(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		db.AssertMissing(t, "oauth_clients", map[string]interface{}{"id": 1})
+		db.AssertMissing(t, "oauth_authorizations", map[string]interface{}{"client_id": 1})
+		db.AssertMissing(t, "oauth_refresh_tokens", map[string]interface{}{"authorization_id": 1})
+	})
+}
+
+func TestOAuthAuthorization(t *testing.T) {
+	t.Run("read all", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		result, _, _, err := (&OAuthAuthorization{}).ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+		require.NoError(t, err)
+		authorizations := result.([]*OAuthAuthorization)
+		require.Len(t, authorizations, 1)
+		assert.Equal(t, "Test app", authorizations[0].Client.Name)
+		assert.Equal(t, []string{"read_all"}, authorizations[0].Scopes["tasks"])
+	})
+	t.Run("revoke", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		u := &user.User{ID: 1}
+
+		authorization := &OAuthAuthorization{ID: 1}
+		can, err := authorization.CanDelete(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = authorization.Delete(s, u)
+		require.NoError(t, err)
+		db.AssertMissing(t, "oauth_authorizations", map[string]interface{}{"id": 1})
+		db.AssertMissing(t, "oauth_authorization_codes", map[string]interface{}{"authorization_id": 1})
+	})
+	t.Run("revoke for another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&OAuthAuthorization{ID: 1}).CanDelete(s, &user.User{ID: 2})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestExchangeOAuthToken(t *testing.T) {
+	t.Run("authorization code", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		response, err := ExchangeOAuthToken(s, &OAuthTokenRequest{
+			GrantType:    OAuthGrantTypeAuthorizationCode,
+			Code:         "testcode1",
+			RedirectURI:  "https://app.example.com/callback",
+			CodeVerifier: testOAuthCodeVerifier,
+			ClientID:     "testclient1",
+			ClientSecret: "testsecret1",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Bearer", response.TokenType)
+		assert.Equal(t, "tasks:read_all", response.Scope)
+		assert.NotEmpty(t, response.RefreshToken)
+
+		token, err := GetTokenFromTokenString(s, response.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), token.OwnerID)
+		assert.Equal(t, int64(1), token.OAuthAuthorizationID)
+		db.AssertMissing(t, "oauth_authorization_codes", map[string]interface{}{"id": 1})
+
+		// Access tokens of oauth apps are not listed with the user's own tokens
+		tokens, _, _, err := (&APIToken{}).ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+		require.NoError(t, err)
+		assert.Len(t, tokens, 2)
+	})
+	t.Run("wrong code verifier", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := ExchangeOAuthToken(s, &OAuthTokenRequest{
+			GrantType:    OAuthGrantTypeAuthorizationCode,
+			Code:         "testcode1",
+			RedirectURI:  "https://app.example.com/callback",
+			CodeVerifier: "wrong",
+			ClientID:     "testclient1",
+			ClientSecret: "testsecret1",
+		})
+		require.Error(t, err)
+		assert.True(t, IsErrOAuthInvalidGrant(err))
+		db.AssertMissing(t, "oauth_authorization_codes", map[string]interface{}{"id": 1})
+	})
+	t.Run("expired code", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := ExchangeOAuthToken(s, &OAuthTokenRequest{
+			GrantType:    OAuthGrantTypeAuthorizationCode,
+			Code:         "testcode2",
+			RedirectURI:  "https://app.example.com/callback",
+			CodeVerifier: testOAuthCodeVerifier,
+			ClientID:     "testclient1",
+			ClientSecret: "testsecret1",
+		})
+		require.Error(t, err)
+		assert.True(t, IsErrOAuthInvalidGrant(err))
+	})
+	t.Run("wrong client secret", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := ExchangeOAuthToken(s, &OAuthTokenRequest{
+			GrantType:    OAuthGrantTypeAuthorizationCode,
+			Code:         "testcode1",
+			RedirectURI:  "https://app.example.com/callback",
+			CodeVerifier: testOAuthCodeVerifier,
+			ClientID:     "testclient1",
+			ClientSecret: "wrong",
+		})
+		require.Error(t, err)
+		assert.True(t, IsErrOAuthInvalidClientCredentials(err))
+	})
+	t.Run("code of another client", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := ExchangeOAuthToken(s, &OAuthTokenRequest{
+			GrantType:    OAuthGrantTypeAuthorizationCode,
+			Code:         "testcode1",
+			RedirectURI:  "https://app.example.com/callback",
+			CodeVerifier: testOAuthCodeVerifier,
+			ClientID:     "testclient2",
+		})
+		require.Error(t, err)
+		assert.True(t, IsErrOAuthInvalidGrant(err))
+	})
+	t.Run("refresh token", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		request := &OAuthTokenRequest{
+			GrantType:    OAuthGrantTypeRefreshToken,
+			RefreshToken: "testrefreshtoken1",
+			ClientID:     "testclient1",
+			ClientSecret: "testsecret1",
+		}
+		response, err := ExchangeOAuthToken(s, request)
+		require.NoError(t, err)
+		assert.NotEmpty(t, response.AccessToken)
+		assert.NotEqual(t, "testrefreshtoken1", response.RefreshToken)
+
+		// Refresh tokens can only be used once
+		_, err = ExchangeOAuthToken(s, request)
+		require.Error(t, err)
+		assert.True(t, IsErrOAuthInvalidGrant(err))
+	})
+	t.Run("unsupported grant type", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := ExchangeOAuthToken(s, &OAuthTokenRequest{
+			GrantType:    "password",
+			ClientID:     "testclient1",
+			ClientSecret: "testsecret1",
+		})
+		require.Error(t, err)
+		assert.True(t, IsErrOAuthUnsupportedGrantType(err))
+	})
+}
@@ -97,6 +97,11 @@ type ProjectWithTasksAndBuckets struct {
 	TaskBuckets      []*TaskBucket   `xorm:"-" json:"task_buckets"`
 	Positions        []*TaskPosition `xorm:"-" json:"positions"`
 	BackgroundFileID int64           `xorm:"null" json:"background_file_id"`
+	// Only used for migration. Users the project should be shared with after it was created.
+	Users []*ProjectUser `xorm:"-" json:"-"`
+	// Only used for migration. The id of the project in the service it was migrated from. It is used to resume
+	// a migration which did not finish, projects without one are created again every time.
+	SourceID string `xorm:"-" json:"-"`
 }
 
 // TableName returns a better name for the projects table
@@ -145,6 +150,13 @@ var FavoritesPseudoProject = Project{
 			ViewKind:  ProjectViewKindTable,
 			Position:  300,
 		},
+		{
+			ID:        -4,
+			ProjectID: FavoritesPseudoProjectID,
+			Title:     "Calendar",
+			ViewKind:  ProjectViewKindCalendar,
+			Position:  400,
+		},
 	},
 
 	Created: time.Now(),
@@ -178,6 +190,9 @@ func (p *Project) ReadAll(s *xorm.Session, a web.Auth, search string, page int,
 		err = addProjectDetails(s, projects, a)
 		if err == nil && len(projects) > 0 {
 			projects[0].ParentProjectID = 0
+			if !shareAuth.targetsProject() {
+				projects[0].Views = shareAuth.filterViews(projects[0].Views)
+			}
 		}
 		return projects, 0, 0, err
 	}
@@ -203,9 +218,12 @@ func (p *Project) ReadAll(s *xorm.Session, a web.Auth, search string, page int,
 	/////////////////
 	// Saved Filters
 
-	savedFiltersProject, err := getSavedFilterProjects(s, doer)
-	if err != nil {
-		return nil, 0, 0, err
+	var savedFiltersProject []*Project
+	if doer.APITokenScope == nil || len(doer.APITokenScope.ProjectIDs) == 0 {
+		savedFiltersProject, err = getSavedFilterProjects(s, doer)
+		if err != nil {
+			return nil, 0, 0, err
+		}
 	}
 
 	if len(savedFiltersProject) > 0 {
@@ -541,6 +559,13 @@ func getRawProjectsForUser(s *xorm.Session, opts *projectOptions) (projects []*P
 		return
 	}
 
+	scope := opts.user.APITokenScope
+	if scope != nil && len(scope.ProjectIDs) > 0 {
+		// Favorites contain tasks from all projects, that's why they are not added for limited tokens
+		allProjects = filterProjectsForAPITokenScope(scope, allProjects)
+		return allProjects, len(allProjects), int64(len(allProjects)), nil
+	}
+
 	favoriteCount, err := s.
 		Where(builder.And(
 			builder.Eq{"user_id": opts.user.ID},
@@ -1136,7 +1161,7 @@ func (p *Project) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
-	_, err = s.Where("project_id = ?", p.ID).Delete(&LinkSharing{})
+	_, err = deleteLinkShares(s, builder.Eq{"project_id": p.ID})
 	if err != nil {
 		return
 	}
@@ -1151,6 +1176,11 @@ func (p *Project) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
+	err = deleteProjectAutomations(s, p.ID)
+	if err != nil {
+		return
+	}
+
 	// Delete the project
 	_, err = s.ID(p.ID).Delete(&Project{})
 	if err != nil {
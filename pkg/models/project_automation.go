@@ -0,0 +1,654 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+const (
+	// automationLoopProtectionWindow is the time frame in which the executions of an automation for a single task are counted.
+	automationLoopProtectionWindow = time.Minute
+	// automationLoopProtectionMaxExecutions is the maximum number of times an automation will run for the same task
+	// within automationLoopProtectionWindow. This prevents automations from triggering each other (or themselves) endlessly.
+	automationLoopProtectionMaxExecutions = 5
+)
+
+// ProjectAutomation is a rule which runs actions on a project whenever its trigger fires.
+type ProjectAutomation struct {
+	// The unique, numeric id of this automation.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"automation"`
+	// The project this automation belongs to.
+	ProjectID int64 `xorm:"bigint not null index" json:"project_id" param:"project"`
+	// The title of this automation.
+	Title string `xorm:"varchar(250) not null" json:"title" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+	// If true, the automation will not run.
+	Disabled bool `xorm:"not null default false" json:"disabled"`
+
+	// The name of the event which triggers this automation. Check the /automations/events endpoint for all available events.
+	// Either this or trigger_schedule must be set.
+	TriggerEvent string `xorm:"varchar(250) null" json:"trigger_event"`
+	// A cron expression like "0 9 * * 1" which triggers this automation on a schedule.
+	// Either this or trigger_event must be set.
+	TriggerSchedule string `xorm:"varchar(250) null" json:"trigger_schedule"`
+
+	// The conditions a task must match for the actions to run, in the same syntax as task filters.
+	// When triggered by an event, the actions only run if the task of the event matches. When triggered by a schedule,
+	// the actions run once for every task in the project which matches. Leave empty to always run.
+	Filter string `xorm:"text null" json:"filter"`
+	// The timezone used to evaluate dates in the filter. Defaults to the timezone of the user who created the automation.
+	FilterTimezone string `xorm:"varchar(250) null" json:"filter_timezone"`
+
+	// The actions to run, in order.
+	Actions []*ProjectAutomationAction `xorm:"json not null" json:"actions" valid:"required"`
+
+	// When the automation was last triggered by its schedule.
+	LastRunAt time.Time `xorm:"DATETIME null" json:"last_run_at"`
+
+	// The user who initially created the automation. Actions are executed on behalf of this user.
+	CreatedBy   *user.User `xorm:"-" json:"created_by" valid:"-"`
+	CreatedByID int64      `xorm:"bigint not null" json:"-"`
+
+	// A timestamp when this automation was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this automation was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for project automations
+func (*ProjectAutomation) TableName() string {
+	return "project_automations"
+}
+
+var availableAutomationEvents map[string]bool
+var availableAutomationEventsLock *sync.Mutex
+
+func init() {
+	availableAutomationEvents = make(map[string]bool)
+	availableAutomationEventsLock = &sync.Mutex{}
+}
+
+// RegisterEventForAutomation makes an event available as an automation trigger
+func RegisterEventForAutomation(event events.Event) {
+	availableAutomationEventsLock.Lock()
+	defer availableAutomationEventsLock.Unlock()
+
+	availableAutomationEvents[event.Name()] = true
+	events.RegisterListener(event.Name(), &AutomationListener{
+		EventName: event.Name(),
+	})
+}
+
+// GetAvailableAutomationEvents returns all events which can be used as an automation trigger
+func GetAvailableAutomationEvents() []string {
+	evts := []string{}
+	for e := range availableAutomationEvents {
+		evts = append(evts, e)
+	}
+
+	sort.Strings(evts)
+
+	return evts
+}
+
+func getProjectAutomationByID(s *xorm.Session, id int64) (pa *ProjectAutomation, err error) {
+	pa = &ProjectAutomation{}
+	exists, err := s.Where("id = ?", id).Get(pa)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrProjectAutomationDoesNotExist{AutomationID: id}
+	}
+	return
+}
+
+// hasTaskTrigger returns whether the automation runs in the context of a single task.
+func (pa *ProjectAutomation) hasTaskTrigger() bool {
+	if pa.TriggerEvent != "" {
+		return strings.HasPrefix(pa.TriggerEvent, "task.")
+	}
+	return pa.Filter != ""
+}
+
+func (pa *ProjectAutomation) getFilterTimezone(s *xorm.Session) (string, error) {
+	if pa.FilterTimezone != "" {
+		return pa.FilterTimezone, nil
+	}
+
+	u, err := user.GetUserByID(s, pa.CreatedByID)
+	if err != nil {
+		return "", err
+	}
+	return u.Timezone, nil
+}
+
+func (pa *ProjectAutomation) validate(s *xorm.Session, a web.Auth) (err error) {
+	if (pa.TriggerEvent == "") == (pa.TriggerSchedule == "") {
+		return &ErrInvalidProjectAutomationTrigger{Reason: "exactly one of trigger_event and trigger_schedule must be set"}
+	}
+
+	if pa.TriggerEvent != "" {
+		if _, has := availableAutomationEvents[pa.TriggerEvent]; !has {
+			return &ErrInvalidProjectAutomationTrigger{Reason: "unknown event " + pa.TriggerEvent}
+		}
+	}
+
+	if pa.TriggerSchedule != "" {
+		_, err = cron.NextRun(pa.TriggerSchedule, time.Now())
+		if err != nil {
+			return &ErrInvalidProjectAutomationTrigger{Reason: "invalid schedule: " + err.Error()}
+		}
+	}
+
+	if pa.Filter != "" {
+		_, err = getTaskFiltersFromFilterString(pa.Filter, pa.FilterTimezone)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(pa.Actions) == 0 {
+		return InvalidFieldError([]string{"actions"})
+	}
+
+	for _, action := range pa.Actions {
+		err = action.validate(s, a, pa)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Create creates a new project automation
+// @Summary Create a project automation
+// @Description Create an automation rule which runs actions on a project when its trigger fires.
+// @tags automations
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param automation body models.ProjectAutomation true "The automation with required fields"
+// @Success 200 {object} models.ProjectAutomation "The created automation."
+// @Failure 400 {object} web.HTTPError "Invalid automation object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/automations [put]
+func (pa *ProjectAutomation) Create(s *xorm.Session, a web.Auth) (err error) {
+	err = pa.validate(s, a)
+	if err != nil {
+		return err
+	}
+
+	pa.ID = 0
+	pa.LastRunAt = time.Time{}
+	pa.CreatedByID = a.GetID()
+	_, err = s.Insert(pa)
+	if err != nil {
+		return err
+	}
+
+	pa.CreatedBy, err = user.GetUserByID(s, pa.CreatedByID)
+	return
+}
+
+// ReadOne returns a single project automation
+// @Summary Get one project automation
+// @Description Returns a single automation of a project.
+// @tags automations
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param automation path int true "Automation ID"
+// @Success 200 {object} models.ProjectAutomation "The automation"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 404 {object} web.HTTPError "The automation does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/automations/{automation} [get]
+func (pa *ProjectAutomation) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	automation, err := getProjectAutomationByID(s, pa.ID)
+	if err != nil {
+		return err
+	}
+
+	*pa = *automation
+	pa.hideSecrets()
+
+	pa.CreatedBy, err = user.GetUserByID(s, pa.CreatedByID)
+	return
+}
+
+// ReadAll returns all automations of a project
+// @Summary Get all automations of a project
+// @Description Returns all automation rules of a project.
+// @tags automations
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Success 200 {array} models.ProjectAutomation "The automations"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/automations [get]
+func (pa *ProjectAutomation) ReadAll(s *xorm.Session, a web.Auth, _ string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	can, err := pa.canDoProjectAutomation(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+	query := s.Where("project_id = ?", pa.ProjectID).
+		OrderBy("id asc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+
+	automations := []*ProjectAutomation{}
+	err = query.Find(&automations)
+	if err != nil {
+		return
+	}
+
+	total, err := s.Where("project_id = ?", pa.ProjectID).
+		Count(&ProjectAutomation{})
+	if err != nil {
+		return
+	}
+
+	userIDs := []int64{}
+	for _, automation := range automations {
+		userIDs = append(userIDs, automation.CreatedByID)
+	}
+
+	users, err := user.GetUsersByIDs(s, userIDs)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	for _, automation := range automations {
+		automation.hideSecrets()
+		automation.CreatedBy = users[automation.CreatedByID]
+	}
+
+	return automations, len(automations), total, err
+}
+
+// Update updates a project automation
+// @Summary Update a project automation
+// @Description Updates the trigger, conditions and actions of an automation.
+// @tags automations
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param automation path int true "Automation ID"
+// @Param automationBody body models.ProjectAutomation true "The automation with updated values"
+// @Success 200 {object} models.ProjectAutomation "The updated automation."
+// @Failure 400 {object} web.HTTPError "Invalid automation object provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 404 {object} web.HTTPError "The automation does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/automations/{automation} [post]
+func (pa *ProjectAutomation) Update(s *xorm.Session, a web.Auth) (err error) {
+	old, err := getProjectAutomationByID(s, pa.ID)
+	if err != nil {
+		return err
+	}
+	pa.CreatedByID = old.CreatedByID
+	pa.keepSecrets(old)
+
+	err = pa.validate(s, a)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("id = ?", pa.ID).
+		Cols(
+			"title",
+			"disabled",
+			"trigger_event",
+			"trigger_schedule",
+			"filter",
+			"filter_timezone",
+			"actions",
+		).
+		Update(pa)
+	if err != nil {
+		return err
+	}
+
+	pa.hideSecrets()
+	return
+}
+
+// Delete deletes a project automation
+// @Summary Delete a project automation
+// @Description Deletes an automation and its execution log.
+// @tags automations
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param automation path int true "Automation ID"
+// @Success 200 {object} models.Message "Successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 404 {object} web.HTTPError "The automation does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/automations/{automation} [delete]
+func (pa *ProjectAutomation) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	_, err = s.Where("automation_id = ?", pa.ID).Delete(&ProjectAutomationLog{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("id = ?", pa.ID).Delete(&ProjectAutomation{})
+	return
+}
+
+// deleteProjectAutomations removes all automations of a project together with their execution logs.
+func deleteProjectAutomations(s *xorm.Session, projectID int64) (err error) {
+	_, err = s.
+		Where(builder.In("automation_id",
+			builder.Select("id").From("project_automations").Where(builder.Eq{"project_id": projectID}),
+		)).
+		Delete(&ProjectAutomationLog{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("project_id = ?", projectID).Delete(&ProjectAutomation{})
+	return
+}
+
+// hideSecrets removes webhook secrets so that they are not sent to the client
+func (pa *ProjectAutomation) hideSecrets() {
+	for _, action := range pa.Actions {
+		action.Secret = ""
+	}
+}
+
+// keepSecrets keeps the webhook secrets of an existing automation when an update does not provide them
+func (pa *ProjectAutomation) keepSecrets(old *ProjectAutomation) {
+	for i, action := range pa.Actions {
+		if action.Secret != "" || i >= len(old.Actions) {
+			continue
+		}
+		if old.Actions[i].Kind == action.Kind && old.Actions[i].URL == action.URL {
+			action.Secret = old.Actions[i].Secret
+		}
+	}
+}
+
+// getMatchingTaskIDs returns the ids of all tasks in the automation's project which match its filter.
+// If taskID is not 0, only that task is checked.
+func (pa *ProjectAutomation) getMatchingTaskIDs(s *xorm.Session, taskID int64) (taskIDs []int64, err error) {
+	conds := []builder.Cond{
+		builder.Eq{"project_id": pa.ProjectID},
+	}
+
+	if taskID != 0 {
+		conds = append(conds, builder.Eq{"id": taskID})
+	}
+
+	if pa.Filter != "" {
+		timezone, err := pa.getFilterTimezone(s)
+		if err != nil {
+			return nil, err
+		}
+
+		parsedFilters, err := getTaskFiltersFromFilterString(pa.Filter, timezone)
+		if err != nil {
+			return nil, err
+		}
+
+		filterCond, err := convertFiltersToDBFilterCond(parsedFilters, false)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, filterCond)
+	}
+
+	taskIDs = []int64{}
+	err = s.
+		Table("tasks").
+		Cols("id").
+		Where(builder.And(conds...)).
+		OrderBy("id asc").
+		Find(&taskIDs)
+	return
+}
+
+// isLooping checks whether the automation already ran too often for the same task in a short time frame,
+// which means it is most likely triggering itself through the events its actions cause.
+func (pa *ProjectAutomation) isLooping(s *xorm.Session, taskID int64) (bool, error) {
+	if taskID == 0 {
+		return false, nil
+	}
+
+	count, err := s.
+		Where("automation_id = ? AND task_id = ? AND status != ? AND created > ?",
+			pa.ID, taskID, ProjectAutomationLogStatusSkipped, time.Now().Add(-automationLoopProtectionWindow)).
+		Count(&ProjectAutomationLog{})
+	if err != nil {
+		return false, err
+	}
+
+	return count >= automationLoopProtectionMaxExecutions, nil
+}
+
+// execute runs all actions of the automation for a task. If the automation does not run in the context of a task,
+// task is nil. Every execution is recorded in the execution log.
+func (pa *ProjectAutomation) execute(s *xorm.Session, trigger string, task *Task) (err error) {
+	var taskID int64
+	if task != nil {
+		taskID = task.ID
+	}
+
+	looping, err := pa.isLooping(s, taskID)
+	if err != nil {
+		return err
+	}
+	if looping {
+		log.Warningf("Automation %d ran too often for task %d, not running it again to prevent a loop", pa.ID, taskID)
+		return addProjectAutomationLog(s, pa, trigger, taskID, ProjectAutomationLogStatusSkipped, "Loop protection: the automation ran too often for this task in a short time.")
+	}
+
+	doer, err := user.GetUserByID(s, pa.CreatedByID)
+	if err != nil {
+		return err
+	}
+
+	// The creator might have lost access to the project since creating the automation
+	can, err := pa.canDoProjectAutomation(s, doer)
+	if err != nil {
+		return err
+	}
+	if !can {
+		return ErrGenericForbidden{}
+	}
+
+	for _, action := range pa.Actions {
+		err = action.execute(s, doer, pa, trigger, task)
+		if err != nil {
+			return err
+		}
+	}
+
+	return addProjectAutomationLog(s, pa, trigger, taskID, ProjectAutomationLogStatusSuccess, "")
+}
+
+// run executes the automation in its own session. When one of the actions fails, the remaining actions are not run
+// and the failure is recorded in the execution log.
+func (pa *ProjectAutomation) run(trigger string, taskID int64) {
+	s := db.NewSession()
+	defer s.Close()
+
+	var task *Task
+	if taskID != 0 {
+		t, err := GetTaskByIDSimple(s, taskID)
+		if err != nil {
+			log.Errorf("Could not get task %d for automation %d: %s", taskID, pa.ID, err)
+			return
+		}
+		task = &t
+	}
+
+	err := pa.execute(s, trigger, task)
+	if err == nil {
+		return
+	}
+
+	log.Errorf("Could not run automation %d for task %d: %s", pa.ID, taskID, err)
+
+	err = addProjectAutomationLog(s, pa, trigger, taskID, ProjectAutomationLogStatusFailed, err.Error())
+	if err != nil {
+		log.Errorf("Could not save execution log for automation %d: %s", pa.ID, err)
+	}
+}
+
+// runAutomationsForEvent runs all automations of a project which are triggered by an event.
+func runAutomationsForEvent(s *xorm.Session, eventName string, projectID int64, taskID int64) error {
+	automations := []*ProjectAutomation{}
+	err := s.
+		Where("project_id = ? AND trigger_event = ? AND disabled = ?", projectID, eventName, false).
+		OrderBy("id asc").
+		Find(&automations)
+	if err != nil {
+		return err
+	}
+
+	for _, pa := range automations {
+		if pa.hasTaskTrigger() && taskID == 0 {
+			continue
+		}
+
+		if taskID != 0 {
+			taskIDs, err := pa.getMatchingTaskIDs(s, taskID)
+			if err != nil {
+				log.Errorf("Could not evaluate the conditions of automation %d: %s", pa.ID, err)
+				continue
+			}
+			if len(taskIDs) == 0 {
+				continue
+			}
+		}
+
+		pa.run(eventName, taskID)
+	}
+
+	return nil
+}
+
+// runScheduled runs an automation triggered by its schedule, once for every matching task or once without
+// a task if it has no conditions.
+func (pa *ProjectAutomation) runScheduled(s *xorm.Session, now time.Time) error {
+	pa.LastRunAt = now
+	_, err := s.Where("id = ?", pa.ID).Cols("last_run_at").NoAutoTime().Update(pa)
+	if err != nil {
+		return err
+	}
+
+	const trigger = "schedule"
+
+	if !pa.hasTaskTrigger() {
+		pa.run(trigger, 0)
+		return nil
+	}
+
+	taskIDs, err := pa.getMatchingTaskIDs(s, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, taskID := range taskIDs {
+		pa.run(trigger, taskID)
+	}
+
+	return nil
+}
+
+// RegisterAutomationCron registers the cron which runs automations with a schedule trigger
+func RegisterAutomationCron() {
+	if !config.AutomationsEnabled.GetBool() {
+		return
+	}
+
+	err := cron.Schedule("* * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		automations := []*ProjectAutomation{}
+		err := s.
+			Where("trigger_schedule != '' AND trigger_schedule IS NOT NULL AND disabled = ?", false).
+			Find(&automations)
+		if err != nil {
+			log.Errorf("[Automations] Could not get automations with a schedule: %s", err)
+			return
+		}
+
+		now := time.Now()
+		for _, pa := range automations {
+			lastRun := pa.LastRunAt
+			if lastRun.IsZero() {
+				lastRun = pa.Created
+			}
+
+			next, err := cron.NextRun(pa.TriggerSchedule, lastRun)
+			if err != nil {
+				log.Errorf("[Automations] Invalid schedule for automation %d: %s", pa.ID, err)
+				continue
+			}
+			if next.After(now) {
+				continue
+			}
+
+			log.Debugf("[Automations] Running scheduled automation %d", pa.ID)
+			err = pa.runScheduled(s, now)
+			if err != nil {
+				log.Errorf("[Automations] Could not run scheduled automation %d: %s", pa.ID, err)
+			}
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register automation cron: %s", err)
+	}
+}
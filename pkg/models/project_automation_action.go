@@ -0,0 +1,341 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"net/url"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/xorm"
+)
+
+// ProjectAutomationActionKind is the kind of action an automation runs
+type ProjectAutomationActionKind string
+
+const (
+	// ProjectAutomationActionUpdateTask updates the fields of the task which triggered the automation.
+	ProjectAutomationActionUpdateTask ProjectAutomationActionKind = "update_task"
+	// ProjectAutomationActionMoveTask moves the task which triggered the automation to another project.
+	ProjectAutomationActionMoveTask ProjectAutomationActionKind = "move_task"
+	// ProjectAutomationActionCreateTask creates a new task.
+	ProjectAutomationActionCreateTask ProjectAutomationActionKind = "create_task"
+	// ProjectAutomationActionNotifyUsers sends a notification to users.
+	ProjectAutomationActionNotifyUsers ProjectAutomationActionKind = "notify_users"
+	// ProjectAutomationActionCallWebhook sends a POST request with the automation and task to a url.
+	ProjectAutomationActionCallWebhook ProjectAutomationActionKind = "call_webhook"
+)
+
+// The task fields an update_task action can change
+var automationUpdatableTaskFields = map[string]bool{
+	"title":        true,
+	"description":  true,
+	"done":         true,
+	"priority":     true,
+	"percent_done": true,
+	"due_date":     true,
+	"start_date":   true,
+	"end_date":     true,
+	"hex_color":    true,
+}
+
+// ProjectAutomationAction is one action of a project automation
+type ProjectAutomationAction struct {
+	// The kind of this action. One of update_task, move_task, create_task, notify_users or call_webhook.
+	Kind ProjectAutomationActionKind `json:"kind"`
+	// The task fields to change with update_task. Possible values are title, description, done, priority, percent_done, due_date, start_date, end_date and hex_color.
+	Fields []string `json:"fields,omitempty"`
+	// The new values of the fields for update_task or the task to create with create_task.
+	Task *Task `json:"task,omitempty"`
+	// The project to move the task to with move_task or to create the new task in with create_task. Defaults to the project of the automation for create_task.
+	ProjectID int64 `json:"project_id,omitempty"`
+	// The users to notify with notify_users.
+	UserIDs []int64 `json:"user_ids,omitempty"`
+	// The message of the notification sent with notify_users.
+	Message string `json:"message,omitempty"`
+	// The http or https url a POST request is sent to with call_webhook. Only available if webhooks are enabled.
+	URL string `json:"url,omitempty"`
+	// If provided, webhook requests will be signed using HMAC, the same way as regular webhooks.
+	Secret string `json:"secret,omitempty"`
+}
+
+func (action *ProjectAutomationAction) validate(s *xorm.Session, a web.Auth, pa *ProjectAutomation) (err error) {
+	invalid := func(reason string) error {
+		return &ErrInvalidProjectAutomationAction{Kind: action.Kind, Reason: reason}
+	}
+
+	switch action.Kind {
+	case ProjectAutomationActionUpdateTask, ProjectAutomationActionMoveTask:
+		if !pa.hasTaskTrigger() {
+			return invalid("this action needs a task, but the trigger does not provide one")
+		}
+	}
+
+	switch action.Kind {
+	case ProjectAutomationActionUpdateTask:
+		if len(action.Fields) == 0 || action.Task == nil {
+			return invalid("fields and task must be set")
+		}
+		for _, field := range action.Fields {
+			if !automationUpdatableTaskFields[field] {
+				return invalid("field " + field + " cannot be updated")
+			}
+		}
+	case ProjectAutomationActionMoveTask:
+		if action.ProjectID == 0 {
+			return invalid("project_id must be set")
+		}
+		return checkAutomationProjectWriteAccess(s, a, action)
+	case ProjectAutomationActionCreateTask:
+		if action.Task == nil || action.Task.Title == "" {
+			return invalid("task must have a title")
+		}
+		if action.ProjectID != 0 {
+			return checkAutomationProjectWriteAccess(s, a, action)
+		}
+	case ProjectAutomationActionNotifyUsers:
+		if len(action.UserIDs) == 0 || action.Message == "" {
+			return invalid("user_ids and message must be set")
+		}
+	case ProjectAutomationActionCallWebhook:
+		if !config.WebhooksEnabled.GetBool() {
+			return invalid("webhooks are disabled on this instance")
+		}
+		target, err := url.Parse(action.URL)
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+			return invalid("url must be a http or https url")
+		}
+	default:
+		return invalid("unknown action")
+	}
+
+	return nil
+}
+
+func checkAutomationProjectWriteAccess(s *xorm.Session, a web.Auth, action *ProjectAutomationAction) error {
+	project := &Project{ID: action.ProjectID}
+	can, err := project.CanWrite(s, a)
+	if err != nil {
+		return err
+	}
+	if !can {
+		return ErrGenericForbidden{}
+	}
+	return nil
+}
+
+func (action *ProjectAutomationAction) execute(s *xorm.Session, doer *user.User, pa *ProjectAutomation, trigger string, task *Task) (err error) {
+	switch action.Kind {
+	case ProjectAutomationActionUpdateTask:
+		if task == nil {
+			return nil
+		}
+		return action.updateTask(s, doer, task)
+	case ProjectAutomationActionMoveTask:
+		if task == nil {
+			return nil
+		}
+		return action.moveTask(s, doer, task)
+	case ProjectAutomationActionCreateTask:
+		return action.createTask(s, doer, pa)
+	case ProjectAutomationActionNotifyUsers:
+		return action.notifyUsers(s, pa, task)
+	case ProjectAutomationActionCallWebhook:
+		// Webhooks could have been disabled after the automation was created
+		if !config.WebhooksEnabled.GetBool() {
+			log.Debugf("Webhooks are disabled, not calling the webhook of automation %d", pa.ID)
+			return nil
+		}
+		return action.callWebhook(pa, trigger, task)
+	}
+
+	return nil
+}
+
+// getFullTaskForAutomation returns a task an automation changes, after making sure the user the automation runs
+// on behalf of can still change it.
+func getFullTaskForAutomation(s *xorm.Session, doer *user.User, task *Task) (*Task, error) {
+	fullTask := &Task{ID: task.ID}
+	can, err := fullTask.CanUpdate(s, doer)
+	if err != nil {
+		return nil, err
+	}
+	if !can {
+		return nil, ErrGenericForbidden{}
+	}
+
+	err = fullTask.ReadOne(s, doer)
+	return fullTask, err
+}
+
+func (action *ProjectAutomationAction) updateTask(s *xorm.Session, doer *user.User, task *Task) error {
+	fullTask, err := getFullTaskForAutomation(s, doer, task)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range action.Fields {
+		switch field {
+		case "title":
+			fullTask.Title = action.Task.Title
+		case "description":
+			fullTask.Description = action.Task.Description
+		case "done":
+			fullTask.Done = action.Task.Done
+		case "priority":
+			fullTask.Priority = action.Task.Priority
+		case "percent_done":
+			fullTask.PercentDone = action.Task.PercentDone
+		case "due_date":
+			fullTask.DueDate = action.Task.DueDate
+		case "start_date":
+			fullTask.StartDate = action.Task.StartDate
+		case "end_date":
+			fullTask.EndDate = action.Task.EndDate
+		case "hex_color":
+			fullTask.HexColor = action.Task.HexColor
+		}
+	}
+
+	err = fullTask.Update(s, doer)
+	if err != nil {
+		return err
+	}
+
+	*task = *fullTask
+	return nil
+}
+
+func (action *ProjectAutomationAction) moveTask(s *xorm.Session, doer *user.User, task *Task) error {
+	if task.ProjectID == action.ProjectID {
+		return nil
+	}
+
+	err := checkAutomationProjectWriteAccess(s, doer, action)
+	if err != nil {
+		return err
+	}
+
+	fullTask, err := getFullTaskForAutomation(s, doer, task)
+	if err != nil {
+		return err
+	}
+
+	fullTask.ProjectID = action.ProjectID
+	err = fullTask.Update(s, doer)
+	if err != nil {
+		return err
+	}
+
+	*task = *fullTask
+	return nil
+}
+
+func (action *ProjectAutomationAction) createTask(s *xorm.Session, doer *user.User, pa *ProjectAutomation) error {
+	projectID := action.ProjectID
+	if projectID == 0 {
+		projectID = pa.ProjectID
+	}
+
+	err := checkAutomationProjectWriteAccess(s, doer, &ProjectAutomationAction{ProjectID: projectID})
+	if err != nil {
+		return err
+	}
+
+	newTask := &Task{
+		Title:       action.Task.Title,
+		Description: action.Task.Description,
+		Priority:    action.Task.Priority,
+		PercentDone: action.Task.PercentDone,
+		DueDate:     action.Task.DueDate,
+		StartDate:   action.Task.StartDate,
+		EndDate:     action.Task.EndDate,
+		HexColor:    action.Task.HexColor,
+		RepeatAfter: action.Task.RepeatAfter,
+		ProjectID:   projectID,
+	}
+
+	return createTask(s, newTask, doer, false, true)
+}
+
+func (action *ProjectAutomationAction) notifyUsers(s *xorm.Session, pa *ProjectAutomation, task *Task) error {
+	project, err := GetProjectSimpleByID(s, pa.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	users, err := user.GetUsersByIDs(s, action.UserIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range users {
+		// Only notify users who can see the project
+		can, _, err := project.CanRead(s, u)
+		if err != nil {
+			return err
+		}
+		if !can {
+			continue
+		}
+
+		err = notifications.Notify(u, &ProjectAutomationNotification{
+			Automation: pa,
+			Project:    project,
+			Task:       task,
+			Message:    action.Message,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (action *ProjectAutomationAction) callWebhook(pa *ProjectAutomation, trigger string, task *Task) error {
+	w := &Webhook{
+		ID:        pa.ID,
+		TargetURL: action.URL,
+		Secret:    action.Secret,
+		ProjectID: pa.ProjectID,
+	}
+
+	// Copy the actions so that hiding the secrets does not change the automation which is currently running
+	automation := *pa
+	automation.Actions = make([]*ProjectAutomationAction, 0, len(pa.Actions))
+	for _, a := range pa.Actions {
+		actionCopy := *a
+		automation.Actions = append(automation.Actions, &actionCopy)
+	}
+	automation.hideSecrets()
+
+	return w.sendWebhookPayload(&WebhookPayload{
+		EventName: "automation.executed",
+		Time:      time.Now(),
+		Data: map[string]interface{}{
+			"automation": &automation,
+			"trigger":    trigger,
+			"task":       task,
+		},
+	})
+}
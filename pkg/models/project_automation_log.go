@@ -0,0 +1,121 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/web"
+
+	"xorm.io/xorm"
+)
+
+// ProjectAutomationLogStatus is the outcome of an automation execution
+type ProjectAutomationLogStatus string
+
+const (
+	// ProjectAutomationLogStatusSuccess means all actions of the automation ran successfully.
+	ProjectAutomationLogStatusSuccess ProjectAutomationLogStatus = "success"
+	// ProjectAutomationLogStatusFailed means one of the actions failed and the remaining actions were not run.
+	ProjectAutomationLogStatusFailed ProjectAutomationLogStatus = "failed"
+	// ProjectAutomationLogStatusSkipped means the automation was not run because of the loop protection.
+	ProjectAutomationLogStatusSkipped ProjectAutomationLogStatus = "skipped"
+)
+
+// ProjectAutomationLog is an entry in the execution log of a project automation
+type ProjectAutomationLog struct {
+	// The unique, numeric id of this log entry.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	// The automation which was executed.
+	AutomationID int64 `xorm:"bigint not null index" json:"automation_id" param:"automation"`
+	// The project the automation belongs to.
+	ProjectID int64 `xorm:"-" json:"-" param:"project"`
+	// The task the automation was executed for. 0 if it did not run for a task.
+	TaskID int64 `xorm:"bigint null" json:"task_id"`
+	// What triggered the execution. Either the name of the event or "schedule".
+	TriggeredBy string `xorm:"varchar(250) not null" json:"triggered_by"`
+	// The outcome of the execution. One of success, failed or skipped.
+	Status ProjectAutomationLogStatus `xorm:"varchar(50) not null" json:"status"`
+	// The error message if the execution failed or the reason it was skipped.
+	Message string `xorm:"text null" json:"message"`
+
+	// A timestamp when the automation was executed.
+	Created time.Time `xorm:"created not null" json:"created"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for project automation logs
+func (*ProjectAutomationLog) TableName() string {
+	return "project_automation_logs"
+}
+
+func addProjectAutomationLog(s *xorm.Session, pa *ProjectAutomation, trigger string, taskID int64, status ProjectAutomationLogStatus, message string) error {
+	_, err := s.Insert(&ProjectAutomationLog{
+		AutomationID: pa.ID,
+		TaskID:       taskID,
+		TriggeredBy:  trigger,
+		Status:       status,
+		Message:      message,
+	})
+	return err
+}
+
+// ReadAll returns the execution log of an automation
+// @Summary Get the execution log of an automation
+// @Description Returns all executions of an automation, newest first.
+// @tags automations
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Param automation path int true "Automation ID"
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Success 200 {array} models.ProjectAutomationLog "The execution log"
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 404 {object} web.HTTPError "The automation does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/automations/{automation}/logs [get]
+func (l *ProjectAutomationLog) ReadAll(s *xorm.Session, a web.Auth, _ string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	pa := &ProjectAutomation{ID: l.AutomationID, ProjectID: l.ProjectID}
+	can, _, err := pa.CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	limit, start := getLimitFromPageIndex(page, perPage)
+	query := s.Where("automation_id = ?", l.AutomationID).
+		OrderBy("created desc, id desc")
+	if limit > 0 {
+		query = query.Limit(limit, start)
+	}
+
+	logs := []*ProjectAutomationLog{}
+	err = query.Find(&logs)
+	if err != nil {
+		return
+	}
+
+	total, err := s.Where("automation_id = ?", l.AutomationID).
+		Count(&ProjectAutomationLog{})
+	return logs, len(logs), total, err
+}
@@ -0,0 +1,74 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// CanRead checks if a user can see an automation
+func (pa *ProjectAutomation) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	can, err := pa.canDoExistingProjectAutomation(s, a)
+	if err != nil || !can {
+		return false, 0, err
+	}
+
+	p := &Project{ID: pa.ProjectID}
+	return p.CanRead(s, a)
+}
+
+// CanCreate checks if a user can create an automation on a project
+func (pa *ProjectAutomation) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return pa.canDoProjectAutomation(s, a)
+}
+
+// CanUpdate checks if a user can update an automation
+func (pa *ProjectAutomation) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	return pa.canDoExistingProjectAutomation(s, a)
+}
+
+// CanDelete checks if a user can delete an automation
+func (pa *ProjectAutomation) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return pa.canDoExistingProjectAutomation(s, a)
+}
+
+func (pa *ProjectAutomation) canDoExistingProjectAutomation(s *xorm.Session, a web.Auth) (bool, error) {
+	automation, err := getProjectAutomationByID(s, pa.ID)
+	if err != nil {
+		return false, err
+	}
+
+	// Make sure the automation belongs to the project from the url
+	if automation.ProjectID != pa.ProjectID {
+		return false, &ErrProjectAutomationDoesNotExist{AutomationID: pa.ID}
+	}
+
+	return pa.canDoProjectAutomation(s, a)
+}
+
+// Since automations run actions on behalf of the user who created them, only users who can manage a project are
+// allowed to see or change its automations.
+func (pa *ProjectAutomation) canDoProjectAutomation(s *xorm.Session, a web.Auth) (bool, error) {
+	_, isShareAuth := a.(*LinkSharing)
+	if isShareAuth {
+		return false, nil
+	}
+
+	p := &Project{ID: pa.ProjectID}
+	return p.CanUpdate(s, a)
+}
@@ -0,0 +1,381 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectAutomation_Create(t *testing.T) {
+	RegisterEventForAutomation(&TaskCreatedEvent{})
+	u := &user.User{ID: 1}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pa := &ProjectAutomation{
+			ProjectID:    1,
+			Title:        "Done tasks",
+			TriggerEvent: "task.created",
+			Filter:       "priority >= 3",
+			Actions: []*ProjectAutomationAction{
+				{Kind: ProjectAutomationActionUpdateTask, Fields: []string{"done"}, Task: &Task{Done: true}},
+				{Kind: ProjectAutomationActionNotifyUsers, UserIDs: []int64{1}, Message: "Done"},
+			},
+		}
+		err := pa.Create(s, u)
+		require.NoError(t, err)
+		assert.NotEqual(t, int64(0), pa.ID)
+		assert.Equal(t, int64(1), pa.CreatedBy.ID)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "project_automations", map[string]interface{}{
+			"id":            pa.ID,
+			"project_id":    1,
+			"trigger_event": "task.created",
+			"created_by_id": 1,
+		}, false)
+	})
+	t.Run("invalid triggers", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		actions := []*ProjectAutomationAction{
+			{Kind: ProjectAutomationActionCreateTask, Task: &Task{Title: "Test"}},
+		}
+
+		for name, pa := range map[string]*ProjectAutomation{
+			"no trigger":       {ProjectID: 1, Title: "Test", Actions: actions},
+			"both triggers":    {ProjectID: 1, Title: "Test", TriggerEvent: "task.created", TriggerSchedule: "* * * * *", Actions: actions},
+			"unknown event":    {ProjectID: 1, Title: "Test", TriggerEvent: "task.unknown", Actions: actions},
+			"invalid schedule": {ProjectID: 1, Title: "Test", TriggerSchedule: "every day", Actions: actions},
+		} {
+			err := pa.Create(s, u)
+			require.Error(t, err, name)
+			assert.True(t, IsErrInvalidProjectAutomationTrigger(err), name)
+		}
+	})
+	t.Run("invalid actions", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pa := &ProjectAutomation{
+			ProjectID:    1,
+			Title:        "Test",
+			TriggerEvent: "task.created",
+			Actions: []*ProjectAutomationAction{
+				{Kind: ProjectAutomationActionUpdateTask, Fields: []string{"created_by_id"}, Task: &Task{}},
+			},
+		}
+		err := pa.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidProjectAutomationAction(err))
+
+		// Scheduled automations without conditions don't run for a task
+		pa = &ProjectAutomation{
+			ProjectID:       1,
+			Title:           "Test",
+			TriggerSchedule: "0 9 * * *",
+			Actions: []*ProjectAutomationAction{
+				{Kind: ProjectAutomationActionMoveTask, ProjectID: 2},
+			},
+		}
+		err = pa.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidProjectAutomationAction(err))
+	})
+	t.Run("invalid webhook urls", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		for _, url := range []string{"httpfoo", "ftp://example.com/hook", "https://", "example.com/hook"} {
+			pa := &ProjectAutomation{
+				ProjectID:    1,
+				Title:        "Test",
+				TriggerEvent: "task.created",
+				Actions: []*ProjectAutomationAction{
+					{Kind: ProjectAutomationActionCallWebhook, URL: url},
+				},
+			}
+			err := pa.Create(s, u)
+			require.Error(t, err, url)
+			assert.True(t, IsErrInvalidProjectAutomationAction(err), url)
+		}
+	})
+	t.Run("webhook with webhooks disabled", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		config.WebhooksEnabled.Set(false)
+		defer config.WebhooksEnabled.Set(true)
+
+		pa := &ProjectAutomation{
+			ProjectID:    1,
+			Title:        "Test",
+			TriggerEvent: "task.created",
+			Actions: []*ProjectAutomationAction{
+				{Kind: ProjectAutomationActionCallWebhook, URL: "https://example.com/hook"},
+			},
+		}
+		err := pa.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidProjectAutomationAction(err))
+	})
+	t.Run("invalid filter", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pa := &ProjectAutomation{
+			ProjectID:    1,
+			Title:        "Test",
+			TriggerEvent: "task.created",
+			Filter:       "foo = bar",
+			Actions: []*ProjectAutomationAction{
+				{Kind: ProjectAutomationActionCreateTask, Task: &Task{Title: "Test"}},
+			},
+		}
+		err := pa.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidTaskFilterValue(err))
+	})
+}
+
+func TestProjectAutomation_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	pa := &ProjectAutomation{ProjectID: 2}
+	result, _, total, err := pa.ReadAll(s, &user.User{ID: 3}, "", 0, 50)
+	require.NoError(t, err)
+	automations := result.([]*ProjectAutomation)
+	require.Len(t, automations, 1)
+	assert.Equal(t, int64(1), total)
+	assert.Equal(t, int64(3), automations[0].ID)
+	assert.Empty(t, automations[0].Actions[0].Secret)
+}
+
+func TestProjectAutomation_Update(t *testing.T) {
+	RegisterEventForAutomation(&TaskCommentCreatedEvent{})
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	pa := &ProjectAutomation{
+		ID:           3,
+		ProjectID:    2,
+		Title:        "Notify on comments",
+		TriggerEvent: "task.comment.created",
+		Actions: []*ProjectAutomationAction{
+			{Kind: ProjectAutomationActionCallWebhook, URL: "https://example.com/hook"},
+		},
+	}
+	err := pa.Update(s, &user.User{ID: 3})
+	require.NoError(t, err)
+
+	// The secret is not sent to clients, so it must be kept when it is not provided
+	automation, err := getProjectAutomationByID(s, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "supersecret", automation.Actions[0].Secret)
+}
+
+func TestProjectAutomationRights(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	t.Run("automation of another project", func(t *testing.T) {
+		pa := &ProjectAutomation{ID: 3, ProjectID: 1}
+		_, err := pa.CanUpdate(s, &user.User{ID: 1})
+		require.Error(t, err)
+		assert.True(t, IsErrProjectAutomationDoesNotExist(err))
+	})
+	t.Run("link share", func(t *testing.T) {
+		pa := &ProjectAutomation{ID: 1, ProjectID: 1}
+		can, err := pa.CanUpdate(s, &LinkSharing{ID: 2, ProjectID: 1, Right: RightAdmin})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+}
+
+func TestProjectAutomation_Run(t *testing.T) {
+	t.Run("event with matching task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := runAutomationsForEvent(s, "task.created", 1, 1)
+		require.NoError(t, err)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":       1,
+			"priority": 4,
+		}, false)
+		db.AssertExists(t, "project_automation_logs", map[string]interface{}{
+			"automation_id": 1,
+			"task_id":       1,
+			"triggered_by":  "task.created",
+			"status":        ProjectAutomationLogStatusSuccess,
+		}, false)
+	})
+	t.Run("event with task not matching the conditions", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		// Task 2 is done
+		err := runAutomationsForEvent(s, "task.created", 1, 2)
+		require.NoError(t, err)
+
+		db.AssertMissing(t, "project_automation_logs", map[string]interface{}{
+			"automation_id": 1,
+			"task_id":       2,
+		})
+	})
+	t.Run("loop protection", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pa, err := getProjectAutomationByID(s, 1)
+		require.NoError(t, err)
+		for i := 0; i < automationLoopProtectionMaxExecutions; i++ {
+			err = addProjectAutomationLog(s, pa, "task.updated", 3, ProjectAutomationLogStatusSuccess, "")
+			require.NoError(t, err)
+		}
+
+		task, err := GetTaskByIDSimple(s, 3)
+		require.NoError(t, err)
+		err = pa.execute(s, "task.updated", &task)
+		require.NoError(t, err)
+
+		db.AssertExists(t, "project_automation_logs", map[string]interface{}{
+			"automation_id": 1,
+			"task_id":       3,
+			"status":        ProjectAutomationLogStatusSkipped,
+		}, false)
+		db.AssertMissing(t, "tasks", map[string]interface{}{
+			"id":       3,
+			"priority": 4,
+		})
+	})
+	t.Run("creator lost access to the project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.Where("id = ?", 1).Cols("created_by_id").Update(&ProjectAutomation{CreatedByID: 2})
+		require.NoError(t, err)
+
+		err = runAutomationsForEvent(s, "task.created", 1, 1)
+		require.NoError(t, err)
+
+		db.AssertMissing(t, "tasks", map[string]interface{}{
+			"id":       1,
+			"priority": 4,
+		})
+		db.AssertExists(t, "project_automation_logs", map[string]interface{}{
+			"automation_id": 1,
+			"task_id":       1,
+			"status":        ProjectAutomationLogStatusFailed,
+		}, false)
+	})
+	t.Run("schedule", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		pa, err := getProjectAutomationByID(s, 2)
+		require.NoError(t, err)
+
+		now := time.Now().Round(time.Second)
+		err = pa.runScheduled(s, now)
+		require.NoError(t, err)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"title":      "Weekly review",
+			"project_id": 1,
+		}, false)
+		db.AssertExists(t, "project_automation_logs", map[string]interface{}{
+			"automation_id": 2,
+			"task_id":       0,
+			"triggered_by":  "schedule",
+			"status":        ProjectAutomationLogStatusSuccess,
+		}, false)
+	})
+}
+
+func TestProjectAutomationAction_CallWebhook(t *testing.T) {
+	t.Run("webhooks disabled", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		config.WebhooksEnabled.Set(false)
+		defer config.WebhooksEnabled.Set(true)
+
+		// Nothing listens on this port, so the automation would fail if it tried to call the webhook
+		action := &ProjectAutomationAction{Kind: ProjectAutomationActionCallWebhook, URL: "http://127.0.0.1:1/hook"}
+		err := action.execute(s, &user.User{ID: 1}, &ProjectAutomation{ID: 1, ProjectID: 1}, "task.created", nil)
+		require.NoError(t, err)
+	})
+}
+
+func TestAutomationListener_Handle(t *testing.T) {
+	t.Run("task which is not an object", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		al := &AutomationListener{EventName: "task.created"}
+		err := al.Handle(message.NewMessage("1", []byte(`{"task":"invalid","project":{"id":1}}`)))
+		require.NoError(t, err)
+
+		db.AssertMissing(t, "project_automation_logs", map[string]interface{}{
+			"automation_id": 1,
+			"task_id":       0,
+		})
+	})
+}
+
+func TestProjectAutomationLog_ReadAll(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	l := &ProjectAutomationLog{AutomationID: 1, ProjectID: 1}
+	result, _, _, err := l.ReadAll(s, &user.User{ID: 1}, "", 0, 50)
+	require.NoError(t, err)
+	logs := result.([]*ProjectAutomationLog)
+	require.Len(t, logs, 1)
+	assert.Equal(t, int64(1), logs[0].TaskID)
+}
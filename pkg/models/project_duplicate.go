@@ -101,7 +101,7 @@ func (pd *ProjectDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
 
 	log.Debugf("Duplicated all tasks from project %d into %d", pd.ProjectID, pd.Project.ID)
 
-	err = duplicateViews(s, pd, doer, newTaskIDs)
+	newViewIDs, err := duplicateViews(s, pd, doer, newTaskIDs)
 	if err != nil {
 		return
 	}
@@ -152,7 +152,10 @@ func (pd *ProjectDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
 	for _, share := range linkShares {
 		share.ID = 0
 		share.ProjectID = pd.Project.ID
+		share.TaskID = newTaskIDs[share.TaskID]
+		share.ProjectViewID = newViewIDs[share.ProjectViewID]
 		share.Hash = utils.MakeRandomString(40)
+		share.Uses = 0
 		if _, err := s.Insert(share); err != nil {
 			return err
 		}
@@ -164,7 +167,7 @@ func (pd *ProjectDuplicate) Create(s *xorm.Session, doer web.Auth) (err error) {
 	return
 }
 
-func duplicateViews(s *xorm.Session, pd *ProjectDuplicate, doer web.Auth, taskMap map[int64]int64) (err error) {
+func duplicateViews(s *xorm.Session, pd *ProjectDuplicate, doer web.Auth, taskMap map[int64]int64) (viewMap map[int64]int64, err error) {
 	// Duplicate Views
 	views := make(map[int64]*ProjectView)
 	err = s.Where("project_id = ?", pd.ProjectID).Find(&views)
@@ -173,7 +176,7 @@ func duplicateViews(s *xorm.Session, pd *ProjectDuplicate, doer web.Auth, taskMa
 	}
 
 	oldViewIDs := []int64{}
-	viewMap := make(map[int64]int64)
+	viewMap = make(map[int64]int64)
 	for _, view := range views {
 		oldID := view.ID
 		oldViewIDs = append(oldViewIDs, oldID)
@@ -210,7 +213,7 @@ func duplicateViews(s *xorm.Session, pd *ProjectDuplicate, doer web.Auth, taskMa
 
 		err = b.Create(s, doer)
 		if err != nil {
-			return err
+			return nil, err
 		}
 
 		bucketMap[oldBucketID] = b.ID
@@ -219,7 +222,7 @@ func duplicateViews(s *xorm.Session, pd *ProjectDuplicate, doer web.Auth, taskMa
 	oldTaskBuckets := []*TaskBucket{}
 	err = s.In("bucket_id", oldBucketIDs).Find(&oldTaskBuckets)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	taskBuckets := []*TaskBucket{}
@@ -234,7 +237,7 @@ func duplicateViews(s *xorm.Session, pd *ProjectDuplicate, doer web.Auth, taskMa
 	if len(taskBuckets) > 0 {
 		_, err = s.Insert(&taskBuckets)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -436,6 +439,22 @@ func duplicateTasks(s *xorm.Session, doer web.Auth, ld *ProjectDuplicate) (newTa
 
 	log.Debugf("Duplicated all comments from project %d into %d", ld.ProjectID, ld.Project.ID)
 
+	// Checklists
+	checklistItems := []*TaskChecklistItem{}
+	err = s.In("task_id", oldTaskIDs).Find(&checklistItems)
+	if err != nil {
+		return
+	}
+	for _, item := range checklistItems {
+		item.ID = 0
+		item.TaskID = newTaskIDs[item.TaskID]
+		if _, err := s.Insert(item); err != nil {
+			return nil, err
+		}
+	}
+
+	log.Debugf("Duplicated all checklists from project %d into %d", ld.ProjectID, ld.Project.ID)
+
 	// Relations in that project
 	// Low-Effort: Only copy those relations which are between tasks in the same project
 	// because we can do that without a lot of hassle
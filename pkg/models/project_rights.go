@@ -48,10 +48,15 @@ func (p *Project) CanWrite(s *xorm.Session, a web.Auth) (bool, error) {
 	// Check if we're dealing with a share auth
 	shareAuth, ok := a.(*LinkSharing)
 	if ok {
-		return originalProject.ID == shareAuth.ProjectID &&
+		return originalProject.ID == shareAuth.ProjectID && shareAuth.targetsProject() &&
 			(shareAuth.Right == RightWrite || shareAuth.Right == RightAdmin), errIsArchived
 	}
 
+	canAccess, err := apiTokenCanAccessProject(s, a, originalProject.ID, true)
+	if err != nil || !canAccess {
+		return false, err
+	}
+
 	// Check if the user is either owner or can write to the project
 	if originalProject.isOwner(&user.User{ID: a.GetID()}) {
 		canWrite = true
@@ -71,6 +76,13 @@ func (p *Project) CanWrite(s *xorm.Session, a web.Auth) (bool, error) {
 // CanRead checks if a user has read access to a project
 func (p *Project) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
 
+	// Favorites and saved filters contain tasks from all projects and are not available for tokens limited to some projects
+	if p.ID < 0 {
+		if scope := getAPITokenScope(a); scope != nil && len(scope.ProjectIDs) > 0 {
+			return false, 0, nil
+		}
+	}
+
 	// The favorite project needs a special treatment
 	if p.ID == FavoritesPseudoProject.ID {
 		owner, err := user.GetFromAuth(a)
@@ -101,14 +113,20 @@ func (p *Project) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
 	// Check if we're dealing with a share auth
 	shareAuth, ok := a.(*LinkSharing)
 	if ok {
-		return p.ID == shareAuth.ProjectID &&
+		return p.ID == shareAuth.ProjectID && shareAuth.targetsProject() &&
 			(shareAuth.Right == RightRead || shareAuth.Right == RightWrite || shareAuth.Right == RightAdmin), int(shareAuth.Right), nil
 	}
 
+	canAccess, err := apiTokenCanAccessProject(s, a, p.ID, false)
+	if err != nil || !canAccess {
+		return false, 0, err
+	}
+
 	if p.isOwner(&user.User{ID: a.GetID()}) {
-		return true, int(RightAdmin), nil
+		return true, apiTokenMaxRight(a, RightAdmin), nil
 	}
-	return p.checkRight(s, a, RightRead, RightWrite, RightAdmin)
+	canRead, maxRight, err := p.checkRight(s, a, RightRead, RightWrite, RightAdmin)
+	return canRead, apiTokenMaxRight(a, Right(maxRight)), err
 }
 
 // CanUpdate checks if the user can update a project
@@ -173,7 +191,8 @@ func (p *Project) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
 	if is {
 		return false, nil
 	}
-	return true, nil
+	// Limited api tokens can only create projects below the projects they can write to
+	return getAPITokenScope(a) == nil, nil
 }
 
 // IsAdmin returns whether the user has admin rights on the project or not
@@ -191,7 +210,12 @@ func (p *Project) IsAdmin(s *xorm.Session, a web.Auth) (bool, error) {
 	// Check if we're dealing with a share auth
 	shareAuth, ok := a.(*LinkSharing)
 	if ok {
-		return originalProject.ID == shareAuth.ProjectID && shareAuth.Right == RightAdmin, nil
+		return originalProject.ID == shareAuth.ProjectID && shareAuth.targetsProject() && shareAuth.Right == RightAdmin, nil
+	}
+
+	canAccess, err := apiTokenCanAccessProject(s, a, originalProject.ID, true)
+	if err != nil || !canAccess {
+		return false, err
 	}
 
 	// Check all the things
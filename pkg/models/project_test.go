@@ -287,6 +287,12 @@ func TestProject_Delete(t *testing.T) {
 		db.AssertMissing(t, "tasks", map[string]interface{}{
 			"id": 1,
 		})
+		db.AssertMissing(t, "project_automations", map[string]interface{}{
+			"project_id": 1,
+		})
+		db.AssertMissing(t, "project_automation_logs", map[string]interface{}{
+			"automation_id": 1,
+		})
 	})
 	t.Run("with background", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
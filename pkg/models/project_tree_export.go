@@ -0,0 +1,261 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"sort"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/api/pkg/version"
+
+	"xorm.io/xorm"
+)
+
+// ProjectTreeMembersFileName is the name of the file in a project tree export which holds the users and teams
+// referenced in the exported projects.
+const ProjectTreeMembersFileName = "members.json"
+
+// ExportedUser is a user referenced in a project tree export. When importing the export into another instance,
+// the user is matched with a user of that instance by username.
+type ExportedUser struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+}
+
+// ExportedTeam is a team one of the projects in a project tree export was shared with. Shares are not imported,
+// they are listed in the migration report when importing the export into another instance.
+type ExportedTeam struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// ExportedShare is a share of one of the projects in a project tree export with a user or a team.
+type ExportedShare struct {
+	ProjectID int64 `json:"project_id"`
+	UserID    int64 `json:"user_id,omitempty"`
+	TeamID    int64 `json:"team_id,omitempty"`
+	Right     Right `json:"right"`
+}
+
+// ProjectTreeMembers holds all users and teams referenced in a project tree export, together with the shares
+// of the exported projects.
+type ProjectTreeMembers struct {
+	Users  []*ExportedUser  `json:"users"`
+	Teams  []*ExportedTeam  `json:"teams"`
+	Shares []*ExportedShare `json:"shares"`
+}
+
+// ProjectTreeExportFileName returns the name for the export file of a project tree.
+func ProjectTreeExportFileName(projectID int64) string {
+	return "vikunja-project-" + strconv.FormatInt(projectID, 10) + ".zip"
+}
+
+// ExportProjectTree writes a project with all its child projects to a zip file in the format of the user data export,
+// which can be imported with the vikunja-file migrator. Next to views, buckets, tasks, comments, attachments, labels,
+// relations and backgrounds, it contains the usernames of all assignees so that they can be matched with the users
+// of the instance the export is imported into, and the users and teams the projects are shared with.
+// Only admins of a project can export it.
+func ExportProjectTree(s *xorm.Session, u *user.User, projectID int64, w io.Writer) (err error) {
+	if projectID <= 0 {
+		return ErrProjectDoesNotExist{ID: projectID}
+	}
+
+	root := &Project{ID: projectID}
+	isAdmin, err := root.IsAdmin(s, u)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return ErrGenericForbidden{}
+	}
+
+	rawProjects, err := getProjectTree(s, u, projectID)
+	if err != nil {
+		return err
+	}
+
+	projects, taskIDs, err := getProjectsWithTasksAndBuckets(s, u, rawProjects)
+	if err != nil {
+		return err
+	}
+
+	members, err := getProjectTreeMembers(s, projects)
+	if err != nil {
+		return err
+	}
+
+	wr := zip.NewWriter(w)
+
+	data, err := json.Marshal(projects)
+	if err != nil {
+		return err
+	}
+	err = utils.WriteBytesToZip("data.json", data, wr)
+	if err != nil {
+		return err
+	}
+
+	data, err = json.Marshal(members)
+	if err != nil {
+		return err
+	}
+	err = utils.WriteBytesToZip(ProjectTreeMembersFileName, data, wr)
+	if err != nil {
+		return err
+	}
+
+	err = exportTaskAttachments(s, wr, taskIDs)
+	if err != nil {
+		return err
+	}
+
+	err = writeProjectBackgroundsToZip(rawProjects, wr)
+	if err != nil {
+		return err
+	}
+
+	err = utils.WriteBytesToZip("VERSION", []byte(version.Version), wr)
+	if err != nil {
+		return err
+	}
+
+	return wr.Close()
+}
+
+// getProjectTree returns the project with the given id and all child projects below it the user has access to.
+// The project itself is returned as a top level project.
+func getProjectTree(s *xorm.Session, u *user.User, projectID int64) (tree []*Project, err error) {
+	allProjects, _, _, err := getRawProjectsForUser(
+		s,
+		&projectOptions{
+			user:        u,
+			page:        0,
+			perPage:     -1,
+			getArchived: true,
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[int64][]*Project)
+	var root *Project
+	for _, p := range allProjects {
+		if p.ID == projectID {
+			root = p
+			continue
+		}
+		children[p.ParentProjectID] = append(children[p.ParentProjectID], p)
+	}
+	if root == nil {
+		return nil, ErrProjectDoesNotExist{ID: projectID}
+	}
+
+	root.ParentProjectID = 0
+	tree = []*Project{root}
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i].ID]...)
+	}
+
+	return tree, nil
+}
+
+func getProjectTreeMembers(s *xorm.Session, projects []*ProjectWithTasksAndBuckets) (members *ProjectTreeMembers, err error) {
+	members = &ProjectTreeMembers{
+		Users:  []*ExportedUser{},
+		Teams:  []*ExportedTeam{},
+		Shares: []*ExportedShare{},
+	}
+
+	projectIDs := make([]int64, 0, len(projects))
+	userIDs := []int64{}
+	for _, p := range projects {
+		projectIDs = append(projectIDs, p.ID)
+		for _, t := range p.Tasks {
+			for _, a := range t.Assignees {
+				userIDs = append(userIDs, a.ID)
+			}
+		}
+	}
+
+	projectUsers := []*ProjectUser{}
+	err = s.In("project_id", projectIDs).OrderBy("id asc").Find(&projectUsers)
+	if err != nil {
+		return nil, err
+	}
+	for _, pu := range projectUsers {
+		userIDs = append(userIDs, pu.UserID)
+		members.Shares = append(members.Shares, &ExportedShare{
+			ProjectID: pu.ProjectID,
+			UserID:    pu.UserID,
+			Right:     pu.Right,
+		})
+	}
+
+	teamProjects := []*TeamProject{}
+	err = s.In("project_id", projectIDs).OrderBy("id asc").Find(&teamProjects)
+	if err != nil {
+		return nil, err
+	}
+	teamIDs := make([]int64, 0, len(teamProjects))
+	for _, tp := range teamProjects {
+		teamIDs = append(teamIDs, tp.TeamID)
+		members.Shares = append(members.Shares, &ExportedShare{
+			ProjectID: tp.ProjectID,
+			TeamID:    tp.TeamID,
+			Right:     tp.Right,
+		})
+	}
+
+	if len(userIDs) > 0 {
+		users, err := user.GetUsersByIDs(s, userIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, usr := range users {
+			members.Users = append(members.Users, &ExportedUser{
+				ID:       usr.ID,
+				Username: usr.Username,
+				Name:     usr.Name,
+			})
+		}
+		sort.Slice(members.Users, func(i, j int) bool {
+			return members.Users[i].ID < members.Users[j].ID
+		})
+	}
+
+	if len(teamIDs) > 0 {
+		teams := []*Team{}
+		err = s.In("id", teamIDs).OrderBy("id asc").Find(&teams)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range teams {
+			members.Teams = append(members.Teams, &ExportedTeam{
+				ID:   t.ID,
+				Name: t.Name,
+			})
+		}
+	}
+
+	return members, nil
+}
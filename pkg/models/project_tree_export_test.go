@@ -0,0 +1,117 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readZipFile(t *testing.T, r *zip.Reader, name string, v interface{}) {
+	f, err := r.Open(name)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, json.NewDecoder(f).Decode(v))
+}
+
+func TestExportProjectTree(t *testing.T) {
+	t.Run("child projects", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		buf := &bytes.Buffer{}
+		err := ExportProjectTree(s, &user.User{ID: 6}, 12, buf)
+		require.NoError(t, err)
+
+		r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+
+		projects := []*ProjectWithTasksAndBuckets{}
+		readZipFile(t, r, "data.json", &projects)
+		require.Len(t, projects, 3)
+		assert.Equal(t, int64(12), projects[0].ID)
+		assert.Equal(t, int64(0), projects[0].ParentProjectID)
+		assert.Equal(t, int64(25), projects[1].ID)
+		assert.Equal(t, int64(12), projects[1].ParentProjectID)
+		assert.Equal(t, int64(26), projects[2].ID)
+		assert.Equal(t, int64(25), projects[2].ParentProjectID)
+
+		_, err = r.Open("VERSION")
+		require.NoError(t, err)
+	})
+	t.Run("users and teams", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		buf := &bytes.Buffer{}
+		err := ExportProjectTree(s, &user.User{ID: 3}, 3, buf)
+		require.NoError(t, err)
+
+		r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		require.NoError(t, err)
+
+		members := &ProjectTreeMembers{}
+		readZipFile(t, r, ProjectTreeMembersFileName, members)
+
+		usernames := []string{}
+		for _, u := range members.Users {
+			usernames = append(usernames, u.Username)
+		}
+		assert.Contains(t, usernames, "user2")
+
+		raw := map[string][]map[string]interface{}{}
+		readZipFile(t, r, ProjectTreeMembersFileName, &raw)
+		for _, u := range raw["users"] {
+			assert.NotContains(t, u, "email")
+		}
+
+		require.Len(t, members.Teams, 1)
+		assert.Equal(t, int64(1), members.Teams[0].ID)
+		assert.Equal(t, "testteam1", members.Teams[0].Name)
+
+		assert.Contains(t, members.Shares, &ExportedShare{ProjectID: 3, UserID: 2, Right: RightRead})
+		assert.Contains(t, members.Shares, &ExportedShare{ProjectID: 3, TeamID: 1, Right: RightRead})
+	})
+	t.Run("no admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := ExportProjectTree(s, &user.User{ID: 1}, 3, &bytes.Buffer{})
+		require.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+	t.Run("nonexistent project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := ExportProjectTree(s, &user.User{ID: 1}, 9999999, &bytes.Buffer{})
+		require.Error(t, err)
+		assert.True(t, IsErrProjectDoesNotExist(err))
+	})
+}
@@ -19,9 +19,11 @@ package models
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"time"
 
 	"code.vikunja.io/web"
+	"xorm.io/builder"
 	"xorm.io/xorm"
 )
 
@@ -37,6 +39,8 @@ func (p *ProjectViewKind) MarshalJSON() ([]byte, error) {
 		return []byte(`"table"`), nil
 	case ProjectViewKindKanban:
 		return []byte(`"kanban"`), nil
+	case ProjectViewKindCalendar:
+		return []byte(`"calendar"`), nil
 	}
 
 	return []byte(`null`), nil
@@ -58,6 +62,8 @@ func (p *ProjectViewKind) UnmarshalJSON(bytes []byte) error {
 		*p = ProjectViewKindTable
 	case "kanban":
 		*p = ProjectViewKindKanban
+	case "calendar":
+		*p = ProjectViewKindCalendar
 	default:
 		return fmt.Errorf("unknown project view kind: %s", value)
 	}
@@ -70,6 +76,7 @@ const (
 	ProjectViewKindGantt
 	ProjectViewKindTable
 	ProjectViewKindKanban
+	ProjectViewKindCalendar
 )
 
 type BucketConfigurationModeKind int
@@ -119,6 +126,70 @@ type ProjectViewBucketConfiguration struct {
 	Filter string
 }
 
+type SwimlaneConfigurationModeKind int
+
+const (
+	SwimlaneConfigurationModeNone SwimlaneConfigurationModeKind = iota
+	SwimlaneConfigurationModeAssignee
+	SwimlaneConfigurationModeLabel
+	SwimlaneConfigurationModePriority
+	SwimlaneConfigurationModeParentTask
+	SwimlaneConfigurationModeFilter
+)
+
+func (p *SwimlaneConfigurationModeKind) MarshalJSON() ([]byte, error) {
+	switch *p {
+	case SwimlaneConfigurationModeNone:
+		return []byte(`"none"`), nil
+	case SwimlaneConfigurationModeAssignee:
+		return []byte(`"assignee"`), nil
+	case SwimlaneConfigurationModeLabel:
+		return []byte(`"label"`), nil
+	case SwimlaneConfigurationModePriority:
+		return []byte(`"priority"`), nil
+	case SwimlaneConfigurationModeParentTask:
+		return []byte(`"parent_task"`), nil
+	case SwimlaneConfigurationModeFilter:
+		return []byte(`"filter"`), nil
+	}
+
+	return []byte(`null`), nil
+}
+
+func (p *SwimlaneConfigurationModeKind) UnmarshalJSON(bytes []byte) error {
+	var value string
+	err := json.Unmarshal(bytes, &value)
+	if err != nil {
+		return err
+	}
+
+	switch value {
+	case "none":
+		*p = SwimlaneConfigurationModeNone
+	case "assignee":
+		*p = SwimlaneConfigurationModeAssignee
+	case "label":
+		*p = SwimlaneConfigurationModeLabel
+	case "priority":
+		*p = SwimlaneConfigurationModePriority
+	case "parent_task":
+		*p = SwimlaneConfigurationModeParentTask
+	case "filter":
+		*p = SwimlaneConfigurationModeFilter
+	default:
+		return fmt.Errorf("unknown swimlane configuration mode kind: %s", value)
+	}
+
+	return nil
+}
+
+type ProjectViewSwimlaneConfiguration struct {
+	Title  string `json:"title"`
+	Filter string `json:"filter"`
+	// How many tasks of this swimlane can be in the same bucket at the same time. 0 means unlimited.
+	Limit int64 `json:"limit"`
+}
+
 type ProjectView struct {
 	// The unique numeric id of this view
 	ID int64 `xorm:"autoincr not null unique pk" json:"id" param:"view"`
@@ -126,7 +197,7 @@ type ProjectView struct {
 	Title string `xorm:"varchar(255) not null" json:"title" valid:"required,runelength(1|250)"`
 	// The project this view belongs to
 	ProjectID int64 `xorm:"not null index" json:"project_id" param:"project"`
-	// The kind of this view. Can be `list`, `gantt`, `table` or `kanban`.
+	// The kind of this view. Can be `list`, `gantt`, `table`, `kanban` or `calendar`.
 	ViewKind ProjectViewKind `xorm:"not null" json:"view_kind"`
 
 	// The filter query to match tasks by. Check out https://vikunja.io/docs/filters for a full explanation.
@@ -143,6 +214,13 @@ type ProjectView struct {
 	// If tasks are moved to the done bucket, they are marked as done. If they are marked as done individually, they are moved into the done bucket.
 	DoneBucketID int64 `xorm:"bigint INDEX null" json:"done_bucket_id"`
 
+	// The swimlane configuration mode. Can be `none`, `assignee`, `label`, `priority`, `parent_task` or `filter`. Swimlanes group the tasks of a kanban view horizontally, in addition to its buckets.
+	SwimlaneConfigurationMode SwimlaneConfigurationModeKind `xorm:"default 0" json:"swimlane_configuration_mode"`
+	// When the swimlane configuration mode is `filter`, this field holds the title, filter and limit of each swimlane.
+	SwimlaneConfiguration []*ProjectViewSwimlaneConfiguration `xorm:"json" json:"swimlane_configuration"`
+	// How many tasks of one swimlane can be in the same bucket at the same time. Used for all swimlane modes except `filter`, where every swimlane has its own limit. 0 means unlimited.
+	SwimlaneLimit int64 `xorm:"default 0" json:"swimlane_limit" minimum:"0" valid:"range(0|9223372036854775807)"`
+
 	// A timestamp when this view was updated. You cannot change this value.
 	Updated time.Time `xorm:"updated not null" json:"updated"`
 	// A timestamp when this reaction was created. You cannot change this value.
@@ -178,6 +256,15 @@ func getViewsForProject(s *xorm.Session, projectID int64) (views []*ProjectView,
 // @Router /projects/{project}/views [get]
 func (pv *ProjectView) ReadAll(s *xorm.Session, a web.Auth, _ string, _ int, _ int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
 
+	// A link share for a single view only sees that view
+	if share, is := a.(*LinkSharing); is && share.ProjectViewID != 0 && share.ProjectID == pv.ProjectID {
+		view, err := GetProjectViewByIDAndProject(s, share.ProjectViewID, share.ProjectID)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		return []*ProjectView{view}, 1, 1, nil
+	}
+
 	pp := &Project{ID: pv.ProjectID}
 	can, _, err := pp.CanRead(s, a)
 	if err != nil {
@@ -239,6 +326,11 @@ func (pv *ProjectView) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{project}/views/{id} [delete]
 func (pv *ProjectView) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	_, err = deleteLinkShares(s, builder.Eq{"project_view_id": pv.ID, "project_id": pv.ProjectID})
+	if err != nil {
+		return
+	}
+
 	_, err = s.
 		Where("id = ? AND project_id = ?", pv.ID, pv.ProjectID).
 		Delete(&ProjectView{})
@@ -255,10 +347,16 @@ func (pv *ProjectView) Delete(s *xorm.Session, _ web.Auth) (err error) {
 // @Param project path int true "Project ID"
 // @Param view body models.ProjectView true "The project view you want to create."
 // @Success 200 {object} models.ProjectView "The created project view"
+// @Failure 400 {object} web.HTTPError "Invalid project view object provided."
 // @Failure 403 {object} web.HTTPError "The user does not have access to create a project view"
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{project}/views [put]
 func (pv *ProjectView) Create(s *xorm.Session, a web.Auth) (err error) {
+	err = pv.validateSwimlaneConfiguration()
+	if err != nil {
+		return err
+	}
+
 	return createProjectView(s, pv, a, true, true)
 }
 
@@ -337,6 +435,11 @@ func addTasksToView(s *xorm.Session, a web.Auth, pv *ProjectView, b *Bucket) (er
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{project}/views/{id} [post]
 func (pv *ProjectView) Update(s *xorm.Session, _ web.Auth) (err error) {
+	err = pv.validateSwimlaneConfiguration()
+	if err != nil {
+		return err
+	}
+
 	// Check if the project view exists
 	_, err = GetProjectViewByIDAndProject(s, pv.ID, pv.ProjectID)
 	if err != nil {
@@ -354,6 +457,9 @@ func (pv *ProjectView) Update(s *xorm.Session, _ web.Auth) (err error) {
 			"bucket_configuration",
 			"default_bucket_id",
 			"done_bucket_id",
+			"swimlane_configuration_mode",
+			"swimlane_configuration",
+			"swimlane_limit",
 		).
 		Update(pv)
 	return
@@ -409,6 +515,35 @@ func GetProjectViewByID(s *xorm.Session, id int64) (view *ProjectView, err error
 	return
 }
 
+// containsTask checks if a task is shown in the view, taking the filter of the view into account.
+func (pv *ProjectView) containsTask(s *xorm.Session, a web.Auth, t *Task) (bool, error) {
+	if t.ProjectID != pv.ProjectID {
+		return false, nil
+	}
+
+	if pv.Filter == "" {
+		return true, nil
+	}
+
+	filter := "(" + pv.Filter + ") && id = " + strconv.FormatInt(t.ID, 10)
+	opts := &taskSearchOptions{
+		page:   -1,
+		filter: filter,
+	}
+	var err error
+	opts.parsedFilters, err = getTaskFiltersFromFilterString(filter, "")
+	if err != nil {
+		return false, err
+	}
+
+	tasks, _, _, err := getRawTasksForProjects(s, []*Project{{ID: pv.ProjectID}}, a, opts)
+	if err != nil {
+		return false, err
+	}
+
+	return len(tasks) > 0, nil
+}
+
 func CreateDefaultViewsForProject(s *xorm.Session, project *Project, a web.Auth, createBacklogBucket bool, createDefaultListFilter bool) (err error) {
 	list := &ProjectView{
 		ProjectID: project.ID,
@@ -465,5 +600,21 @@ func CreateDefaultViewsForProject(s *xorm.Session, project *Project, a web.Auth,
 		kanban,
 	}
 
+	// Saved filters usually span many projects and dates, so they get a calendar view as well
+	if getSavedFilterIDFromProjectID(project.ID) > 0 {
+		calendar := &ProjectView{
+			ProjectID: project.ID,
+			Title:     "Calendar",
+			ViewKind:  ProjectViewKindCalendar,
+			Position:  500,
+		}
+		err = createProjectView(s, calendar, a, createBacklogBucket, true)
+		if err != nil {
+			return
+		}
+
+		project.Views = append(project.Views, calendar)
+	}
+
 	return
 }
@@ -22,6 +22,13 @@ import (
 )
 
 func (pv *ProjectView) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	// Link shares for a single task or view can only see the view they were created for
+	if share, is := a.(*LinkSharing); is && !share.targetsProject() {
+		return share.ProjectViewID != 0 &&
+			pv.ID == share.ProjectViewID &&
+			pv.ProjectID == share.ProjectID, int(RightRead), nil
+	}
+
 	filterID := getSavedFilterIDFromProjectID(pv.ProjectID)
 	if filterID > 0 {
 		sf := &SavedFilter{ID: filterID}
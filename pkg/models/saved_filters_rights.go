@@ -29,11 +29,18 @@ func (sf *SavedFilter) CanRead(s *xorm.Session, auth web.Auth) (bool, int, error
 
 // CanDelete checks if a user has the right to delete a saved filter
 func (sf *SavedFilter) CanDelete(s *xorm.Session, auth web.Auth) (bool, error) {
+	if !apiTokenCanChangeUnscoped(auth) {
+		return false, nil
+	}
 	return sf.canDoFilter(s, auth)
 }
 
 // CanUpdate checks if a user has the right to update a saved filter
 func (sf *SavedFilter) CanUpdate(s *xorm.Session, auth web.Auth) (bool, error) {
+	if !apiTokenCanChangeUnscoped(auth) {
+		return false, nil
+	}
+
 	// A normal check would replace the passed struct which in our case would override the values we want to update.
 	sff := &SavedFilter{ID: sf.ID}
 	return sff.canDoFilter(s, auth)
@@ -45,7 +52,7 @@ func (sf *SavedFilter) CanCreate(_ *xorm.Session, auth web.Auth) (bool, error) {
 		return false, nil
 	}
 
-	return true, nil
+	return apiTokenCanChangeUnscoped(auth), nil
 }
 
 // Helper function to check saved filter rights sind they all have the same logic
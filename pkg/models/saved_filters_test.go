@@ -74,6 +74,11 @@ func TestSavedFilter_Create(t *testing.T) {
 		delete(vals, "filters")
 	}
 	db.AssertExists(t, "saved_filters", vals, true)
+	db.AssertExists(t, "project_views", map[string]interface{}{
+		"project_id": getProjectIDFromSavedFilterID(sf.ID),
+		"view_kind":  ProjectViewKindCalendar,
+		"title":      "Calendar",
+	}, false)
 }
 
 func TestSavedFilter_ReadOne(t *testing.T) {
@@ -0,0 +1,112 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// ServiceAccountToken is an api token which belongs to a service account instead of the user who created it.
+type ServiceAccountToken struct {
+	// The service account this token belongs to.
+	ServiceAccountID int64 `json:"-" param:"serviceaccount"`
+
+	APIToken
+}
+
+// Create creates a new api token for a service account
+// @Summary Create a new api token for a service account
+// @Description Create a new api token for a service account. The token can access everything the service account has access to, limited by its permissions and projects.
+// @tags service accounts
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Service account ID"
+// @Param token body models.APIToken true "The token object with required fields"
+// @Success 200 {object} models.APIToken "The created token."
+// @Failure 400 {object} web.HTTPError "Invalid token object provided."
+// @Failure 403 {object} web.HTTPError "The user cannot manage this service account."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /service-accounts/{id}/tokens [put]
+func (t *ServiceAccountToken) Create(s *xorm.Session, _ web.Auth) (err error) {
+	bot, err := getServiceAccountUser(s, t.ServiceAccountID)
+	if err != nil {
+		return err
+	}
+
+	return t.APIToken.Create(s, bot)
+}
+
+// ReadAll returns all api tokens of a service account
+// @Summary Get all api tokens of a service account
+// @Description Returns all api tokens of a service account.
+// @tags service accounts
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Service account ID"
+// @Param page query int false "The page number, used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of tokens per page. This parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search tokens by their title."
+// @Success 200 {array} models.APIToken "The list of all tokens"
+// @Failure 403 {object} web.HTTPError "The user cannot manage this service account."
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /service-accounts/{id}/tokens [get]
+func (t *ServiceAccountToken) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	can, err := canManageServiceAccount(s, a, t.ServiceAccountID)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !can {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	return t.APIToken.ReadAll(s, &user.User{ID: t.ServiceAccountID}, search, page, perPage)
+}
+
+// Delete deletes an api token of a service account
+// @Summary Delete an api token of a service account
+// @Description Delete an api token of a service account.
+// @tags service accounts
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Service account ID"
+// @Param tokenID path int true "Token ID"
+// @Success 200 {object} models.Message "Successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user cannot manage this service account."
+// @Failure 404 {object} web.HTTPError "The token does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /service-accounts/{id}/tokens/{tokenID} [delete]
+func (t *ServiceAccountToken) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	return t.APIToken.Delete(s, &user.User{ID: t.ServiceAccountID})
+}
+
+// CanCreate checks if a user can create an api token for a service account
+func (t *ServiceAccountToken) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return canManageServiceAccount(s, a, t.ServiceAccountID)
+}
+
+// CanDelete checks if a user can delete an api token of a service account
+func (t *ServiceAccountToken) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	can, err := canManageServiceAccount(s, a, t.ServiceAccountID)
+	if err != nil || !can {
+		return false, err
+	}
+
+	return t.APIToken.CanDelete(s, &user.User{ID: t.ServiceAccountID})
+}
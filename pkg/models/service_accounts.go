@@ -0,0 +1,253 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// ServiceAccount is a user which cannot log in and is used by integrations through its own api tokens.
+// Because it is managed by a team or the instance admins instead of a single person, it keeps working when people leave.
+type ServiceAccount struct {
+	// The unique, numeric id of this service account. This is also the id of the user behind it.
+	ID int64 `json:"id" param:"serviceaccount"`
+	// The username of the service account. Use it to share projects with it or to add it to teams. Cannot be changed.
+	Username string `json:"username" valid:"length(1|250)" minLength:"1" maxLength:"250"`
+	// The display name of the service account.
+	Name string `json:"name"`
+	// The team managing this service account. All admins of that team can manage it. If 0, only instance admins can manage it.
+	TeamID int64 `json:"team_id"`
+
+	// A timestamp when this service account was created. You cannot change this value.
+	Created time.Time `json:"created"`
+	// A timestamp when this service account was last updated. You cannot change this value.
+	Updated time.Time `json:"updated"`
+
+	web.CRUDable `json:"-"`
+	web.Rights   `json:"-"`
+}
+
+func serviceAccountFromUser(u *user.User) *ServiceAccount {
+	return &ServiceAccount{
+		ID:       u.ID,
+		Username: u.Username,
+		Name:     u.Name,
+		TeamID:   u.BotOwnerTeamID,
+		Created:  u.Created,
+		Updated:  u.Updated,
+	}
+}
+
+// getServiceAccountUser returns the user behind a service account.
+func getServiceAccountUser(s *xorm.Session, id int64) (*user.User, error) {
+	u, err := user.GetUserByID(s, id)
+	if err != nil && !user.IsErrUserDoesNotExist(err) {
+		return nil, err
+	}
+	if err != nil || !u.IsBot {
+		return nil, &ErrServiceAccountDoesNotExist{ServiceAccountID: id}
+	}
+	return u, nil
+}
+
+// Create creates a new service account
+// @Summary Create a service account
+// @Description Creates a new service account. Service accounts cannot log in, they make requests with their own api tokens. If a team is provided, all admins of that team can manage the service account, otherwise only instance admins can create and manage it.
+// @tags service accounts
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param serviceAccount body models.ServiceAccount true "The service account"
+// @Success 201 {object} models.ServiceAccount "The created service account."
+// @Failure 400 {object} web.HTTPError "Invalid service account object provided."
+// @Failure 403 {object} web.HTTPError "The user is not allowed to create service accounts for that team."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /service-accounts [put]
+func (sa *ServiceAccount) Create(s *xorm.Session, _ web.Auth) (err error) {
+	if sa.TeamID != 0 {
+		_, err = GetTeamByID(s, sa.TeamID)
+		if err != nil {
+			return err
+		}
+	}
+
+	bot, err := user.CreateBot(s, &user.User{
+		Username:       sa.Username,
+		Name:           sa.Name,
+		BotOwnerTeamID: sa.TeamID,
+	})
+	if err != nil {
+		return err
+	}
+
+	*sa = *serviceAccountFromUser(bot)
+	return nil
+}
+
+// ReadOne returns a service account
+// @Summary Get one service account
+// @Description Returns one service account.
+// @tags service accounts
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Service account ID"
+// @Success 200 {object} models.ServiceAccount "The service account"
+// @Failure 403 {object} web.HTTPError "The user cannot manage this service account."
+// @Failure 404 {object} web.HTTPError "The service account does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /service-accounts/{id} [get]
+func (sa *ServiceAccount) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	bot, err := getServiceAccountUser(s, sa.ID)
+	if err != nil {
+		return err
+	}
+
+	*sa = *serviceAccountFromUser(bot)
+	return nil
+}
+
+// ReadAll returns all service accounts a user can manage
+// @Summary Get all service accounts
+// @Description Returns all service accounts managed by a team the user is admin of. Instance admins get all service accounts.
+// @tags service accounts
+// @Produce json
+// @Security JWTKeyAuth
+// @Param page query int false "The page number. Used for pagination. If not provided, the first page of results is returned."
+// @Param per_page query int false "The maximum number of items per page. Note this parameter is limited by the configured maximum of items per page."
+// @Param s query string false "Search service accounts by their username or name."
+// @Success 200 {array} models.ServiceAccount "The service accounts"
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /service-accounts [get]
+func (sa *ServiceAccount) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	if _, is := a.(*LinkSharing); is {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	u, err := user.GetUserByID(s, a.GetID())
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	var where builder.Cond = builder.Eq{"is_bot": true}
+	if !u.IsAdmin {
+		where = builder.And(
+			where,
+			builder.In("bot_owner_team_id",
+				builder.
+					Select("team_id").
+					From("team_members").
+					Where(builder.Eq{"user_id": u.ID, "admin": true}),
+			),
+		)
+	}
+
+	if search != "" {
+		where = builder.And(
+			where,
+			builder.Or(
+				db.ILIKE("username", search),
+				db.ILIKE("name", search),
+			),
+		)
+	}
+
+	bots := []*user.User{}
+	err = s.
+		Where(where).
+		OrderBy("id asc").
+		Limit(getLimitFromPageIndex(page, perPage)).
+		Find(&bots)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	serviceAccounts := make([]*ServiceAccount, 0, len(bots))
+	for _, bot := range bots {
+		serviceAccounts = append(serviceAccounts, serviceAccountFromUser(bot))
+	}
+
+	totalCount, err := s.Where(where).Count(&user.User{})
+	return serviceAccounts, len(serviceAccounts), totalCount, err
+}
+
+// Update updates a service account
+// @Summary Update a service account
+// @Description Updates the name of a service account or moves it to another team. The username cannot be changed.
+// @tags service accounts
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Service account ID"
+// @Param serviceAccount body models.ServiceAccount true "The service account with updated values"
+// @Success 200 {object} models.ServiceAccount "The updated service account."
+// @Failure 400 {object} web.HTTPError "Invalid service account object provided."
+// @Failure 403 {object} web.HTTPError "The user cannot manage this service account."
+// @Failure 404 {object} web.HTTPError "The service account does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /service-accounts/{id} [post]
+func (sa *ServiceAccount) Update(s *xorm.Session, _ web.Auth) (err error) {
+	bot, err := getServiceAccountUser(s, sa.ID)
+	if err != nil {
+		return err
+	}
+
+	if sa.TeamID != 0 {
+		_, err = GetTeamByID(s, sa.TeamID)
+		if err != nil {
+			return err
+		}
+	}
+
+	bot.Name = sa.Name
+	bot.BotOwnerTeamID = sa.TeamID
+	_, err = s.
+		Where("id = ?", bot.ID).
+		Cols("name", "bot_owner_team_id").
+		Update(bot)
+	if err != nil {
+		return err
+	}
+
+	return sa.ReadOne(s, nil)
+}
+
+// Delete deletes a service account
+// @Summary Delete a service account
+// @Description Deletes a service account together with its api tokens and all projects it owns.
+// @tags service accounts
+// @Produce json
+// @Security JWTKeyAuth
+// @Param id path int true "Service account ID"
+// @Success 200 {object} models.Message "The service account was successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user cannot manage this service account."
+// @Failure 404 {object} web.HTTPError "The service account does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /service-accounts/{id} [delete]
+func (sa *ServiceAccount) Delete(s *xorm.Session, _ web.Auth) (err error) {
+	bot, err := getServiceAccountUser(s, sa.ID)
+	if err != nil {
+		return err
+	}
+
+	return DeleteUser(s, bot)
+}
@@ -0,0 +1,92 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"xorm.io/xorm"
+)
+
+// canManageServiceAccountsOfTeam checks if a user can manage the service accounts of a team.
+// Instance admins can manage all service accounts, service accounts without a team can only be managed by them.
+func canManageServiceAccountsOfTeam(s *xorm.Session, a web.Auth, teamID int64) (bool, error) {
+	if _, is := a.(*LinkSharing); is {
+		return false, nil
+	}
+
+	// A token limited to some projects should not be able to create or change other credentials
+	if getAPITokenScope(a) != nil {
+		return false, nil
+	}
+
+	u, err := user.GetUserByID(s, a.GetID())
+	if err != nil {
+		return false, err
+	}
+	if u.IsAdmin {
+		return true, nil
+	}
+
+	if teamID == 0 {
+		return false, nil
+	}
+
+	return (&Team{ID: teamID}).IsAdmin(s, a)
+}
+
+// canManageServiceAccount checks if a user can manage an existing service account.
+func canManageServiceAccount(s *xorm.Session, a web.Auth, id int64) (bool, error) {
+	bot, err := getServiceAccountUser(s, id)
+	if err != nil {
+		return false, err
+	}
+
+	return canManageServiceAccountsOfTeam(s, a, bot.BotOwnerTeamID)
+}
+
+// CanCreate checks if a user can create a service account
+func (sa *ServiceAccount) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
+	return canManageServiceAccountsOfTeam(s, a, sa.TeamID)
+}
+
+// CanRead checks if a user can see a service account
+func (sa *ServiceAccount) CanRead(s *xorm.Session, a web.Auth) (bool, int, error) {
+	can, err := canManageServiceAccount(s, a, sa.ID)
+	return can, int(RightAdmin), err
+}
+
+// CanUpdate checks if a user can update a service account. Moving it to another team requires being able to manage
+// the service accounts of that team as well.
+func (sa *ServiceAccount) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	bot, err := getServiceAccountUser(s, sa.ID)
+	if err != nil {
+		return false, err
+	}
+
+	can, err := canManageServiceAccountsOfTeam(s, a, bot.BotOwnerTeamID)
+	if err != nil || !can || bot.BotOwnerTeamID == sa.TeamID {
+		return can, err
+	}
+
+	return canManageServiceAccountsOfTeam(s, a, sa.TeamID)
+}
+
+// CanDelete checks if a user can delete a service account
+func (sa *ServiceAccount) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	return canManageServiceAccount(s, a, sa.ID)
+}
@@ -0,0 +1,218 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceAccount_Create(t *testing.T) {
+	t.Run("team admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		u := &user.User{ID: 1}
+
+		sa := &ServiceAccount{Username: "deploybot", Name: "Deploy bot", TeamID: 1}
+		can, err := sa.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = sa.Create(s, u)
+		require.NoError(t, err)
+		assert.NotZero(t, sa.ID)
+		assert.Equal(t, int64(1), sa.TeamID)
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":                sa.ID,
+			"username":          "deploybot",
+			"is_bot":            true,
+			"bot_owner_team_id": 1,
+		}, false)
+	})
+	t.Run("no team admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&ServiceAccount{Username: "deploybot", TeamID: 1}).CanCreate(s, &user.User{ID: 2})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("without team", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&ServiceAccount{Username: "deploybot"}).CanCreate(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("without team as instance admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&ServiceAccount{Username: "deploybot"}).CanCreate(s, &user.User{ID: 16})
+		require.NoError(t, err)
+		assert.True(t, can)
+	})
+	t.Run("existing username", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := (&ServiceAccount{Username: "user1", TeamID: 1}).Create(s, &user.User{ID: 1})
+		require.Error(t, err)
+		assert.True(t, user.IsErrUsernameExists(err))
+	})
+}
+
+func TestServiceAccount_ReadAll(t *testing.T) {
+	t.Run("team admin", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		result, _, total, err := (&ServiceAccount{}).ReadAll(s, &user.User{ID: 1}, "", 1, 50)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), total)
+		serviceAccounts := result.([]*ServiceAccount)
+		assert.Equal(t, "bot17", serviceAccounts[0].Username)
+	})
+	t.Run("team member", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		result, _, _, err := (&ServiceAccount{}).ReadAll(s, &user.User{ID: 2}, "", 1, 50)
+		require.NoError(t, err)
+		assert.Empty(t, result)
+	})
+}
+
+func TestServiceAccount_Update(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		u := &user.User{ID: 1}
+
+		sa := &ServiceAccount{ID: 17, Name: "Renamed", TeamID: 1}
+		can, err := sa.CanUpdate(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = sa.Update(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, "bot17", sa.Username)
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":   17,
+			"name": "Renamed",
+		}, false)
+	})
+	t.Run("move to a team the user is not admin of", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		can, err := (&ServiceAccount{ID: 17, TeamID: 2}).CanUpdate(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+	t.Run("normal user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := (&ServiceAccount{ID: 1}).CanUpdate(s, &user.User{ID: 1})
+		require.Error(t, err)
+		assert.True(t, IsErrServiceAccountDoesNotExist(err))
+	})
+}
+
+func TestServiceAccount_Delete(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+	u := &user.User{ID: 1}
+
+	token := &ServiceAccountToken{ServiceAccountID: 17, APIToken: APIToken{
+		Title: "bot token",
+	}}
+	err := token.Create(s, u)
+	require.NoError(t, err)
+
+	sa := &ServiceAccount{ID: 17}
+	can, err := sa.CanDelete(s, u)
+	require.NoError(t, err)
+	assert.True(t, can)
+	err = sa.Delete(s, u)
+	require.NoError(t, err)
+	db.AssertMissing(t, "users", map[string]interface{}{"id": 17})
+	db.AssertMissing(t, "api_tokens", map[string]interface{}{"owner_id": 17})
+}
+
+func TestServiceAccountToken(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		u := &user.User{ID: 1}
+
+		token := &ServiceAccountToken{ServiceAccountID: 17, APIToken: APIToken{
+			Title: "bot token",
+		}}
+		can, err := token.CanCreate(s, u)
+		require.NoError(t, err)
+		assert.True(t, can)
+		err = token.Create(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(17), token.OwnerID)
+
+		result, _, _, err := (&ServiceAccountToken{ServiceAccountID: 17}).ReadAll(s, u, "", 1, 50)
+		require.NoError(t, err)
+		assert.Len(t, result, 1)
+	})
+	t.Run("not allowed to manage the service account", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		u := &user.User{ID: 2}
+
+		can, err := (&ServiceAccountToken{ServiceAccountID: 17}).CanCreate(s, u)
+		require.NoError(t, err)
+		assert.False(t, can)
+		_, _, _, err = (&ServiceAccountToken{ServiceAccountID: 17}).ReadAll(s, u, "", 1, 50)
+		require.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+	t.Run("delete a token of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		token := &ServiceAccountToken{ServiceAccountID: 17}
+		token.ID = 1
+		can, err := token.CanDelete(s, &user.User{ID: 1})
+		require.NoError(t, err)
+		assert.False(t, can)
+	})
+}
@@ -0,0 +1,304 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskChecklistItem_Create(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{
+			TaskID: 1,
+			Title:  " Buy eggs ",
+		}
+		err := item.Create(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, "Buy eggs", item.Title)
+		assert.Equal(t, int64(1), item.CreatedBy.ID)
+		assert.Equal(t, calculateDefaultPosition(item.ID, 0), item.Position)
+		err = s.Commit()
+		require.NoError(t, err)
+		events.AssertDispatched(t, &TaskUpdatedEvent{})
+
+		db.AssertExists(t, "task_checklist_items", map[string]interface{}{
+			"id":            item.ID,
+			"task_id":       1,
+			"title":         "Buy eggs",
+			"created_by_id": 1,
+		}, false)
+	})
+	t.Run("empty title", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{
+			TaskID: 1,
+			Title:  "  ",
+		}
+		err := item.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrTaskChecklistItemTitleCannotBeEmpty(err))
+	})
+	t.Run("assignee without access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{
+			TaskID:     1,
+			Title:      "Lorem",
+			AssigneeID: 2,
+		}
+		err := item.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrUserDoesNotHaveAccessToProject(err))
+	})
+	t.Run("nonexisting task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{
+			TaskID: 99999,
+			Title:  "Lorem",
+		}
+		err := item.Create(s, u)
+		require.Error(t, err)
+		assert.True(t, IsErrTaskDoesNotExist(err))
+	})
+}
+
+func TestTaskChecklistItem_ReadAll(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{TaskID: 1}
+		result, resultCount, _, err := item.ReadAll(s, u, "", 0, -1)
+		require.NoError(t, err)
+		items := result.([]*TaskChecklistItem)
+		assert.Equal(t, 2, resultCount)
+		assert.Equal(t, int64(1), items[0].ID)
+		assert.Equal(t, int64(1), items[0].Assignee.ID)
+		assert.Equal(t, int64(2), items[1].ID)
+		assert.Nil(t, items[1].Assignee)
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{TaskID: 14}
+		_, _, _, err := item.ReadAll(s, u, "", 0, -1)
+		require.Error(t, err)
+		assert.True(t, IsErrGenericForbidden(err))
+	})
+}
+
+func TestTaskChecklistItem_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("mark done", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{
+			ID:     2,
+			TaskID: 1,
+			Title:  "Buy bread",
+			Done:   true,
+		}
+		err := item.Update(s, u)
+		require.NoError(t, err)
+		assert.False(t, item.DoneAt.IsZero())
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "task_checklist_items", map[string]interface{}{
+			"id":       2,
+			"done":     true,
+			"position": 131072,
+		}, false)
+	})
+	t.Run("item of another task", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		item := &TaskChecklistItem{
+			ID:     3,
+			TaskID: 1,
+		}
+		can, err := item.CanUpdate(s, u)
+		require.Error(t, err)
+		assert.False(t, can)
+		assert.True(t, IsErrTaskChecklistItemDoesNotExist(err))
+	})
+}
+
+func TestTaskChecklistItem_Delete(t *testing.T) {
+	u := &user.User{ID: 1}
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	item := &TaskChecklistItem{ID: 1, TaskID: 1}
+	can, err := item.CanDelete(s, u)
+	require.NoError(t, err)
+	assert.True(t, can)
+	err = item.Delete(s, u)
+	require.NoError(t, err)
+	err = s.Commit()
+	require.NoError(t, err)
+
+	db.AssertMissing(t, "task_checklist_items", map[string]interface{}{
+		"id": 1,
+	})
+}
+
+func TestTask_UpdateTaskChecklistItems(t *testing.T) {
+	u := &user.User{ID: 1}
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	task := &Task{ID: 1}
+	err := task.UpdateTaskChecklistItems(s, u, []*TaskChecklistItem{
+		{Title: "Buy milk"},
+		{Title: "Buy cheese", Done: true},
+	})
+	require.NoError(t, err)
+	err = s.Commit()
+	require.NoError(t, err)
+
+	db.AssertExists(t, "task_checklist_items", map[string]interface{}{
+		"id":          1,
+		"task_id":     1,
+		"title":       "Buy milk",
+		"done":        false,
+		"assignee_id": 1,
+	}, false)
+	db.AssertExists(t, "task_checklist_items", map[string]interface{}{
+		"task_id": 1,
+		"title":   "Buy cheese",
+		"done":    true,
+	}, false)
+	db.AssertMissing(t, "task_checklist_items", map[string]interface{}{
+		"id": 2,
+	})
+}
+
+func TestTaskChecklistItemPosition_Update(t *testing.T) {
+	u := &user.User{ID: 1}
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		p := &TaskChecklistItemPosition{
+			ChecklistItemID: 2,
+			TaskID:          1,
+			Position:        100,
+		}
+		err := p.Update(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "task_checklist_items", map[string]interface{}{
+			"id":       2,
+			"position": 100,
+		}, false)
+	})
+	t.Run("recalculate", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		p := &TaskChecklistItemPosition{
+			ChecklistItemID: 2,
+			TaskID:          1,
+			Position:        0.01,
+		}
+		err := p.Update(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		items, err := getChecklistItemsByTaskIDs(s, []int64{1})
+		require.NoError(t, err)
+		require.Len(t, items, 2)
+		assert.Equal(t, int64(2), items[0].ID)
+		assert.Equal(t, int64(1), items[1].ID)
+		assert.Greater(t, items[0].Position, 0.1)
+	})
+}
+
+func TestTaskCollection_ChecklistProgressFilter(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	getTaskIDs := func(t *testing.T, filter string, includeNulls bool) []int64 {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{
+			ProjectID:          1,
+			Filter:             filter,
+			FilterIncludeNulls: includeNulls,
+		}
+		result, _, _, err := tc.ReadAll(s, u, "", 0, 50)
+		require.NoError(t, err)
+
+		ids := []int64{}
+		for _, task := range result.([]*Task) {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+
+	t.Run("partially done", func(t *testing.T) {
+		ids := getTaskIDs(t, "checklist_progress > 0 && checklist_progress < 1", false)
+		assert.Equal(t, []int64{1}, ids)
+	})
+	t.Run("completely done", func(t *testing.T) {
+		ids := getTaskIDs(t, "checklist_progress = 1", false)
+		assert.Equal(t, []int64{3}, ids)
+	})
+	t.Run("include tasks without checklist", func(t *testing.T) {
+		ids := getTaskIDs(t, "checklist_progress < 1", true)
+		assert.Contains(t, ids, int64(1))
+		assert.Contains(t, ids, int64(2))
+		assert.NotContains(t, ids, int64(3))
+	})
+}
@@ -0,0 +1,571 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// TaskChecklistItem represents a single item on the checklist of a task
+type TaskChecklistItem struct {
+	// The unique, numeric id of this checklist item.
+	ID int64 `xorm:"autoincr pk unique not null" json:"id" param:"checklistitem"`
+	// The task this checklist item belongs to.
+	TaskID int64 `xorm:"bigint not null INDEX" json:"task_id" param:"task"`
+	// The title of the checklist item. Required.
+	Title string `xorm:"text not null" json:"title" valid:"required"`
+	// Whether the checklist item is done.
+	Done bool `xorm:"null" json:"done"`
+	// The time when the checklist item was marked as done.
+	DoneAt time.Time `xorm:"null 'done_at'" json:"done_at"`
+	// The position of the checklist item within the checklist of its task. Items are always returned sorted by this.
+	// Works the same way as the task position, to move an item between two others use the position endpoint.
+	Position float64 `xorm:"double null" json:"position"`
+	// The id of the user this checklist item is assigned to. Optional, the user needs to have access to the task.
+	AssigneeID int64 `xorm:"bigint null" json:"assignee_id"`
+	// The user this checklist item is assigned to.
+	Assignee *user.User `xorm:"-" json:"assignee"`
+	// When this checklist item is due. Optional.
+	DueDate time.Time `xorm:"DATETIME null 'due_date'" json:"due_date"`
+
+	// The user who created this checklist item.
+	CreatedBy   *user.User `xorm:"-" json:"created_by"`
+	CreatedByID int64      `xorm:"bigint not null" json:"-"`
+
+	// A timestamp when this checklist item was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this checklist item was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// TableName returns the table name for task checklist items
+func (*TaskChecklistItem) TableName() string {
+	return "task_checklist_items"
+}
+
+func getTaskChecklistItemByID(s *xorm.Session, id int64) (item *TaskChecklistItem, err error) {
+	item = &TaskChecklistItem{}
+	exists, err := s.
+		Where("id = ?", id).
+		Get(item)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrTaskChecklistItemDoesNotExist{ID: id}
+	}
+	return
+}
+
+func getChecklistItemsByTaskIDs(s *xorm.Session, taskIDs []int64) (items []*TaskChecklistItem, err error) {
+	items = []*TaskChecklistItem{}
+	err = s.
+		In("task_id", taskIDs).
+		OrderBy("position asc, id asc").
+		Find(&items)
+	if err != nil {
+		return
+	}
+
+	userIDs := make([]int64, 0, len(items))
+	for _, item := range items {
+		userIDs = append(userIDs, item.CreatedByID)
+		if item.AssigneeID != 0 {
+			userIDs = append(userIDs, item.AssigneeID)
+		}
+	}
+
+	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
+	if err != nil {
+		return
+	}
+
+	for _, item := range items {
+		item.CreatedBy = users[item.CreatedByID]
+		if item.AssigneeID != 0 {
+			item.Assignee = users[item.AssigneeID]
+		}
+	}
+
+	return
+}
+
+func addChecklistItemsToTasks(s *xorm.Session, taskIDs []int64, taskMap map[int64]*Task) (err error) {
+	items, err := getChecklistItemsByTaskIDs(s, taskIDs)
+	if err != nil {
+		return
+	}
+
+	for _, item := range items {
+		taskMap[item.TaskID].ChecklistItems = append(taskMap[item.TaskID].ChecklistItems, item)
+	}
+
+	for _, task := range taskMap {
+		task.ChecklistProgress = calculateChecklistProgress(task.ChecklistItems)
+	}
+
+	return
+}
+
+// calculateChecklistProgress returns the share of done items, between 0 and 1.
+func calculateChecklistProgress(items []*TaskChecklistItem) float64 {
+	if len(items) == 0 {
+		return 0
+	}
+
+	var done int
+	for _, item := range items {
+		if item.Done {
+			done++
+		}
+	}
+
+	return float64(done) / float64(len(items))
+}
+
+// checklistProgressSQL calculates the checklist progress of the task in the outer query. It is null for tasks
+// without checklist items.
+const checklistProgressSQL = "(SELECT SUM(CASE WHEN task_checklist_items.done THEN 1 ELSE 0 END) * 1.0 / COUNT(*) " +
+	"FROM task_checklist_items WHERE task_checklist_items.task_id = tasks.id)"
+
+func getChecklistProgressFilterCond(f *taskFilter, includeNulls bool) (cond builder.Cond, err error) {
+	var operator string
+	switch f.comparator {
+	case taskFilterComparatorEquals:
+		operator = "="
+	case taskFilterComparatorNotEquals:
+		operator = "!="
+	case taskFilterComparatorGreater:
+		operator = ">"
+	case taskFilterComparatorGreateEquals:
+		operator = ">="
+	case taskFilterComparatorLess:
+		operator = "<"
+	case taskFilterComparatorLessEquals:
+		operator = "<="
+	case taskFilterComparatorIn:
+		values, is := f.value.([]interface{})
+		if !is {
+			return nil, ErrInvalidTaskFilterValue{Field: f.field, Value: f.value}
+		}
+		conds := make([]builder.Cond, 0, len(values))
+		for _, v := range values {
+			conds = append(conds, builder.Expr(checklistProgressSQL+" = ?", v))
+		}
+		cond = builder.Or(conds...)
+	default:
+		return nil, ErrInvalidTaskFilterValue{Field: f.field, Value: f.value}
+	}
+
+	if operator != "" {
+		cond = builder.Expr(checklistProgressSQL+" "+operator+" ?", f.value)
+	}
+
+	if includeNulls {
+		cond = builder.Or(cond, builder.NotIn("tasks.id", builder.Select("task_id").From("task_checklist_items")))
+	}
+
+	return
+}
+
+func (item *TaskChecklistItem) validate(s *xorm.Session, task *Task) (err error) {
+	item.Title = strings.TrimSpace(item.Title)
+	if item.Title == "" {
+		return &ErrTaskChecklistItemTitleCannotBeEmpty{}
+	}
+
+	if item.AssigneeID == 0 {
+		return nil
+	}
+
+	assignee, err := user.GetUserByID(s, item.AssigneeID)
+	if err != nil {
+		return err
+	}
+	project := &Project{ID: task.ProjectID}
+	canRead, _, err := project.CanRead(s, assignee)
+	if err != nil {
+		return err
+	}
+	if !canRead {
+		return ErrUserDoesNotHaveAccessToProject{ProjectID: task.ProjectID, UserID: item.AssigneeID}
+	}
+	item.Assignee = assignee
+
+	return nil
+}
+
+// updateDoneAt sets or resets the done_at timestamp when the done state of an item changes.
+func (item *TaskChecklistItem) updateDoneAt(wasDone bool) {
+	if item.Done && !wasDone {
+		item.DoneAt = time.Now()
+	}
+	if !item.Done {
+		item.DoneAt = time.Time{}
+	}
+}
+
+func dispatchTaskUpdatedForChecklist(s *xorm.Session, a web.Auth, taskID int64) (err error) {
+	task, err := GetTaskByIDSimple(s, taskID)
+	if err != nil {
+		return err
+	}
+
+	doer, _ := user.GetFromAuth(a)
+	err = events.Dispatch(&TaskUpdatedEvent{
+		Task: &task,
+		Doer: doer,
+	})
+	if err != nil {
+		return err
+	}
+
+	return updateProjectByTaskID(s, taskID)
+}
+
+// Create adds a new checklist item to a task
+// @Summary Add a checklist item to a task
+// @Description Adds a new checklist item to a task. The user needs to have write access to the task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param item body models.TaskChecklistItem true "The checklist item"
+// @Success 201 {object} models.TaskChecklistItem "The created checklist item."
+// @Failure 400 {object} web.HTTPError "Invalid checklist item provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/checklist [put]
+func (item *TaskChecklistItem) Create(s *xorm.Session, a web.Auth) (err error) {
+	task, err := GetTaskByIDSimple(s, item.TaskID)
+	if err != nil {
+		return err
+	}
+
+	err = item.validate(s, &task)
+	if err != nil {
+		return err
+	}
+
+	item.ID = 0
+	item.updateDoneAt(false)
+
+	item.CreatedBy, err = GetUserOrLinkShareUser(s, a)
+	if err != nil {
+		return err
+	}
+	item.CreatedByID = item.CreatedBy.ID
+
+	_, err = s.Insert(item)
+	if err != nil {
+		return err
+	}
+
+	// Items are appended to the end of the checklist unless a position was provided
+	if item.Position == 0 {
+		item.Position = calculateDefaultPosition(item.ID, item.Position)
+		_, err = s.
+			Where("id = ?", item.ID).
+			Cols("position").
+			NoAutoTime().
+			Update(item)
+		if err != nil {
+			return err
+		}
+	}
+
+	return dispatchTaskUpdatedForChecklist(s, a, item.TaskID)
+}
+
+// ReadOne returns a single checklist item
+// @Summary Get one checklist item
+// @Description Returns a single checklist item of a task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param checklistitem path int true "Checklist item ID"
+// @Success 200 {object} models.TaskChecklistItem "The checklist item."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 404 {object} web.HTTPError "The checklist item does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/checklist/{checklistitem} [get]
+func (item *TaskChecklistItem) ReadOne(s *xorm.Session, _ web.Auth) (err error) {
+	items, err := getChecklistItemsByTaskIDs(s, []int64{item.TaskID})
+	if err != nil {
+		return err
+	}
+
+	for _, i := range items {
+		if i.ID == item.ID {
+			*item = *i
+			return nil
+		}
+	}
+
+	return &ErrTaskChecklistItemDoesNotExist{ID: item.ID, TaskID: item.TaskID}
+}
+
+// ReadAll returns all checklist items of a task
+// @Summary Get the checklist of a task
+// @Description Returns all checklist items of a task, sorted by their position.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Success 200 {array} models.TaskChecklistItem "The checklist items."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/checklist [get]
+func (item *TaskChecklistItem) ReadAll(s *xorm.Session, a web.Auth, _ string, _ int, _ int) (result interface{}, resultCount int, numberOfTotalItems int64, err error) {
+	canRead, _, err := item.CanRead(s, a)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if !canRead {
+		return nil, 0, 0, ErrGenericForbidden{}
+	}
+
+	items, err := getChecklistItemsByTaskIDs(s, []int64{item.TaskID})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return items, len(items), int64(len(items)), nil
+}
+
+// Update updates a checklist item
+// @Summary Update a checklist item
+// @Description Updates the title, done state, assignee or due date of a checklist item. To move an item, use the position endpoint.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param checklistitem path int true "Checklist item ID"
+// @Param item body models.TaskChecklistItem true "The checklist item with updated values"
+// @Success 200 {object} models.TaskChecklistItem "The updated checklist item."
+// @Failure 400 {object} web.HTTPError "Invalid checklist item provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 404 {object} web.HTTPError "The checklist item does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/checklist/{checklistitem} [post]
+func (item *TaskChecklistItem) Update(s *xorm.Session, a web.Auth) (err error) {
+	old, err := getTaskChecklistItemByID(s, item.ID)
+	if err != nil {
+		return err
+	}
+
+	task, err := GetTaskByIDSimple(s, item.TaskID)
+	if err != nil {
+		return err
+	}
+
+	err = item.validate(s, &task)
+	if err != nil {
+		return err
+	}
+
+	item.DoneAt = old.DoneAt
+	item.updateDoneAt(old.Done)
+	item.Position = old.Position
+	item.CreatedByID = old.CreatedByID
+
+	_, err = s.
+		Where("id = ?", item.ID).
+		Cols("title", "done", "done_at", "assignee_id", "due_date").
+		Update(item)
+	if err != nil {
+		return err
+	}
+
+	return dispatchTaskUpdatedForChecklist(s, a, item.TaskID)
+}
+
+// Delete removes a checklist item
+// @Summary Delete a checklist item
+// @Description Removes a checklist item from a task.
+// @tags task
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param checklistitem path int true "Checklist item ID"
+// @Success 200 {object} models.Message "The checklist item was successfully deleted."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 404 {object} web.HTTPError "The checklist item does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/checklist/{checklistitem} [delete]
+func (item *TaskChecklistItem) Delete(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.
+		Where("id = ? AND task_id = ?", item.ID, item.TaskID).
+		Delete(&TaskChecklistItem{})
+	if err != nil {
+		return err
+	}
+
+	return dispatchTaskUpdatedForChecklist(s, a, item.TaskID)
+}
+
+// UpdateTaskChecklistItems changes the checklist of a task to the given items. It is used by clients which only know
+// the title and done state of the items, existing items are therefore matched by their title to keep everything else.
+func (t *Task) UpdateTaskChecklistItems(s *xorm.Session, a web.Auth, items []*TaskChecklistItem) (err error) {
+	existing, err := getChecklistItemsByTaskIDs(s, []int64{t.ID})
+	if err != nil {
+		return err
+	}
+
+	existingByTitle := make(map[string][]*TaskChecklistItem, len(existing))
+	for _, item := range existing {
+		existingByTitle[item.Title] = append(existingByTitle[item.Title], item)
+	}
+
+	for _, item := range items {
+		title := strings.TrimSpace(item.Title)
+		if title == "" {
+			continue
+		}
+
+		matching := existingByTitle[title]
+		if len(matching) == 0 {
+			item.TaskID = t.ID
+			err = item.Create(s, a)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		old := matching[0]
+		existingByTitle[title] = matching[1:]
+		if old.Done == item.Done {
+			continue
+		}
+
+		old.Done = item.Done
+		err = old.Update(s, a)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, remaining := range existingByTitle {
+		for _, item := range remaining {
+			err = item.Delete(s, a)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// TaskChecklistItemPosition is used to move a checklist item to a different position
+type TaskChecklistItemPosition struct {
+	// The id of the checklist item to move.
+	ChecklistItemID int64 `xorm:"-" json:"-" param:"checklistitem"`
+	// The task the checklist item belongs to.
+	TaskID int64 `xorm:"-" json:"-" param:"task"`
+	// The new position of the checklist item. To move an item between two others, use a value between their
+	// positions. If the value is smaller than 0.1, the positions of all items of the checklist are recalculated.
+	Position float64 `xorm:"-" json:"position"`
+
+	web.CRUDable `xorm:"-" json:"-"`
+	web.Rights   `xorm:"-" json:"-"`
+}
+
+// CanUpdate checks if a user can move a checklist item
+func (p *TaskChecklistItemPosition) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	item := &TaskChecklistItem{ID: p.ChecklistItemID, TaskID: p.TaskID}
+	return item.CanUpdate(s, a)
+}
+
+// Update moves a checklist item to a new position
+// @Summary Move a checklist item
+// @Description Updates the position of a checklist item within the checklist of its task.
+// @tags task
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param task path int true "Task ID"
+// @Param checklistitem path int true "Checklist item ID"
+// @Param position body models.TaskChecklistItemPosition true "The new position"
+// @Success 200 {object} models.TaskChecklistItemPosition "The updated position."
+// @Failure 400 {object} web.HTTPError "Invalid position provided."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the task."
+// @Failure 404 {object} web.HTTPError "The checklist item does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tasks/{task}/checklist/{checklistitem}/position [post]
+func (p *TaskChecklistItemPosition) Update(s *xorm.Session, a web.Auth) (err error) {
+	_, err = s.
+		Where("id = ? AND task_id = ?", p.ChecklistItemID, p.TaskID).
+		Cols("position").
+		Update(&TaskChecklistItem{Position: p.Position})
+	if err != nil {
+		return err
+	}
+
+	if p.Position < 0.1 {
+		err = recalculateChecklistItemPositions(s, p.TaskID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return dispatchTaskUpdatedForChecklist(s, a, p.TaskID)
+}
+
+func recalculateChecklistItemPositions(s *xorm.Session, taskID int64) (err error) {
+	items := []*TaskChecklistItem{}
+	err = s.
+		Where("task_id = ?", taskID).
+		OrderBy("position asc, id asc").
+		Find(&items)
+	if err != nil {
+		return err
+	}
+
+	maxPosition := math.Pow(2, 32)
+	for i, item := range items {
+		item.Position = maxPosition / float64(len(items)) * float64(i+1)
+		_, err = s.
+			Where("id = ?", item.ID).
+			Cols("position").
+			NoAutoTime().
+			Update(item)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -50,6 +50,10 @@ type TaskCollection struct {
 	// pagination limit being returned, but all subtasks will be present in the response.
 	Expand TaskCollectionExpandable `query:"expand" json:"-"`
 
+	// The start and end of the date range to return tasks for. Only used for calendar views, where they are required.
+	DateFrom string `query:"date_from" json:"-"`
+	DateTo   string `query:"date_to" json:"-"`
+
 	isSavedFilter bool
 
 	web.CRUDable `xorm:"-" json:"-"`
@@ -72,6 +76,7 @@ func validateTaskField(fieldName string) error {
 		taskPropertyCreatedByID,
 		taskPropertyProjectID,
 		taskPropertyRepeatAfter,
+		taskPropertyRepeatMode,
 		taskPropertyPriority,
 		taskPropertyStartDate,
 		taskPropertyEndDate,
@@ -136,7 +141,17 @@ func getTaskFilterOptsFromCollection(tf *TaskCollection, projectView *ProjectVie
 }
 
 func getTaskOrTasksInBuckets(s *xorm.Session, a web.Auth, projects []*Project, view *ProjectView, opts *taskSearchOptions) (tasks interface{}, resultCount int, totalItems int64, err error) {
+	if view != nil && view.ViewKind == ProjectViewKindCalendar {
+		return getTasksForCalendarView(s, a, projects, view, opts)
+	}
+
 	if view != nil && !strings.Contains(opts.filter, "bucket_id") {
+		if view.BucketConfigurationMode != BucketConfigurationModeNone &&
+			view.SwimlaneConfigurationMode != SwimlaneConfigurationModeNone {
+			swimlanes, err := GetTasksInSwimlanesForView(s, view, projects, opts, a)
+			return swimlanes, len(swimlanes), int64(len(swimlanes)), err
+		}
+
 		if view.BucketConfigurationMode != BucketConfigurationModeNone {
 			tasksInBuckets, err := GetTasksInBucketsForView(s, view, projects, opts, a)
 			return tasksInBuckets, len(tasksInBuckets), int64(len(tasksInBuckets)), err
@@ -151,7 +166,7 @@ func getRelevantProjectsFromCollection(s *xorm.Session, a web.Auth, tf *TaskColl
 		projects, _, _, err = getRawProjectsForUser(
 			s,
 			&projectOptions{
-				user: &user.User{ID: a.GetID()},
+				user: &user.User{ID: a.GetID(), APITokenScope: getAPITokenScope(a)},
 				page: -1,
 			},
 		)
@@ -191,8 +206,11 @@ func getRelevantProjectsFromCollection(s *xorm.Session, a web.Auth, tf *TaskColl
 // @Param filter_timezone query string false "The time zone which should be used for date match (statements like "now" resolve to different actual times)"
 // @Param filter_include_nulls query string false "If set to true the result will include filtered fields whose value is set to `null`. Available values are `true` or `false`. Defaults to `false`."
 // @Param expand query string false "If set to `subtasks`, Vikunja will fetch only tasks which do not have subtasks and then in a second step, will fetch all of these subtasks. This may result in more tasks than the pagination limit being returned, but all subtasks will be present in the response. You can only set this to `subtasks`."
+// @Param date_from query string false "Only used for calendar views, where it is required. The start of the date range to return tasks for. Accepts the same date formats as the filter query."
+// @Param date_to query string false "Only used for calendar views, where it is required. The end of the date range to return tasks for. Accepts the same date formats as the filter query."
 // @Security JWTKeyAuth
-// @Success 200 {array} models.Task "The tasks"
+// @Success 200 {array} models.Task "The tasks. For calendar views, this is an array of models.CalendarTask instead. For kanban views, this is an array of models.Bucket or, if the view has swimlanes, models.Swimlane."
+// @Failure 400 {object} web.HTTPError "The date range for a calendar view is missing or invalid."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /projects/{id}/views/{view}/tasks [get]
 func (tf *TaskCollection) ReadAll(s *xorm.Session, a web.Auth, search string, page int, perPage int) (result interface{}, resultCount int, totalItems int64, err error) {
@@ -232,6 +250,8 @@ func (tf *TaskCollection) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 		tc := sf.getTaskCollection()
 		tc.ProjectViewID = tf.ProjectViewID
 		tc.ProjectID = tf.ProjectID
+		tc.DateFrom = tf.DateFrom
+		tc.DateTo = tf.DateTo
 		tc.isSavedFilter = true
 
 		return tc.ReadAll(s, a, search, page, perPage)
@@ -253,6 +273,20 @@ func (tf *TaskCollection) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 		}
 	}
 
+	var dateRange *calendarDateRange
+	if view != nil && view.ViewKind == ProjectViewKindCalendar {
+		dateRange, err = getCalendarDateRange(tf.DateFrom, tf.DateTo, tf.FilterTimezone)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+
+		if tf.Filter != "" {
+			tf.Filter = "(" + tf.Filter + ") && (" + dateRange.getFilter() + ")"
+		} else {
+			tf.Filter = dateRange.getFilter()
+		}
+	}
+
 	opts, err := getTaskFilterOptsFromCollection(tf, view)
 	if err != nil {
 		return nil, 0, 0, err
@@ -263,6 +297,7 @@ func (tf *TaskCollection) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 	opts.perPage = perPage
 	opts.expand = tf.Expand
 	opts.isSavedFilter = tf.isSavedFilter
+	opts.calendarDateRange = dateRange
 
 	if view != nil {
 		var hasOrderByPosition bool
@@ -283,6 +318,11 @@ func (tf *TaskCollection) ReadAll(s *xorm.Session, a web.Auth, search string, pa
 
 	shareAuth, is := a.(*LinkSharing)
 	if is {
+		// Link shares for a single task or view can only get the tasks of their view
+		if !shareAuth.targetsProject() && (view == nil || view.ID != shareAuth.ProjectViewID) {
+			return nil, 0, 0, ErrGenericForbidden{}
+		}
+
 		project, err := GetProjectSimpleByID(s, shareAuth.ProjectID)
 		if err != nil {
 			return nil, 0, 0, err
@@ -0,0 +1,209 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/web"
+
+	"github.com/jszwedko/go-datemath"
+	"xorm.io/xorm"
+)
+
+// maxCalendarOccurrences limits how many occurrences of a single repeating task are returned for one date range.
+const maxCalendarOccurrences = 500
+
+// CalendarTask represents a task as it is shown in a calendar view.
+type CalendarTask struct {
+	Task
+	// If true, this is a computed occurrence of a repeating task and not the task itself. It shares the id of the
+	// task it was computed from and only its dates differ. Occurrences cannot be changed, change the original task instead.
+	IsOccurrence bool `json:"is_occurrence"`
+}
+
+type calendarDateRange struct {
+	from time.Time
+	to   time.Time
+}
+
+func parseCalendarDate(rawDate string, loc *time.Location) (date time.Time, err error) {
+	expr, err := datemath.Parse(rawDate)
+	if err == nil {
+		return expr.Time(datemath.WithLocation(config.GetTimeZone())).In(loc), nil
+	}
+
+	return parseTimeFromUserInput(rawDate)
+}
+
+func getCalendarDateRange(rawFrom, rawTo, timezone string) (dateRange *calendarDateRange, err error) {
+	errInvalidRange := &ErrInvalidCalendarDateRange{
+		DateFrom: rawFrom,
+		DateTo:   rawTo,
+	}
+
+	if rawFrom == "" || rawTo == "" {
+		return nil, errInvalidRange
+	}
+
+	loc := config.GetTimeZone()
+	if timezone != "" {
+		loc, err = time.LoadLocation(timezone)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	dateRange = &calendarDateRange{}
+	dateRange.from, err = parseCalendarDate(rawFrom, loc)
+	if err != nil {
+		return nil, errInvalidRange
+	}
+	dateRange.to, err = parseCalendarDate(rawTo, loc)
+	if err != nil {
+		return nil, errInvalidRange
+	}
+
+	if dateRange.from.After(dateRange.to) {
+		return nil, errInvalidRange
+	}
+
+	return dateRange, nil
+}
+
+// getFilter returns a filter query matching all tasks which could show up in the date range.
+// Repeating tasks which started before the range might still have occurrences in it, so they are
+// only bounded by the end of the range. Everything else is sorted out once the occurrences are known.
+func (r *calendarDateRange) getFilter() string {
+	from := r.from.Format(time.RFC3339)
+	to := r.to.Format(time.RFC3339)
+	return "(due_date <= " + to + " || start_date <= " + to + " || end_date <= " + to + ") && " +
+		"(due_date >= " + from + " || start_date >= " + from + " || end_date >= " + from + " || " +
+		"repeat_after > 0 || repeat_mode = " + strconv.Itoa(int(TaskRepeatModeMonth)) + ")"
+}
+
+func (r *calendarDateRange) overlaps(start, end time.Time) bool {
+	return !start.After(r.to) && !end.Before(r.from)
+}
+
+// getTaskDateSpan returns the earliest and latest date of due, start and end date of a task.
+func getTaskDateSpan(t *Task) (start, end time.Time, hasDates bool) {
+	for _, d := range []time.Time{t.DueDate, t.StartDate, t.EndDate} {
+		if d.IsZero() {
+			continue
+		}
+		if !hasDates || d.Before(start) {
+			start = d
+		}
+		if !hasDates || d.After(end) {
+			end = d
+		}
+		hasDates = true
+	}
+
+	return
+}
+
+// getDateOfRepetition returns the date a task date will have after the task was repeated n times.
+func getDateOfRepetition(t *Task, d time.Time, n int) time.Time {
+	if d.IsZero() || n == 0 {
+		return d
+	}
+
+	if t.RepeatMode == TaskRepeatModeMonth {
+		return time.Date(d.Year(), d.Month()+time.Month(n), d.Day(), d.Hour(), d.Minute(), d.Second(), d.Nanosecond(), d.Location())
+	}
+
+	return d.Add(time.Duration(t.RepeatAfter) * time.Second * time.Duration(n))
+}
+
+// getFirstRelevantRepetition returns how often a task needs to repeat until it could show up in the date range.
+// This avoids walking through all repetitions of tasks which started a long time ago.
+func (r *calendarDateRange) getFirstRelevantRepetition(t *Task, end time.Time) (n int) {
+	if !end.Before(r.from) {
+		return 0
+	}
+
+	if t.RepeatMode == TaskRepeatModeMonth {
+		n = (r.from.Year()-end.Year())*12 + int(r.from.Month()) - int(end.Month()) - 1
+	} else {
+		n = int(r.from.Sub(end) / (time.Duration(t.RepeatAfter) * time.Second))
+	}
+
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+func (r *calendarDateRange) getOccurrences(t *Task) (occurrences []*CalendarTask) {
+	start, end, hasDates := getTaskDateSpan(t)
+	if !hasDates {
+		return nil
+	}
+
+	if !t.isRepeating() {
+		if r.overlaps(start, end) {
+			return []*CalendarTask{{Task: *t}}
+		}
+		return nil
+	}
+
+	first := r.getFirstRelevantRepetition(t, end)
+	for n := first; n < first+maxCalendarOccurrences; n++ {
+		occurrenceStart := getDateOfRepetition(t, start, n)
+		if occurrenceStart.After(r.to) {
+			break
+		}
+		if !r.overlaps(occurrenceStart, getDateOfRepetition(t, end, n)) {
+			continue
+		}
+
+		occurrence := &CalendarTask{
+			Task:         *t,
+			IsOccurrence: n > 0,
+		}
+		occurrence.DueDate = getDateOfRepetition(t, t.DueDate, n)
+		occurrence.StartDate = getDateOfRepetition(t, t.StartDate, n)
+		occurrence.EndDate = getDateOfRepetition(t, t.EndDate, n)
+		occurrences = append(occurrences, occurrence)
+	}
+
+	return
+}
+
+// getTasksForCalendarView returns all tasks and occurrences of repeating tasks overlapping the requested date range.
+// Pagination applies to the tasks, all occurrences of a repeating task are always returned on the same page.
+func getTasksForCalendarView(s *xorm.Session, a web.Auth, projects []*Project, view *ProjectView, opts *taskSearchOptions) (tasks []*CalendarTask, resultCount int, totalItems int64, err error) {
+	if opts.calendarDateRange == nil {
+		return nil, 0, 0, &ErrInvalidCalendarDateRange{}
+	}
+
+	rawTasks, _, totalItems, err := getTasksForProjects(s, projects, a, opts, view)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	tasks = []*CalendarTask{}
+	for _, t := range rawTasks {
+		tasks = append(tasks, opts.calendarDateRange.getOccurrences(t)...)
+	}
+
+	return tasks, len(tasks), totalItems, nil
+}
@@ -0,0 +1,215 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskCollection_ReadAll_CalendarView(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	createCalendarView := func(t *testing.T, filter string) *ProjectView {
+		s := db.NewSession()
+		defer s.Close()
+
+		view := &ProjectView{
+			ProjectID: 1,
+			Title:     "Calendar",
+			ViewKind:  ProjectViewKindCalendar,
+			Filter:    filter,
+		}
+		_, err := s.Insert(view)
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+		return view
+	}
+
+	getTaskIDs := func(result interface{}) (ids []int64) {
+		for _, task := range result.([]*CalendarTask) {
+			ids = append(ids, task.ID)
+		}
+		return
+	}
+
+	t.Run("tasks overlapping the date range", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		view := createCalendarView(t, "")
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{
+			ProjectID:     1,
+			ProjectViewID: view.ID,
+			DateFrom:      "2018-12-10",
+			DateTo:        "2018-12-31",
+		}
+		result, _, _, err := tc.ReadAll(s, u, "", 1, 50)
+		require.NoError(t, err)
+		ids := getTaskIDs(result)
+		assert.ElementsMatch(t, []int64{7, 8, 9}, ids)
+	})
+	t.Run("honors the view filter", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		view := createCalendarView(t, "end_date > 2018-12-01")
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{
+			ProjectID:     1,
+			ProjectViewID: view.ID,
+			DateFrom:      "2018-12-10",
+			DateTo:        "2018-12-31",
+		}
+		result, _, _, err := tc.ReadAll(s, u, "", 1, 50)
+		require.NoError(t, err)
+		ids := getTaskIDs(result)
+		assert.ElementsMatch(t, []int64{8, 9}, ids)
+	})
+	t.Run("monthly repeating task from before the date range", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		view := createCalendarView(t, "")
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := s.
+			Where("id = ?", 28).
+			Cols("due_date", "repeat_after", "repeat_mode").
+			Update(&Task{
+				DueDate:    time.Date(2018, 1, 15, 12, 0, 0, 0, config.GetTimeZone()),
+				RepeatMode: TaskRepeatModeMonth,
+			})
+		require.NoError(t, err)
+
+		tc := &TaskCollection{
+			ProjectID:     1,
+			ProjectViewID: view.ID,
+			DateFrom:      "2018-12-10",
+			DateTo:        "2018-12-31",
+		}
+		result, _, _, err := tc.ReadAll(s, u, "", 1, 50)
+		require.NoError(t, err)
+		ids := getTaskIDs(result)
+		assert.ElementsMatch(t, []int64{7, 8, 9, 28}, ids)
+	})
+	t.Run("missing date range", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		view := createCalendarView(t, "")
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{
+			ProjectID:     1,
+			ProjectViewID: view.ID,
+			DateFrom:      "2018-12-10",
+		}
+		_, _, _, err := tc.ReadAll(s, u, "", 1, 50)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidCalendarDateRange(err))
+	})
+	t.Run("date range in the wrong order", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		view := createCalendarView(t, "")
+		s := db.NewSession()
+		defer s.Close()
+
+		tc := &TaskCollection{
+			ProjectID:     1,
+			ProjectViewID: view.ID,
+			DateFrom:      "2018-12-31",
+			DateTo:        "2018-12-10",
+		}
+		_, _, _, err := tc.ReadAll(s, u, "", 1, 50)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidCalendarDateRange(err))
+	})
+}
+
+func TestCalendarDateRange_getOccurrences(t *testing.T) {
+	dateRange := &calendarDateRange{
+		from: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		to:   time.Date(2024, 3, 31, 23, 59, 59, 0, time.UTC),
+	}
+
+	t.Run("task without dates", func(t *testing.T) {
+		assert.Empty(t, dateRange.getOccurrences(&Task{ID: 1}))
+	})
+	t.Run("task outside of the range", func(t *testing.T) {
+		task := &Task{
+			ID:      1,
+			DueDate: time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC),
+		}
+		assert.Empty(t, dateRange.getOccurrences(task))
+	})
+	t.Run("task spanning the range", func(t *testing.T) {
+		task := &Task{
+			ID:        1,
+			StartDate: time.Date(2024, 2, 20, 10, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2024, 4, 2, 10, 0, 0, 0, time.UTC),
+		}
+		occurrences := dateRange.getOccurrences(task)
+		require.Len(t, occurrences, 1)
+		assert.False(t, occurrences[0].IsOccurrence)
+	})
+	t.Run("repeating task", func(t *testing.T) {
+		task := &Task{
+			ID:          1,
+			DueDate:     time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+			RepeatAfter: 60 * 60 * 24 * 7,
+		}
+		occurrences := dateRange.getOccurrences(task)
+		require.Len(t, occurrences, 4)
+		for i, day := range []int{4, 11, 18, 25} {
+			assert.Equal(t, time.Date(2024, 3, day, 10, 0, 0, 0, time.UTC), occurrences[i].DueDate)
+			assert.True(t, occurrences[i].IsOccurrence)
+			assert.Equal(t, int64(1), occurrences[i].ID)
+		}
+	})
+	t.Run("repeating task starting in the range", func(t *testing.T) {
+		task := &Task{
+			ID:          1,
+			DueDate:     time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC),
+			RepeatAfter: 60 * 60 * 24 * 7,
+		}
+		occurrences := dateRange.getOccurrences(task)
+		require.Len(t, occurrences, 2)
+		assert.False(t, occurrences[0].IsOccurrence)
+		assert.Equal(t, time.Date(2024, 3, 20, 10, 0, 0, 0, time.UTC), occurrences[0].DueDate)
+		assert.True(t, occurrences[1].IsOccurrence)
+		assert.Equal(t, time.Date(2024, 3, 27, 10, 0, 0, 0, time.UTC), occurrences[1].DueDate)
+	})
+	t.Run("monthly repeating task", func(t *testing.T) {
+		task := &Task{
+			ID:         1,
+			StartDate:  time.Date(2023, 11, 15, 10, 0, 0, 0, time.UTC),
+			EndDate:    time.Date(2023, 11, 16, 10, 0, 0, 0, time.UTC),
+			RepeatMode: TaskRepeatModeMonth,
+		}
+		occurrences := dateRange.getOccurrences(task)
+		require.Len(t, occurrences, 1)
+		assert.Equal(t, time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC), occurrences[0].StartDate)
+		assert.Equal(t, time.Date(2024, 3, 16, 10, 0, 0, 0, time.UTC), occurrences[0].EndDate)
+	})
+}
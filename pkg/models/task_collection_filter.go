@@ -259,6 +259,8 @@ func getValueForField(field reflect.StructField, rawValue string, loc *time.Loca
 	rawValue = strings.TrimSpace(rawValue)
 
 	switch field.Type.Kind() {
+	case reflect.Int:
+		value, err = strconv.Atoi(rawValue)
 	case reflect.Int64:
 		value, err = strconv.ParseInt(rawValue, 10, 64)
 	case reflect.Float64:
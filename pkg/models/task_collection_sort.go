@@ -36,6 +36,7 @@ const (
 	taskPropertyCreatedByID   string = "created_by_id"
 	taskPropertyProjectID     string = "project_id"
 	taskPropertyRepeatAfter   string = "repeat_after"
+	taskPropertyRepeatMode    string = "repeat_mode"
 	taskPropertyPriority      string = "priority"
 	taskPropertyStartDate     string = "start_date"
 	taskPropertyEndDate       string = "end_date"
@@ -48,6 +49,8 @@ const (
 	taskPropertyBucketID      string = "bucket_id"
 	taskPropertyIndex         string = "index"
 	taskPropertyProjectViewID string = "project_view_id"
+
+	taskPropertyChecklistProgress string = "checklist_progress"
 )
 
 const (
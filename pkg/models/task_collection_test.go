@@ -155,8 +155,36 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 				},
 			},
 		},
-		Created: time.Unix(1543626724, 0).In(loc),
-		Updated: time.Unix(1543626724, 0).In(loc),
+		ChecklistItems: []*TaskChecklistItem{
+			{
+				ID:          1,
+				TaskID:      1,
+				Title:       "Buy milk",
+				Done:        true,
+				DoneAt:      time.Unix(1543626824, 0).In(loc),
+				Position:    65536,
+				AssigneeID:  1,
+				Assignee:    user1,
+				CreatedByID: 1,
+				CreatedBy:   user1,
+				Created:     time.Unix(1543626724, 0).In(loc),
+				Updated:     time.Unix(1543626724, 0).In(loc),
+			},
+			{
+				ID:          2,
+				TaskID:      1,
+				Title:       "Buy bread",
+				Position:    131072,
+				DueDate:     time.Unix(1543713124, 0).In(loc),
+				CreatedByID: 1,
+				CreatedBy:   user1,
+				Created:     time.Unix(1543626724, 0).In(loc),
+				Updated:     time.Unix(1543626724, 0).In(loc),
+			},
+		},
+		ChecklistProgress: 0.5,
+		Created:           time.Unix(1543626724, 0).In(loc),
+		Updated:           time.Unix(1543626724, 0).In(loc),
 	}
 	task2 := &Task{
 		ID:          2,
@@ -191,9 +219,24 @@ func TestTaskCollection_ReadAll(t *testing.T) {
 		CreatedBy:    user1,
 		ProjectID:    1,
 		RelatedTasks: map[RelationKind][]*Task{},
-		Created:      time.Unix(1543626724, 0).In(loc),
-		Updated:      time.Unix(1543626724, 0).In(loc),
-		Priority:     100,
+		ChecklistItems: []*TaskChecklistItem{
+			{
+				ID:          3,
+				TaskID:      3,
+				Title:       "Call the bank",
+				Done:        true,
+				DoneAt:      time.Unix(1543626824, 0).In(loc),
+				Position:    65536,
+				CreatedByID: 1,
+				CreatedBy:   user1,
+				Created:     time.Unix(1543626724, 0).In(loc),
+				Updated:     time.Unix(1543626724, 0).In(loc),
+			},
+		},
+		ChecklistProgress: 1,
+		Created:           time.Unix(1543626724, 0).In(loc),
+		Updated:           time.Unix(1543626724, 0).In(loc),
+		Priority:          100,
 	}
 	task4 := &Task{
 		ID:           4,
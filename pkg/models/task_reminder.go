@@ -30,6 +30,7 @@ import (
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/cron"
 	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/metrics"
 	"code.vikunja.io/api/pkg/user"
 )
 
@@ -245,6 +246,11 @@ func RegisterReminderCron() {
 		defer s.Close()
 
 		now := time.Now()
+		// The cron is scheduled at the start of every minute, everything after that is lag.
+		defer func() {
+			metrics.SetReminderCronLag(time.Since(now.Truncate(time.Minute)))
+		}()
+
 		reminders, err := getTasksWithRemindersDueAndTheirUsers(s, now)
 		if err != nil {
 			log.Errorf("[Task Reminder Cron] Could not get tasks with reminders in the next minute: %s", err)
@@ -155,6 +155,15 @@ func convertFiltersToDBFilterCond(rawFilters []*taskFilter, includeNulls bool) (
 			continue
 		}
 
+		if f.field == taskPropertyChecklistProgress {
+			filter, err := getChecklistProgressFilterCond(f, includeNulls)
+			if err != nil {
+				return nil, err
+			}
+			dbFilters = append(dbFilters, filter)
+			continue
+		}
+
 		if f.field == "parent_project" || f.field == "parent_project_id" {
 			filter, err := getFilterCond(&taskFilter{
 				// recreating the struct here to avoid modifying it when reusing the opts struct
@@ -100,6 +100,11 @@ type Task struct {
 	// All attachments this task has
 	Attachments []*TaskAttachment `xorm:"-" json:"attachments"`
 
+	// The checklist of this task, sorted by position. Use the checklist endpoints to modify it.
+	ChecklistItems []*TaskChecklistItem `xorm:"-" json:"checklist_items"`
+	// How much of the checklist is done, between 0 and 1. Will be 0 if the task does not have a checklist.
+	ChecklistProgress float64 `xorm:"-" json:"checklist_progress"`
+
 	// If this task has a cover image, the field will return the id of the attachment that is the cover image.
 	CoverImageAttachmentID int64 `xorm:"bigint default 0" json:"cover_image_attachment_id"`
 
@@ -139,6 +144,9 @@ type Task struct {
 type TaskWithComments struct {
 	Task
 	Comments []*TaskComment `xorm:"-" json:"comments"`
+	// Only used for migration. The id of the task in the service it was migrated from. It is used to resume
+	// a migration which did not finish.
+	SourceID string `xorm:"-" json:"-"`
 }
 
 // TableName returns the table name for tasks
@@ -186,6 +194,7 @@ type taskSearchOptions struct {
 	isSavedFilter      bool
 	projectIDs         []int64
 	expand             TaskCollectionExpandable
+	calendarDateRange  *calendarDateRange
 }
 
 // ReadAll is a dummy function to still have that endpoint documented
@@ -586,6 +595,11 @@ func addMoreInfoToTasks(s *xorm.Session, taskMap map[int64]*Task, a web.Auth, vi
 		return
 	}
 
+	err = addChecklistItemsToTasks(s, taskIDs, taskMap)
+	if err != nil {
+		return
+	}
+
 	users, err := getUsersOrLinkSharesFromIDs(s, userIDs)
 	if err != nil {
 		return
@@ -1510,6 +1524,12 @@ func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
+	// Delete the checklist
+	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskChecklistItem{})
+	if err != nil {
+		return
+	}
+
 	// Delete all positions
 	_, err = s.Where("task_id = ?", t.ID).Delete(&TaskPosition{})
 	if err != nil {
@@ -1522,6 +1542,12 @@ func (t *Task) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
+	// Delete all link shares of this task
+	_, err = deleteLinkShares(s, builder.Eq{"task_id": t.ID})
+	if err != nil {
+		return
+	}
+
 	// Actually delete the task
 	_, err = s.ID(t.ID).Delete(Task{})
 	if err != nil {
@@ -23,6 +23,11 @@ import (
 
 // CanDelete checks if the user can delete an task
 func (t *Task) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	// Link shares for a single task or view can never delete tasks
+	if share, is := a.(*LinkSharing); is && !share.targetsProject() {
+		return false, nil
+	}
+
 	return t.canDoTask(s, a)
 }
 
@@ -46,6 +51,11 @@ func (t *Task) CanRead(s *xorm.Session, a web.Auth) (canRead bool, maxRight int,
 		return
 	}
 
+	if share, is := a.(*LinkSharing); is && !share.targetsProject() {
+		canRead, err = share.canReadTask(s, t)
+		return canRead, int(share.Right), err
+	}
+
 	// A user can read a task if it has access to the project
 	l := &Project{ID: t.ProjectID}
 	return l.CanRead(s, a)
@@ -76,7 +86,36 @@ func (t *Task) canDoTask(s *xorm.Session, a web.Auth) (bool, error) {
 		}
 	}
 
+	// Link shares for a single task can only change that task, view link shares are read only
+	if share, is := a.(*LinkSharing); is && !share.targetsProject() {
+		if share.TaskID != ot.ID || share.Right != RightWrite {
+			return false, nil
+		}
+		p, err := GetProjectSimpleByID(s, ot.ProjectID)
+		if err != nil {
+			return false, err
+		}
+		return true, p.CheckIsArchived(s)
+	}
+
 	// A user can do a task if it has write acces to its project
 	l := &Project{ID: ot.ProjectID}
 	return l.CanWrite(s, a)
 }
+
+// canReadTask checks if a link share for a single task or view can see a task.
+func (share *LinkSharing) canReadTask(s *xorm.Session, t *Task) (bool, error) {
+	if t.ProjectID != share.ProjectID {
+		return false, nil
+	}
+
+	if share.TaskID != 0 {
+		return t.ID == share.TaskID, nil
+	}
+
+	view, err := GetProjectViewByIDAndProject(s, share.ProjectViewID, share.ProjectID)
+	if err != nil {
+		return false, err
+	}
+	return view.containsTask(s, share, t)
+}
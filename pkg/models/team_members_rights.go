@@ -29,6 +29,10 @@ func (tm *TeamMember) CanCreate(s *xorm.Session, a web.Auth) (bool, error) {
 
 // CanDelete checks if the user can delete a new team member
 func (tm *TeamMember) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	if !apiTokenCanChangeUnscoped(a) {
+		return false, nil
+	}
+
 	u, err := user.GetUserByUsername(s, tm.Username)
 	if err != nil {
 		return false, err
@@ -51,6 +55,10 @@ func (tm *TeamMember) IsAdmin(s *xorm.Session, a web.Auth) (bool, error) {
 		return false, nil
 	}
 
+	if !apiTokenCanChangeUnscoped(a) {
+		return false, nil
+	}
+
 	// A user can add a member to a team if he is admin of that team
 	exists, err := s.
 		Where("user_id = ? AND team_id = ? AND admin = ?", a.GetID(), tm.TeamID, true).
@@ -167,6 +167,20 @@ func FindAllOidcTeamIDsForUser(s *xorm.Session, userID int64) (ts []int64, err e
 	return ts, nil
 }
 
+// FindAllTeamIDsForUserByIssuer returns the ids of all teams a user is a member of which were created from
+// the groups of an external identity provider.
+func FindAllTeamIDsForUserByIssuer(s *xorm.Session, userID int64, issuer string) (ts []int64, err error) {
+	ts = []int64{}
+	err = s.
+		Table("team_members").
+		Join("INNER", "teams", "teams.id = team_members.team_id").
+		Where("team_members.user_id = ? AND teams.issuer = ?", userID, issuer).
+		And("teams.oidc_id != ? AND teams.oidc_id IS NOT NULL", "").
+		Cols("teams.id").
+		Find(&ts)
+	return
+}
+
 func addMoreInfoToTeams(s *xorm.Session, teams []*Team) (err error) {
 
 	if len(teams) == 0 {
@@ -255,6 +269,25 @@ func (t *Team) CreateNewTeam(s *xorm.Session, a web.Auth, firstUserShouldBeAdmin
 	})
 }
 
+// CreateExternalTeam creates a team which is managed by an external identity provider, for example via scim.
+// Unlike teams created by users, it has no creator who becomes a member of the team.
+func CreateExternalTeam(s *xorm.Session, t *Team) (err error) {
+	if t.Name == "" {
+		return ErrTeamNameCannotBeEmpty{}
+	}
+
+	t.ID = 0
+	t.CreatedByID = 0
+	_, err = s.Insert(t)
+	if err != nil {
+		return
+	}
+
+	return events.Dispatch(&TeamCreatedEvent{
+		Team: t,
+	})
+}
+
 // ReadOne implements the CRUD method to get one team
 // @Summary Gets one team
 // @Description Returns a team by its ID.
@@ -388,6 +421,16 @@ func (t *Team) Delete(s *xorm.Session, a web.Auth) (err error) {
 		return
 	}
 
+	// Service accounts of the team are managed by the instance admins from now on
+	_, err = s.
+		Where("bot_owner_team_id = ?", t.ID).
+		Cols("bot_owner_team_id").
+		NoAutoTime().
+		Update(&user.User{BotOwnerTeamID: 0})
+	if err != nil {
+		return
+	}
+
 	return events.Dispatch(&TeamDeletedEvent{
 		Team: t,
 		Doer: a,
@@ -28,16 +28,22 @@ func (t *Team) CanCreate(_ *xorm.Session, a web.Auth) (bool, error) {
 	}
 
 	// This is currently a dummy function, later on we could imagine global limits etc.
-	return true, nil
+	return apiTokenCanChangeUnscoped(a), nil
 }
 
 // CanUpdate checks if the user can update a team
 func (t *Team) CanUpdate(s *xorm.Session, a web.Auth) (bool, error) {
+	if !apiTokenCanChangeUnscoped(a) {
+		return false, nil
+	}
 	return t.IsAdmin(s, a)
 }
 
 // CanDelete checks if a user can delete a team
 func (t *Team) CanDelete(s *xorm.Session, a web.Auth) (bool, error) {
+	if !apiTokenCanChangeUnscoped(a) {
+		return false, nil
+	}
 	return t.IsAdmin(s, a)
 }
 
@@ -270,6 +270,10 @@ func TestTeam_Delete(t *testing.T) {
 		db.AssertMissing(t, "teams", map[string]interface{}{
 			"id": 1,
 		})
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":                17,
+			"bot_owner_team_id": 0,
+		}, false)
 	})
 }
 
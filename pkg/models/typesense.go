@@ -120,6 +120,10 @@ func CreateTypesenseCollections() error {
 				Name: "percent_done",
 				Type: "float",
 			},
+			{
+				Name: "checklist_progress",
+				Type: "float",
+			},
 			{
 				Name: "identifier",
 				Type: "string",
@@ -467,6 +471,7 @@ type typesenseTask struct {
 	EndDate                *int64      `json:"end_date"`
 	HexColor               string      `json:"hex_color"`
 	PercentDone            float64     `json:"percent_done"`
+	ChecklistProgress      float64     `json:"checklist_progress"`
 	Identifier             string      `json:"identifier"`
 	Index                  int64       `json:"index"`
 	UID                    string      `json:"uid"`
@@ -501,6 +506,7 @@ func convertTaskToTypesenseTask(task *Task, positions []*TaskPositionWithView, b
 		EndDate:                pointer.Int64(task.EndDate.UTC().Unix()),
 		HexColor:               task.HexColor,
 		PercentDone:            task.PercentDone,
+		ChecklistProgress:      task.ChecklistProgress,
 		Identifier:             task.Identifier,
 		Index:                  task.Index,
 		UID:                    task.UID,
@@ -69,6 +69,18 @@ func SetupTests() {
 		"project_views",
 		"task_positions",
 		"task_buckets",
+		"project_automations",
+		"project_automation_logs",
+		"task_checklist_items",
+		"sessions",
+		"user_webauthn_credentials",
+		"user_recovery_codes",
+		"user_audit_log",
+		"oauth_clients",
+		"oauth_authorizations",
+		"oauth_authorization_codes",
+		"oauth_refresh_tokens",
+		"link_share_access_log",
 	)
 	if err != nil {
 		log.Fatal(err)
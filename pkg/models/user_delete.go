@@ -149,14 +149,62 @@ func DeleteUser(s *xorm.Session, u *user.User) (err error) {
 		}
 	}
 
-	_, err = s.Where("id = ?", u.ID).Delete(&user.User{})
+	err = deleteUserExportData(s, u)
+	if err != nil {
+		return err
+	}
+
+	err = user.DeleteAllSessions(s, u.ID, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, bean := range []interface{}{&TeamMember{}, &user.WebAuthnCredential{}, &user.RecoveryCode{}, &user.AuditLogEntry{}} {
+		_, err = s.Where("user_id = ?", u.ID).Delete(bean)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = s.Where("owner_id = ?", u.ID).Delete(&APIToken{})
 	if err != nil {
 		return err
 	}
 
-	return notifications.Notify(u, &user.AccountDeletedNotification{
+	authorizations := []*OAuthAuthorization{}
+	err = s.Where("user_id = ?", u.ID).Find(&authorizations)
+	if err != nil {
+		return err
+	}
+	for _, authorization := range authorizations {
+		err = authorization.revoke(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	clients := []*OAuthClient{}
+	err = s.Where("owner_id = ?", u.ID).Find(&clients)
+	if err != nil {
+		return err
+	}
+	for _, client := range clients {
+		err = client.Delete(s, u)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The notification checks if the user still exists, so it needs to be sent before they are deleted
+	err = notifications.Notify(u, &user.AccountDeletedNotification{
 		User: u,
 	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("id = ?", u.ID).Delete(&user.User{})
+	return err
 }
 
 func ensureProjectAdminUser(s *xorm.Session, l *Project) (hadUsers bool, err error) {
@@ -0,0 +1,528 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+	"code.vikunja.io/api/pkg/version"
+
+	"xorm.io/xorm"
+)
+
+const (
+	defaultUserExportRetention = 7
+	maxUserExportRetention     = 100
+	// Backups larger than this are only linked in the mail, not attached.
+	maxUserExportMailAttachmentSize = 10 * 1024 * 1024
+)
+
+// UserExportInterval defines how often a scheduled export is created
+type UserExportInterval string
+
+const (
+	UserExportIntervalDaily  UserExportInterval = `daily`
+	UserExportIntervalWeekly UserExportInterval = `weekly`
+)
+
+// Validate checks if the interval is known
+func (i UserExportInterval) Validate() error {
+	switch i {
+	case UserExportIntervalDaily, UserExportIntervalWeekly:
+		return nil
+	}
+	return &ErrInvalidExportInterval{Interval: string(i)}
+}
+
+// Duration returns the time between two scheduled exports
+func (i UserExportInterval) Duration() time.Duration {
+	if i == UserExportIntervalWeekly {
+		return time.Hour * 24 * 7
+	}
+	return time.Hour * 24
+}
+
+// UserExportSchedule holds the settings of a recurring data export of a user.
+type UserExportSchedule struct {
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"-"`
+	UserID int64 `xorm:"bigint not null unique" json:"-"`
+
+	// How often the export is created. Can be `daily` or `weekly`.
+	Interval UserExportInterval `xorm:"varchar(20) not null" json:"interval"`
+	// How many backups are kept. Older backups are removed once a new one was created.
+	RetentionCount int64 `xorm:"bigint not null default 7" json:"retention_count"`
+	// If true, the user gets an email for every created backup. Backups up to 10MB are attached to that email.
+	SendEmail bool `xorm:"not null default false" json:"send_email"`
+	// If set, every backup is uploaded to this WebDAV folder.
+	WebdavURL string `xorm:"text null" json:"webdav_url"`
+	// The username used to authenticate against the WebDAV server.
+	WebdavUsername string `xorm:"text null" json:"webdav_username"`
+	// The password used to authenticate against the WebDAV server. It is only used to set the password and never returned.
+	WebdavPassword string `xorm:"-" json:"webdav_password,omitempty"`
+	// Whether a password for the WebDAV server is saved. You cannot change this value.
+	HasWebdavPassword bool `xorm:"-" json:"has_webdav_password"`
+	// The WebDAV password, encrypted with the JWT secret of this instance.
+	EncryptedWebdavPassword string `xorm:"'webdav_password' text null" json:"-"`
+
+	// The last time an export was created. You cannot change this value.
+	LastRunAt time.Time `xorm:"null" json:"last_run_at"`
+	// The next time an export will be created. You cannot change this value.
+	NextRunAt time.Time `xorm:"not null INDEX" json:"next_run_at"`
+	// The error of the last run, if there was one. You cannot change this value.
+	LastError string `xorm:"text null" json:"last_error"`
+
+	// A timestamp when this schedule was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+	// A timestamp when this schedule was last updated. You cannot change this value.
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+}
+
+// TableName returns the table name for user export schedules
+func (*UserExportSchedule) TableName() string {
+	return "user_export_schedules"
+}
+
+// UserExportBackup is a data export which was created from a schedule.
+type UserExportBackup struct {
+	// The unique, numeric id of this backup.
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
+	FileID int64 `xorm:"bigint not null" json:"-"`
+	// The size of the backup in bytes.
+	Size uint64 `xorm:"bigint not null" json:"size"`
+
+	// A timestamp when this backup was created.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the table name for user export backups
+func (*UserExportBackup) TableName() string {
+	return "user_export_backups"
+}
+
+// GetUserExportSchedule returns the export schedule of a user.
+func GetUserExportSchedule(s *xorm.Session, u *user.User) (schedule *UserExportSchedule, err error) {
+	schedule = &UserExportSchedule{}
+	has, err := s.Where("user_id = ?", u.ID).Get(schedule)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, &ErrUserExportScheduleDoesNotExist{UserID: u.ID}
+	}
+	schedule.HasWebdavPassword = schedule.EncryptedWebdavPassword != ""
+	return
+}
+
+func (sch *UserExportSchedule) validate() error {
+	err := sch.Interval.Validate()
+	if err != nil {
+		return err
+	}
+
+	if sch.RetentionCount == 0 {
+		sch.RetentionCount = defaultUserExportRetention
+	}
+	if sch.RetentionCount < 1 || sch.RetentionCount > maxUserExportRetention {
+		return &ErrInvalidExportRetention{RetentionCount: sch.RetentionCount}
+	}
+
+	if sch.WebdavURL != "" {
+		u, err := url.Parse(sch.WebdavURL)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return &ErrInvalidExportWebDAVURL{URL: sch.WebdavURL}
+		}
+
+		// Host names are checked again when connecting, this only catches obvious cases early
+		ip := net.ParseIP(u.Hostname())
+		if !config.ExportAllowPrivateHosts.GetBool() &&
+			(strings.EqualFold(u.Hostname(), "localhost") || (ip != nil && !utils.IsPublicIP(ip))) {
+			return &ErrInvalidExportWebDAVURL{URL: sch.WebdavURL}
+		}
+	}
+
+	return nil
+}
+
+// setWebdavPassword encrypts the WebDAV password of a schedule. The existing one is kept if none was provided.
+func (sch *UserExportSchedule) setWebdavPassword(existing *UserExportSchedule) (err error) {
+	switch {
+	case sch.WebdavURL == "":
+		sch.EncryptedWebdavPassword = ""
+	case sch.WebdavPassword != "":
+		sch.EncryptedWebdavPassword, err = utils.EncryptString(config.ServiceJWTSecret.GetString(), sch.WebdavPassword)
+		if err != nil {
+			return err
+		}
+	case existing != nil:
+		sch.EncryptedWebdavPassword = existing.EncryptedWebdavPassword
+	}
+
+	sch.WebdavPassword = ""
+	sch.HasWebdavPassword = sch.EncryptedWebdavPassword != ""
+	return nil
+}
+
+// SaveUserExportSchedule creates or updates the export schedule of a user.
+// If no WebDAV password is provided, the existing one is kept.
+func SaveUserExportSchedule(s *xorm.Session, u *user.User, sch *UserExportSchedule) (err error) {
+	err = sch.validate()
+	if err != nil {
+		return err
+	}
+
+	existing := &UserExportSchedule{}
+	has, err := s.Where("user_id = ?", u.ID).Get(existing)
+	if err != nil {
+		return err
+	}
+
+	sch.UserID = u.ID
+	if !has {
+		err = sch.setWebdavPassword(nil)
+		if err != nil {
+			return err
+		}
+		sch.NextRunAt = time.Now()
+		_, err = s.Insert(sch)
+		return err
+	}
+
+	sch.ID = existing.ID
+	sch.LastRunAt = existing.LastRunAt
+	sch.LastError = existing.LastError
+	sch.NextRunAt = existing.NextRunAt
+	if sch.Interval != existing.Interval && !existing.LastRunAt.IsZero() {
+		sch.NextRunAt = existing.LastRunAt.Add(sch.Interval.Duration())
+	}
+	err = sch.setWebdavPassword(existing)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.
+		ID(sch.ID).
+		Cols(
+			"interval",
+			"retention_count",
+			"send_email",
+			"webdav_url",
+			"webdav_username",
+			"webdav_password",
+			"next_run_at",
+		).
+		Update(sch)
+	if err != nil {
+		return err
+	}
+
+	return pruneUserExportBackups(s, u.ID, sch.RetentionCount)
+}
+
+// DeleteUserExportSchedule removes the export schedule of a user. Existing backups are kept.
+func DeleteUserExportSchedule(s *xorm.Session, u *user.User) (err error) {
+	deleted, err := s.Where("user_id = ?", u.ID).Delete(&UserExportSchedule{})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ErrUserExportScheduleDoesNotExist{UserID: u.ID}
+	}
+	return nil
+}
+
+// GetUserExportBackups returns all backups of a user, newest first.
+func GetUserExportBackups(s *xorm.Session, u *user.User) (backups []*UserExportBackup, err error) {
+	backups = []*UserExportBackup{}
+	err = s.
+		Where("user_id = ?", u.ID).
+		OrderBy("created DESC, id DESC").
+		Find(&backups)
+	return
+}
+
+// GetUserExportBackupFile returns the file of a backup of a user.
+func GetUserExportBackupFile(s *xorm.Session, u *user.User, backupID int64) (f *files.File, err error) {
+	backup := &UserExportBackup{}
+	has, err := s.Where("id = ? AND user_id = ?", backupID, u.ID).Get(backup)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, &ErrUserExportBackupDoesNotExist{BackupID: backupID}
+	}
+
+	f = &files.File{ID: backup.FileID}
+	err = f.LoadFileMetaByID()
+	if err != nil {
+		return nil, err
+	}
+	err = f.LoadFileByID()
+	return
+}
+
+// pruneUserExportBackups removes all backups of a user except the newest keep ones.
+func pruneUserExportBackups(s *xorm.Session, userID int64, keep int64) (err error) {
+	backups := []*UserExportBackup{}
+	err = s.
+		Where("user_id = ?", userID).
+		OrderBy("created DESC, id DESC").
+		Find(&backups)
+	if err != nil {
+		return err
+	}
+
+	if int64(len(backups)) <= keep {
+		return nil
+	}
+
+	return deleteUserExportBackups(s, backups[keep:])
+}
+
+func deleteUserExportBackups(s *xorm.Session, backups []*UserExportBackup) (err error) {
+	ids := make([]int64, 0, len(backups))
+	for _, b := range backups {
+		f := &files.File{ID: b.FileID}
+		err = f.Delete()
+		if err != nil && !files.IsErrFileDoesNotExist(err) {
+			return err
+		}
+		ids = append(ids, b.ID)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err = s.In("id", ids).Delete(&UserExportBackup{})
+	return
+}
+
+// deleteUserExportData removes the export schedule and all backups of a user.
+func deleteUserExportData(s *xorm.Session, u *user.User) (err error) {
+	_, err = s.Where("user_id = ?", u.ID).Delete(&UserExportSchedule{})
+	if err != nil {
+		return err
+	}
+
+	return pruneUserExportBackups(s, u.ID, 0)
+}
+
+// RunUserExportSchedule creates a new backup for a schedule, removes old backups and sends the new one
+// to the configured destinations.
+// Errors while sending the backup are saved with the schedule instead of returned, the backup is kept in that case.
+func RunUserExportSchedule(s *xorm.Session, u *user.User, sch *UserExportSchedule) (backup *UserExportBackup, err error) {
+	exportFile, err := createUserDataExportFile(s, u)
+	if err != nil {
+		return nil, err
+	}
+
+	backup = &UserExportBackup{
+		UserID: u.ID,
+		FileID: exportFile.ID,
+		Size:   exportFile.Size,
+	}
+	_, err = s.Insert(backup)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pruneUserExportBackups(s, u.ID, sch.RetentionCount)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName := "vikunja-export-" + backup.Created.Format("2006-01-02") + ".zip"
+
+	errs := []string{}
+	if sch.WebdavURL != "" {
+		err = uploadUserExportToWebDAV(sch, exportFile, fileName)
+		if err != nil {
+			log.Errorf("Could not upload scheduled export of user %d to WebDAV: %s", u.ID, err)
+			errs = append(errs, "WebDAV upload failed: "+err.Error())
+		}
+	}
+
+	if sch.SendEmail {
+		err = notifyUserExportBackupCreated(u, backup, exportFile, fileName)
+		if err != nil {
+			log.Errorf("Could not send scheduled export of user %d: %s", u.ID, err)
+			errs = append(errs, "Sending email failed: "+err.Error())
+		}
+	}
+
+	now := time.Now()
+	sch.LastRunAt = now
+	sch.NextRunAt = now.Add(sch.Interval.Duration())
+	sch.LastError = strings.Join(errs, "\n")
+	_, err = s.
+		ID(sch.ID).
+		Cols("last_run_at", "next_run_at", "last_error").
+		Update(sch)
+	return backup, err
+}
+
+func uploadUserExportToWebDAV(sch *UserExportSchedule, exportFile *files.File, fileName string) (err error) {
+	err = exportFile.LoadFileByID()
+	if err != nil {
+		return err
+	}
+	defer exportFile.File.Close()
+
+	target := strings.TrimSuffix(sch.WebdavURL, "/") + "/" + url.PathEscape(fileName)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPut, target, exportFile.File)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(exportFile.Size)
+	req.Header.Set("Content-Type", "application/zip")
+	req.Header.Set("User-Agent", "Vikunja/"+version.Version)
+
+	var password string
+	if sch.EncryptedWebdavPassword != "" {
+		password, err = utils.DecryptString(config.ServiceJWTSecret.GetString(), sch.EncryptedWebdavPassword)
+		if err != nil {
+			return fmt.Errorf("could not decrypt the WebDAV password, please save it again: %w", err)
+		}
+	}
+	if sch.WebdavUsername != "" || password != "" {
+		req.SetBasicAuth(sch.WebdavUsername, password)
+	}
+
+	client := &http.Client{Timeout: 5 * time.Minute}
+	if !config.ExportAllowPrivateHosts.GetBool() {
+		client = utils.NewPublicHTTPClient(5 * time.Minute)
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode > 299 {
+		return fmt.Errorf("got unexpected status %d from WebDAV server", res.StatusCode)
+	}
+
+	return nil
+}
+
+func notifyUserExportBackupCreated(u *user.User, backup *UserExportBackup, exportFile *files.File, fileName string) (err error) {
+	n := &UserExportBackupCreatedNotification{
+		User:     u,
+		Backup:   backup,
+		FileName: fileName,
+	}
+
+	if exportFile.Size <= maxUserExportMailAttachmentSize {
+		err = exportFile.LoadFileByID()
+		if err != nil {
+			return err
+		}
+		defer exportFile.File.Close()
+
+		n.content, err = io.ReadAll(exportFile.File)
+		if err != nil {
+			return err
+		}
+	}
+
+	return notifications.Notify(u, n)
+}
+
+func runDueUserExportSchedule(sch *UserExportSchedule) (err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	err = s.Begin()
+	if err != nil {
+		return err
+	}
+
+	u, err := user.GetUserByID(s, sch.UserID)
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	if u.Status == user.StatusDisabled {
+		// Try again later, the user might be enabled by then
+		sch.NextRunAt = time.Now().Add(sch.Interval.Duration())
+		_, err = s.ID(sch.ID).Cols("next_run_at").Update(sch)
+		if err != nil {
+			_ = s.Rollback()
+			return err
+		}
+		return s.Commit()
+	}
+
+	_, err = RunUserExportSchedule(s, u, sch)
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	return s.Commit()
+}
+
+// RegisterUserExportScheduleCron creates all scheduled user data exports which are due.
+func RegisterUserExportScheduleCron() {
+	const logPrefix = "[User Export Schedule Cron] "
+
+	err := cron.Schedule("15 * * * *", func() {
+		s := db.NewSession()
+		schedules := []*UserExportSchedule{}
+		err := s.Where("next_run_at <= ?", time.Now()).Find(&schedules)
+		s.Close()
+		if err != nil {
+			log.Errorf(logPrefix+"Could not get due export schedules: %s", err)
+			return
+		}
+
+		if len(schedules) == 0 {
+			return
+		}
+
+		log.Debugf(logPrefix+"Creating %d scheduled exports...", len(schedules))
+
+		for _, sch := range schedules {
+			err = runDueUserExportSchedule(sch)
+			if err != nil {
+				log.Errorf(logPrefix+"Could not create scheduled export for user %d: %s", sch.UserID, err)
+			}
+		}
+
+		log.Debugf(logPrefix+"Done creating %d scheduled exports", len(schedules))
+	})
+	if err != nil {
+		log.Fatalf("Could not register user export schedule cron: %s", err)
+	}
+}
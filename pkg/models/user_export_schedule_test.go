@@ -0,0 +1,284 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package models
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveUserExportSchedule(t *testing.T) {
+	t.Run("create", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &user.User{ID: 1}
+		sch := &UserExportSchedule{
+			Interval:       UserExportIntervalDaily,
+			WebdavURL:      "https://dav.example.com/backups",
+			WebdavPassword: "secret",
+		}
+		err := SaveUserExportSchedule(s, u, sch)
+		require.NoError(t, err)
+		assert.Equal(t, int64(defaultUserExportRetention), sch.RetentionCount)
+		assert.Empty(t, sch.WebdavPassword)
+		assert.True(t, sch.HasWebdavPassword)
+		db.AssertExists(t, "user_export_schedules", map[string]interface{}{
+			"user_id":  1,
+			"interval": "daily",
+		}, false)
+		db.AssertMissing(t, "user_export_schedules", map[string]interface{}{
+			"webdav_password": "secret",
+		})
+
+		got, err := GetUserExportSchedule(s, u)
+		require.NoError(t, err)
+		assert.Equal(t, sch.ID, got.ID)
+		assert.Empty(t, got.WebdavPassword)
+		assert.True(t, got.HasWebdavPassword)
+		password, err := utils.DecryptString(config.ServiceJWTSecret.GetString(), got.EncryptedWebdavPassword)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", password)
+
+		data, err := json.Marshal(got)
+		require.NoError(t, err)
+		assert.NotContains(t, string(data), `"webdav_password"`)
+	})
+	t.Run("update keeps the password", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		u := &user.User{ID: 2}
+		err := SaveUserExportSchedule(s, u, &UserExportSchedule{
+			Interval:       UserExportIntervalDaily,
+			WebdavURL:      "https://dav.example.com/backups",
+			WebdavPassword: "secret",
+		})
+		require.NoError(t, err)
+
+		err = SaveUserExportSchedule(s, u, &UserExportSchedule{
+			Interval:       UserExportIntervalWeekly,
+			RetentionCount: 3,
+			WebdavURL:      "https://dav.example.com/backups",
+		})
+		require.NoError(t, err)
+		db.AssertExists(t, "user_export_schedules", map[string]interface{}{
+			"user_id":         2,
+			"interval":        "weekly",
+			"retention_count": 3,
+		}, false)
+
+		got, err := GetUserExportSchedule(s, u)
+		require.NoError(t, err)
+		password, err := utils.DecryptString(config.ServiceJWTSecret.GetString(), got.EncryptedWebdavPassword)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", password)
+	})
+	t.Run("invalid interval", func(t *testing.T) {
+		s := db.NewSession()
+		defer s.Close()
+
+		err := SaveUserExportSchedule(s, &user.User{ID: 1}, &UserExportSchedule{Interval: "hourly"})
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidExportInterval(err))
+	})
+	t.Run("invalid retention", func(t *testing.T) {
+		s := db.NewSession()
+		defer s.Close()
+
+		err := SaveUserExportSchedule(s, &user.User{ID: 1}, &UserExportSchedule{
+			Interval:       UserExportIntervalDaily,
+			RetentionCount: maxUserExportRetention + 1,
+		})
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidExportRetention(err))
+	})
+	t.Run("invalid webdav url", func(t *testing.T) {
+		s := db.NewSession()
+		defer s.Close()
+
+		err := SaveUserExportSchedule(s, &user.User{ID: 1}, &UserExportSchedule{
+			Interval:  UserExportIntervalDaily,
+			WebdavURL: "ftp://dav.example.com",
+		})
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidExportWebDAVURL(err))
+	})
+	t.Run("private webdav host", func(t *testing.T) {
+		s := db.NewSession()
+		defer s.Close()
+
+		for _, webdavURL := range []string{
+			"http://localhost/backups",
+			"http://127.0.0.1:8080",
+			"http://169.254.169.254/latest",
+			"https://[::1]/dav",
+		} {
+			err := SaveUserExportSchedule(s, &user.User{ID: 1}, &UserExportSchedule{
+				Interval:  UserExportIntervalDaily,
+				WebdavURL: webdavURL,
+			})
+			require.Error(t, err, webdavURL)
+			assert.True(t, IsErrInvalidExportWebDAVURL(err), webdavURL)
+		}
+	})
+}
+
+// allowPrivateExportHosts allows uploading backups to the local test servers.
+func allowPrivateExportHosts(t *testing.T) {
+	config.ExportAllowPrivateHosts.Set(true)
+	t.Cleanup(func() {
+		config.ExportAllowPrivateHosts.Set(false)
+	})
+}
+
+func TestRunUserExportSchedule(t *testing.T) {
+	t.Run("retention and webdav upload", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		var uploads []string
+		var uploaded []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, ok := r.BasicAuth()
+			if r.Method != http.MethodPut || !ok || username != "user" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			uploads = append(uploads, r.URL.Path)
+			uploaded, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer server.Close()
+
+		allowPrivateExportHosts(t)
+		u, err := user.GetUserByID(s, 3)
+		require.NoError(t, err)
+		sch := &UserExportSchedule{
+			Interval:       UserExportIntervalDaily,
+			RetentionCount: 2,
+			WebdavURL:      server.URL + "/backups/",
+			WebdavUsername: "user",
+			WebdavPassword: "secret",
+		}
+		err = SaveUserExportSchedule(s, u, sch)
+		require.NoError(t, err)
+
+		var backups []*UserExportBackup
+		for i := 0; i < 3; i++ {
+			backup, err := RunUserExportSchedule(s, u, sch)
+			require.NoError(t, err)
+			backups = append(backups, backup)
+		}
+
+		assert.Empty(t, sch.LastError)
+		assert.False(t, sch.LastRunAt.IsZero())
+		assert.True(t, sch.NextRunAt.After(sch.LastRunAt))
+		assert.Len(t, uploads, 3)
+		assert.Regexp(t, `^/backups/vikunja-export-\d{4}-\d{2}-\d{2}\.zip$`, uploads[0])
+		assert.Equal(t, "PK", string(uploaded[:2]))
+
+		remaining, err := GetUserExportBackups(s, u)
+		require.NoError(t, err)
+		assert.Len(t, remaining, 2)
+		db.AssertMissing(t, "user_export_backups", map[string]interface{}{"id": backups[0].ID})
+		db.AssertMissing(t, "files", map[string]interface{}{"id": backups[0].FileID})
+
+		f, err := GetUserExportBackupFile(s, u, backups[2].ID)
+		require.NoError(t, err)
+		assert.Equal(t, backups[2].FileID, f.ID)
+
+		_, err = GetUserExportBackupFile(s, &user.User{ID: 1}, backups[2].ID)
+		require.Error(t, err)
+		assert.True(t, IsErrUserExportBackupDoesNotExist(err))
+	})
+	t.Run("failed upload is saved with the schedule", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		notifications.Fake()
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		defer server.Close()
+
+		allowPrivateExportHosts(t)
+		u, err := user.GetUserByID(s, 4)
+		require.NoError(t, err)
+		sch := &UserExportSchedule{
+			Interval:  UserExportIntervalWeekly,
+			WebdavURL: server.URL,
+			SendEmail: true,
+		}
+		err = SaveUserExportSchedule(s, u, sch)
+		require.NoError(t, err)
+
+		backup, err := RunUserExportSchedule(s, u, sch)
+		require.NoError(t, err)
+		assert.Contains(t, sch.LastError, "403")
+		db.AssertExists(t, "user_export_backups", map[string]interface{}{"id": backup.ID}, false)
+		db.AssertExists(t, "user_export_schedules", map[string]interface{}{
+			"id":         sch.ID,
+			"last_error": sch.LastError,
+		}, false)
+		notifications.AssertSent(t, &UserExportBackupCreatedNotification{})
+	})
+	t.Run("upload to a host resolving to a private address", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		uploaded := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			uploaded = true
+		}))
+		defer server.Close()
+
+		u, err := user.GetUserByID(s, 6)
+		require.NoError(t, err)
+		// Saved directly, like a host name which only resolves to a private address after it was validated
+		sch := &UserExportSchedule{
+			UserID:         u.ID,
+			Interval:       UserExportIntervalDaily,
+			RetentionCount: 1,
+			WebdavURL:      server.URL,
+		}
+		_, err = s.Insert(sch)
+		require.NoError(t, err)
+
+		_, err = RunUserExportSchedule(s, u, sch)
+		require.NoError(t, err)
+		assert.False(t, uploaded)
+		assert.Contains(t, sch.LastError, utils.ErrNonPublicAddress.Error())
+	})
+}
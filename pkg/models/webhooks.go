@@ -35,6 +35,7 @@ import (
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/events"
 	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/metrics"
 	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/api/pkg/version"
 	"code.vikunja.io/web"
@@ -289,13 +290,17 @@ func (w *Webhook) sendWebhookPayload(p *WebhookPayload) (err error) {
 	req.Header.Add("Content-Type", "application/json")
 
 	client := getWebhookHTTPClient()
+	start := time.Now()
 	res, err := client.Do(req)
 	if err != nil {
+		metrics.ObserveWebhookDelivery(p.EventName, time.Since(start), true)
 		return err
 	}
 
 	defer res.Body.Close()
 
+	metrics.ObserveWebhookDelivery(p.EventName, time.Since(start), res.StatusCode > 399)
+
 	if res.StatusCode > 399 {
 		responseBody, err := io.ReadAll(res.Body)
 		if err != nil {
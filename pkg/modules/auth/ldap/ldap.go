@@ -0,0 +1,336 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package ldap
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+
+	petname "github.com/dustinkirkland/golang-petname"
+	"github.com/go-ldap/ldap/v3"
+	"xorm.io/xorm"
+)
+
+// entry holds everything about a user Vikunja needs from the directory.
+type entry struct {
+	dn       string
+	username string
+	email    string
+	name     string
+}
+
+func connect() (*ldap.Conn, error) {
+	host := config.AuthLDAPHost.GetString()
+	scheme := "ldap"
+	if config.AuthLDAPUseTLS.GetBool() {
+		scheme = "ldaps"
+	}
+
+	url := scheme + "://" + net.JoinHostPort(host, strconv.Itoa(config.AuthLDAPPort.GetInt()))
+	return ldap.DialURL(url, ldap.DialWithTLSConfig(&tls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: !config.AuthLDAPVerifyTLS.GetBool(),
+	}))
+}
+
+// bindServiceAccount binds with the configured service account to search the directory.
+// If no service account is configured, searches are made anonymously.
+func bindServiceAccount(conn *ldap.Conn) error {
+	bindDN := config.AuthLDAPBindDN.GetString()
+	if bindDN == "" {
+		return nil
+	}
+	return conn.Bind(bindDN, config.AuthLDAPBindPassword.GetString())
+}
+
+func isInvalidCredentials(err error) bool {
+	return ldap.IsErrorWithCode(err, ldap.LDAPResultInvalidCredentials)
+}
+
+func findUser(conn *ldap.Conn, username string) (*entry, error) {
+	usernameAttribute := config.AuthLDAPAttributeUsername.GetString()
+	emailAttribute := config.AuthLDAPAttributeEmail.GetString()
+	nameAttribute := config.AuthLDAPAttributeDisplayName.GetString()
+
+	result, err := conn.Search(ldap.NewSearchRequest(
+		config.AuthLDAPBaseDN.GetString(),
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		2, // Only one user should match, a second one means the filter is ambiguous
+		0,
+		false,
+		fmt.Sprintf(config.AuthLDAPUserFilter.GetString(), ldap.EscapeFilter(username)),
+		[]string{usernameAttribute, emailAttribute, nameAttribute},
+		nil,
+	))
+	if err != nil && !ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+		return nil, err
+	}
+	if result == nil || len(result.Entries) == 0 {
+		return nil, nil
+	}
+	if len(result.Entries) > 1 {
+		log.Warningf("LDAP: More than one user matches the username %s, please check the user filter", username)
+		return nil, nil
+	}
+
+	e := result.Entries[0]
+	return &entry{
+		dn:       e.DN,
+		username: e.GetAttributeValue(usernameAttribute),
+		email:    e.GetAttributeValue(emailAttribute),
+		name:     e.GetAttributeValue(nameAttribute),
+	}, nil
+}
+
+func findGroups(conn *ldap.Conn, userDN string) (teamData []*models.OIDCTeam, err error) {
+	result, err := conn.Search(ldap.NewSearchRequest(
+		config.AuthLDAPBaseDN.GetString(),
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0,
+		0,
+		false,
+		fmt.Sprintf(config.AuthLDAPGroupFilter.GetString(), ldap.EscapeFilter(userDN)),
+		[]string{"cn", "description"},
+		nil,
+	))
+	if err != nil {
+		if ldap.IsErrorWithCode(err, ldap.LDAPResultNoSuchObject) {
+			return []*models.OIDCTeam{}, nil
+		}
+		return nil, err
+	}
+
+	teamData = make([]*models.OIDCTeam, 0, len(result.Entries))
+	for _, group := range result.Entries {
+		name := group.GetAttributeValue("cn")
+		if name == "" {
+			log.Warningf("LDAP: Group %s does not have a cn, not creating a team for it", group.DN)
+			continue
+		}
+		teamData = append(teamData, &models.OIDCTeam{
+			Name:        name,
+			OidcID:      group.DN,
+			Description: group.GetAttributeValue("description"),
+		})
+	}
+
+	return teamData, nil
+}
+
+// AuthenticateUserInLDAP checks the username and password of a user against the ldap directory by binding
+// with them. If they are valid, the Vikunja user for the directory entry is returned. It is created if the
+// user logs in for the first time, otherwise their email and name are updated from the directory.
+// If group sync is enabled, the user is added to a team for each of their groups.
+func AuthenticateUserInLDAP(s *xorm.Session, username, password string) (u *user.User, err error) {
+	if username == "" || password == "" {
+		return nil, user.ErrNoUsernamePassword{}
+	}
+
+	conn, err := connect()
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to the ldap server: %w", err)
+	}
+	defer conn.Close()
+
+	err = bindServiceAccount(conn)
+	if err != nil {
+		return nil, fmt.Errorf("could not bind with the ldap service account: %w", err)
+	}
+
+	e, err := findUser(conn, username)
+	if err != nil {
+		return nil, fmt.Errorf("could not search for the user in ldap: %w", err)
+	}
+	if e == nil {
+		return nil, user.ErrWrongUsernameOrPassword{}
+	}
+
+	err = conn.Bind(e.dn, password)
+	if err != nil {
+		if isInvalidCredentials(err) {
+			return nil, user.ErrWrongUsernameOrPassword{}
+		}
+		return nil, fmt.Errorf("could not bind as the user in ldap: %w", err)
+	}
+
+	u, err = getOrCreateUser(s, e)
+	if err != nil {
+		return nil, err
+	}
+
+	if !config.AuthLDAPGroupSyncEnabled.GetBool() {
+		return u, nil
+	}
+
+	// The user might not be allowed to search for groups
+	err = bindServiceAccount(conn)
+	if err != nil {
+		return nil, fmt.Errorf("could not bind with the ldap service account: %w", err)
+	}
+
+	teamData, err := findGroups(conn, e.dn)
+	if err != nil {
+		return nil, fmt.Errorf("could not search for the groups of the user in ldap: %w", err)
+	}
+
+	return u, syncTeams(s, u, teamData)
+}
+
+// CheckUserPassword checks the password of a user who logs in through the ldap directory by binding as them.
+func CheckUserPassword(u *user.User, password string) error {
+	if password == "" {
+		return user.ErrWrongUsernameOrPassword{}
+	}
+
+	conn, err := connect()
+	if err != nil {
+		return fmt.Errorf("could not connect to the ldap server: %w", err)
+	}
+	defer conn.Close()
+
+	err = conn.Bind(u.Subject, password)
+	if err != nil {
+		if isInvalidCredentials(err) {
+			return user.ErrWrongUsernameOrPassword{}
+		}
+		return fmt.Errorf("could not bind as the user in ldap: %w", err)
+	}
+
+	return nil
+}
+
+func getOrCreateUser(s *xorm.Session, e *entry) (u *user.User, err error) {
+	u, err = user.GetUserWithEmail(s, &user.User{
+		Issuer:  user.IssuerLDAP,
+		Subject: e.dn,
+	})
+	if err != nil && !user.IsErrUserDoesNotExist(err) {
+		return nil, err
+	}
+
+	if err == nil {
+		if e.email == u.Email && e.name == u.Name {
+			return u, nil
+		}
+
+		u.Email = e.email
+		u.Name = e.name
+		return user.UpdateUser(s, u, false)
+	}
+
+	newUser := &user.User{
+		Username: strings.ReplaceAll(e.username, " ", "-"),
+		Email:    e.email,
+		Name:     e.name,
+		Status:   user.StatusActive,
+		Issuer:   user.IssuerLDAP,
+		Subject:  e.dn,
+	}
+	if newUser.Username == "" {
+		newUser.Username = petname.Generate(3, "-")
+	}
+
+	u, err = user.CreateUser(s, newUser)
+	if user.IsErrUsernameExists(err) {
+		// The username is already taken by a local user or a user from another provider
+		newUser.Username = petname.Generate(3, "-")
+		u, err = user.CreateUser(s, newUser)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = models.CreateNewProjectForUser(s, u)
+	return u, err
+}
+
+func getTeamName(name string) string {
+	return name + " (LDAP)"
+}
+
+// syncTeams makes sure the user is a member of exactly the teams of their ldap groups.
+// Teams are matched by the dn of their group and created if they don't exist yet.
+func syncTeams(s *xorm.Session, u *user.User, teamData []*models.OIDCTeam) (err error) {
+	oldTeamIDs, err := models.FindAllTeamIDsForUserByIssuer(s, u.ID, user.IssuerLDAP)
+	if err != nil {
+		return err
+	}
+
+	teamIDs := make([]int64, 0, len(teamData))
+	for _, data := range teamData {
+		team, err := models.GetTeamByOidcIDAndIssuer(s, data.OidcID, user.IssuerLDAP)
+		if err != nil && !models.IsErrOIDCTeamDoesNotExist(err) {
+			return err
+		}
+
+		if err != nil {
+			team = &models.Team{
+				Name:        getTeamName(data.Name),
+				Description: data.Description,
+				OidcID:      data.OidcID,
+				Issuer:      user.IssuerLDAP,
+			}
+			err = team.CreateNewTeam(s, u, false)
+			if err != nil {
+				return err
+			}
+		} else if team.Name != getTeamName(data.Name) || team.Description != data.Description {
+			team.Name = getTeamName(data.Name)
+			team.Description = data.Description
+			err = team.Update(s, u)
+			if err != nil {
+				return err
+			}
+		}
+
+		member := &models.TeamMember{TeamID: team.ID, UserID: u.ID, Username: u.Username}
+		exists, err := member.MembershipExists(s)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			err = member.Create(s, u)
+			if err != nil {
+				return err
+			}
+		}
+
+		teamIDs = append(teamIDs, team.ID)
+	}
+
+	teamIDsToLeave := utils.NotIn(oldTeamIDs, teamIDs)
+	if len(teamIDsToLeave) == 0 {
+		return nil
+	}
+
+	_, err = s.
+		In("team_id", teamIDsToLeave).
+		And("user_id = ?", u.ID).
+		Delete(&models.TeamMember{})
+	return err
+}
@@ -0,0 +1,259 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package ldap
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testUserDN = "uid=jdoe,ou=users,dc=example,dc=com"
+
+func addTestUser(srv *testServer, username, email string) *testEntry {
+	e := &testEntry{
+		dn:       testUserDN,
+		password: "secret",
+		attributes: map[string][]string{
+			"uid":         {username},
+			"mail":        {email},
+			"displayName": {"John Doe"},
+		},
+	}
+	srv.addEntry(e)
+	srv.addSearchResult("(&(objectclass=inetOrgPerson)(uid="+username+"))", e)
+	return e
+}
+
+func TestAuthenticateUserInLDAP(t *testing.T) {
+	t.Run("new user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		srv := newTestServer(t)
+		addTestUser(srv, "jdoe", "jdoe@example.com")
+
+		u, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		assert.Equal(t, "jdoe", u.Username)
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":       u.ID,
+			"username": "jdoe",
+			"email":    "jdoe@example.com",
+			"name":     "John Doe",
+			"issuer":   user.IssuerLDAP,
+			"subject":  testUserDN,
+		}, false)
+		db.AssertExists(t, "projects", map[string]interface{}{
+			"owner_id": u.ID,
+		}, false)
+	})
+	t.Run("existing user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		srv := newTestServer(t)
+		addTestUser(srv, "jdoe", "jdoe@example.com")
+
+		first, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		require.NoError(t, err)
+
+		addTestUser(srv, "jdoe", "john@example.com")
+		u, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		assert.Equal(t, first.ID, u.ID)
+		db.AssertExists(t, "users", map[string]interface{}{
+			"id":    u.ID,
+			"email": "john@example.com",
+		}, false)
+	})
+	t.Run("username already taken", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		srv := newTestServer(t)
+		addTestUser(srv, "user1", "jdoe@example.com")
+
+		u, err := AuthenticateUserInLDAP(s, "user1", "secret")
+		require.NoError(t, err)
+		assert.NotEqual(t, "user1", u.Username)
+		assert.NotEqual(t, int64(1), u.ID)
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		srv := newTestServer(t)
+		addTestUser(srv, "jdoe", "jdoe@example.com")
+
+		_, err := AuthenticateUserInLDAP(s, "jdoe", "wrong")
+		require.Error(t, err)
+		assert.True(t, user.IsErrWrongUsernameOrPassword(err))
+	})
+	t.Run("unknown user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		newTestServer(t)
+
+		_, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		require.Error(t, err)
+		assert.True(t, user.IsErrWrongUsernameOrPassword(err))
+	})
+	t.Run("no password", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		srv := newTestServer(t)
+		addTestUser(srv, "jdoe", "jdoe@example.com")
+
+		_, err := AuthenticateUserInLDAP(s, "jdoe", "")
+		require.Error(t, err)
+		assert.True(t, user.IsErrNoUsernamePassword(err))
+	})
+	t.Run("wrong service account password", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		srv := newTestServer(t)
+		addTestUser(srv, "jdoe", "jdoe@example.com")
+		config.AuthLDAPBindPassword.Set("wrong")
+
+		_, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		require.Error(t, err)
+		assert.False(t, user.IsErrWrongUsernameOrPassword(err))
+	})
+}
+
+func TestCheckUserPassword(t *testing.T) {
+	u := &user.User{ID: 1, Issuer: user.IssuerLDAP, Subject: testUserDN}
+
+	t.Run("valid password", func(t *testing.T) {
+		srv := newTestServer(t)
+		addTestUser(srv, "jdoe", "jdoe@example.com")
+
+		err := CheckUserPassword(u, "secret")
+		require.NoError(t, err)
+	})
+	t.Run("wrong password", func(t *testing.T) {
+		srv := newTestServer(t)
+		addTestUser(srv, "jdoe", "jdoe@example.com")
+
+		err := CheckUserPassword(u, "wrong")
+		require.Error(t, err)
+		assert.True(t, user.IsErrWrongUsernameOrPassword(err))
+	})
+	t.Run("empty password", func(t *testing.T) {
+		srv := newTestServer(t)
+		addTestUser(srv, "jdoe", "jdoe@example.com")
+
+		err := CheckUserPassword(u, "")
+		require.Error(t, err)
+		assert.True(t, user.IsErrWrongUsernameOrPassword(err))
+	})
+}
+
+func TestLDAPGroupSync(t *testing.T) {
+	groupFilter := "(&(objectclass=groupOfNames)(member=" + testUserDN + "))"
+	developers := &testEntry{
+		dn: "cn=developers,ou=groups,dc=example,dc=com",
+		attributes: map[string][]string{
+			"cn":          {"developers"},
+			"description": {"All developers"},
+		},
+	}
+	admins := &testEntry{
+		dn: "cn=admins,ou=groups,dc=example,dc=com",
+		attributes: map[string][]string{
+			"cn": {"admins"},
+		},
+	}
+
+	t.Run("creates teams for groups", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		srv := newTestServer(t)
+		config.AuthLDAPGroupSyncEnabled.Set(true)
+		addTestUser(srv, "jdoe", "jdoe@example.com")
+		srv.addSearchResult(groupFilter, developers, admins)
+
+		u, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		db.AssertExists(t, "teams", map[string]interface{}{
+			"name":        "developers (LDAP)",
+			"description": "All developers",
+			"oidc_id":     developers.dn,
+			"issuer":      user.IssuerLDAP,
+		}, false)
+		db.AssertExists(t, "teams", map[string]interface{}{
+			"name":    "admins (LDAP)",
+			"oidc_id": admins.dn,
+			"issuer":  user.IssuerLDAP,
+		}, false)
+
+		teamIDs, err := models.FindAllTeamIDsForUserByIssuer(s, u.ID, user.IssuerLDAP)
+		require.NoError(t, err)
+		assert.Len(t, teamIDs, 2)
+	})
+	t.Run("removes user from teams of groups they left", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+		srv := newTestServer(t)
+		config.AuthLDAPGroupSyncEnabled.Set(true)
+		addTestUser(srv, "jdoe", "jdoe@example.com")
+		srv.addSearchResult(groupFilter, developers, admins)
+
+		_, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		require.NoError(t, err)
+
+		srv.addSearchResult(groupFilter, developers)
+		u, err := AuthenticateUserInLDAP(s, "jdoe", "secret")
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+
+		team, err := models.GetTeamByOidcIDAndIssuer(s, admins.dn, user.IssuerLDAP)
+		require.NoError(t, err)
+		db.AssertMissing(t, "team_members", map[string]interface{}{
+			"team_id": team.ID,
+			"user_id": u.ID,
+		})
+		team, err = models.GetTeamByOidcIDAndIssuer(s, developers.dn, user.IssuerLDAP)
+		require.NoError(t, err)
+		db.AssertExists(t, "team_members", map[string]interface{}{
+			"team_id": team.ID,
+			"user_id": u.ID,
+		}, false)
+	})
+}
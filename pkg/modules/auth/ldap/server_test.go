@@ -0,0 +1,203 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package ldap
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"code.vikunja.io/api/pkg/config"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testBaseDN          = "dc=example,dc=com"
+	testServiceDN       = "cn=vikunja,dc=example,dc=com"
+	testServicePassword = "service"
+)
+
+type testEntry struct {
+	dn         string
+	password   string
+	attributes map[string][]string
+}
+
+// testServer is a minimal ldap server which only understands what Vikunja needs: simple binds and searches.
+// Searches are answered by comparing the filter to the filters entries were registered for.
+type testServer struct {
+	listener net.Listener
+
+	sync.Mutex
+	entries  map[string]*testEntry
+	searches map[string][]*testEntry
+}
+
+// newTestServer starts a test server and configures Vikunja to use it.
+func newTestServer(t *testing.T) *testServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &testServer{
+		listener: listener,
+		entries:  map[string]*testEntry{},
+		searches: map[string][]*testEntry{},
+	}
+	srv.addEntry(&testEntry{dn: testServiceDN, password: testServicePassword})
+	go srv.serve()
+	t.Cleanup(func() {
+		_ = listener.Close()
+	})
+
+	previous := map[config.Key]interface{}{}
+	for key, value := range map[config.Key]interface{}{
+		config.AuthLDAPEnabled:          true,
+		config.AuthLDAPHost:             "127.0.0.1",
+		config.AuthLDAPPort:             listener.Addr().(*net.TCPAddr).Port,
+		config.AuthLDAPUseTLS:           false,
+		config.AuthLDAPBaseDN:           testBaseDN,
+		config.AuthLDAPBindDN:           testServiceDN,
+		config.AuthLDAPBindPassword:     testServicePassword,
+		config.AuthLDAPGroupSyncEnabled: false,
+	} {
+		previous[key] = key.Get()
+		key.Set(value)
+	}
+	t.Cleanup(func() {
+		for key, value := range previous {
+			key.Set(value)
+		}
+	})
+
+	return srv
+}
+
+func (srv *testServer) addEntry(e *testEntry) {
+	srv.Lock()
+	defer srv.Unlock()
+	srv.entries[e.dn] = e
+}
+
+func (srv *testServer) addSearchResult(filter string, entries ...*testEntry) {
+	srv.Lock()
+	defer srv.Unlock()
+	srv.searches[filter] = entries
+}
+
+func (srv *testServer) serve() {
+	for {
+		conn, err := srv.listener.Accept()
+		if err != nil {
+			return
+		}
+		go srv.handle(conn)
+	}
+}
+
+func (srv *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		request, err := ber.ReadPacket(conn)
+		if err != nil || len(request.Children) < 2 {
+			return
+		}
+
+		messageID := request.Children[0].Value.(int64)
+		op := request.Children[1]
+
+		var responses []*ber.Packet
+		switch op.Tag {
+		case ldap.ApplicationBindRequest:
+			responses = []*ber.Packet{srv.bind(op)}
+		case ldap.ApplicationSearchRequest:
+			responses, err = srv.search(op)
+			if err != nil {
+				return
+			}
+		case ldap.ApplicationUnbindRequest:
+			return
+		default:
+			return
+		}
+
+		for _, response := range responses {
+			envelope := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, "MessageID"))
+			envelope.AppendChild(response)
+			if _, err := conn.Write(envelope.Bytes()); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func newResult(tag ber.Tag, code uint16) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Result")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, int64(code), "Result Code"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+	return result
+}
+
+func (srv *testServer) bind(op *ber.Packet) *ber.Packet {
+	dn := op.Children[1].Value.(string)
+	password := op.Children[2].Data.String()
+
+	srv.Lock()
+	defer srv.Unlock()
+
+	e, exists := srv.entries[dn]
+	if !exists || password == "" || e.password != password {
+		return newResult(ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials)
+	}
+	return newResult(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess)
+}
+
+func (srv *testServer) search(op *ber.Packet) ([]*ber.Packet, error) {
+	filter, err := ldap.DecompileFilter(op.Children[6])
+	if err != nil {
+		return nil, err
+	}
+
+	srv.Lock()
+	defer srv.Unlock()
+
+	responses := []*ber.Packet{}
+	for _, e := range srv.searches[filter] {
+		result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+		result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, e.dn, "DN"))
+		attributes := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes")
+		for name, values := range e.attributes {
+			attribute := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attribute")
+			attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, "Type"))
+			set := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "Values")
+			for _, value := range values {
+				set.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, "Value"))
+			}
+			attribute.AppendChild(set)
+			attributes.AppendChild(attribute)
+		}
+		result.AppendChild(attributes)
+		responses = append(responses, result)
+	}
+
+	return append(responses, newResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess)), nil
+}
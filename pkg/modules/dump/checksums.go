@@ -0,0 +1,213 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dump
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+	"strings"
+)
+
+const checksumsFileName = "checksums.json"
+
+// Manifest holds the checksums of all tables and files in a dump.
+type Manifest struct {
+	// The Vikunja version the dump was created with.
+	Version string `json:"version"`
+	// The database type the dump was created from.
+	DatabaseType string                    `json:"database_type"`
+	Tables       map[string]*ManifestEntry `json:"tables"`
+	Files        map[string]*ManifestEntry `json:"files"`
+}
+
+// ManifestEntry holds the checksum of a single table or file in a dump.
+type ManifestEntry struct {
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+	// The number of rows, only set for tables.
+	Rows int64 `json:"rows,omitempty"`
+}
+
+type checksumWriter struct {
+	hash hash.Hash
+	size int64
+}
+
+func newChecksumWriter() *checksumWriter {
+	return &checksumWriter{hash: sha256.New()}
+}
+
+func (c *checksumWriter) Write(p []byte) (n int, err error) {
+	n, err = c.hash.Write(p)
+	c.size += int64(n)
+	return
+}
+
+func (c *checksumWriter) entry() *ManifestEntry {
+	return &ManifestEntry{
+		SHA256: hex.EncodeToString(c.hash.Sum(nil)),
+		Size:   c.size,
+	}
+}
+
+func readManifest(file *zip.File) (manifest *Manifest, err error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("could not open checksums: %w", err)
+	}
+	defer rc.Close()
+
+	manifest = &Manifest{}
+	err = json.NewDecoder(rc).Decode(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("could not read checksums: %w", err)
+	}
+	return
+}
+
+func checksumZipFile(file *zip.File) (*ManifestEntry, error) {
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	cw := newChecksumWriter()
+	_, err = io.Copy(cw, rc)
+	if err != nil {
+		return nil, err
+	}
+	return cw.entry(), nil
+}
+
+func verifyEntries(kind string, expected map[string]*ManifestEntry, actual map[string]*zip.File) (errs []error) {
+	names := make([]string, 0, len(expected))
+	for name := range expected {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		file, has := actual[name]
+		if !has {
+			errs = append(errs, fmt.Errorf("%s %s is missing", kind, name))
+			continue
+		}
+
+		got, err := checksumZipFile(file)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("could not read %s %s: %w", kind, name, err))
+			continue
+		}
+		if got.SHA256 != expected[name].SHA256 || got.Size != expected[name].Size {
+			errs = append(errs, fmt.Errorf("checksum of %s %s does not match", kind, name))
+		}
+	}
+
+	for name := range actual {
+		if _, has := expected[name]; !has {
+			errs = append(errs, fmt.Errorf("%s %s has no checksum", kind, name))
+		}
+	}
+
+	return
+}
+
+// Verify checks all tables and files in a dump against the checksums saved when it was created.
+func Verify(filename string) error {
+	r, err := zip.OpenReader(filename)
+	if err != nil {
+		return fmt.Errorf("could not open zip file: %w", err)
+	}
+	defer r.Close()
+
+	return verifyDump(readDumpContents(&r.Reader))
+}
+
+func verifyDump(contents *dumpContents) error {
+	if contents.checksumsFile == nil {
+		return fmt.Errorf("dump does not contain checksums, it was created with an older version of Vikunja")
+	}
+
+	manifest, err := readManifest(contents.checksumsFile)
+	if err != nil {
+		return err
+	}
+
+	errs := verifyEntries("table", manifest.Tables, contents.dbfiles)
+	errs = append(errs, verifyEntries("file", manifest.Files, contents.filesFiles)...)
+	if len(errs) > 0 {
+		return fmt.Errorf("dump is corrupt: %w", errors.Join(errs...))
+	}
+
+	return nil
+}
+
+// dumpContents holds all relevant files of a dump
+type dumpContents struct {
+	configFile    *zip.File
+	dotEnvFile    *zip.File
+	versionFile   *zip.File
+	checksumsFile *zip.File
+	// The table files, without the .json suffix
+	dbfiles map[string]*zip.File
+	// The stored files, by their id
+	filesFiles map[string]*zip.File
+}
+
+func readDumpContents(r *zip.Reader) *dumpContents {
+	contents := &dumpContents{
+		dbfiles:    make(map[string]*zip.File),
+		filesFiles: make(map[string]*zip.File),
+	}
+
+	for _, file := range r.File {
+		if strings.HasPrefix(file.Name, "config") {
+			contents.configFile = file
+			continue
+		}
+		if strings.HasPrefix(file.Name, "database/") {
+			fname := strings.ReplaceAll(file.Name, "database/", "")
+			contents.dbfiles[fname[:len(fname)-5]] = file
+			continue
+		}
+		if file.Name == ".env" {
+			contents.dotEnvFile = file
+			continue
+		}
+		if strings.HasPrefix(file.Name, "files/") {
+			contents.filesFiles[strings.ReplaceAll(file.Name, "files/", "")] = file
+			continue
+		}
+		if file.Name == "VERSION" {
+			contents.versionFile = file
+			continue
+		}
+		if file.Name == checksumsFileName {
+			contents.checksumsFile = file
+		}
+	}
+
+	return contents
+}
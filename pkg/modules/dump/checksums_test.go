@@ -0,0 +1,175 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dump
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestDump(t *testing.T, entries map[string]string, manifest *Manifest) string {
+	filename := filepath.Join(t.TempDir(), "dump.zip")
+	f, err := os.Create(filename)
+	require.NoError(t, err)
+	defer f.Close()
+
+	wr := zip.NewWriter(f)
+	for name, content := range entries {
+		w, err := wr.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	if manifest != nil {
+		w, err := wr.Create(checksumsFileName)
+		require.NoError(t, err)
+		require.NoError(t, json.NewEncoder(w).Encode(manifest))
+	}
+	require.NoError(t, wr.Close())
+
+	return filename
+}
+
+func checksumOf(content string) *ManifestEntry {
+	cw := newChecksumWriter()
+	_, _ = cw.Write([]byte(content))
+	return cw.entry()
+}
+
+func TestVerify(t *testing.T) {
+	tasks := `[{"id":1,"title":"Task"}]`
+	file := "file content"
+
+	t.Run("valid", func(t *testing.T) {
+		filename := writeTestDump(t, map[string]string{
+			"database/tasks.json": tasks,
+			"files/1":             file,
+		}, &Manifest{
+			Tables: map[string]*ManifestEntry{"tasks": checksumOf(tasks)},
+			Files:  map[string]*ManifestEntry{"1": checksumOf(file)},
+		})
+
+		require.NoError(t, Verify(filename))
+	})
+	t.Run("changed file", func(t *testing.T) {
+		filename := writeTestDump(t, map[string]string{
+			"database/tasks.json": tasks,
+			"files/1":             "other content",
+		}, &Manifest{
+			Tables: map[string]*ManifestEntry{"tasks": checksumOf(tasks)},
+			Files:  map[string]*ManifestEntry{"1": checksumOf(file)},
+		})
+
+		err := Verify(filename)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum of file 1 does not match")
+	})
+	t.Run("missing table", func(t *testing.T) {
+		filename := writeTestDump(t, map[string]string{
+			"files/1": file,
+		}, &Manifest{
+			Tables: map[string]*ManifestEntry{"tasks": checksumOf(tasks)},
+			Files:  map[string]*ManifestEntry{"1": checksumOf(file)},
+		})
+
+		err := Verify(filename)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "table tasks is missing")
+	})
+	t.Run("no checksums", func(t *testing.T) {
+		filename := writeTestDump(t, map[string]string{
+			"database/tasks.json": tasks,
+		}, nil)
+
+		err := Verify(filename)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not contain checksums")
+	})
+}
+
+func TestReadTableRows(t *testing.T) {
+	openTable := func(t *testing.T, content string) *zip.File {
+		filename := writeTestDump(t, map[string]string{"database/tasks.json": content}, nil)
+		r, err := zip.OpenReader(filename)
+		require.NoError(t, err)
+		t.Cleanup(func() { r.Close() })
+		return r.File[0]
+	}
+
+	rows := make([]map[string]interface{}, restoreBatchSize*2+1)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"id": i + 1}
+	}
+	content, err := json.Marshal(rows)
+	require.NoError(t, err)
+
+	t.Run("batches", func(t *testing.T) {
+		batches := []int{}
+		count, err := readTableRows(openTable(t, string(content)), func(batch []map[string]interface{}) error {
+			batches = append(batches, len(batch))
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(len(rows)), count)
+		assert.Equal(t, []int{restoreBatchSize, restoreBatchSize, 1}, batches)
+	})
+	t.Run("numbers are kept as json numbers", func(t *testing.T) {
+		var value interface{}
+		_, err := readTableRows(openTable(t, `[{"id":9007199254740993}]`), func(batch []map[string]interface{}) error {
+			value = batch[0]["id"]
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, json.Number("9007199254740993"), value)
+	})
+	t.Run("empty table", func(t *testing.T) {
+		called := false
+		count, err := readTableRows(openTable(t, `[]`), func([]map[string]interface{}) error {
+			called = true
+			return nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), count)
+		assert.False(t, called)
+	})
+	t.Run("error in a batch stops reading", func(t *testing.T) {
+		calls := 0
+		count, err := readTableRows(openTable(t, string(content)), func([]map[string]interface{}) error {
+			calls++
+			return errors.New("restore failed")
+		})
+		require.EqualError(t, err, "restore failed")
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, int64(restoreBatchSize), count)
+	})
+	t.Run("not an array", func(t *testing.T) {
+		_, err := readTableRows(openTable(t, `{"id":1}`), func([]map[string]interface{}) error { return nil })
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expected table data to be a json array")
+	})
+	t.Run("truncated", func(t *testing.T) {
+		_, err := readTableRows(openTable(t, `[{"id":1},{"id":`), func([]map[string]interface{}) error { return nil })
+		require.Error(t, err)
+	})
+}
@@ -18,9 +18,11 @@ package dump
 
 import (
 	"archive/zip"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 
 	"code.vikunja.io/api/pkg/db"
@@ -77,17 +79,33 @@ func Dump(filename string) error {
 	}
 	log.Info("Dumped version")
 
+	manifest := &Manifest{
+		Version:      version.Version,
+		DatabaseType: string(db.Type()),
+		Tables:       make(map[string]*ManifestEntry),
+		Files:        make(map[string]*ManifestEntry),
+	}
+
 	// Database
 	log.Info("Start dumping database...")
-	data, err := db.Dump()
+	tables, err := db.Tables()
 	if err != nil {
-		return fmt.Errorf("error saving database data: %w", err)
+		return fmt.Errorf("error getting database tables: %w", err)
 	}
-	for t, d := range data {
-		err = utils.WriteBytesToZip("database/"+t+".json", d, dumpWriter)
+	for _, t := range tables {
+		w, err := createZipEntry("database/"+t.Name+".json", dumpWriter)
+		if err != nil {
+			return fmt.Errorf("error writing database table %s: %w", t.Name, err)
+		}
+
+		cw := newChecksumWriter()
+		rows, err := db.DumpTable(t, io.MultiWriter(w, cw))
 		if err != nil {
-			return fmt.Errorf("error writing database table %s: %w", t, err)
+			return fmt.Errorf("error writing database table %s: %w", t.Name, err)
 		}
+
+		manifest.Tables[t.Name] = cw.entry()
+		manifest.Tables[t.Name].Rows = rows
 	}
 	log.Info("Dumped database")
 
@@ -98,18 +116,47 @@ func Dump(filename string) error {
 		return fmt.Errorf("error saving file: %w", err)
 	}
 
-	err = utils.WriteFilesToZip(allFiles, dumpWriter)
-	if err != nil {
-		return err
+	for fid, file := range allFiles {
+		name := strconv.FormatInt(fid, 10)
+		w, err := createZipEntry("files/"+name, dumpWriter)
+		if err != nil {
+			return fmt.Errorf("error writing file %d: %w", fid, err)
+		}
+
+		cw := newChecksumWriter()
+		_, err = io.Copy(io.MultiWriter(w, cw), file)
+		if err != nil {
+			return fmt.Errorf("error writing file %d: %w", fid, err)
+		}
+		_ = file.Close()
+
+		manifest.Files[name] = cw.entry()
 	}
 
 	log.Infof("Dumped files")
 
+	// Checksums
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return fmt.Errorf("error creating checksums: %w", err)
+	}
+	err = utils.WriteBytesToZip(checksumsFileName, manifestJSON, dumpWriter)
+	if err != nil {
+		return fmt.Errorf("error saving checksums: %w", err)
+	}
+
 	log.Info("Done creating dump")
 	log.Infof("Dump file saved at %s", filename)
 	return nil
 }
 
+func createZipEntry(name string, writer *zip.Writer) (io.Writer, error) {
+	return writer.CreateHeader(&zip.FileHeader{
+		Name:   name,
+		Method: utils.CompressionUsed,
+	})
+}
+
 func writeFileToZip(filename string, writer *zip.Writer) error {
 	// #nosec
 	fileToZip, err := os.Open(filename)
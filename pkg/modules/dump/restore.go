@@ -28,7 +28,6 @@ import (
 	"os"
 	"sort"
 	"strconv"
-	"strings"
 
 	"github.com/hashicorp/go-version"
 
@@ -44,6 +43,9 @@ import (
 
 const maxConfigSize = 5 * 1024 * 1024 // 5 MB, should be largely enough
 
+// restoreBatchSize is the number of rows inserted at once when restoring a table
+const restoreBatchSize = 1000
+
 // Restore takes a zip file name and restores it
 func Restore(filename string) error {
 
@@ -51,6 +53,7 @@ func Restore(filename string) error {
 	if err != nil {
 		return fmt.Errorf("could not open zip file: %w", err)
 	}
+	defer r.Close()
 
 	log.Warning("Restoring a dump will wipe your current installation!")
 	log.Warning("To confirm, please type 'Yes, I understand' and confirm with enter:")
@@ -64,44 +67,35 @@ func Restore(filename string) error {
 	}
 
 	// Find the configFile, database and files files
-	var configFile *zip.File
-	var dotEnvFile *zip.File
-	var versionFile *zip.File
-	dbfiles := make(map[string]*zip.File)
-	filesFiles := make(map[string]*zip.File)
-	for _, file := range r.File {
-		if strings.HasPrefix(file.Name, "config") {
-			configFile = file
-			continue
-		}
-		if strings.HasPrefix(file.Name, "database/") {
-			fname := strings.ReplaceAll(file.Name, "database/", "")
-			dbfiles[fname[:len(fname)-5]] = file
-			continue
-		}
-		if file.Name == ".env" {
-			dotEnvFile = file
-			continue
-		}
-		if strings.HasPrefix(file.Name, "files/") {
-			filesFiles[strings.ReplaceAll(file.Name, "files/", "")] = file
-			continue
-		}
-		if file.Name == "VERSION" {
-			versionFile = file
-		}
-	}
+	contents := readDumpContents(&r.Reader)
 
 	///////
 	// Check if we're restoring to the same version as the dump
-	err = checkVikunjaVersion(versionFile)
+	_, err = checkVikunjaVersion(contents.versionFile)
 	if err != nil {
 		return err
 	}
 
+	///////
+	// Check the dump is complete before touching anything
+	var manifest *Manifest
+	if contents.checksumsFile != nil {
+		err = verifyDump(contents)
+		if err != nil {
+			return err
+		}
+		manifest, err = readManifest(contents.checksumsFile)
+		if err != nil {
+			return err
+		}
+		log.Info("Verified checksums of all tables and files.")
+	} else {
+		log.Warning("Dump does not contain checksums, unable to verify it.")
+	}
+
 	///////
 	// Restore the config file
-	err = restoreConfig(configFile, dotEnvFile)
+	err = restoreConfig(contents.configFile, contents.dotEnvFile)
 	if err != nil {
 		return err
 	}
@@ -112,45 +106,34 @@ func Restore(filename string) error {
 	initialize.InitEngines()
 	files.InitFileHandler()
 
-	///////
-	// Restore the db
-	// Start by wiping everything
-	if err := db.WipeEverything(); err != nil {
-		return fmt.Errorf("could not wipe database: %w", err)
+	if manifest != nil && manifest.DatabaseType != string(db.Type()) {
+		log.Infof("Dump was created from a %s database, restoring it into %s.", manifest.DatabaseType, db.Type())
 	}
-	log.Info("Wiped database.")
 
+	///////
+	// Restore the db
 	// Because we don't explicitly saved the table definitions, we take the last ran db migration from the dump
 	// and execute everything until that point.
-	migrations := dbfiles["migration"]
-	rc, err := migrations.Open()
+	lastMigration, err := getLastMigration(contents.dbfiles)
 	if err != nil {
-		return fmt.Errorf("could not open migrations: %w", err)
-	}
-	defer rc.Close()
-
-	var buf bytes.Buffer
-	if _, err := buf.ReadFrom(rc); err != nil {
-		return fmt.Errorf("could not read migrations: %w", err)
+		return err
 	}
 
-	ms := []*xormigrate.Migration{}
-	if err := json.Unmarshal(buf.Bytes(), &ms); err != nil {
-		return fmt.Errorf("could not read migrations: %w", err)
+	// Start by wiping everything
+	if err := db.WipeEverything(); err != nil {
+		return fmt.Errorf("could not wipe database: %w", err)
 	}
-	sort.Slice(ms, func(i, j int) bool {
-		return ms[i].ID < ms[j].ID
-	})
+	log.Info("Wiped database.")
 
-	lastMigration := ms[len(ms)-2]
-	log.Debugf("Last migration: %s", lastMigration.ID)
-	if err := migration.MigrateTo(lastMigration.ID, nil); err != nil {
+	log.Debugf("Last migration: %s", lastMigration)
+	if err := migration.MigrateTo(lastMigration, nil); err != nil {
 		return fmt.Errorf("could not create db structure: %w", err)
 	}
 
-	delete(dbfiles, "migration")
+	delete(contents.dbfiles, "migration")
 
-	err = restoreTableData(dbfiles)
+	// Dumps created before checksums were added contain some json fields base64 encoded
+	err = restoreTableData(contents.dbfiles, contents.checksumsFile == nil)
 	if err != nil {
 		return err
 	}
@@ -160,7 +143,7 @@ func Restore(filename string) error {
 
 	///////
 	// Restore Files
-	for i, file := range filesFiles {
+	for i, file := range contents.filesFiles {
 		id, err := strconv.ParseInt(i, 10, 64)
 		if err != nil {
 			return fmt.Errorf("could not parse file id %s: %w", i, err)
@@ -180,7 +163,7 @@ func Restore(filename string) error {
 		_ = fc.Close()
 		log.Infof("Restored file %s", i)
 	}
-	log.Infof("Restored %d files.", len(filesFiles))
+	log.Infof("Restored %d files.", len(contents.filesFiles))
 
 	///////
 	// Done
@@ -190,68 +173,152 @@ func Restore(filename string) error {
 	return nil
 }
 
-func restoreTableData(tables map[string]*zip.File) error {
-	jsonFields := map[string][]string{
-		"notifications": {"notification"},
-		"users":         {"frontend_settings"},
+// getLastMigration returns the id of the last migration which ran before the dump was created
+func getLastMigration(dbfiles map[string]*zip.File) (string, error) {
+	migrations, has := dbfiles["migration"]
+	if !has {
+		return "", fmt.Errorf("dump does not contain the migration table")
 	}
 
-	// Restore all db data
-	for table, d := range tables {
-		content, err := unmarshalFileToJSON(d)
-		if err != nil {
-			return fmt.Errorf("could not read table %s: %w", table, err)
-		}
+	rc, err := migrations.Open()
+	if err != nil {
+		return "", fmt.Errorf("could not open migrations: %w", err)
+	}
+	defer rc.Close()
 
-		fields, hasJSONFields := jsonFields[table]
-		if hasJSONFields {
-			for i := range content {
-				for _, f := range fields {
+	ms := []*xormigrate.Migration{}
+	if err := json.NewDecoder(rc).Decode(&ms); err != nil {
+		return "", fmt.Errorf("could not read migrations: %w", err)
+	}
 
-					if _, hasField := content[i][f]; !hasField {
-						continue
-					}
+	ids := make([]string, 0, len(ms))
+	for _, m := range ms {
+		// The initial schema creation is saved as a migration as well
+		if m.ID == "SCHEMA_INIT" {
+			continue
+		}
+		ids = append(ids, m.ID)
+	}
+	if len(ids) == 0 {
+		return "", fmt.Errorf("dump does not contain any migrations")
+	}
+	sort.Strings(ids)
 
-					var decoded []byte
-					decoded, err = base64.StdEncoding.DecodeString(content[i][f].(string))
-					if err != nil && !errors.Is(err, base64.CorruptInputError(0)) {
-						return fmt.Errorf("could not decode field '%s' %s: %w", f, content[i][f], err)
-					}
+	return ids[len(ids)-1], nil
+}
 
-					if err != nil && errors.Is(err, base64.CorruptInputError(0)) {
-						decoded = []byte(content[i][f].(string))
-					}
+var jsonFields = map[string][]string{
+	"notifications": {"notification"},
+	"users":         {"frontend_settings"},
+}
+
+func restoreTableData(tables map[string]*zip.File, decodeBase64 bool) error {
+	// Restore all db data
+	for table, d := range tables {
+		meta, err := db.GetTable(table)
+		if err != nil {
+			return err
+		}
 
-					content[i][f] = string(decoded)
+		rows, err := readTableRows(d, func(content []map[string]interface{}) error {
+			if decodeBase64 {
+				err := decodeJSONFields(table, content)
+				if err != nil {
+					return err
 				}
 			}
+			return db.RestoreRows(meta, content)
+		})
+		if err != nil {
+			return fmt.Errorf("could not restore table data for table %s: %w", table, err)
 		}
 
-		if err := db.Restore(table, content); err != nil {
+		err = db.ResetIDSequence(meta)
+		if err != nil {
 			return fmt.Errorf("could not restore table data for table %s: %w", table, err)
 		}
-		log.Infof("Restored table %s", table)
+
+		log.Infof("Restored table %s (%d rows)", table, rows)
 	}
 	log.Infof("Restored %d tables", len(tables))
 
 	return nil
 }
 
-func unmarshalFileToJSON(file *zip.File) (contents []map[string]interface{}, err error) {
+// Older dumps contain some json fields base64 encoded.
+func decodeJSONFields(table string, content []map[string]interface{}) error {
+	fields, hasJSONFields := jsonFields[table]
+	if !hasJSONFields {
+		return nil
+	}
+
+	for i := range content {
+		for _, f := range fields {
+
+			strVal, hasField := content[i][f].(string)
+			if !hasField {
+				continue
+			}
+
+			decoded, err := base64.StdEncoding.DecodeString(strVal)
+			if err != nil && !errors.Is(err, base64.CorruptInputError(0)) {
+				return fmt.Errorf("could not decode field '%s' %s: %w", f, strVal, err)
+			}
+
+			if err != nil && errors.Is(err, base64.CorruptInputError(0)) {
+				decoded = []byte(strVal)
+			}
+
+			content[i][f] = string(decoded)
+		}
+	}
+
+	return nil
+}
+
+// readTableRows reads the rows of a dumped table in batches and passes each batch to fn.
+// The table is never loaded into memory completely, so that large instances can be restored.
+func readTableRows(file *zip.File, fn func(content []map[string]interface{}) error) (rows int64, err error) {
 	rc, err := file.Open()
 	if err != nil {
 		return
 	}
 	defer rc.Close()
 
-	var buf bytes.Buffer
-	if _, err := buf.ReadFrom(rc); err != nil {
-		return nil, err
+	dec := json.NewDecoder(rc)
+	dec.UseNumber()
+
+	t, err := dec.Token()
+	if err != nil {
+		return 0, err
+	}
+	if delim, is := t.(json.Delim); !is || delim != '[' {
+		return 0, fmt.Errorf("expected table data to be a json array")
 	}
 
-	contents = []map[string]interface{}{}
-	if err := json.Unmarshal(buf.Bytes(), &contents); err != nil {
-		return nil, err
+	batch := make([]map[string]interface{}, 0, restoreBatchSize)
+	for dec.More() {
+		row := map[string]interface{}{}
+		if err := dec.Decode(&row); err != nil {
+			return rows, err
+		}
+		batch = append(batch, row)
+		rows++
+
+		if len(batch) == restoreBatchSize {
+			if err := fn(batch); err != nil {
+				return rows, err
+			}
+			batch = make([]map[string]interface{}, 0, restoreBatchSize)
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return rows, err
+	}
+
+	if len(batch) > 0 {
+		err = fn(batch)
 	}
 	return
 }
@@ -315,37 +382,39 @@ func restoreConfig(configFile, dotEnvFile *zip.File) error {
 	return nil
 }
 
-func checkVikunjaVersion(versionFile *zip.File) error {
+func checkVikunjaVersion(versionFile *zip.File) (dumpedVersion string, err error) {
 	if versionFile == nil {
-		return fmt.Errorf("dump does not contain VERSION file, refusing to continue")
+		return "", fmt.Errorf("dump does not contain VERSION file, refusing to continue")
 	}
 	vf, err := versionFile.Open()
 	if err != nil {
-		return fmt.Errorf("could not open version file: %w", err)
+		return "", fmt.Errorf("could not open version file: %w", err)
 	}
+	defer vf.Close()
 
 	var bufVersion bytes.Buffer
 	if _, err := bufVersion.ReadFrom(vf); err != nil {
-		return fmt.Errorf("could not read version file: %w", err)
+		return "", fmt.Errorf("could not read version file: %w", err)
 	}
 
 	versionString := bufVersion.String()
 	if versionString == "dev" && vversion.Version == "dev" {
 		log.Debugf("Importing from dev version")
-	} else {
-		dumpedVersion, err := version.NewVersion(bufVersion.String())
-		if err != nil {
-			return err
-		}
-		currentVersion, err := version.NewVersion(vversion.Version)
-		if err != nil {
-			return err
-		}
+		return versionString, nil
+	}
 
-		if !dumpedVersion.Equal(currentVersion) {
-			return fmt.Errorf("export was created with version %s but this is %s - please make sure you are running the same Vikunja version before restoring", dumpedVersion, currentVersion)
-		}
+	dumped, err := version.NewVersion(versionString)
+	if err != nil {
+		return versionString, err
+	}
+	currentVersion, err := version.NewVersion(vversion.Version)
+	if err != nil {
+		return versionString, err
 	}
 
-	return nil
+	if !dumped.Equal(currentVersion) {
+		return versionString, fmt.Errorf("export was created with version %s but this is %s - please make sure you are running the same Vikunja version before restoring", dumped, currentVersion)
+	}
+
+	return versionString, nil
 }
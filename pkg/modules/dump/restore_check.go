@@ -0,0 +1,142 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dump
+
+import (
+	"archive/zip"
+	"fmt"
+	"sort"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/migration"
+	vversion "code.vikunja.io/api/pkg/version"
+)
+
+// RestoreReport describes whether a dump can be restored into the current instance.
+type RestoreReport struct {
+	DumpVersion       string
+	CurrentVersion    string
+	VersionCompatible bool
+
+	// The database type the dump was created from, empty if the dump does not contain checksums.
+	DumpDatabaseType    string
+	CurrentDatabaseType string
+
+	// The last migration which ran before the dump was created.
+	LastMigration string
+	// Whether this version of Vikunja knows all migrations of the dump.
+	SchemaCompatible bool
+	// The number of migrations which will run after the dump was restored.
+	PendingMigrations int
+
+	// The number of rows per table in the dump.
+	Tables map[string]int64
+	Files  int
+
+	HasChecksums      bool
+	ChecksumsVerified bool
+
+	// Everything which prevents the dump from being restored.
+	Problems []string
+}
+
+// CanRestore returns true if no problems were found.
+func (r *RestoreReport) CanRestore() bool {
+	return len(r.Problems) == 0
+}
+
+func (r *RestoreReport) addProblem(err error) {
+	r.Problems = append(r.Problems, err.Error())
+}
+
+// CheckRestore checks if a dump can be restored into the current instance without changing anything.
+func CheckRestore(filename string) (report *RestoreReport, err error) {
+	r, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open zip file: %w", err)
+	}
+	defer r.Close()
+
+	contents := readDumpContents(&r.Reader)
+
+	report = &RestoreReport{
+		CurrentVersion:      vversion.Version,
+		CurrentDatabaseType: string(db.Type()),
+		Tables:              make(map[string]int64, len(contents.dbfiles)),
+		Files:               len(contents.filesFiles),
+		HasChecksums:        contents.checksumsFile != nil,
+	}
+
+	report.DumpVersion, err = checkVikunjaVersion(contents.versionFile)
+	if err != nil {
+		report.addProblem(err)
+	} else {
+		report.VersionCompatible = true
+	}
+
+	checkSchema(report, contents.dbfiles)
+
+	if contents.checksumsFile == nil {
+		for table, file := range contents.dbfiles {
+			rows, err := readTableRows(file, func([]map[string]interface{}) error { return nil })
+			if err != nil {
+				report.addProblem(fmt.Errorf("could not read table %s: %w", table, err))
+				continue
+			}
+			report.Tables[table] = rows
+		}
+		return report, nil
+	}
+
+	manifest, err := readManifest(contents.checksumsFile)
+	if err != nil {
+		report.addProblem(err)
+		return report, nil
+	}
+	report.DumpDatabaseType = manifest.DatabaseType
+	for table, entry := range manifest.Tables {
+		report.Tables[table] = entry.Rows
+	}
+
+	err = verifyDump(contents)
+	if err != nil {
+		report.addProblem(err)
+	} else {
+		report.ChecksumsVerified = true
+	}
+
+	return report, nil
+}
+
+func checkSchema(report *RestoreReport, dbfiles map[string]*zip.File) {
+	lastMigration, err := getLastMigration(dbfiles)
+	if err != nil {
+		report.addProblem(err)
+		return
+	}
+	report.LastMigration = lastMigration
+
+	known := migration.GetMigrationIDs()
+	i := sort.SearchStrings(known, lastMigration)
+	if i == len(known) || known[i] != lastMigration {
+		report.addProblem(fmt.Errorf("dump was created with migration %s which is unknown to this version of Vikunja", lastMigration))
+		return
+	}
+
+	report.SchemaCompatible = true
+	report.PendingMigrations = len(known) - i - 1
+}
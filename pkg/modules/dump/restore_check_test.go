@@ -0,0 +1,144 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/migration"
+	vversion "code.vikunja.io/api/pkg/version"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRestore(t *testing.T) {
+	_, err := db.CreateTestEngine()
+	require.NoError(t, err)
+
+	ids := migration.GetMigrationIDs()
+	require.NotEmpty(t, ids)
+	lastMigration := ids[len(ids)-1]
+
+	migrations := `[{"id":"SCHEMA_INIT"},{"id":"` + ids[len(ids)-2] + `"}]`
+	tasks := `[{"id":1,"title":"Task"},{"id":2,"title":"Other task"}]`
+	file := "file content"
+
+	validManifest := func() *Manifest {
+		tasksEntry := checksumOf(tasks)
+		tasksEntry.Rows = 2
+		return &Manifest{
+			Version:      vversion.Version,
+			DatabaseType: "mysql",
+			Tables: map[string]*ManifestEntry{
+				"migration": checksumOf(migrations),
+				"tasks":     tasksEntry,
+			},
+			Files: map[string]*ManifestEntry{"1": checksumOf(file)},
+		}
+	}
+
+	t.Run("restorable dump", func(t *testing.T) {
+		filename := writeTestDump(t, map[string]string{
+			"VERSION":                 vversion.Version,
+			"database/migration.json": migrations,
+			"database/tasks.json":     tasks,
+			"files/1":                 file,
+		}, validManifest())
+
+		report, err := CheckRestore(filename)
+		require.NoError(t, err)
+		assert.True(t, report.CanRestore(), "problems: %v", report.Problems)
+		assert.True(t, report.VersionCompatible)
+		assert.True(t, report.SchemaCompatible)
+		assert.True(t, report.HasChecksums)
+		assert.True(t, report.ChecksumsVerified)
+		assert.Equal(t, ids[len(ids)-2], report.LastMigration)
+		assert.Equal(t, 1, report.PendingMigrations)
+		assert.Equal(t, "mysql", report.DumpDatabaseType)
+		assert.Equal(t, string(db.Type()), report.CurrentDatabaseType)
+		assert.Equal(t, int64(2), report.Tables["tasks"])
+		assert.Equal(t, 1, report.Files)
+	})
+	t.Run("dump without checksums", func(t *testing.T) {
+		filename := writeTestDump(t, map[string]string{
+			"VERSION":                 vversion.Version,
+			"database/migration.json": migrations,
+			"database/tasks.json":     tasks,
+		}, nil)
+
+		report, err := CheckRestore(filename)
+		require.NoError(t, err)
+		assert.True(t, report.CanRestore(), "problems: %v", report.Problems)
+		assert.False(t, report.HasChecksums)
+		assert.False(t, report.ChecksumsVerified)
+		assert.Empty(t, report.DumpDatabaseType)
+		// Without checksums, the rows are counted by reading the tables
+		assert.Equal(t, int64(2), report.Tables["tasks"])
+		assert.Equal(t, int64(2), report.Tables["migration"])
+	})
+	t.Run("corrupt dump", func(t *testing.T) {
+		filename := writeTestDump(t, map[string]string{
+			"VERSION":                 vversion.Version,
+			"database/migration.json": migrations,
+			"database/tasks.json":     `[{"id":1,"title":"Changed"}]`,
+			"files/1":                 file,
+		}, validManifest())
+
+		report, err := CheckRestore(filename)
+		require.NoError(t, err)
+		assert.False(t, report.CanRestore())
+		assert.True(t, report.HasChecksums)
+		assert.False(t, report.ChecksumsVerified)
+		require.Len(t, report.Problems, 1)
+		assert.Contains(t, report.Problems[0], "checksum of table tasks does not match")
+	})
+	t.Run("unknown migration", func(t *testing.T) {
+		filename := writeTestDump(t, map[string]string{
+			"VERSION":                 vversion.Version,
+			"database/migration.json": `[{"id":"` + lastMigration + `9"}]`,
+		}, nil)
+
+		report, err := CheckRestore(filename)
+		require.NoError(t, err)
+		assert.False(t, report.CanRestore())
+		assert.False(t, report.SchemaCompatible)
+		require.Len(t, report.Problems, 1)
+		assert.Contains(t, report.Problems[0], "unknown to this version of Vikunja")
+	})
+	t.Run("missing version and migrations", func(t *testing.T) {
+		filename := writeTestDump(t, map[string]string{
+			"database/tasks.json": tasks,
+		}, nil)
+
+		report, err := CheckRestore(filename)
+		require.NoError(t, err)
+		assert.False(t, report.VersionCompatible)
+		assert.False(t, report.SchemaCompatible)
+		assert.Len(t, report.Problems, 2)
+	})
+	t.Run("not a zip file", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "dump.zip")
+		require.NoError(t, os.WriteFile(filename, []byte("not a zip"), 0600))
+
+		_, err := CheckRestore(filename)
+		require.Error(t, err)
+	})
+}
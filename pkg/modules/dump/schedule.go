@@ -0,0 +1,134 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dump
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/log"
+)
+
+const (
+	dumpFilePrefix = "vikunja-dump_"
+	dumpFileSuffix = ".zip"
+)
+
+// NewFileName returns the name of a new dump file in dir
+func NewFileName(dir string) string {
+	return filepath.Join(dir, dumpFilePrefix+time.Now().Format("2006-01-02_15-04-05")+dumpFileSuffix)
+}
+
+// Rotate removes all dumps in dir except the newest keep ones. Only files created by Vikunja are removed.
+func Rotate(dir string, keep int) (removed []string, err error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type dumpFile struct {
+		path    string
+		modTime time.Time
+	}
+	dumps := []*dumpFile{}
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasPrefix(e.Name(), dumpFilePrefix) || !strings.HasSuffix(e.Name(), dumpFileSuffix) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		dumps = append(dumps, &dumpFile{
+			path:    filepath.Join(dir, e.Name()),
+			modTime: info.ModTime(),
+		})
+	}
+
+	if len(dumps) <= keep {
+		return nil, nil
+	}
+
+	sort.Slice(dumps, func(i, j int) bool {
+		if dumps[i].modTime.Equal(dumps[j].modTime) {
+			return dumps[i].path > dumps[j].path
+		}
+		return dumps[i].modTime.After(dumps[j].modTime)
+	})
+
+	for _, d := range dumps[keep:] {
+		err = os.Remove(d.path)
+		if err != nil {
+			return removed, err
+		}
+		removed = append(removed, d.path)
+	}
+
+	return removed, nil
+}
+
+// RegisterDumpCron creates a dump of the whole instance on the configured schedule and removes old dumps.
+func RegisterDumpCron() {
+	if !config.DumpScheduleEnabled.GetBool() {
+		return
+	}
+
+	const logPrefix = "[Dump Cron] "
+
+	dir := config.DumpPath.GetString()
+	if dir == "" {
+		dir = config.ServiceRootpath.GetString()
+	}
+
+	err := cron.Schedule(config.DumpSchedule.GetString(), func() {
+		err := os.MkdirAll(dir, 0700)
+		if err != nil {
+			log.Errorf(logPrefix+"Could not create dump folder %s: %s", dir, err)
+			return
+		}
+
+		filename := NewFileName(dir)
+		log.Infof(logPrefix+"Creating dump %s...", filename)
+		err = Dump(filename)
+		if err != nil {
+			log.Errorf(logPrefix+"Could not create dump: %s", err)
+			return
+		}
+		log.Infof(logPrefix+"Created dump %s", filename)
+
+		removed, err := Rotate(dir, config.DumpRetention.GetInt())
+		if err != nil {
+			log.Errorf(logPrefix+"Could not remove old dumps: %s", err)
+			return
+		}
+		if len(removed) > 0 {
+			log.Infof(logPrefix+"Removed %d old dumps", len(removed))
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register dump cron: %s", err)
+	}
+}
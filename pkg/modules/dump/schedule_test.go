@@ -0,0 +1,54 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package dump
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotate(t *testing.T) {
+	dir := t.TempDir()
+
+	names := []string{
+		"vikunja-dump_2024-07-01_03-00-00.zip",
+		"vikunja-dump_2024-07-02_03-00-00.zip",
+		"vikunja-dump_2024-07-03_03-00-00.zip",
+		"other.zip",
+	}
+	for i, name := range names {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte{}, 0600))
+		modTime := time.Now().Add(time.Duration(i-len(names)) * time.Hour)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	removed, err := Rotate(dir, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, names[0])}, removed)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+	assert.NoFileExists(t, filepath.Join(dir, names[0]))
+	assert.FileExists(t, filepath.Join(dir, "other.zip"))
+}
@@ -0,0 +1,214 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package export
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"xorm.io/xorm"
+)
+
+// Format is one of the standard formats the tasks of a project or saved filter can be exported to.
+type Format string
+
+const (
+	FormatCSV      Format = `csv`
+	FormatICS      Format = `ics`
+	FormatMarkdown Format = `md`
+	FormatTodoTxt  Format = `todotxt`
+)
+
+// Validate checks if the format is one of the known formats.
+func (f Format) Validate() error {
+	switch f {
+	case FormatCSV, FormatICS, FormatMarkdown, FormatTodoTxt:
+		return nil
+	}
+	return &models.ErrInvalidExportFormat{Format: string(f)}
+}
+
+// Extension returns the file extension for files in this format.
+func (f Format) Extension() string {
+	if f == FormatTodoTxt {
+		return "txt"
+	}
+	return string(f)
+}
+
+// MimeType returns the mime type for files in this format.
+func (f Format) MimeType() string {
+	switch f {
+	case FormatCSV:
+		return "text/csv"
+	case FormatICS:
+		return "text/calendar"
+	case FormatMarkdown:
+		return "text/markdown"
+	default:
+		return "text/plain"
+	}
+}
+
+// Data holds the tasks of one project or saved filter which should be exported.
+type Data struct {
+	Project *models.Project
+	Tasks   []*models.Task
+	// The buckets of the first kanban view with manual buckets, ordered by their position.
+	// Empty if the project has no such view, as is the case for saved filters.
+	Buckets []*models.Bucket
+	// The bucket of each task in that view, with the task id as key.
+	TaskBuckets map[int64]int64
+}
+
+// FileName returns the name for an export file of this data.
+func (d *Data) FileName(format Format) string {
+	return "vikunja-" + strconv.FormatInt(d.Project.ID, 10) + "." + format.Extension()
+}
+
+func checkProjectAccess(s *xorm.Session, u *user.User, projectID int64) (project *models.Project, err error) {
+	project = &models.Project{ID: projectID}
+	canRead, _, err := project.CanRead(s, u)
+	if err != nil {
+		return nil, err
+	}
+	if !canRead {
+		return nil, models.ErrUserDoesNotHaveAccessToProject{
+			ProjectID: projectID,
+			UserID:    u.ID,
+		}
+	}
+
+	err = project.ReadOne(s, u)
+	return
+}
+
+// CountTasks returns the number of tasks the user would export from a project or saved filter.
+func CountTasks(s *xorm.Session, u *user.User, projectID int64) (count int64, err error) {
+	_, err = checkProjectAccess(s, u, projectID)
+	if err != nil {
+		return
+	}
+
+	tc := &models.TaskCollection{ProjectID: projectID}
+	_, _, count, err = tc.ReadAll(s, u, "", 1, 1)
+	return
+}
+
+// GetData returns all tasks of a project or saved filter the user has access to.
+func GetData(s *xorm.Session, u *user.User, projectID int64) (data *Data, err error) {
+	project, err := checkProjectAccess(s, u, projectID)
+	if err != nil {
+		return
+	}
+
+	tc := &models.TaskCollection{
+		ProjectID: projectID,
+		SortBy:    []string{"done", "id"},
+	}
+	result, _, _, err := tc.ReadAll(s, u, "", 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	data = &Data{
+		Project:     project,
+		Tasks:       result.([]*models.Task),
+		TaskBuckets: make(map[int64]int64),
+	}
+
+	if projectID < 0 {
+		return data, nil
+	}
+
+	view := &models.ProjectView{}
+	has, err := s.
+		Where("project_id = ? AND view_kind = ? AND bucket_configuration_mode = ?",
+			projectID, models.ProjectViewKindKanban, models.BucketConfigurationModeManual).
+		OrderBy("position asc").
+		Get(view)
+	if err != nil || !has {
+		return data, err
+	}
+
+	err = s.
+		Where("project_view_id = ?", view.ID).
+		OrderBy("position asc").
+		Find(&data.Buckets)
+	if err != nil {
+		return nil, err
+	}
+
+	taskBuckets := []*models.TaskBucket{}
+	err = s.Where("project_view_id = ?", view.ID).Find(&taskBuckets)
+	if err != nil {
+		return nil, err
+	}
+	for _, tb := range taskBuckets {
+		data.TaskBuckets[tb.TaskID] = tb.BucketID
+	}
+
+	return data, nil
+}
+
+// Write writes the data in the given format.
+func Write(w io.Writer, format Format, data *Data) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, data)
+	case FormatICS:
+		return writeICS(w, data)
+	case FormatMarkdown:
+		return writeMarkdown(w, data)
+	case FormatTodoTxt:
+		return writeTodoTxt(w, data)
+	}
+	return &models.ErrInvalidExportFormat{Format: string(format)}
+}
+
+// ExportProject returns the tasks of a project or saved filter in the given format. If the project has more
+// tasks than configured in export.synclimit, it returns an error and the export needs to be requested
+// as a background job instead.
+func ExportProject(s *xorm.Session, u *user.User, projectID int64, format Format) (content []byte, data *Data, err error) {
+	err = format.Validate()
+	if err != nil {
+		return
+	}
+
+	count, err := CountTasks(s, u, projectID)
+	if err != nil {
+		return
+	}
+	limit := config.ExportSyncLimit.GetInt64()
+	if limit > 0 && count > limit {
+		return nil, nil, &models.ErrExportTooLarge{TaskCount: count, Limit: limit}
+	}
+
+	data, err = GetData(s, u, projectID)
+	if err != nil {
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	err = Write(buf, format, data)
+	return buf.Bytes(), data, err
+}
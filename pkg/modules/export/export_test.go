@@ -0,0 +1,210 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testData() *Data {
+	due := time.Date(2024, 7, 3, 12, 0, 0, 0, time.UTC)
+	created := time.Date(2024, 7, 1, 10, 0, 0, 0, time.UTC)
+	return &Data{
+		Project: &models.Project{ID: 42, Title: "Home Office"},
+		Tasks: []*models.Task{
+			{
+				ID:        1,
+				Index:     1,
+				Title:     "Buy a desk",
+				Priority:  4,
+				DueDate:   due,
+				Created:   created,
+				Labels:    []*models.Label{{Title: "Shopping list"}},
+				Assignees: []*user.User{{Username: "user1"}},
+				CreatedBy: &user.User{Username: "user1"},
+			},
+			{
+				ID:      2,
+				Index:   2,
+				Title:   "Set up the monitor",
+				Done:    true,
+				DoneAt:  due,
+				Created: created,
+			},
+		},
+	}
+}
+
+func TestWrite(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := Write(buf, FormatCSV, testData())
+		require.NoError(t, err)
+		assert.Equal(t, `#,Done,Title,Priority,Labels,Assignees,Due Date,Start Date,End Date,Percent Done,Done At,Created,Updated,Created By
+#1,false,Buy a desk,4,Shopping list,user1,2024-07-03T12:00:00Z,,,0,,2024-07-01T10:00:00Z,,user1
+#2,true,Set up the monitor,0,,,,,,0,2024-07-03T12:00:00Z,2024-07-01T10:00:00Z,,
+`, buf.String())
+	})
+	t.Run("markdown", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := Write(buf, FormatMarkdown, testData())
+		require.NoError(t, err)
+		assert.Equal(t, `# Home Office
+
+- [ ] Buy a desk (due 2024-07-03)
+- [x] Set up the monitor
+`, buf.String())
+	})
+	t.Run("markdown grouped by bucket", func(t *testing.T) {
+		data := testData()
+		data.Buckets = []*models.Bucket{
+			{ID: 1, Title: "To Do"},
+			{ID: 2, Title: "Done"},
+		}
+		data.TaskBuckets = map[int64]int64{2: 2}
+
+		buf := &bytes.Buffer{}
+		err := Write(buf, FormatMarkdown, data)
+		require.NoError(t, err)
+		assert.Equal(t, `# Home Office
+
+## To Do
+
+
+## Done
+
+- [x] Set up the monitor
+
+## Other
+
+- [ ] Buy a desk (due 2024-07-03)
+`, buf.String())
+	})
+	t.Run("todo.txt", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := Write(buf, FormatTodoTxt, testData())
+		require.NoError(t, err)
+		assert.Equal(t, `(B) 2024-07-01 Buy a desk +Home_Office @Shopping_list due:2024-07-03
+x 2024-07-03 2024-07-01 Set up the monitor +Home_Office
+`, buf.String())
+	})
+	t.Run("ics", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		err := Write(buf, FormatICS, testData())
+		require.NoError(t, err)
+		assert.Contains(t, buf.String(), "BEGIN:VCALENDAR")
+		assert.Contains(t, buf.String(), "X-WR-CALNAME:Home Office")
+		assert.Contains(t, buf.String(), "SUMMARY:Buy a desk")
+		assert.Contains(t, buf.String(), "SUMMARY:Set up the monitor")
+	})
+	t.Run("invalid format", func(t *testing.T) {
+		err := Write(&bytes.Buffer{}, "pdf", testData())
+		require.Error(t, err)
+		assert.True(t, models.IsErrInvalidExportFormat(err))
+	})
+}
+
+func TestExportProject(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	t.Run("project", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		content, data, err := ExportProject(s, u, 1, FormatMarkdown)
+		require.NoError(t, err)
+		assert.Equal(t, "vikunja-1.md", data.FileName(FormatMarkdown))
+		assert.Len(t, data.Buckets, 3)
+		assert.Contains(t, string(content), "# Test1\n")
+		assert.Contains(t, string(content), "## testbucket1\n\n- [ ] task #1\n")
+		assert.Contains(t, string(content), "- [x] task #2 done\n")
+	})
+	t.Run("saved filter", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		content, data, err := ExportProject(s, u, -2, FormatCSV)
+		require.NoError(t, err)
+		assert.Equal(t, "testfilter1", data.Project.Title)
+		assert.Empty(t, data.Buckets)
+		assert.NotEmpty(t, data.Tasks)
+		assert.Contains(t, string(content), "Title,Priority")
+	})
+	t.Run("no access", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, _, err := ExportProject(s, u, 2, FormatCSV)
+		require.Error(t, err)
+		assert.True(t, models.IsErrUserDoesNotHaveAccessToProject(err))
+	})
+	t.Run("too large", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		limit := config.ExportSyncLimit.GetInt64()
+		defer config.ExportSyncLimit.Set(limit)
+		config.ExportSyncLimit.Set(1)
+
+		_, _, err := ExportProject(s, u, 1, FormatCSV)
+		require.Error(t, err)
+		assert.True(t, models.IsErrExportTooLarge(err))
+	})
+}
+
+func TestRequestExport(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	te, err := RequestExport(s, u, 1, FormatTodoTxt)
+	require.NoError(t, err)
+	require.NoError(t, s.Commit())
+	events.AssertDispatched(t, &TaskExportRequestedEvent{})
+
+	_, err = GetExportFile(s, u, 1, te.ID)
+	require.Error(t, err)
+	assert.True(t, models.IsErrExportNotReady(err))
+
+	events.TestListener(t, &TaskExportRequestedEvent{User: u, ExportID: te.ID}, &HandleTaskExport{})
+
+	f, err := GetExportFile(s, u, 1, te.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "vikunja-1.txt", f.Name)
+	assert.Equal(t, "text/plain", f.Mime)
+
+	_, err = GetExportFile(s, &user.User{ID: 2}, 1, te.ID)
+	require.Error(t, err)
+	assert.True(t, models.IsErrExportDoesNotExist(err))
+}
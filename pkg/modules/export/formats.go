@@ -0,0 +1,233 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/caldav"
+	"code.vikunja.io/api/pkg/models"
+)
+
+const dateFormat = `2006-01-02`
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(dateFormat)
+}
+
+func labelTitles(t *models.Task) []string {
+	titles := make([]string, 0, len(t.Labels))
+	for _, l := range t.Labels {
+		titles = append(titles, l.Title)
+	}
+	return titles
+}
+
+func assigneeNames(t *models.Task) []string {
+	names := make([]string, 0, len(t.Assignees))
+	for _, a := range t.Assignees {
+		names = append(names, a.Username)
+	}
+	return names
+}
+
+// writeCSV writes the tasks with the same columns as the table view.
+func writeCSV(w io.Writer, data *Data) error {
+	cw := csv.NewWriter(w)
+
+	err := cw.Write([]string{
+		"#",
+		"Done",
+		"Title",
+		"Priority",
+		"Labels",
+		"Assignees",
+		"Due Date",
+		"Start Date",
+		"End Date",
+		"Percent Done",
+		"Done At",
+		"Created",
+		"Updated",
+		"Created By",
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, t := range data.Tasks {
+		var createdBy string
+		if t.CreatedBy != nil {
+			createdBy = t.CreatedBy.Username
+		}
+
+		err = cw.Write([]string{
+			t.GetFullIdentifier(),
+			strconv.FormatBool(t.Done),
+			t.Title,
+			strconv.FormatInt(t.Priority, 10),
+			strings.Join(labelTitles(t), ", "),
+			strings.Join(assigneeNames(t), ", "),
+			formatTime(t.DueDate),
+			formatTime(t.StartDate),
+			formatTime(t.EndDate),
+			strconv.FormatFloat(t.PercentDone*100, 'f', -1, 64),
+			formatTime(t.DoneAt),
+			formatTime(t.Created),
+			formatTime(t.Updated),
+			createdBy,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeICS(w io.Writer, data *Data) error {
+	tasks := make([]*models.TaskWithComments, 0, len(data.Tasks))
+	for _, t := range data.Tasks {
+		tasks = append(tasks, &models.TaskWithComments{Task: *t})
+	}
+
+	_, err := io.WriteString(w, caldav.GetCaldavTodosForTasks(&models.ProjectWithTasksAndBuckets{Project: *data.Project}, tasks))
+	return err
+}
+
+func writeMarkdownTask(sb *strings.Builder, t *models.Task) {
+	if t.Done {
+		sb.WriteString("- [x] ")
+	} else {
+		sb.WriteString("- [ ] ")
+	}
+	sb.WriteString(strings.ReplaceAll(t.Title, "\n", " "))
+	if !t.DueDate.IsZero() {
+		sb.WriteString(" (due " + formatDate(t.DueDate) + ")")
+	}
+	sb.WriteString("\n")
+}
+
+// writeMarkdown writes the tasks as a checkbox list. If the project has a kanban view, the tasks
+// are grouped by the buckets of that view.
+func writeMarkdown(w io.Writer, data *Data) error {
+	sb := &strings.Builder{}
+	sb.WriteString("# " + data.Project.Title + "\n")
+
+	if len(data.Buckets) == 0 {
+		sb.WriteString("\n")
+		for _, t := range data.Tasks {
+			writeMarkdownTask(sb, t)
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+
+	tasksByBucket := make(map[int64][]*models.Task, len(data.Buckets))
+	for _, t := range data.Tasks {
+		bucketID := data.TaskBuckets[t.ID]
+		tasksByBucket[bucketID] = append(tasksByBucket[bucketID], t)
+	}
+
+	for _, b := range data.Buckets {
+		sb.WriteString("\n## " + b.Title + "\n\n")
+		for _, t := range tasksByBucket[b.ID] {
+			writeMarkdownTask(sb, t)
+		}
+	}
+
+	// Tasks which are not in any bucket of the view
+	if len(tasksByBucket[0]) > 0 {
+		sb.WriteString("\n## Other\n\n")
+		for _, t := range tasksByBucket[0] {
+			writeMarkdownTask(sb, t)
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Vikunja's priorities go from 1 (low) to 5 (do now), todo.txt uses A for the highest.
+var todoTxtPriorities = map[int64]string{
+	5: "A",
+	4: "B",
+	3: "C",
+	2: "D",
+	1: "E",
+}
+
+func todoTxtWord(s string) string {
+	return strings.Join(strings.Fields(s), "_")
+}
+
+// writeTodoTxt writes one line per task as specified in https://github.com/todotxt/todo.txt
+func writeTodoTxt(w io.Writer, data *Data) error {
+	sb := &strings.Builder{}
+	project := todoTxtWord(data.Project.Title)
+
+	for _, t := range data.Tasks {
+		if t.Done {
+			sb.WriteString("x ")
+			if !t.DoneAt.IsZero() {
+				sb.WriteString(formatDate(t.DoneAt) + " ")
+			}
+		} else if p, has := todoTxtPriorities[t.Priority]; has {
+			sb.WriteString("(" + p + ") ")
+		}
+
+		if !t.Created.IsZero() {
+			sb.WriteString(formatDate(t.Created) + " ")
+		}
+
+		sb.WriteString(strings.Join(strings.Fields(t.Title), " "))
+
+		if project != "" {
+			sb.WriteString(" +" + project)
+		}
+		for _, l := range labelTitles(t) {
+			sb.WriteString(" @" + todoTxtWord(l))
+		}
+		if !t.DueDate.IsZero() {
+			sb.WriteString(" due:" + formatDate(t.DueDate))
+		}
+		if t.Done {
+			if p, has := todoTxtPriorities[t.Priority]; has {
+				sb.WriteString(" pri:" + p)
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
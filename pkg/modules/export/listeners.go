@@ -0,0 +1,98 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package export
+
+import (
+	"encoding/json"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+func RegisterListeners() {
+	events.RegisterListener((&TaskExportRequestedEvent{}).Name(), &HandleTaskExport{})
+}
+
+// TaskExportRequestedEvent represents a TaskExportRequestedEvent event
+type TaskExportRequestedEvent struct {
+	User     *user.User `json:"user"`
+	ExportID int64      `json:"export_id"`
+}
+
+// Name defines the name for TaskExportRequestedEvent
+func (t *TaskExportRequestedEvent) Name() string {
+	return "task.export.requested"
+}
+
+// HandleTaskExport represents a listener
+type HandleTaskExport struct {
+}
+
+// Name defines the name for the HandleTaskExport listener
+func (l *HandleTaskExport) Name() string {
+	return "handle.task.export"
+}
+
+// Handle is executed when the event HandleTaskExport listens on is fired
+func (l *HandleTaskExport) Handle(msg *message.Message) (err error) {
+	event := &TaskExportRequestedEvent{}
+	err = json.Unmarshal(msg.Payload, event)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("[Task Export] Starting task export %d for user %d...", event.ExportID, event.User.ID)
+
+	s := db.NewSession()
+	defer s.Close()
+	err = s.Begin()
+	if err != nil {
+		return
+	}
+
+	u, err := user.GetUserByID(s, event.User.ID)
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	te := &TaskExport{}
+	has, err := s.Where("id = ? AND user_id = ?", event.ExportID, u.ID).Get(te)
+	if err != nil || !has {
+		_ = s.Rollback()
+		return err
+	}
+
+	data, err := createExportFile(s, u, te)
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	err = s.Commit()
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("[Task Export] Done with task export %d for user %d", event.ExportID, event.User.ID)
+
+	return notifyExportReady(u, te, data)
+}
@@ -0,0 +1,54 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package export
+
+import (
+	"strconv"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+)
+
+// ExportReadyNotification represents a ExportReadyNotification notification
+type ExportReadyNotification struct {
+	User    *user.User      `json:"user"`
+	Export  *TaskExport     `json:"export"`
+	Project *models.Project `json:"project"`
+}
+
+// ToMail returns the mail notification for ExportReadyNotification
+func (n *ExportReadyNotification) ToMail() *notifications.Mail {
+	return notifications.NewMail().
+		Subject("Your export of "+n.Project.Title+" is ready").
+		Greeting("Hi "+n.User.GetName()+",").
+		Line("The export of "+n.Project.Title+" you requested is ready for you to download. Click the button below to download it:").
+		Action("Download", config.ServicePublicURL.GetString()+"projects/"+strconv.FormatInt(n.Export.ProjectID, 10)+"/export/"+strconv.FormatInt(n.Export.ID, 10)).
+		Line("The download will be available for the next 7 days.").
+		Line("Have a nice day!")
+}
+
+// ToDB returns the ExportReadyNotification notification in a format which can be saved in the db
+func (n *ExportReadyNotification) ToDB() interface{} {
+	return nil
+}
+
+// Name returns the name of the notification
+func (n *ExportReadyNotification) Name() string {
+	return "task.export.ready"
+}
@@ -0,0 +1,204 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package export
+
+import (
+	"bytes"
+	"time"
+
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/events"
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/notifications"
+	"code.vikunja.io/api/pkg/user"
+
+	"xorm.io/xorm"
+)
+
+// TaskExport is an export of a project or saved filter which is created in the background.
+type TaskExport struct {
+	// The unique, numeric id of this export.
+	ID int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	// The project or saved filter this export was created for.
+	ProjectID int64 `xorm:"bigint not null INDEX" json:"project_id"`
+	// The format of this export. Can be `csv`, `ics`, `md` or `todotxt`.
+	Format Format `xorm:"varchar(20) not null" json:"format"`
+	// Whether the export file was created and can be downloaded.
+	Done bool `xorm:"not null default false" json:"done"`
+
+	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
+	FileID int64 `xorm:"bigint null" json:"-"`
+
+	// A timestamp when this export was requested. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the table name for task exports
+func (*TaskExport) TableName() string {
+	return "task_exports"
+}
+
+// GetTables returns all structs which are also a table.
+func GetTables() []interface{} {
+	return []interface{}{
+		&TaskExport{},
+	}
+}
+
+// RequestExport creates a new export which is then created in the background. The user gets an email once it is ready.
+func RequestExport(s *xorm.Session, u *user.User, projectID int64, format Format) (te *TaskExport, err error) {
+	err = format.Validate()
+	if err != nil {
+		return
+	}
+
+	_, err = checkProjectAccess(s, u, projectID)
+	if err != nil {
+		return
+	}
+
+	te = &TaskExport{
+		ProjectID: projectID,
+		Format:    format,
+		UserID:    u.ID,
+	}
+	_, err = s.Insert(te)
+	if err != nil {
+		return nil, err
+	}
+
+	err = events.Dispatch(&TaskExportRequestedEvent{
+		User:     u,
+		ExportID: te.ID,
+	})
+	return
+}
+
+// GetExportByID returns an export of the user.
+func GetExportByID(s *xorm.Session, u *user.User, projectID, exportID int64) (te *TaskExport, err error) {
+	te = &TaskExport{}
+	has, err := s.
+		Where("id = ? AND project_id = ? AND user_id = ?", exportID, projectID, u.ID).
+		Get(te)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, &models.ErrExportDoesNotExist{ExportID: exportID}
+	}
+	return
+}
+
+// GetExportFile returns the file of an export which is done.
+func GetExportFile(s *xorm.Session, u *user.User, projectID, exportID int64) (f *files.File, err error) {
+	te, err := GetExportByID(s, u, projectID, exportID)
+	if err != nil {
+		return nil, err
+	}
+	if !te.Done {
+		return nil, &models.ErrExportNotReady{ExportID: exportID}
+	}
+
+	f = &files.File{ID: te.FileID}
+	err = f.LoadFileMetaByID()
+	if err != nil {
+		return nil, err
+	}
+	err = f.LoadFileByID()
+	return
+}
+
+func createExportFile(s *xorm.Session, u *user.User, te *TaskExport) (data *Data, err error) {
+	data, err = GetData(s, u, te.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	err = Write(buf, te.Format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := files.CreateWithMimeAndSession(s, buf, data.FileName(te.Format), uint64(buf.Len()), u, te.Format.MimeType(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	te.FileID = f.ID
+	te.Done = true
+	_, err = s.ID(te.ID).Cols("file_id", "done").Update(te)
+	return
+}
+
+// RegisterOldExportCleanupCron removes all exports which are older than a week.
+func RegisterOldExportCleanupCron() {
+	const logPrefix = "[Task Export Cleanup Cron] "
+
+	err := cron.Schedule("30 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		exports := []*TaskExport{}
+		err := s.Where("created < ?", time.Now().Add(-time.Hour*24*7)).Find(&exports)
+		if err != nil {
+			log.Errorf(logPrefix+"Could not get old exports: %s", err)
+			return
+		}
+
+		if len(exports) == 0 {
+			return
+		}
+
+		log.Debugf(logPrefix+"Removing %d old task exports...", len(exports))
+
+		ids := make([]int64, 0, len(exports))
+		for _, te := range exports {
+			if te.FileID != 0 {
+				f := &files.File{ID: te.FileID}
+				err = f.Delete()
+				if err != nil && !files.IsErrFileDoesNotExist(err) {
+					log.Errorf(logPrefix+"Could not remove file of task export %d: %s", te.ID, err)
+					return
+				}
+			}
+			ids = append(ids, te.ID)
+		}
+
+		_, err = s.In("id", ids).Delete(&TaskExport{})
+		if err != nil {
+			log.Errorf(logPrefix+"Could not remove old task exports: %s", err)
+			return
+		}
+
+		log.Debugf(logPrefix+"Removed %d old task exports", len(exports))
+	})
+	if err != nil {
+		log.Fatalf("Could not register old task export cleanup cron: %s", err)
+	}
+}
+
+func notifyExportReady(u *user.User, te *TaskExport, data *Data) error {
+	return notifications.Notify(u, &ExportReadyNotification{
+		User:    u,
+		Export:  te,
+		Project: data.Project,
+	})
+}
@@ -17,6 +17,8 @@
 package keyvalue
 
 import (
+	"time"
+
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/modules/keyvalue/memory"
 	"code.vikunja.io/api/pkg/modules/keyvalue/redis"
@@ -25,6 +27,7 @@ import (
 // Storage defines an interface for saving key-value pairs
 type Storage interface {
 	Put(key string, value interface{}) (err error)
+	PutWithExpiry(key string, value interface{}, expiry time.Duration) (err error)
 	Get(key string) (value interface{}, exists bool, err error)
 	GetWithValue(key string, value interface{}) (exists bool, err error)
 	Del(key string) (err error)
@@ -51,6 +54,11 @@ func Put(key string, value interface{}) error {
 	return store.Put(key, value)
 }
 
+// PutWithExpiry puts a value in the storage backend which is removed again after the expiry passed
+func PutWithExpiry(key string, value interface{}, expiry time.Duration) error {
+	return store.PutWithExpiry(key, value, expiry)
+}
+
 // Get returns a value from a storage backend
 func Get(key string) (value interface{}, exists bool, err error) {
 	return store.Get(key)
@@ -19,28 +19,49 @@ package memory
 import (
 	"reflect"
 	"sync"
+	"time"
 
 	e "code.vikunja.io/api/pkg/modules/keyvalue/error"
 )
 
+// How often expired values are removed from the storage. Expired values are never returned, this only
+// makes sure values which are never read again don't pile up.
+const expirySweepInterval = time.Minute
+
 // Storage is the memory implementation of a storage backend
 type Storage struct {
-	store map[string]interface{}
-	mutex sync.Mutex
+	store     map[string]interface{}
+	expiries  map[string]time.Time
+	lastSweep time.Time
+	mutex     sync.Mutex
 }
 
 // NewStorage creates a new memory storage
 func NewStorage() *Storage {
 	s := &Storage{}
 	s.store = make(map[string]interface{})
+	s.expiries = make(map[string]time.Time)
 	return s
 }
 
 // Put puts a value into the memory storage
 func (s *Storage) Put(key string, value interface{}) (err error) {
+	return s.PutWithExpiry(key, value, 0)
+}
+
+// PutWithExpiry puts a value into the memory storage which is removed again once the expiry passed.
+// An expiry of 0 keeps the value forever.
+func (s *Storage) PutWithExpiry(key string, value interface{}, expiry time.Duration) (err error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	s.removeExpired()
+
+	delete(s.expiries, key)
+	if expiry > 0 {
+		s.expiries[key] = time.Now().Add(expiry)
+	}
+
 	val := reflect.ValueOf(value)
 	// Make sure to store the underlying value when value is a pointer to a value
 	if val.Kind() == reflect.Ptr {
@@ -52,11 +73,33 @@ func (s *Storage) Put(key string, value interface{}) (err error) {
 	return nil
 }
 
+// removeExpired deletes all values whose expiry passed. It needs to be called with the mutex locked.
+func (s *Storage) removeExpired() {
+	now := time.Now()
+	if now.Sub(s.lastSweep) < expirySweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, expires := range s.expiries {
+		if expires.Before(now) {
+			delete(s.store, key)
+			delete(s.expiries, key)
+		}
+	}
+}
+
 // Get retrieves a saved value from memory storage
 func (s *Storage) Get(key string) (value interface{}, exists bool, err error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	if expires, has := s.expiries[key]; has && expires.Before(time.Now()) {
+		delete(s.store, key)
+		delete(s.expiries, key)
+		return nil, false, nil
+	}
+
 	value, exists = s.store[key]
 	return
 }
@@ -85,6 +128,7 @@ func (s *Storage) Del(key string) (err error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 	delete(s.store, key)
+	delete(s.expiries, key)
 	return nil
 }
 
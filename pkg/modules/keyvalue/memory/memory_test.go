@@ -0,0 +1,68 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package memory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorage_PutWithExpiry(t *testing.T) {
+	t.Run("not expired", func(t *testing.T) {
+		s := NewStorage()
+		require.NoError(t, s.PutWithExpiry("key", "value", time.Hour))
+
+		value, exists, err := s.Get("key")
+		require.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "value", value)
+	})
+	t.Run("expired", func(t *testing.T) {
+		s := NewStorage()
+		require.NoError(t, s.PutWithExpiry("key", "value", time.Nanosecond))
+		time.Sleep(time.Millisecond)
+
+		_, exists, err := s.Get("key")
+		require.NoError(t, err)
+		assert.False(t, exists)
+		assert.Empty(t, s.store)
+	})
+	t.Run("expired values are removed when putting", func(t *testing.T) {
+		s := NewStorage()
+		require.NoError(t, s.PutWithExpiry("old", "value", time.Nanosecond))
+		time.Sleep(time.Millisecond)
+		s.lastSweep = time.Time{}
+
+		require.NoError(t, s.PutWithExpiry("new", "value", time.Hour))
+		assert.NotContains(t, s.store, "old")
+		assert.Contains(t, s.store, "new")
+	})
+	t.Run("put without expiry keeps the value", func(t *testing.T) {
+		s := NewStorage()
+		require.NoError(t, s.PutWithExpiry("key", "value", time.Nanosecond))
+		require.NoError(t, s.Put("key", "other"))
+		time.Sleep(time.Millisecond)
+
+		value, exists, err := s.Get("key")
+		require.NoError(t, err)
+		assert.True(t, exists)
+		assert.Equal(t, "other", value)
+	})
+}
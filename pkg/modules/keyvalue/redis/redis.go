@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/gob"
 	"errors"
+	"time"
 
 	"code.vikunja.io/api/pkg/red"
 	"github.com/redis/go-redis/v9"
@@ -42,6 +43,12 @@ func NewStorage() *Storage {
 
 // Put puts a value into redis
 func (s *Storage) Put(key string, value interface{}) (err error) {
+	return s.PutWithExpiry(key, value, 0)
+}
+
+// PutWithExpiry puts a value into redis which redis removes again once the expiry passed.
+// An expiry of 0 keeps the value forever.
+func (s *Storage) PutWithExpiry(key string, value interface{}, expiry time.Duration) (err error) {
 
 	var v interface{}
 
@@ -63,10 +70,10 @@ func (s *Storage) Put(key string, value interface{}) (err error) {
 		if err != nil {
 			return err
 		}
-		return s.client.Set(context.Background(), key, buf.Bytes(), 0).Err()
+		return s.client.Set(context.Background(), key, buf.Bytes(), expiry).Err()
 	}
 
-	return s.client.Set(context.Background(), key, v, 0).Err()
+	return s.client.Set(context.Background(), key, v, expiry).Err()
 }
 
 // Get retrieves a saved value from redis
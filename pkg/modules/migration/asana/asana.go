@@ -0,0 +1,461 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package asana
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
+	"code.vikunja.io/api/pkg/user"
+)
+
+const logPrefix = "[Asana Migration] "
+
+const dateFormat = "2006-01-02"
+
+// The columns of the csv export which are mapped to task fields, all other columns are custom fields.
+const (
+	columnID          = "Task ID"
+	columnName        = "Name"
+	columnNotes       = "Notes"
+	columnSection     = "Section/Column"
+	columnProjects    = "Projects"
+	columnParent      = "Parent task"
+	columnTags        = "Tags"
+	columnCompletedAt = "Completed At"
+	columnDueDate     = "Due Date"
+	columnStartDate   = "Start Date"
+	columnBlockedBy   = "Blocked By (Dependencies)"
+)
+
+// Columns of the csv export which are neither mapped nor useful as custom fields.
+var ignoredColumns = map[string]bool{
+	"Created At":              true,
+	"Last Modified":           true,
+	"Assignee":                true,
+	"Assignee Email":          true,
+	"Blocking (Dependencies)": true,
+}
+
+// FileMigrator imports tasks from an Asana json or csv project export.
+type FileMigrator struct {
+}
+
+// asanaTask is the format independent representation of an Asana task.
+type asanaTask struct {
+	ID           string
+	Name         string
+	Notes        string
+	Completed    bool
+	CompletedAt  time.Time
+	DueDate      time.Time
+	StartDate    time.Time
+	Project      string
+	Section      string
+	Tags         []string
+	ParentID     string
+	ParentName   string
+	BlockedBy    []string
+	CustomFields []*migration.CustomField
+}
+
+// Name is used to get the name of the asana migration - we're using the docs here to annotate the status route.
+// @Summary Get migration status
+// @Description Returns if the current user already did the migation or not. This is useful to show a confirmation message in the frontend if the user is trying to do the same migration again.
+// @tags migration
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} migration.Status "The migration status"
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/asana/status [get]
+func (m *FileMigrator) Name() string {
+	return "asana"
+}
+
+// Migrate takes an asana export, parses it and imports everything in it into Vikunja.
+// @Summary Import all tasks from an Asana export
+// @Description Imports all projects, sections, tasks, subtasks, tags and custom fields from an Asana json or csv export into Vikunja. Sections become kanban buckets, custom fields are added to the task description.
+// @tags migration
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security JWTKeyAuth
+// @Param import formData string true "The Asana json or csv export."
+// @Success 200 {object} models.Message "A message telling you everything was migrated successfully."
+// @Failure 400 {object} web.HTTPError "The file could not be parsed."
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/asana/migrate [post]
+func (m *FileMigrator) Migrate(u *user.User, file io.ReaderAt, size int64) error {
+	content, err := io.ReadAll(io.NewSectionReader(file, 0, size))
+	if err != nil {
+		return err
+	}
+
+	tasks, err := parseExport(content)
+	if err != nil {
+		return err
+	}
+
+	if len(tasks) == 0 {
+		return &models.ErrInvalidImportFile{Reason: "the file does not contain any tasks"}
+	}
+
+	log.Debugf(logPrefix+"Importing %d tasks for user %d", len(tasks), u.ID)
+
+	return migration.InsertFromStructure(convertAsanaTasksToVikunja(tasks), u)
+}
+
+func parseExport(content []byte) ([]*asanaTask, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return nil, &models.ErrInvalidImportFile{Reason: "the file is empty"}
+	}
+	if trimmed[0] == '{' {
+		return parseJSON(trimmed)
+	}
+	return parseCSV(trimmed)
+}
+
+func parseDate(value string, layout string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		log.Debugf(logPrefix+"Could not parse date %s: %s", value, err)
+		return time.Time{}
+	}
+	return t
+}
+
+func splitList(value string) (list []string) {
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return
+}
+
+func parseCSV(content []byte) (tasks []*asanaTask, err error) {
+	header, records, err := migration.ReadCSV(content, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	hasNameColumn := false
+	for _, column := range header {
+		if column == columnName {
+			hasNameColumn = true
+		}
+	}
+	if !hasNameColumn {
+		return nil, &models.ErrInvalidImportFile{Reason: "the csv file does not have a " + columnName + " column"}
+	}
+
+	for _, record := range records {
+		task := &asanaTask{
+			ID:          strings.TrimSpace(record[columnID]),
+			Name:        strings.TrimSpace(record[columnName]),
+			Notes:       record[columnNotes],
+			CompletedAt: parseDate(record[columnCompletedAt], dateFormat),
+			DueDate:     parseDate(record[columnDueDate], dateFormat),
+			StartDate:   parseDate(record[columnStartDate], dateFormat),
+			Section:     strings.TrimSpace(record[columnSection]),
+			Tags:        splitList(record[columnTags]),
+			ParentName:  strings.TrimSpace(record[columnParent]),
+			BlockedBy:   splitList(record[columnBlockedBy]),
+		}
+		task.Completed = !task.CompletedAt.IsZero()
+		if projects := splitList(record[columnProjects]); len(projects) > 0 {
+			task.Project = projects[0]
+		}
+
+		for _, column := range header {
+			switch column {
+			case columnID, columnName, columnNotes, columnSection, columnProjects, columnParent, columnTags,
+				columnCompletedAt, columnDueDate, columnStartDate, columnBlockedBy:
+				continue
+			}
+			if ignoredColumns[column] {
+				continue
+			}
+			task.CustomFields = append(task.CustomFields, &migration.CustomField{Name: column, Value: record[column]})
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return
+}
+
+type jsonExport struct {
+	Data []*jsonTask `json:"data"`
+}
+
+type jsonReference struct {
+	GID  string `json:"gid"`
+	Name string `json:"name"`
+}
+
+type jsonTask struct {
+	GID         string     `json:"gid"`
+	Name        string     `json:"name"`
+	Notes       string     `json:"notes"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completed_at"`
+	DueOn       string     `json:"due_on"`
+	DueAt       *time.Time `json:"due_at"`
+	StartOn     string     `json:"start_on"`
+	Memberships []struct {
+		Project *jsonReference `json:"project"`
+		Section *jsonReference `json:"section"`
+	} `json:"memberships"`
+	Projects     []*jsonReference `json:"projects"`
+	Tags         []*jsonReference `json:"tags"`
+	Parent       *jsonReference   `json:"parent"`
+	Dependencies []*jsonReference `json:"dependencies"`
+	CustomFields []struct {
+		Name         string `json:"name"`
+		DisplayValue string `json:"display_value"`
+	} `json:"custom_fields"`
+	Subtasks []*jsonTask `json:"subtasks"`
+}
+
+func parseJSON(content []byte) (tasks []*asanaTask, err error) {
+	export := &jsonExport{}
+	err = json.Unmarshal(content, export)
+	if err != nil {
+		return nil, &models.ErrInvalidImportFile{Reason: "could not parse the json: " + err.Error()}
+	}
+
+	var add func(t *jsonTask, parentID string)
+	add = func(t *jsonTask, parentID string) {
+		task := &asanaTask{
+			ID:        t.GID,
+			Name:      strings.TrimSpace(t.Name),
+			Notes:     t.Notes,
+			Completed: t.Completed,
+			DueDate:   parseDate(t.DueOn, dateFormat),
+			StartDate: parseDate(t.StartOn, dateFormat),
+			ParentID:  parentID,
+		}
+		if t.CompletedAt != nil {
+			task.CompletedAt = *t.CompletedAt
+		}
+		if t.DueAt != nil {
+			task.DueDate = *t.DueAt
+		}
+		if t.Parent != nil && task.ParentID == "" {
+			task.ParentID = t.Parent.GID
+		}
+		for _, membership := range t.Memberships {
+			if membership.Project == nil {
+				continue
+			}
+			task.Project = membership.Project.Name
+			if membership.Section != nil {
+				task.Section = membership.Section.Name
+			}
+			break
+		}
+		if task.Project == "" && len(t.Projects) > 0 {
+			task.Project = t.Projects[0].Name
+		}
+		for _, tag := range t.Tags {
+			task.Tags = append(task.Tags, tag.Name)
+		}
+		for _, dependency := range t.Dependencies {
+			task.BlockedBy = append(task.BlockedBy, dependency.GID)
+		}
+		for _, field := range t.CustomFields {
+			task.CustomFields = append(task.CustomFields, &migration.CustomField{Name: field.Name, Value: field.DisplayValue})
+		}
+
+		tasks = append(tasks, task)
+
+		for _, subtask := range t.Subtasks {
+			add(subtask, t.GID)
+		}
+	}
+
+	for _, t := range export.Data {
+		add(t, "")
+	}
+
+	return
+}
+
+// convertAsanaTasksToVikunja creates a project for every Asana project as child of a "Migrated from Asana" project.
+// Sections become buckets, subtasks are related to their parent task.
+func convertAsanaTasksToVikunja(tasks []*asanaTask) (result []*models.ProjectWithTasksAndBuckets) {
+	var pseudoParentID int64 = 1
+	result = []*models.ProjectWithTasksAndBuckets{
+		{
+			Project: models.Project{
+				ID:    pseudoParentID,
+				Title: "Migrated from Asana",
+			},
+			SourceID: migration.RootProjectSourceID,
+		},
+	}
+
+	// Task ids are only unique within Asana, names are used to reference tasks in the csv export.
+	idsByAsanaID := make(map[string]int64, len(tasks))
+	idsByName := make(map[string]int64, len(tasks))
+	tasksByID := make(map[int64]*asanaTask, len(tasks))
+	for index, t := range tasks {
+		id := int64(index + 1)
+		tasksByID[id] = t
+		if t.ID != "" {
+			idsByAsanaID[t.ID] = id
+		}
+		if _, has := idsByName[t.Name]; !has {
+			idsByName[t.Name] = id
+		}
+	}
+	findTask := func(reference string) (int64, bool) {
+		if id, has := idsByAsanaID[reference]; has {
+			return id, true
+		}
+		id, has := idsByName[reference]
+		return id, has
+	}
+
+	parentIDs := make(map[int64]int64)
+	for index, t := range tasks {
+		reference := t.ParentID
+		if reference == "" {
+			reference = t.ParentName
+		}
+		if reference == "" {
+			continue
+		}
+		parentID, has := findTask(reference)
+		if !has {
+			log.Debugf(logPrefix+"Could not find parent %s of task %s", reference, t.Name)
+			continue
+		}
+		parentIDs[int64(index+1)] = parentID
+	}
+
+	// Subtasks are usually not part of a project in Asana, they're put in the project of their parent instead.
+	projectOf := func(id int64) string {
+		for depth := 0; depth < len(tasks); depth++ {
+			if tasksByID[id].Project != "" {
+				return tasksByID[id].Project
+			}
+			parentID, has := parentIDs[id]
+			if !has {
+				break
+			}
+			id = parentID
+		}
+		return "Asana"
+	}
+
+	projects := make(map[string]*models.ProjectWithTasksAndBuckets)
+	bucketIDs := make(map[string]map[string]int64)
+	vikunjaTasks := make(map[int64]*models.TaskWithComments, len(tasks))
+	var bucketID int64 = 1
+
+	for index, t := range tasks {
+		id := int64(index + 1)
+		projectTitle := projectOf(id)
+		project, has := projects[projectTitle]
+		if !has {
+			project = &models.ProjectWithTasksAndBuckets{
+				Project: models.Project{
+					ID:              int64(len(projects)+1) + pseudoParentID,
+					ParentProjectID: pseudoParentID,
+					Title:           projectTitle,
+				},
+				// Projects are only referenced by their name in Asana exports
+				SourceID: projectTitle,
+			}
+			projects[projectTitle] = project
+			bucketIDs[projectTitle] = make(map[string]int64)
+			result = append(result, project)
+		}
+
+		task := &models.TaskWithComments{
+			Task: models.Task{
+				ID:          id,
+				Title:       t.Name,
+				Description: migration.PlainTextToHTML(t.Notes) + migration.CustomFieldsToHTML(t.CustomFields),
+				Done:        t.Completed,
+				DoneAt:      t.CompletedAt,
+				DueDate:     t.DueDate,
+				StartDate:   t.StartDate,
+			},
+			SourceID: t.ID,
+		}
+
+		if t.Section != "" {
+			sectionBucketID, has := bucketIDs[projectTitle][t.Section]
+			if !has {
+				sectionBucketID = bucketID
+				bucketID++
+				bucketIDs[projectTitle][t.Section] = sectionBucketID
+				project.Buckets = append(project.Buckets, &models.Bucket{
+					ID:    sectionBucketID,
+					Title: t.Section,
+				})
+			}
+			task.BucketID = sectionBucketID
+		}
+
+		for _, tag := range t.Tags {
+			task.Labels = append(task.Labels, &models.Label{Title: tag})
+		}
+
+		for _, reference := range t.BlockedBy {
+			blockingID, has := findTask(reference)
+			if !has || blockingID == id {
+				continue
+			}
+			addRelation(task, models.RelationKindBlocked, blockingID)
+		}
+
+		vikunjaTasks[id] = task
+		project.Tasks = append(project.Tasks, task)
+	}
+
+	for id := int64(1); id <= int64(len(tasks)); id++ {
+		parentID, has := parentIDs[id]
+		if !has || parentID == id {
+			continue
+		}
+		addRelation(vikunjaTasks[parentID], models.RelationKindSubtask, id)
+	}
+
+	return
+}
+
+func addRelation(task *models.TaskWithComments, kind models.RelationKind, otherID int64) {
+	if task.RelatedTasks == nil {
+		task.RelatedTasks = make(models.RelatedTaskMap)
+	}
+	task.RelatedTasks[kind] = append(task.RelatedTasks[kind], &models.Task{ID: otherID})
+}
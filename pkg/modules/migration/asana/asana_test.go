@@ -0,0 +1,175 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package asana
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertAsanaCSV(t *testing.T) {
+	content, err := os.ReadFile("export.csv")
+	require.NoError(t, err)
+	tasks, err := parseExport(content)
+	require.NoError(t, err)
+	require.Len(t, tasks, 4)
+
+	structure := convertAsanaTasksToVikunja(tasks)
+	require.Len(t, structure, 3)
+	assert.Equal(t, "Migrated from Asana", structure[0].Title)
+	assert.Equal(t, "Product Launch", structure[1].Title)
+	assert.Equal(t, structure[0].ID, structure[1].ParentProjectID)
+	assert.Equal(t, "Website", structure[2].Title)
+
+	launch := structure[1]
+	assert.Equal(t, "Product Launch", launch.SourceID)
+	require.Len(t, launch.Tasks, 3)
+	require.Len(t, launch.Buckets, 2)
+	assert.Equal(t, "Planning", launch.Buckets[0].Title)
+	assert.Equal(t, "Done", launch.Buckets[1].Title)
+
+	plan := launch.Tasks[0]
+	assert.Equal(t, "Plan launch event", plan.Title)
+	assert.Equal(t, "1207437651390001", plan.SourceID)
+	assert.Equal(t, "<p>Find a venue.</p><p>Send invitations afterwards.</p><ul><li><strong>Estimated hours</strong>: 8</li><li><strong>Stage</strong>: Draft</li></ul>", plan.Description)
+	assert.Equal(t, launch.Buckets[0].ID, plan.BucketID)
+	assert.Equal(t, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), plan.StartDate)
+	assert.Equal(t, time.Date(2024, 6, 20, 0, 0, 0, 0, time.UTC), plan.DueDate)
+	require.Len(t, plan.Labels, 2)
+	assert.Equal(t, "events", plan.Labels[0].Title)
+	assert.Equal(t, "marketing", plan.Labels[1].Title)
+	assert.Equal(t, models.RelatedTaskMap{
+		models.RelationKindSubtask: {{ID: 2}},
+	}, plan.RelatedTasks)
+
+	// The subtask does not have a project in the export and is put into the one of its parent
+	venue := launch.Tasks[1]
+	assert.Equal(t, "Book venue", venue.Title)
+	assert.Equal(t, int64(0), venue.BucketID)
+
+	flyers := launch.Tasks[2]
+	assert.True(t, flyers.Done)
+	assert.Equal(t, time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC), flyers.DoneAt)
+	assert.Equal(t, models.RelatedTaskMap{
+		models.RelationKindBlocked: {{ID: 1}},
+	}, flyers.RelatedTasks)
+}
+
+func TestConvertAsanaJSON(t *testing.T) {
+	content, err := os.ReadFile("export.json")
+	require.NoError(t, err)
+	tasks, err := parseExport(content)
+	require.NoError(t, err)
+	require.Len(t, tasks, 3)
+
+	structure := convertAsanaTasksToVikunja(tasks)
+	require.Len(t, structure, 2)
+	release := structure[1]
+	assert.Equal(t, "Release 2.0", release.Title)
+	require.Len(t, release.Tasks, 3)
+	require.Len(t, release.Buckets, 2)
+	assert.Equal(t, "In Progress", release.Buckets[0].Title)
+	assert.Equal(t, "To Do", release.Buckets[1].Title)
+
+	notes := release.Tasks[0]
+	assert.Equal(t, "<p>Summarize all changes.</p><ul><li><strong>Priority</strong>: High</li></ul>", notes.Description)
+	assert.Equal(t, time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC), notes.DueDate)
+	require.Len(t, notes.Labels, 1)
+	assert.Equal(t, "docs", notes.Labels[0].Title)
+	assert.Equal(t, models.RelatedTaskMap{
+		models.RelationKindSubtask: {{ID: 2}},
+	}, notes.RelatedTasks)
+
+	changes := release.Tasks[1]
+	assert.Equal(t, "Collect changes", changes.Title)
+	assert.True(t, changes.Done)
+	assert.Equal(t, time.Date(2024, 6, 10, 14, 30, 0, 0, time.UTC), changes.DoneAt)
+	assert.Equal(t, time.Date(2024, 6, 11, 12, 0, 0, 0, time.UTC), changes.DueDate)
+
+	publish := release.Tasks[2]
+	assert.Equal(t, release.Buckets[1].ID, publish.BucketID)
+	assert.Equal(t, models.RelatedTaskMap{
+		models.RelationKindBlocked: {{ID: 1}},
+	}, publish.RelatedTasks)
+}
+
+func TestParseExport(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		_, err := parseExport([]byte(""))
+		require.Error(t, err)
+		assert.True(t, models.IsErrInvalidImportFile(err))
+	})
+	t.Run("csv without name column", func(t *testing.T) {
+		_, err := parseExport([]byte("Title,Notes\nTest,Lorem"))
+		require.Error(t, err)
+		assert.True(t, models.IsErrInvalidImportFile(err))
+	})
+}
+
+func TestFileMigrator_Migrate(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+
+	u := &user.User{ID: 1}
+	content, err := os.ReadFile("export.csv")
+	require.NoError(t, err)
+	m := &FileMigrator{}
+	err = m.Migrate(u, strings.NewReader(string(content)), int64(len(content)))
+	require.NoError(t, err)
+
+	s := db.NewSession()
+	defer s.Close()
+
+	getTask := func(title string) *models.Task {
+		task := &models.Task{}
+		has, err := s.Where("title = ?", title).Get(task)
+		require.NoError(t, err)
+		require.True(t, has)
+		return task
+	}
+
+	plan := getTask("Plan launch event")
+	venue := getTask("Book venue")
+	flyers := getTask("Print flyers")
+
+	assert.Equal(t, plan.ProjectID, venue.ProjectID)
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       plan.ID,
+		"other_task_id": venue.ID,
+		"relation_kind": models.RelationKindSubtask,
+	}, false)
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       flyers.ID,
+		"other_task_id": plan.ID,
+		"relation_kind": models.RelationKindBlocked,
+	}, false)
+	db.AssertExists(t, "buckets", map[string]interface{}{
+		"title": "Planning",
+	}, false)
+	db.AssertExists(t, "projects", map[string]interface{}{
+		"title":    "Product Launch",
+		"owner_id": u.ID,
+	}, false)
+}
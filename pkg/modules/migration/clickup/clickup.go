@@ -0,0 +1,435 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package clickup
+
+import (
+	"encoding/json"
+	"html"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
+	"code.vikunja.io/api/pkg/user"
+)
+
+const logPrefix = "[ClickUp Migration] "
+
+// The columns of the csv export which are mapped to task fields.
+const (
+	columnID          = "Task ID"
+	columnName        = "Task Name"
+	columnContent     = "Task Content"
+	columnStatus      = "Status"
+	columnDateCreated = "Date Created"
+	columnDueDate     = "Due Date"
+	columnStartDate   = "Start Date"
+	columnParentID    = "Parent ID"
+	columnAttachments = "Attachments"
+	columnTags        = "Tags"
+	columnPriority    = "Priority"
+	columnList        = "List Name"
+	columnFolder      = "Folder Name"
+	columnSpace       = "Space Name"
+	columnChecklists  = "Checklists"
+	columnComments    = "Comments"
+)
+
+// Columns of the csv export which are neither mapped nor useful as custom fields. All columns ending in "Text"
+// are ignored as well, they contain human readable versions of other columns.
+var ignoredColumns = map[string]bool{
+	"Assignees":         true,
+	"Assigned Comments": true,
+	"Time Estimated":    true,
+	"Time Spent":        true,
+	"Rolled Up Time":    true,
+	"Date Updated":      true,
+	"Date Closed":       true,
+	"Date Done":         true,
+}
+
+var mappedColumns = map[string]bool{
+	columnID:          true,
+	columnName:        true,
+	columnContent:     true,
+	columnStatus:      true,
+	columnDateCreated: true,
+	columnDueDate:     true,
+	columnStartDate:   true,
+	columnParentID:    true,
+	columnAttachments: true,
+	columnTags:        true,
+	columnPriority:    true,
+	columnList:        true,
+	columnFolder:      true,
+	columnSpace:       true,
+	columnChecklists:  true,
+	columnComments:    true,
+}
+
+// Statuses which mark a task as done.
+var doneStatuses = map[string]bool{
+	"complete": true,
+	"closed":   true,
+	"done":     true,
+}
+
+var priorityMap = map[string]int64{
+	"low":    1,
+	"4":      1,
+	"normal": 2,
+	"3":      2,
+	"high":   3,
+	"2":      3,
+	"urgent": 4,
+	"1":      4,
+}
+
+// Custom field columns have their type appended to the name, like "Story Points (number)".
+var customFieldType = regexp.MustCompile(`\s*\([^)]*\)$`)
+
+// ClickUp uses "hidden" as folder name for lists which are not in a folder.
+const hiddenFolder = "hidden"
+
+// FileMigrator imports tasks from a ClickUp csv export.
+type FileMigrator struct {
+}
+
+// Name is used to get the name of the clickup migration - we're using the docs here to annotate the status route.
+// @Summary Get migration status
+// @Description Returns if the current user already did the migation or not. This is useful to show a confirmation message in the frontend if the user is trying to do the same migration again.
+// @tags migration
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} migration.Status "The migration status"
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/clickup/status [get]
+func (m *FileMigrator) Name() string {
+	return "clickup"
+}
+
+// Migrate takes a clickup export, parses it and imports everything in it into Vikunja.
+// @Summary Import all tasks from a ClickUp export
+// @Description Imports all spaces, folders, lists, tasks, subtasks, checklists, comments and custom fields from a ClickUp csv export into Vikunja. Statuses become kanban buckets, custom fields are added to the task description.
+// @tags migration
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security JWTKeyAuth
+// @Param import formData string true "The ClickUp csv export."
+// @Success 200 {object} models.Message "A message telling you everything was migrated successfully."
+// @Failure 400 {object} web.HTTPError "The file could not be parsed."
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/clickup/migrate [post]
+func (m *FileMigrator) Migrate(u *user.User, file io.ReaderAt, size int64) error {
+	content, err := io.ReadAll(io.NewSectionReader(file, 0, size))
+	if err != nil {
+		return err
+	}
+
+	header, records, err := migration.ReadCSV(content, 0)
+	if err != nil {
+		return err
+	}
+
+	structure, err := convertClickUpTasksToVikunja(header, records)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf(logPrefix+"Importing %d tasks for user %d", len(records), u.ID)
+
+	return migration.InsertFromStructure(structure, u)
+}
+
+// parseTimestamp parses the unix timestamps in milliseconds ClickUp uses for dates.
+func parseTimestamp(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	ms, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Debugf(logPrefix+"Could not parse date %s: %s", value, err)
+		return time.Time{}
+	}
+	return time.UnixMilli(ms).UTC()
+}
+
+// parseList parses lists like tags or attachments which are exported as [a, b].
+func parseList(value string) (list []string) {
+	value = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(value), "["), "]")
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return
+}
+
+type checklistItem struct {
+	Name     string `json:"name"`
+	Resolved bool   `json:"resolved"`
+}
+
+// parseChecklists parses the checklists of a task. They are exported as a json object with the checklist names
+// as keys and the items either as plain strings or as objects.
+func parseChecklists(value string) (items []*models.TaskChecklistItem) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	checklists := make(map[string][]json.RawMessage)
+	if err := json.Unmarshal([]byte(value), &checklists); err != nil {
+		log.Debugf(logPrefix+"Could not parse checklists %s: %s", value, err)
+		return nil
+	}
+
+	names := make([]string, 0, len(checklists))
+	for name := range checklists {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		for _, raw := range checklists[name] {
+			item := &checklistItem{}
+			if json.Unmarshal(raw, &item.Name) != nil {
+				if err := json.Unmarshal(raw, item); err != nil {
+					continue
+				}
+			}
+			title := item.Name
+			if len(checklists) > 1 {
+				title = name + ": " + title
+			}
+			items = append(items, &models.TaskChecklistItem{
+				Title: title,
+				Done:  item.Resolved,
+			})
+		}
+	}
+
+	return
+}
+
+type comment struct {
+	Text string          `json:"text"`
+	By   string          `json:"by"`
+	Date json.RawMessage `json:"date"`
+}
+
+func parseComments(value string) (comments []*models.TaskComment) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+
+	raw := []*comment{}
+	if err := json.Unmarshal([]byte(value), &raw); err != nil {
+		log.Debugf(logPrefix+"Could not parse comments %s: %s", value, err)
+		return nil
+	}
+
+	for _, c := range raw {
+		if strings.TrimSpace(c.Text) == "" {
+			continue
+		}
+		text := migration.PlainTextToHTML(c.Text)
+		if c.By != "" {
+			text = "<p><em>" + html.EscapeString(c.By) + "</em>:</p>" + text
+		}
+		created := parseTimestamp(strings.Trim(string(c.Date), `"`))
+		comments = append(comments, &models.TaskComment{
+			Comment: text,
+			Created: created,
+			Updated: created,
+		})
+	}
+
+	return
+}
+
+func isTextColumn(column string) bool {
+	return strings.HasSuffix(column, " Text")
+}
+
+// projectPath returns the path of the project a task belongs to, starting with the space.
+func projectPath(record map[string]string) (path []string) {
+	for _, column := range []string{columnSpace, columnFolder, columnList} {
+		name := strings.TrimSpace(record[column])
+		if name == "" || (column == columnFolder && strings.EqualFold(name, hiddenFolder)) {
+			continue
+		}
+		path = append(path, name)
+	}
+	if len(path) == 0 {
+		path = []string{"ClickUp"}
+	}
+	return
+}
+
+// convertClickUpTasksToVikunja creates a project for every space, folder and list as children of a
+// "Migrated from ClickUp" project. Statuses become buckets, subtasks are related to their parent task.
+func convertClickUpTasksToVikunja(header []string, records []map[string]string) (result []*models.ProjectWithTasksAndBuckets, err error) {
+	hasNameColumn := false
+	for _, column := range header {
+		if column == columnName {
+			hasNameColumn = true
+		}
+	}
+	if !hasNameColumn {
+		return nil, &models.ErrInvalidImportFile{Reason: "the csv file does not have a " + columnName + " column"}
+	}
+	if len(records) == 0 {
+		return nil, &models.ErrInvalidImportFile{Reason: "the file does not contain any tasks"}
+	}
+
+	var pseudoParentID int64 = 1
+	result = []*models.ProjectWithTasksAndBuckets{
+		{
+			Project: models.Project{
+				ID:    pseudoParentID,
+				Title: "Migrated from ClickUp",
+			},
+			SourceID: migration.RootProjectSourceID,
+		},
+	}
+
+	idsByClickUpID := make(map[string]int64, len(records))
+	for index, record := range records {
+		if id := strings.TrimSpace(record[columnID]); id != "" {
+			idsByClickUpID[id] = int64(index + 1)
+		}
+	}
+
+	projects := make(map[string]*models.ProjectWithTasksAndBuckets)
+	getProject := func(path []string) *models.ProjectWithTasksAndBuckets {
+		parentID := pseudoParentID
+		var project *models.ProjectWithTasksAndBuckets
+		for i := range path {
+			key := strings.Join(path[:i+1], "\x00")
+			var has bool
+			project, has = projects[key]
+			if !has {
+				project = &models.ProjectWithTasksAndBuckets{
+					Project: models.Project{
+						ID:              int64(len(projects)+1) + pseudoParentID,
+						ParentProjectID: parentID,
+						Title:           path[i],
+					},
+					// Spaces, folders and lists are only referenced by their name in ClickUp exports
+					SourceID: strings.Join(path[:i+1], "/"),
+				}
+				projects[key] = project
+				result = append(result, project)
+			}
+			parentID = project.ID
+		}
+		return project
+	}
+
+	bucketIDs := make(map[int64]map[string]int64)
+	tasks := make(map[int64]*models.TaskWithComments, len(records))
+	var bucketID int64 = 1
+
+	for index, record := range records {
+		id := int64(index + 1)
+		project := getProject(projectPath(record))
+
+		customFields := []*migration.CustomField{}
+		for _, column := range header {
+			if mappedColumns[column] || ignoredColumns[column] || isTextColumn(column) {
+				continue
+			}
+			customFields = append(customFields, &migration.CustomField{
+				Name:  customFieldType.ReplaceAllString(column, ""),
+				Value: record[column],
+			})
+		}
+
+		task := &models.TaskWithComments{
+			Task: models.Task{
+				ID:             id,
+				Title:          strings.TrimSpace(record[columnName]),
+				Description:    migration.PlainTextToHTML(record[columnContent]) + migration.CustomFieldsToHTML(customFields),
+				Done:           doneStatuses[strings.ToLower(strings.TrimSpace(record[columnStatus]))],
+				DueDate:        parseTimestamp(record[columnDueDate]),
+				StartDate:      parseTimestamp(record[columnStartDate]),
+				Priority:       priorityMap[strings.ToLower(strings.TrimSpace(record[columnPriority]))],
+				ChecklistItems: parseChecklists(record[columnChecklists]),
+			},
+			Comments: parseComments(record[columnComments]),
+			SourceID: strings.TrimSpace(record[columnID]),
+		}
+
+		// Attachments are only linked in the export, we keep the links in the description.
+		for _, link := range parseList(record[columnAttachments]) {
+			task.Description += `<p><a href="` + html.EscapeString(link) + `">` + html.EscapeString(link) + "</a></p>"
+		}
+
+		if status := strings.TrimSpace(record[columnStatus]); status != "" {
+			if bucketIDs[project.ID] == nil {
+				bucketIDs[project.ID] = make(map[string]int64)
+			}
+			statusBucketID, has := bucketIDs[project.ID][status]
+			if !has {
+				statusBucketID = bucketID
+				bucketID++
+				bucketIDs[project.ID][status] = statusBucketID
+				project.Buckets = append(project.Buckets, &models.Bucket{
+					ID:    statusBucketID,
+					Title: status,
+				})
+			}
+			task.BucketID = statusBucketID
+		}
+
+		for _, tag := range parseList(record[columnTags]) {
+			task.Labels = append(task.Labels, &models.Label{Title: tag})
+		}
+
+		tasks[id] = task
+		project.Tasks = append(project.Tasks, task)
+	}
+
+	for index, record := range records {
+		id := int64(index + 1)
+		parent := strings.TrimSpace(record[columnParentID])
+		if parent == "" {
+			continue
+		}
+		parentID, has := idsByClickUpID[parent]
+		if !has || parentID == id {
+			log.Debugf(logPrefix+"Could not find parent %s of task %s", parent, record[columnName])
+			continue
+		}
+		parentTask := tasks[parentID]
+		if parentTask.RelatedTasks == nil {
+			parentTask.RelatedTasks = make(models.RelatedTaskMap)
+		}
+		parentTask.RelatedTasks[models.RelationKindSubtask] = append(parentTask.RelatedTasks[models.RelationKindSubtask], &models.Task{ID: id})
+	}
+
+	return
+}
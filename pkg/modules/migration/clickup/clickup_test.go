@@ -0,0 +1,148 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package clickup
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertClickUpTasks(t *testing.T) {
+	content, err := os.ReadFile("export.csv")
+	require.NoError(t, err)
+	header, records, err := migration.ReadCSV(content, 0)
+	require.NoError(t, err)
+
+	structure, err := convertClickUpTasksToVikunja(header, records)
+	require.NoError(t, err)
+	require.Len(t, structure, 5)
+	assert.Equal(t, "Migrated from ClickUp", structure[0].Title)
+	assert.Equal(t, "Product", structure[1].Title)
+	assert.Equal(t, structure[0].ID, structure[1].ParentProjectID)
+	assert.Equal(t, "Development", structure[2].Title)
+	assert.Equal(t, structure[1].ID, structure[2].ParentProjectID)
+	assert.Equal(t, "Sprint 1", structure[3].Title)
+	assert.Equal(t, "Product/Development/Sprint 1", structure[3].SourceID)
+	assert.Equal(t, structure[2].ID, structure[3].ParentProjectID)
+	// Lists without a folder are directly below their space
+	assert.Equal(t, "Marketing", structure[4].Title)
+	assert.Equal(t, structure[1].ID, structure[4].ParentProjectID)
+
+	sprint := structure[3]
+	require.Len(t, sprint.Tasks, 2)
+	require.Len(t, sprint.Buckets, 2)
+	assert.Equal(t, "in progress", sprint.Buckets[0].Title)
+	assert.Equal(t, "to do", sprint.Buckets[1].Title)
+
+	form := sprint.Tasks[0]
+	assert.Equal(t, "Build signup form", form.Title)
+	assert.Equal(t, "86a3kq1w", form.SourceID)
+	assert.Equal(t, "<p>Fields: name, email</p><p>Needs validation.</p>"+
+		"<ul><li><strong>Story Points</strong>: 5</li><li><strong>Customer</strong>: ACME</li></ul>"+
+		`<p><a href="https://t12345.p.clickup-attachments.com/t12345/mockup.png">https://t12345.p.clickup-attachments.com/t12345/mockup.png</a></p>`, form.Description)
+	assert.False(t, form.Done)
+	assert.Equal(t, int64(3), form.Priority)
+	assert.Equal(t, sprint.Buckets[0].ID, form.BucketID)
+	assert.Equal(t, time.Date(2024, 6, 15, 10, 0, 0, 0, time.UTC), form.DueDate)
+	assert.Equal(t, time.Date(2024, 6, 2, 10, 0, 0, 0, time.UTC), form.StartDate)
+	require.Len(t, form.Labels, 2)
+	assert.Equal(t, "frontend", form.Labels[0].Title)
+	assert.Equal(t, "forms", form.Labels[1].Title)
+	require.Len(t, form.ChecklistItems, 2)
+	assert.Equal(t, "Works on mobile", form.ChecklistItems[0].Title)
+	assert.True(t, form.ChecklistItems[0].Done)
+	assert.Equal(t, "Has tests", form.ChecklistItems[1].Title)
+	assert.False(t, form.ChecklistItems[1].Done)
+	require.Len(t, form.Comments, 1)
+	assert.Equal(t, "<p><em>jane@example.com</em>:</p><p>Please use the new design</p>", form.Comments[0].Comment)
+	assert.Equal(t, time.Date(2024, 6, 1, 14, 0, 0, 0, time.UTC), form.Comments[0].Created)
+	assert.Equal(t, models.RelatedTaskMap{
+		models.RelationKindSubtask: {{ID: 2}},
+	}, form.RelatedTasks)
+
+	captcha := sprint.Tasks[1]
+	assert.Equal(t, int64(2), captcha.Priority)
+	assert.Empty(t, captcha.Labels)
+
+	mail := structure[4].Tasks[0]
+	assert.True(t, mail.Done)
+	assert.Equal(t, int64(4), mail.Priority)
+	require.Len(t, mail.ChecklistItems, 3)
+	assert.Equal(t, "Review: Spelling", mail.ChecklistItems[0].Title)
+	assert.Equal(t, "Review: Links", mail.ChecklistItems[1].Title)
+	assert.Equal(t, "Send: Schedule", mail.ChecklistItems[2].Title)
+}
+
+func TestConvertClickUpTasksInvalid(t *testing.T) {
+	header, records, err := migration.ReadCSV([]byte("Name,Status\nTest,open"), 0)
+	require.NoError(t, err)
+	_, err = convertClickUpTasksToVikunja(header, records)
+	require.Error(t, err)
+	assert.True(t, models.IsErrInvalidImportFile(err))
+}
+
+func TestFileMigrator_Migrate(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+
+	u := &user.User{ID: 1}
+	content, err := os.ReadFile("export.csv")
+	require.NoError(t, err)
+	m := &FileMigrator{}
+	err = m.Migrate(u, strings.NewReader(string(content)), int64(len(content)))
+	require.NoError(t, err)
+
+	s := db.NewSession()
+	defer s.Close()
+
+	form := &models.Task{}
+	has, err := s.Where("title = ?", "Build signup form").Get(form)
+	require.NoError(t, err)
+	require.True(t, has)
+	captcha := &models.Task{}
+	has, err = s.Where("title = ?", "Add captcha").Get(captcha)
+	require.NoError(t, err)
+	require.True(t, has)
+
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       form.ID,
+		"other_task_id": captcha.ID,
+		"relation_kind": models.RelationKindSubtask,
+	}, false)
+	db.AssertExists(t, "task_checklist_items", map[string]interface{}{
+		"task_id": form.ID,
+		"title":   "Works on mobile",
+		"done":    true,
+	}, false)
+	db.AssertExists(t, "task_comments", map[string]interface{}{
+		"task_id": form.ID,
+		"comment": "<p><em>jane@example.com</em>:</p><p>Please use the new design</p>",
+	}, false)
+	db.AssertExists(t, "projects", map[string]interface{}{
+		"title":    "Sprint 1",
+		"owner_id": u.ID,
+	}, false)
+}
@@ -51,6 +51,14 @@ func insertFromStructure(s *xorm.Session, str []*models.ProjectWithTasksAndBucke
 
 	labels := make(map[string]*models.Label)
 	archivedProjects := []int64{}
+	created := &createdTasks{
+		byOldID: make(map[int64]int64),
+	}
+
+	prog, err := newProgress(s, str, user)
+	if err != nil {
+		return err
+	}
 
 	childRelations := make(map[int64][]int64)          // old id is the key, slice of old children ids
 	projectsByOldID := make(map[int64]*models.Project) // old id is the key
@@ -67,13 +75,29 @@ func insertFromStructure(s *xorm.Session, str []*models.ProjectWithTasksAndBucke
 			p.ParentProjectID = 0
 		}
 
+		existing, err := prog.migratedProject(p)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			log.Debugf("[creating structure] Project with old id %d was already migrated as project %d, skipping", oldID, existing.ID)
+			resumeProject(p, existing, &archivedProjects, created, prog)
+			projectsByOldID[oldID] = existing
+			continue
+		}
+
+		err = prog.removePartialProject(p, user)
+		if err != nil {
+			return err
+		}
+
 		p.ID = 0
 
 		for _, view := range p.Views {
 			view.ProjectID = 0
 		}
 
-		err = createProject(s, p, &archivedProjects, labels, user)
+		err = createProject(s, p, &archivedProjects, labels, created, prog, user)
 		if err != nil {
 			return err
 		}
@@ -102,6 +126,43 @@ func insertFromStructure(s *xorm.Session, str []*models.ProjectWithTasksAndBucke
 		}
 	}
 
+	for _, pa := range created.pendingAssignees {
+		err = pa.assignee.Create(s, user)
+		if models.IsErrUserAlreadyAssigned(err) {
+			err = nil
+			continue
+		}
+		if err != nil {
+			err = prog.itemFailed(ItemKindTask, pa.taskSourceID, pa.title, err)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		log.Debugf("[creating structure] Assigned user %d to task %d", pa.assignee.UserID, pa.assignee.TaskID)
+	}
+
+	// Relations to tasks in other projects can only be created once all projects exist
+	for _, rel := range created.pendingRelations {
+		otherTaskID, has := created.byOldID[rel.OtherTaskID]
+		if !has {
+			log.Debugf("[creating structure] could not find related task with old id %d for task %d", rel.OtherTaskID, rel.TaskID)
+			continue
+		}
+
+		oldOtherTaskID := rel.OtherTaskID
+		rel.OtherTaskID = otherTaskID
+		err = rel.Create(s, user)
+		if err != nil && !models.IsErrRelationAlreadyExists(err) {
+			err = prog.itemFailed(ItemKindRelation, sourceID(oldOtherTaskID), string(rel.RelationKind), err)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		log.Debugf("[creating structure] Created task relation between task %d and %d", rel.TaskID, rel.OtherTaskID)
+	}
+
 	if len(archivedProjects) > 0 {
 		_, err = s.
 			Cols("is_archived").
@@ -117,8 +178,56 @@ func insertFromStructure(s *xorm.Session, str []*models.ProjectWithTasksAndBucke
 	return nil
 }
 
-func createProject(s *xorm.Session, project *models.ProjectWithTasksAndBuckets, archivedProjectIDs *[]int64, labels map[string]*models.Label, user *user.User) (err error) {
-	err = createProjectWithEverything(s, project, archivedProjectIDs, labels, user)
+// createdTasks keeps track of all tasks created during a migration across all projects.
+type createdTasks struct {
+	// byOldID maps the id of a task in the migrated structure to the id of the newly created task.
+	byOldID map[int64]int64
+	// pendingRelations holds relations where the other task has not been created yet. Their OtherTaskID
+	// is the id of the other task in the migrated structure.
+	pendingRelations []*models.TaskRelation
+	// pendingAssignees holds the assignees of all created tasks. They are added once all projects were created.
+	pendingAssignees []*pendingAssignee
+}
+
+// pendingAssignee is a user who should be assigned to a newly created task.
+type pendingAssignee struct {
+	taskSourceID string
+	title        string
+	assignee     *models.TaskAssginee
+}
+
+// resumeProject makes the tasks of a project which was already migrated in a previous run available to
+// relations from the projects created in this run.
+func resumeProject(project *models.ProjectWithTasksAndBuckets, existing *models.Project, archivedProjects *[]int64, created *createdTasks, prog *progress) {
+	if project.IsArchived {
+		*archivedProjects = append(*archivedProjects, existing.ID)
+	}
+
+	for _, t := range project.Tasks {
+		newID, has := prog.migratedTaskID(t.SourceID)
+		if !has {
+			continue
+		}
+		created.byOldID[t.ID] = newID
+
+		// The previous run might have failed before it could create relations to other projects
+		for kind, tasks := range t.RelatedTasks {
+			for _, rt := range tasks {
+				if rt.ID == 0 || rt.Title != "" {
+					continue
+				}
+				created.pendingRelations = append(created.pendingRelations, &models.TaskRelation{
+					TaskID:       newID,
+					OtherTaskID:  rt.ID,
+					RelationKind: kind,
+				})
+			}
+		}
+	}
+}
+
+func createProject(s *xorm.Session, project *models.ProjectWithTasksAndBuckets, archivedProjectIDs *[]int64, labels map[string]*models.Label, created *createdTasks, prog *progress, user *user.User) (err error) {
+	err = createProjectWithEverything(s, project, archivedProjectIDs, labels, created, prog, user)
 	if err != nil {
 		return err
 	}
@@ -128,7 +237,7 @@ func createProject(s *xorm.Session, project *models.ProjectWithTasksAndBuckets,
 	return
 }
 
-func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTasksAndBuckets, archivedProjects *[]int64, labels map[string]*models.Label, user *user.User) (err error) {
+func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTasksAndBuckets, archivedProjects *[]int64, labels map[string]*models.Label, created *createdTasks, prog *progress, user *user.User) (err error) {
 	// The tasks and bucket slices are going to be reset during the creation of the project, so we rescue it here
 	// to be able to still loop over them aftere the project was created.
 	tasks := project.Tasks
@@ -154,12 +263,30 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 		return
 	}
 
+	progressItem, err := prog.projectStarted(project)
+	if err != nil {
+		return
+	}
+
 	if wasArchived {
 		*archivedProjects = append(*archivedProjects, project.ID)
 	}
 
 	log.Debugf("[creating structure] Created project %d", project.ID)
 
+	for _, pu := range project.Users {
+		pu.ProjectID = project.ID
+		err = pu.Create(s, user)
+		if models.IsErrUserAlreadyHasAccess(err) {
+			err = nil
+			continue
+		}
+		if err != nil {
+			return
+		}
+		log.Debugf("[creating structure] Shared project %d with user %s", project.ID, pu.Username)
+	}
+
 	bf, is := originalBackgroundInformation.(*bytes.Buffer)
 	if is {
 
@@ -293,15 +420,41 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 	}
 
 	tasksByOldID := make(map[int64]*models.TaskWithComments, len(tasks))
+	migratedTasks := make(map[string]int64, len(tasks)) // source id is the key
 	newTaskIDs := []int64{}
 	// Create all tasks
 	for i, t := range tasks {
 		oldid := t.ID
 		t.ProjectID = project.ID
+		// Assignees need access to the project, which they might only get through a parent project.
+		// They are added once all projects were created and moved below their parents.
+		assignees := t.Assignees
+		t.Assignees = nil
 		err = t.Create(s, user)
 		if err != nil && models.IsErrTaskCannotBeEmpty(err) {
+			err = prog.itemSkipped(ItemKindTask, t.SourceID, t.Title, "The task has no title")
+			if err != nil {
+				return
+			}
 			continue
 		}
+		// The task is already created when its bucket is checked. At this point, the bucket id still references
+		// the bucket in the migrated structure, it is set to the new bucket below.
+		if err != nil && models.IsErrBucketDoesNotExist(err) && t.ID != 0 {
+			err = nil
+		}
+		if err != nil {
+			// If the task itself was created, only some of its details like an assignee could not be saved.
+			// It is still reported, but everything else of the task is migrated as usual.
+			taskCreated := t.ID != 0
+			err = prog.itemFailed(ItemKindTask, t.SourceID, t.Title, err)
+			if err != nil {
+				return
+			}
+			if !taskCreated {
+				continue
+			}
+		}
 
 		err = setBucketOrDefault(&tasks[i].Task)
 		if err != nil {
@@ -310,9 +463,28 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 
 		newTaskIDs = append(newTaskIDs, t.ID)
 
+		for _, a := range assignees {
+			created.pendingAssignees = append(created.pendingAssignees, &pendingAssignee{
+				taskSourceID: t.SourceID,
+				title:        t.Title,
+				assignee:     &models.TaskAssginee{TaskID: t.ID, UserID: a.ID},
+			})
+		}
+
 		tasksByOldID[oldid] = t
+		if oldid != 0 {
+			created.byOldID[oldid] = t.ID
+		}
+		if t.SourceID != "" {
+			migratedTasks[t.SourceID] = t.ID
+		}
 
 		log.Debugf("[creating structure] Created task %d", t.ID)
+
+		err = prog.taskDone()
+		if err != nil {
+			return
+		}
 		if len(t.RelatedTasks) > 0 {
 			log.Debugf("[creating structure] Creating %d related task kinds", len(t.RelatedTasks))
 		}
@@ -325,19 +497,37 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 			}
 
 			for _, rt := range tasks {
+				// Related tasks which only reference another task by its id live in another project or are
+				// created later. The relation to them is created once all projects were created.
+				if _, exists := tasksByOldID[rt.ID]; !exists && rt.ID != 0 && rt.Title == "" {
+					created.pendingRelations = append(created.pendingRelations, &models.TaskRelation{
+						TaskID:       t.ID,
+						OtherTaskID:  rt.ID,
+						RelationKind: kind,
+					})
+					continue
+				}
+
 				// First create the related tasks if they do not exist
 				if _, exists := tasksByOldID[rt.ID]; !exists || rt.ID == 0 {
 					oldid := rt.ID
 					rt.ProjectID = t.ProjectID
 					err = rt.Create(s, user)
 					if err != nil {
-						return
+						err = prog.itemFailed(ItemKindTask, sourceID(oldid), rt.Title, err)
+						if err != nil {
+							return
+						}
+						continue
 					}
 					err = setBucketOrDefault(rt)
 					if err != nil {
 						return
 					}
 					tasksByOldID[oldid] = &models.TaskWithComments{Task: *rt}
+					if oldid != 0 {
+						created.byOldID[oldid] = rt.ID
+					}
 					log.Debugf("[creating structure] Created related task %d", rt.ID)
 				}
 
@@ -352,7 +542,11 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 				}
 				err = taskRel.Create(s, user)
 				if err != nil && !models.IsErrRelationAlreadyExists(err) {
-					return
+					err = prog.itemFailed(ItemKindRelation, sourceID(rt.ID), t.Title+" "+string(kind)+" "+rt.Title, err)
+					if err != nil {
+						return
+					}
+					continue
 				}
 
 				log.Debugf("[creating structure] Created task relation between task %d and %d", t.ID, rt.ID)
@@ -373,10 +567,19 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 				fr := io.NopCloser(bytes.NewReader(a.File.FileContent))
 				err = a.NewAttachment(s, fr, a.File.Name, a.File.Size, user)
 				if err != nil {
-					return
+					err = prog.itemFailed(ItemKindAttachment, sourceID(oldID), a.File.Name, err)
+					if err != nil {
+						return
+					}
+					continue
 				}
 				log.Debugf("[creating structure] Created new attachment %d", a.ID)
 
+				err = prog.attachmentDone()
+				if err != nil {
+					return
+				}
+
 				if t.CoverImageAttachmentID == oldID {
 					t.CoverImageAttachmentID = a.ID
 					err = t.Update(s, user)
@@ -400,7 +603,11 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 			if !exists {
 				err = label.Create(s, user)
 				if err != nil {
-					return err
+					err = prog.itemFailed(ItemKindLabel, sourceID(label.ID), label.Title, err)
+					if err != nil {
+						return err
+					}
+					continue
 				}
 				log.Debugf("[creating structure] Created new label %d", label.ID)
 				labels[label.Title+label.HexColor] = label
@@ -413,21 +620,46 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 			}
 			err = lt.Create(s, user)
 			if err != nil && !models.IsErrLabelIsAlreadyOnTask(err) {
-				return err
+				err = prog.itemFailed(ItemKindLabel, sourceID(lb.ID), lb.Title, err)
+				if err != nil {
+					return err
+				}
+				continue
 			}
 			log.Debugf("[creating structure] Associated task %d with label %d", t.ID, lb.ID)
 		}
 
 		// Comments
 		for _, comment := range t.Comments {
+			oldID := comment.ID
 			comment.TaskID = t.ID
 			comment.ID = 0
 			err = comment.CreateWithTimestamps(s, user)
 			if err != nil {
-				return
+				err = prog.itemFailed(ItemKindComment, sourceID(oldID), t.Title, err)
+				if err != nil {
+					return
+				}
+				continue
 			}
 			log.Debugf("[creating structure] Created new comment %d", comment.ID)
 		}
+
+		// Checklist
+		for _, item := range t.ChecklistItems {
+			oldID := item.ID
+			item.TaskID = t.ID
+			item.ID = 0
+			err = item.Create(s, user)
+			if err != nil {
+				err = prog.itemFailed(ItemKindChecklistItem, sourceID(oldID), item.Title, err)
+				if err != nil {
+					return
+				}
+				continue
+			}
+			log.Debugf("[creating structure] Created new checklist item %d", item.ID)
+		}
 	}
 
 	// All tasks brought their own bucket with them, therefore the newly created default bucket is just extra space
@@ -515,5 +747,5 @@ func createProjectWithEverything(s *xorm.Session, project *models.ProjectWithTas
 	project.Tasks = tasks
 	project.Buckets = originalBuckets
 
-	return nil
+	return prog.projectDone(progressItem, migratedTasks)
 }
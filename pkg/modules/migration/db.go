@@ -20,5 +20,8 @@ package migration
 func GetTables() []interface{} {
 	return []interface{}{
 		&Status{},
+		&Item{},
+		&Sync{},
+		&SyncMapping{},
 	}
 }
@@ -0,0 +1,663 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package generic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
+	"code.vikunja.io/api/pkg/user"
+
+	"xorm.io/xorm"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+)
+
+// The date formats which are tried when the options don't specify one.
+var defaultDateFormats = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// Mapping defines which column (csv) or key (json) of the imported file holds which task field.
+// Fields which are left empty are not imported.
+type Mapping struct {
+	// The title of the task. Required.
+	Title string `json:"title"`
+	// The description of the task.
+	Description string `json:"description"`
+	// The due date of the task, parsed with the date format of the options.
+	DueDate string `json:"due_date"`
+	// The labels of the task, separated by the label separator of the options. Labels which don't exist will be created.
+	Labels string `json:"labels"`
+	// The username or email address of the user the task should be assigned to.
+	Assignee string `json:"assignee"`
+	// The path of the project the task should be put in, with the parts separated by the project separator of the options.
+	// Projects which don't exist yet will be created below the project holding the import.
+	Project string `json:"project"`
+	// The title of the kanban bucket the task should be put in.
+	Bucket string `json:"bucket"`
+	// An identifier of the task in the file. Only used to resolve parent ids.
+	ID string `json:"id"`
+	// The identifier of the parent task of this task, must be the value of the id column of another row.
+	ParentID string `json:"parent_id"`
+}
+
+// Options configure how a file is imported.
+type Options struct {
+	// The format of the file, either "csv" or "json". Detected from the file content if empty.
+	Format string `json:"format"`
+	// The delimiter of csv files. Defaults to ",".
+	Delimiter string `json:"delimiter"`
+	// Which column or key holds which task field.
+	Mapping Mapping `json:"mapping"`
+	// A go time layout to parse dates. If empty, RFC3339 and a few common ISO 8601 formats are tried.
+	DateFormat string `json:"date_format"`
+	// The separator for multiple labels in one field. Defaults to ",".
+	LabelSeparator string `json:"label_separator"`
+	// The separator between the parts of a project path. Defaults to "/".
+	ProjectSeparator string `json:"project_separator"`
+	// The title of the project which will hold all imported projects and tasks. Defaults to "Imported tasks".
+	ProjectTitle string `json:"project_title"`
+	// If true, the imported project is shared with all assignees who are not the importing user, so that tasks can
+	// be assigned to them. If false, rows assigning a task to another user are invalid.
+	ShareWithAssignees bool `json:"share_with_assignees"`
+}
+
+// FileMigrator imports tasks from arbitrary csv or json files with a user-provided mapping.
+type FileMigrator struct {
+	options *Options
+}
+
+// PreviewRow is a single row of an imported file as it would be imported.
+type PreviewRow struct {
+	// The number of the row in the file, starting at 1 for the first task.
+	Row         int       `json:"row"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	DueDate     time.Time `json:"due_date"`
+	Labels      []string  `json:"labels"`
+	Assignee    string    `json:"assignee"`
+	Project     string    `json:"project"`
+	Bucket      string    `json:"bucket"`
+	ID          string    `json:"id"`
+	ParentID    string    `json:"parent_id"`
+	// Everything which prevents this row from being imported.
+	Errors []string `json:"errors"`
+
+	assignee *user.User
+}
+
+// Preview is the result of a dry run.
+type Preview struct {
+	// The detected or configured format of the file.
+	Format string `json:"format"`
+	// All rows of the file.
+	Rows []*PreviewRow `json:"rows"`
+	// The number of rows which can be imported.
+	ValidRows int `json:"valid_rows"`
+	// The number of rows with errors. The file can only be imported if this is 0.
+	InvalidRows int `json:"invalid_rows"`
+	// The paths of all projects which will be created.
+	Projects []string `json:"projects"`
+	// The usernames of all users the imported project will be shared with, read only.
+	Shares []string `json:"shares"`
+}
+
+// Name is used to get the name of the generic file migration - we're using the docs here to annotate the status route.
+// @Summary Get migration status
+// @Description Returns if the current user already did the migation or not. This is useful to show a confirmation message in the frontend if the user is trying to do the same migration again.
+// @tags migration
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} migration.Status "The migration status"
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/generic/status [get]
+func (m *FileMigrator) Name() string {
+	return "generic"
+}
+
+// SetOptions parses the json options sent along with the file.
+func (m *FileMigrator) SetOptions(raw string) error {
+	opts := &Options{}
+	if raw == "" {
+		return &models.ErrInvalidImportMapping{Reason: "you must provide a mapping"}
+	}
+	if err := json.Unmarshal([]byte(raw), opts); err != nil {
+		return &models.ErrInvalidImportMapping{Reason: err.Error()}
+	}
+
+	if opts.Mapping.Title == "" {
+		return &models.ErrInvalidImportMapping{Reason: "you must map a column to the title"}
+	}
+	if opts.Mapping.ParentID != "" && opts.Mapping.ID == "" {
+		return &models.ErrInvalidImportMapping{Reason: "you must map a column to the id to use parent ids"}
+	}
+
+	opts.Format = strings.ToLower(opts.Format)
+	if opts.Format != "" && opts.Format != FormatCSV && opts.Format != FormatJSON {
+		return &models.ErrInvalidImportMapping{Reason: "the format must be either csv or json"}
+	}
+	if len([]rune(opts.Delimiter)) > 1 {
+		return &models.ErrInvalidImportMapping{Reason: "the delimiter must be a single character"}
+	}
+	if opts.LabelSeparator == "" {
+		opts.LabelSeparator = ","
+	}
+	if opts.ProjectSeparator == "" {
+		opts.ProjectSeparator = "/"
+	}
+	if opts.ProjectTitle == "" {
+		opts.ProjectTitle = "Imported tasks"
+	}
+
+	m.options = opts
+	return nil
+}
+
+// Preview parses the file and validates every row without importing anything.
+func (m *FileMigrator) Preview(u *user.User, file io.ReaderAt, size int64) (interface{}, error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	return m.preview(s, u, file, size)
+}
+
+func (m *FileMigrator) preview(s *xorm.Session, u *user.User, file io.ReaderAt, size int64) (*Preview, error) {
+	if m.options == nil {
+		return nil, &models.ErrInvalidImportMapping{Reason: "you must provide a mapping"}
+	}
+
+	content, err := io.ReadAll(io.NewSectionReader(file, 0, size))
+	if err != nil {
+		return nil, err
+	}
+
+	format := m.options.Format
+	if format == "" {
+		format = detectFormat(content)
+	}
+
+	var records []map[string]string
+	switch format {
+	case FormatJSON:
+		records, err = readJSON(content, m.options.LabelSeparator)
+	default:
+		var delimiter rune
+		if m.options.Delimiter != "" {
+			delimiter = []rune(m.options.Delimiter)[0]
+		}
+		_, records, err = migration.ReadCSV(content, delimiter)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &Preview{
+		Format: format,
+		Rows:   make([]*PreviewRow, 0, len(records)),
+	}
+
+	users := make(map[string]*user.User)
+	for i, record := range records {
+		row, err := m.parseRecord(s, u, i+1, record, users)
+		if err != nil {
+			return nil, err
+		}
+		preview.Rows = append(preview.Rows, row)
+	}
+
+	validateParents(preview.Rows)
+
+	projects := make(map[string]bool)
+	preview.Shares = []string{}
+	for _, row := range preview.Rows {
+		if len(row.Errors) > 0 {
+			preview.InvalidRows++
+			continue
+		}
+		preview.ValidRows++
+		for _, path := range projectPaths(row.Project, m.options.ProjectSeparator) {
+			projects[path] = true
+		}
+	}
+	for _, share := range getShares(preview.Rows, u) {
+		preview.Shares = append(preview.Shares, share.Username)
+	}
+
+	preview.Projects = make([]string, 0, len(projects))
+	for path := range projects {
+		preview.Projects = append(preview.Projects, path)
+	}
+	sort.Strings(preview.Projects)
+
+	return preview, nil
+}
+
+// Migrate imports the file with the mapping of the options.
+// @Summary Import tasks from a csv or json file
+// @Description Imports tasks from any csv or json file. The `options` form field holds a json object with the format and a mapping of the file's columns (or keys) to task fields: title, description, due_date, labels, assignee (username or email), project (a path like `Work/Clients`), bucket, id and parent_id. All tasks are imported in a new project. Tasks can only be assigned to other users if `share_with_assignees` is set in the options, the project is then shared with them. Set `dry_run` to `true` to get a preview (generic.Preview) with all validation errors per row and the users the project will be shared with instead of importing anything. The file is only imported if no row has errors.
+// @tags migration
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security JWTKeyAuth
+// @Param import formData string true "The csv or json file."
+// @Param options formData string true "The import options as json, including the mapping."
+// @Param dry_run formData bool false "If true, returns a preview of the import instead of importing anything."
+// @Success 200 {object} models.Message "A message telling you everything was migrated successfully."
+// @Failure 400 {object} web.HTTPError "The options or the file are invalid."
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/generic/migrate [put]
+func (m *FileMigrator) Migrate(u *user.User, file io.ReaderAt, size int64) error {
+	s := db.NewSession()
+	preview, err := m.preview(s, u, file, size)
+	s.Close()
+	if err != nil {
+		return err
+	}
+
+	for _, row := range preview.Rows {
+		if len(row.Errors) > 0 {
+			return &models.ErrInvalidImportFile{
+				Reason: fmt.Sprintf("%d rows have errors, the first one is in row %d: %s", preview.InvalidRows, row.Row, row.Errors[0]),
+			}
+		}
+	}
+
+	if len(preview.Rows) == 0 {
+		return &models.ErrInvalidImportFile{Reason: "the file does not contain any tasks"}
+	}
+
+	structure := convertRowsToVikunja(preview.Rows, m.options, u)
+	return migration.InsertFromStructure(structure, u)
+}
+
+func detectFormat(content []byte) string {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+	if len(trimmed) > 0 && (trimmed[0] == '[' || trimmed[0] == '{') {
+		return FormatJSON
+	}
+	return FormatCSV
+}
+
+func readJSON(content []byte, labelSeparator string) (records []map[string]string, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	decoder.UseNumber()
+
+	raw := []map[string]interface{}{}
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, &models.ErrInvalidImportFile{Reason: "the file must contain an array of objects: " + err.Error()}
+	}
+
+	records = make([]map[string]string, 0, len(raw))
+	for _, item := range raw {
+		record := make(map[string]string, len(item))
+		for key, value := range item {
+			record[key] = jsonValueToString(value, labelSeparator)
+		}
+		records = append(records, record)
+	}
+
+	return
+}
+
+func jsonValueToString(value interface{}, separator string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, part := range v {
+			parts = append(parts, jsonValueToString(part, separator))
+		}
+		return strings.Join(parts, separator)
+	default:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	}
+}
+
+func (m *FileMigrator) parseRecord(s *xorm.Session, doer *user.User, number int, record map[string]string, users map[string]*user.User) (row *PreviewRow, err error) {
+	mapping := m.options.Mapping
+	get := func(column string) string {
+		if column == "" {
+			return ""
+		}
+		return strings.TrimSpace(record[column])
+	}
+
+	row = &PreviewRow{
+		Row:         number,
+		Title:       get(mapping.Title),
+		Description: get(mapping.Description),
+		Assignee:    get(mapping.Assignee),
+		Project:     get(mapping.Project),
+		Bucket:      get(mapping.Bucket),
+		ID:          get(mapping.ID),
+		ParentID:    get(mapping.ParentID),
+		Labels:      []string{},
+		Errors:      []string{},
+	}
+
+	if row.Title == "" {
+		row.Errors = append(row.Errors, "the title is empty")
+	}
+
+	if dueDate := get(mapping.DueDate); dueDate != "" {
+		row.DueDate, err = parseDate(dueDate, m.options.DateFormat)
+		if err != nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("the due date %q could not be parsed", dueDate))
+			err = nil
+		}
+	}
+
+	for _, label := range strings.Split(get(mapping.Labels), m.options.LabelSeparator) {
+		label = strings.TrimSpace(label)
+		if label != "" {
+			row.Labels = append(row.Labels, label)
+		}
+	}
+
+	if row.Assignee != "" {
+		assignee, has := users[row.Assignee]
+		if !has {
+			assignee, err = findAssignee(s, doer, row.Assignee)
+			if err != nil {
+				return nil, err
+			}
+			users[row.Assignee] = assignee
+		}
+		if assignee == nil {
+			row.Errors = append(row.Errors, fmt.Sprintf("the user %q does not exist", row.Assignee))
+		}
+		if assignee != nil && assignee.ID != doer.ID && !m.options.ShareWithAssignees {
+			row.Errors = append(row.Errors, fmt.Sprintf("the user %q does not have access to the imported project, enable sharing it with assignees to assign tasks to them", row.Assignee))
+		}
+		row.assignee = assignee
+	}
+
+	return row, nil
+}
+
+func parseDate(value string, format string) (time.Time, error) {
+	if format != "" {
+		return time.ParseInLocation(format, value, config.GetTimeZone())
+	}
+
+	var err error
+	for _, f := range defaultDateFormats {
+		var t time.Time
+		t, err = time.ParseInLocation(f, value, config.GetTimeZone())
+		if err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+// findAssignee looks up a user by username or email. Users are only found by email if they allowed it
+// in their settings, to not expose who is using an email address.
+func findAssignee(s *xorm.Session, doer *user.User, identifier string) (*user.User, error) {
+	var u *user.User
+	var err error
+	if strings.Contains(identifier, "@") {
+		u, err = user.GetUserWithEmail(s, &user.User{Email: identifier})
+		if err == nil && !u.DiscoverableByEmail && u.ID != doer.ID {
+			return nil, nil
+		}
+	} else {
+		u, err = user.GetUserByUsername(s, identifier)
+	}
+	if user.IsErrUserDoesNotExist(err) {
+		return nil, nil
+	}
+	return u, err
+}
+
+func validateParents(rows []*PreviewRow) {
+	byID := make(map[string]*PreviewRow, len(rows))
+	for _, row := range rows {
+		if row.ID == "" {
+			continue
+		}
+		if _, has := byID[row.ID]; has {
+			row.Errors = append(row.Errors, fmt.Sprintf("the id %q is used by more than one row", row.ID))
+			continue
+		}
+		byID[row.ID] = row
+	}
+
+	for _, row := range rows {
+		if row.ParentID == "" {
+			continue
+		}
+		parent, has := byID[row.ParentID]
+		switch {
+		case !has:
+			row.Errors = append(row.Errors, fmt.Sprintf("the parent task %q does not exist", row.ParentID))
+		case parent == row:
+			row.Errors = append(row.Errors, "a task cannot be its own parent")
+		case parent.Project != row.Project:
+			row.Errors = append(row.Errors, "the parent task must be in the same project")
+		}
+	}
+}
+
+// getShares returns all users the imported project needs to be shared with to assign the tasks to them,
+// sorted by their username.
+func getShares(rows []*PreviewRow, doer *user.User) (users []*user.User) {
+	shared := make(map[int64]bool)
+	for _, row := range rows {
+		if len(row.Errors) > 0 || row.assignee == nil || row.assignee.ID == doer.ID || shared[row.assignee.ID] {
+			continue
+		}
+		shared[row.assignee.ID] = true
+		users = append(users, row.assignee)
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+	return
+}
+
+// projectPaths returns all paths of a project path, from the top most project to the project itself.
+func projectPaths(path string, separator string) (paths []string) {
+	current := ""
+	for _, part := range strings.Split(path, separator) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if current != "" {
+			current += separator
+		}
+		current += part
+		paths = append(paths, current)
+	}
+	return
+}
+
+func convertRowsToVikunja(rows []*PreviewRow, opts *Options, doer *user.User) (result []*models.ProjectWithTasksAndBuckets) {
+	var pseudoParentID int64 = 1
+	root := &models.ProjectWithTasksAndBuckets{
+		Project: models.Project{
+			ID:    pseudoParentID,
+			Title: opts.ProjectTitle,
+		},
+		SourceID: migration.RootProjectSourceID,
+	}
+	result = []*models.ProjectWithTasksAndBuckets{root}
+
+	// All projects are below the root project and inherit its shares, so sharing it is enough
+	// to be able to assign the tasks.
+	if opts.ShareWithAssignees {
+		for _, assignee := range getShares(rows, doer) {
+			root.Users = append(root.Users, &models.ProjectUser{
+				Username: assignee.Username,
+				Right:    models.RightRead,
+			})
+		}
+	}
+
+	projects := map[string]*models.ProjectWithTasksAndBuckets{"": root}
+	getProject := func(path string) *models.ProjectWithTasksAndBuckets {
+		parent := root
+		for _, p := range projectPaths(path, opts.ProjectSeparator) {
+			project, has := projects[p]
+			if !has {
+				parts := strings.Split(p, opts.ProjectSeparator)
+				project = &models.ProjectWithTasksAndBuckets{
+					Project: models.Project{
+						ID:              int64(len(result)) + pseudoParentID,
+						ParentProjectID: parent.ID,
+						Title:           strings.TrimSpace(parts[len(parts)-1]),
+					},
+					SourceID: p,
+				}
+				projects[p] = project
+				result = append(result, project)
+			}
+			parent = project
+		}
+		return parent
+	}
+
+	var bucketID int64
+	buckets := make(map[*models.ProjectWithTasksAndBuckets]map[string]int64)
+
+	tasksByFileID := make(map[string]*models.TaskWithComments)
+	projectsByFileID := make(map[string]*models.ProjectWithTasksAndBuckets)
+	for _, row := range rows {
+		project := getProject(row.Project)
+
+		labels := make([]*models.Label, 0, len(row.Labels))
+		for _, l := range row.Labels {
+			labels = append(labels, &models.Label{Title: l})
+		}
+
+		task := &models.TaskWithComments{
+			Task: models.Task{
+				ID:          int64(row.Row),
+				Title:       row.Title,
+				Description: row.Description,
+				DueDate:     row.DueDate,
+				Labels:      labels,
+			},
+			SourceID: row.ID,
+		}
+
+		if row.assignee != nil {
+			task.Assignees = []*user.User{row.assignee}
+		}
+
+		if row.Bucket != "" {
+			if buckets[project] == nil {
+				buckets[project] = make(map[string]int64)
+			}
+			id, has := buckets[project][row.Bucket]
+			if !has {
+				bucketID++
+				id = bucketID
+				buckets[project][row.Bucket] = id
+				project.Buckets = append(project.Buckets, &models.Bucket{
+					ID:    id,
+					Title: row.Bucket,
+				})
+			}
+			task.BucketID = id
+		}
+
+		project.Tasks = append(project.Tasks, task)
+		if row.ID != "" {
+			tasksByFileID[row.ID] = task
+			projectsByFileID[row.ID] = project
+		}
+	}
+
+	// Tasks are created in the order they appear in their project. Related tasks which do not exist yet are created
+	// on the fly, which is why the relation is always added to the task which is created last.
+	positions := make(map[*models.TaskWithComments]int)
+	for _, project := range result {
+		for i, task := range project.Tasks {
+			positions[task] = i
+		}
+	}
+	for _, row := range rows {
+		if row.ParentID == "" {
+			continue
+		}
+		parent := tasksByFileID[row.ParentID]
+		child := tasksByFileID[row.ID]
+		if child == nil {
+			child = findTaskByRow(projectsByFileID[row.ParentID], row.Row)
+		}
+		if parent == nil || child == nil {
+			continue
+		}
+
+		if positions[parent] < positions[child] {
+			addRelation(child, models.RelationKindParenttask, parent)
+			continue
+		}
+		addRelation(parent, models.RelationKindSubtask, child)
+	}
+
+	return
+}
+
+func findTaskByRow(project *models.ProjectWithTasksAndBuckets, row int) *models.TaskWithComments {
+	if project == nil {
+		return nil
+	}
+	for _, t := range project.Tasks {
+		if t.ID == int64(row) {
+			return t
+		}
+	}
+	return nil
+}
+
+func addRelation(task *models.TaskWithComments, kind models.RelationKind, other *models.TaskWithComments) {
+	if task.RelatedTasks == nil {
+		task.RelatedTasks = make(models.RelatedTaskMap)
+	}
+	task.RelatedTasks[kind] = append(task.RelatedTasks[kind], &models.Task{ID: other.ID})
+}
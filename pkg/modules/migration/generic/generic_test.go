@@ -0,0 +1,261 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package generic
+
+import (
+	"strings"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCSV = `Name;Notes;Due;Tags;Owner;List;Status;Key;Parent
+Write report;Quarterly numbers;2024-06-20;work, urgent;user2;Work/Reports;In Progress;1;
+Collect numbers;;2024-06-18;;user1;Work/Reports;Todo;2;1
+Buy milk;;;home;;;;3;
+`
+
+func newTestMigrator(t *testing.T, options string) *FileMigrator {
+	m := &FileMigrator{}
+	err := m.SetOptions(options)
+	require.NoError(t, err)
+	return m
+}
+
+const testCSVOptions = `{
+	"delimiter": ";",
+	"share_with_assignees": true,
+	"mapping": {
+		"title": "Name",
+		"description": "Notes",
+		"due_date": "Due",
+		"labels": "Tags",
+		"assignee": "Owner",
+		"project": "List",
+		"bucket": "Status",
+		"id": "Key",
+		"parent_id": "Parent"
+	}
+}`
+
+func TestFileMigrator_SetOptions(t *testing.T) {
+	t.Run("missing title", func(t *testing.T) {
+		m := &FileMigrator{}
+		err := m.SetOptions(`{"mapping":{"description":"Notes"}}`)
+		require.Error(t, err)
+		assert.True(t, models.IsErrInvalidImportMapping(err))
+	})
+	t.Run("parent without id", func(t *testing.T) {
+		m := &FileMigrator{}
+		err := m.SetOptions(`{"mapping":{"title":"Name","parent_id":"Parent"}}`)
+		require.Error(t, err)
+		assert.True(t, models.IsErrInvalidImportMapping(err))
+	})
+	t.Run("invalid format", func(t *testing.T) {
+		m := &FileMigrator{}
+		err := m.SetOptions(`{"format":"xml","mapping":{"title":"Name"}}`)
+		require.Error(t, err)
+		assert.True(t, models.IsErrInvalidImportMapping(err))
+	})
+}
+
+func TestFileMigrator_Preview(t *testing.T) {
+	u := &user.User{ID: 1, Username: "user1"}
+
+	t.Run("csv", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		m := newTestMigrator(t, testCSVOptions)
+		result, err := m.Preview(u, strings.NewReader(testCSV), int64(len(testCSV)))
+		require.NoError(t, err)
+		preview := result.(*Preview)
+
+		assert.Equal(t, FormatCSV, preview.Format)
+		require.Len(t, preview.Rows, 3)
+		assert.Equal(t, 3, preview.ValidRows)
+		assert.Equal(t, 0, preview.InvalidRows)
+		assert.Equal(t, []string{"Work", "Work/Reports"}, preview.Projects)
+		assert.Equal(t, []string{"user2"}, preview.Shares)
+
+		assert.Equal(t, "Write report", preview.Rows[0].Title)
+		assert.Equal(t, "Quarterly numbers", preview.Rows[0].Description)
+		assert.Equal(t, []string{"work", "urgent"}, preview.Rows[0].Labels)
+		assert.Equal(t, 2024, preview.Rows[0].DueDate.Year())
+		assert.Equal(t, "1", preview.Rows[1].ParentID)
+		assert.True(t, preview.Rows[2].DueDate.IsZero())
+	})
+	t.Run("json", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		content := `[
+			{"task": "First", "tags": ["a", "b"], "id": 10, "done": true},
+			{"task": "Second", "parent": 10, "id": 11}
+		]`
+		m := newTestMigrator(t, `{"mapping":{"title":"task","labels":"tags","id":"id","parent_id":"parent"}}`)
+		result, err := m.Preview(u, strings.NewReader(content), int64(len(content)))
+		require.NoError(t, err)
+		preview := result.(*Preview)
+
+		assert.Equal(t, FormatJSON, preview.Format)
+		require.Len(t, preview.Rows, 2)
+		assert.Equal(t, 2, preview.ValidRows)
+		assert.Equal(t, []string{"a", "b"}, preview.Rows[0].Labels)
+		assert.Equal(t, "10", preview.Rows[1].ParentID)
+	})
+	t.Run("validation errors", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		content := "title,due,assignee,id,parent\n" +
+			",,,1,\n" +
+			"Task,not a date,,2,\n" +
+			"Task,,user1337,3,\n" +
+			"Task,,,4,99\n" +
+			"Task,,,4,\n"
+		m := newTestMigrator(t, `{"mapping":{"title":"title","due_date":"due","assignee":"assignee","id":"id","parent_id":"parent"}}`)
+		result, err := m.Preview(u, strings.NewReader(content), int64(len(content)))
+		require.NoError(t, err)
+		preview := result.(*Preview)
+
+		require.Len(t, preview.Rows, 5)
+		assert.Equal(t, 0, preview.ValidRows)
+		assert.Equal(t, 5, preview.InvalidRows)
+		assert.Contains(t, preview.Rows[0].Errors, "the title is empty")
+		assert.Contains(t, preview.Rows[1].Errors[0], "could not be parsed")
+		assert.Contains(t, preview.Rows[2].Errors[0], "does not exist")
+		assert.Contains(t, preview.Rows[3].Errors[0], "parent task")
+		assert.Contains(t, preview.Rows[4].Errors[0], "more than one row")
+	})
+	t.Run("email of user who is not discoverable", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		content := "title,assignee\nTask,user2@example.com\n"
+		m := newTestMigrator(t, `{"mapping":{"title":"title","assignee":"assignee"}}`)
+		result, err := m.Preview(u, strings.NewReader(content), int64(len(content)))
+		require.NoError(t, err)
+		preview := result.(*Preview)
+
+		require.Len(t, preview.Rows, 1)
+		assert.Equal(t, 1, preview.InvalidRows)
+	})
+	t.Run("assignee without sharing", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		content := "title,assignee\nOwn task,user1\nOther task,user2\n"
+		m := newTestMigrator(t, `{"mapping":{"title":"title","assignee":"assignee"}}`)
+		result, err := m.Preview(u, strings.NewReader(content), int64(len(content)))
+		require.NoError(t, err)
+		preview := result.(*Preview)
+
+		require.Len(t, preview.Rows, 2)
+		assert.Empty(t, preview.Rows[0].Errors)
+		require.Len(t, preview.Rows[1].Errors, 1)
+		assert.Contains(t, preview.Rows[1].Errors[0], "does not have access")
+		assert.Empty(t, preview.Shares)
+	})
+}
+
+func TestFileMigrator_Migrate(t *testing.T) {
+	u := &user.User{ID: 1, Username: "user1"}
+
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		m := newTestMigrator(t, testCSVOptions)
+		err := m.Migrate(u, strings.NewReader(testCSV), int64(len(testCSV)))
+		require.NoError(t, err)
+
+		db.AssertExists(t, "projects", map[string]interface{}{
+			"title":    "Imported tasks",
+			"owner_id": 1,
+		}, false)
+		db.AssertExists(t, "projects", map[string]interface{}{
+			"title":    "Reports",
+			"owner_id": 1,
+		}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"title":       "Write report",
+			"description": "Quarterly numbers",
+		}, false)
+		db.AssertExists(t, "buckets", map[string]interface{}{
+			"title": "In Progress",
+		}, false)
+		db.AssertExists(t, "labels", map[string]interface{}{
+			"title":         "urgent",
+			"created_by_id": 1,
+		}, false)
+		// The project is shared with the assignee
+		db.AssertExists(t, "users_projects", map[string]interface{}{
+			"user_id": 2,
+		}, false)
+
+		s := db.NewSession()
+		defer s.Close()
+		task := &models.Task{}
+		_, err = s.Where("title = ?", "Collect numbers").Get(task)
+		require.NoError(t, err)
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       task.ID,
+			"relation_kind": models.RelationKindParenttask,
+		}, false)
+	})
+	t.Run("with invalid rows", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		content := "title,due\n,2024-01-01\nTask,\n"
+		m := newTestMigrator(t, `{"format":"csv","mapping":{"title":"title","due_date":"due"}}`)
+		err := m.Migrate(u, strings.NewReader(content), int64(len(content)))
+		require.Error(t, err)
+		assert.True(t, models.IsErrInvalidImportFile(err))
+	})
+}
+
+func TestConvertRowsToVikunja(t *testing.T) {
+	t.Run("relation is added to the task created last", func(t *testing.T) {
+		rows := []*PreviewRow{
+			{Row: 1, Title: "Child", ID: "b", ParentID: "a"},
+			{Row: 2, Title: "Parent", ID: "a"},
+		}
+		opts := &Options{ProjectSeparator: "/", ProjectTitle: "Imported tasks"}
+		result := convertRowsToVikunja(rows, opts, &user.User{ID: 1})
+
+		require.Len(t, result, 1)
+		require.Len(t, result[0].Tasks, 2)
+		assert.Empty(t, result[0].Tasks[0].RelatedTasks)
+		require.Len(t, result[0].Tasks[1].RelatedTasks[models.RelationKindSubtask], 1)
+		assert.Equal(t, int64(1), result[0].Tasks[1].RelatedTasks[models.RelationKindSubtask][0].ID)
+	})
+	t.Run("source ids", func(t *testing.T) {
+		rows := []*PreviewRow{
+			{Row: 1, Title: "Task", ID: "a", Project: "Work/Reports"},
+		}
+		opts := &Options{ProjectSeparator: "/", ProjectTitle: "Imported tasks"}
+		result := convertRowsToVikunja(rows, opts, &user.User{ID: 1})
+
+		require.Len(t, result, 3)
+		assert.Equal(t, migration.RootProjectSourceID, result[0].SourceID)
+		assert.Equal(t, "Work", result[1].SourceID)
+		assert.Equal(t, "Work/Reports", result[2].SourceID)
+		require.Len(t, result[2].Tasks, 1)
+		assert.Equal(t, "a", result[2].Tasks[0].SourceID)
+	})
+}
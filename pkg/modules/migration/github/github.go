@@ -0,0 +1,443 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
+	"code.vikunja.io/api/pkg/user"
+)
+
+const logPrefix = "[GitHub Migration] "
+
+// attachmentURLPrefixes contains the prefixes of all urls linked in an issue which are downloaded as attachments.
+var attachmentURLPrefixes = []string{
+	"https://github.com/user-attachments/",
+	"https://user-images.githubusercontent.com/",
+	"https://private-user-images.githubusercontent.com/",
+}
+
+// FileMigrator imports GitHub issues from a json export. The export can either be created with
+// `gh issue list --state all --json number,title,body,state,createdAt,closedAt,author,labels,milestone,comments,url`
+// or be the response of the issues endpoint of the GitHub api.
+type FileMigrator struct {
+}
+
+type githubUser struct {
+	Login string `json:"login"`
+}
+
+type githubLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type githubMilestone struct {
+	Number      int64  `json:"number"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	State       string `json:"state"`
+}
+
+type githubComment struct {
+	Author     *githubUser `json:"author"`
+	User       *githubUser `json:"user"`
+	Body       string      `json:"body"`
+	CreatedAt  time.Time   `json:"createdAt"`
+	CreatedAt2 time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// githubIssue contains the fields of both the gh cli and the api export formats.
+type githubIssue struct {
+	Number    int64            `json:"number"`
+	Title     string           `json:"title"`
+	Body      string           `json:"body"`
+	State     string           `json:"state"`
+	Labels    []*githubLabel   `json:"labels"`
+	Milestone *githubMilestone `json:"milestone"`
+	// The cli returns the comments, the api only returns the number of comments.
+	Comments json.RawMessage `json:"comments"`
+
+	// gh cli
+	URL       string      `json:"url"`
+	Author    *githubUser `json:"author"`
+	CreatedAt time.Time   `json:"createdAt"`
+	ClosedAt  *time.Time  `json:"closedAt"`
+
+	// api
+	HTMLURL     string          `json:"html_url"`
+	User        *githubUser     `json:"user"`
+	CreatedAt2  time.Time       `json:"created_at"`
+	ClosedAt2   *time.Time      `json:"closed_at"`
+	PullRequest json.RawMessage `json:"pull_request"`
+}
+
+func (i *githubIssue) link() string {
+	// The url field of the api points to the api itself
+	if i.HTMLURL != "" {
+		return i.HTMLURL
+	}
+	return i.URL
+}
+
+func (i *githubIssue) closedAt() time.Time {
+	if i.ClosedAt != nil {
+		return *i.ClosedAt
+	}
+	if i.ClosedAt2 != nil {
+		return *i.ClosedAt2
+	}
+	return time.Time{}
+}
+
+func (i *githubIssue) comments() (comments []*githubComment) {
+	if len(i.Comments) == 0 || i.Comments[0] != '[' {
+		return nil
+	}
+	err := json.Unmarshal(i.Comments, &comments)
+	if err != nil {
+		log.Debugf(logPrefix+"Could not parse comments of issue %d: %s", i.Number, err)
+		return nil
+	}
+	return
+}
+
+// Name is used to get the name of the github migration - we're using the docs here to annotate the status route.
+// @Summary Get migration status
+// @Description Returns if the current user already did the migation or not. This is useful to show a confirmation message in the frontend if the user is trying to do the same migration again.
+// @tags migration
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} migration.Status "The migration status"
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/github/status [get]
+func (m *FileMigrator) Name() string {
+	return "github"
+}
+
+// Migrate takes a github issues export, parses it and imports everything in it into Vikunja.
+// @Summary Import all issues from a GitHub issues export
+// @Description Imports all issues, comments, labels and attachments from a GitHub issues json export into Vikunja. The repository becomes a project, every milestone a child project. References between issues are converted to task relations.
+// @tags migration
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security JWTKeyAuth
+// @Param import formData string true "The json export, created with `gh issue list --state all --json number,title,body,state,createdAt,closedAt,author,labels,milestone,comments,url` or the issues api."
+// @Success 200 {object} models.Message "A message telling you everything was migrated successfully."
+// @Failure 400 {object} web.HTTPError "The file could not be parsed."
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/github/migrate [post]
+func (m *FileMigrator) Migrate(u *user.User, file io.ReaderAt, size int64) error {
+	content, err := io.ReadAll(io.NewSectionReader(file, 0, size))
+	if err != nil {
+		return err
+	}
+
+	issues, err := parseExport(content)
+	if err != nil {
+		return err
+	}
+
+	if len(issues) == 0 {
+		return &models.ErrInvalidImportFile{Reason: "the file does not contain any issues"}
+	}
+
+	log.Debugf(logPrefix+"Importing %d issues for user %d", len(issues), u.ID)
+
+	structure, err := convertGithubIssuesToVikunja(issues)
+	if err != nil {
+		return err
+	}
+	return migration.InsertFromStructure(structure, u)
+}
+
+func parseExport(content []byte) (issues []*githubIssue, err error) {
+	content = bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+	all := []*githubIssue{}
+	err = json.Unmarshal(content, &all)
+	if err != nil {
+		return nil, &models.ErrInvalidImportFile{Reason: "could not parse the json: " + err.Error()}
+	}
+
+	for _, i := range all {
+		// The issues api also returns pull requests
+		if len(i.PullRequest) > 0 && string(i.PullRequest) != "null" {
+			continue
+		}
+		if i.Number == 0 {
+			return nil, &models.ErrInvalidImportFile{Reason: "an issue does not have a number"}
+		}
+		issues = append(issues, i)
+	}
+
+	sort.Slice(issues, func(a, b int) bool {
+		return issues[a].Number < issues[b].Number
+	})
+
+	return
+}
+
+// repositoryFromURL returns the owner/repo part of an issue url.
+func repositoryFromURL(issueURL string) string {
+	u, err := url.Parse(issueURL)
+	if err != nil {
+		return ""
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+var (
+	// An issue reference is a # followed by the issue number which is not part of a url or a reference to
+	// an issue in another repository.
+	referenceRegex  = `(?:^|[^\w/&#])#(\d+)\b`
+	issueReference  = regexp.MustCompile(referenceRegex)
+	duplicateOf     = regexp.MustCompile(`(?i)duplicate\s+of\s+#(\d+)\b`)
+	blockedBy       = regexp.MustCompile(`(?i)(?:blocked\s+by|depends\s+on)\s+#(\d+)\b`)
+	blocks          = regexp.MustCompile(`(?i)(?:^|[^\w])blocks\s+#(\d+)\b`)
+	taskListItem    = regexp.MustCompile(`(?m)^\s*[-*+]\s+\[[ xX]\]\s+#(\d+)\b`)
+	markdownLink    = regexp.MustCompile(`!?\[([^\]]*)\]\((https?://[^)\s]+)\)`)
+	htmlImageSource = regexp.MustCompile(`<img[^>]+src="(https?://[^"]+)"`)
+)
+
+// findRelations returns all issues referenced in the body and the kind of relation the reference stands for.
+// Each referenced issue only gets one relation, the more specific kinds win over a generic reference.
+func findRelations(number int64, body string) (relations map[int64]models.RelationKind) {
+	relations = make(map[int64]models.RelationKind)
+
+	add := func(matches [][]string, kind models.RelationKind) {
+		for _, match := range matches {
+			other, err := strconv.ParseInt(match[1], 10, 64)
+			if err != nil || other == number {
+				continue
+			}
+			if _, has := relations[other]; !has {
+				relations[other] = kind
+			}
+		}
+	}
+
+	add(duplicateOf.FindAllStringSubmatch(body, -1), models.RelationKindDuplicateOf)
+	add(blockedBy.FindAllStringSubmatch(body, -1), models.RelationKindBlocked)
+	add(blocks.FindAllStringSubmatch(body, -1), models.RelationKindBlocking)
+	add(taskListItem.FindAllStringSubmatch(body, -1), models.RelationKindSubtask)
+	add(issueReference.FindAllStringSubmatch(body, -1), models.RelationKindRelated)
+
+	return
+}
+
+func isAttachmentURL(link string) bool {
+	for _, prefix := range attachmentURLPrefixes {
+		if strings.HasPrefix(link, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// downloadAttachments downloads all files uploaded to an issue body. Files which can't be downloaded are skipped.
+func downloadAttachments(number int64, body string) (attachments []*models.TaskAttachment) {
+	type link struct {
+		name string
+		url  string
+	}
+	links := []*link{}
+	for _, match := range markdownLink.FindAllStringSubmatch(body, -1) {
+		links = append(links, &link{name: match[1], url: match[2]})
+	}
+	for _, match := range htmlImageSource.FindAllStringSubmatch(body, -1) {
+		links = append(links, &link{url: match[1]})
+	}
+
+	seen := make(map[string]bool)
+	for _, l := range links {
+		if !isAttachmentURL(l.url) || seen[l.url] {
+			continue
+		}
+		seen[l.url] = true
+
+		name := path.Base(l.url)
+		if path.Ext(l.name) != "" {
+			name = l.name
+		}
+
+		log.Debugf(logPrefix+"Downloading attachment %s of issue %d", l.url, number)
+		buf, err := migration.DownloadFile(l.url)
+		if err != nil {
+			log.Errorf(logPrefix+"Could not download attachment %s of issue %d: %s", l.url, number, err)
+			continue
+		}
+
+		attachments = append(attachments, &models.TaskAttachment{
+			File: &files.File{
+				Name:        name,
+				Mime:        http.DetectContentType(buf.Bytes()),
+				Size:        uint64(buf.Len()),
+				FileContent: buf.Bytes(),
+			},
+		})
+	}
+
+	return
+}
+
+// convertGithubIssuesToVikunja converts the issues into a project for the repository with a child project for
+// every milestone. The issue numbers are kept as task ids to resolve references between issues.
+func convertGithubIssuesToVikunja(issues []*githubIssue) (result []*models.ProjectWithTasksAndBuckets, err error) {
+	var pseudoParentID int64 = 1
+	title := repositoryFromURL(issues[0].link())
+	if title == "" {
+		title = "Migrated from GitHub"
+	}
+	root := &models.ProjectWithTasksAndBuckets{
+		Project: models.Project{
+			ID:    pseudoParentID,
+			Title: title,
+		},
+		SourceID: migration.RootProjectSourceID,
+	}
+	result = []*models.ProjectWithTasksAndBuckets{root}
+
+	numbers := make(map[int64]bool, len(issues))
+	for _, i := range issues {
+		numbers[i.Number] = true
+	}
+
+	milestones := make(map[string]*models.ProjectWithTasksAndBuckets)
+
+	for _, i := range issues {
+		project := root
+		if i.Milestone != nil && i.Milestone.Title != "" {
+			var has bool
+			project, has = milestones[i.Milestone.Title]
+			if !has {
+				project = &models.ProjectWithTasksAndBuckets{
+					Project: models.Project{
+						ID:              int64(len(milestones)+1) + pseudoParentID,
+						ParentProjectID: pseudoParentID,
+						Title:           i.Milestone.Title,
+						IsArchived:      strings.EqualFold(i.Milestone.State, "closed"),
+					},
+					SourceID: i.Milestone.Title,
+				}
+				project.Description, err = migration.ConvertMarkdownToHTML(i.Milestone.Description)
+				if err != nil {
+					return nil, err
+				}
+				milestones[i.Milestone.Title] = project
+				result = append(result, project)
+			}
+		}
+
+		task := &models.TaskWithComments{
+			Task: models.Task{
+				ID:    i.Number,
+				Title: i.Title,
+				Done:  strings.EqualFold(i.State, "closed"),
+			},
+			SourceID: strconv.FormatInt(i.Number, 10),
+		}
+		if task.Done {
+			task.DoneAt = i.closedAt()
+		}
+
+		task.Description, err = migration.ConvertMarkdownToHTML(i.Body)
+		if err != nil {
+			return nil, err
+		}
+		if strings.HasPrefix(i.link(), "https://") || strings.HasPrefix(i.link(), "http://") {
+			task.Description += `<p>Migrated from <a href="` + html.EscapeString(i.link()) + `">#` + strconv.FormatInt(i.Number, 10) + "</a></p>"
+		}
+
+		for _, l := range i.Labels {
+			task.Labels = append(task.Labels, &models.Label{
+				Title:    l.Name,
+				HexColor: l.Color,
+			})
+		}
+
+		for _, c := range i.comments() {
+			author := c.Author
+			if author == nil {
+				author = c.User
+			}
+			created := c.CreatedAt
+			if created.IsZero() {
+				created = c.CreatedAt2
+			}
+			updated := c.UpdatedAt
+			if updated.IsZero() {
+				updated = created
+			}
+
+			comment := &models.TaskComment{
+				Comment: c.Body,
+				Created: created,
+				Updated: updated,
+			}
+			if author != nil {
+				comment.Comment = "*" + author.Login + "*:\n\n" + comment.Comment
+			}
+			comment.Comment, err = migration.ConvertMarkdownToHTML(comment.Comment)
+			if err != nil {
+				return nil, err
+			}
+			task.Comments = append(task.Comments, comment)
+		}
+
+		for other, kind := range findRelations(i.Number, i.Body) {
+			if !numbers[other] {
+				log.Debugf(logPrefix+"Issue #%d referenced in issue #%d is not part of the export", other, i.Number)
+				continue
+			}
+			if task.RelatedTasks == nil {
+				task.RelatedTasks = make(models.RelatedTaskMap)
+			}
+			task.RelatedTasks[kind] = append(task.RelatedTasks[kind], &models.Task{ID: other})
+		}
+		for kind := range task.RelatedTasks {
+			sort.Slice(task.RelatedTasks[kind], func(a, b int) bool {
+				return task.RelatedTasks[kind][a].ID < task.RelatedTasks[kind][b].ID
+			})
+		}
+
+		task.Attachments = downloadAttachments(i.Number, i.Body)
+
+		project.Tasks = append(project.Tasks, task)
+	}
+
+	return
+}
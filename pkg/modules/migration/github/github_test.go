@@ -0,0 +1,205 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package github
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readFixture reads the recorded export and serves all attachments in it from a local test server.
+func readFixture(t *testing.T) []byte {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("\x89PNG\r\n\x1a\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	originalPrefixes := attachmentURLPrefixes
+	attachmentURLPrefixes = []string{server.URL + "/"}
+	t.Cleanup(func() {
+		attachmentURLPrefixes = originalPrefixes
+	})
+
+	content, err := os.ReadFile("issues.json")
+	require.NoError(t, err)
+	return []byte(strings.ReplaceAll(string(content), "https://github.com/user-attachments/", server.URL+"/user-attachments/"))
+}
+
+func TestFindRelations(t *testing.T) {
+	relations := findRelations(1, "Duplicate of #2, blocked by #3, depends on #4 and blocks #5.\n\n- [ ] #6\n- [x] #7\n\nSee #8, #1, other/repo#9, https://example.com/#10 and &#11;")
+	assert.Equal(t, map[int64]models.RelationKind{
+		2: models.RelationKindDuplicateOf,
+		3: models.RelationKindBlocked,
+		4: models.RelationKindBlocked,
+		5: models.RelationKindBlocking,
+		6: models.RelationKindSubtask,
+		7: models.RelationKindSubtask,
+		8: models.RelationKindRelated,
+	}, relations)
+}
+
+func TestConvertGithubIssues(t *testing.T) {
+	issues, err := parseExport(readFixture(t))
+	require.NoError(t, err)
+	require.Len(t, issues, 5)
+
+	structure, err := convertGithubIssuesToVikunja(issues)
+	require.NoError(t, err)
+	require.Len(t, structure, 3)
+	assert.Equal(t, "vikunja/example", structure[0].Title)
+	assert.Equal(t, "v1.0", structure[1].Title)
+	assert.Equal(t, "v1.0", structure[1].SourceID)
+	assert.Equal(t, "<p>The <strong>first</strong> release</p>\n", structure[1].Description)
+	assert.Equal(t, structure[0].ID, structure[1].ParentProjectID)
+	assert.Equal(t, "v1.1", structure[2].Title)
+	require.Len(t, structure[0].Tasks, 2)
+	require.Len(t, structure[1].Tasks, 2)
+	require.Len(t, structure[2].Tasks, 1)
+
+	crash := structure[1].Tasks[0]
+	assert.Equal(t, int64(1), crash.ID)
+	assert.Equal(t, "1", crash.SourceID)
+	assert.Equal(t, "Crash when saving a project", crash.Title)
+	assert.False(t, crash.Done)
+	assert.Contains(t, crash.Description, "<p>The app crashes when saving a project without a title.</p>")
+	assert.Contains(t, crash.Description, `<a href="https://github.com/vikunja/example/issues/1">#1</a>`)
+	require.Len(t, crash.Labels, 1)
+	assert.Equal(t, "bug", crash.Labels[0].Title)
+	assert.Equal(t, "d73a4a", crash.Labels[0].HexColor)
+	require.Len(t, crash.Comments, 2)
+	assert.Equal(t, "<p><em>hubot</em>:</p>\n<p>I can reproduce this, see the <strong>log</strong> below.</p>\n", crash.Comments[0].Comment)
+	assert.Equal(t, time.Date(2024, 5, 2, 8, 15, 0, 0, time.UTC), crash.Comments[0].Created)
+	assert.Equal(t, time.Date(2024, 5, 2, 8, 15, 0, 0, time.UTC), crash.Comments[0].Updated)
+	require.Len(t, crash.Attachments, 1)
+	assert.Equal(t, "0c9a3c6e-7d5a-4b8e-9f1d-3a1b2c3d4e5f", crash.Attachments[0].File.Name)
+	assert.Equal(t, "image/png", crash.Attachments[0].File.Mime)
+	assert.Equal(t, models.RelatedTaskMap{
+		models.RelationKindBlocked: {{ID: 3}},
+	}, crash.RelatedTasks)
+
+	duplicate := structure[0].Tasks[0]
+	assert.True(t, duplicate.Done)
+	assert.Equal(t, time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC), duplicate.DoneAt)
+	assert.Equal(t, models.RelatedTaskMap{
+		models.RelationKindDuplicateOf: {{ID: 1}},
+	}, duplicate.RelatedTasks)
+
+	validation := structure[1].Tasks[1]
+	assert.Equal(t, models.RelatedTaskMap{
+		models.RelationKindSubtask: {{ID: 4}, {ID: 5}},
+	}, validation.RelatedTasks)
+
+	projectForm := structure[2].Tasks[0]
+	assert.Equal(t, models.RelatedTaskMap{
+		models.RelationKindBlocking: {{ID: 1}},
+		models.RelationKindRelated:  {{ID: 2}},
+	}, projectForm.RelatedTasks)
+}
+
+func TestParseExport(t *testing.T) {
+	t.Run("api format", func(t *testing.T) {
+		issues, err := parseExport([]byte(`[
+			{"number": 2, "title": "A pull request", "pull_request": {"url": "https://api.github.com/repos/vikunja/example/pulls/2"}},
+			{"number": 1, "title": "An issue", "state": "closed", "comments": 3, "closed_at": "2024-05-03T12:00:00Z", "url": "https://api.github.com/repos/vikunja/example/issues/1", "html_url": "https://github.com/vikunja/example/issues/1"}
+		]`))
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "https://github.com/vikunja/example/issues/1", issues[0].link())
+		assert.Equal(t, time.Date(2024, 5, 3, 12, 0, 0, 0, time.UTC), issues[0].closedAt())
+		assert.Empty(t, issues[0].comments())
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := parseExport([]byte(`{"number": 1}`))
+		require.Error(t, err)
+		assert.True(t, models.IsErrInvalidImportFile(err))
+	})
+}
+
+func TestFileMigrator_Migrate(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+
+	u := &user.User{ID: 1}
+	content := readFixture(t)
+	m := &FileMigrator{}
+	err := m.Migrate(u, strings.NewReader(string(content)), int64(len(content)))
+	require.NoError(t, err)
+
+	s := db.NewSession()
+	defer s.Close()
+
+	getTask := func(title string) *models.Task {
+		task := &models.Task{}
+		has, err := s.Where("title = ?", title).Get(task)
+		require.NoError(t, err)
+		require.True(t, has)
+		return task
+	}
+
+	crash := getTask("Crash when saving a project")
+	duplicate := getTask("Saving fails")
+	validation := getTask("Form validation")
+	projectForm := getTask("Validate project form")
+	taskForm := getTask("Validate task form")
+
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       crash.ID,
+		"other_task_id": validation.ID,
+		"relation_kind": models.RelationKindBlocked,
+	}, false)
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       duplicate.ID,
+		"other_task_id": crash.ID,
+		"relation_kind": models.RelationKindDuplicateOf,
+	}, false)
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       validation.ID,
+		"other_task_id": projectForm.ID,
+		"relation_kind": models.RelationKindSubtask,
+	}, false)
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       taskForm.ID,
+		"other_task_id": validation.ID,
+		"relation_kind": models.RelationKindParenttask,
+	}, false)
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       projectForm.ID,
+		"other_task_id": crash.ID,
+		"relation_kind": models.RelationKindBlocking,
+	}, false)
+	db.AssertExists(t, "task_comments", map[string]interface{}{
+		"task_id": crash.ID,
+		"comment": "<p><em>octocat</em>:</p>\n<p>Thanks!</p>\n",
+	}, false)
+	db.AssertExists(t, "task_attachments", map[string]interface{}{
+		"task_id": crash.ID,
+	}, false)
+	db.AssertExists(t, "projects", map[string]interface{}{
+		"title":    "vikunja/example",
+		"owner_id": u.ID,
+	}, false)
+}
@@ -17,7 +17,10 @@
 package handler
 
 import (
+	"encoding/csv"
 	"net/http"
+	"strconv"
+	"time"
 
 	"code.vikunja.io/api/pkg/modules/migration"
 	user2 "code.vikunja.io/api/pkg/user"
@@ -38,3 +41,57 @@ func status(ms migration.MigratorName, c echo.Context) error {
 
 	return c.JSON(http.StatusOK, status)
 }
+
+// report returns all items which failed or were skipped during the last migration.
+// @Summary Get the report of the last migration
+// @Description Returns all projects, tasks, attachments and other items which could not be migrated or were skipped during the last migration with this migrator. Pass `format=csv` to download the report as a csv file instead.
+// @tags migration
+// @Produce json
+// @Produce text/csv
+// @Security JWTKeyAuth
+// @Param migrator path string true "The name of the migrator, for example trello or todoist."
+// @Param format query string false "Set to `csv` to get the report as a csv file."
+// @Success 200 {array} migration.Item "The failed and skipped items"
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/{migrator}/report [get]
+func report(ms migration.MigratorName, c echo.Context) error {
+	user, err := user2.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	items, err := migration.GetMigrationReport(ms, user)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if c.QueryParam("format") != "csv" {
+		return c.JSON(http.StatusOK, items)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+ms.Name()+`-migration-report.csv"`)
+	c.Response().WriteHeader(http.StatusOK)
+
+	w := csv.NewWriter(c.Response())
+	err = w.Write([]string{"kind", "source_id", "target_id", "state", "title", "message", "created"})
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		err = w.Write([]string{
+			item.Kind,
+			item.SourceID,
+			strconv.FormatInt(item.TargetID, 10),
+			item.State,
+			item.Title,
+			item.Message,
+			item.Created.Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
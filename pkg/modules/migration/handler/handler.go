@@ -49,6 +49,7 @@ func (mw *MigrationWeb) RegisterMigrator(g *echo.Group) {
 	g.GET("/"+ms.Name()+"/auth", mw.AuthURL)
 	g.GET("/"+ms.Name()+"/status", mw.Status)
 	g.POST("/"+ms.Name()+"/migrate", mw.Migrate)
+	g.GET("/"+ms.Name()+"/report", mw.Report)
 	registeredMigrators[ms.Name()] = mw
 }
 
@@ -104,3 +105,10 @@ func (mw *MigrationWeb) Status(c echo.Context) error {
 
 	return status(ms, c)
 }
+
+// Report returns the items which failed or were skipped during the last migration
+func (mw *MigrationWeb) Report(c echo.Context) error {
+	ms := mw.MigrationStruct()
+
+	return report(ms, c)
+}
@@ -18,7 +18,9 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
+	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/modules/migration"
 	user2 "code.vikunja.io/api/pkg/user"
@@ -35,6 +37,7 @@ func (fw *FileMigratorWeb) RegisterRoutes(g *echo.Group) {
 	ms := fw.MigrationStruct()
 	g.GET("/"+ms.Name()+"/status", fw.Status)
 	g.PUT("/"+ms.Name()+"/migrate", fw.Migrate)
+	g.GET("/"+ms.Name()+"/report", fw.Report)
 }
 
 // Migrate calls the migration method
@@ -57,6 +60,28 @@ func (fw *FileMigratorWeb) Migrate(c echo.Context) error {
 	}
 	defer src.Close()
 
+	if withOptions, is := ms.(migration.FileMigratorWithOptions); is {
+		err = withOptions.SetOptions(c.FormValue("options"))
+		if err != nil {
+			return handler.HandleHTTPError(err, c)
+		}
+	}
+
+	dryRun, _ := strconv.ParseBool(c.FormValue("dry_run"))
+	if dryRun {
+		previewer, is := ms.(migration.PreviewFileMigrator)
+		if !is {
+			return echo.NewHTTPError(http.StatusBadRequest, "This migrator does not support dry runs.")
+		}
+
+		preview, err := previewer.Preview(user, src, file.Size)
+		if err != nil {
+			return handler.HandleHTTPError(err, c)
+		}
+
+		return c.JSON(http.StatusOK, preview)
+	}
+
 	m, err := migration.StartMigration(ms, user)
 	if err != nil {
 		return handler.HandleHTTPError(err, c)
@@ -65,6 +90,11 @@ func (fw *FileMigratorWeb) Migrate(c echo.Context) error {
 	// Do the migration
 	err = ms.Migrate(user, src, file.Size)
 	if err != nil {
+		// Finishing the migration even when it failed, otherwise it could not be resumed
+		ferr := migration.FailMigration(m, err)
+		if ferr != nil {
+			log.Errorf("[Migration] Could not finish migration %d for user %d, error was: %s", m.ID, user.ID, ferr.Error())
+		}
 		return handler.HandleHTTPError(err, c)
 	}
 
@@ -73,6 +103,10 @@ func (fw *FileMigratorWeb) Migrate(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
+	if m.FailedItems > 0 {
+		return c.JSON(http.StatusOK, models.Message{Message: "The migration is done, but " + strconv.FormatInt(m.FailedItems, 10) + " items could not be migrated. Check the migration report for details."})
+	}
+
 	return c.JSON(http.StatusOK, models.Message{Message: "Everything was migrated successfully."})
 }
 
@@ -82,3 +116,10 @@ func (fw *FileMigratorWeb) Status(c echo.Context) error {
 
 	return status(ms, c)
 }
+
+// Report returns the items which failed or were skipped during the last migration
+func (fw *FileMigratorWeb) Report(c echo.Context) error {
+	ms := fw.MigrationStruct()
+
+	return report(ms, c)
+}
@@ -0,0 +1,142 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package handler
+
+import (
+	"net/http"
+
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
+	user2 "code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+	"github.com/labstack/echo/v4"
+)
+
+// SyncWeb holds the web handler for continuous syncs with a migrator
+type SyncWeb struct {
+	SyncStruct func() migration.Syncer
+}
+
+// SyncRequest holds the code obtained from the sync auth url
+type SyncRequest struct {
+	Code string `json:"code"`
+}
+
+// RegisterRoutes registers all routes to manage a sync and makes the syncer available to the sync cron
+func (sw *SyncWeb) RegisterRoutes(g *echo.Group) {
+	ms := sw.SyncStruct()
+	g.GET("/"+ms.Name()+"/sync/auth", sw.AuthURL)
+	g.GET("/"+ms.Name()+"/sync", sw.Status)
+	g.PUT("/"+ms.Name()+"/sync", sw.Enable)
+	g.DELETE("/"+ms.Name()+"/sync", sw.Disable)
+	migration.RegisterSyncer(ms)
+}
+
+// AuthURL is the web handler to get the auth url for a sync
+func (sw *SyncWeb) AuthURL(c echo.Context) error {
+	ms := sw.SyncStruct()
+	return c.JSON(http.StatusOK, &AuthURL{URL: ms.SyncAuthURL()})
+}
+
+// Status returns the sync of the current user with this migrator
+// @Summary Get the sync status
+// @Description Returns when the sync of the current user with this service last ran and whether it failed.
+// @tags migration
+// @Produce json
+// @Security JWTKeyAuth
+// @Param migrator path string true "The name of the migrator, either todoist or microsoft-todo."
+// @Success 200 {object} migration.Sync "The sync"
+// @Failure 404 {object} web.HTTPError "The user has no sync with this service."
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/{migrator}/sync [get]
+func (sw *SyncWeb) Status(c echo.Context) error {
+	ms := sw.SyncStruct()
+
+	user, err := user2.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	sc, err := migration.GetSync(ms, user)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, sc)
+}
+
+// Enable sets up a continuous sync for the current user
+// @Summary Start syncing with a service
+// @Description Keeps all projects and tasks of the current user in sync with the service. Changes are synced in both directions periodically. If the user already has a sync with this service, its credentials are replaced.
+// @tags migration
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param migrator path string true "The name of the migrator, either todoist or microsoft-todo."
+// @Param code body handler.SyncRequest true "The auth code previously obtained from the sync auth url."
+// @Success 200 {object} migration.Sync "The sync"
+// @Failure 400 {object} web.HTTPError "No code provided."
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/{migrator}/sync [put]
+func (sw *SyncWeb) Enable(c echo.Context) error {
+	ms := sw.SyncStruct()
+
+	user, err := user2.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	req := &SyncRequest{}
+	err = c.Bind(req)
+	if err != nil || req.Code == "" {
+		return echo.NewHTTPError(http.StatusBadRequest, "No or invalid code provided.")
+	}
+
+	sc, err := migration.EnableSync(ms, user, req.Code)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, sc)
+}
+
+// Disable stops the sync of the current user
+// @Summary Stop syncing with a service
+// @Description Stops the sync with the service. Everything which was synced so far is kept.
+// @tags migration
+// @Produce json
+// @Security JWTKeyAuth
+// @Param migrator path string true "The name of the migrator, either todoist or microsoft-todo."
+// @Success 200 {object} models.Message "The sync was stopped."
+// @Failure 404 {object} web.HTTPError "The user has no sync with this service."
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/{migrator}/sync [delete]
+func (sw *SyncWeb) Disable(c echo.Context) error {
+	ms := sw.SyncStruct()
+
+	user, err := user2.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = migration.DisableSync(ms, user)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, models.Message{Message: "The sync was stopped successfully."})
+}
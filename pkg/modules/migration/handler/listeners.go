@@ -73,6 +73,18 @@ func (s *MigrationListener) Handle(msg *message.Message) (err error) {
 	ms := event.Migrator.(migration.Migrator)
 
 	m, err := migrateInListener(ms, event)
+	if err != nil && m == nil {
+		// The migration was never started, there is nothing to finish
+		log.Errorf("[Migration] Could not start migration from %s for user %d. Error was: %s", event.MigratorKind, event.User.ID, err.Error())
+		nerr := notifications.Notify(event.User, &MigrationFailedNotification{
+			MigratorName: ms.Name(),
+			Error:        err,
+		})
+		if nerr != nil {
+			log.Errorf("[Migration] Could not sent failed migration notification to user %d, error was: %s", event.User.ID, nerr.Error())
+		}
+		return nil
+	}
 	if err != nil {
 		log.Errorf("[Migration] Migration %d from %s for user %d failed. Error was: %s", m.ID, event.MigratorKind, event.User.ID, err.Error())
 
@@ -96,7 +108,7 @@ func (s *MigrationListener) Handle(msg *message.Message) (err error) {
 		}
 
 		// Still need to finish the migration, otherwise restarting will not work
-		err = migration.FinishMigration(m)
+		err = migration.FailMigration(m, err)
 		if err != nil {
 			log.Errorf("[Migration] Could not finish migration %d for user %d, error was: %s", m.ID, event.User.ID, err.Error())
 		}
@@ -125,6 +137,7 @@ func migrateInListener(ms migration.Migrator, event *MigrationRequestedEvent) (m
 
 	err = notifications.Notify(event.User, &MigrationDoneNotification{
 		MigratorName: ms.Name(),
+		FailedItems:  m.FailedItems,
 	})
 	if err != nil {
 		log.Errorf("[Migration] Could not sent migration success notification for migration %d to user %d, error was: %s", m.ID, event.User.ID, err.Error())
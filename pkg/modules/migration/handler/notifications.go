@@ -17,6 +17,8 @@
 package handler
 
 import (
+	"strconv"
+
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 
@@ -27,15 +29,22 @@ import (
 // MigrationDoneNotification represents a MigrationDoneNotification notification
 type MigrationDoneNotification struct {
 	MigratorName string
+	FailedItems  int64
 }
 
 // ToMail returns the mail notification for MigrationDoneNotification
 func (n *MigrationDoneNotification) ToMail() *notifications.Mail {
 	kind := cases.Title(language.English).String(n.MigratorName)
 
-	return notifications.NewMail().
-		Subject("The migration from "+kind+" to Vikunja was completed").
-		Line("Vikunja has imported all lists/projects, tasks, notes, reminders and files from "+kind+" you have access to.").
+	mail := notifications.NewMail().
+		Subject("The migration from " + kind + " to Vikunja was completed").
+		Line("Vikunja has imported all lists/projects, tasks, notes, reminders and files from " + kind + " you have access to.")
+
+	if n.FailedItems > 0 {
+		mail.Line(strconv.FormatInt(n.FailedItems, 10) + " items could not be imported. You can download a report with all of them from the migration page.")
+	}
+
+	return mail.
 		Action("View your imported projects in Vikunja", config.ServicePublicURL.GetString()).
 		Line("Have fun with your new (old) projects!")
 }
@@ -19,9 +19,18 @@ package migration
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
+	"fmt"
+	"html"
 	"net/http"
 	"net/url"
 	"strings"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/utils"
+
+	"github.com/yuin/goldmark"
 )
 
 // DownloadFile downloads a file and returns its contents
@@ -31,6 +40,20 @@ func DownloadFile(url string) (buf *bytes.Buffer, err error) {
 
 // DownloadFileWithHeaders downloads a file and allows you to pass in headers
 func DownloadFileWithHeaders(url string, headers http.Header) (buf *bytes.Buffer, err error) {
+	return downloadFile(&http.Client{}, url, headers)
+}
+
+// DownloadUntrustedFile downloads a file from a url taken from a file the user uploaded. Unless
+// migration.allowprivatehosts is enabled, only files on public hosts are downloaded.
+func DownloadUntrustedFile(url string) (buf *bytes.Buffer, err error) {
+	hc := &http.Client{}
+	if !config.MigrationAllowPrivateHosts.GetBool() {
+		hc = utils.NewPublicHTTPClient(0)
+	}
+	return downloadFile(hc, url, nil)
+}
+
+func downloadFile(hc *http.Client, url string, headers http.Header) (buf *bytes.Buffer, err error) {
 	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
 	if err != nil {
 		return nil, err
@@ -42,12 +65,16 @@ func DownloadFileWithHeaders(url string, headers http.Header) (buf *bytes.Buffer
 		}
 	}
 
-	hc := http.Client{}
 	resp, err := hc.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("got http status %d while downloading %s", resp.StatusCode, url)
+	}
+
 	buf = &bytes.Buffer{}
 	_, err = buf.ReadFrom(resp.Body)
 
@@ -75,3 +102,96 @@ func DoPostWithHeaders(url string, form url.Values, headers map[string]string) (
 	hc := http.Client{}
 	return hc.Do(req)
 }
+
+// ReadCSV parses a csv export and returns its header and all non-empty rows keyed by their column name.
+// If delimiter is 0, a comma is used.
+func ReadCSV(content []byte, delimiter rune) (header []string, records []map[string]string, err error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))))
+	if delimiter != 0 {
+		reader.Comma = delimiter
+	}
+	reader.FieldsPerRecord = -1
+
+	lines, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, &models.ErrInvalidImportFile{Reason: err.Error()}
+	}
+	if len(lines) == 0 {
+		return nil, nil, &models.ErrInvalidImportFile{Reason: "the file is empty"}
+	}
+
+	header = lines[0]
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	records = make([]map[string]string, 0, len(lines)-1)
+	for _, line := range lines[1:] {
+		record := make(map[string]string, len(header))
+		empty := true
+		for i, value := range line {
+			if i >= len(header) {
+				break
+			}
+			record[header[i]] = value
+			if strings.TrimSpace(value) != "" {
+				empty = false
+			}
+		}
+		if empty {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	return
+}
+
+// CustomField is a field of a task in another service which does not have an equivalent in Vikunja.
+type CustomField struct {
+	Name  string
+	Value string
+}
+
+// CustomFieldsToHTML renders custom fields as a html list which can be appended to a task description.
+// Fields without a value are left out.
+func CustomFieldsToHTML(fields []*CustomField) string {
+	var buf strings.Builder
+	for _, f := range fields {
+		if strings.TrimSpace(f.Value) == "" {
+			continue
+		}
+		buf.WriteString("<li><strong>" + html.EscapeString(f.Name) + "</strong>: " + html.EscapeString(strings.TrimSpace(f.Value)) + "</li>")
+	}
+	if buf.Len() == 0 {
+		return ""
+	}
+	return "<ul>" + buf.String() + "</ul>"
+}
+
+// ConvertMarkdownToHTML converts markdown, as used by most other services, to the html Vikunja uses for
+// descriptions and comments.
+func ConvertMarkdownToHTML(input string) (output string, err error) {
+	var buf bytes.Buffer
+	err = goldmark.Convert([]byte(input), &buf)
+	if err != nil {
+		return
+	}
+	//#nosec - we are not responsible to escape this as we don't know the context where it is used
+	return buf.String(), nil
+}
+
+// PlainTextToHTML escapes plain text and converts its paragraphs and line breaks to html.
+func PlainTextToHTML(text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return ""
+	}
+
+	paragraphs := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n")
+	var buf strings.Builder
+	for _, p := range paragraphs {
+		buf.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(strings.TrimSpace(p)), "\n", "<br>") + "</p>")
+	}
+	return buf.String()
+}
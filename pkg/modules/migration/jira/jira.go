@@ -0,0 +1,689 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package jira
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"html"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
+	"code.vikunja.io/api/pkg/user"
+)
+
+const logPrefix = "[Jira Migration] "
+
+const (
+	xmlTimeFormat      = "Mon, 2 Jan 2006 15:04:05 -0700"
+	jsonTimeFormat     = "2006-01-02T15:04:05.000-0700"
+	jsonDueDateFormat  = "2006-01-02"
+	epicLinkFieldName  = "Epic Link"
+	statusCategoryDone = "done"
+)
+
+// FileMigrator imports Jira issues from an xml (RSS) or json (REST search result) export.
+type FileMigrator struct {
+}
+
+// issue is the format independent representation of a Jira issue.
+type issue struct {
+	Key         string
+	Link        string
+	BaseURL     string
+	ProjectKey  string
+	ProjectName string
+	Summary     string
+	Description string // html
+	Priority    string
+	Status      string
+	Done        bool
+	DoneAt      time.Time
+	DueDate     time.Time
+	Labels      []string
+	Parent      string
+	EpicLink    string
+	Links       []*issueLink
+	Comments    []*issueComment
+	Attachments []*issueAttachment
+}
+
+type issueLink struct {
+	Kind models.RelationKind
+	Key  string
+}
+
+type issueComment struct {
+	Author  string
+	Body    string // html
+	Created time.Time
+	Updated time.Time
+}
+
+type issueAttachment struct {
+	Name string
+	Mime string
+	URL  string
+}
+
+var priorityMap = map[string]int64{
+	"lowest":   1,
+	"low":      1,
+	"minor":    1,
+	"trivial":  1,
+	"medium":   2,
+	"high":     3,
+	"major":    3,
+	"highest":  4,
+	"critical": 4,
+	"blocker":  5,
+}
+
+// Name is used to get the name of the jira migration - we're using the docs here to annotate the status route.
+// @Summary Get migration status
+// @Description Returns if the current user already did the migation or not. This is useful to show a confirmation message in the frontend if the user is trying to do the same migration again.
+// @tags migration
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} migration.Status "The migration status"
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/jira/status [get]
+func (m *FileMigrator) Name() string {
+	return "jira"
+}
+
+// Migrate takes a jira export, parses it and imports everything in it into Vikunja.
+// @Summary Import all issues from a Jira export
+// @Description Imports all issues, comments, labels, links and attachments from a Jira xml (RSS) or json (REST search result) export into Vikunja. Every Jira project becomes a project, epics and parent issues become parent tasks.
+// @tags migration
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security JWTKeyAuth
+// @Param import formData string true "The Jira xml or json export."
+// @Success 200 {object} models.Message "A message telling you everything was migrated successfully."
+// @Failure 400 {object} web.HTTPError "The file could not be parsed."
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/jira/migrate [post]
+func (m *FileMigrator) Migrate(u *user.User, file io.ReaderAt, size int64) error {
+	content, err := io.ReadAll(io.NewSectionReader(file, 0, size))
+	if err != nil {
+		return err
+	}
+
+	issues, err := parseExport(content)
+	if err != nil {
+		return err
+	}
+
+	if len(issues) == 0 {
+		return &models.ErrInvalidImportFile{Reason: "the file does not contain any issues"}
+	}
+
+	log.Debugf(logPrefix+"Importing %d issues for user %d", len(issues), u.ID)
+
+	structure := convertJiraIssuesToVikunja(issues)
+	return migration.InsertFromStructure(structure, u)
+}
+
+func parseExport(content []byte) ([]*issue, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+	if len(trimmed) == 0 {
+		return nil, &models.ErrInvalidImportFile{Reason: "the file is empty"}
+	}
+
+	switch trimmed[0] {
+	case '<':
+		return parseXML(trimmed)
+	case '{':
+		return parseJSON(trimmed)
+	default:
+		return nil, &models.ErrInvalidImportFile{Reason: "the file is neither a Jira xml nor a json export"}
+	}
+}
+
+type xmlExport struct {
+	Items []*xmlItem `xml:"channel>item"`
+}
+
+type xmlItem struct {
+	Link    string `xml:"link"`
+	Project struct {
+		Key  string `xml:"key,attr"`
+		Name string `xml:",chardata"`
+	} `xml:"project"`
+	Description    string `xml:"description"`
+	Key            string `xml:"key"`
+	Summary        string `xml:"summary"`
+	Parent         string `xml:"parent"`
+	Priority       string `xml:"priority"`
+	Status         string `xml:"status"`
+	StatusCategory struct {
+		Key string `xml:"key,attr"`
+	} `xml:"statusCategory"`
+	Labels   []string `xml:"labels>label"`
+	Resolved string   `xml:"resolved"`
+	Due      string   `xml:"due"`
+	Comments []struct {
+		Author  string `xml:"author,attr"`
+		Created string `xml:"created,attr"`
+		Body    string `xml:",chardata"`
+	} `xml:"comments>comment"`
+	IssueLinkTypes []struct {
+		Name    string   `xml:"name"`
+		Outward []string `xml:"outwardlinks>issuelink>issuekey"`
+		Inward  []string `xml:"inwardlinks>issuelink>issuekey"`
+	} `xml:"issuelinks>issuelinktype"`
+	Attachments []struct {
+		ID   string `xml:"id,attr"`
+		Name string `xml:"name,attr"`
+	} `xml:"attachments>attachment"`
+	CustomFields []struct {
+		Name   string   `xml:"customfieldname"`
+		Values []string `xml:"customfieldvalues>customfieldvalue"`
+	} `xml:"customfields>customfield"`
+}
+
+func parseXMLTime(value string) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(xmlTimeFormat, value)
+	if err != nil {
+		log.Debugf(logPrefix+"Could not parse date %s: %s", value, err)
+		return time.Time{}
+	}
+	return t
+}
+
+func parseXML(content []byte) (issues []*issue, err error) {
+	export := &xmlExport{}
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	// Jira exports may contain html entities in the text content
+	decoder.Strict = false
+	decoder.Entity = xml.HTMLEntity
+	err = decoder.Decode(export)
+	if err != nil {
+		return nil, &models.ErrInvalidImportFile{Reason: "could not parse the xml: " + err.Error()}
+	}
+
+	for _, item := range export.Items {
+		i := &issue{
+			Key:         strings.TrimSpace(item.Key),
+			Link:        strings.TrimSpace(item.Link),
+			ProjectKey:  item.Project.Key,
+			ProjectName: strings.TrimSpace(item.Project.Name),
+			Summary:     strings.TrimSpace(item.Summary),
+			Description: strings.TrimSpace(item.Description),
+			Priority:    strings.TrimSpace(item.Priority),
+			Status:      strings.TrimSpace(item.Status),
+			DoneAt:      parseXMLTime(item.Resolved),
+			DueDate:     parseXMLTime(item.Due),
+			Labels:      item.Labels,
+			Parent:      strings.TrimSpace(item.Parent),
+		}
+		i.Done = item.StatusCategory.Key == statusCategoryDone || !i.DoneAt.IsZero()
+		if index := strings.Index(i.Link, "/browse/"); index > 0 {
+			i.BaseURL = i.Link[:index]
+		}
+
+		for _, field := range item.CustomFields {
+			if field.Name == epicLinkFieldName && len(field.Values) > 0 {
+				i.EpicLink = strings.TrimSpace(field.Values[0])
+			}
+		}
+
+		for _, c := range item.Comments {
+			created := parseXMLTime(c.Created)
+			i.Comments = append(i.Comments, &issueComment{
+				Author:  c.Author,
+				Body:    strings.TrimSpace(c.Body),
+				Created: created,
+				Updated: created,
+			})
+		}
+
+		for _, linkType := range item.IssueLinkTypes {
+			outward, inward := getRelationKindsForLinkType(linkType.Name)
+			for _, key := range linkType.Outward {
+				i.Links = append(i.Links, &issueLink{Kind: outward, Key: strings.TrimSpace(key)})
+			}
+			for _, key := range linkType.Inward {
+				i.Links = append(i.Links, &issueLink{Kind: inward, Key: strings.TrimSpace(key)})
+			}
+		}
+
+		for _, a := range item.Attachments {
+			if i.BaseURL == "" {
+				log.Debugf(logPrefix+"Could not determine the jira url for issue %s, not downloading attachment %s", i.Key, a.Name)
+				continue
+			}
+			i.Attachments = append(i.Attachments, &issueAttachment{
+				Name: a.Name,
+				URL:  i.BaseURL + "/secure/attachment/" + a.ID + "/" + a.Name,
+			})
+		}
+
+		issues = append(issues, i)
+	}
+
+	return
+}
+
+type jsonExport struct {
+	Issues []*jsonIssue `json:"issues"`
+	// Names maps the field ids to their names, it is only present when the export was done with expand=names.
+	Names map[string]string `json:"names"`
+}
+
+type jsonKey struct {
+	Key string `json:"key"`
+}
+
+type jsonName struct {
+	Name string `json:"name"`
+}
+
+type jsonIssue struct {
+	Key    string                     `json:"key"`
+	Self   string                     `json:"self"`
+	Fields map[string]json.RawMessage `json:"fields"`
+}
+
+type jsonFields struct {
+	Summary     string          `json:"summary"`
+	Description json.RawMessage `json:"description"`
+	Project     struct {
+		Key  string `json:"key"`
+		Name string `json:"name"`
+	} `json:"project"`
+	Parent   *jsonKey  `json:"parent"`
+	Priority *jsonName `json:"priority"`
+	Status   struct {
+		Name           string `json:"name"`
+		StatusCategory struct {
+			Key string `json:"key"`
+		} `json:"statusCategory"`
+	} `json:"status"`
+	ResolutionDate string   `json:"resolutiondate"`
+	DueDate        string   `json:"duedate"`
+	Labels         []string `json:"labels"`
+	Comment        struct {
+		Comments []struct {
+			Author struct {
+				DisplayName string `json:"displayName"`
+			} `json:"author"`
+			Body    json.RawMessage `json:"body"`
+			Created string          `json:"created"`
+			Updated string          `json:"updated"`
+		} `json:"comments"`
+	} `json:"comment"`
+	IssueLinks []struct {
+		Type         jsonName `json:"type"`
+		InwardIssue  *jsonKey `json:"inwardIssue"`
+		OutwardIssue *jsonKey `json:"outwardIssue"`
+	} `json:"issuelinks"`
+	Attachment []struct {
+		Filename string `json:"filename"`
+		MimeType string `json:"mimeType"`
+		Content  string `json:"content"`
+	} `json:"attachment"`
+}
+
+func parseJSONTime(value string, format string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(format, value)
+	if err != nil {
+		log.Debugf(logPrefix+"Could not parse date %s: %s", value, err)
+		return time.Time{}
+	}
+	return t
+}
+
+func parseJSON(content []byte) (issues []*issue, err error) {
+	export := &jsonExport{}
+	err = json.Unmarshal(content, export)
+	if err != nil {
+		return nil, &models.ErrInvalidImportFile{Reason: "could not parse the json: " + err.Error()}
+	}
+
+	epicLinkField := ""
+	for id, name := range export.Names {
+		if name == epicLinkFieldName {
+			epicLinkField = id
+		}
+	}
+
+	for _, ji := range export.Issues {
+		raw, err := json.Marshal(ji.Fields)
+		if err != nil {
+			return nil, err
+		}
+		fields := &jsonFields{}
+		err = json.Unmarshal(raw, fields)
+		if err != nil {
+			return nil, &models.ErrInvalidImportFile{Reason: "could not parse the fields of issue " + ji.Key + ": " + err.Error()}
+		}
+
+		i := &issue{
+			Key:         ji.Key,
+			ProjectKey:  fields.Project.Key,
+			ProjectName: fields.Project.Name,
+			Summary:     strings.TrimSpace(fields.Summary),
+			Description: richTextToHTML(fields.Description),
+			Status:      fields.Status.Name,
+			DoneAt:      parseJSONTime(fields.ResolutionDate, jsonTimeFormat),
+			DueDate:     parseJSONTime(fields.DueDate, jsonDueDateFormat),
+			Labels:      fields.Labels,
+		}
+		i.Done = fields.Status.StatusCategory.Key == statusCategoryDone || !i.DoneAt.IsZero()
+		if index := strings.Index(ji.Self, "/rest/"); index > 0 {
+			i.BaseURL = ji.Self[:index]
+			i.Link = i.BaseURL + "/browse/" + ji.Key
+		}
+		if fields.Priority != nil {
+			i.Priority = fields.Priority.Name
+		}
+		if fields.Parent != nil {
+			i.Parent = fields.Parent.Key
+		}
+		if epicLinkField != "" {
+			var epic string
+			if value, has := ji.Fields[epicLinkField]; has && json.Unmarshal(value, &epic) == nil {
+				i.EpicLink = epic
+			}
+		}
+
+		for _, c := range fields.Comment.Comments {
+			i.Comments = append(i.Comments, &issueComment{
+				Author:  c.Author.DisplayName,
+				Body:    richTextToHTML(c.Body),
+				Created: parseJSONTime(c.Created, jsonTimeFormat),
+				Updated: parseJSONTime(c.Updated, jsonTimeFormat),
+			})
+		}
+
+		for _, link := range fields.IssueLinks {
+			outward, inward := getRelationKindsForLinkType(link.Type.Name)
+			if link.OutwardIssue != nil {
+				i.Links = append(i.Links, &issueLink{Kind: outward, Key: link.OutwardIssue.Key})
+			}
+			if link.InwardIssue != nil {
+				i.Links = append(i.Links, &issueLink{Kind: inward, Key: link.InwardIssue.Key})
+			}
+		}
+
+		for _, a := range fields.Attachment {
+			i.Attachments = append(i.Attachments, &issueAttachment{
+				Name: a.Filename,
+				Mime: a.MimeType,
+				URL:  a.Content,
+			})
+		}
+
+		issues = append(issues, i)
+	}
+
+	return
+}
+
+// richTextToHTML converts a Jira text field to html. Older versions of the api return the text as a string in
+// the wiki markup, newer ones return it in the Atlassian Document Format.
+func richTextToHTML(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var text string
+	if json.Unmarshal(raw, &text) == nil {
+		return migration.PlainTextToHTML(text)
+	}
+
+	doc := &adfNode{}
+	if err := json.Unmarshal(raw, doc); err != nil {
+		log.Debugf(logPrefix+"Could not parse rich text: %s", err)
+		return ""
+	}
+
+	var buf strings.Builder
+	doc.writeHTML(&buf)
+	return buf.String()
+}
+
+// adfNode is a node of a document in the Atlassian Document Format.
+type adfNode struct {
+	Type    string     `json:"type"`
+	Text    string     `json:"text"`
+	Content []*adfNode `json:"content"`
+}
+
+func (n *adfNode) writeHTML(buf *strings.Builder) {
+	var tag string
+	switch n.Type {
+	case "text":
+		buf.WriteString(html.EscapeString(n.Text))
+		return
+	case "hardBreak":
+		buf.WriteString("<br>")
+		return
+	case "paragraph":
+		tag = "p"
+	case "bulletList":
+		tag = "ul"
+	case "orderedList":
+		tag = "ol"
+	case "listItem":
+		tag = "li"
+	case "codeBlock":
+		tag = "pre"
+	case "blockquote":
+		tag = "blockquote"
+	}
+
+	if tag != "" {
+		buf.WriteString("<" + tag + ">")
+	}
+	for _, c := range n.Content {
+		c.writeHTML(buf)
+	}
+	if tag != "" {
+		buf.WriteString("</" + tag + ">")
+	}
+}
+
+// getRelationKindsForLinkType returns the relation kinds for the outward and the inward direction of a Jira link type.
+func getRelationKindsForLinkType(name string) (outward models.RelationKind, inward models.RelationKind) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "blocks":
+		return models.RelationKindBlocking, models.RelationKindBlocked
+	case "duplicate":
+		return models.RelationKindDuplicateOf, models.RelationKindDuplicates
+	case "cloners":
+		return models.RelationKindCopiedFrom, models.RelationKindCopiedTo
+	default:
+		return models.RelationKindRelated, models.RelationKindRelated
+	}
+}
+
+func addRelation(task *models.TaskWithComments, kind models.RelationKind, otherID int64) {
+	if otherID == task.ID {
+		return
+	}
+	if task.RelatedTasks == nil {
+		task.RelatedTasks = make(models.RelatedTaskMap)
+	}
+	for _, rt := range task.RelatedTasks[kind] {
+		if rt.ID == otherID {
+			return
+		}
+	}
+	task.RelatedTasks[kind] = append(task.RelatedTasks[kind], &models.Task{ID: otherID})
+}
+
+func downloadAttachment(i *issue, a *issueAttachment) *models.TaskAttachment {
+	log.Debugf(logPrefix+"Downloading attachment %s of issue %s", a.Name, i.Key)
+	buf, err := migration.DownloadUntrustedFile(a.URL)
+	if err != nil {
+		log.Errorf(logPrefix+"Could not download attachment %s of issue %s: %s", a.Name, i.Key, err)
+		return nil
+	}
+
+	mime := a.Mime
+	if mime == "" {
+		mime = http.DetectContentType(buf.Bytes())
+	}
+
+	return &models.TaskAttachment{
+		File: &files.File{
+			Name:        a.Name,
+			Mime:        mime,
+			Size:        uint64(buf.Len()),
+			FileContent: buf.Bytes(),
+		},
+	}
+}
+
+// convertJiraIssuesToVikunja converts the issues into a project structure. All Jira projects are created as child
+// projects of a "Migrated from Jira" project, epics and parent issues become parent tasks.
+func convertJiraIssuesToVikunja(issues []*issue) (result []*models.ProjectWithTasksAndBuckets) {
+	var pseudoParentID int64 = 1
+	result = []*models.ProjectWithTasksAndBuckets{
+		{
+			Project: models.Project{
+				ID:    pseudoParentID,
+				Title: "Migrated from Jira",
+			},
+			SourceID: migration.RootProjectSourceID,
+		},
+	}
+
+	taskIDsByKey := make(map[string]int64, len(issues))
+	for index, i := range issues {
+		taskIDsByKey[i.Key] = int64(index + 1)
+	}
+
+	projects := make(map[string]*models.ProjectWithTasksAndBuckets)
+	bucketIDsByProject := make(map[string]map[string]int64)
+	var bucketID int64 = 1
+
+	for index, i := range issues {
+		project, has := projects[i.ProjectKey]
+		if !has {
+			title := i.ProjectName
+			if title == "" {
+				title = i.ProjectKey
+			}
+			project = &models.ProjectWithTasksAndBuckets{
+				Project: models.Project{
+					ID:              int64(len(projects)+1) + pseudoParentID,
+					ParentProjectID: pseudoParentID,
+					Title:           title,
+				},
+				SourceID: i.ProjectKey,
+			}
+			projects[i.ProjectKey] = project
+			bucketIDsByProject[i.ProjectKey] = make(map[string]int64)
+			result = append(result, project)
+		}
+
+		task := &models.TaskWithComments{
+			Task: models.Task{
+				ID:          int64(index + 1),
+				Title:       i.Summary,
+				Description: i.Description,
+				Done:        i.Done,
+				DoneAt:      i.DoneAt,
+				DueDate:     i.DueDate,
+				Priority:    priorityMap[strings.ToLower(i.Priority)],
+			},
+			SourceID: i.Key,
+		}
+		if task.Title == "" {
+			task.Title = i.Key
+		}
+		if i.Link != "" {
+			task.Description += `<p>Migrated from <a href="` + html.EscapeString(i.Link) + `">` + html.EscapeString(i.Key) + "</a></p>"
+		}
+
+		if i.Status != "" {
+			statusBucketID, has := bucketIDsByProject[i.ProjectKey][i.Status]
+			if !has {
+				statusBucketID = bucketID
+				bucketID++
+				bucketIDsByProject[i.ProjectKey][i.Status] = statusBucketID
+				project.Buckets = append(project.Buckets, &models.Bucket{
+					ID:    statusBucketID,
+					Title: i.Status,
+				})
+			}
+			task.BucketID = statusBucketID
+		}
+
+		for _, label := range i.Labels {
+			task.Labels = append(task.Labels, &models.Label{Title: label})
+		}
+
+		for _, c := range i.Comments {
+			task.Comments = append(task.Comments, &models.TaskComment{
+				Comment: "<p><em>" + html.EscapeString(c.Author) + "</em>:</p>" + c.Body,
+				Created: c.Created,
+				Updated: c.Updated,
+			})
+		}
+
+		for _, parent := range []string{i.Parent, i.EpicLink} {
+			if parent == "" {
+				continue
+			}
+			parentID, has := taskIDsByKey[parent]
+			if !has {
+				log.Debugf(logPrefix+"Parent %s of issue %s is not part of the export", parent, i.Key)
+				continue
+			}
+			addRelation(task, models.RelationKindParenttask, parentID)
+		}
+
+		for _, link := range i.Links {
+			otherID, has := taskIDsByKey[link.Key]
+			if !has {
+				log.Debugf(logPrefix+"Linked issue %s of issue %s is not part of the export", link.Key, i.Key)
+				continue
+			}
+			addRelation(task, link.Kind, otherID)
+		}
+
+		for _, a := range i.Attachments {
+			attachment := downloadAttachment(i, a)
+			if attachment != nil {
+				task.Attachments = append(task.Attachments, attachment)
+			}
+		}
+
+		project.Tasks = append(project.Tasks, task)
+	}
+
+	return
+}
@@ -0,0 +1,274 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package jira
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readFixture reads a recorded export and points all attachment urls in it to a local test server.
+func readFixture(t *testing.T, filename string, originalURL string) []byte {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("attachment content of " + r.URL.Path))
+	}))
+	t.Cleanup(server.Close)
+
+	// The test server only listens on localhost
+	config.MigrationAllowPrivateHosts.Set(true)
+	t.Cleanup(func() {
+		config.MigrationAllowPrivateHosts.Set(false)
+	})
+
+	content, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	return []byte(strings.ReplaceAll(string(content), originalURL, server.URL))
+}
+
+func findTask(t *testing.T, structure []*models.ProjectWithTasksAndBuckets, title string) (*models.ProjectWithTasksAndBuckets, *models.TaskWithComments) {
+	for _, p := range structure {
+		for _, task := range p.Tasks {
+			if task.Title == title {
+				return p, task
+			}
+		}
+	}
+	t.Fatalf("task %s not found", title)
+	return nil, nil
+}
+
+func TestConvertJiraXML(t *testing.T) {
+	content := readFixture(t, "export.xml", "https://jira.example.com")
+	issues, err := parseExport(content)
+	require.NoError(t, err)
+	require.Len(t, issues, 5)
+
+	structure := convertJiraIssuesToVikunja(issues)
+	require.Len(t, structure, 3)
+	assert.Equal(t, "Migrated from Jira", structure[0].Title)
+	assert.Equal(t, "Demo Project", structure[1].Title)
+	assert.Equal(t, "DEMO", structure[1].SourceID)
+	assert.Equal(t, structure[0].ID, structure[1].ParentProjectID)
+	assert.Equal(t, "Operations", structure[2].Title)
+	require.Len(t, structure[1].Tasks, 4)
+	require.Len(t, structure[1].Buckets, 3)
+	assert.Equal(t, "In Progress", structure[1].Buckets[0].Title)
+	assert.Equal(t, "To Do", structure[1].Buckets[1].Title)
+	assert.Equal(t, "Done", structure[1].Buckets[2].Title)
+
+	epic := structure[1].Tasks[0]
+	assert.Equal(t, "Checkout redesign", epic.Title)
+	assert.Equal(t, "DEMO-1", epic.SourceID)
+	assert.Equal(t, int64(3), epic.Priority)
+	assert.False(t, epic.Done)
+
+	story := structure[1].Tasks[1]
+	assert.Equal(t, "Add payment provider", story.Title)
+	assert.Equal(t, int64(5), story.Priority)
+	assert.Equal(t, structure[1].Buckets[1].ID, story.BucketID)
+	assert.True(t, strings.HasPrefix(story.Description, "<p>We need to support <b>another</b> payment provider.</p>"))
+	assert.Contains(t, story.Description, `/browse/DEMO-2">DEMO-2</a>`)
+	assert.Equal(t, time.Date(2024, 6, 14, 0, 0, 0, 0, time.UTC), story.DueDate.UTC())
+	require.Len(t, story.Labels, 2)
+	assert.Equal(t, "backend", story.Labels[0].Title)
+	assert.Equal(t, "payments", story.Labels[1].Title)
+	require.Len(t, story.Comments, 1)
+	assert.Equal(t, "<p><em>asmith</em>:</p><p>Which provider should we use?</p>", story.Comments[0].Comment)
+	assert.Equal(t, time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC), story.Comments[0].Created.UTC())
+	require.Len(t, story.Attachments, 1)
+	assert.Equal(t, "flow.txt", story.Attachments[0].File.Name)
+	assert.Equal(t, "attachment content of /secure/attachment/10200/flow.txt", string(story.Attachments[0].File.FileContent))
+	assert.Equal(t, []*models.Task{{ID: epic.ID}}, story.RelatedTasks[models.RelationKindParenttask])
+	assert.Equal(t, []*models.Task{{ID: 3}}, story.RelatedTasks[models.RelationKindBlocking])
+
+	_, done := findTask(t, structure, "Update terms of service")
+	assert.True(t, done.Done)
+	assert.Equal(t, time.Date(2024, 6, 6, 14, 45, 0, 0, time.UTC), done.DoneAt.UTC())
+	assert.Equal(t, int64(1), done.Priority)
+	assert.Equal(t, []*models.Task{{ID: 2}}, done.RelatedTasks[models.RelationKindBlocked])
+	assert.Equal(t, []*models.Task{{ID: 5}}, done.RelatedTasks[models.RelationKindDuplicates])
+
+	_, subtask := findTask(t, structure, "Write integration tests")
+	assert.True(t, strings.HasPrefix(subtask.Description, "<p>Cover the happy path &amp; refunds.</p>"))
+	assert.Equal(t, []*models.Task{{ID: 2}}, subtask.RelatedTasks[models.RelationKindParenttask])
+
+	_, duplicate := findTask(t, structure, "Terms of service are outdated")
+	assert.Equal(t, int64(4), duplicate.Priority)
+	assert.Equal(t, []*models.Task{{ID: 3}}, duplicate.RelatedTasks[models.RelationKindDuplicateOf])
+	require.Len(t, duplicate.Comments, 1)
+	assert.Equal(t, "<p><em>support</em>:</p><p>Customer mail attached in the ticket system.</p>", duplicate.Comments[0].Comment)
+}
+
+func TestDownloadAttachment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte("content"))
+	}))
+	defer server.Close()
+	i := &issue{Key: "DEMO-1"}
+
+	t.Run("private host", func(t *testing.T) {
+		attachment := downloadAttachment(i, &issueAttachment{Name: "file.txt", URL: server.URL + "/file.txt"})
+		assert.Nil(t, attachment)
+	})
+	t.Run("private hosts allowed", func(t *testing.T) {
+		config.MigrationAllowPrivateHosts.Set(true)
+		defer config.MigrationAllowPrivateHosts.Set(false)
+
+		attachment := downloadAttachment(i, &issueAttachment{Name: "file.txt", URL: server.URL + "/file.txt"})
+		require.NotNil(t, attachment)
+		assert.Equal(t, "content", string(attachment.File.FileContent))
+	})
+	t.Run("error response", func(t *testing.T) {
+		config.MigrationAllowPrivateHosts.Set(true)
+		defer config.MigrationAllowPrivateHosts.Set(false)
+
+		attachment := downloadAttachment(i, &issueAttachment{Name: "file.txt", URL: server.URL + "/missing"})
+		assert.Nil(t, attachment)
+	})
+}
+
+func TestConvertJiraJSON(t *testing.T) {
+	content := readFixture(t, "export.json", "https://example.atlassian.net")
+	issues, err := parseExport(content)
+	require.NoError(t, err)
+	require.Len(t, issues, 3)
+
+	structure := convertJiraIssuesToVikunja(issues)
+	require.Len(t, structure, 2)
+	assert.Equal(t, "Website", structure[1].Title)
+	require.Len(t, structure[1].Tasks, 3)
+
+	epic := structure[1].Tasks[0]
+	assert.True(t, strings.HasPrefix(epic.Description, "<p>All work for the new landing page.</p>"))
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), epic.DueDate)
+	assert.Equal(t, int64(2), epic.Priority)
+
+	story := structure[1].Tasks[1]
+	assert.Equal(t, "Design hero section", story.Title)
+	assert.True(t, story.Done)
+	assert.Equal(t, time.Date(2024, 6, 5, 15, 30, 0, 0, time.UTC), story.DoneAt.UTC())
+	assert.True(t, strings.HasPrefix(story.Description, "<p>Use the new brand colors.</p><p>See the &lt;style guide&gt;.</p>"))
+	assert.Equal(t, []*models.Task{{ID: epic.ID}}, story.RelatedTasks[models.RelationKindParenttask])
+	assert.Equal(t, []*models.Task{{ID: 3}}, story.RelatedTasks[models.RelationKindCopiedTo])
+	// OLD-9 is not part of the export
+	assert.Empty(t, story.RelatedTasks[models.RelationKindRelated])
+	require.Len(t, story.Comments, 1)
+	assert.Equal(t, "<p><em>Mia Krystof</em>:</p><p>Looks great!</p>", story.Comments[0].Comment)
+	assert.Equal(t, time.Date(2024, 6, 4, 10, 0, 0, 0, time.UTC), story.Comments[0].Created.UTC())
+	assert.Equal(t, time.Date(2024, 6, 4, 10, 5, 0, 0, time.UTC), story.Comments[0].Updated.UTC())
+	require.Len(t, story.Attachments, 1)
+	assert.Equal(t, "hero.txt", story.Attachments[0].File.Name)
+	assert.Equal(t, "text/plain", story.Attachments[0].File.Mime)
+
+	clone := structure[1].Tasks[2]
+	assert.Equal(t, []*models.Task{{ID: epic.ID}}, clone.RelatedTasks[models.RelationKindParenttask])
+	assert.Equal(t, []*models.Task{{ID: 2}}, clone.RelatedTasks[models.RelationKindCopiedFrom])
+}
+
+func TestParseExport(t *testing.T) {
+	t.Run("empty", func(t *testing.T) {
+		_, err := parseExport([]byte("  "))
+		require.Error(t, err)
+		assert.True(t, models.IsErrInvalidImportFile(err))
+	})
+	t.Run("unknown format", func(t *testing.T) {
+		_, err := parseExport([]byte("Key,Summary\nDEMO-1,Test"))
+		require.Error(t, err)
+		assert.True(t, models.IsErrInvalidImportFile(err))
+	})
+	t.Run("invalid json", func(t *testing.T) {
+		_, err := parseExport([]byte(`{"issues": [`))
+		require.Error(t, err)
+		assert.True(t, models.IsErrInvalidImportFile(err))
+	})
+}
+
+func TestFileMigrator_Migrate(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+
+	u := &user.User{ID: 1}
+	content := readFixture(t, "export.xml", "https://jira.example.com")
+	m := &FileMigrator{}
+	err := m.Migrate(u, strings.NewReader(string(content)), int64(len(content)))
+	require.NoError(t, err)
+
+	s := db.NewSession()
+	defer s.Close()
+
+	getTask := func(title string) *models.Task {
+		task := &models.Task{}
+		has, err := s.Where("title = ?", title).Get(task)
+		require.NoError(t, err)
+		require.True(t, has)
+		return task
+	}
+
+	epic := getTask("Checkout redesign")
+	story := getTask("Add payment provider")
+	done := getTask("Update terms of service")
+	subtask := getTask("Write integration tests")
+	duplicate := getTask("Terms of service are outdated")
+
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       story.ID,
+		"other_task_id": epic.ID,
+		"relation_kind": models.RelationKindParenttask,
+	}, false)
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       subtask.ID,
+		"other_task_id": story.ID,
+		"relation_kind": models.RelationKindParenttask,
+	}, false)
+	// The blocked task is created after the blocking one
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       story.ID,
+		"other_task_id": done.ID,
+		"relation_kind": models.RelationKindBlocking,
+	}, false)
+	// The duplicate lives in another project
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       duplicate.ID,
+		"other_task_id": done.ID,
+		"relation_kind": models.RelationKindDuplicateOf,
+	}, false)
+	db.AssertExists(t, "task_comments", map[string]interface{}{
+		"task_id": duplicate.ID,
+		"comment": "<p><em>support</em>:</p><p>Customer mail attached in the ticket system.</p>",
+	}, false)
+	db.AssertExists(t, "task_attachments", map[string]interface{}{
+		"task_id": story.ID,
+	}, false)
+	db.AssertExists(t, "projects", map[string]interface{}{
+		"title":    "Operations",
+		"owner_id": u.ID,
+	}, false)
+}
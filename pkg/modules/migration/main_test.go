@@ -23,7 +23,9 @@ import (
 	"code.vikunja.io/api/pkg/events"
 
 	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/user"
 )
@@ -39,6 +41,16 @@ func TestMain(m *testing.M) {
 	files.InitTests()
 	user.InitTests()
 	models.SetupTests()
+
+	x, err := db.CreateTestEngine()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = x.Sync2(GetTables()...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
 	events.Fake()
 	os.Exit(m.Run())
 }
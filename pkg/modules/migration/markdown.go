@@ -0,0 +1,228 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ConvertHTMLToMarkdown converts the html Vikunja uses for descriptions to markdown, for services which
+// only understand markdown. Formatting without a markdown equivalent is dropped, the text is kept.
+func ConvertHTMLToMarkdown(input string) (output string, err error) {
+	nodes, err := html.ParseFragment(strings.NewReader(input), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	md := &markdownWriter{}
+	for _, n := range nodes {
+		md.writeNode(n)
+	}
+
+	return strings.TrimSpace(md.buf.String()), nil
+}
+
+type markdownWriter struct {
+	buf strings.Builder
+	// The list items are currently written in, the innermost last. Zero for unordered lists, the
+	// number of the next item otherwise.
+	lists []int
+	pre   bool
+}
+
+func (md *markdownWriter) writeChildren(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		md.writeNode(c)
+	}
+}
+
+// block makes sure the following content starts in a new paragraph.
+func (md *markdownWriter) block() {
+	out := md.buf.String()
+	if out == "" || strings.HasSuffix(out, "\n\n") {
+		return
+	}
+	if strings.HasSuffix(out, "\n") {
+		md.buf.WriteString("\n")
+		return
+	}
+	md.buf.WriteString("\n\n")
+}
+
+func (md *markdownWriter) newLine() {
+	out := md.buf.String()
+	if out != "" && !strings.HasSuffix(out, "\n") {
+		md.buf.WriteString("\n")
+	}
+}
+
+func (md *markdownWriter) wrap(n *html.Node, marker string) {
+	md.buf.WriteString(marker)
+	md.writeChildren(n)
+	md.buf.WriteString(marker)
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func (md *markdownWriter) writeNode(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if md.pre {
+			md.buf.WriteString(n.Data)
+			return
+		}
+		text := strings.Join(strings.Fields(n.Data), " ")
+		if text == "" {
+			return
+		}
+		out := md.buf.String()
+		if strings.TrimLeft(n.Data, " \t\n") != n.Data && out != "" && !strings.HasSuffix(out, " ") && !strings.HasSuffix(out, "\n") {
+			text = " " + text
+		}
+		if strings.TrimRight(n.Data, " \t\n") != n.Data {
+			text += " "
+		}
+		md.buf.WriteString(text)
+		return
+	case html.ElementNode:
+	default:
+		md.writeChildren(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.P, atom.Div:
+		if len(md.lists) > 0 {
+			// Paragraphs in list items would end the list
+			md.writeChildren(n)
+			return
+		}
+		md.block()
+		md.writeChildren(n)
+		md.block()
+	case atom.Br:
+		md.buf.WriteString("\n")
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level, _ := strconv.Atoi(n.Data[1:])
+		md.block()
+		md.buf.WriteString(strings.Repeat("#", level) + " ")
+		md.writeChildren(n)
+		md.block()
+	case atom.Strong, atom.B:
+		md.wrap(n, "**")
+	case atom.Em, atom.I:
+		md.wrap(n, "*")
+	case atom.S, atom.Del, atom.Strike:
+		md.wrap(n, "~~")
+	case atom.Code:
+		if md.pre {
+			md.writeChildren(n)
+			return
+		}
+		md.wrap(n, "`")
+	case atom.Pre:
+		md.block()
+		md.buf.WriteString("```\n")
+		md.pre = true
+		md.writeChildren(n)
+		md.pre = false
+		md.newLine()
+		md.buf.WriteString("```")
+		md.block()
+	case atom.A:
+		href := attr(n, "href")
+		if href == "" {
+			md.writeChildren(n)
+			return
+		}
+		md.buf.WriteString("[")
+		md.writeChildren(n)
+		md.buf.WriteString("](" + href + ")")
+	case atom.Ul, atom.Ol:
+		if len(md.lists) == 0 {
+			md.block()
+		} else {
+			md.newLine()
+		}
+		next := 0
+		if n.DataAtom == atom.Ol {
+			next = 1
+		}
+		md.lists = append(md.lists, next)
+		md.writeChildren(n)
+		md.lists = md.lists[:len(md.lists)-1]
+		if len(md.lists) == 0 {
+			md.block()
+		}
+	case atom.Li:
+		md.newLine()
+		depth := len(md.lists)
+		if depth == 0 {
+			md.buf.WriteString("- ")
+			md.writeChildren(n)
+			return
+		}
+		md.buf.WriteString(strings.Repeat("  ", depth-1))
+		if md.lists[depth-1] == 0 {
+			md.buf.WriteString("- ")
+		} else {
+			md.buf.WriteString(strconv.Itoa(md.lists[depth-1]) + ". ")
+			md.lists[depth-1]++
+		}
+		if attr(n, "data-type") == "taskItem" {
+			if attr(n, "data-checked") == "true" {
+				md.buf.WriteString("[x] ")
+			} else {
+				md.buf.WriteString("[ ] ")
+			}
+		}
+		md.writeChildren(n)
+	case atom.Blockquote:
+		md.block()
+		inner := &markdownWriter{}
+		inner.writeChildren(n)
+		for _, line := range strings.Split(strings.TrimSpace(inner.buf.String()), "\n") {
+			md.buf.WriteString("> " + line + "\n")
+		}
+		md.block()
+	case atom.Hr:
+		md.block()
+		md.buf.WriteString("---")
+		md.block()
+	case atom.Img:
+		md.buf.WriteString("![" + attr(n, "alt") + "](" + attr(n, "src") + ")")
+	case atom.Script, atom.Style:
+		// Never part of the description text
+	default:
+		md.writeChildren(n)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -34,7 +35,12 @@ import (
 )
 
 const apiScopes = `tasks.read tasks.read.shared`
-const apiPrefix = `https://graph.microsoft.com/v1.0/me/todo/`
+
+// The api urls are variables so that tests can point them to a local stub.
+var (
+	apiPrefix = `https://graph.microsoft.com/v1.0/me/todo/`
+	tokenURL  = `https://login.microsoftonline.com/common/oauth2/v2.0/token`
+)
 
 type Migration struct {
 	Code string `json:"code"`
@@ -46,6 +52,7 @@ type apiTokenResponse struct {
 	ExpiresIn    int    `json:"expires_in"`
 	ExtExpiresIn int    `json:"ext_expires_in"`
 	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
 }
 
 type task struct {
@@ -154,16 +161,23 @@ func (m *Migration) Name() string {
 }
 
 func getMicrosoftGraphAuthToken(code string) (accessToken string, err error) {
-
-	form := url.Values{
-		"client_id":     []string{config.MigrationMicrosoftTodoClientID.GetString()},
-		"client_secret": []string{config.MigrationMicrosoftTodoClientSecret.GetString()},
-		"scope":         []string{apiScopes},
-		"code":          []string{code},
-		"redirect_uri":  []string{config.MigrationMicrosoftTodoRedirectURL.GetString()},
-		"grant_type":    []string{"authorization_code"},
+	token, err := requestMicrosoftGraphToken(url.Values{
+		"scope":        []string{apiScopes},
+		"code":         []string{code},
+		"redirect_uri": []string{config.MigrationMicrosoftTodoRedirectURL.GetString()},
+		"grant_type":   []string{"authorization_code"},
+	})
+	if err != nil {
+		return "", err
 	}
-	resp, err := migration.DoPost("https://login.microsoftonline.com/common/oauth2/v2.0/token", form)
+	return token.AccessToken, nil
+}
+
+func requestMicrosoftGraphToken(form url.Values) (token *apiTokenResponse, err error) {
+	form.Set("client_id", config.MigrationMicrosoftTodoClientID.GetString())
+	form.Set("client_secret", config.MigrationMicrosoftTodoClientSecret.GetString())
+
+	resp, err := migration.DoPost(tokenURL, form)
 	if err != nil {
 		return
 	}
@@ -172,20 +186,37 @@ func getMicrosoftGraphAuthToken(code string) (accessToken string, err error) {
 	if resp.StatusCode > 399 {
 		buf := &bytes.Buffer{}
 		_, _ = buf.ReadFrom(resp.Body)
-		return "", fmt.Errorf("got http status %d while trying to get token, error was %s", resp.StatusCode, buf.String())
+		return nil, fmt.Errorf("got http status %d while trying to get token, error was %s", resp.StatusCode, buf.String())
 	}
 
-	token := &apiTokenResponse{}
+	token = &apiTokenResponse{}
 	err = json.NewDecoder(resp.Body).Decode(token)
-	return token.AccessToken, err
+	return token, err
 }
 
 func makeAuthenticatedGetRequest(token, urlPart string, v interface{}) error {
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, apiPrefix+urlPart, nil)
+	return makeAuthenticatedRequest(http.MethodGet, token, urlPart, nil, v)
+}
+
+func makeAuthenticatedRequest(method, token, urlPart string, payload interface{}, v interface{}) error {
+	var body io.Reader
+	if payload != nil {
+		buf := &bytes.Buffer{}
+		err := json.NewEncoder(buf).Encode(payload)
+		if err != nil {
+			return err
+		}
+		body = buf
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method, apiPrefix+urlPart, body)
 	if err != nil {
 		return err
 	}
 	req.Header.Set("Authorization", "Bearer "+token)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
 
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
@@ -206,7 +237,7 @@ func makeAuthenticatedGetRequest(token, urlPart string, v interface{}) error {
 	// If the response is an empty json array, we need to exit here, otherwise this breaks the json parser since it
 	// expects a null for an empty slice
 	str := buf.String()
-	if str == "[]" {
+	if str == "[]" || v == nil {
 		return nil
 	}
 
@@ -270,6 +301,7 @@ func convertMicrosoftTodoData(todoData []*project) (vikunjsStructure []*models.P
 				ID:    pseudoParentID,
 				Title: "Migrated from Microsoft Todo",
 			},
+			SourceID: migration.RootProjectSourceID,
 		},
 	}
 
@@ -286,6 +318,7 @@ func convertMicrosoftTodoData(todoData []*project) (vikunjsStructure []*models.P
 				ID:              int64(index+1) + pseudoParentID,
 				ParentProjectID: pseudoParentID,
 			},
+			SourceID: l.ID,
 		}
 
 		log.Debugf("[Microsoft Todo Migration] Converting %d tasks", len(l.Tasks))
@@ -364,7 +397,7 @@ func convertMicrosoftTodoData(todoData []*project) (vikunjsStructure []*models.P
 				}
 			}
 
-			project.Tasks = append(project.Tasks, &models.TaskWithComments{Task: *task})
+			project.Tasks = append(project.Tasks, &models.TaskWithComments{Task: *task, SourceID: t.ID})
 			log.Debugf("[Microsoft Todo Migration] Done converted %d tasks", len(l.Tasks))
 		}
 
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
 
 	"github.com/d4l3k/messagediff"
 	"github.com/stretchr/testify/assert"
@@ -90,13 +91,16 @@ func TestConverting(t *testing.T) {
 			},
 		},
 		{
+			ID:          "list2",
 			DisplayName: "Project 2",
 			Tasks: []*task{
 				{
+					ID:     "list2-task1",
 					Title:  "Task 1",
 					Status: "notStarted",
 				},
 				{
+					ID:     "list2-task2",
 					Title:  "Task 2",
 					Status: "notStarted",
 				},
@@ -110,6 +114,7 @@ func TestConverting(t *testing.T) {
 				Title: "Migrated from Microsoft Todo",
 				ID:    1,
 			},
+			SourceID: migration.RootProjectSourceID,
 		},
 		{
 			Project: models.Project{
@@ -174,16 +179,19 @@ func TestConverting(t *testing.T) {
 				ID:              3,
 				ParentProjectID: 1,
 			},
+			SourceID: "list2",
 			Tasks: []*models.TaskWithComments{
 				{
 					Task: models.Task{
 						Title: "Task 1",
 					},
+					SourceID: "list2-task1",
 				},
 				{
 					Task: models.Task{
 						Title: "Task 2",
 					},
+					SourceID: "list2-task2",
 				},
 			},
 		},
@@ -0,0 +1,225 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package microsofttodo
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/modules/migration"
+)
+
+// The sync needs to change tasks and keep access after the first access token expired
+const syncAPIScopes = `tasks.readwrite offline_access`
+
+type taskPayload struct {
+	Title       string            `json:"title"`
+	Body        *body             `json:"body"`
+	Importance  string            `json:"importance"`
+	Status      string            `json:"status"`
+	DueDateTime *dateTimeTimeZone `json:"dueDateTime"`
+}
+
+// SyncAuthURL returns the url users need to authorize a continuous sync at.
+// @Summary Get the auth url to sync with Microsoft Todo
+// @Description Returns the auth url where the user needs to get its auth code to keep their tasks in Microsoft Todo in sync with Vikunja.
+// @tags migration
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} handler.AuthURL "The auth url."
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/microsoft-todo/sync/auth [get]
+func (m *Migration) SyncAuthURL() string {
+	return "https://login.microsoftonline.com/common/oauth2/v2.0/authorize" +
+		"?client_id=" + config.MigrationMicrosoftTodoClientID.GetString() +
+		"&response_type=code" +
+		"&redirect_uri=" + config.MigrationMicrosoftTodoRedirectURL.GetString() +
+		"&response_mode=query" +
+		"&scope=" + syncAPIScopes
+}
+
+func credentialsFromToken(token *apiTokenResponse) *migration.SyncCredentials {
+	return &migration.SyncCredentials{
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		TokenExpiry:  time.Now().Add(time.Duration(token.ExpiresIn) * time.Second),
+	}
+}
+
+// Connect exchanges the auth code for an access and a refresh token.
+func (m *Migration) Connect(code string) (credentials *migration.SyncCredentials, err error) {
+	token, err := requestMicrosoftGraphToken(url.Values{
+		"scope":        []string{syncAPIScopes},
+		"code":         []string{code},
+		"redirect_uri": []string{config.MigrationMicrosoftTodoRedirectURL.GetString()},
+		"grant_type":   []string{"authorization_code"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return credentialsFromToken(token), nil
+}
+
+// refreshToken gets a new access token if the current one is about to expire.
+func refreshToken(credentials *migration.SyncCredentials) (err error) {
+	if time.Now().Add(time.Minute).Before(credentials.TokenExpiry) {
+		return nil
+	}
+
+	token, err := requestMicrosoftGraphToken(url.Values{
+		"scope":         []string{syncAPIScopes},
+		"refresh_token": []string{credentials.RefreshToken},
+		"grant_type":    []string{"refresh_token"},
+	})
+	if err != nil {
+		return err
+	}
+
+	refreshed := credentialsFromToken(token)
+	if refreshed.RefreshToken == "" {
+		refreshed.RefreshToken = credentials.RefreshToken
+	}
+	*credentials = *refreshed
+
+	log.Debugf("[Microsoft Todo Sync] Refreshed access token")
+
+	return nil
+}
+
+// Fetch returns all task lists and their tasks, including the completed ones.
+func (m *Migration) Fetch(credentials *migration.SyncCredentials) (projects []*migration.SyncProject, tasks []*migration.SyncTask, err error) {
+	err = refreshToken(credentials)
+	if err != nil {
+		return
+	}
+
+	todoData, err := getMicrosoftTodoData(credentials.AccessToken)
+	if err != nil {
+		return
+	}
+
+	for _, p := range todoData {
+		projects = append(projects, &migration.SyncProject{
+			SourceID: p.ID,
+			Title:    p.DisplayName,
+		})
+
+		for _, t := range p.Tasks {
+			st, err := convertTaskToSyncTask(t, p.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			tasks = append(tasks, st)
+		}
+	}
+
+	return
+}
+
+func convertTaskToSyncTask(t *task, projectID string) (st *migration.SyncTask, err error) {
+	st = &migration.SyncTask{
+		SourceID:        t.ID,
+		ProjectSourceID: projectID,
+		Title:           t.Title,
+		Done:            t.Status == "completed",
+		Updated:         t.LastModifiedDateTime,
+	}
+
+	if t.Body != nil && t.Body.ContentType == "text" {
+		st.Description = t.Body.Content
+	}
+
+	switch t.Importance {
+	case "low":
+		st.Priority = 1
+	case "normal":
+		st.Priority = 2
+	case "high":
+		st.Priority = 3
+	}
+
+	if t.DueDateTime != nil {
+		st.DueDate, err = t.DueDateTime.toTime()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return st, nil
+}
+
+func syncTaskToPayload(st *migration.SyncTask) *taskPayload {
+	payload := &taskPayload{
+		Title: st.Title,
+		Body: &body{
+			Content:     st.Description,
+			ContentType: "text",
+		},
+		Importance: "normal",
+		Status:     "notStarted",
+	}
+
+	switch {
+	case st.Priority >= 3:
+		payload.Importance = "high"
+	case st.Priority <= 1:
+		payload.Importance = "low"
+	}
+
+	if st.Done {
+		payload.Status = "completed"
+	}
+
+	if !st.DueDate.IsZero() {
+		payload.DueDateTime = &dateTimeTimeZone{
+			DateTime: st.DueDate.UTC().Format("2006-01-02T15:04:05.0000000"),
+			TimeZone: "UTC",
+		}
+	}
+
+	return payload
+}
+
+// CreateTask creates a new task in the task list of the task's project.
+func (m *Migration) CreateTask(credentials *migration.SyncCredentials, st *migration.SyncTask) (sourceID string, err error) {
+	err = refreshToken(credentials)
+	if err != nil {
+		return
+	}
+
+	created := &task{}
+	err = makeAuthenticatedRequest(http.MethodPost, credentials.AccessToken, "lists/"+st.ProjectSourceID+"/tasks", syncTaskToPayload(st), created)
+	if err != nil {
+		return "", err
+	}
+
+	return created.ID, nil
+}
+
+// UpdateTask saves all changes of a task to Microsoft Todo.
+func (m *Migration) UpdateTask(credentials *migration.SyncCredentials, st *migration.SyncTask) (err error) {
+	err = refreshToken(credentials)
+	if err != nil {
+		return
+	}
+
+	return makeAuthenticatedRequest(http.MethodPatch, credentials.AccessToken, "lists/"+st.ProjectSourceID+"/tasks/"+st.SourceID, syncTaskToPayload(st), nil)
+}
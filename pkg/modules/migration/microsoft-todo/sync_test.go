@@ -0,0 +1,160 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package microsofttodo
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/modules/migration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubRequest struct {
+	Method  string
+	Path    string
+	Payload map[string]interface{}
+}
+
+// newGraphStub starts a local server which answers like the Microsoft Graph api and records all changes sent to it
+func newGraphStub(t *testing.T, requests *[]*stubRequest) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+		assert.Equal(t, "refresh", r.Form.Get("refresh_token"))
+		_, _ = w.Write([]byte(`{"access_token": "refreshed", "expires_in": 3600}`))
+	})
+	mux.HandleFunc("/todo/", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer refreshed", r.Header.Get("Authorization"))
+
+		if r.Method != http.MethodGet {
+			req := &stubRequest{Method: r.Method, Path: r.URL.Path}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req.Payload))
+			*requests = append(*requests, req)
+			_, _ = w.Write([]byte(`{"id": "created-id"}`))
+			return
+		}
+
+		switch r.URL.Path {
+		case "/todo/lists":
+			_, _ = w.Write([]byte(`{"value": [{"id": "l1", "displayName": "Tasks"}]}`))
+		case "/todo/lists/l1/tasks":
+			_, _ = w.Write([]byte(`{"value": [
+				{"id": "t1", "title": "Task 1", "status": "completed", "importance": "high", "lastModifiedDateTime": "2024-06-30T10:00:00Z", "body": {"content": "Lorem", "contentType": "text"}, "dueDateTime": {"dateTime": "2024-07-01T00:00:00.0000000", "timeZone": "UTC"}},
+				{"id": "t2", "title": "Task 2", "status": "notStarted", "importance": "normal"}
+			]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	oldAPIPrefix, oldTokenURL := apiPrefix, tokenURL
+	apiPrefix = server.URL + "/todo/"
+	tokenURL = server.URL + "/token"
+	t.Cleanup(func() {
+		apiPrefix, tokenURL = oldAPIPrefix, oldTokenURL
+	})
+}
+
+func TestSyncer(t *testing.T) {
+	expiredCredentials := func() *migration.SyncCredentials {
+		return &migration.SyncCredentials{
+			AccessToken:  "expired",
+			RefreshToken: "refresh",
+			TokenExpiry:  time.Now().Add(-time.Hour),
+		}
+	}
+
+	t.Run("fetch", func(t *testing.T) {
+		newGraphStub(t, &[]*stubRequest{})
+		credentials := expiredCredentials()
+
+		projects, tasks, err := (&Migration{}).Fetch(credentials)
+		require.NoError(t, err)
+
+		assert.Equal(t, "refreshed", credentials.AccessToken)
+		assert.Equal(t, "refresh", credentials.RefreshToken)
+		assert.True(t, credentials.TokenExpiry.After(time.Now()))
+
+		require.Len(t, projects, 1)
+		assert.Equal(t, "l1", projects[0].SourceID)
+		assert.Equal(t, "Tasks", projects[0].Title)
+
+		require.Len(t, tasks, 2)
+		assert.Equal(t, "t1", tasks[0].SourceID)
+		assert.Equal(t, "l1", tasks[0].ProjectSourceID)
+		assert.True(t, tasks[0].Done)
+		assert.Equal(t, "Lorem", tasks[0].Description)
+		assert.Equal(t, int64(3), tasks[0].Priority)
+		assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), tasks[0].DueDate.UTC())
+		assert.Equal(t, time.Date(2024, 6, 30, 10, 0, 0, 0, time.UTC), tasks[0].Updated)
+		assert.False(t, tasks[1].Done)
+		assert.Equal(t, int64(2), tasks[1].Priority)
+	})
+	t.Run("create task", func(t *testing.T) {
+		requests := []*stubRequest{}
+		newGraphStub(t, &requests)
+
+		id, err := (&Migration{}).CreateTask(expiredCredentials(), &migration.SyncTask{
+			ProjectSourceID: "l1",
+			Title:           "New task",
+			Priority:        4,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "created-id", id)
+
+		require.Len(t, requests, 1)
+		assert.Equal(t, http.MethodPost, requests[0].Method)
+		assert.Equal(t, "/todo/lists/l1/tasks", requests[0].Path)
+		assert.Equal(t, "New task", requests[0].Payload["title"])
+		assert.Equal(t, "high", requests[0].Payload["importance"])
+		assert.Equal(t, "notStarted", requests[0].Payload["status"])
+		assert.Nil(t, requests[0].Payload["dueDateTime"])
+	})
+	t.Run("update task", func(t *testing.T) {
+		requests := []*stubRequest{}
+		newGraphStub(t, &requests)
+
+		err := (&Migration{}).UpdateTask(expiredCredentials(), &migration.SyncTask{
+			SourceID:        "t1",
+			ProjectSourceID: "l1",
+			Title:           "Changed",
+			Done:            true,
+			DueDate:         time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC),
+		})
+		require.NoError(t, err)
+
+		require.Len(t, requests, 1)
+		assert.Equal(t, http.MethodPatch, requests[0].Method)
+		assert.Equal(t, "/todo/lists/l1/tasks/t1", requests[0].Path)
+		assert.Equal(t, "Changed", requests[0].Payload["title"])
+		assert.Equal(t, "completed", requests[0].Payload["status"])
+		assert.Equal(t, map[string]interface{}{
+			"dateTime": "2024-07-01T12:00:00.0000000",
+			"timeZone": "UTC",
+		}, requests[0].Payload["dueDateTime"])
+	})
+}
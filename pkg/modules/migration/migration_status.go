@@ -17,9 +17,11 @@
 package migration
 
 import (
+	"sync"
 	"time"
 
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/user"
 )
 
@@ -30,6 +32,25 @@ type Status struct {
 	MigratorName string    `xorm:"varchar(255)" json:"migrator_name"`
 	StartedAt    time.Time `xorm:"not null" json:"started_at"`
 	FinishedAt   time.Time `xorm:"null" json:"finished_at"`
+
+	// The number of projects which will be created during this migration.
+	ProjectsTotal int64 `xorm:"bigint not null default 0" json:"projects_total"`
+	// The number of projects which were already created.
+	ProjectsDone int64 `xorm:"bigint not null default 0" json:"projects_done"`
+	// The number of tasks which will be created during this migration.
+	TasksTotal int64 `xorm:"bigint not null default 0" json:"tasks_total"`
+	// The number of tasks which were already created.
+	TasksDone int64 `xorm:"bigint not null default 0" json:"tasks_done"`
+	// The number of attachments which will be created during this migration.
+	AttachmentsTotal int64 `xorm:"bigint not null default 0" json:"attachments_total"`
+	// The number of attachments which were already created.
+	AttachmentsDone int64 `xorm:"bigint not null default 0" json:"attachments_done"`
+	// The number of items which could not be migrated. Check the migration report for details.
+	FailedItems int64 `xorm:"bigint not null default 0" json:"failed_items"`
+	// The number of items which were skipped, for example because they were already migrated in a previous run.
+	SkippedItems int64 `xorm:"bigint not null default 0" json:"skipped_items"`
+	// If the migration failed, this contains the error message.
+	Error string `xorm:"text null" json:"error"`
 }
 
 // TableName holds the table name for the migration status table
@@ -37,7 +58,20 @@ func (s *Status) TableName() string {
 	return "migration_status"
 }
 
-// StartMigration sets the migration status for a user
+// runningMigrations holds the status of all migrations currently running in this instance, keyed by user id.
+// The structure creation uses it to report its progress without every migrator having to pass the status along.
+// Because of that, only one migration can run at a time for a user.
+var runningMigrations sync.Map
+
+func getRunningMigration(u *user.User) *Status {
+	status, has := runningMigrations.Load(u.ID)
+	if !has {
+		return nil
+	}
+	return status.(*Status)
+}
+
+// StartMigration sets the migration status for a user. It fails if another migration is still running for the user.
 func StartMigration(m MigratorName, u *user.User) (status *Status, err error) {
 	s := db.NewSession()
 	defer s.Close()
@@ -47,7 +81,17 @@ func StartMigration(m MigratorName, u *user.User) (status *Status, err error) {
 		MigratorName: m.Name(),
 		StartedAt:    time.Now(),
 	}
+
+	if _, running := runningMigrations.LoadOrStore(u.ID, status); running {
+		return nil, &models.ErrMigrationAlreadyRunning{UserID: u.ID}
+	}
+
 	_, err = s.Insert(status)
+	if err != nil {
+		runningMigrations.Delete(u.ID)
+		return nil, err
+	}
+
 	return
 }
 
@@ -56,12 +100,21 @@ func FinishMigration(status *Status) (err error) {
 	s := db.NewSession()
 	defer s.Close()
 
+	runningMigrations.Delete(status.UserID)
+
 	status.FinishedAt = time.Now()
 
 	_, err = s.Where("id = ?", status.ID).Update(status)
 	return
 }
 
+// FailMigration finishes a migration and saves the error it failed with. Everything which was migrated
+// successfully up to that point is kept and will be skipped when the migration is started again.
+func FailMigration(status *Status, migrationErr error) (err error) {
+	status.Error = migrationErr.Error()
+	return FinishMigration(status)
+}
+
 // GetMigrationStatus returns the migration status for a migration and a user
 func GetMigrationStatus(m MigratorName, u *user.User) (status *Status, err error) {
 	s := db.NewSession()
@@ -47,3 +47,18 @@ type FileMigrator interface {
 	// The user object is the user who's tasks will be migrated.
 	Migrate(user *user.User, file io.ReaderAt, size int64) error
 }
+
+// FileMigratorWithOptions is a FileMigrator which needs additional options from the user to import a file,
+// for example which column of the file holds which value.
+type FileMigratorWithOptions interface {
+	FileMigrator
+	// SetOptions receives the raw options the user sent along with the file. It is called before Migrate or Preview.
+	SetOptions(options string) error
+}
+
+// PreviewFileMigrator is a FileMigrator which can show what it would import without actually importing anything.
+type PreviewFileMigrator interface {
+	FileMigrator
+	// Preview parses the file and returns everything which would be imported, including validation errors.
+	Preview(user *user.User, file io.ReaderAt, size int64) (preview interface{}, err error)
+}
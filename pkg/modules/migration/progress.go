@@ -0,0 +1,486 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/xorm"
+)
+
+// The kinds of items a migration keeps track of
+const (
+	ItemKindProject       = "project"
+	ItemKindTask          = "task"
+	ItemKindAttachment    = "attachment"
+	ItemKindLabel         = "label"
+	ItemKindComment       = "comment"
+	ItemKindRelation      = "relation"
+	ItemKindChecklistItem = "checklist_item"
+	ItemKindUser          = "user"
+	ItemKindShare         = "share"
+)
+
+// The states a migrated item can be in
+const (
+	ItemStateStarted = "started"
+	ItemStateDone    = "done"
+	ItemStateFailed  = "failed"
+	ItemStateSkipped = "skipped"
+)
+
+// RootProjectSourceID is the source id of the project migrators put everything they migrate into. It does not
+// exist in the service they migrate from, but using the same id every time allows to resume into it.
+const RootProjectSourceID = "root"
+
+// progressSaveInterval is the minimum time between two progress updates saved to the database.
+const progressSaveInterval = time.Second
+
+// Item is a single thing which was migrated or could not be migrated during a migration.
+type Item struct {
+	ID           int64  `xorm:"bigint autoincr not null unique pk" json:"-"`
+	StatusID     int64  `xorm:"bigint not null INDEX" json:"-"`
+	UserID       int64  `xorm:"bigint not null INDEX" json:"-"`
+	MigratorName string `xorm:"varchar(255) not null" json:"-"`
+
+	// What kind of item this is, for example project, task or attachment.
+	Kind string `xorm:"varchar(50) not null" json:"kind"`
+	// The id of the item in the service it was migrated from.
+	SourceID string `xorm:"varchar(250) null" json:"source_id"`
+	// The id of the item in Vikunja, if it was created.
+	TargetID int64 `xorm:"bigint null" json:"target_id"`
+	// One of started, done, failed or skipped.
+	State string `xorm:"varchar(20) not null" json:"state"`
+	// The title or name of the item, if it has one.
+	Title string `xorm:"text null" json:"title"`
+	// Why the item failed or was skipped.
+	Message string `xorm:"text null" json:"message"`
+
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName holds the table name for the migration items table
+func (i *Item) TableName() string {
+	return "migration_items"
+}
+
+// progress keeps track of what was already migrated and saves it along with the migration status.
+// If no migration is running for the user, nothing is saved.
+type progress struct {
+	s      *xorm.Session
+	status *Status
+
+	// Projects and tasks migrated in previous runs which did not finish, keyed by their source id.
+	doneProjects    map[string]*Item
+	startedProjects map[string]*Item
+	doneTasks       map[string]int64
+
+	lastSaved time.Time
+}
+
+// sourceID formats the id an item has in the migrated structure for the migration report. Items without an id
+// are reported without one.
+func sourceID(oldID int64) string {
+	if oldID == 0 {
+		return ""
+	}
+	return strconv.FormatInt(oldID, 10)
+}
+
+func newProgress(s *xorm.Session, str []*models.ProjectWithTasksAndBuckets, u *user.User) (p *progress, err error) {
+	p = &progress{
+		s:               s,
+		status:          getRunningMigration(u),
+		doneProjects:    make(map[string]*Item),
+		startedProjects: make(map[string]*Item),
+		doneTasks:       make(map[string]int64),
+	}
+	if p.status == nil {
+		return p, nil
+	}
+
+	p.status.ProjectsTotal = 0
+	p.status.TasksTotal = 0
+	p.status.AttachmentsTotal = 0
+	for _, project := range str {
+		if project.ID == models.FavoritesPseudoProjectID {
+			continue
+		}
+		p.status.ProjectsTotal++
+		p.status.TasksTotal += int64(len(project.Tasks))
+		for _, t := range project.Tasks {
+			for _, a := range t.Attachments {
+				if a.File != nil && len(a.File.FileContent) > 0 {
+					p.status.AttachmentsTotal++
+				}
+			}
+		}
+	}
+
+	err = p.loadPreviousRuns()
+	if err != nil {
+		return nil, err
+	}
+
+	return p, p.save(true)
+}
+
+// loadPreviousRuns loads all projects and tasks migrated by runs of the same migrator since the last one
+// which finished successfully. These are the ones a new run needs to resume from.
+func (p *progress) loadPreviousRuns() (err error) {
+	previous := []*Status{}
+	err = p.s.
+		Where("user_id = ? AND migrator_name = ? AND id < ?", p.status.UserID, p.status.MigratorName, p.status.ID).
+		Desc("id").
+		Find(&previous)
+	if err != nil {
+		return
+	}
+
+	statusIDs := []int64{}
+	for _, st := range previous {
+		if !st.FinishedAt.IsZero() && st.Error == "" {
+			break
+		}
+		statusIDs = append(statusIDs, st.ID)
+	}
+	if len(statusIDs) == 0 {
+		return nil
+	}
+
+	items := []*Item{}
+	err = p.s.
+		In("status_id", statusIDs).
+		In("kind", ItemKindProject, ItemKindTask).
+		In("state", ItemStateStarted, ItemStateDone).
+		OrderBy("id asc").
+		Find(&items)
+	if err != nil {
+		return
+	}
+
+	for _, item := range items {
+		switch {
+		case item.Kind == ItemKindTask:
+			p.doneTasks[item.SourceID] = item.TargetID
+		case item.State == ItemStateDone:
+			p.doneProjects[item.SourceID] = item
+			delete(p.startedProjects, item.SourceID)
+		default:
+			if _, done := p.doneProjects[item.SourceID]; !done {
+				p.startedProjects[item.SourceID] = item
+			}
+		}
+	}
+
+	log.Debugf("[creating structure] Resuming migration %d, %d projects were already migrated, %d were only partially migrated", p.status.ID, len(p.doneProjects), len(p.startedProjects))
+
+	return nil
+}
+
+func (p *progress) save(force bool) (err error) {
+	if p.status == nil {
+		return nil
+	}
+	if !force && time.Since(p.lastSaved) < progressSaveInterval {
+		return nil
+	}
+
+	_, err = p.s.
+		Where("id = ?", p.status.ID).
+		Cols(
+			"projects_total",
+			"projects_done",
+			"tasks_total",
+			"tasks_done",
+			"attachments_total",
+			"attachments_done",
+			"failed_items",
+			"skipped_items",
+		).
+		Update(p.status)
+	if err != nil {
+		return err
+	}
+
+	p.lastSaved = time.Now()
+	return nil
+}
+
+func (p *progress) record(item *Item) (err error) {
+	if p.status == nil {
+		return nil
+	}
+
+	item.StatusID = p.status.ID
+	item.UserID = p.status.UserID
+	item.MigratorName = p.status.MigratorName
+	_, err = p.s.Insert(item)
+	return
+}
+
+// migratedProject returns the project created from the given project in a previous run, if that run created it
+// completely and it still exists.
+func (p *progress) migratedProject(project *models.ProjectWithTasksAndBuckets) (existing *models.Project, err error) {
+	if project.SourceID == "" {
+		return nil, nil
+	}
+
+	item, has := p.doneProjects[project.SourceID]
+	if !has {
+		return nil, nil
+	}
+
+	existing, err = models.GetProjectSimpleByID(p.s, item.TargetID)
+	if models.IsErrProjectDoesNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p.status.ProjectsDone++
+	p.status.TasksDone += int64(len(project.Tasks))
+	p.status.SkippedItems++
+	err = p.record(&Item{
+		Kind:     ItemKindProject,
+		SourceID: item.SourceID,
+		TargetID: existing.ID,
+		State:    ItemStateSkipped,
+		Title:    project.Title,
+		Message:  "Already migrated in a previous run",
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return existing, p.save(false)
+}
+
+// migratedTaskID returns the id of the task created from the task with the given source id in a previous run.
+func (p *progress) migratedTaskID(sourceID string) (id int64, has bool) {
+	if sourceID == "" {
+		return 0, false
+	}
+	id, has = p.doneTasks[sourceID]
+	return
+}
+
+// removePartialProject deletes whatever a previous run created for the project before it failed,
+// so that the project can be created again from scratch.
+func (p *progress) removePartialProject(project *models.ProjectWithTasksAndBuckets, u *user.User) (err error) {
+	if project.SourceID == "" {
+		return nil
+	}
+
+	item, has := p.startedProjects[project.SourceID]
+	if !has {
+		return nil
+	}
+
+	partial, err := models.GetProjectSimpleByID(p.s, item.TargetID)
+	if models.IsErrProjectDoesNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("[creating structure] Removing partially migrated project %d before creating it again", partial.ID)
+
+	return partial.Delete(p.s, u)
+}
+
+func (p *progress) projectStarted(project *models.ProjectWithTasksAndBuckets) (item *Item, err error) {
+	item = &Item{
+		Kind:     ItemKindProject,
+		SourceID: project.SourceID,
+		TargetID: project.ID,
+		State:    ItemStateStarted,
+		Title:    project.Title,
+	}
+	return item, p.record(item)
+}
+
+// projectDone marks a project and all its tasks as completely migrated. Only completely migrated projects
+// are skipped when a migration is resumed. The tasks are the ids of the created tasks, keyed by their source id.
+func (p *progress) projectDone(item *Item, tasks map[string]int64) (err error) {
+	if p.status == nil {
+		return nil
+	}
+
+	p.status.ProjectsDone++
+
+	if item.SourceID == "" {
+		return p.save(false)
+	}
+
+	item.State = ItemStateDone
+	_, err = p.s.Where("id = ?", item.ID).Cols("state").Update(item)
+	if err != nil {
+		return err
+	}
+
+	taskItems := make([]*Item, 0, len(tasks))
+	for taskSourceID, newID := range tasks {
+		if taskSourceID == "" {
+			continue
+		}
+		taskItems = append(taskItems, &Item{
+			StatusID:     p.status.ID,
+			UserID:       p.status.UserID,
+			MigratorName: p.status.MigratorName,
+			Kind:         ItemKindTask,
+			SourceID:     taskSourceID,
+			TargetID:     newID,
+			State:        ItemStateDone,
+		})
+	}
+	if len(taskItems) > 0 {
+		_, err = p.s.Insert(taskItems)
+		if err != nil {
+			return err
+		}
+	}
+
+	return p.save(false)
+}
+
+func (p *progress) taskDone() error {
+	if p.status == nil {
+		return nil
+	}
+	p.status.TasksDone++
+	return p.save(false)
+}
+
+func (p *progress) attachmentDone() error {
+	if p.status == nil {
+		return nil
+	}
+	p.status.AttachmentsDone++
+	return p.save(false)
+}
+
+// isItemError checks if an error was caused by the migrated item itself, for example because it is invalid
+// or too large. These errors only affect the one item, the rest of the migration can continue.
+func isItemError(err error) bool {
+	if files.IsErrFileIsTooLarge(err) {
+		return true
+	}
+	_, is := err.(web.HTTPErrorProcessor)
+	return is
+}
+
+// itemFailed records an item which could not be migrated. If the error is not caused by the item itself, it is
+// returned and the migration should be aborted.
+func (p *progress) itemFailed(kind, sourceID, title string, itemErr error) (err error) {
+	if !isItemError(itemErr) {
+		return itemErr
+	}
+
+	log.Debugf("[creating structure] Could not migrate %s %s (%s): %s", kind, sourceID, title, itemErr.Error())
+
+	if p.status == nil {
+		return nil
+	}
+
+	p.status.FailedItems++
+	err = p.record(&Item{
+		Kind:     kind,
+		SourceID: sourceID,
+		State:    ItemStateFailed,
+		Title:    title,
+		Message:  itemErr.Error(),
+	})
+	if err != nil {
+		return err
+	}
+	return p.save(false)
+}
+
+// itemSkipped records an item which was deliberately not migrated.
+func (p *progress) itemSkipped(kind, sourceID, title, reason string) (err error) {
+	if p.status == nil {
+		return nil
+	}
+
+	p.status.SkippedItems++
+	err = p.record(&Item{
+		Kind:     kind,
+		SourceID: sourceID,
+		State:    ItemStateSkipped,
+		Title:    title,
+		Message:  reason,
+	})
+	if err != nil {
+		return err
+	}
+	return p.save(false)
+}
+
+// SkipItem records an item which a migrator deliberately did not migrate before creating the migrated structure,
+// for example because it references a user which does not exist in Vikunja. It shows up in the migration report
+// of the migration currently running for the user.
+func SkipItem(u *user.User, kind, sourceID, title, reason string) (err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	p := &progress{
+		s:      s,
+		status: getRunningMigration(u),
+	}
+	err = p.itemSkipped(kind, sourceID, title, reason)
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	return s.Commit()
+}
+
+// GetMigrationReport returns all items which failed or were skipped during the last migration of a user
+// with the given migrator.
+func GetMigrationReport(m MigratorName, u *user.User) (items []*Item, err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	status := &Status{}
+	has, err := s.
+		Where("user_id = ? and migrator_name = ?", u.ID, m.Name()).
+		Desc("id").
+		Get(status)
+	if err != nil || !has {
+		return []*Item{}, err
+	}
+
+	items = []*Item{}
+	err = s.
+		Where("status_id = ?", status.ID).
+		In("state", ItemStateFailed, ItemStateSkipped).
+		OrderBy("id asc").
+		Find(&items)
+	return
+}
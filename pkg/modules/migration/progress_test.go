@@ -0,0 +1,226 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"errors"
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/builder"
+)
+
+type testMigrator struct {
+	name string
+}
+
+func (m *testMigrator) Name() string {
+	return m.name
+}
+
+func TestMigrationProgress(t *testing.T) {
+	u := &user.User{
+		ID: 1,
+	}
+
+	t.Run("failed items", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		m := &testMigrator{name: "progress-failed"}
+		structure := []*models.ProjectWithTasksAndBuckets{
+			{
+				Project: models.Project{
+					ID:    1,
+					Title: "Project with an invalid checklist item",
+				},
+				Tasks: []*models.TaskWithComments{
+					{
+						Task: models.Task{
+							ID:    10,
+							Title: "Task with checklist",
+							ChecklistItems: []*models.TaskChecklistItem{
+								{ID: 1, Title: ""},
+								{ID: 2, Title: "Valid checklist item"},
+							},
+						},
+					},
+					{
+						Task: models.Task{
+							ID: 11,
+						},
+						SourceID: "task-11",
+					},
+				},
+			},
+		}
+
+		status, err := StartMigration(m, u)
+		require.NoError(t, err)
+		err = InsertFromStructure(structure, u)
+		require.NoError(t, err)
+		err = FinishMigration(status)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(1), status.ProjectsDone)
+		assert.Equal(t, int64(1), status.TasksDone)
+		assert.Equal(t, int64(2), status.TasksTotal)
+		assert.Equal(t, int64(1), status.FailedItems)
+		assert.Equal(t, int64(1), status.SkippedItems)
+		db.AssertExists(t, "task_checklist_items", map[string]interface{}{
+			"title": "Valid checklist item",
+		}, false)
+
+		saved, err := GetMigrationStatus(m, u)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), saved.FailedItems)
+
+		report, err := GetMigrationReport(m, u)
+		require.NoError(t, err)
+		require.Len(t, report, 2)
+		assert.Equal(t, ItemKindChecklistItem, report[0].Kind)
+		assert.Equal(t, ItemStateFailed, report[0].State)
+		assert.Equal(t, "1", report[0].SourceID)
+		assert.Equal(t, ItemKindTask, report[1].Kind)
+		assert.Equal(t, ItemStateSkipped, report[1].State)
+		assert.Equal(t, "task-11", report[1].SourceID)
+	})
+	t.Run("resume after failure", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		m := &testMigrator{name: "progress-resume"}
+		// The ids in the structure are made up by the migrators and may differ between runs,
+		// only the source ids stay the same.
+		getStructure := func(offset int64) []*models.ProjectWithTasksAndBuckets {
+			return []*models.ProjectWithTasksAndBuckets{
+				{
+					Project: models.Project{
+						ID:    offset + 1,
+						Title: "Resumed project 1",
+					},
+					SourceID: "project-1",
+					Tasks: []*models.TaskWithComments{
+						{
+							Task: models.Task{
+								ID:    offset + 10,
+								Title: "Resumed task 1",
+							},
+							SourceID: "task-1",
+						},
+					},
+				},
+				{
+					Project: models.Project{
+						ID:    offset + 2,
+						Title: "Resumed project 2",
+					},
+					SourceID: "project-2",
+					Tasks: []*models.TaskWithComments{
+						{
+							Task: models.Task{
+								ID:    offset + 20,
+								Title: "Resumed task 2",
+								RelatedTasks: map[models.RelationKind][]*models.Task{
+									models.RelationKindBlocking: {
+										{ID: offset + 10},
+									},
+								},
+							},
+							SourceID: "task-2",
+						},
+					},
+				},
+			}
+		}
+
+		first, err := StartMigration(m, u)
+		require.NoError(t, err)
+		err = InsertFromStructure(getStructure(0), u)
+		require.NoError(t, err)
+
+		// Pretend the first run failed while creating the second project
+		s := db.NewSession()
+		_, err = s.
+			Where("status_id = ? AND kind = ? AND source_id = ?", first.ID, ItemKindProject, "project-2").
+			Cols("state").
+			Update(&Item{State: ItemStateStarted})
+		s.Close()
+		require.NoError(t, err)
+		err = FailMigration(first, errors.New("something went wrong"))
+		require.NoError(t, err)
+
+		second, err := StartMigration(m, u)
+		require.NoError(t, err)
+		err = InsertFromStructure(getStructure(100), u)
+		require.NoError(t, err)
+		err = FinishMigration(second)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(2), second.ProjectsDone)
+		assert.Equal(t, int64(1), second.SkippedItems)
+		db.AssertCount(t, "projects", builder.Eq{"title": "Resumed project 1"}, 1)
+		db.AssertCount(t, "projects", builder.Eq{"title": "Resumed project 2"}, 1)
+		db.AssertCount(t, "tasks", builder.Eq{"title": "Resumed task 1"}, 1)
+		db.AssertCount(t, "tasks", builder.Eq{"title": "Resumed task 2"}, 1)
+
+		s = db.NewSession()
+		defer s.Close()
+		task1 := &models.Task{}
+		_, err = s.Where("title = ?", "Resumed task 1").Get(task1)
+		require.NoError(t, err)
+		task2 := &models.Task{}
+		_, err = s.Where("title = ?", "Resumed task 2").Get(task2)
+		require.NoError(t, err)
+		db.AssertExists(t, "task_relations", map[string]interface{}{
+			"task_id":       task2.ID,
+			"other_task_id": task1.ID,
+			"relation_kind": models.RelationKindBlocking,
+		}, false)
+
+		// A migration which is started again after it succeeded creates everything again
+		third, err := StartMigration(m, u)
+		require.NoError(t, err)
+		err = InsertFromStructure(getStructure(0), u)
+		require.NoError(t, err)
+		err = FinishMigration(third)
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), third.SkippedItems)
+		db.AssertCount(t, "projects", builder.Eq{"title": "Resumed project 1"}, 2)
+	})
+}
+
+func TestStartMigration(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	u := &user.User{ID: 1}
+
+	first, err := StartMigration(&testMigrator{name: "first"}, u)
+	require.NoError(t, err)
+
+	_, err = StartMigration(&testMigrator{name: "second"}, u)
+	require.Error(t, err)
+	assert.True(t, models.IsErrMigrationAlreadyRunning(err))
+
+	err = FinishMigration(first)
+	require.NoError(t, err)
+
+	second, err := StartMigration(&testMigrator{name: "second"}, u)
+	require.NoError(t, err)
+	err = FinishMigration(second)
+	require.NoError(t, err)
+}
@@ -0,0 +1,695 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+
+	"xorm.io/xorm"
+)
+
+// Syncer is implemented by migrators which can keep projects and tasks continuously in sync with the
+// service they migrate from.
+type Syncer interface {
+	MigratorName
+	// SyncAuthURL returns the url where users authorize Vikunja to read and change their data.
+	SyncAuthURL() string
+	// Connect exchanges the code obtained from the auth url for credentials which are saved with the sync.
+	Connect(code string) (credentials *SyncCredentials, err error)
+	// Fetch returns all projects and tasks from the service. Tasks which are done may be left out,
+	// a task which is not returned anymore will be marked as done in Vikunja.
+	// Fetch may refresh the credentials, they are saved after every sync.
+	Fetch(credentials *SyncCredentials) (projects []*SyncProject, tasks []*SyncTask, err error)
+	// CreateTask creates a new task in the service and returns its id.
+	CreateTask(credentials *SyncCredentials, task *SyncTask) (sourceID string, err error)
+	// UpdateTask saves all changes of a task to the service.
+	UpdateTask(credentials *SyncCredentials, task *SyncTask) (err error)
+}
+
+// SyncCredentials hold everything needed to access the api of a synced service on behalf of a user.
+type SyncCredentials struct {
+	AccessToken  string    `xorm:"text null" json:"-"`
+	RefreshToken string    `xorm:"text null" json:"-"`
+	TokenExpiry  time.Time `xorm:"null" json:"-"`
+}
+
+// SyncProject is a project in a synced service.
+type SyncProject struct {
+	SourceID string
+	Title    string
+}
+
+// SyncTask holds all properties of a task which are kept in sync.
+type SyncTask struct {
+	SourceID        string
+	ProjectSourceID string
+	Title           string
+	Description     string
+	Done            bool
+	DueDate         time.Time
+	Priority        int64
+	// When the task was last modified in the service. Zero if the service does not provide it.
+	Updated time.Time
+}
+
+// hash returns a checksum of all synced properties. It is used to find out which side changed a task since the last sync.
+func (t *SyncTask) hash() string {
+	h := sha256.New()
+	for _, part := range []string{
+		t.Title,
+		t.Description,
+		strconv.FormatBool(t.Done),
+		strconv.FormatInt(t.DueDate.Unix(), 10),
+		strconv.FormatInt(t.Priority, 10),
+	} {
+		_, _ = h.Write([]byte(part))
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func syncTaskFromTask(task *models.Task, sourceID, projectSourceID string) *SyncTask {
+	st := &SyncTask{
+		SourceID:        sourceID,
+		ProjectSourceID: projectSourceID,
+		Title:           task.Title,
+		Description:     task.Description,
+		Done:            task.Done,
+		Priority:        task.Priority,
+		Updated:         task.Updated,
+	}
+	if !task.DueDate.IsZero() {
+		st.DueDate = task.DueDate
+	}
+	return st
+}
+
+func (t *SyncTask) applyTo(task *models.Task) {
+	task.Title = t.Title
+	task.Description = t.Description
+	task.Done = t.Done
+	task.DueDate = t.DueDate
+	task.Priority = t.Priority
+}
+
+// Sync is a continuous two-way sync between a user and a migrator.
+type Sync struct {
+	ID           int64  `xorm:"bigint autoincr not null unique pk" json:"id"`
+	UserID       int64  `xorm:"bigint not null INDEX" json:"-"`
+	MigratorName string `xorm:"varchar(255) not null" json:"migrator_name"`
+
+	SyncCredentials `xorm:"extends"`
+
+	// When the last sync ran.
+	LastSyncAt time.Time `xorm:"null" json:"last_sync_at"`
+	// If the last sync failed, this contains the error message.
+	LastError string `xorm:"text null" json:"last_error"`
+
+	Created time.Time `xorm:"created not null" json:"created"`
+	Updated time.Time `xorm:"updated not null" json:"updated"`
+}
+
+// TableName holds the table name for the migration syncs table
+func (sc *Sync) TableName() string {
+	return "migration_syncs"
+}
+
+// The kinds of things a sync keeps a mapping for
+const (
+	SyncMappingKindProject = "project"
+	SyncMappingKindTask    = "task"
+)
+
+// SyncMapping connects a project or task in a synced service with its counterpart in Vikunja.
+type SyncMapping struct {
+	ID       int64  `xorm:"bigint autoincr not null unique pk" json:"-"`
+	SyncID   int64  `xorm:"bigint not null INDEX" json:"-"`
+	Kind     string `xorm:"varchar(50) not null" json:"-"`
+	SourceID string `xorm:"varchar(250) not null" json:"-"`
+	TargetID int64  `xorm:"bigint not null" json:"-"`
+	// The checksum of the task as it was after the last sync.
+	Hash string `xorm:"varchar(64) null" json:"-"`
+
+	Created time.Time `xorm:"created not null" json:"-"`
+	Updated time.Time `xorm:"updated not null" json:"-"`
+}
+
+// TableName holds the table name for the migration sync mappings table
+func (m *SyncMapping) TableName() string {
+	return "migration_sync_mappings"
+}
+
+var registeredSyncers = make(map[string]Syncer)
+
+// RegisterSyncer makes a syncer available to the sync cron.
+func RegisterSyncer(syncer Syncer) {
+	registeredSyncers[syncer.Name()] = syncer
+}
+
+// runningSyncs holds the ids of all syncs currently running, to prevent a slow sync from running twice at the same time.
+var runningSyncs sync.Map
+
+// GetSync returns the sync of a user with a migrator.
+func GetSync(m MigratorName, u *user.User) (sc *Sync, err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	return getSync(s, m, u)
+}
+
+func getSync(s *xorm.Session, m MigratorName, u *user.User) (sc *Sync, err error) {
+	sc = &Sync{}
+	has, err := s.
+		Where("user_id = ? AND migrator_name = ?", u.ID, m.Name()).
+		Get(sc)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, &models.ErrMigrationSyncDoesNotExist{MigratorName: m.Name(), UserID: u.ID}
+	}
+	return sc, nil
+}
+
+// EnableSync sets up a sync between a user and a migrator with the code obtained from the sync auth url.
+// If the user already has one, its credentials are replaced.
+func EnableSync(syncer Syncer, u *user.User, code string) (sc *Sync, err error) {
+	credentials, err := syncer.Connect(code)
+	if err != nil {
+		return nil, err
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	sc, err = getSync(s, syncer, u)
+	if err != nil && !models.IsErrMigrationSyncDoesNotExist(err) {
+		_ = s.Rollback()
+		return nil, err
+	}
+	if err != nil {
+		sc = &Sync{
+			UserID:          u.ID,
+			MigratorName:    syncer.Name(),
+			SyncCredentials: *credentials,
+		}
+		_, err = s.Insert(sc)
+	} else {
+		sc.SyncCredentials = *credentials
+		sc.LastError = ""
+		_, err = s.
+			Where("id = ?", sc.ID).
+			Cols("access_token", "refresh_token", "token_expiry", "last_error").
+			Update(sc)
+	}
+	if err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	return sc, s.Commit()
+}
+
+// DisableSync stops syncing a user with a migrator. Everything synced so far stays where it is.
+func DisableSync(m MigratorName, u *user.User) (err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	sc, err := getSync(s, m, u)
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	_, err = s.Where("sync_id = ?", sc.ID).Delete(&SyncMapping{})
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	_, err = s.Where("id = ?", sc.ID).Delete(&Sync{})
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	return s.Commit()
+}
+
+// RunSync syncs everything between Vikunja and the service of the syncer once.
+func RunSync(sc *Sync, syncer Syncer) (err error) {
+	if _, running := runningSyncs.LoadOrStore(sc.ID, true); running {
+		log.Debugf("[Migration Sync] Sync %d is still running, skipping", sc.ID)
+		return nil
+	}
+	defer runningSyncs.Delete(sc.ID)
+
+	s := db.NewSession()
+	defer s.Close()
+
+	syncErr := runSync(s, sc, syncer)
+	if syncErr != nil {
+		log.Errorf("[Migration Sync] Sync %d from %s for user %d failed: %s", sc.ID, sc.MigratorName, sc.UserID, syncErr)
+		sc.LastError = syncErr.Error()
+	} else {
+		sc.LastError = ""
+	}
+	sc.LastSyncAt = time.Now()
+
+	_, err = s.
+		Where("id = ?", sc.ID).
+		Cols("access_token", "refresh_token", "token_expiry", "last_sync_at", "last_error").
+		Update(sc)
+	if err != nil {
+		_ = s.Rollback()
+		return err
+	}
+
+	err = s.Commit()
+	if err != nil {
+		return err
+	}
+
+	return syncErr
+}
+
+type syncRun struct {
+	s       *xorm.Session
+	sc      *Sync
+	syncer  Syncer
+	user    *user.User
+	now     time.Time
+	created []*SyncMapping
+
+	projectsBySource map[string]*SyncMapping
+	projectsByTarget map[int64]*SyncMapping
+	tasksBySource    map[string]*SyncMapping
+	tasksByTarget    map[int64]*SyncMapping
+}
+
+func runSync(s *xorm.Session, sc *Sync, syncer Syncer) (err error) {
+	u, err := user.GetUserByID(s, sc.UserID)
+	if err != nil {
+		return err
+	}
+
+	projects, tasks, err := syncer.Fetch(&sc.SyncCredentials)
+	if err != nil {
+		return err
+	}
+
+	mappings := []*SyncMapping{}
+	err = s.Where("sync_id = ?", sc.ID).Find(&mappings)
+	if err != nil {
+		return err
+	}
+
+	run := &syncRun{
+		s:                s,
+		sc:               sc,
+		syncer:           syncer,
+		user:             u,
+		now:              time.Now(),
+		projectsBySource: make(map[string]*SyncMapping),
+		projectsByTarget: make(map[int64]*SyncMapping),
+		tasksBySource:    make(map[string]*SyncMapping),
+		tasksByTarget:    make(map[int64]*SyncMapping),
+	}
+	for _, m := range mappings {
+		run.addMapping(m)
+	}
+
+	err = run.syncProjects(projects)
+	if err != nil {
+		return err
+	}
+
+	err = run.syncTasks(tasks)
+	if err != nil {
+		return err
+	}
+
+	err = run.pushNewTasks()
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("[Migration Sync] Synced %d projects and %d tasks for sync %d", len(projects), len(tasks), sc.ID)
+
+	return nil
+}
+
+func (r *syncRun) addMapping(m *SyncMapping) {
+	switch m.Kind {
+	case SyncMappingKindProject:
+		r.projectsBySource[m.SourceID] = m
+		r.projectsByTarget[m.TargetID] = m
+	case SyncMappingKindTask:
+		r.tasksBySource[m.SourceID] = m
+		r.tasksByTarget[m.TargetID] = m
+	}
+}
+
+func (r *syncRun) removeMapping(m *SyncMapping) (err error) {
+	switch m.Kind {
+	case SyncMappingKindProject:
+		delete(r.projectsBySource, m.SourceID)
+		delete(r.projectsByTarget, m.TargetID)
+	case SyncMappingKindTask:
+		delete(r.tasksBySource, m.SourceID)
+		delete(r.tasksByTarget, m.TargetID)
+	}
+
+	_, err = r.s.Where("id = ?", m.ID).Delete(&SyncMapping{})
+	return
+}
+
+func (r *syncRun) insertMapping(kind, sourceID string, targetID int64, hash string) (err error) {
+	m := &SyncMapping{
+		SyncID:   r.sc.ID,
+		Kind:     kind,
+		SourceID: sourceID,
+		TargetID: targetID,
+		Hash:     hash,
+	}
+	_, err = r.s.Insert(m)
+	if err != nil {
+		return err
+	}
+	r.addMapping(m)
+	return nil
+}
+
+// syncProjects creates a Vikunja project for every new project in the service. Projects are only synced
+// from the service to Vikunja, not the other way around.
+func (r *syncRun) syncProjects(projects []*SyncProject) (err error) {
+	seen := make(map[string]bool, len(projects))
+	for _, sp := range projects {
+		seen[sp.SourceID] = true
+
+		if m, has := r.projectsBySource[sp.SourceID]; has {
+			_, err = models.GetProjectSimpleByID(r.s, m.TargetID)
+			if err == nil {
+				continue
+			}
+			if !models.IsErrProjectDoesNotExist(err) {
+				return err
+			}
+
+			// The project was deleted in Vikunja, it will be created again
+			err = r.removeMapping(m)
+			if err != nil {
+				return err
+			}
+		}
+
+		project := &models.Project{Title: sp.Title}
+		err = models.CreateProject(r.s, project, r.user, true, true)
+		if err != nil {
+			return err
+		}
+
+		err = r.insertMapping(SyncMappingKindProject, sp.SourceID, project.ID, "")
+		if err != nil {
+			return err
+		}
+
+		log.Debugf("[Migration Sync] Created project %d for project %s of sync %d", project.ID, sp.SourceID, r.sc.ID)
+	}
+
+	// Projects which were removed from the service are not synced anymore
+	for sourceID, m := range r.projectsBySource {
+		if seen[sourceID] {
+			continue
+		}
+		err = r.removeMapping(m)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncTasks brings all tasks from the service and their counterparts in Vikunja up to date. If a task changed
+// on both sides since the last sync, the side where it was modified last wins.
+func (r *syncRun) syncTasks(tasks []*SyncTask) (err error) {
+	seen := make(map[string]bool, len(tasks))
+	for _, st := range tasks {
+		projectMapping, has := r.projectsBySource[st.ProjectSourceID]
+		if !has {
+			continue
+		}
+		seen[st.SourceID] = true
+
+		m, has := r.tasksBySource[st.SourceID]
+		if !has {
+			err = r.createTask(st, projectMapping.TargetID)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		task, err := r.getTask(m.TargetID)
+		if models.IsErrTaskDoesNotExist(err) {
+			// Deleting tasks is not synced, the task simply won't be synced anymore
+			err = r.removeMapping(m)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		can, err := r.canUpdate(task)
+		if err != nil {
+			return err
+		}
+		if !can {
+			log.Debugf("[Migration Sync] User %d cannot change task %d anymore, skipping it", r.user.ID, task.ID)
+			continue
+		}
+
+		err = r.syncTask(st, task, m)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Tasks which are not returned by the service anymore were either done or deleted there
+	for sourceID, m := range r.tasksBySource {
+		if seen[sourceID] {
+			continue
+		}
+
+		task, err := r.getTask(m.TargetID)
+		if err != nil && !models.IsErrTaskDoesNotExist(err) {
+			return err
+		}
+		if err == nil && !task.Done {
+			current := syncTaskFromTask(task, m.SourceID, "")
+			can, err := r.canUpdate(task)
+			if err != nil {
+				return err
+			}
+			if can && current.hash() == m.Hash {
+				task.Done = true
+				err = task.Update(r.s, r.user)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		err = r.removeMapping(m)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getTask returns a task with everything Task.Update saves, so that updating it does not remove its
+// assignees or reminders.
+func (r *syncRun) getTask(taskID int64) (task *models.Task, err error) {
+	task = &models.Task{ID: taskID}
+	err = task.ReadOne(r.s, r.user)
+	return
+}
+
+// canUpdate checks if the user of the sync may still change a task. They might have lost access to its
+// project since the sync was enabled.
+func (r *syncRun) canUpdate(task *models.Task) (bool, error) {
+	can, err := task.CanUpdate(r.s, r.user)
+	if _, is := err.(web.HTTPErrorProcessor); is {
+		return false, nil
+	}
+	return can, err
+}
+
+func (r *syncRun) createTask(st *SyncTask, projectID int64) (err error) {
+	task := &models.Task{ProjectID: projectID}
+	can, err := task.CanCreate(r.s, r.user)
+	if _, is := err.(web.HTTPErrorProcessor); is || (err == nil && !can) {
+		log.Debugf("[Migration Sync] User %d cannot create tasks in project %d anymore, skipping task %s", r.user.ID, projectID, st.SourceID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	st.applyTo(task)
+	err = task.Create(r.s, r.user)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("[Migration Sync] Created task %d for task %s of sync %d", task.ID, st.SourceID, r.sc.ID)
+
+	return r.insertMapping(SyncMappingKindTask, st.SourceID, task.ID, st.hash())
+}
+
+func (r *syncRun) syncTask(st *SyncTask, task *models.Task, m *SyncMapping) (err error) {
+	current := syncTaskFromTask(task, st.SourceID, st.ProjectSourceID)
+	sourceChanged := st.hash() != m.Hash
+	targetChanged := current.hash() != m.Hash
+
+	if !sourceChanged && !targetChanged {
+		return nil
+	}
+
+	pull := sourceChanged
+	if sourceChanged && targetChanged {
+		// If the service does not tell when a task was modified, the change was only noticed now and is
+		// therefore considered the newer one.
+		sourceUpdated := st.Updated
+		if sourceUpdated.IsZero() {
+			sourceUpdated = r.now
+		}
+		pull = !task.Updated.After(sourceUpdated)
+
+		winner := "Vikunja"
+		if pull {
+			winner = r.sc.MigratorName
+		}
+		log.Debugf("[Migration Sync] Task %d was changed in Vikunja and %s, keeping the changes from %s", task.ID, r.sc.MigratorName, winner)
+	}
+
+	var hash string
+	if pull {
+		st.applyTo(task)
+		err = task.Update(r.s, r.user)
+		if err != nil {
+			return err
+		}
+		hash = st.hash()
+	} else {
+		err = r.syncer.UpdateTask(&r.sc.SyncCredentials, current)
+		if err != nil {
+			return err
+		}
+		hash = current.hash()
+	}
+
+	m.Hash = hash
+	_, err = r.s.Where("id = ?", m.ID).Cols("hash").Update(m)
+	return err
+}
+
+// pushNewTasks creates all tasks which were created in a synced project in Vikunja in the service.
+func (r *syncRun) pushNewTasks() (err error) {
+	for projectID, projectMapping := range r.projectsByTarget {
+		tasks := []*models.Task{}
+		err = r.s.
+			Where("project_id = ? AND done = ?", projectID, false).
+			OrderBy("id asc").
+			Find(&tasks)
+		if err != nil {
+			return err
+		}
+
+		for _, task := range tasks {
+			if _, has := r.tasksByTarget[task.ID]; has {
+				continue
+			}
+
+			st := syncTaskFromTask(task, "", projectMapping.SourceID)
+			st.SourceID, err = r.syncer.CreateTask(&r.sc.SyncCredentials, st)
+			if err != nil {
+				return err
+			}
+
+			err = r.insertMapping(SyncMappingKindTask, st.SourceID, task.ID, st.hash())
+			if err != nil {
+				return err
+			}
+
+			log.Debugf("[Migration Sync] Created task %s in %s for task %d of sync %d", st.SourceID, r.sc.MigratorName, task.ID, r.sc.ID)
+		}
+	}
+
+	return nil
+}
+
+// RegisterSyncCron registers the cron which periodically runs all syncs.
+func RegisterSyncCron() {
+	if !config.MigrationSyncEnable.GetBool() {
+		return
+	}
+
+	const logPrefix = "[Migration Sync Cron] "
+
+	err := cron.Schedule(config.MigrationSyncSchedule.GetString(), func() {
+		s := db.NewSession()
+		syncs := []*Sync{}
+		err := s.Find(&syncs)
+		s.Close()
+		if err != nil {
+			log.Errorf(logPrefix+"Could not get syncs: %s", err)
+			return
+		}
+
+		for _, sc := range syncs {
+			syncer, has := registeredSyncers[sc.MigratorName]
+			if !has {
+				continue
+			}
+
+			// Errors are saved with the sync and logged already
+			_ = RunSync(sc, syncer)
+		}
+	})
+	if err != nil {
+		log.Fatalf(logPrefix+"Could not register sync cron: %s", err)
+	}
+}
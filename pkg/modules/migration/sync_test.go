@@ -0,0 +1,256 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package migration
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSyncer keeps projects and tasks in memory, like a remote service would
+type fakeSyncer struct {
+	projects []*SyncProject
+	tasks    map[string]*SyncTask
+	nextID   int
+}
+
+func (f *fakeSyncer) Name() string {
+	return "fake-sync"
+}
+
+func (f *fakeSyncer) SyncAuthURL() string {
+	return ""
+}
+
+func (f *fakeSyncer) Connect(code string) (*SyncCredentials, error) {
+	return &SyncCredentials{AccessToken: code}, nil
+}
+
+func (f *fakeSyncer) Fetch(_ *SyncCredentials) (projects []*SyncProject, tasks []*SyncTask, err error) {
+	for _, t := range f.tasks {
+		copied := *t
+		tasks = append(tasks, &copied)
+	}
+	return f.projects, tasks, nil
+}
+
+func (f *fakeSyncer) CreateTask(_ *SyncCredentials, task *SyncTask) (string, error) {
+	f.nextID++
+	copied := *task
+	copied.SourceID = "new-" + strconv.Itoa(f.nextID)
+	f.tasks[copied.SourceID] = &copied
+	return copied.SourceID, nil
+}
+
+func (f *fakeSyncer) UpdateTask(_ *SyncCredentials, task *SyncTask) error {
+	copied := *task
+	f.tasks[task.SourceID] = &copied
+	return nil
+}
+
+func getSyncedTask(t *testing.T, sc *Sync, sourceID string) *models.Task {
+	s := db.NewSession()
+	defer s.Close()
+
+	m := &SyncMapping{}
+	has, err := s.Where("sync_id = ? AND kind = ? AND source_id = ?", sc.ID, SyncMappingKindTask, sourceID).Get(m)
+	require.NoError(t, err)
+	require.True(t, has, "no mapping for task %s", sourceID)
+
+	task, err := models.GetTaskByIDSimple(s, m.TargetID)
+	require.NoError(t, err)
+	return &task
+}
+
+func updateTask(t *testing.T, task *models.Task, u *user.User) {
+	s := db.NewSession()
+	defer s.Close()
+
+	err := task.Update(s, u)
+	require.NoError(t, err)
+	err = s.Commit()
+	require.NoError(t, err)
+}
+
+func TestSync(t *testing.T) {
+	u := &user.User{ID: 1}
+
+	setup := func(t *testing.T) (*fakeSyncer, *Sync) {
+		db.LoadAndAssertFixtures(t)
+		// Syncs are not part of the fixtures, so we need to remove the one from the last test by hand
+		err := DisableSync(&fakeSyncer{}, u)
+		if err != nil && !models.IsErrMigrationSyncDoesNotExist(err) {
+			require.NoError(t, err)
+		}
+
+		syncer := &fakeSyncer{
+			projects: []*SyncProject{
+				{SourceID: "p1", Title: "Synced project"},
+			},
+			tasks: map[string]*SyncTask{
+				"t1": {SourceID: "t1", ProjectSourceID: "p1", Title: "Synced task", Priority: 3},
+			},
+		}
+		sc, err := EnableSync(syncer, u, "token")
+		require.NoError(t, err)
+		err = RunSync(sc, syncer)
+		require.NoError(t, err)
+		return syncer, sc
+	}
+
+	t.Run("initial sync", func(t *testing.T) {
+		_, sc := setup(t)
+
+		assert.Equal(t, "token", sc.AccessToken)
+		assert.Empty(t, sc.LastError)
+		assert.False(t, sc.LastSyncAt.IsZero())
+		db.AssertExists(t, "projects", map[string]interface{}{
+			"title":    "Synced project",
+			"owner_id": 1,
+		}, false)
+		task := getSyncedTask(t, sc, "t1")
+		assert.Equal(t, "Synced task", task.Title)
+		assert.Equal(t, int64(3), task.Priority)
+	})
+	t.Run("pull changes", func(t *testing.T) {
+		syncer, sc := setup(t)
+
+		syncer.tasks["t1"].Title = "Changed in the service"
+		err := RunSync(sc, syncer)
+		require.NoError(t, err)
+
+		task := getSyncedTask(t, sc, "t1")
+		assert.Equal(t, "Changed in the service", task.Title)
+	})
+	t.Run("push changes", func(t *testing.T) {
+		syncer, sc := setup(t)
+
+		task := getSyncedTask(t, sc, "t1")
+		task.Title = "Changed in Vikunja"
+		task.Done = true
+		updateTask(t, task, u)
+
+		err := RunSync(sc, syncer)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Changed in Vikunja", syncer.tasks["t1"].Title)
+		assert.True(t, syncer.tasks["t1"].Done)
+	})
+	t.Run("conflict", func(t *testing.T) {
+		t.Run("service is newer", func(t *testing.T) {
+			syncer, sc := setup(t)
+
+			task := getSyncedTask(t, sc, "t1")
+			task.Title = "Changed in Vikunja"
+			updateTask(t, task, u)
+			syncer.tasks["t1"].Title = "Changed in the service"
+			syncer.tasks["t1"].Updated = time.Now().Add(time.Hour)
+
+			err := RunSync(sc, syncer)
+			require.NoError(t, err)
+
+			task = getSyncedTask(t, sc, "t1")
+			assert.Equal(t, "Changed in the service", task.Title)
+			assert.Equal(t, "Changed in the service", syncer.tasks["t1"].Title)
+		})
+		t.Run("vikunja is newer", func(t *testing.T) {
+			syncer, sc := setup(t)
+
+			task := getSyncedTask(t, sc, "t1")
+			task.Title = "Changed in Vikunja"
+			updateTask(t, task, u)
+			syncer.tasks["t1"].Title = "Changed in the service"
+			syncer.tasks["t1"].Updated = time.Now().Add(-time.Hour)
+
+			err := RunSync(sc, syncer)
+			require.NoError(t, err)
+
+			task = getSyncedTask(t, sc, "t1")
+			assert.Equal(t, "Changed in Vikunja", task.Title)
+			assert.Equal(t, "Changed in Vikunja", syncer.tasks["t1"].Title)
+		})
+	})
+	t.Run("push new tasks", func(t *testing.T) {
+		syncer, sc := setup(t)
+
+		existing := getSyncedTask(t, sc, "t1")
+		s := db.NewSession()
+		task := &models.Task{
+			Title:     "Created in Vikunja",
+			ProjectID: existing.ProjectID,
+		}
+		err := task.Create(s, u)
+		require.NoError(t, err)
+		err = s.Commit()
+		require.NoError(t, err)
+		s.Close()
+
+		err = RunSync(sc, syncer)
+		require.NoError(t, err)
+
+		require.Contains(t, syncer.tasks, "new-1")
+		assert.Equal(t, "Created in Vikunja", syncer.tasks["new-1"].Title)
+		assert.Equal(t, "p1", syncer.tasks["new-1"].ProjectSourceID)
+		assert.Equal(t, task.ID, getSyncedTask(t, sc, "new-1").ID)
+
+		// Running it again must not create the task twice
+		err = RunSync(sc, syncer)
+		require.NoError(t, err)
+		assert.Len(t, syncer.tasks, 2)
+	})
+	t.Run("task removed from the service", func(t *testing.T) {
+		syncer, sc := setup(t)
+		task := getSyncedTask(t, sc, "t1")
+
+		delete(syncer.tasks, "t1")
+		err := RunSync(sc, syncer)
+		require.NoError(t, err)
+
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"id":   task.ID,
+			"done": true,
+		}, false)
+		db.AssertMissing(t, "migration_sync_mappings", map[string]interface{}{
+			"sync_id":   sc.ID,
+			"source_id": "t1",
+		})
+	})
+	t.Run("disable", func(t *testing.T) {
+		_, sc := setup(t)
+
+		err := DisableSync(&fakeSyncer{}, u)
+		require.NoError(t, err)
+
+		db.AssertMissing(t, "migration_syncs", map[string]interface{}{
+			"id": sc.ID,
+		})
+		db.AssertMissing(t, "migration_sync_mappings", map[string]interface{}{
+			"sync_id": sc.ID,
+		})
+		_, err = GetSync(&fakeSyncer{}, u)
+		require.Error(t, err)
+		assert.True(t, models.IsErrMigrationSyncDoesNotExist(err))
+	})
+}
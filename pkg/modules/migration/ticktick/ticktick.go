@@ -21,6 +21,7 @@ import (
 	"errors"
 	"io"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -82,6 +83,7 @@ func convertTickTickToVikunja(tasks []*tickTickTask) (result []*models.ProjectWi
 				ID:    pseudoParentID,
 				Title: "Migrated from TickTick",
 			},
+			SourceID: migration.RootProjectSourceID,
 		},
 	}
 
@@ -95,6 +97,8 @@ func convertTickTickToVikunja(tasks []*tickTickTask) (result []*models.ProjectWi
 					ParentProjectID: pseudoParentID,
 					Title:           t.ProjectName,
 				},
+				// TickTick exports don't contain the id of a project, but its name is unique
+				SourceID: t.ProjectName,
 			}
 		}
 
@@ -119,6 +123,9 @@ func convertTickTickToVikunja(tasks []*tickTickTask) (result []*models.ProjectWi
 				Labels:      labels,
 			},
 		}
+		if t.TaskID != 0 {
+			task.SourceID = strconv.FormatInt(t.TaskID, 10)
+		}
 
 		if !t.DueDate.IsZero() && t.Reminder > 0 {
 			task.Task.Reminders = []*models.TaskReminder{
@@ -93,8 +93,10 @@ func TestConvertTicktickTasksToVikunja(t *testing.T) {
 
 	assert.Len(t, vikunjaTasks[1].Tasks, 3)
 	assert.Equal(t, vikunjaTasks[1].Title, tickTickTasks[0].ProjectName)
+	assert.Equal(t, tickTickTasks[0].ProjectName, vikunjaTasks[1].SourceID)
 
 	assert.Equal(t, vikunjaTasks[1].Tasks[0].Title, tickTickTasks[0].Title)
+	assert.Equal(t, "1", vikunjaTasks[1].Tasks[0].SourceID)
 	assert.Equal(t, vikunjaTasks[1].Tasks[0].Description, tickTickTasks[0].Content)
 	assert.Equal(t, vikunjaTasks[1].Tasks[0].StartDate, tickTickTasks[0].StartDate.Time)
 	assert.Equal(t, vikunjaTasks[1].Tasks[0].EndDate, tickTickTasks[0].DueDate.Time)
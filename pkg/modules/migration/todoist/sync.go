@@ -0,0 +1,285 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package todoist
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/modules/migration"
+	"code.vikunja.io/api/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+type command struct {
+	Type   string                 `json:"type"`
+	UUID   string                 `json:"uuid"`
+	TempID string                 `json:"temp_id,omitempty"`
+	Args   map[string]interface{} `json:"args"`
+}
+
+type commandResponse struct {
+	SyncStatus    map[string]interface{} `json:"sync_status"`
+	TempIDMapping map[string]string      `json:"temp_id_mapping"`
+}
+
+// SyncAuthURL returns the url users need to authorize a continuous sync at. In contrast to a migration,
+// the sync needs to be able to change tasks in todoist.
+// @Summary Get the auth url to sync with todoist
+// @Description Returns the auth url where the user needs to get its auth code to keep their tasks in todoist in sync with Vikunja.
+// @tags migration
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} handler.AuthURL "The auth url."
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/todoist/sync/auth [get]
+func (m *Migration) SyncAuthURL() string {
+	return "https://todoist.com/oauth/authorize" +
+		"?client_id=" + config.MigrationTodoistClientID.GetString() +
+		"&scope=data:read_write" +
+		"&state=" + utils.MakeRandomString(32)
+}
+
+// Connect exchanges the auth code for an api token. Todoist tokens don't expire.
+func (m *Migration) Connect(code string) (credentials *migration.SyncCredentials, err error) {
+	token, err := getAccessTokenFromAuthToken(code)
+	if err != nil {
+		return nil, err
+	}
+	if token == "" {
+		return nil, fmt.Errorf("could not get an api token from todoist")
+	}
+
+	return &migration.SyncCredentials{AccessToken: token}, nil
+}
+
+// Fetch returns all active projects and tasks. Todoist does not return done tasks in a sync.
+func (m *Migration) Fetch(credentials *migration.SyncCredentials) (projects []*migration.SyncProject, tasks []*migration.SyncTask, err error) {
+	resp, err := migration.DoPostWithHeaders(apiURL+"sync", url.Values{
+		"sync_token":     []string{"*"},
+		"resource_types": []string{`["projects","items"]`},
+	}, bearerHeader(credentials))
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 399 {
+		buf := &bytes.Buffer{}
+		_, _ = buf.ReadFrom(resp.Body)
+		return nil, nil, fmt.Errorf("got http status %d while trying to sync with todoist, error was %s", resp.StatusCode, buf.String())
+	}
+
+	syncResponse := &sync{}
+	err = json.NewDecoder(resp.Body).Decode(syncResponse)
+	if err != nil {
+		return
+	}
+
+	for _, p := range syncResponse.Projects {
+		if p.IsDeleted || p.IsArchived {
+			continue
+		}
+		projects = append(projects, &migration.SyncProject{
+			SourceID: p.ID,
+			Title:    p.Name,
+		})
+	}
+
+	for _, i := range syncResponse.Items {
+		if i.IsDeleted {
+			continue
+		}
+
+		t := &migration.SyncTask{
+			SourceID:        i.ID,
+			ProjectSourceID: i.ProjectID,
+			Title:           i.Content,
+			Done:            i.Checked,
+			Updated:         i.DateUpdated,
+		}
+
+		// Todoist descriptions are markdown, Vikunja's are html
+		if i.Description != "" {
+			t.Description, err = migration.ConvertMarkdownToHTML(i.Description)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		// Todoist priorities only range from 1 (lowest) and max 4 (highest), same as in the migration
+		if i.Priority > 1 {
+			t.Priority = i.Priority
+		}
+
+		if i.Due != nil {
+			t.DueDate, err = parseDate(i.Due.Date)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		tasks = append(tasks, t)
+	}
+
+	log.Debugf("[Todoist Sync] Got %d projects and %d tasks", len(projects), len(tasks))
+
+	return
+}
+
+// CreateTask creates a new task in todoist.
+func (m *Migration) CreateTask(credentials *migration.SyncCredentials, task *migration.SyncTask) (sourceID string, err error) {
+	tempID := uuid.NewString()
+	args, err := taskArgs(task)
+	if err != nil {
+		return "", err
+	}
+	args["project_id"] = task.ProjectSourceID
+
+	res, err := runCommands(credentials, &command{
+		Type:   "item_add",
+		UUID:   uuid.NewString(),
+		TempID: tempID,
+		Args:   args,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sourceID, has := res.TempIDMapping[tempID]
+	if !has {
+		return "", fmt.Errorf("todoist did not return an id for the new task")
+	}
+
+	if task.Done {
+		_, err = runCommands(credentials, &command{
+			Type: "item_complete",
+			UUID: uuid.NewString(),
+			Args: map[string]interface{}{"id": sourceID},
+		})
+	}
+
+	return sourceID, err
+}
+
+// UpdateTask saves all changes of a task to todoist.
+func (m *Migration) UpdateTask(credentials *migration.SyncCredentials, task *migration.SyncTask) (err error) {
+	args, err := taskArgs(task)
+	if err != nil {
+		return err
+	}
+	args["id"] = task.SourceID
+
+	doneCommand := "item_uncomplete"
+	if task.Done {
+		doneCommand = "item_complete"
+	}
+
+	_, err = runCommands(credentials,
+		&command{
+			Type: "item_update",
+			UUID: uuid.NewString(),
+			Args: args,
+		},
+		&command{
+			Type: doneCommand,
+			UUID: uuid.NewString(),
+			Args: map[string]interface{}{"id": task.SourceID},
+		},
+	)
+	return
+}
+
+func bearerHeader(credentials *migration.SyncCredentials) map[string]string {
+	return map[string]string{
+		"Authorization": "Bearer " + credentials.AccessToken,
+	}
+}
+
+func taskArgs(task *migration.SyncTask) (args map[string]interface{}, err error) {
+	description, err := migration.ConvertHTMLToMarkdown(task.Description)
+	if err != nil {
+		return nil, err
+	}
+
+	args = map[string]interface{}{
+		"content":     task.Title,
+		"description": description,
+		"priority":    1,
+		"due":         nil,
+	}
+
+	if task.Priority > 1 {
+		args["priority"] = task.Priority
+	}
+	if task.Priority > 4 {
+		args["priority"] = 4
+	}
+
+	if !task.DueDate.IsZero() {
+		due := task.DueDate.UTC()
+		// Dates without a time are parsed as the end of the day, see parseDate
+		date := due.Format("2006-01-02T15:04:05Z")
+		if due.Hour() == 23 && due.Minute() == 59 && due.Second() == 0 {
+			date = due.Format("2006-01-02")
+		}
+		args["due"] = map[string]string{"date": date}
+	}
+
+	return args, nil
+}
+
+func runCommands(credentials *migration.SyncCredentials, commands ...*command) (res *commandResponse, err error) {
+	cmds, err := json.Marshal(commands)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := migration.DoPostWithHeaders(apiURL+"sync", url.Values{
+		"commands": []string{string(cmds)},
+	}, bearerHeader(credentials))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode > 399 {
+		buf := &bytes.Buffer{}
+		_, _ = buf.ReadFrom(resp.Body)
+		return nil, fmt.Errorf("got http status %d while sending changes to todoist, error was %s", resp.StatusCode, buf.String())
+	}
+
+	res = &commandResponse{}
+	err = json.NewDecoder(resp.Body).Decode(res)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cmd := range commands {
+		status, has := res.SyncStatus[cmd.UUID]
+		if !has || status != "ok" {
+			return nil, fmt.Errorf("todoist could not run %s: %v", cmd.Type, status)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,145 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package todoist
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/modules/migration"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTodoistStub starts a local server which answers like the todoist sync api and records all commands sent to it
+func newTodoistStub(t *testing.T, commands *[]*command) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/sync", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		require.NoError(t, r.ParseForm())
+
+		if r.Form.Get("commands") == "" {
+			_, _ = w.Write([]byte(`{
+				"projects": [
+					{"id": "p1", "name": "Inbox"},
+					{"id": "p2", "name": "Archived", "is_archived": true}
+				],
+				"items": [
+					{"id": "i1", "project_id": "p1", "content": "Task 1", "description": "Lorem **ipsum**", "priority": 4, "due": {"date": "2024-07-01"}, "updated_at": "2024-06-30T10:00:00Z"},
+					{"id": "i2", "project_id": "p1", "content": "Task 2", "priority": 1, "due": {"date": "2024-07-02T09:30:00Z"}},
+					{"id": "i3", "project_id": "p1", "content": "Deleted", "is_deleted": true}
+				]
+			}`))
+			return
+		}
+
+		cmds := []*command{}
+		require.NoError(t, json.Unmarshal([]byte(r.Form.Get("commands")), &cmds))
+		*commands = append(*commands, cmds...)
+
+		res := &commandResponse{
+			SyncStatus:    map[string]interface{}{},
+			TempIDMapping: map[string]string{},
+		}
+		for _, cmd := range cmds {
+			res.SyncStatus[cmd.UUID] = "ok"
+			if cmd.TempID != "" {
+				res.TempIDMapping[cmd.TempID] = "created-id"
+			}
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+	t.Cleanup(server.Close)
+
+	oldAPIURL := apiURL
+	apiURL = server.URL + "/"
+	t.Cleanup(func() {
+		apiURL = oldAPIURL
+	})
+}
+
+func TestSyncer(t *testing.T) {
+	credentials := &migration.SyncCredentials{AccessToken: "secret"}
+
+	t.Run("fetch", func(t *testing.T) {
+		newTodoistStub(t, &[]*command{})
+
+		projects, tasks, err := (&Migration{}).Fetch(credentials)
+		require.NoError(t, err)
+
+		require.Len(t, projects, 1)
+		assert.Equal(t, "p1", projects[0].SourceID)
+		assert.Equal(t, "Inbox", projects[0].Title)
+
+		require.Len(t, tasks, 2)
+		assert.Equal(t, "i1", tasks[0].SourceID)
+		assert.Equal(t, "p1", tasks[0].ProjectSourceID)
+		assert.Equal(t, "Task 1", tasks[0].Title)
+		assert.Equal(t, "<p>Lorem <strong>ipsum</strong></p>\n", tasks[0].Description)
+		assert.Equal(t, int64(4), tasks[0].Priority)
+		assert.Equal(t, time.Date(2024, 7, 1, 23, 59, 0, 0, time.UTC), tasks[0].DueDate)
+		assert.Equal(t, time.Date(2024, 6, 30, 10, 0, 0, 0, time.UTC), tasks[0].Updated)
+		assert.Equal(t, int64(0), tasks[1].Priority)
+		assert.Equal(t, time.Date(2024, 7, 2, 9, 30, 0, 0, time.UTC), tasks[1].DueDate)
+	})
+	t.Run("create task", func(t *testing.T) {
+		commands := []*command{}
+		newTodoistStub(t, &commands)
+
+		id, err := (&Migration{}).CreateTask(credentials, &migration.SyncTask{
+			ProjectSourceID: "p1",
+			Title:           "New task",
+			Description:     "<p>Lorem <strong>ipsum</strong></p><ul><li><p>one</p></li><li><p>two</p></li></ul>",
+			Priority:        5,
+			DueDate:         time.Date(2024, 7, 1, 23, 59, 0, 0, time.UTC),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "created-id", id)
+
+		require.Len(t, commands, 1)
+		assert.Equal(t, "item_add", commands[0].Type)
+		assert.Equal(t, "p1", commands[0].Args["project_id"])
+		assert.Equal(t, "New task", commands[0].Args["content"])
+		assert.Equal(t, "Lorem **ipsum**\n\n- one\n- two", commands[0].Args["description"])
+		assert.InDelta(t, 4, commands[0].Args["priority"], 0)
+		assert.Equal(t, map[string]interface{}{"date": "2024-07-01"}, commands[0].Args["due"])
+	})
+	t.Run("update task", func(t *testing.T) {
+		commands := []*command{}
+		newTodoistStub(t, &commands)
+
+		err := (&Migration{}).UpdateTask(credentials, &migration.SyncTask{
+			SourceID:        "i1",
+			ProjectSourceID: "p1",
+			Title:           "Changed",
+			Done:            true,
+		})
+		require.NoError(t, err)
+
+		require.Len(t, commands, 2)
+		assert.Equal(t, "item_update", commands[0].Type)
+		assert.Equal(t, "i1", commands[0].Args["id"])
+		assert.Equal(t, "Changed", commands[0].Args["content"])
+		assert.Nil(t, commands[0].Args["due"])
+		assert.Equal(t, "item_complete", commands[1].Type)
+		assert.Equal(t, "i1", commands[1].Args["id"])
+	})
+}
@@ -37,6 +37,9 @@ import (
 
 const paginationLimit = 200
 
+// apiURL is the base url of the todoist sync api. Tests point it to a local stub.
+var apiURL = "https://api.todoist.com/sync/v9/"
+
 // Migration is the todoist migration struct
 type Migration struct {
 	Code string `json:"code"`
@@ -81,6 +84,7 @@ type item struct {
 	UserID         string      `json:"user_id"`
 	ProjectID      string      `json:"project_id"`
 	Content        string      `json:"content"`
+	Description    string      `json:"description"`
 	Priority       int64       `json:"priority"`
 	Due            *dueDate    `json:"due"`
 	ParentID       string      `json:"parent_id"`
@@ -95,6 +99,8 @@ type item struct {
 	DateAdded      time.Time   `json:"added_at"`
 	HasMoreNotes   bool        `json:"has_more_notes"`
 	DateCompleted  time.Time   `json:"completed_at"`
+	DateUpdated    time.Time   `json:"updated_at"`
+	IsDeleted      bool        `json:"is_deleted"`
 }
 
 type itemWrapper struct {
@@ -245,6 +251,37 @@ func parseDate(dateString string) (date time.Time, err error) {
 	return date, err
 }
 
+// getChecklistCandidates returns the ids of all sub-tasks which only have a title and a done state. They are
+// converted to checklist items of their parent task instead of tasks on their own.
+func getChecklistCandidates(sync *sync) map[string]bool {
+	hasDetails := make(map[string]bool)
+	for _, i := range sync.Items {
+		if i != nil && i.ParentID != "" {
+			// Tasks with sub-tasks of their own
+			hasDetails[i.ParentID] = true
+		}
+	}
+	for _, n := range sync.Notes {
+		hasDetails[n.ItemID] = true
+	}
+	for _, r := range sync.Reminders {
+		hasDetails[r.ItemID] = true
+	}
+
+	candidates := make(map[string]bool)
+	for _, i := range sync.Items {
+		if i == nil || i.ParentID == "" || hasDetails[i.ID] {
+			continue
+		}
+		if i.Description != "" || i.Due != nil || len(i.Labels) > 0 || i.Priority > 1 || i.ResponsibleUID != "" {
+			continue
+		}
+		candidates[i.ID] = true
+	}
+
+	return candidates
+}
+
 func convertTodoistToVikunja(sync *sync, doneItems map[string]*doneItem) (fullVikunjaHierachie []*models.ProjectWithTasksAndBuckets, err error) {
 
 	var pseudoParentID int64 = 1
@@ -254,6 +291,7 @@ func convertTodoistToVikunja(sync *sync, doneItems map[string]*doneItem) (fullVi
 			ID:    pseudoParentID,
 			Title: "Migrated from todoist",
 		},
+		SourceID: migration.RootProjectSourceID,
 	}
 	fullVikunjaHierachie = append(fullVikunjaHierachie, parent)
 
@@ -277,6 +315,7 @@ func convertTodoistToVikunja(sync *sync, doneItems map[string]*doneItem) (fullVi
 				HexColor:        todoistColors[p.Color],
 				IsArchived:      p.IsArchived,
 			},
+			SourceID: p.ID,
 		}
 
 		lists[p.ID] = project
@@ -324,6 +363,7 @@ func convertTodoistToVikunja(sync *sync, doneItems map[string]*doneItem) (fullVi
 				Done:     i.Checked,
 				BucketID: sections[i.SectionID],
 			},
+			SourceID: i.ID,
 		}
 
 		// Only try to parse the task done at date if the task is actually done
@@ -367,13 +407,22 @@ func convertTodoistToVikunja(sync *sync, doneItems map[string]*doneItem) (fullVi
 		lists[i.ProjectID].Tasks = append(lists[i.ProjectID].Tasks, task)
 	}
 
-	// If the parenId of a task is not 0, create a task relation
-	// We're looping again here to make sure we have seen all tasks before and have them in our map
+	checklistCandidates := getChecklistCandidates(sync)
+
+	// Sorted to keep the order of the sub-tasks in the checklists
+	subTasks := make([]*item, 0, len(sync.Items))
 	for _, i := range sync.Items {
-		if i.ParentID == "" {
-			continue
+		if i != nil && i.ParentID != "" {
+			subTasks = append(subTasks, i)
 		}
+	}
+	sort.SliceStable(subTasks, func(a, b int) bool {
+		return subTasks[a].ChildOrder < subTasks[b].ChildOrder
+	})
 
+	// If the parenId of a task is not 0, create a task relation
+	// We're looping again here to make sure we have seen all tasks before and have them in our map
+	for _, i := range subTasks {
 		if _, exists := tasks[i.ParentID]; !exists {
 			log.Debugf("[Todoist Migration] Could not find task %s in tasks map while trying to resolve subtasks for task %s", i.ParentID, i.ID)
 			continue
@@ -389,7 +438,16 @@ func convertTodoistToVikunja(sync *sync, doneItems map[string]*doneItem) (fullVi
 			continue
 		}
 
-		tasks[i.ParentID].RelatedTasks[models.RelationKindSubtask] = append(tasks[i.ParentID].RelatedTasks[models.RelationKindSubtask], &tasks[i.ID].Task)
+		// Sub-tasks with nothing but a title are what a checklist is in Vikunja
+		if checklistCandidates[i.ID] {
+			tasks[i.ParentID].ChecklistItems = append(tasks[i.ParentID].ChecklistItems, &models.TaskChecklistItem{
+				Title:  tasks[i.ID].Title,
+				Done:   tasks[i.ID].Done,
+				DoneAt: tasks[i.ID].DoneAt,
+			})
+		} else {
+			tasks[i.ParentID].RelatedTasks[models.RelationKindSubtask] = append(tasks[i.ParentID].RelatedTasks[models.RelationKindSubtask], &tasks[i.ID].Task)
+		}
 
 		// Remove the task from the top level structure, otherwise it is added twice
 	outer:
@@ -542,7 +600,7 @@ func (m *Migration) Migrate(u *user.User) (err error) {
 		"Authorization": "Bearer " + token,
 	}
 
-	resp, err := migration.DoPostWithHeaders("https://api.todoist.com/sync/v9/sync", form, bearerHeader)
+	resp, err := migration.DoPostWithHeaders(apiURL+"sync", form, bearerHeader)
 	if err != nil {
 		return
 	}
@@ -561,7 +619,7 @@ func (m *Migration) Migrate(u *user.User) (err error) {
 	doneItems := make(map[string]*doneItem)
 
 	for {
-		resp, err = migration.DoPostWithHeaders("https://api.todoist.com/sync/v9/completed/get_all?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset*paginationLimit), form, bearerHeader)
+		resp, err = migration.DoPostWithHeaders(apiURL+"completed/get_all?limit="+strconv.Itoa(paginationLimit)+"&offset="+strconv.Itoa(offset*paginationLimit), form, bearerHeader)
 		if err != nil {
 			return
 		}
@@ -593,7 +651,7 @@ func (m *Migration) Migrate(u *user.User) (err error) {
 			doneItems[i.TaskID] = i
 
 			// need to get done item data
-			resp, err = migration.DoPostWithHeaders("https://api.todoist.com/sync/v9/items/get", url.Values{
+			resp, err = migration.DoPostWithHeaders(apiURL+"items/get", url.Values{
 				"item_id": []string{i.TaskID},
 			}, bearerHeader)
 			if err != nil {
@@ -630,7 +688,7 @@ func (m *Migration) Migrate(u *user.User) (err error) {
 	log.Debugf("[Todoist Migration] Getting archived projects for user %d", u.ID)
 
 	// Get all archived projects
-	resp, err = migration.DoPostWithHeaders("https://api.todoist.com/sync/v9/projects/get_archived", form, bearerHeader)
+	resp, err = migration.DoPostWithHeaders(apiURL+"projects/get_archived", form, bearerHeader)
 	if err != nil {
 		return
 	}
@@ -648,7 +706,7 @@ func (m *Migration) Migrate(u *user.User) (err error) {
 
 	// Project data is not included in the regular sync for archived projects, so we need to get all of those by hand
 	for _, p := range archivedProjects {
-		resp, err = migration.DoPostWithHeaders("https://api.todoist.com/sync/v9/projects/get_data?project_id="+p.ID, form, bearerHeader)
+		resp, err = migration.DoPostWithHeaders(apiURL+"projects/get_data?project_id="+p.ID, form, bearerHeader)
 		if err != nil {
 			return
 		}
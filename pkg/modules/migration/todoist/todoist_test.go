@@ -24,6 +24,7 @@ import (
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/files"
 	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -143,6 +144,28 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 				Checked:    false,
 				DateAdded:  time1,
 			},
+			{
+				ID:         "400000121",
+				UserID:     "1855589",
+				ProjectID:  "396936926",
+				Content:    "Second checklist item",
+				Priority:   1,
+				ParentID:   "400000006",
+				ChildOrder: 3,
+				DateAdded:  time1,
+			},
+			{
+				ID:            "400000120",
+				UserID:        "1855589",
+				ProjectID:     "396936926",
+				Content:       "First checklist item",
+				Priority:      1,
+				ParentID:      "400000006",
+				ChildOrder:    2,
+				DateAdded:     time1,
+				Checked:       true,
+				DateCompleted: time3,
+			},
 			{
 				ID:            "400000106",
 				UserID:        "1855589",
@@ -371,6 +394,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 				ID:    1,
 				Title: "Migrated from todoist",
 			},
+			SourceID: migration.RootProjectSourceID,
 		},
 		{
 			Project: models.Project{
@@ -380,6 +404,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 				Description:     "Lorem Ipsum dolor sit amet\nLorem Ipsum dolor sit amet 2\nLorem Ipsum dolor sit amet 3",
 				HexColor:        todoistColors["berry_red"],
 			},
+			SourceID: "396936926",
 			Buckets: []*models.Bucket{
 				{
 					ID:    1,
@@ -398,6 +423,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 							{Reminder: time.Date(2020, time.June, 16, 7, 0, 0, 0, time.UTC).In(config.GetTimeZone())},
 						},
 					},
+					SourceID: "400000000",
 				},
 				{
 					Task: models.Task{
@@ -406,6 +432,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						Done:        false,
 						Created:     time1,
 					},
+					SourceID: "400000001",
 				},
 				{
 					Task: models.Task{
@@ -416,6 +443,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 							{Reminder: time.Date(2020, time.July, 15, 7, 0, 0, 0, time.UTC).In(config.GetTimeZone())},
 						},
 					},
+					SourceID: "400000002",
 				},
 				{
 					Task: models.Task{
@@ -430,6 +458,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 							{Reminder: time.Date(2020, time.June, 15, 7, 0, 0, 0, time.UTC).In(config.GetTimeZone())},
 						},
 					},
+					SourceID: "400000003",
 				},
 				{
 					Task: models.Task{
@@ -438,6 +467,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						Created: time1,
 						Labels:  vikunjaLabels,
 					},
+					SourceID: "400000004",
 				},
 				{
 					Task: models.Task{
@@ -450,6 +480,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 							{Reminder: time.Date(2020, time.June, 15, 7, 0, 0, 0, time.UTC).In(config.GetTimeZone())},
 						},
 					},
+					SourceID: "400000005",
 				},
 				{
 					Task: models.Task{
@@ -469,7 +500,18 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 								},
 							},
 						},
+						ChecklistItems: []*models.TaskChecklistItem{
+							{
+								Title:  "First checklist item",
+								Done:   true,
+								DoneAt: time3,
+							},
+							{
+								Title: "Second checklist item",
+							},
+						},
 					},
+					SourceID: "400000006",
 				},
 				{
 					Task: models.Task{
@@ -480,6 +522,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						DoneAt:  time3,
 						Labels:  vikunjaLabels,
 					},
+					SourceID: "400000106",
 				},
 				{
 					Task: models.Task{
@@ -488,6 +531,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						Created: time1,
 						DoneAt:  time3,
 					},
+					SourceID: "400000107",
 				},
 				{
 					Task: models.Task{
@@ -496,6 +540,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						Created: time1,
 						DoneAt:  time3,
 					},
+					SourceID: "400000108",
 				},
 				{
 					Task: models.Task{
@@ -505,6 +550,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						DoneAt:   time3,
 						BucketID: 1,
 					},
+					SourceID: "400000109",
 				},
 			},
 		},
@@ -516,6 +562,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 				Description:     "Lorem Ipsum dolor sit amet 4\nLorem Ipsum dolor sit amet 5",
 				HexColor:        todoistColors["mint_green"],
 			},
+			SourceID: "396936927",
 			Tasks: []*models.TaskWithComments{
 				{
 					Task: models.Task{
@@ -524,6 +571,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						DueDate: dueTime,
 						Created: time1,
 					},
+					SourceID: "400000007",
 				},
 				{
 					Task: models.Task{
@@ -532,6 +580,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						DueDate: dueTime,
 						Created: time1,
 					},
+					SourceID: "400000008",
 				},
 				{
 					Task: models.Task{
@@ -542,6 +591,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 							{Reminder: time.Date(2020, time.June, 15, 7, 0, 0, 0, time.UTC).In(config.GetTimeZone())},
 						},
 					},
+					SourceID: "400000009",
 				},
 				{
 					Task: models.Task{
@@ -551,6 +601,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						Created:     time1,
 						DoneAt:      time3,
 					},
+					SourceID: "400000010",
 				},
 				{
 					Task: models.Task{
@@ -571,6 +622,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 							},
 						},
 					},
+					SourceID: "400000101",
 				},
 				{
 					Task: models.Task{
@@ -580,6 +632,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						Created: time1,
 						Labels:  vikunjaLabels,
 					},
+					SourceID: "400000102",
 				},
 				{
 					Task: models.Task{
@@ -588,6 +641,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						Created: time1,
 						Labels:  vikunjaLabels,
 					},
+					SourceID: "400000103",
 				},
 				{
 					Task: models.Task{
@@ -596,6 +650,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						Created: time1,
 						Labels:  vikunjaLabels,
 					},
+					SourceID: "400000104",
 				},
 				{
 					Task: models.Task{
@@ -605,6 +660,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						Created: time1,
 						Labels:  vikunjaLabels,
 					},
+					SourceID: "400000105",
 				},
 			},
 		},
@@ -616,6 +672,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 				HexColor:        todoistColors["mint_green"],
 				IsArchived:      true,
 			},
+			SourceID: "396936928",
 			Tasks: []*models.TaskWithComments{
 				{
 					Task: models.Task{
@@ -624,6 +681,7 @@ func TestConvertTodoistToVikunja(t *testing.T) {
 						Created: time1,
 						DoneAt:  time3,
 					},
+					SourceID: "400000111",
 				},
 			},
 		},
@@ -17,8 +17,6 @@
 package trello
 
 import (
-	"bytes"
-
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/files"
 	"code.vikunja.io/api/pkg/log"
@@ -27,7 +25,6 @@ import (
 	"code.vikunja.io/api/pkg/user"
 
 	"github.com/adlio/trello"
-	"github.com/yuin/goldmark"
 )
 
 // Migration represents the trello migration struct
@@ -213,16 +210,6 @@ func fillCardData(client *trello.Client, board *trello.Board) (err error) {
 	return
 }
 
-func convertMarkdownToHTML(input string) (output string, err error) {
-	var buf bytes.Buffer
-	err = goldmark.Convert([]byte(input), &buf)
-	if err != nil {
-		return
-	}
-	//#nosec - we are not responsible to escape this as we don't know the context where it is used
-	return buf.String(), nil
-}
-
 // Converts all previously obtained data from trello into the vikunja format.
 // `trelloData` should contain all boards with their projects and cards respectively.
 func convertTrelloDataToVikunja(organizationName string, trelloData []*trello.Board, token string, currentMember *trello.Member) (fullVikunjaHierachie []*models.ProjectWithTasksAndBuckets, err error) {
@@ -236,6 +223,7 @@ func convertTrelloDataToVikunja(organizationName string, trelloData []*trello.Bo
 				ID:    pseudoParentID,
 				Title: organizationName,
 			},
+			SourceID: organizationName,
 		},
 	}
 
@@ -252,6 +240,7 @@ func convertTrelloDataToVikunja(organizationName string, trelloData []*trello.Bo
 				Description:     board.Desc,
 				IsArchived:      board.Closed,
 			},
+			SourceID: board.ID,
 		}
 
 		// Background
@@ -286,9 +275,10 @@ func convertTrelloDataToVikunja(organizationName string, trelloData []*trello.Bo
 						Title:    card.Name,
 						BucketID: bucketID,
 					},
+					SourceID: card.ID,
 				}
 
-				task.Description, err = convertMarkdownToHTML(card.Desc)
+				task.Description, err = migration.ConvertMarkdownToHTML(card.Desc)
 				if err != nil {
 					return nil, err
 				}
@@ -297,19 +287,20 @@ func convertTrelloDataToVikunja(organizationName string, trelloData []*trello.Bo
 					task.DueDate = *card.Due
 				}
 
-				// Checklists (as markdown in description)
+				// Checklists
+				// Vikunja tasks only have one checklist, so we're prefixing the items with the name of their
+				// checklist if the card has more than one.
 				for _, checklist := range card.Checklists {
-					task.Description += "\n\n<h2> " + checklist.Name + "</h2>\n\n" + `<ul data-type="taskList">`
-
 					for _, item := range checklist.CheckItems {
-						task.Description += "\n"
-						if item.State == "complete" {
-							task.Description += `<li data-checked="true" data-type="taskItem"><label><input type="checkbox" checked="checked"><span></span></label><div><p>` + item.Name + `</p></div></li>`
-						} else {
-							task.Description += `<li data-checked="false" data-type="taskItem"><label><input type="checkbox"><span></span></label><div><p>` + item.Name + `</p></div></li>`
+						title := item.Name
+						if len(card.Checklists) > 1 {
+							title = checklist.Name + ": " + item.Name
 						}
+						task.ChecklistItems = append(task.ChecklistItems, &models.TaskChecklistItem{
+							Title: title,
+							Done:  item.State == "complete",
+						})
 					}
-					task.Description += "</ul>"
 				}
 				if len(card.Checklists) > 0 {
 					log.Debugf("[Trello Migration] Converted %d checklists from card %s", len(card.Checklists), card.ID)
@@ -411,7 +402,7 @@ func convertTrelloDataToVikunja(organizationName string, trelloData []*trello.Bo
 							comment.Comment = "*" + action.MemberCreator.FullName + "*:\n\n" + comment.Comment
 						}
 
-						comment.Comment, err = convertMarkdownToHTML(comment.Comment)
+						comment.Comment, err = migration.ConvertMarkdownToHTML(comment.Comment)
 						if err != nil {
 							return
 						}
@@ -41,6 +41,7 @@ func getTestBoard(t *testing.T) ([]*trello.Board, time.Time) {
 
 	trelloData := []*trello.Board{
 		{
+			ID:   "board1",
 			Name: "TestBoard",
 			Organization: trello.Organization{
 				ID:          "orgid",
@@ -54,6 +55,7 @@ func getTestBoard(t *testing.T) ([]*trello.Board, time.Time) {
 					Name: "Test Project 1",
 					Cards: []*trello.Card{
 						{
+							ID:   "card1",
 							Name: "Test Card 1",
 							Desc: "Card Description **bold**",
 							Pos:  123,
@@ -250,6 +252,7 @@ func TestConvertTrelloToVikunja(t *testing.T) {
 					ID:    1,
 					Title: "orgid",
 				},
+				SourceID: "orgid",
 			},
 			{
 				Project: models.Project{
@@ -259,6 +262,7 @@ func TestConvertTrelloToVikunja(t *testing.T) {
 					Description:           "This is a description",
 					BackgroundInformation: bytes.NewBuffer(exampleFile),
 				},
+				SourceID: "board1",
 				Buckets: []*models.Bucket{
 					{
 						ID:    1,
@@ -298,23 +302,26 @@ func TestConvertTrelloToVikunja(t *testing.T) {
 								},
 							},
 						},
+						SourceID: "card1",
 					},
 					{
 						Task: models.Task{
 							Title: "Test Card 2",
-							Description: `
-
-<h2> Checkproject 1</h2>
-
-<ul data-type="taskList">
-<li data-checked="false" data-type="taskItem"><label><input type="checkbox"><span></span></label><div><p>Pending Task</p></div></li>
-<li data-checked="true" data-type="taskItem"><label><input type="checkbox" checked="checked"><span></span></label><div><p>Completed Task</p></div></li></ul>
-
-<h2> Checkproject 2</h2>
-
-<ul data-type="taskList">
-<li data-checked="false" data-type="taskItem"><label><input type="checkbox"><span></span></label><div><p>Pending Task</p></div></li>
-<li data-checked="false" data-type="taskItem"><label><input type="checkbox"><span></span></label><div><p>Another Pending Task</p></div></li></ul>`,
+							ChecklistItems: []*models.TaskChecklistItem{
+								{
+									Title: "Checkproject 1: Pending Task",
+								},
+								{
+									Title: "Checkproject 1: Completed Task",
+									Done:  true,
+								},
+								{
+									Title: "Checkproject 2: Pending Task",
+								},
+								{
+									Title: "Checkproject 2: Another Pending Task",
+								},
+							},
 							BucketID: 1,
 						},
 					},
@@ -406,6 +413,7 @@ func TestConvertTrelloToVikunja(t *testing.T) {
 					ID:    1,
 					Title: "orgid2",
 				},
+				SourceID: "orgid2",
 			},
 			{
 				Project: models.Project{
@@ -435,6 +443,7 @@ func TestConvertTrelloToVikunja(t *testing.T) {
 					ID:    1,
 					Title: "Personal",
 				},
+				SourceID: "Personal",
 			},
 			{
 				Project: models.Project{
@@ -0,0 +1,133 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package vikunjafile
+
+import (
+	"strconv"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
+	"code.vikunja.io/api/pkg/user"
+
+	"xorm.io/xorm"
+)
+
+// remapMembers replaces the assignees in the projects of a project tree export with the users of this instance
+// with the same username. Users which don't exist are reported in the migration report and removed from the tasks.
+// Tasks and comments are always created by the importing user and the shares of the exported projects are not
+// imported, they are only listed in the migration report so the importing user can share the projects again.
+func remapMembers(doer *user.User, projects []*models.ProjectWithTasksAndBuckets, members *models.ProjectTreeMembers) (err error) {
+	s := db.NewSession()
+	defer s.Close()
+
+	users, err := getUserMapping(s, doer, members)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range projects {
+		remapMembersInProjectAndChildren(p, users)
+	}
+
+	return reportShares(doer, projects, members)
+}
+
+// getUserMapping maps the ids of the users in a project tree export to the users of this instance.
+// Users which could not be found are not in the map.
+func getUserMapping(s *xorm.Session, doer *user.User, members *models.ProjectTreeMembers) (users map[int64]*user.User, err error) {
+	users = make(map[int64]*user.User, len(members.Users))
+	for _, eu := range members.Users {
+		u, err := user.GetUserByUsername(s, eu.Username)
+		if err != nil && !user.IsErrUserDoesNotExist(err) {
+			return nil, err
+		}
+		if err != nil {
+			log.Debugf(logPrefix+"Could not find a user for exported user %s (%d)", eu.Username, eu.ID)
+			err = migration.SkipItem(doer, migration.ItemKindUser, strconv.FormatInt(eu.ID, 10), eu.Username,
+				"No user with this username exists. The user was removed from all tasks.")
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+		users[eu.ID] = u
+	}
+
+	return users, nil
+}
+
+// reportShares adds every share of the exported projects to the migration report.
+func reportShares(doer *user.User, projects []*models.ProjectWithTasksAndBuckets, members *models.ProjectTreeMembers) (err error) {
+	projectTitles := make(map[int64]string)
+	var addTitles func(ps []*models.ProjectWithTasksAndBuckets)
+	addTitles = func(ps []*models.ProjectWithTasksAndBuckets) {
+		for _, p := range ps {
+			projectTitles[p.ID] = p.Title
+			addTitles(p.ChildProjects)
+		}
+	}
+	addTitles(projects)
+
+	usernames := make(map[int64]string, len(members.Users))
+	for _, eu := range members.Users {
+		usernames[eu.ID] = eu.Username
+	}
+	teamNames := make(map[int64]string, len(members.Teams))
+	for _, et := range members.Teams {
+		teamNames[et.ID] = et.Name
+	}
+
+	for _, share := range members.Shares {
+		title, has := projectTitles[share.ProjectID]
+		if !has {
+			continue
+		}
+
+		sharedWith := "user " + usernames[share.UserID]
+		sourceID := "user-" + strconv.FormatInt(share.UserID, 10)
+		if share.TeamID != 0 {
+			sharedWith = "team " + teamNames[share.TeamID]
+			sourceID = "team-" + strconv.FormatInt(share.TeamID, 10)
+		}
+
+		err = migration.SkipItem(doer, migration.ItemKindShare, sourceID, title,
+			"The project was shared with the "+sharedWith+". Shares are not imported, share the project again if they should have access.")
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func remapMembersInProjectAndChildren(p *models.ProjectWithTasksAndBuckets, users map[int64]*user.User) {
+	for _, t := range p.Tasks {
+		assignees := make([]*user.User, 0, len(t.Assignees))
+		for _, a := range t.Assignees {
+			if u, has := users[a.ID]; has {
+				assignees = append(assignees, u)
+			}
+		}
+		t.Assignees = assignees
+	}
+
+	for _, cp := range p.ChildProjects {
+		remapMembersInProjectAndChildren(cp, users)
+	}
+}
@@ -55,7 +55,7 @@ func (v *FileMigrator) Name() string {
 
 // Migrate takes a vikunja file export, parses it and imports everything in it into Vikunja.
 // @Summary Import all projects, tasks etc. from a Vikunja data export
-// @Description Imports all projects, tasks, notes, reminders, subtasks and files from a Vikunjda data export into Vikunja.
+// @Description Imports all projects, tasks, notes, reminders, subtasks and files from a Vikunjda data export or a project export into Vikunja. Users and teams of a project export are matched with the users and teams of this instance by their username or email and the name of the team. Users and teams which could not be found show up in the migration report.
 // @tags migration
 // @Accept x-www-form-urlencoded
 // @Produce json
@@ -75,6 +75,7 @@ func (v *FileMigrator) Migrate(user *user.User, file io.ReaderAt, size int64) er
 	var dataFile *zip.File
 	var filterFile *zip.File
 	var versionFile *zip.File
+	var membersFile *zip.File
 	storedFiles := make(map[int64]*zip.File)
 	for _, f := range r.File {
 		if strings.HasPrefix(f.Name, "files/") {
@@ -96,6 +97,10 @@ func (v *FileMigrator) Migrate(user *user.User, file io.ReaderAt, size int64) er
 			filterFile = f
 			log.Debugf(logPrefix + "Found a filter file")
 		}
+		if f.Name == models.ProjectTreeMembersFileName {
+			membersFile = f
+			log.Debugf(logPrefix + "Found a members file")
+		}
 		if f.Name == "VERSION" {
 			versionFile = f
 			log.Debugf(logPrefix + "Found a version file")
@@ -166,6 +171,17 @@ func (v *FileMigrator) Migrate(user *user.User, file io.ReaderAt, size int64) er
 		}
 	}
 
+	if membersFile != nil {
+		members, err := readMembersFile(membersFile)
+		if err != nil {
+			return err
+		}
+		err = remapMembers(user, projects, members)
+		if err != nil {
+			return fmt.Errorf("could not map users and teams: %w", err)
+		}
+	}
+
 	err = migration.InsertFromStructure(projects, user)
 	if err != nil {
 		return fmt.Errorf("could not insert data: %w", err)
@@ -211,6 +227,21 @@ func (v *FileMigrator) Migrate(user *user.User, file io.ReaderAt, size int64) er
 	return s.Commit()
 }
 
+func readMembersFile(membersFile *zip.File) (members *models.ProjectTreeMembers, err error) {
+	mf, err := membersFile.Open()
+	if err != nil {
+		return nil, fmt.Errorf("could not open members file: %w", err)
+	}
+	defer mf.Close()
+
+	members = &models.ProjectTreeMembers{}
+	if err := json.NewDecoder(mf).Decode(members); err != nil {
+		return nil, fmt.Errorf("could not read members data: %w", err)
+	}
+
+	return members, nil
+}
+
 func addDetailsToProjectAndChildren(p *models.ProjectWithTasksAndBuckets, storedFiles map[int64]*zip.File) (err error) {
 	err = addDetailsToProject(p, storedFiles)
 	if err != nil {
@@ -249,7 +280,11 @@ func addDetailsToProject(l *models.ProjectWithTasksAndBuckets, storedFiles map[i
 		l.BackgroundInformation = &buf
 	}
 
+	// The ids in the export are the ids the projects and tasks had in the Vikunja instance they were exported from
+	l.SourceID = strconv.FormatInt(l.ID, 10)
+
 	for _, t := range l.Tasks {
+		t.SourceID = strconv.FormatInt(t.ID, 10)
 		for _, label := range t.Labels {
 			label.ID = 0
 		}
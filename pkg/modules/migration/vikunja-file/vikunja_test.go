@@ -17,16 +17,40 @@
 package vikunjafile
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
 	"os"
 	"testing"
 
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
 	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/version"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+func createTestExport(t *testing.T, contents map[string]interface{}) *bytes.Reader {
+	buf := &bytes.Buffer{}
+	wr := zip.NewWriter(buf)
+	for name, content := range contents {
+		f, err := wr.Create(name)
+		require.NoError(t, err)
+		if str, is := content.(string); is {
+			_, err = f.Write([]byte(str))
+		} else {
+			err = json.NewEncoder(f).Encode(content)
+		}
+		require.NoError(t, err)
+	}
+	require.NoError(t, wr.Close())
+	return bytes.NewReader(buf.Bytes())
+}
+
 func TestVikunjaFileMigrator_Migrate(t *testing.T) {
 	t.Run("migrate successfully", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
@@ -75,6 +99,105 @@ func TestVikunjaFileMigrator_Migrate(t *testing.T) {
 			"created_by_id": u.ID,
 		}, false)
 	})
+	t.Run("project export with users and teams", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+
+		m := &FileMigrator{}
+		u := &user.User{ID: 1}
+
+		projects := []*models.ProjectWithTasksAndBuckets{
+			{
+				Project: models.Project{ID: 100, Title: "Exported project"},
+				Tasks: []*models.TaskWithComments{
+					{
+						Task: models.Task{
+							ID:        1000,
+							Title:     "Exported task",
+							CreatedBy: &user.User{ID: 500},
+							Assignees: []*user.User{{ID: 500}, {ID: 501}},
+						},
+						Comments: []*models.TaskComment{
+							{Comment: "Exported comment", Author: &user.User{ID: 502}},
+						},
+					},
+				},
+			},
+			{
+				Project: models.Project{ID: 101, Title: "Exported child project", ParentProjectID: 100},
+				Tasks: []*models.TaskWithComments{
+					{
+						Task: models.Task{
+							ID:        1001,
+							Title:     "Exported child task",
+							CreatedBy: &user.User{ID: 501},
+							Assignees: []*user.User{{ID: 500}},
+						},
+					},
+				},
+			},
+		}
+		members := &models.ProjectTreeMembers{
+			Users: []*models.ExportedUser{
+				{ID: 500, Username: "user2"},
+				{ID: 501, Username: "unknown"},
+				{ID: 502, Username: "user3"},
+			},
+			Teams: []*models.ExportedTeam{
+				{ID: 600, Name: "testteam1"},
+			},
+			Shares: []*models.ExportedShare{
+				{ProjectID: 100, UserID: 500, Right: models.RightWrite},
+				{ProjectID: 100, TeamID: 600, Right: models.RightRead},
+			},
+		}
+		f := createTestExport(t, map[string]interface{}{
+			"data.json":                       projects,
+			models.ProjectTreeMembersFileName: members,
+			"VERSION":                         version.Version,
+		})
+
+		status, err := migration.StartMigration(m, u)
+		require.NoError(t, err)
+		err = m.Migrate(u, f, f.Size())
+		require.NoError(t, err)
+		err = migration.FinishMigration(status)
+		require.NoError(t, err)
+
+		project := &models.Project{}
+		has, err := db.NewSession().Where("title = ?", "Exported project").Get(project)
+		require.NoError(t, err)
+		require.True(t, has)
+
+		// Shares are only reported, never created from the file
+		db.AssertMissing(t, "users_projects", map[string]interface{}{
+			"project_id": project.ID,
+		})
+		db.AssertMissing(t, "team_projects", map[string]interface{}{
+			"project_id": project.ID,
+		})
+		// Everything is created by the importing user
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"title":         "Exported task",
+			"created_by_id": u.ID,
+		}, false)
+		db.AssertExists(t, "tasks", map[string]interface{}{
+			"title":         "Exported child task",
+			"created_by_id": u.ID,
+		}, false)
+		db.AssertExists(t, "task_comments", map[string]interface{}{
+			"comment":   "Exported comment",
+			"author_id": u.ID,
+		}, false)
+
+		report, err := migration.GetMigrationReport(m, u)
+		require.NoError(t, err)
+		kinds := map[string][]string{}
+		for _, item := range report {
+			kinds[item.Kind] = append(kinds[item.Kind], item.SourceID)
+		}
+		assert.Equal(t, []string{"501"}, kinds[migration.ItemKindUser])
+		assert.ElementsMatch(t, []string{"user-500", "team-600"}, kinds[migration.ItemKindShare])
+	})
 	t.Run("should not accept an old import", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
 
@@ -0,0 +1,455 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package wekan
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/files"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/migration"
+	"code.vikunja.io/api/pkg/user"
+)
+
+const logPrefix = "[Wekan Migration] "
+
+// FileMigrator imports a board from a Wekan json export.
+type FileMigrator struct {
+}
+
+type wekanBoard struct {
+	ID             string                `json:"_id"`
+	Title          string                `json:"title"`
+	Description    string                `json:"description"`
+	Archived       bool                  `json:"archived"`
+	Lists          []*wekanList          `json:"lists"`
+	Cards          []*wekanCard          `json:"cards"`
+	Labels         []*wekanLabel         `json:"labels"`
+	Checklists     []*wekanChecklist     `json:"checklists"`
+	ChecklistItems []*wekanChecklistItem `json:"checklistItems"`
+	Comments       []*wekanComment       `json:"comments"`
+	CustomFields   []*wekanCustomField   `json:"customFields"`
+	Users          []*wekanUser          `json:"users"`
+	Attachments    []*wekanAttachment    `json:"attachments"`
+}
+
+type wekanList struct {
+	ID    string  `json:"_id"`
+	Title string  `json:"title"`
+	Sort  float64 `json:"sort"`
+}
+
+type wekanCard struct {
+	ID           string     `json:"_id"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	ListID       string     `json:"listId"`
+	ParentID     string     `json:"parentId"`
+	Sort         float64    `json:"sort"`
+	Archived     bool       `json:"archived"`
+	DueAt        *time.Time `json:"dueAt"`
+	StartAt      *time.Time `json:"startAt"`
+	EndAt        *time.Time `json:"endAt"`
+	LabelIDs     []string   `json:"labelIds"`
+	CustomFields []struct {
+		ID    string          `json:"_id"`
+		Value json.RawMessage `json:"value"`
+	} `json:"customFields"`
+}
+
+type wekanLabel struct {
+	ID    string `json:"_id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type wekanChecklist struct {
+	ID     string  `json:"_id"`
+	CardID string  `json:"cardId"`
+	Title  string  `json:"title"`
+	Sort   float64 `json:"sort"`
+}
+
+type wekanChecklistItem struct {
+	ChecklistID string  `json:"checklistId"`
+	Title       string  `json:"title"`
+	Sort        float64 `json:"sort"`
+	IsFinished  bool    `json:"isFinished"`
+}
+
+type wekanComment struct {
+	CardID     string     `json:"cardId"`
+	Text       string     `json:"text"`
+	UserID     string     `json:"userId"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ModifiedAt *time.Time `json:"modifiedAt"`
+}
+
+type wekanCustomField struct {
+	ID   string `json:"_id"`
+	Name string `json:"name"`
+}
+
+type wekanUser struct {
+	ID       string `json:"_id"`
+	Username string `json:"username"`
+	Profile  struct {
+		Fullname string `json:"fullname"`
+	} `json:"profile"`
+}
+
+type wekanAttachment struct {
+	CardID string `json:"cardId"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	// The file content, base64 encoded
+	File string `json:"file"`
+}
+
+var wekanColorMap = map[string]string{
+	"white":         "ffffff",
+	"green":         "3cb500",
+	"yellow":        "fad900",
+	"orange":        "ff9f19",
+	"red":           "eb4646",
+	"purple":        "a632db",
+	"blue":          "0079bf",
+	"sky":           "00c2e0",
+	"lime":          "51e898",
+	"pink":          "ff78cb",
+	"black":         "4d4d4d",
+	"silver":        "c0c0c0",
+	"peachpuff":     "ffdab9",
+	"crimson":       "dc143c",
+	"plum":          "dda0dd",
+	"darkgreen":     "006400",
+	"slateblue":     "6a5acd",
+	"magenta":       "ff00ff",
+	"gold":          "ffd700",
+	"navy":          "000080",
+	"gray":          "808080",
+	"saddlebrown":   "8b4513",
+	"paleturquoise": "afeeee",
+	"mistyrose":     "ffe4e1",
+	"indigo":        "4b0082",
+}
+
+// Name is used to get the name of the wekan migration - we're using the docs here to annotate the status route.
+// @Summary Get migration status
+// @Description Returns if the current user already did the migation or not. This is useful to show a confirmation message in the frontend if the user is trying to do the same migration again.
+// @tags migration
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} migration.Status "The migration status"
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/wekan/status [get]
+func (m *FileMigrator) Name() string {
+	return "wekan"
+}
+
+// Migrate takes a wekan board export, parses it and imports everything in it into Vikunja.
+// @Summary Import a board from a Wekan export
+// @Description Imports a board with all its lists, cards, subtasks, labels, checklists, comments, attachments and custom fields from a Wekan json export into Vikunja. Lists become kanban buckets, custom fields are added to the task description.
+// @tags migration
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Security JWTKeyAuth
+// @Param import formData string true "The Wekan board json export."
+// @Success 200 {object} models.Message "A message telling you everything was migrated successfully."
+// @Failure 400 {object} web.HTTPError "The file could not be parsed."
+// @Failure 500 {object} models.Message "Internal server error"
+// @Router /migration/wekan/migrate [post]
+func (m *FileMigrator) Migrate(u *user.User, file io.ReaderAt, size int64) error {
+	content, err := io.ReadAll(io.NewSectionReader(file, 0, size))
+	if err != nil {
+		return err
+	}
+
+	board, err := parseExport(content)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf(logPrefix+"Importing board with %d cards for user %d", len(board.Cards), u.ID)
+
+	structure, err := convertWekanBoardToVikunja(board)
+	if err != nil {
+		return err
+	}
+	return migration.InsertFromStructure(structure, u)
+}
+
+func parseExport(content []byte) (*wekanBoard, error) {
+	board := &wekanBoard{}
+	err := json.Unmarshal(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")), board)
+	if err != nil {
+		return nil, &models.ErrInvalidImportFile{Reason: "could not parse the json: " + err.Error()}
+	}
+	if board.Title == "" {
+		return nil, &models.ErrInvalidImportFile{Reason: "the file is not a Wekan board export"}
+	}
+	return board, nil
+}
+
+func customFieldValue(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return strings.Join(values, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// convertWekanBoardToVikunja creates a project for the board below a "Migrated from Wekan" project. Lists become
+// buckets, subtasks are related to their parent card.
+func convertWekanBoardToVikunja(board *wekanBoard) (result []*models.ProjectWithTasksAndBuckets, err error) {
+	var pseudoParentID int64 = 1
+	project := &models.ProjectWithTasksAndBuckets{
+		Project: models.Project{
+			ID:              pseudoParentID + 1,
+			ParentProjectID: pseudoParentID,
+			Title:           board.Title,
+			IsArchived:      board.Archived,
+		},
+		SourceID: board.ID,
+	}
+	project.Description, err = migration.ConvertMarkdownToHTML(board.Description)
+	if err != nil {
+		return nil, err
+	}
+	result = []*models.ProjectWithTasksAndBuckets{
+		{
+			Project: models.Project{
+				ID:    pseudoParentID,
+				Title: "Migrated from Wekan",
+			},
+			SourceID: migration.RootProjectSourceID,
+		},
+		project,
+	}
+
+	sort.SliceStable(board.Lists, func(i, j int) bool {
+		return board.Lists[i].Sort < board.Lists[j].Sort
+	})
+	bucketIDs := make(map[string]int64, len(board.Lists))
+	for index, l := range board.Lists {
+		bucketIDs[l.ID] = int64(index + 1)
+		project.Buckets = append(project.Buckets, &models.Bucket{
+			ID:    int64(index + 1),
+			Title: l.Title,
+		})
+	}
+
+	labels := make(map[string]*models.Label, len(board.Labels))
+	for _, l := range board.Labels {
+		title := l.Name
+		if title == "" {
+			title = l.Color
+		}
+		labels[l.ID] = &models.Label{
+			Title:    title,
+			HexColor: wekanColorMap[l.Color],
+		}
+	}
+
+	customFieldNames := make(map[string]string, len(board.CustomFields))
+	for _, f := range board.CustomFields {
+		customFieldNames[f.ID] = f.Name
+	}
+
+	usernames := make(map[string]string, len(board.Users))
+	for _, u := range board.Users {
+		usernames[u.ID] = u.Username
+		if u.Profile.Fullname != "" {
+			usernames[u.ID] = u.Profile.Fullname
+		}
+	}
+
+	checklistsByCard := make(map[string][]*wekanChecklist)
+	for _, c := range board.Checklists {
+		checklistsByCard[c.CardID] = append(checklistsByCard[c.CardID], c)
+	}
+	itemsByChecklist := make(map[string][]*wekanChecklistItem)
+	for _, item := range board.ChecklistItems {
+		itemsByChecklist[item.ChecklistID] = append(itemsByChecklist[item.ChecklistID], item)
+	}
+
+	commentsByCard := make(map[string][]*wekanComment)
+	for _, c := range board.Comments {
+		commentsByCard[c.CardID] = append(commentsByCard[c.CardID], c)
+	}
+
+	attachmentsByCard := make(map[string][]*wekanAttachment)
+	for _, a := range board.Attachments {
+		attachmentsByCard[a.CardID] = append(attachmentsByCard[a.CardID], a)
+	}
+
+	sort.SliceStable(board.Cards, func(i, j int) bool {
+		return board.Cards[i].Sort < board.Cards[j].Sort
+	})
+	idsByCardID := make(map[string]int64, len(board.Cards))
+	for index, card := range board.Cards {
+		idsByCardID[card.ID] = int64(index + 1)
+	}
+
+	tasks := make(map[int64]*models.TaskWithComments, len(board.Cards))
+	for index, card := range board.Cards {
+		task := &models.TaskWithComments{
+			Task: models.Task{
+				ID:       int64(index + 1),
+				Title:    card.Title,
+				Done:     card.Archived,
+				BucketID: bucketIDs[card.ListID],
+			},
+			SourceID: card.ID,
+		}
+
+		task.Description, err = migration.ConvertMarkdownToHTML(card.Description)
+		if err != nil {
+			return nil, err
+		}
+		customFields := make([]*migration.CustomField, 0, len(card.CustomFields))
+		for _, f := range card.CustomFields {
+			name, has := customFieldNames[f.ID]
+			if !has {
+				continue
+			}
+			customFields = append(customFields, &migration.CustomField{Name: name, Value: customFieldValue(f.Value)})
+		}
+		task.Description += migration.CustomFieldsToHTML(customFields)
+
+		if card.DueAt != nil {
+			task.DueDate = *card.DueAt
+		}
+		if card.StartAt != nil {
+			task.StartDate = *card.StartAt
+		}
+		if card.EndAt != nil {
+			task.EndDate = *card.EndAt
+		}
+
+		for _, labelID := range card.LabelIDs {
+			if label, has := labels[labelID]; has {
+				task.Labels = append(task.Labels, &models.Label{
+					Title:    label.Title,
+					HexColor: label.HexColor,
+				})
+			}
+		}
+
+		// Vikunja tasks only have one checklist, so we're prefixing the items with the name of their
+		// checklist if the card has more than one.
+		checklists := checklistsByCard[card.ID]
+		sort.SliceStable(checklists, func(i, j int) bool {
+			return checklists[i].Sort < checklists[j].Sort
+		})
+		for _, checklist := range checklists {
+			items := itemsByChecklist[checklist.ID]
+			sort.SliceStable(items, func(i, j int) bool {
+				return items[i].Sort < items[j].Sort
+			})
+			for _, item := range items {
+				title := item.Title
+				if len(checklists) > 1 {
+					title = checklist.Title + ": " + item.Title
+				}
+				task.ChecklistItems = append(task.ChecklistItems, &models.TaskChecklistItem{
+					Title: title,
+					Done:  item.IsFinished,
+				})
+			}
+		}
+
+		for _, c := range commentsByCard[card.ID] {
+			comment := &models.TaskComment{
+				Comment: c.Text,
+				Created: c.CreatedAt,
+				Updated: c.CreatedAt,
+			}
+			if c.ModifiedAt != nil {
+				comment.Updated = *c.ModifiedAt
+			}
+			if author, has := usernames[c.UserID]; has {
+				comment.Comment = "*" + author + "*:\n\n" + comment.Comment
+			}
+			comment.Comment, err = migration.ConvertMarkdownToHTML(comment.Comment)
+			if err != nil {
+				return nil, err
+			}
+			task.Comments = append(task.Comments, comment)
+		}
+
+		for _, a := range attachmentsByCard[card.ID] {
+			content, err := base64.StdEncoding.DecodeString(a.File)
+			if err != nil || len(content) == 0 {
+				log.Debugf(logPrefix+"Attachment %s of card %s does not contain a file, skipping", a.Name, card.ID)
+				continue
+			}
+			task.Attachments = append(task.Attachments, &models.TaskAttachment{
+				File: &files.File{
+					Name:        a.Name,
+					Mime:        a.Type,
+					Size:        uint64(len(content)),
+					FileContent: content,
+				},
+			})
+		}
+
+		tasks[task.ID] = task
+		project.Tasks = append(project.Tasks, task)
+	}
+
+	for _, card := range board.Cards {
+		if card.ParentID == "" {
+			continue
+		}
+		parentID, has := idsByCardID[card.ParentID]
+		id := idsByCardID[card.ID]
+		if !has || parentID == id {
+			log.Debugf(logPrefix+"Could not find parent %s of card %s", card.ParentID, card.ID)
+			continue
+		}
+		parent := tasks[parentID]
+		if parent.RelatedTasks == nil {
+			parent.RelatedTasks = make(models.RelatedTaskMap)
+		}
+		parent.RelatedTasks[models.RelationKindSubtask] = append(parent.RelatedTasks[models.RelationKindSubtask], &models.Task{ID: id})
+	}
+
+	return
+}
@@ -0,0 +1,139 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package wekan
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertWekanBoard(t *testing.T) {
+	content, err := os.ReadFile("export.json")
+	require.NoError(t, err)
+	board, err := parseExport(content)
+	require.NoError(t, err)
+
+	structure, err := convertWekanBoardToVikunja(board)
+	require.NoError(t, err)
+	require.Len(t, structure, 2)
+	assert.Equal(t, "Migrated from Wekan", structure[0].Title)
+
+	project := structure[1]
+	assert.Equal(t, "Home Renovation", project.Title)
+	assert.Equal(t, "bXy7dQWaAhh8rB5hG", project.SourceID)
+	assert.Equal(t, "<p>Everything for the <strong>new</strong> kitchen</p>\n", project.Description)
+	assert.Equal(t, structure[0].ID, project.ParentProjectID)
+	require.Len(t, project.Buckets, 3)
+	assert.Equal(t, "To Do", project.Buckets[0].Title)
+	assert.Equal(t, "Doing", project.Buckets[1].Title)
+	assert.Equal(t, "Done", project.Buckets[2].Title)
+	require.Len(t, project.Tasks, 3)
+
+	cabinets := project.Tasks[0]
+	assert.Equal(t, "Choose cabinets", cabinets.Title)
+	assert.Equal(t, "C1", cabinets.SourceID)
+	assert.Equal(t, project.Buckets[0].ID, cabinets.BucketID)
+	assert.Equal(t, "<p>Compare <em>at least</em> three offers</p>\n<ul><li><strong>Budget</strong>: 2500</li><li><strong>Suppliers</strong>: IKEA, Local carpenter</li></ul>", cabinets.Description)
+	assert.Equal(t, time.Date(2024, 6, 30, 18, 0, 0, 0, time.UTC), cabinets.DueDate)
+	assert.Equal(t, time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC), cabinets.StartDate)
+	require.Len(t, cabinets.Labels, 2)
+	assert.Equal(t, "Urgent", cabinets.Labels[0].Title)
+	assert.Equal(t, "eb4646", cabinets.Labels[0].HexColor)
+	assert.Equal(t, "green", cabinets.Labels[1].Title)
+	require.Len(t, cabinets.ChecklistItems, 3)
+	assert.Equal(t, "Buy: Cabinets", cabinets.ChecklistItems[0].Title)
+	assert.True(t, cabinets.ChecklistItems[0].Done)
+	assert.Equal(t, "Buy: Handles", cabinets.ChecklistItems[1].Title)
+	assert.Equal(t, "Install: Mount cabinets", cabinets.ChecklistItems[2].Title)
+	require.Len(t, cabinets.Comments, 1)
+	assert.Equal(t, "<p><em>Alex Builder</em>:</p>\n<p>The <strong>white</strong> ones look best</p>\n", cabinets.Comments[0].Comment)
+	assert.Equal(t, time.Date(2024, 5, 5, 10, 0, 0, 0, time.UTC), cabinets.Comments[0].Created)
+	assert.Equal(t, time.Date(2024, 5, 5, 10, 30, 0, 0, time.UTC), cabinets.Comments[0].Updated)
+	require.Len(t, cabinets.Attachments, 1)
+	assert.Equal(t, "floorplan.txt", cabinets.Attachments[0].File.Name)
+	assert.Equal(t, "Kitchen plan", string(cabinets.Attachments[0].File.FileContent))
+	assert.Equal(t, models.RelatedTaskMap{
+		models.RelationKindSubtask: {{ID: 2}},
+	}, cabinets.RelatedTasks)
+
+	walls := project.Tasks[1]
+	assert.Equal(t, project.Buckets[1].ID, walls.BucketID)
+	assert.Equal(t, time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC), walls.EndDate)
+
+	tiles := project.Tasks[2]
+	assert.True(t, tiles.Done)
+	assert.Equal(t, project.Buckets[2].ID, tiles.BucketID)
+}
+
+func TestParseExport(t *testing.T) {
+	_, err := parseExport([]byte(`{"cards": []}`))
+	require.Error(t, err)
+	assert.True(t, models.IsErrInvalidImportFile(err))
+}
+
+func TestFileMigrator_Migrate(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+
+	u := &user.User{ID: 1}
+	content, err := os.ReadFile("export.json")
+	require.NoError(t, err)
+	m := &FileMigrator{}
+	err = m.Migrate(u, strings.NewReader(string(content)), int64(len(content)))
+	require.NoError(t, err)
+
+	s := db.NewSession()
+	defer s.Close()
+
+	cabinets := &models.Task{}
+	has, err := s.Where("title = ?", "Choose cabinets").Get(cabinets)
+	require.NoError(t, err)
+	require.True(t, has)
+	walls := &models.Task{}
+	has, err = s.Where("title = ?", "Measure the walls").Get(walls)
+	require.NoError(t, err)
+	require.True(t, has)
+
+	db.AssertExists(t, "task_relations", map[string]interface{}{
+		"task_id":       cabinets.ID,
+		"other_task_id": walls.ID,
+		"relation_kind": models.RelationKindSubtask,
+	}, false)
+	db.AssertExists(t, "task_checklist_items", map[string]interface{}{
+		"task_id": cabinets.ID,
+		"title":   "Buy: Cabinets",
+		"done":    true,
+	}, false)
+	db.AssertExists(t, "task_attachments", map[string]interface{}{
+		"task_id": cabinets.ID,
+	}, false)
+	db.AssertExists(t, "labels", map[string]interface{}{
+		"title":     "Urgent",
+		"hex_color": "eb4646",
+	}, false)
+	db.AssertExists(t, "buckets", map[string]interface{}{
+		"title": "Doing",
+	}, false)
+}
@@ -0,0 +1,225 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/api/pkg/utils"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// Member is a member of a group.
+type Member struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// Group is the scim representation of a Vikunja team.
+type Group struct {
+	Schemas     []string  `json:"schemas"`
+	ID          string    `json:"id,omitempty"`
+	ExternalID  string    `json:"externalId,omitempty"`
+	DisplayName string    `json:"displayName"`
+	Members     []*Member `json:"members"`
+	Meta        *Meta     `json:"meta,omitempty"`
+}
+
+// groupFilterColumns maps the attributes groups can be filtered by to their database columns.
+var groupFilterColumns = map[string]string{
+	"id":          "id",
+	"displayname": "name",
+	"externalid":  "oidc_id",
+}
+
+func convertTeam(t *models.Team) *Group {
+	members := make([]*Member, 0, len(t.Members))
+	for _, m := range t.Members {
+		members = append(members, &Member{
+			Value:   strconv.FormatInt(m.ID, 10),
+			Display: m.Username,
+		})
+	}
+
+	return &Group{
+		Schemas:     []string{SchemaGroup},
+		ID:          strconv.FormatInt(t.ID, 10),
+		ExternalID:  t.OidcID,
+		DisplayName: t.Name,
+		Members:     members,
+		Meta: &Meta{
+			ResourceType: "Group",
+			Created:      t.Created,
+			LastModified: t.Updated,
+		},
+	}
+}
+
+// getTeam returns a team managed via scim. Teams created by users or other issuers can't be changed via scim.
+func getTeam(s *xorm.Session, rawID string) (*models.Team, error) {
+	id, err := parseID(rawID)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := models.GetTeamByID(s, id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Issuer != Issuer() {
+		return nil, models.ErrTeamDoesNotExist{TeamID: id}
+	}
+	return t, nil
+}
+
+func listTeams(s *xorm.Session, f *filter, startIndex, count int) (teams []*models.Team, total int64, err error) {
+	cond := builder.And(builder.Eq{"issuer": Issuer()})
+	if f != nil {
+		column, supported := groupFilterColumns[strings.ToLower(f.attribute)]
+		if !supported {
+			return nil, 0, newError(http.StatusBadRequest, "invalidFilter", "Groups can only be filtered by id, displayName or externalId.")
+		}
+		cond = builder.And(cond, builder.Eq{column: f.value})
+	}
+
+	if count == 0 {
+		total, err = s.Where(cond).Count(&models.Team{})
+		return []*models.Team{}, total, err
+	}
+
+	ids := []int64{}
+	total, err = s.
+		Table("teams").
+		Where(cond).
+		OrderBy("id").
+		Limit(count, startIndex-1).
+		Cols("id").
+		FindAndCount(&ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	teams = make([]*models.Team, 0, len(ids))
+	for _, id := range ids {
+		t, err := models.GetTeamByID(s, id)
+		if err != nil {
+			return nil, 0, err
+		}
+		teams = append(teams, t)
+	}
+
+	return teams, total, nil
+}
+
+// setMembers makes the members of a group the only members of its team.
+func setMembers(s *xorm.Session, t *models.Team, members []*Member) error {
+	userIDs := make([]int64, 0, len(members))
+	for _, m := range members {
+		id, err := strconv.ParseInt(m.Value, 10, 64)
+		if err != nil {
+			return newError(http.StatusBadRequest, "invalidValue", fmt.Sprintf("The member '%s' does not exist.", m.Value))
+		}
+		userIDs = append(userIDs, id)
+	}
+
+	currentIDs := []int64{}
+	err := s.
+		Table("team_members").
+		Where("team_id = ?", t.ID).
+		Cols("user_id").
+		Find(&currentIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range utils.NotIn(userIDs, currentIDs) {
+		u, err := user.GetUserByID(s, id)
+		if err != nil {
+			if user.IsErrUserDoesNotExist(err) {
+				return newError(http.StatusBadRequest, "invalidValue", fmt.Sprintf("The member '%d' does not exist.", id))
+			}
+			return err
+		}
+
+		// The member is the doer so they don't get a notification about being added to the team
+		tm := &models.TeamMember{TeamID: t.ID, Username: u.Username}
+		err = tm.Create(s, u)
+		if err != nil && !models.IsErrUserIsMemberOfTeam(err) {
+			return err
+		}
+	}
+
+	toRemove := utils.NotIn(currentIDs, userIDs)
+	if len(toRemove) == 0 {
+		return nil
+	}
+
+	_, err = s.
+		Where("team_id = ?", t.ID).
+		In("user_id", toRemove).
+		Delete(&models.TeamMember{})
+	return err
+}
+
+func createTeam(s *xorm.Session, g *Group) (*models.Team, error) {
+	t := &models.Team{
+		Name:   g.DisplayName,
+		OidcID: g.ExternalID,
+		Issuer: Issuer(),
+	}
+	err := models.CreateExternalTeam(s, t)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setMembers(s, t, g.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.GetTeamByID(s, t.ID)
+}
+
+func replaceTeam(s *xorm.Session, t *models.Team, g *Group) (*models.Team, error) {
+	if g.DisplayName == "" {
+		return nil, models.ErrTeamNameCannotBeEmpty{}
+	}
+
+	t.Name = g.DisplayName
+	t.OidcID = g.ExternalID
+	_, err := s.
+		ID(t.ID).
+		Cols("name", "oidc_id").
+		Update(t)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setMembers(s, t, g.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.GetTeamByID(s, t.ID)
+}
@@ -0,0 +1,360 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package scim
+
+import (
+	"net/http"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/labstack/echo/v4"
+	"xorm.io/xorm"
+)
+
+// HandleAuthError returns a scim error when the bearer token of a request is missing or invalid.
+func HandleAuthError(_ error, c echo.Context) error {
+	return respond(c, http.StatusUnauthorized, newError(http.StatusUnauthorized, "", "A valid bearer token is required."))
+}
+
+func commitUser(c echo.Context, s *xorm.Session, u *user.User, code int) error {
+	su, err := convertUser(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	return respond(c, code, su)
+}
+
+func commitTeam(c echo.Context, s *xorm.Session, t *models.Team, code int) error {
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	return respond(c, code, convertTeam(t))
+}
+
+// GetUsers returns all users managed via scim, optionally filtered.
+func GetUsers(c echo.Context) error {
+	f, err := parseFilter(c.QueryParam("filter"))
+	if err != nil {
+		return handleError(c, err)
+	}
+	startIndex, count := getPagination(c)
+
+	s := db.NewSession()
+	defer s.Close()
+
+	users, total, err := listUsers(s, f, startIndex, count)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	resources := make([]interface{}, 0, len(users))
+	for _, u := range users {
+		su, err := convertUser(s, u)
+		if err != nil {
+			return handleError(c, err)
+		}
+		resources = append(resources, su)
+	}
+
+	return respond(c, http.StatusOK, &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// GetUser returns one user managed via scim.
+func GetUser(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := getUser(s, c.Param("id"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	su, err := convertUser(s, u)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return respond(c, http.StatusOK, su)
+}
+
+// CreateUser creates a new user.
+func CreateUser(c echo.Context) error {
+	su := &User{}
+	if err := bind(c, su); err != nil {
+		return handleError(c, err)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := createUser(s, su)
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	return commitUser(c, s, u, http.StatusCreated)
+}
+
+// ReplaceUser replaces all attributes of a user.
+func ReplaceUser(c echo.Context) error {
+	su := &User{}
+	if err := bind(c, su); err != nil {
+		return handleError(c, err)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := getUser(s, c.Param("id"))
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	u, err = replaceUser(s, u, su)
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	return commitUser(c, s, u, http.StatusOK)
+}
+
+// PatchUser changes some attributes of a user. This is mostly used to deactivate users.
+func PatchUser(c echo.Context) error {
+	patch := &PatchRequest{}
+	if err := bind(c, patch); err != nil {
+		return handleError(c, err)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := getUser(s, c.Param("id"))
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	current, err := convertUser(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	patched := &User{}
+	err = applyPatch(current, patch.Operations, patched)
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	u, err = replaceUser(s, u, patched)
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	return commitUser(c, s, u, http.StatusOK)
+}
+
+// DeleteUser deletes a user and all their data.
+func DeleteUser(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := getUser(s, c.Param("id"))
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	err = models.DeleteUser(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// GetGroups returns all teams managed via scim, optionally filtered.
+func GetGroups(c echo.Context) error {
+	f, err := parseFilter(c.QueryParam("filter"))
+	if err != nil {
+		return handleError(c, err)
+	}
+	startIndex, count := getPagination(c)
+
+	s := db.NewSession()
+	defer s.Close()
+
+	teams, total, err := listTeams(s, f, startIndex, count)
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	resources := make([]interface{}, 0, len(teams))
+	for _, t := range teams {
+		resources = append(resources, convertTeam(t))
+	}
+
+	return respond(c, http.StatusOK, &ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// GetGroup returns one team managed via scim.
+func GetGroup(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	t, err := getTeam(s, c.Param("id"))
+	if err != nil {
+		return handleError(c, err)
+	}
+
+	return respond(c, http.StatusOK, convertTeam(t))
+}
+
+// CreateGroup creates a new team with the members of the group.
+func CreateGroup(c echo.Context) error {
+	g := &Group{}
+	if err := bind(c, g); err != nil {
+		return handleError(c, err)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	t, err := createTeam(s, g)
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	return commitTeam(c, s, t, http.StatusCreated)
+}
+
+// ReplaceGroup replaces the name and all members of a team.
+func ReplaceGroup(c echo.Context) error {
+	g := &Group{}
+	if err := bind(c, g); err != nil {
+		return handleError(c, err)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	t, err := getTeam(s, c.Param("id"))
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	t, err = replaceTeam(s, t, g)
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	return commitTeam(c, s, t, http.StatusOK)
+}
+
+// PatchGroup changes the name of a team or adds and removes members.
+func PatchGroup(c echo.Context) error {
+	patch := &PatchRequest{}
+	if err := bind(c, patch); err != nil {
+		return handleError(c, err)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	t, err := getTeam(s, c.Param("id"))
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	patched := &Group{}
+	err = applyPatch(convertTeam(t), patch.Operations, patched)
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	t, err = replaceTeam(s, t, patched)
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	return commitTeam(c, s, t, http.StatusOK)
+}
+
+// DeleteGroup deletes a team.
+func DeleteGroup(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	t, err := getTeam(s, c.Param("id"))
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	err = t.Delete(s, nil)
+	if err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handleError(c, err)
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,274 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// applyPatch applies all operations of a patch request to a resource. The resource is modified in place.
+// Only the subset of RFC 7644 section 3.5.2 identity providers use in practice is supported: attribute paths,
+// sub-attributes and equality filters on multi-valued attributes.
+func applyPatch(resource interface{}, operations []*PatchOperation, result interface{}) error {
+	raw, err := json.Marshal(resource)
+	if err != nil {
+		return err
+	}
+	attributes := map[string]interface{}{}
+	err = json.Unmarshal(raw, &attributes)
+	if err != nil {
+		return err
+	}
+
+	for _, operation := range operations {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return newError(http.StatusBadRequest, "invalidSyntax", fmt.Sprintf("Unsupported patch operation '%s'.", operation.Op))
+		}
+
+		var value interface{}
+		if len(operation.Value) > 0 {
+			err = json.Unmarshal(operation.Value, &value)
+			if err != nil {
+				return newError(http.StatusBadRequest, "invalidValue", "The value of a patch operation is not valid json.")
+			}
+		}
+
+		if operation.Path != "" {
+			err = applyOperation(attributes, op, operation.Path, value)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		// Without a path, the value contains the attributes to change
+		values, is := value.(map[string]interface{})
+		if !is || op == "remove" {
+			return newError(http.StatusBadRequest, "noTarget", "A patch operation without a path needs an object as value.")
+		}
+		for path, v := range values {
+			err = applyOperation(attributes, op, path, v)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	raw, err = json.Marshal(attributes)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(raw, result)
+	if err != nil {
+		return newError(http.StatusBadRequest, "invalidValue", "The patched resource is not valid: "+err.Error())
+	}
+	return nil
+}
+
+// path is a parsed patch path like emails[type eq "work"].value
+type path struct {
+	attribute    string
+	filter       *filter
+	subAttribute string
+}
+
+func parsePath(raw string) (*path, error) {
+	raw = trimSchema(strings.TrimSpace(raw))
+	p := &path{}
+
+	if start := strings.Index(raw, "["); start != -1 {
+		end := strings.LastIndex(raw, "]")
+		if end < start {
+			return nil, newError(http.StatusBadRequest, "invalidPath", fmt.Sprintf("The path '%s' is not valid.", raw))
+		}
+
+		f, err := parseFilter(raw[start+1 : end])
+		if err != nil {
+			return nil, err
+		}
+		p.attribute = raw[:start]
+		p.filter = f
+		p.subAttribute = strings.TrimPrefix(raw[end+1:], ".")
+		return p, nil
+	}
+
+	p.attribute, p.subAttribute, _ = strings.Cut(raw, ".")
+	if p.attribute == "" {
+		return nil, newError(http.StatusBadRequest, "invalidPath", fmt.Sprintf("The path '%s' is not valid.", raw))
+	}
+	return p, nil
+}
+
+// key returns the key of an attribute in a map, attribute names are case-insensitive.
+func key(attributes map[string]interface{}, name string) string {
+	for k := range attributes {
+		if strings.EqualFold(k, name) {
+			return k
+		}
+	}
+	return name
+}
+
+func matches(value interface{}, f *filter) bool {
+	element, is := value.(map[string]interface{})
+	if !is {
+		return false
+	}
+	v, exists := element[key(element, f.attribute)]
+	return exists && strings.EqualFold(fmt.Sprint(v), f.value)
+}
+
+// sameValue checks if two elements of a multi-valued attribute like members are the same by their value.
+func sameValue(a, b interface{}) bool {
+	am, aIsMap := a.(map[string]interface{})
+	bm, bIsMap := b.(map[string]interface{})
+	if aIsMap && bIsMap {
+		return fmt.Sprint(am["value"]) == fmt.Sprint(bm["value"])
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+func toSlice(value interface{}) []interface{} {
+	if value == nil {
+		return []interface{}{}
+	}
+	if values, is := value.([]interface{}); is {
+		return values
+	}
+	return []interface{}{value}
+}
+
+func applyOperation(attributes map[string]interface{}, op string, rawPath string, value interface{}) error {
+	p, err := parsePath(rawPath)
+	if err != nil {
+		return err
+	}
+	attribute := key(attributes, p.attribute)
+
+	if p.filter != nil {
+		return applyFilteredOperation(attributes, attribute, op, p, value)
+	}
+
+	if p.subAttribute != "" {
+		complexAttribute, is := attributes[attribute].(map[string]interface{})
+		if !is {
+			complexAttribute = map[string]interface{}{}
+		}
+		if op == "remove" {
+			delete(complexAttribute, key(complexAttribute, p.subAttribute))
+		} else {
+			complexAttribute[key(complexAttribute, p.subAttribute)] = value
+		}
+		attributes[attribute] = complexAttribute
+		return nil
+	}
+
+	existing, isMultiValued := attributes[attribute].([]interface{})
+
+	switch op {
+	case "remove":
+		if !isMultiValued || value == nil {
+			delete(attributes, attribute)
+			return nil
+		}
+		remaining := []interface{}{}
+		for _, e := range existing {
+			remove := false
+			for _, v := range toSlice(value) {
+				if sameValue(e, v) {
+					remove = true
+					break
+				}
+			}
+			if !remove {
+				remaining = append(remaining, e)
+			}
+		}
+		attributes[attribute] = remaining
+	case "add":
+		if _, isSlice := value.([]interface{}); !isMultiValued && !isSlice {
+			attributes[attribute] = value
+			return nil
+		}
+		for _, v := range toSlice(value) {
+			exists := false
+			for _, e := range existing {
+				if sameValue(e, v) {
+					exists = true
+					break
+				}
+			}
+			if !exists {
+				existing = append(existing, v)
+			}
+		}
+		attributes[attribute] = existing
+	case "replace":
+		attributes[attribute] = value
+	}
+
+	return nil
+}
+
+// applyFilteredOperation changes all elements of a multi-valued attribute which match the filter of the path.
+func applyFilteredOperation(attributes map[string]interface{}, attribute string, op string, p *path, value interface{}) error {
+	existing := toSlice(attributes[attribute])
+	result := make([]interface{}, 0, len(existing))
+	matched := false
+
+	for _, e := range existing {
+		if !matches(e, p.filter) {
+			result = append(result, e)
+			continue
+		}
+		matched = true
+
+		switch {
+		case op == "remove" && p.subAttribute == "":
+			// Leave the element out
+		case op == "remove":
+			element := e.(map[string]interface{})
+			delete(element, key(element, p.subAttribute))
+			result = append(result, element)
+		case p.subAttribute == "":
+			result = append(result, value)
+		default:
+			element := e.(map[string]interface{})
+			element[key(element, p.subAttribute)] = value
+			result = append(result, element)
+		}
+	}
+
+	// Setting a sub-attribute of an element which does not exist yet, like the work email, creates it
+	if !matched && op != "remove" {
+		if p.subAttribute == "" {
+			result = append(result, value)
+		} else {
+			result = append(result, map[string]interface{}{
+				p.filter.attribute: p.filter.value,
+				p.subAttribute:     value,
+			})
+		}
+	}
+
+	attributes[attribute] = result
+	return nil
+}
@@ -0,0 +1,139 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func patchOperations(t *testing.T, raw string) []*PatchOperation {
+	patch := &PatchRequest{}
+	err := json.Unmarshal([]byte(raw), patch)
+	require.NoError(t, err)
+	return patch.Operations
+}
+
+func TestApplyPatch(t *testing.T) {
+	active := boolean(true)
+	testUser := func() *User {
+		return &User{
+			Schemas:     []string{SchemaUser},
+			ID:          "1",
+			UserName:    "jdoe",
+			DisplayName: "John Doe",
+			Emails:      []*Email{{Value: "jdoe@example.com", Type: "work", Primary: true}},
+			Active:      &active,
+		}
+	}
+	testGroup := func() *Group {
+		return &Group{
+			Schemas:     []string{SchemaGroup},
+			ID:          "1",
+			DisplayName: "Developers",
+			Members:     []*Member{{Value: "1"}, {Value: "2"}},
+		}
+	}
+
+	t.Run("replace with path", func(t *testing.T) {
+		patched := &User{}
+		err := applyPatch(testUser(), patchOperations(t, `{"Operations":[{"op":"replace","path":"active","value":false}]}`), patched)
+		require.NoError(t, err)
+		assert.False(t, bool(*patched.Active))
+		assert.Equal(t, "jdoe", patched.UserName)
+	})
+	t.Run("replace with boolean as string", func(t *testing.T) {
+		patched := &User{}
+		err := applyPatch(testUser(), patchOperations(t, `{"Operations":[{"op":"Replace","path":"active","value":"False"}]}`), patched)
+		require.NoError(t, err)
+		assert.False(t, bool(*patched.Active))
+	})
+	t.Run("replace without path", func(t *testing.T) {
+		patched := &User{}
+		err := applyPatch(testUser(), patchOperations(t, `{"Operations":[{"op":"replace","value":{"active":false,"displayName":"Johnny"}}]}`), patched)
+		require.NoError(t, err)
+		assert.False(t, bool(*patched.Active))
+		assert.Equal(t, "Johnny", patched.DisplayName)
+	})
+	t.Run("sub-attribute", func(t *testing.T) {
+		patched := &User{}
+		err := applyPatch(testUser(), patchOperations(t, `{"Operations":[{"op":"add","path":"name.givenName","value":"John"}]}`), patched)
+		require.NoError(t, err)
+		assert.Equal(t, "John", patched.Name.GivenName)
+	})
+	t.Run("filtered path", func(t *testing.T) {
+		patched := &User{}
+		err := applyPatch(testUser(), patchOperations(t, `{"Operations":[{"op":"replace","path":"emails[type eq \"work\"].value","value":"john@example.com"}]}`), patched)
+		require.NoError(t, err)
+		require.Len(t, patched.Emails, 1)
+		assert.Equal(t, "john@example.com", patched.email())
+	})
+	t.Run("fully qualified path", func(t *testing.T) {
+		patched := &User{}
+		err := applyPatch(testUser(), patchOperations(t, `{"Operations":[{"op":"replace","path":"urn:ietf:params:scim:schemas:core:2.0:User:userName","value":"john"}]}`), patched)
+		require.NoError(t, err)
+		assert.Equal(t, "john", patched.UserName)
+	})
+	t.Run("add members", func(t *testing.T) {
+		patched := &Group{}
+		err := applyPatch(testGroup(), patchOperations(t, `{"Operations":[{"op":"add","path":"members","value":[{"value":"2"},{"value":"3"}]}]}`), patched)
+		require.NoError(t, err)
+		require.Len(t, patched.Members, 3)
+		assert.Equal(t, "3", patched.Members[2].Value)
+	})
+	t.Run("remove member with filter", func(t *testing.T) {
+		patched := &Group{}
+		err := applyPatch(testGroup(), patchOperations(t, `{"Operations":[{"op":"remove","path":"members[value eq \"1\"]"}]}`), patched)
+		require.NoError(t, err)
+		require.Len(t, patched.Members, 1)
+		assert.Equal(t, "2", patched.Members[0].Value)
+	})
+	t.Run("remove member with value", func(t *testing.T) {
+		patched := &Group{}
+		err := applyPatch(testGroup(), patchOperations(t, `{"Operations":[{"op":"remove","path":"members","value":[{"value":"2"}]}]}`), patched)
+		require.NoError(t, err)
+		require.Len(t, patched.Members, 1)
+		assert.Equal(t, "1", patched.Members[0].Value)
+	})
+	t.Run("unsupported operation", func(t *testing.T) {
+		patched := &Group{}
+		err := applyPatch(testGroup(), patchOperations(t, `{"Operations":[{"op":"move","path":"members"}]}`), patched)
+		require.Error(t, err)
+	})
+}
+
+func TestParseFilter(t *testing.T) {
+	f, err := parseFilter(`userName eq "jdoe"`)
+	require.NoError(t, err)
+	assert.Equal(t, "userName", f.attribute)
+	assert.Equal(t, "jdoe", f.value)
+
+	f, err = parseFilter(`urn:ietf:params:scim:schemas:core:2.0:User:externalId EQ "a \"quoted\" id"`)
+	require.NoError(t, err)
+	assert.Equal(t, "externalId", f.attribute)
+	assert.Equal(t, `a "quoted" id`, f.value)
+
+	f, err = parseFilter("")
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	_, err = parseFilter(`userName sw "j"`)
+	require.Error(t, err)
+}
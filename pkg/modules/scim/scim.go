@@ -0,0 +1,255 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package scim implements a SCIM 2.0 (RFC 7643 and 7644) service provider which lets identity providers
+// manage Vikunja users and teams.
+package scim
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"github.com/labstack/echo/v4"
+)
+
+// All schemas used in scim requests and responses
+const (
+	SchemaUser         = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup        = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaListResponse = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp      = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError        = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+const contentType = "application/scim+json"
+
+// Issuer returns the issuer of all users and teams managed via scim.
+func Issuer() string {
+	issuer := config.AuthSCIMIssuer.GetString()
+	if issuer == "" {
+		return user.IssuerSCIM
+	}
+	return issuer
+}
+
+// ValidateToken checks the bearer token of a scim client against the configured one.
+func ValidateToken(token string, _ echo.Context) (bool, error) {
+	configured := config.AuthSCIMToken.GetString()
+	if configured == "" {
+		return false, nil
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(configured)) == 1, nil
+}
+
+// Meta holds the metadata of a resource.
+type Meta struct {
+	ResourceType string    `json:"resourceType"`
+	Created      time.Time `json:"created"`
+	LastModified time.Time `json:"lastModified"`
+}
+
+// ListResponse is returned when querying resources.
+type ListResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int64         `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// PatchOperation is a single change to a resource.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// PatchRequest holds all changes which should be made to a resource.
+type PatchRequest struct {
+	Schemas    []string          `json:"schemas"`
+	Operations []*PatchOperation `json:"Operations"`
+}
+
+// Error is a scim error response.
+type Error struct {
+	Schemas []string `json:"schemas"`
+	Status  string   `json:"status"`
+	Type    string   `json:"scimType,omitempty"`
+	Detail  string   `json:"detail"`
+
+	code int
+}
+
+func (err *Error) Error() string {
+	return fmt.Sprintf("scim error [Status: %d, Type: %s, Detail: %s]", err.code, err.Type, err.Detail)
+}
+
+func newError(code int, typ, detail string) *Error {
+	return &Error{
+		Schemas: []string{SchemaError},
+		Status:  strconv.Itoa(code),
+		Type:    typ,
+		Detail:  detail,
+		code:    code,
+	}
+}
+
+// boolean accepts both booleans and strings like "True" as some identity providers send those.
+type boolean bool
+
+func (b *boolean) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case bool:
+		*b = boolean(v)
+	case string:
+		parsed, err := strconv.ParseBool(strings.ToLower(v))
+		if err != nil {
+			return err
+		}
+		*b = boolean(parsed)
+	default:
+		return fmt.Errorf("invalid boolean %s", string(data))
+	}
+
+	return nil
+}
+
+var filterRegex = regexp.MustCompile(`(?i)^\s*([a-z0-9_.:$-]+)\s+eq\s+(?:"((?:[^"\\]|\\.)*)"|(\S+))\s*$`)
+
+// filter is an equality filter, the only one supported.
+type filter struct {
+	attribute string
+	value     string
+}
+
+func parseFilter(raw string) (*filter, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	matches := filterRegex.FindStringSubmatch(raw)
+	if matches == nil {
+		return nil, newError(http.StatusBadRequest, "invalidFilter", "Only filters in the form 'attribute eq \"value\"' are supported.")
+	}
+
+	value := matches[3]
+	if matches[3] == "" {
+		value = strings.ReplaceAll(matches[2], `\"`, `"`)
+	}
+
+	return &filter{
+		attribute: trimSchema(matches[1]),
+		value:     value,
+	}, nil
+}
+
+// trimSchema removes the schema urn from fully qualified attribute names.
+func trimSchema(attribute string) string {
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		if strings.HasPrefix(attribute, schema+":") {
+			return strings.TrimPrefix(attribute, schema+":")
+		}
+	}
+	return attribute
+}
+
+func parseID(id string) (int64, error) {
+	parsed, err := strconv.ParseInt(id, 10, 64)
+	if err != nil || parsed < 1 {
+		return 0, newError(http.StatusNotFound, "", "The resource does not exist.")
+	}
+	return parsed, nil
+}
+
+func bind(c echo.Context, v interface{}) error {
+	err := json.NewDecoder(c.Request().Body).Decode(v)
+	if err != nil {
+		return newError(http.StatusBadRequest, "invalidSyntax", "The request body is not valid: "+err.Error())
+	}
+	return nil
+}
+
+func respond(c echo.Context, code int, v interface{}) error {
+	c.Response().Header().Set(echo.HeaderContentType, contentType)
+	c.Response().WriteHeader(code)
+	return json.NewEncoder(c.Response()).Encode(v)
+}
+
+// handleError converts all errors into scim error responses.
+func handleError(c echo.Context, err error) error {
+	var scimErr *Error
+	if errors.As(err, &scimErr) {
+		return respond(c, scimErr.code, scimErr)
+	}
+
+	switch {
+	case user.IsErrUserDoesNotExist(err), models.IsErrTeamDoesNotExist(err):
+		return respond(c, http.StatusNotFound, newError(http.StatusNotFound, "", "The resource does not exist."))
+	case isConflict(err):
+		return respond(c, http.StatusConflict, newError(http.StatusConflict, "uniqueness", err.Error()))
+	case user.IsErrNoUsernamePassword(err), user.IsErrUsernameMustNotContainSpaces(err), models.IsErrTeamNameCannotBeEmpty(err):
+		return respond(c, http.StatusBadRequest, newError(http.StatusBadRequest, "invalidValue", err.Error()))
+	}
+
+	log.Errorf("SCIM: %s", err)
+	return respond(c, http.StatusInternalServerError, newError(http.StatusInternalServerError, "", "Internal server error."))
+}
+
+// isConflict checks if an error means the username or email address is already taken.
+// Creating a user returns these errors as values while updating returns pointers.
+func isConflict(err error) bool {
+	switch err.(type) {
+	case user.ErrUsernameExists, *user.ErrUsernameExists, user.ErrUserEmailExists, *user.ErrUserEmailExists:
+		return true
+	}
+	return false
+}
+
+// getPagination returns the start index and count of a list request. The start index is 1-based.
+func getPagination(c echo.Context) (startIndex, count int) {
+	startIndex, err := strconv.Atoi(c.QueryParam("startIndex"))
+	if err != nil || startIndex < 1 {
+		startIndex = 1
+	}
+
+	maxCount := config.ServiceMaxItemsPerPage.GetInt()
+	count, err = strconv.Atoi(c.QueryParam("count"))
+	if err != nil || count > maxCount {
+		count = maxCount
+	}
+	if count < 0 {
+		count = 0
+	}
+
+	return
+}
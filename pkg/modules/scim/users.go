@@ -0,0 +1,262 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package scim
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// Name holds the name of a user.
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// Email is an email address of a user.
+type Email struct {
+	Value   string  `json:"value"`
+	Type    string  `json:"type,omitempty"`
+	Primary boolean `json:"primary"`
+}
+
+// GroupReference is a group a user is a member of.
+type GroupReference struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+// User is the scim representation of a Vikunja user.
+type User struct {
+	Schemas     []string          `json:"schemas"`
+	ID          string            `json:"id,omitempty"`
+	ExternalID  string            `json:"externalId,omitempty"`
+	UserName    string            `json:"userName"`
+	Name        *Name             `json:"name,omitempty"`
+	DisplayName string            `json:"displayName,omitempty"`
+	Emails      []*Email          `json:"emails,omitempty"`
+	Active      *boolean          `json:"active,omitempty"`
+	Groups      []*GroupReference `json:"groups,omitempty"`
+	Meta        *Meta             `json:"meta,omitempty"`
+}
+
+// email returns the primary email address of the user or the first one if none is marked as primary.
+func (su *User) email() string {
+	for _, e := range su.Emails {
+		if e.Primary {
+			return e.Value
+		}
+	}
+	if len(su.Emails) > 0 {
+		return su.Emails[0].Value
+	}
+	return ""
+}
+
+func (su *User) name() string {
+	if su.DisplayName != "" {
+		return su.DisplayName
+	}
+	if su.Name == nil {
+		return ""
+	}
+	if su.Name.Formatted != "" {
+		return su.Name.Formatted
+	}
+	return strings.TrimSpace(su.Name.GivenName + " " + su.Name.FamilyName)
+}
+
+// subject returns the subject the user is identified by when logging in via openid.
+func (su *User) subject() string {
+	if su.ExternalID != "" {
+		return su.ExternalID
+	}
+	return su.UserName
+}
+
+func (su *User) validate() error {
+	if su.UserName == "" {
+		return newError(http.StatusBadRequest, "invalidValue", "The userName is required.")
+	}
+	if su.email() == "" {
+		return newError(http.StatusBadRequest, "invalidValue", "At least one email address is required.")
+	}
+	return nil
+}
+
+// userFilterColumns maps the attributes users can be filtered by to their database columns.
+var userFilterColumns = map[string]string{
+	"id":           "id",
+	"username":     "username",
+	"externalid":   "subject",
+	"emails":       "email",
+	"emails.value": "email",
+}
+
+func convertUser(s *xorm.Session, u *user.User) (*User, error) {
+	teams := []*models.Team{}
+	err := s.
+		Table("teams").
+		Join("INNER", "team_members", "team_members.team_id = teams.id").
+		Where("team_members.user_id = ? AND teams.issuer = ?", u.ID, Issuer()).
+		OrderBy("teams.id").
+		Find(&teams)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]*GroupReference, 0, len(teams))
+	for _, t := range teams {
+		groups = append(groups, &GroupReference{
+			Value:   strconv.FormatInt(t.ID, 10),
+			Display: t.Name,
+		})
+	}
+
+	active := boolean(u.Status != user.StatusDisabled)
+	return &User{
+		Schemas:     []string{SchemaUser},
+		ID:          strconv.FormatInt(u.ID, 10),
+		ExternalID:  u.Subject,
+		UserName:    u.Username,
+		Name:        &Name{Formatted: u.Name},
+		DisplayName: u.Name,
+		Emails:      []*Email{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Groups:      groups,
+		Meta: &Meta{
+			ResourceType: "User",
+			Created:      u.Created,
+			LastModified: u.Updated,
+		},
+	}, nil
+}
+
+// getUser returns a user managed via scim. Users from other issuers can't be changed via scim.
+func getUser(s *xorm.Session, rawID string) (*user.User, error) {
+	id, err := parseID(rawID)
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := user.GetUserWithEmail(s, &user.User{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	if u.Issuer != Issuer() {
+		return nil, user.ErrUserDoesNotExist{UserID: id}
+	}
+	return u, nil
+}
+
+func listUsers(s *xorm.Session, f *filter, startIndex, count int) (users []*user.User, total int64, err error) {
+	cond := builder.And(builder.Eq{"issuer": Issuer()})
+	if f != nil {
+		column, supported := userFilterColumns[strings.ToLower(f.attribute)]
+		if !supported {
+			return nil, 0, newError(http.StatusBadRequest, "invalidFilter", "Users can only be filtered by id, userName, externalId or emails.")
+		}
+		cond = builder.And(cond, builder.Eq{column: f.value})
+	}
+
+	users = []*user.User{}
+	if count == 0 {
+		total, err = s.Where(cond).Count(&user.User{})
+		return
+	}
+	total, err = s.
+		Where(cond).
+		OrderBy("id").
+		Limit(count, startIndex-1).
+		FindAndCount(&users)
+	return
+}
+
+func setUserActive(s *xorm.Session, u *user.User, active *boolean) error {
+	if active == nil {
+		return nil
+	}
+
+	status := user.StatusActive
+	if !*active {
+		status = user.StatusDisabled
+	}
+	if u.Status == status {
+		return nil
+	}
+
+	err := user.SetUserStatus(s, u, status)
+	u.Status = status
+	return err
+}
+
+func createUser(s *xorm.Session, su *User) (*user.User, error) {
+	if err := su.validate(); err != nil {
+		return nil, err
+	}
+
+	u, err := user.CreateUser(s, &user.User{
+		Username: su.UserName,
+		Email:    su.email(),
+		Name:     su.name(),
+		Issuer:   Issuer(),
+		Subject:  su.subject(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = setUserActive(s, u, su.Active)
+	if err != nil {
+		return nil, err
+	}
+
+	err = models.CreateNewProjectForUser(s, u)
+	return u, err
+}
+
+func replaceUser(s *xorm.Session, u *user.User, su *User) (*user.User, error) {
+	if err := su.validate(); err != nil {
+		return nil, err
+	}
+
+	if su.subject() != u.Subject {
+		u.Subject = su.subject()
+		_, err := s.ID(u.ID).Cols("subject").Update(u)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := setUserActive(s, u, su.Active)
+	if err != nil {
+		return nil, err
+	}
+
+	u.Username = su.UserName
+	u.Email = su.email()
+	u.Name = su.name()
+	return user.UpdateUser(s, u, true)
+}
@@ -28,6 +28,8 @@ type Mail struct {
 	greeting   string
 	introLines []*mailLine
 	outroLines []*mailLine
+
+	attachments map[string][]byte
 }
 
 type mailLine struct {
@@ -80,6 +82,15 @@ func (m *Mail) HTML(line string) *Mail {
 	return m.appendLine(line, true)
 }
 
+// Attachment adds a file to the mail message
+func (m *Mail) Attachment(name string, content []byte) *Mail {
+	if m.attachments == nil {
+		m.attachments = make(map[string][]byte)
+	}
+	m.attachments[name] = content
+	return m
+}
+
 func (m *Mail) appendLine(line string, isHTML bool) *Mail {
 	if m.actionURL == "" {
 		m.introLines = append(m.introLines, &mailLine{
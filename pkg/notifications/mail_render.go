@@ -21,6 +21,7 @@ import (
 	"embed"
 	_ "embed"
 	templatehtml "html/template"
+	"io"
 	templatetext "text/template"
 
 	"github.com/microcosm-cc/bluemonday"
@@ -181,5 +182,12 @@ func RenderMail(m *Mail) (mailOpts *mail.Opts, err error) {
 		},
 	}
 
+	if len(m.attachments) > 0 {
+		mailOpts.Attachments = make(map[string]io.Reader, len(m.attachments))
+		for name, content := range m.attachments {
+			mailOpts.Attachments[name] = bytes.NewReader(content)
+		}
+	}
+
 	return mailOpts, nil
 }
@@ -86,6 +86,19 @@ func TestNewMail(t *testing.T) {
 		assert.Equal(t, "This should be an outro line", mail.introLines[2].Text)
 		assert.Equal(t, "And one more, because why not?", mail.introLines[3].Text)
 	})
+	t.Run("With attachment", func(t *testing.T) {
+		mail := NewMail().
+			Subject("Testmail").
+			Line("This is a line").
+			Attachment("export.zip", []byte("content"))
+
+		assert.Equal(t, []byte("content"), mail.attachments["export.zip"])
+
+		mailOpts, err := RenderMail(mail)
+		require.NoError(t, err)
+		assert.Len(t, mailOpts.Attachments, 1)
+		assert.Contains(t, mailOpts.Attachments, "export.zip")
+	})
 }
 
 func TestRenderMail(t *testing.T) {
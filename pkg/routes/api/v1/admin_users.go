@@ -0,0 +1,87 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+
+	"github.com/labstack/echo/v4"
+)
+
+// AdminResetUserTwoFactor removes all second factors of a user
+// @Summary Reset the two factor authentication of a user
+// @Description Removes the totp setting, all security keys and recovery codes of a user so that they can log in with only their password again. All sessions of the user are logged out. Only available to instance admins.
+// @tags admin
+// @Produce json
+// @Security JWTKeyAuth
+// @Param user path int true "The id of the user"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} web.HTTPError "Invalid user id."
+// @Failure 403 {object} web.HTTPError "The current user is not an instance admin."
+// @Failure 404 {object} web.HTTPError "The user does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /admin/users/{user}/reset-2fa [post]
+func AdminResetUserTwoFactor(c echo.Context) error {
+	userID, err := strconv.ParseInt(c.Param("user"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Invalid user id."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	if err := s.Begin(); err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	admin, err := user.GetCurrentUserFromDB(s, c)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = user.CheckUserIsAdmin(admin)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	u, err := user.GetUserByID(s, userID)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = user.ResetTwoFactor(s, u, "Reset by "+admin.Username)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, models.Message{Message: "The two factor authentication of the user was reset successfully."})
+}
@@ -0,0 +1,80 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/web/handler"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RotateAPIToken replaces an api token with a new one
+// @Summary Rotate an api token
+// @Description Replaces the secret token string of an api token with a new one. The old one stops working immediately. The permissions, projects and expiry date of the token stay the same. The new token is only visible in this response.
+// @tags api
+// @Produce json
+// @Security JWTKeyAuth
+// @Param tokenID path int true "Token ID"
+// @Success 200 {object} models.APIToken "The token with the new token string."
+// @Failure 400 {object} web.HTTPError "Invalid token id."
+// @Failure 403 {object} web.HTTPError "The token does not belong to the user."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /tokens/{tokenID}/rotate [post]
+func RotateAPIToken(c echo.Context) error {
+	tokenID, err := strconv.ParseInt(c.Param("token"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Invalid token id."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	a, err := auth.GetAuthFromClaims(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	token := &models.APIToken{ID: tokenID}
+	can, err := token.CanRotate(s, a)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+	if !can {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(models.ErrGenericForbidden{}, c)
+	}
+
+	err = token.Rotate(s)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, token)
+}
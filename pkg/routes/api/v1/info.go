@@ -22,11 +22,18 @@ import (
 	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/modules/auth/openid"
+	"code.vikunja.io/api/pkg/modules/migration/asana"
+	"code.vikunja.io/api/pkg/modules/migration/clickup"
+	"code.vikunja.io/api/pkg/modules/migration/generic"
+	"code.vikunja.io/api/pkg/modules/migration/github"
+	"code.vikunja.io/api/pkg/modules/migration/jira"
 	microsofttodo "code.vikunja.io/api/pkg/modules/migration/microsoft-todo"
 	"code.vikunja.io/api/pkg/modules/migration/ticktick"
 	"code.vikunja.io/api/pkg/modules/migration/todoist"
 	"code.vikunja.io/api/pkg/modules/migration/trello"
 	vikunja_file "code.vikunja.io/api/pkg/modules/migration/vikunja-file"
+	"code.vikunja.io/api/pkg/modules/migration/wekan"
+	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/api/pkg/version"
 
 	"github.com/labstack/echo/v4"
@@ -43,6 +50,7 @@ type vikunjaInfos struct {
 	TaskAttachmentsEnabled     bool      `json:"task_attachments_enabled"`
 	EnabledBackgroundProviders []string  `json:"enabled_background_providers"`
 	TotpEnabled                bool      `json:"totp_enabled"`
+	WebAuthnEnabled            bool      `json:"webauthn_enabled"`
 	Legal                      legalInfo `json:"legal"`
 	CaldavEnabled              bool      `json:"caldav_enabled"`
 	AuthInfo                   authInfo  `json:"auth"`
@@ -51,18 +59,24 @@ type vikunjaInfos struct {
 	TaskCommentsEnabled        bool      `json:"task_comments_enabled"`
 	DemoModeEnabled            bool      `json:"demo_mode_enabled"`
 	WebhooksEnabled            bool      `json:"webhooks_enabled"`
+	AutomationsEnabled         bool      `json:"automations_enabled"`
 	PublicTeamsEnabled         bool      `json:"public_teams_enabled"`
 }
 
 type authInfo struct {
 	Local         localAuthInfo  `json:"local"`
 	OpenIDConnect openIDAuthInfo `json:"openid_connect"`
+	LDAP          ldapAuthInfo   `json:"ldap"`
 }
 
 type localAuthInfo struct {
 	Enabled bool `json:"enabled"`
 }
 
+type ldapAuthInfo struct {
+	Enabled bool `json:"enabled"`
+}
+
 type openIDAuthInfo struct {
 	Enabled   bool               `json:"enabled"`
 	Providers []*openid.Provider `json:"providers"`
@@ -90,16 +104,24 @@ func Info(c echo.Context) error {
 		RegistrationEnabled:    config.ServiceEnableRegistration.GetBool(),
 		TaskAttachmentsEnabled: config.ServiceEnableTaskAttachments.GetBool(),
 		TotpEnabled:            config.ServiceEnableTotp.GetBool(),
+		WebAuthnEnabled:        user.WebAuthnEnabled(),
 		CaldavEnabled:          config.ServiceEnableCaldav.GetBool(),
 		EmailRemindersEnabled:  config.ServiceEnableEmailReminders.GetBool(),
 		UserDeletionEnabled:    config.ServiceEnableUserDeletion.GetBool(),
 		TaskCommentsEnabled:    config.ServiceEnableTaskComments.GetBool(),
 		DemoModeEnabled:        config.ServiceDemoMode.GetBool(),
 		WebhooksEnabled:        config.WebhooksEnabled.GetBool(),
+		AutomationsEnabled:     config.AutomationsEnabled.GetBool(),
 		PublicTeamsEnabled:     config.ServiceEnablePublicTeams.GetBool(),
 		AvailableMigrators: []string{
 			(&vikunja_file.FileMigrator{}).Name(),
 			(&ticktick.Migrator{}).Name(),
+			(&generic.FileMigrator{}).Name(),
+			(&jira.FileMigrator{}).Name(),
+			(&github.FileMigrator{}).Name(),
+			(&asana.FileMigrator{}).Name(),
+			(&clickup.FileMigrator{}).Name(),
+			(&wekan.FileMigrator{}).Name(),
 		},
 		Legal: legalInfo{
 			ImprintURL:       config.LegalImprintURL.GetString(),
@@ -112,6 +134,9 @@ func Info(c echo.Context) error {
 			OpenIDConnect: openIDAuthInfo{
 				Enabled: config.AuthOpenIDEnabled.GetBool(),
 			},
+			LDAP: ldapAuthInfo{
+				Enabled: config.AuthLDAPEnabled.GetBool(),
+			},
 		},
 	}
 
@@ -42,7 +42,7 @@ type LinkShareAuth struct {
 
 // AuthenticateLinkShare gives a jwt auth token for valid share hashes
 // @Summary Get an auth token for a share
-// @Description Get a jwt auth token for a shared project from a share hash.
+// @Description Get a jwt auth token for a shared project from a share hash. Every successful authentication counts as one use of the link share and is recorded in its access log.
 // @tags sharing
 // @Accept json
 // @Produce json
@@ -50,6 +50,7 @@ type LinkShareAuth struct {
 // @Param share path string true "The share hash"
 // @Success 200 {object} auth.Token "The valid jwt auth token."
 // @Failure 400 {object} web.HTTPError "Invalid link share object provided."
+// @Failure 403 {object} web.HTTPError "The link share has expired, was used too often or cannot be used from this ip address."
 // @Failure 500 {object} models.Message "Internal error"
 // @Router /shares/{share}/auth [post]
 func AuthenticateLinkShare(c echo.Context) error {
@@ -67,6 +68,11 @@ func AuthenticateLinkShare(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
+	err = models.CheckLinkShareAccess(share, c.RealIP())
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
 	if share.SharingType == models.SharingTypeWithPassword {
 		err := models.VerifyLinkSharePassword(share, sh.Password)
 		if err != nil {
@@ -74,6 +80,17 @@ func AuthenticateLinkShare(c echo.Context) error {
 		}
 	}
 
+	err = models.LogLinkShareAccess(s, share, c.RealIP(), c.Request().UserAgent())
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
 	t, err := auth.NewLinkShareJWTAuthtoken(share)
 	if err != nil {
 		return handler.HandleHTTPError(err, c)
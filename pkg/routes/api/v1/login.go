@@ -21,26 +21,29 @@ import (
 
 	"code.vikunja.io/api/pkg/modules/keyvalue"
 
+	"code.vikunja.io/api/pkg/config"
 	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/models"
 	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/modules/auth/ldap"
 	user2 "code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/web/handler"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
+	"xorm.io/xorm"
 )
 
 // Login is the login handler
 // @Summary Login
-// @Description Logs a user in. Returns a JWT-Token to authenticate further requests.
+// @Description Logs a user in. Returns a JWT-Token to authenticate further requests. If the user has a second factor enabled, the request needs to include a totp passcode, the response of one of their security keys or a recovery code.
 // @tags auth
 // @Accept json
 // @Produce json
 // @Param credentials body user.Login true "The login credentials"
 // @Success 200 {object} auth.Token
 // @Failure 400 {object} models.Message "Invalid user password model."
-// @Failure 412 {object} models.Message "Invalid totp passcode."
+// @Failure 412 {object} models.Message "Invalid totp passcode, security key response or recovery code."
 // @Failure 403 {object} models.Message "Invalid username or password."
 // @Router /login [post]
 func Login(c echo.Context) error {
@@ -53,7 +56,7 @@ func Login(c echo.Context) error {
 	defer s.Close()
 
 	// Check user
-	user, err := user2.CheckUserCredentials(s, &u)
+	user, err := checkLoginCredentials(s, &u)
 	if err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
@@ -64,31 +67,12 @@ func Login(c echo.Context) error {
 		return handler.HandleHTTPError(&user2.ErrAccountDisabled{UserID: user.ID}, c)
 	}
 
-	totpEnabled, err := user2.TOTPEnabledForUser(s, user)
+	err = checkSecondFactor(s, c, user, &u)
 	if err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
 	}
 
-	if totpEnabled {
-		if u.TOTPPasscode == "" {
-			_ = s.Rollback()
-			return handler.HandleHTTPError(user2.ErrInvalidTOTPPasscode{}, c)
-		}
-
-		_, err = user2.ValidateTOTPPasscode(s, &user2.TOTPPasscode{
-			User:     user,
-			Passcode: u.TOTPPasscode,
-		})
-		if err != nil {
-			if user2.IsErrInvalidTOTPPasscode(err) {
-				user2.HandleFailedTOTPAuth(s, user)
-			}
-			_ = s.Rollback()
-			return handler.HandleHTTPError(err, c)
-		}
-	}
-
 	if err := keyvalue.Del(user.GetFailedTOTPAttemptsKey()); err != nil {
 		return err
 	}
@@ -105,8 +89,73 @@ func Login(c echo.Context) error {
 	return auth.NewUserAuthTokenResponse(user, c, u.LongToken)
 }
 
+// checkLoginCredentials checks the username and password of a user logging in. If ldap is enabled, they are
+// checked against the directory first, falling back to local users if the directory does not know them.
+func checkLoginCredentials(s *xorm.Session, login *user2.Login) (*user2.User, error) {
+	if !config.AuthLDAPEnabled.GetBool() {
+		return user2.CheckUserCredentials(s, login)
+	}
+
+	u, err := ldap.AuthenticateUserInLDAP(s, login.Username, login.Password)
+	if user2.IsErrWrongUsernameOrPassword(err) && config.AuthLocalEnabled.GetBool() {
+		return user2.CheckUserCredentials(s, login)
+	}
+	return u, err
+}
+
+// checkSecondFactor verifies the second factor of a user logging in, if they enabled one.
+// A recovery code can be used instead of any other second factor.
+func checkSecondFactor(s *xorm.Session, c echo.Context, u *user2.User, login *user2.Login) (err error) {
+	totpEnabled, err := user2.TOTPEnabledForUser(s, u)
+	if err != nil {
+		return err
+	}
+	webAuthnEnabled, err := user2.WebAuthnEnabledForUser(s, u)
+	if err != nil {
+		return err
+	}
+
+	if !totpEnabled && !webAuthnEnabled {
+		return nil
+	}
+
+	if login.RecoveryCode != "" {
+		err = user2.UseRecoveryCode(s, u, login.RecoveryCode)
+		if err != nil {
+			return err
+		}
+		return user2.AddAuditLogEntry(s, &user2.AuditLogEntry{
+			UserID:    u.ID,
+			Action:    user2.AuditActionRecoveryCodeUsed,
+			IPAddress: c.RealIP(),
+			UserAgent: c.Request().UserAgent(),
+		})
+	}
+
+	if webAuthnEnabled && len(login.WebAuthn) > 0 {
+		return user2.ValidateWebAuthnLogin(s, u, login.WebAuthn)
+	}
+
+	if totpEnabled && login.TOTPPasscode != "" {
+		_, err = user2.ValidateTOTPPasscode(s, &user2.TOTPPasscode{
+			User:     u,
+			Passcode: login.TOTPPasscode,
+		})
+		if user2.IsErrInvalidTOTPPasscode(err) {
+			user2.HandleFailedTOTPAuth(s, u)
+		}
+		return err
+	}
+
+	if webAuthnEnabled {
+		return &user2.ErrWebAuthnRequired{UserID: u.ID}
+	}
+
+	return user2.ErrInvalidTOTPPasscode{}
+}
+
 // RenewToken gives a new token to every user with a valid token
-// If the token is valid is checked in the middleware.
+// If the token is valid is checked in the middleware. The new token belongs to the same session as the old one.
 // @Summary Renew user token
 // @Description Returns a new valid jwt user token with an extended length.
 // @tags user
@@ -151,17 +200,69 @@ func RenewToken(c echo.Context) (err error) {
 		return handler.HandleHTTPError(err, c)
 	}
 
-	if err := s.Commit(); err != nil {
+	sid, err := auth.GetSessionIDFromClaims(claims)
+	if err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
 	}
 
-	var long bool
-	lng, has := claims["long"]
-	if has {
-		long = lng.(bool)
+	// The session is checked in the middleware, this only gets it for the token
+	session, err := user2.GetSessionByID(s, sid)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
 	}
 
 	// Create token
-	return auth.NewUserAuthTokenResponse(user, c, long)
+	return auth.NewUserSessionTokenResponse(user, session, c)
+}
+
+// RefreshToken exchanges a refresh token for a new token
+// @Summary Refresh user token
+// @Description Exchanges the refresh token of a session for a new jwt token and a new refresh token. Each refresh token can only be used once.
+// @tags auth
+// @Accept json
+// @Produce json
+// @Param token body user.RefreshToken true "The refresh token"
+// @Success 200 {object} auth.Token
+// @Failure 400 {object} models.Message "No refresh token provided."
+// @Failure 401 {object} models.Message "The refresh token is invalid or the session has ended."
+// @Router /user/token/refresh [post]
+func RefreshToken(c echo.Context) (err error) {
+	t := &user2.RefreshToken{}
+	if err := c.Bind(t); err != nil || t.RefreshToken == "" {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Please provide a refresh token."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	session, err := user2.RefreshSession(s, t.RefreshToken, c.RealIP())
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	user, err := user2.GetUserWithEmail(s, &user2.User{ID: session.UserID})
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if user.Status == user2.StatusDisabled {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(&user2.ErrAccountDisabled{UserID: user.ID}, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return auth.NewUserSessionTokenResponse(user, session, c)
 }
@@ -0,0 +1,193 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+	"net/url"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web"
+	"code.vikunja.io/web/handler"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetOAuthConsent returns what the current user is asked to consent to when an app wants to access their account
+// @Summary Get the consent details of an oauth authorization request
+// @Description Validates an oauth authorization request and returns the app with the permissions it asks for. The frontend shows them as the consent screen. Pass the query parameters the app sent to the authorization url unchanged.
+// @tags oauth
+// @Produce json
+// @Security JWTKeyAuth
+// @Param response_type query string true "Must be code."
+// @Param client_id query string true "The client id of the app."
+// @Param redirect_uri query string true "One of the redirect uris registered for the app."
+// @Param scope query string true "The permissions the app asks for in the form group:permission, separated by spaces."
+// @Param state query string false "An opaque value passed back to the app."
+// @Param code_challenge query string true "The pkce code challenge."
+// @Param code_challenge_method query string true "Must be S256."
+// @Success 200 {object} models.OAuthConsent "The app and the permissions it asks for."
+// @Failure 400 {object} web.HTTPError "Invalid authorization request."
+// @Failure 404 {object} web.HTTPError "The app does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /oauth/authorize [get]
+func GetOAuthConsent(c echo.Context) error {
+	request := &models.OAuthAuthorizationRequest{}
+	if err := c.Bind(request); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Invalid authorization request."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	consent, err := models.GetOAuthConsent(s, u, request)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, consent)
+}
+
+// AuthorizeOAuthClient lets the current user authorize an app to access their account
+// @Summary Authorize an oauth app
+// @Description Records the consent of the current user for an app and returns the url the user should be redirected to. It contains an authorization code the app exchanges for tokens at /oauth/token.
+// @tags oauth
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param request body models.OAuthAuthorizationRequest true "The parameters the app sent to the authorization url."
+// @Success 200 {object} models.OAuthAuthorizationResponse "Where to redirect the user."
+// @Failure 400 {object} web.HTTPError "Invalid authorization request."
+// @Failure 404 {object} web.HTTPError "The app does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /oauth/authorize [post]
+func AuthorizeOAuthClient(c echo.Context) error {
+	request := &models.OAuthAuthorizationRequest{}
+	if err := c.Bind(request); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Invalid authorization request."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	response, err := models.AuthorizeOAuthClient(s, u, request)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, response)
+}
+
+// OAuthErrorResponse is the error format of the oauth token endpoint as defined in RFC 6749.
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func handleOAuthTokenError(c echo.Context, err error) error {
+	status := http.StatusBadRequest
+	var code string
+	switch {
+	case models.IsErrOAuthInvalidClientCredentials(err):
+		status = http.StatusUnauthorized
+		code = "invalid_client"
+	case models.IsErrOAuthInvalidGrant(err):
+		code = "invalid_grant"
+	case models.IsErrOAuthUnsupportedGrantType(err):
+		code = "unsupported_grant_type"
+	default:
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(status, OAuthErrorResponse{
+		Error:            code,
+		ErrorDescription: err.(web.HTTPErrorProcessor).HTTPError().Message,
+	})
+}
+
+// OAuthToken issues tokens to oauth clients
+// @Summary Get oauth tokens
+// @Description The oauth2 token endpoint. Exchanges an authorization code or a refresh token for a new access token and refresh token. Confidential clients authenticate with their client id and secret, either as form values or via http basic auth. Public clients only send their client id. The access token can be used like an api token with the permissions the user granted.
+// @tags oauth
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "Either authorization_code or refresh_token."
+// @Param code formData string false "The authorization code."
+// @Param redirect_uri formData string false "The redirect uri of the authorization request."
+// @Param code_verifier formData string false "The pkce code verifier."
+// @Param refresh_token formData string false "The refresh token."
+// @Param client_id formData string false "The client id of the app."
+// @Param client_secret formData string false "The client secret of the app."
+// @Success 200 {object} models.OAuthTokenResponse "The new tokens."
+// @Failure 400 {object} v1.OAuthErrorResponse "Invalid token request."
+// @Failure 401 {object} v1.OAuthErrorResponse "Invalid client credentials."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /oauth/token [post]
+func OAuthToken(c echo.Context) error {
+	request := &models.OAuthTokenRequest{}
+	if err := c.Bind(request); err != nil {
+		return c.JSON(http.StatusBadRequest, OAuthErrorResponse{
+			Error:            "invalid_request",
+			ErrorDescription: "Invalid token request.",
+		})
+	}
+
+	if clientID, clientSecret, has := c.Request().BasicAuth(); has {
+		request.ClientID, _ = url.QueryUnescape(clientID)
+		request.ClientSecret, _ = url.QueryUnescape(clientSecret)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	response, err := models.ExchangeOAuthToken(s, request)
+	if err != nil {
+		// An authorization code is used up even if the exchange fails so it cannot be tried again
+		if models.IsErrOAuthInvalidGrant(err) {
+			_ = s.Commit()
+		} else {
+			_ = s.Rollback()
+		}
+		return handleOAuthTokenError(c, err)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, response)
+}
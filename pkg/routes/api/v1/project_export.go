@@ -0,0 +1,221 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	auth2 "code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/modules/export"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ProjectExportRequest holds the format of a requested project export
+type ProjectExportRequest struct {
+	// The format of the export. Can be `csv`, `ics`, `md` or `todotxt`.
+	Format export.Format `json:"format"`
+}
+
+func getExportUserAndProject(c echo.Context) (u *user.User, projectID int64, err error) {
+	a, err := auth2.GetAuthFromClaims(c)
+	if err != nil {
+		return nil, 0, handler.HandleHTTPError(err, c)
+	}
+	u, is := a.(*user.User)
+	if !is {
+		return nil, 0, echo.ErrForbidden
+	}
+
+	projectID, err = strconv.ParseInt(c.Param("project"), 10, 64)
+	if err != nil {
+		return nil, 0, echo.NewHTTPError(http.StatusBadRequest, "Invalid project id.")
+	}
+
+	return
+}
+
+// ExportProject returns all tasks of a project in a standard format
+// @Summary Export a project
+// @Description Returns all tasks of a project or saved filter as csv (with the columns of the table view), iCalendar, markdown (a checkbox list grouped by the buckets of the kanban view) or todo.txt. Projects with more tasks than configured in `export.synclimit` can only be exported in the background, request the export for these instead.
+// @tags project
+// @Produce octet-stream
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID, or the project id of a saved filter"
+// @Param format query string true "The format of the export. Can be `csv`, `ics`, `md` or `todotxt`."
+// @Success 200 {file} blob "The exported tasks."
+// @Failure 400 {object} web.HTTPError "The format is invalid."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 413 {object} web.HTTPError "The project has too many tasks to export it directly."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/export [get]
+func ExportProject(c echo.Context) error {
+	u, projectID, err := getExportUserAndProject(c)
+	if err != nil {
+		return err
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	format := export.Format(c.QueryParam("format"))
+	content, data, err := export.ExportProject(s, u, projectID, format)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+data.FileName(format)+`"`)
+	return c.Blob(http.StatusOK, format.MimeType(), content)
+}
+
+// RequestProjectExport requests an export of a project which is created in the background
+// @Summary Request a project export
+// @Description Creates an export of all tasks of a project or saved filter in the background. The user will get an email with a download link once the export is ready. Use this for projects which are too large to export them directly.
+// @tags project
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID, or the project id of a saved filter"
+// @Param export body v1.ProjectExportRequest true "The format of the export."
+// @Success 200 {object} export.TaskExport "The requested export."
+// @Failure 400 {object} web.HTTPError "The format is invalid."
+// @Failure 403 {object} web.HTTPError "The user does not have access to the project."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/export [post]
+func RequestProjectExport(c echo.Context) error {
+	u, projectID, err := getExportUserAndProject(c)
+	if err != nil {
+		return err
+	}
+
+	req := &ProjectExportRequest{}
+	if err := c.Bind(req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "No or invalid format provided.")
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = s.Begin()
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	te, err := export.RequestExport(s, u, projectID, req.Format)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = s.Commit()
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, te)
+}
+
+// DownloadProjectExport downloads a previously requested project export
+// @Summary Download a project export
+// @Description Downloads an export which was created in the background. Exports are available for 7 days.
+// @tags project
+// @Produce octet-stream
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID, or the project id of a saved filter"
+// @Param export path int true "Export ID"
+// @Success 200 {file} blob "The exported tasks."
+// @Failure 404 {object} web.HTTPError "The export does not exist."
+// @Failure 412 {object} web.HTTPError "The export is not ready yet."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/export/{export} [get]
+func DownloadProjectExport(c echo.Context) error {
+	u, projectID, err := getExportUserAndProject(c)
+	if err != nil {
+		return err
+	}
+
+	exportID, err := strconv.ParseInt(c.Param("export"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid export id.")
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	f, err := export.GetExportFile(s, u, projectID, exportID)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+f.Name+`"`)
+	http.ServeContent(c.Response(), c.Request(), f.Name, f.Created, f.File)
+	return nil
+}
+
+// ExportProjectTree exports a project with all its child projects so that it can be imported into another instance
+// @Summary Export a project with its child projects
+// @Description Returns a zip file with the project, all its child projects, views, buckets, tasks, comments, attachments, labels, relations and backgrounds, as well as the users and teams the projects are shared with. The file can be imported on another instance with the vikunja-file migrator, users and teams are matched by their username or email and name. Only project admins can export a project.
+// @tags project
+// @Produce octet-stream
+// @Security JWTKeyAuth
+// @Param project path int true "Project ID"
+// @Success 200 {file} blob "The exported project tree."
+// @Failure 403 {object} web.HTTPError "The user is not an admin of the project."
+// @Failure 404 {object} web.HTTPError "The project does not exist."
+// @Failure 500 {object} models.Message "Internal error"
+// @Router /projects/{project}/export/tree [get]
+func ExportProjectTree(c echo.Context) error {
+	u, projectID, err := getExportUserAndProject(c)
+	if err != nil {
+		return err
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	// The export can contain lots of attachments, that's why it is written to a temporary file first
+	// instead of keeping it in memory.
+	tmp, err := os.CreateTemp("", "vikunja-project-export-*.zip")
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	err = models.ExportProjectTree(s, u, projectID, tmp)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	_, err = tmp.Seek(0, 0)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	fileName := models.ProjectTreeExportFileName(projectID)
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+fileName+`"`)
+	http.ServeContent(c.Response(), c.Request(), fileName, time.Now(), tmp)
+	return nil
+}
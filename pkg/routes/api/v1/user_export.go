@@ -18,6 +18,7 @@ package v1
 
 import (
 	"net/http"
+	"strconv"
 
 	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/events"
@@ -139,3 +140,186 @@ func DownloadUserDataExport(c echo.Context) error {
 	http.ServeContent(c.Response(), c.Request(), exportFile.Name, exportFile.Created, exportFile.File)
 	return nil
 }
+
+// GetUserExportSchedule returns the export schedule of the current user
+// @Summary Get the scheduled data export
+// @Description Returns the settings of the recurring data export of the current user.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} models.UserExportSchedule
+// @Failure 404 {object} web.HTTPError "The user has not scheduled any exports."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/export/schedule [get]
+func GetUserExportSchedule(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUserFromDB(s, c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	schedule, err := models.GetUserExportSchedule(s, u)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, schedule)
+}
+
+// UpdateUserExportSchedule creates or updates the export schedule of the current user
+// @Summary Schedule recurring data exports
+// @Description Creates or updates a recurring data export. Every export is kept as a backup until there are more backups than the retention count. Backups can optionally be uploaded to a WebDAV folder or sent via email.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param schedule body models.UserExportSchedule true "The export schedule. If no WebDAV password is provided, the existing one is kept."
+// @Success 200 {object} models.UserExportSchedule
+// @Failure 400 {object} web.HTTPError "Something's invalid."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/export/schedule [post]
+func UpdateUserExportSchedule(c echo.Context) error {
+	schedule := &models.UserExportSchedule{}
+	if err := c.Bind(schedule); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "No or invalid export schedule provided.")
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err := s.Begin()
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	u, err := user.GetCurrentUserFromDB(s, c)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = models.SaveUserExportSchedule(s, u, schedule)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = s.Commit()
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, schedule)
+}
+
+// DeleteUserExportSchedule removes the export schedule of the current user
+// @Summary Stop recurring data exports
+// @Description Removes the export schedule of the current user. Existing backups are kept.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} models.Message
+// @Failure 404 {object} web.HTTPError "The user has not scheduled any exports."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/export/schedule [delete]
+func DeleteUserExportSchedule(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	err := s.Begin()
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	u, err := user.GetCurrentUserFromDB(s, c)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = models.DeleteUserExportSchedule(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = s.Commit()
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, models.Message{Message: "Successfully removed the export schedule."})
+}
+
+// GetUserExportBackups returns all backups created by the export schedule of the current user
+// @Summary Get all scheduled data export backups
+// @Description Returns all backups created from the export schedule of the current user, newest first.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} models.UserExportBackup
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/export/backups [get]
+func GetUserExportBackups(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUserFromDB(s, c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	backups, err := models.GetUserExportBackups(s, u)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, backups)
+}
+
+// DownloadUserExportBackup is the handler to download a backup created by the export schedule
+// @Summary Download a scheduled data export backup.
+// @tags user
+// @Accept json
+// @Produce octet-stream
+// @Security JWTKeyAuth
+// @Param backup path int true "Backup ID"
+// @Param password body v1.UserPasswordConfirmation true "User password to confirm the download."
+// @Success 200 {file} blob "The backup."
+// @Failure 400 {object} web.HTTPError "Something's invalid."
+// @Failure 404 {object} web.HTTPError "The backup does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/export/backups/{backup}/download [post]
+func DownloadUserExportBackup(c echo.Context) error {
+	backupID, err := strconv.ParseInt(c.Param("backup"), 10, 64)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid backup id.")
+	}
+
+	s, u, err := checkExportRequest(c)
+	if err != nil {
+		return err
+	}
+
+	err = s.Commit()
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	s = db.NewSession()
+	defer s.Close()
+
+	backupFile, err := models.GetUserExportBackupFile(s, u, backupID)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="`+backupFile.Name+`"`)
+	http.ServeContent(c.Response(), c.Request(), backupFile.Name, backupFile.Created, backupFile.File)
+	return nil
+}
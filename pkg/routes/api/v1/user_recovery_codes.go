@@ -0,0 +1,102 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetUserRecoveryCodesStatus returns how many recovery codes the current user has left
+// @Summary Recovery code status
+// @Description Returns how many unused recovery codes the current user has left. The codes themselves are only shown once when they are generated.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} user.RecoveryCodesStatus
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/recovery-codes [get]
+func GetUserRecoveryCodesStatus(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	status, err := user.GetRecoveryCodesStatus(s, u)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, status)
+}
+
+// GenerateUserRecoveryCodes creates new recovery codes for the current user
+// @Summary Generate recovery codes
+// @Description Generates new recovery codes for the current user and invalidates all old ones. Each code can be used once instead of a totp passcode or a security key to log in. The codes are only shown in this response. Users who log in with a password need to confirm it.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param credentials body v1.UserPasswordConfirmation true "The user password."
+// @Success 200 {object} user.RecoveryCodes
+// @Failure 403 {object} web.HTTPError "The user does not have a password and needs to log in again."
+// @Failure 412 {object} web.HTTPError "Bad password provided."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/recovery-codes [post]
+func GenerateUserRecoveryCodes(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUserFromDB(s, c)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = confirmUserPassword(s, c, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	codes, err := user.GenerateRecoveryCodes(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = user.AddAuditLogEntry(s, newAuditLogEntry(c, u, user.AuditActionRecoveryCodesGenerated, ""))
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, codes)
+}
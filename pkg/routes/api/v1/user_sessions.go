@@ -0,0 +1,147 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+
+	"github.com/labstack/echo/v4"
+)
+
+// GetUserSessions returns all sessions of the current user
+// @Summary Get all sessions
+// @Description Returns all devices the current user is logged in with, the most recently used first.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} user.Session
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/sessions [get]
+func GetUserSessions(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	sessions, err := user.GetSessionsForUser(s, u)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	currentID := auth.GetCurrentSessionID(c)
+	for _, sess := range sessions {
+		sess.IsCurrent = sess.ID == currentID
+	}
+
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// DeleteUserSession logs out a session of the current user
+// @Summary Log out a session
+// @Description Logs out one of the devices of the current user. All tokens of the session stop working immediately.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Param session path int true "The id of the session"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} web.HTTPError "Invalid session id."
+// @Failure 404 {object} web.HTTPError "The session does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/sessions/{session} [delete]
+func DeleteUserSession(c echo.Context) error {
+	sessionID, err := strconv.ParseInt(c.Param("session"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Invalid session id."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	err = s.Begin()
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = user.DeleteSession(s, u, sessionID)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = s.Commit()
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, models.Message{Message: "The session was logged out successfully."})
+}
+
+// DeleteAllUserSessions logs out all other sessions of the current user
+// @Summary Log out all other sessions
+// @Description Logs out all devices of the current user except the one the request was made with.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} models.Message
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/sessions [delete]
+func DeleteAllUserSessions(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	err := s.Begin()
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = user.DeleteAllSessions(s, u.ID, auth.GetCurrentSessionID(c))
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = s.Commit()
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, models.Message{Message: "All other sessions were logged out successfully."})
+}
@@ -32,6 +32,14 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// TOTPEnabled is returned after enabling totp.
+type TOTPEnabled struct {
+	Message string `json:"message"`
+	// Single use codes which can be used instead of a totp passcode to log in, in case the totp device is lost.
+	// They are only shown once.
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
 // UserTOTPEnroll is the handler to enroll a user into totp
 // @Summary Enroll a user into totp
 // @Description Creates an initial setup for the user in the db. After this step, the user needs to verify they have a working totp setup with the "enable totp" endpoint.
@@ -69,13 +77,13 @@ func UserTOTPEnroll(c echo.Context) error {
 
 // UserTOTPEnable is the handler to enable totp for a user
 // @Summary Enable a previously enrolled totp setting.
-// @Description Enables a previously enrolled totp setting by providing a totp passcode.
+// @Description Enables a previously enrolled totp setting by providing a totp passcode. Returns recovery codes which can be used to log in if the totp device is lost. They are only shown once.
 // @tags user
 // @Accept json
 // @Produce json
 // @Param totp body user.TOTPPasscode true "The totp passcode."
 // @Security JWTKeyAuth
-// @Success 200 {object} models.Message "Successfully enabled"
+// @Success 200 {object} v1.TOTPEnabled "Successfully enabled"
 // @Failure 400 {object} web.HTTPError "Something's invalid."
 // @Failure 404 {object} web.HTTPError "User does not exist."
 // @Failure 412 {object} web.HTTPError "TOTP is not enrolled."
@@ -102,7 +110,13 @@ func UserTOTPEnable(c echo.Context) error {
 	s := db.NewSession()
 	defer s.Close()
 
-	err = user.EnableTOTP(s, passcode)
+	codes, err := user.EnableTOTP(s, passcode)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = user.AddAuditLogEntry(s, newAuditLogEntry(c, u, user.AuditActionTOTPEnabled, ""))
 	if err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
@@ -113,7 +127,10 @@ func UserTOTPEnable(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
-	return c.JSON(http.StatusOK, models.Message{Message: "TOTP was enabled successfully."})
+	return c.JSON(http.StatusOK, &TOTPEnabled{
+		Message:       "TOTP was enabled successfully.",
+		RecoveryCodes: codes.Codes,
+	})
 }
 
 // UserTOTPDisable disables totp settings for the current user.
@@ -166,6 +183,12 @@ func UserTOTPDisable(c echo.Context) error {
 		return handler.HandleHTTPError(err, c)
 	}
 
+	err = user.AddAuditLogEntry(s, newAuditLogEntry(c, u, user.AuditActionTOTPDisabled, ""))
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
 	if err := s.Commit(); err != nil {
 		_ = s.Rollback()
 		return handler.HandleHTTPError(err, c)
@@ -0,0 +1,380 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/modules/auth/ldap"
+	"code.vikunja.io/api/pkg/user"
+	"code.vikunja.io/web/handler"
+
+	"github.com/labstack/echo/v4"
+	"xorm.io/xorm"
+)
+
+// PasskeyLogin holds the response of a security key to log in without a password.
+type PasskeyLogin struct {
+	// The PublicKeyCredential returned by navigator.credentials.get().
+	Credential json.RawMessage `json:"credential" swaggertype:"object"`
+	// If true, the token returned will be valid a lot longer than default. Useful for "remember me" style logins.
+	LongToken bool `json:"long_token"`
+}
+
+// confirmUserPassword makes sure a security relevant change is made by the owner of the account
+// by asking for their password. Users who don't log in with a password, like users from an OpenID Connect
+// provider, need to have logged in a short time ago instead.
+func confirmUserPassword(s *xorm.Session, c echo.Context, u *user.User) error {
+	if !u.IsLocalUser() && u.Issuer != user.IssuerLDAP {
+		return user.CheckRecentLogin(s, u, auth.GetCurrentSessionID(c))
+	}
+
+	var confirmation UserPasswordConfirmation
+	if err := c.Bind(&confirmation); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "No password provided.")
+	}
+	if err := c.Validate(confirmation); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	if u.Issuer == user.IssuerLDAP {
+		return ldap.CheckUserPassword(u, confirmation.Password)
+	}
+
+	return user.CheckUserPassword(u, confirmation.Password)
+}
+
+func newAuditLogEntry(c echo.Context, u *user.User, action user.AuditAction, details string) *user.AuditLogEntry {
+	return &user.AuditLogEntry{
+		UserID:    u.ID,
+		Action:    action,
+		Details:   details,
+		IPAddress: c.RealIP(),
+		UserAgent: c.Request().UserAgent(),
+	}
+}
+
+// WebAuthnLoginOptions returns the options to confirm a login with a security key
+// @Summary Start a login with a security key
+// @Description Checks the username and password of a user who registered security keys and returns the options which need to be passed to navigator.credentials.get(). The response of the security key then needs to be sent to /login as "webauthn" together with the username and password.
+// @tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body user.Login true "The login credentials"
+// @Success 200 {object} protocol.CredentialAssertion
+// @Failure 403 {object} models.Message "Invalid username or password."
+// @Failure 404 {object} web.HTTPError "The user did not register any security keys."
+// @Router /login/webauthn [post]
+func WebAuthnLoginOptions(c echo.Context) error {
+	login := user.Login{}
+	if err := c.Bind(&login); err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Please provide a username and password."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := checkLoginCredentials(s, &login)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	assertion, err := user.BeginWebAuthnLogin(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, assertion)
+}
+
+// PasskeyLoginOptions returns the options to log in with a passkey
+// @Summary Start a login with a passkey
+// @Description Returns the options which need to be passed to navigator.credentials.get() to log in with a passkey, without a username or password.
+// @tags auth
+// @Produce json
+// @Success 200 {object} protocol.CredentialAssertion
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /login/passkey/options [post]
+func PasskeyLoginOptions(c echo.Context) error {
+	assertion, err := user.BeginPasskeyLogin()
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, assertion)
+}
+
+// LoginWithPasskey logs a user in with a passkey
+// @Summary Login with a passkey
+// @Description Logs a user in with the response of a passkey to the options returned by /login/passkey/options. Returns a JWT-Token to authenticate further requests.
+// @tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body v1.PasskeyLogin true "The response of the passkey"
+// @Success 200 {object} auth.Token
+// @Failure 400 {object} models.Message "Invalid passkey login model."
+// @Failure 412 {object} web.HTTPError "Invalid security key response."
+// @Router /login/passkey [post]
+func LoginWithPasskey(c echo.Context) error {
+	login := &PasskeyLogin{}
+	if err := c.Bind(login); err != nil || len(login.Credential) == 0 {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Please provide the response of a passkey."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.ValidatePasskeyLogin(s, login.Credential)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if u.Status == user.StatusDisabled {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(&user.ErrAccountDisabled{UserID: u.ID}, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return auth.NewUserAuthTokenResponse(u, c, login.LongToken)
+}
+
+// GetUserWebAuthnCredentials returns all security keys of the current user
+// @Summary Get all security keys
+// @Description Returns all security keys and passkeys the current user registered.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {array} user.WebAuthnCredential
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn [get]
+func GetUserWebAuthnCredentials(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	credentials, err := user.GetWebAuthnCredentials(s, u)
+	if err != nil {
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, credentials)
+}
+
+// BeginUserWebAuthnRegistration starts registering a new security key
+// @Summary Start registering a security key
+// @Description Returns the options which need to be passed to navigator.credentials.create() to register a new security key or passkey.
+// @tags user
+// @Produce json
+// @Security JWTKeyAuth
+// @Success 200 {object} protocol.CredentialCreation
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn/register/begin [post]
+func BeginUserWebAuthnRegistration(c echo.Context) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUserFromDB(s, c)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	creation, err := user.BeginWebAuthnRegistration(s, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, creation)
+}
+
+// FinishUserWebAuthnRegistration saves a new security key
+// @Summary Finish registering a security key
+// @Description Verifies the response of the security key to the options returned by /user/settings/webauthn/register/begin and saves it. From then on, the security key is required to log in.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param registration body user.WebAuthnRegistration true "The name and the response of the security key"
+// @Success 201 {object} user.WebAuthnCredential
+// @Failure 400 {object} web.HTTPError "Invalid registration model."
+// @Failure 412 {object} web.HTTPError "Invalid security key response."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn/register/finish [post]
+func FinishUserWebAuthnRegistration(c echo.Context) error {
+	registration := &user.WebAuthnRegistration{}
+	if err := c.Bind(registration); err != nil || len(registration.Credential) == 0 {
+		return echo.NewHTTPError(http.StatusBadRequest, "Please provide the response of the security key.")
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUserFromDB(s, c)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	credential, err := user.FinishWebAuthnRegistration(s, u, registration)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = user.AddAuditLogEntry(s, newAuditLogEntry(c, u, user.AuditActionWebAuthnRegistered, credential.Name))
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusCreated, credential)
+}
+
+// UpdateUserWebAuthnCredential renames a security key of the current user
+// @Summary Rename a security key
+// @Description Changes the name of a security key of the current user.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param credential path int true "The id of the security key"
+// @Param credential body user.WebAuthnCredential true "The security key with the new name"
+// @Success 200 {object} user.WebAuthnCredential
+// @Failure 400 {object} web.HTTPError "Invalid security key model."
+// @Failure 404 {object} web.HTTPError "The security key does not exist."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn/{credential} [post]
+func UpdateUserWebAuthnCredential(c echo.Context) error {
+	credential := &user.WebAuthnCredential{}
+	if err := c.Bind(credential); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid model provided.")
+	}
+	if err := c.Validate(credential); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUser(c)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = user.RenameWebAuthnCredential(s, u, credential)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, credential)
+}
+
+// DeleteUserWebAuthnCredential removes a security key of the current user
+// @Summary Remove a security key
+// @Description Removes a security key of the current user. Users who log in with a password need to confirm it.
+// @tags user
+// @Accept json
+// @Produce json
+// @Security JWTKeyAuth
+// @Param credential path int true "The id of the security key"
+// @Param credentials body v1.UserPasswordConfirmation true "The user password."
+// @Success 200 {object} models.Message
+// @Failure 400 {object} web.HTTPError "Invalid security key id."
+// @Failure 404 {object} web.HTTPError "The security key does not exist."
+// @Failure 403 {object} web.HTTPError "The user does not have a password and needs to log in again."
+// @Failure 412 {object} web.HTTPError "Bad password provided."
+// @Failure 500 {object} models.Message "Internal server error."
+// @Router /user/settings/webauthn/{credential} [delete]
+func DeleteUserWebAuthnCredential(c echo.Context) error {
+	credentialID, err := strconv.ParseInt(c.Param("credential"), 10, 64)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, models.Message{Message: "Invalid security key id."})
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	u, err := user.GetCurrentUserFromDB(s, c)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = confirmUserPassword(s, c, u)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	credential, err := user.DeleteWebAuthnCredential(s, u, credentialID)
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	err = user.AddAuditLogEntry(s, newAuditLogEntry(c, u, user.AuditActionWebAuthnRemoved, credential.Name))
+	if err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return handler.HandleHTTPError(err, c)
+	}
+
+	return c.JSON(http.StatusOK, models.Message{Message: "The security key was removed successfully."})
+}
@@ -17,6 +17,7 @@
 package routes
 
 import (
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -25,7 +26,10 @@ import (
 	"code.vikunja.io/api/pkg/db"
 	"code.vikunja.io/api/pkg/log"
 	"code.vikunja.io/api/pkg/models"
+	"code.vikunja.io/api/pkg/modules/auth"
+	"code.vikunja.io/api/pkg/user"
 
+	"github.com/golang-jwt/jwt/v5"
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 )
@@ -48,6 +52,7 @@ func SetupTokenMiddleware() echo.MiddlewareFunc {
 
 			return false
 		},
+		ParseTokenFunc: parseTokenAndCheckSession,
 		ErrorHandler: func(_ echo.Context, err error) error {
 			if err != nil {
 				return echo.NewHTTPError(http.StatusUnauthorized, "missing, malformed, expired or otherwise invalid token provided").SetInternal(err)
@@ -58,6 +63,74 @@ func SetupTokenMiddleware() echo.MiddlewareFunc {
 	})
 }
 
+// parseTokenAndCheckSession parses a jwt token and makes sure the session of a user token still exists,
+// which is not the case when the user logged out the device or changed their password.
+func parseTokenAndCheckSession(c echo.Context, tokenString string) (interface{}, error) {
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != jwt.SigningMethodHS256.Alg() {
+			return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
+		}
+		return []byte(config.ServiceJWTSecret.GetString()), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	typ, is := claims["type"].(float64)
+	if is && int(typ) == auth.AuthTypeLinkShare {
+		if err := checkLinkShareToken(c, claims); err != nil {
+			return nil, err
+		}
+		return token, nil
+	}
+	if !is || int(typ) != auth.AuthTypeUser {
+		return token, nil
+	}
+
+	sid, err := auth.GetSessionIDFromClaims(claims)
+	if err != nil {
+		return nil, err
+	}
+
+	s := db.NewSession()
+	defer s.Close()
+
+	sess, err := user.GetSessionByID(s, sid)
+	if err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	userID, is := claims["id"].(float64)
+	if !is || sess.UserID != int64(userID) {
+		_ = s.Rollback()
+		return nil, fmt.Errorf("session %d does not belong to user %v", sid, claims["id"])
+	}
+
+	err = user.UpdateSessionActivity(s, sess, c.RealIP())
+	if err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// checkLinkShareToken makes sure the link share of a token still exists, did not expire and can be used
+// from the ip address of the request.
+func checkLinkShareToken(c echo.Context, claims jwt.MapClaims) error {
+	s := db.NewSession()
+	defer s.Close()
+
+	return models.CheckLinkShareToken(s, claims, c.RealIP())
+}
+
 func checkAPITokenAndPutItInContext(tokenHeaderValue string, c echo.Context) error {
 	s := db.NewSession()
 	defer s.Close()
@@ -71,10 +144,31 @@ func checkAPITokenAndPutItInContext(tokenHeaderValue string, c echo.Context) err
 		return echo.NewHTTPError(http.StatusUnauthorized)
 	}
 
+	owner, err := user.GetUserByID(s, token.OwnerID)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+
+	if owner.Status == user.StatusDisabled {
+		log.Debugf("[auth] Tried authenticating with token %d but its owner %d is disabled", token.ID, owner.ID)
+		return echo.NewHTTPError(http.StatusUnauthorized)
+	}
+
 	if !models.CanDoAPIRoute(c, token) {
 		return echo.NewHTTPError(http.StatusUnauthorized)
 	}
 
+	err = token.UpdateLastUsed(s, c.RealIP())
+	if err != nil {
+		_ = s.Rollback()
+		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+
+	if err := s.Commit(); err != nil {
+		_ = s.Rollback()
+		return echo.NewHTTPError(http.StatusInternalServerError).SetInternal(err)
+	}
+
 	c.Set("api_token", token)
 
 	return nil
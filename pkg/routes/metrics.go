@@ -113,6 +113,15 @@ func setupMetricsMiddleware(a *echo.Group) {
 	})
 }
 
+// setupRequestMetrics records the duration of all requests by route and status code.
+func setupRequestMetrics(e *echo.Echo) {
+	if !config.MetricsEnabled.GetBool() {
+		return
+	}
+
+	e.Use(metrics.RecordHTTPRequests)
+}
+
 // updateActiveUsersFromContext updates the currently active users in redis
 func updateActiveUsersFromContext(c echo.Context) (err error) {
 	auth, err := auth2.GetAuthFromClaims(c)
@@ -124,5 +133,10 @@ func updateActiveUsersFromContext(c echo.Context) (err error) {
 		return metrics.SetLinkShareActive(auth)
 	}
 
+	// Service accounts are not people and should not count as active users
+	if u, is := auth.(*user.User); is && u.IsBot {
+		return nil
+	}
+
 	return metrics.SetUserActive(auth)
 }
@@ -68,14 +68,22 @@ import (
 	"code.vikunja.io/api/pkg/modules/background/unsplash"
 	"code.vikunja.io/api/pkg/modules/background/upload"
 	"code.vikunja.io/api/pkg/modules/migration"
+	"code.vikunja.io/api/pkg/modules/migration/asana"
+	"code.vikunja.io/api/pkg/modules/migration/clickup"
+	"code.vikunja.io/api/pkg/modules/migration/generic"
+	"code.vikunja.io/api/pkg/modules/migration/github"
 	migrationHandler "code.vikunja.io/api/pkg/modules/migration/handler"
+	"code.vikunja.io/api/pkg/modules/migration/jira"
 	microsofttodo "code.vikunja.io/api/pkg/modules/migration/microsoft-todo"
 	"code.vikunja.io/api/pkg/modules/migration/ticktick"
 	"code.vikunja.io/api/pkg/modules/migration/todoist"
 	"code.vikunja.io/api/pkg/modules/migration/trello"
 	vikunja_file "code.vikunja.io/api/pkg/modules/migration/vikunja-file"
+	"code.vikunja.io/api/pkg/modules/migration/wekan"
+	"code.vikunja.io/api/pkg/modules/scim"
 	apiv1 "code.vikunja.io/api/pkg/routes/api/v1"
 	"code.vikunja.io/api/pkg/routes/caldav"
+	"code.vikunja.io/api/pkg/user"
 	"code.vikunja.io/api/pkg/version"
 	"code.vikunja.io/web"
 	"code.vikunja.io/web/handler"
@@ -111,6 +119,9 @@ func NewEcho() *echo.Echo {
 		}))
 	}
 
+	// Metrics, before recovering from panics so that these are recorded as well
+	setupRequestMetrics(e)
+
 	// panic recover
 	e.Use(middleware.Recover())
 
@@ -189,6 +200,10 @@ func RegisterRoutes(e *echo.Echo) {
 		registerCalDavRoutes(c)
 	}
 
+	if config.AuthSCIMEnabled.GetBool() {
+		registerSCIMRoutes(e.Group("/scim/v2"))
+	}
+
 	// healthcheck
 	e.GET("/health", HealthcheckHandler)
 
@@ -259,9 +274,12 @@ func registerAPIRoutes(a *echo.Group) {
 	rateLimiter := createRateLimiter(rate)
 	ur.Use(RateLimit(rateLimiter, "ip"))
 
+	if config.AuthLocalEnabled.GetBool() || config.AuthLDAPEnabled.GetBool() {
+		ur.POST("/login", apiv1.Login)
+	}
+
 	if config.AuthLocalEnabled.GetBool() {
 		// User stuff
-		ur.POST("/login", apiv1.Login)
 		ur.POST("/register", apiv1.RegisterUser)
 		ur.POST("/user/password/token", apiv1.UserRequestResetPasswordToken)
 		ur.POST("/user/password/reset", apiv1.UserResetPassword)
@@ -272,6 +290,17 @@ func registerAPIRoutes(a *echo.Group) {
 		ur.POST("/auth/openid/:provider/callback", openid.HandleCallback)
 	}
 
+	ur.POST("/user/token/refresh", apiv1.RefreshToken)
+	ur.POST("/oauth/token", apiv1.OAuthToken)
+
+	if user.WebAuthnEnabled() {
+		if config.AuthLocalEnabled.GetBool() || config.AuthLDAPEnabled.GetBool() {
+			ur.POST("/login/webauthn", apiv1.WebAuthnLoginOptions)
+		}
+		ur.POST("/login/passkey/options", apiv1.PasskeyLoginOptions)
+		ur.POST("/login/passkey", apiv1.LoginWithPasskey)
+	}
+
 	// Testing
 	if config.ServiceTestingtoken.GetString() != "" {
 		n.PATCH("/test/:table", apiv1.HandleTesting)
@@ -307,6 +336,9 @@ func registerAPIRoutes(a *echo.Group) {
 	u.POST("/password", apiv1.UserChangePassword)
 	u.GET("s", apiv1.UserList)
 	u.POST("/token", apiv1.RenewToken)
+	u.GET("/sessions", apiv1.GetUserSessions)
+	u.DELETE("/sessions", apiv1.DeleteAllUserSessions)
+	u.DELETE("/sessions/:session", apiv1.DeleteUserSession)
 	u.POST("/settings/email", apiv1.UpdateUserEmail)
 	u.GET("/settings/avatar", apiv1.GetUserAvatarProvider)
 	u.POST("/settings/avatar", apiv1.ChangeUserAvatarProvider)
@@ -314,6 +346,11 @@ func registerAPIRoutes(a *echo.Group) {
 	u.POST("/settings/general", apiv1.UpdateGeneralUserSettings)
 	u.POST("/export/request", apiv1.RequestUserDataExport)
 	u.POST("/export/download", apiv1.DownloadUserDataExport)
+	u.GET("/export/schedule", apiv1.GetUserExportSchedule)
+	u.POST("/export/schedule", apiv1.UpdateUserExportSchedule)
+	u.DELETE("/export/schedule", apiv1.DeleteUserExportSchedule)
+	u.GET("/export/backups", apiv1.GetUserExportBackups)
+	u.POST("/export/backups/:backup/download", apiv1.DownloadUserExportBackup)
 	u.GET("/timezones", apiv1.GetAvailableTimezones)
 	u.PUT("/settings/token/caldav", apiv1.GenerateCaldavToken)
 	u.GET("/settings/token/caldav", apiv1.GetCaldavTokens)
@@ -327,6 +364,20 @@ func registerAPIRoutes(a *echo.Group) {
 		u.GET("/settings/totp/qrcode", apiv1.UserTOTPQrCode)
 	}
 
+	if user.WebAuthnEnabled() {
+		u.GET("/settings/webauthn", apiv1.GetUserWebAuthnCredentials)
+		u.POST("/settings/webauthn/register/begin", apiv1.BeginUserWebAuthnRegistration)
+		u.POST("/settings/webauthn/register/finish", apiv1.FinishUserWebAuthnRegistration)
+		u.POST("/settings/webauthn/:credential", apiv1.UpdateUserWebAuthnCredential)
+		u.DELETE("/settings/webauthn/:credential", apiv1.DeleteUserWebAuthnCredential)
+	}
+
+	u.GET("/settings/recovery-codes", apiv1.GetUserRecoveryCodesStatus)
+	u.POST("/settings/recovery-codes", apiv1.GenerateUserRecoveryCodes)
+	u.GET("/audit-log", apiv1.GetUserAuditLog)
+
+	a.POST("/admin/users/:user/reset-2fa", apiv1.AdminResetUserTwoFactor)
+
 	// User deletion
 	if config.ServiceEnableUserDeletion.GetBool() {
 		u.POST("/deletion/request", apiv1.UserRequestDeletion)
@@ -345,6 +396,10 @@ func registerAPIRoutes(a *echo.Group) {
 	a.DELETE("/projects/:project", projectHandler.DeleteWeb)
 	a.PUT("/projects", projectHandler.CreateWeb)
 	a.GET("/projects/:project/projectusers", apiv1.ListUsersForProject)
+	a.GET("/projects/:project/export", apiv1.ExportProject)
+	a.POST("/projects/:project/export", apiv1.RequestProjectExport)
+	a.GET("/projects/:project/export/:export", apiv1.DownloadProjectExport)
+	a.GET("/projects/:project/export/tree", apiv1.ExportProjectTree)
 
 	if config.ServiceEnableLinkSharing.GetBool() {
 		projectSharingHandler := &handler.WebHandler{
@@ -356,6 +411,13 @@ func registerAPIRoutes(a *echo.Group) {
 		a.GET("/projects/:project/shares", projectSharingHandler.ReadAllWeb)
 		a.GET("/projects/:project/shares/:share", projectSharingHandler.ReadOneWeb)
 		a.DELETE("/projects/:project/shares/:share", projectSharingHandler.DeleteWeb)
+
+		linkShareAccessHandler := &handler.WebHandler{
+			EmptyStruct: func() handler.CObject {
+				return &models.LinkShareAccess{}
+			},
+		}
+		a.GET("/projects/:project/shares/:share/access-log", linkShareAccessHandler.ReadAllWeb)
 	}
 
 	taskCollectionHandler := &handler.WebHandler{
@@ -401,6 +463,24 @@ func registerAPIRoutes(a *echo.Group) {
 	}
 	a.POST("/tasks/:task/position", taskPositionHandler.UpdateWeb)
 
+	taskChecklistItemHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskChecklistItem{}
+		},
+	}
+	a.GET("/tasks/:task/checklist", taskChecklistItemHandler.ReadAllWeb)
+	a.PUT("/tasks/:task/checklist", taskChecklistItemHandler.CreateWeb)
+	a.GET("/tasks/:task/checklist/:checklistitem", taskChecklistItemHandler.ReadOneWeb)
+	a.POST("/tasks/:task/checklist/:checklistitem", taskChecklistItemHandler.UpdateWeb)
+	a.DELETE("/tasks/:task/checklist/:checklistitem", taskChecklistItemHandler.DeleteWeb)
+
+	taskChecklistItemPositionHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.TaskChecklistItemPosition{}
+		},
+	}
+	a.POST("/tasks/:task/checklist/:checklistitem/position", taskChecklistItemPositionHandler.UpdateWeb)
+
 	bulkTaskHandler := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
 			return &models.BulkTask{}
@@ -591,6 +671,51 @@ func registerAPIRoutes(a *echo.Group) {
 	a.GET("/tokens", apiTokenProvider.ReadAllWeb)
 	a.PUT("/tokens", apiTokenProvider.CreateWeb)
 	a.DELETE("/tokens/:token", apiTokenProvider.DeleteWeb)
+	a.POST("/tokens/:token/rotate", apiv1.RotateAPIToken)
+
+	// OAuth
+	a.GET("/oauth/authorize", apiv1.GetOAuthConsent)
+	a.POST("/oauth/authorize", apiv1.AuthorizeOAuthClient)
+
+	oauthClientHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.OAuthClient{}
+		},
+	}
+	a.GET("/oauth/clients", oauthClientHandler.ReadAllWeb)
+	a.GET("/oauth/clients/:client", oauthClientHandler.ReadOneWeb)
+	a.PUT("/oauth/clients", oauthClientHandler.CreateWeb)
+	a.POST("/oauth/clients/:client", oauthClientHandler.UpdateWeb)
+	a.DELETE("/oauth/clients/:client", oauthClientHandler.DeleteWeb)
+
+	authorizedAppHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.OAuthAuthorization{}
+		},
+	}
+	u.GET("/authorized-apps", authorizedAppHandler.ReadAllWeb)
+	u.DELETE("/authorized-apps/:app", authorizedAppHandler.DeleteWeb)
+
+	// Service accounts
+	serviceAccountHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ServiceAccount{}
+		},
+	}
+	a.GET("/service-accounts", serviceAccountHandler.ReadAllWeb)
+	a.GET("/service-accounts/:serviceaccount", serviceAccountHandler.ReadOneWeb)
+	a.PUT("/service-accounts", serviceAccountHandler.CreateWeb)
+	a.POST("/service-accounts/:serviceaccount", serviceAccountHandler.UpdateWeb)
+	a.DELETE("/service-accounts/:serviceaccount", serviceAccountHandler.DeleteWeb)
+
+	serviceAccountTokenHandler := &handler.WebHandler{
+		EmptyStruct: func() handler.CObject {
+			return &models.ServiceAccountToken{}
+		},
+	}
+	a.GET("/service-accounts/:serviceaccount/tokens", serviceAccountTokenHandler.ReadAllWeb)
+	a.PUT("/service-accounts/:serviceaccount/tokens", serviceAccountTokenHandler.CreateWeb)
+	a.DELETE("/service-accounts/:serviceaccount/tokens/:token", serviceAccountTokenHandler.DeleteWeb)
 
 	// Webhooks
 	if config.WebhooksEnabled.GetBool() {
@@ -606,6 +731,27 @@ func registerAPIRoutes(a *echo.Group) {
 		a.GET("/webhooks/events", apiv1.GetAvailableWebhookEvents)
 	}
 
+	// Automations
+	if config.AutomationsEnabled.GetBool() {
+		automationProvider := &handler.WebHandler{
+			EmptyStruct: func() handler.CObject {
+				return &models.ProjectAutomation{}
+			},
+		}
+		a.GET("/projects/:project/automations", automationProvider.ReadAllWeb)
+		a.PUT("/projects/:project/automations", automationProvider.CreateWeb)
+		a.GET("/projects/:project/automations/:automation", automationProvider.ReadOneWeb)
+		a.POST("/projects/:project/automations/:automation", automationProvider.UpdateWeb)
+		a.DELETE("/projects/:project/automations/:automation", automationProvider.DeleteWeb)
+		automationLogProvider := &handler.WebHandler{
+			EmptyStruct: func() handler.CObject {
+				return &models.ProjectAutomationLog{}
+			},
+		}
+		a.GET("/projects/:project/automations/:automation/logs", automationLogProvider.ReadAllWeb)
+		a.GET("/automations/events", apiv1.GetAvailableAutomationEvents)
+	}
+
 	// Reactions
 	reactionProvider := &handler.WebHandler{
 		EmptyStruct: func() handler.CObject {
@@ -646,6 +792,15 @@ func registerMigrations(m *echo.Group) {
 			},
 		}
 		todoistMigrationHandler.RegisterMigrator(m)
+
+		if config.MigrationSyncEnable.GetBool() {
+			todoistSyncHandler := &migrationHandler.SyncWeb{
+				SyncStruct: func() migration.Syncer {
+					return &todoist.Migration{}
+				},
+			}
+			todoistSyncHandler.RegisterRoutes(m)
+		}
 	}
 
 	// Trello
@@ -666,6 +821,15 @@ func registerMigrations(m *echo.Group) {
 			},
 		}
 		microsoftTodoMigrationHandler.RegisterMigrator(m)
+
+		if config.MigrationSyncEnable.GetBool() {
+			microsoftTodoSyncHandler := &migrationHandler.SyncWeb{
+				SyncStruct: func() migration.Syncer {
+					return &microsofttodo.Migration{}
+				},
+			}
+			microsoftTodoSyncHandler.RegisterRoutes(m)
+		}
 	}
 
 	// Vikunja File Migrator
@@ -683,6 +847,80 @@ func registerMigrations(m *echo.Group) {
 		},
 	}
 	tickTickFileMigrator.RegisterRoutes(m)
+
+	// Generic csv and json File Migrator
+	genericFileMigrator := migrationHandler.FileMigratorWeb{
+		MigrationStruct: func() migration.FileMigrator {
+			return &generic.FileMigrator{}
+		},
+	}
+	genericFileMigrator.RegisterRoutes(m)
+
+	// Jira File Migrator
+	jiraFileMigrator := migrationHandler.FileMigratorWeb{
+		MigrationStruct: func() migration.FileMigrator {
+			return &jira.FileMigrator{}
+		},
+	}
+	jiraFileMigrator.RegisterRoutes(m)
+
+	// GitHub Issues File Migrator
+	githubFileMigrator := migrationHandler.FileMigratorWeb{
+		MigrationStruct: func() migration.FileMigrator {
+			return &github.FileMigrator{}
+		},
+	}
+	githubFileMigrator.RegisterRoutes(m)
+
+	// Asana File Migrator
+	asanaFileMigrator := migrationHandler.FileMigratorWeb{
+		MigrationStruct: func() migration.FileMigrator {
+			return &asana.FileMigrator{}
+		},
+	}
+	asanaFileMigrator.RegisterRoutes(m)
+
+	// ClickUp File Migrator
+	clickUpFileMigrator := migrationHandler.FileMigratorWeb{
+		MigrationStruct: func() migration.FileMigrator {
+			return &clickup.FileMigrator{}
+		},
+	}
+	clickUpFileMigrator.RegisterRoutes(m)
+
+	// Wekan File Migrator
+	wekanFileMigrator := migrationHandler.FileMigratorWeb{
+		MigrationStruct: func() migration.FileMigrator {
+			return &wekan.FileMigrator{}
+		},
+	}
+	wekanFileMigrator.RegisterRoutes(m)
+}
+
+func registerSCIMRoutes(sc *echo.Group) {
+	if config.AuthSCIMToken.GetString() == "" {
+		log.Warning("SCIM is enabled but no token is configured, the scim endpoints will not be available.")
+		return
+	}
+
+	sc.Use(middleware.KeyAuthWithConfig(middleware.KeyAuthConfig{
+		Validator:    scim.ValidateToken,
+		ErrorHandler: scim.HandleAuthError,
+	}))
+
+	sc.GET("/Users", scim.GetUsers)
+	sc.POST("/Users", scim.CreateUser)
+	sc.GET("/Users/:id", scim.GetUser)
+	sc.PUT("/Users/:id", scim.ReplaceUser)
+	sc.PATCH("/Users/:id", scim.PatchUser)
+	sc.DELETE("/Users/:id", scim.DeleteUser)
+
+	sc.GET("/Groups", scim.GetGroups)
+	sc.POST("/Groups", scim.CreateGroup)
+	sc.GET("/Groups/:id", scim.GetGroup)
+	sc.PUT("/Groups/:id", scim.ReplaceGroup)
+	sc.PATCH("/Groups/:id", scim.PatchGroup)
+	sc.DELETE("/Groups/:id", scim.DeleteGroup)
 }
 
 func registerCalDavRoutes(c *echo.Group) {
@@ -0,0 +1,77 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"time"
+
+	"xorm.io/xorm"
+)
+
+// AuditAction is a security relevant change to a user account which is recorded in the audit log.
+type AuditAction string
+
+// All actions recorded in the audit log
+const (
+	AuditActionWebAuthnRegistered     AuditAction = "webauthn.registered"
+	AuditActionWebAuthnRemoved        AuditAction = "webauthn.removed"
+	AuditActionRecoveryCodesGenerated AuditAction = "recovery_codes.generated"
+	AuditActionRecoveryCodeUsed       AuditAction = "recovery_codes.used"
+	AuditActionTOTPEnabled            AuditAction = "totp.enabled"
+	AuditActionTOTPDisabled           AuditAction = "totp.disabled"
+	AuditActionTwoFactorReset         AuditAction = "two_factor.reset"
+)
+
+// AuditLogEntry records a security relevant change to a user account, like adding or removing a second factor.
+type AuditLogEntry struct {
+	// The unique, numeric id of this entry.
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id"`
+	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// What was changed.
+	Action AuditAction `xorm:"varchar(100) not null" json:"action"`
+	// Details about the change, for example the name of the security key which was added.
+	Details string `xorm:"text null" json:"details"`
+	// The ip address the change was made from. Empty if the change was made from the command line.
+	IPAddress string `xorm:"varchar(100) null" json:"ip_address"`
+	// The user agent of the device the change was made with.
+	UserAgent string `xorm:"text null" json:"user_agent"`
+
+	// A timestamp when this entry was created. You cannot change this value.
+	Created time.Time `xorm:"created not null INDEX" json:"created"`
+}
+
+// TableName returns the table name for audit log entries
+func (*AuditLogEntry) TableName() string {
+	return "user_audit_log"
+}
+
+// AddAuditLogEntry records a change to a user account.
+func AddAuditLogEntry(s *xorm.Session, entry *AuditLogEntry) (err error) {
+	entry.ID = 0
+	_, err = s.Insert(entry)
+	return
+}
+
+// GetAuditLog returns the audit log of a user, the newest entries first.
+func GetAuditLog(s *xorm.Session, u *User) (entries []*AuditLogEntry, err error) {
+	entries = []*AuditLogEntry{}
+	err = s.
+		Where("user_id = ?", u.ID).
+		OrderBy("created DESC, id DESC").
+		Find(&entries)
+	return
+}
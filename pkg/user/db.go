@@ -22,5 +22,9 @@ func GetTables() []interface{} {
 		&User{},
 		&TOTP{},
 		&Token{},
+		&Session{},
+		&WebAuthnCredential{},
+		&RecoveryCode{},
+		&AuditLogEntry{},
 	}
 }
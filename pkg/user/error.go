@@ -532,3 +532,217 @@ func (err *ErrUsernameMustNotContainSpaces) HTTPError() web.HTTPError {
 		Message:  "The username must not contain spaces.",
 	}
 }
+
+// ErrSessionDoesNotExist represents a "SessionDoesNotExist" kind of error.
+type ErrSessionDoesNotExist struct {
+	SessionID int64
+}
+
+// IsErrSessionDoesNotExist checks if an error is a ErrSessionDoesNotExist.
+func IsErrSessionDoesNotExist(err error) bool {
+	_, ok := err.(*ErrSessionDoesNotExist)
+	return ok
+}
+
+func (err *ErrSessionDoesNotExist) Error() string {
+	return fmt.Sprintf("Session does not exist [SessionID: %d]", err.SessionID)
+}
+
+// ErrCodeSessionDoesNotExist holds the unique world-error code of this error
+const ErrCodeSessionDoesNotExist = 1023
+
+// HTTPError holds the http error description
+func (err *ErrSessionDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeSessionDoesNotExist,
+		Message:  "The session does not exist or has expired.",
+	}
+}
+
+// ErrInvalidRefreshToken represents a "InvalidRefreshToken" kind of error.
+type ErrInvalidRefreshToken struct{}
+
+// IsErrInvalidRefreshToken checks if an error is a ErrInvalidRefreshToken.
+func IsErrInvalidRefreshToken(err error) bool {
+	_, ok := err.(*ErrInvalidRefreshToken)
+	return ok
+}
+
+func (err *ErrInvalidRefreshToken) Error() string {
+	return "Invalid refresh token"
+}
+
+// ErrCodeInvalidRefreshToken holds the unique world-error code of this error
+const ErrCodeInvalidRefreshToken = 1024
+
+// HTTPError holds the http error description
+func (err *ErrInvalidRefreshToken) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusUnauthorized,
+		Code:     ErrCodeInvalidRefreshToken,
+		Message:  "The refresh token is invalid or has expired. Please log in again.",
+	}
+}
+
+// ErrWebAuthnCredentialDoesNotExist represents a "WebAuthnCredentialDoesNotExist" kind of error.
+type ErrWebAuthnCredentialDoesNotExist struct {
+	CredentialID int64
+}
+
+// IsErrWebAuthnCredentialDoesNotExist checks if an error is a ErrWebAuthnCredentialDoesNotExist.
+func IsErrWebAuthnCredentialDoesNotExist(err error) bool {
+	_, ok := err.(*ErrWebAuthnCredentialDoesNotExist)
+	return ok
+}
+
+func (err *ErrWebAuthnCredentialDoesNotExist) Error() string {
+	return fmt.Sprintf("WebAuthn credential does not exist [CredentialID: %d]", err.CredentialID)
+}
+
+// ErrCodeWebAuthnCredentialDoesNotExist holds the unique world-error code of this error
+const ErrCodeWebAuthnCredentialDoesNotExist = 1025
+
+// HTTPError holds the http error description
+func (err *ErrWebAuthnCredentialDoesNotExist) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusNotFound,
+		Code:     ErrCodeWebAuthnCredentialDoesNotExist,
+		Message:  "The security key does not exist.",
+	}
+}
+
+// ErrInvalidWebAuthnResponse represents a "InvalidWebAuthnResponse" kind of error.
+type ErrInvalidWebAuthnResponse struct {
+	Reason string
+}
+
+// IsErrInvalidWebAuthnResponse checks if an error is a ErrInvalidWebAuthnResponse.
+func IsErrInvalidWebAuthnResponse(err error) bool {
+	_, ok := err.(*ErrInvalidWebAuthnResponse)
+	return ok
+}
+
+func (err *ErrInvalidWebAuthnResponse) Error() string {
+	return fmt.Sprintf("Invalid WebAuthn response [Reason: %s]", err.Reason)
+}
+
+// ErrCodeInvalidWebAuthnResponse holds the unique world-error code of this error
+const ErrCodeInvalidWebAuthnResponse = 1026
+
+// HTTPError holds the http error description
+func (err *ErrInvalidWebAuthnResponse) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeInvalidWebAuthnResponse,
+		Message:  "The security key response is invalid or has expired: " + err.Reason,
+	}
+}
+
+// ErrWebAuthnRequired represents a "WebAuthnRequired" kind of error.
+type ErrWebAuthnRequired struct {
+	UserID int64
+}
+
+// IsErrWebAuthnRequired checks if an error is a ErrWebAuthnRequired.
+func IsErrWebAuthnRequired(err error) bool {
+	_, ok := err.(*ErrWebAuthnRequired)
+	return ok
+}
+
+func (err *ErrWebAuthnRequired) Error() string {
+	return fmt.Sprintf("WebAuthn is required to log in [UserID: %d]", err.UserID)
+}
+
+// ErrCodeWebAuthnRequired holds the unique world-error code of this error
+const ErrCodeWebAuthnRequired = 1027
+
+// HTTPError holds the http error description
+func (err *ErrWebAuthnRequired) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeWebAuthnRequired,
+		Message:  "Please confirm the login with one of your security keys or use a recovery code.",
+	}
+}
+
+// ErrInvalidRecoveryCode represents a "InvalidRecoveryCode" kind of error.
+type ErrInvalidRecoveryCode struct {
+	UserID int64
+}
+
+// IsErrInvalidRecoveryCode checks if an error is a ErrInvalidRecoveryCode.
+func IsErrInvalidRecoveryCode(err error) bool {
+	_, ok := err.(*ErrInvalidRecoveryCode)
+	return ok
+}
+
+func (err *ErrInvalidRecoveryCode) Error() string {
+	return fmt.Sprintf("Invalid recovery code [UserID: %d]", err.UserID)
+}
+
+// ErrCodeInvalidRecoveryCode holds the unique world-error code of this error
+const ErrCodeInvalidRecoveryCode = 1028
+
+// HTTPError holds the http error description
+func (err *ErrInvalidRecoveryCode) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusPreconditionFailed,
+		Code:     ErrCodeInvalidRecoveryCode,
+		Message:  "The recovery code is invalid or was already used.",
+	}
+}
+
+// ErrUserIsNotAdmin represents a "UserIsNotAdmin" kind of error.
+type ErrUserIsNotAdmin struct {
+	UserID int64
+}
+
+// IsErrUserIsNotAdmin checks if an error is a ErrUserIsNotAdmin.
+func IsErrUserIsNotAdmin(err error) bool {
+	_, ok := err.(*ErrUserIsNotAdmin)
+	return ok
+}
+
+func (err *ErrUserIsNotAdmin) Error() string {
+	return fmt.Sprintf("User is not an instance admin [UserID: %d]", err.UserID)
+}
+
+// ErrCodeUserIsNotAdmin holds the unique world-error code of this error
+const ErrCodeUserIsNotAdmin = 1029
+
+// HTTPError holds the http error description
+func (err *ErrUserIsNotAdmin) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeUserIsNotAdmin,
+		Message:  "Only instance admins can do this.",
+	}
+}
+
+// ErrRecentLoginRequired represents a "RecentLoginRequired" kind of error.
+type ErrRecentLoginRequired struct {
+	UserID int64
+}
+
+// IsErrRecentLoginRequired checks if an error is a ErrRecentLoginRequired.
+func IsErrRecentLoginRequired(err error) bool {
+	_, ok := err.(*ErrRecentLoginRequired)
+	return ok
+}
+
+func (err *ErrRecentLoginRequired) Error() string {
+	return fmt.Sprintf("User needs to log in again to confirm the change [UserID: %d]", err.UserID)
+}
+
+// ErrCodeRecentLoginRequired holds the unique world-error code of this error
+const ErrCodeRecentLoginRequired = 1030
+
+// HTTPError holds the http error description
+func (err *ErrRecentLoginRequired) HTTPError() web.HTTPError {
+	return web.HTTPError{
+		HTTPCode: http.StatusForbidden,
+		Code:     ErrCodeRecentLoginRequired,
+		Message:  "Please log in again to confirm this change.",
+	}
+}
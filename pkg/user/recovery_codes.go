@@ -0,0 +1,134 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.vikunja.io/api/pkg/utils"
+
+	"xorm.io/xorm"
+)
+
+const (
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+)
+
+// RecoveryCode is a single use code a user can log in with instead of their second factor,
+// in case they lost access to their totp device or security keys.
+type RecoveryCode struct {
+	ID       int64     `xorm:"bigint autoincr not null unique pk" json:"-"`
+	UserID   int64     `xorm:"bigint not null INDEX" json:"-"`
+	CodeHash string    `xorm:"varchar(64) not null" json:"-"`
+	Created  time.Time `xorm:"created not null" json:"-"`
+}
+
+// TableName returns the table name for recovery codes
+func (*RecoveryCode) TableName() string {
+	return "user_recovery_codes"
+}
+
+// RecoveryCodes holds newly generated recovery codes. They are only shown once.
+type RecoveryCodes struct {
+	Codes []string `json:"codes"`
+}
+
+// RecoveryCodesStatus holds how many unused recovery codes a user has left.
+type RecoveryCodesStatus struct {
+	Remaining int64 `json:"remaining"`
+}
+
+// normalizeRecoveryCode makes sure a recovery code matches no matter how the user typed it.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
+
+func hashRecoveryCode(userID int64, code string) string {
+	// Recovery codes are random, so a fast hash is enough. The user id is added so that the same code
+	// does not result in the same hash for two users.
+	hash := sha256.Sum256([]byte(strconv.FormatInt(userID, 10) + "." + normalizeRecoveryCode(code)))
+	return hex.EncodeToString(hash[:])
+}
+
+// GenerateRecoveryCodes creates new recovery codes for a user, replacing all existing ones.
+func GenerateRecoveryCodes(s *xorm.Session, u *User) (codes *RecoveryCodes, err error) {
+	err = DeleteRecoveryCodes(s, u)
+	if err != nil {
+		return nil, err
+	}
+
+	codes = &RecoveryCodes{Codes: make([]string, 0, recoveryCodeCount)}
+	rows := make([]*RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.CryptoRandomString(recoveryCodeLength)
+		if err != nil {
+			return nil, err
+		}
+		code = strings.ToLower(code)
+		code = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+
+		codes.Codes = append(codes.Codes, code)
+		rows = append(rows, &RecoveryCode{
+			UserID:   u.ID,
+			CodeHash: hashRecoveryCode(u.ID, code),
+		})
+	}
+
+	_, err = s.Insert(&rows)
+	return codes, err
+}
+
+// GetRecoveryCodesStatus returns how many unused recovery codes a user has.
+func GetRecoveryCodesStatus(s *xorm.Session, u *User) (status *RecoveryCodesStatus, err error) {
+	count, err := s.Where("user_id = ?", u.ID).Count(&RecoveryCode{})
+	if err != nil {
+		return nil, err
+	}
+	return &RecoveryCodesStatus{Remaining: count}, nil
+}
+
+// UseRecoveryCode checks a recovery code of a user and removes it so that it can't be used again.
+func UseRecoveryCode(s *xorm.Session, u *User, code string) (err error) {
+	if normalizeRecoveryCode(code) == "" {
+		return &ErrInvalidRecoveryCode{UserID: u.ID}
+	}
+
+	deleted, err := s.
+		Where("user_id = ? AND code_hash = ?", u.ID, hashRecoveryCode(u.ID, code)).
+		Delete(&RecoveryCode{})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ErrInvalidRecoveryCode{UserID: u.ID}
+	}
+
+	return nil
+}
+
+// DeleteRecoveryCodes removes all recovery codes of a user.
+func DeleteRecoveryCodes(s *xorm.Session, u *User) (err error) {
+	_, err = s.Where("user_id = ?", u.ID).Delete(&RecoveryCode{})
+	return
+}
@@ -0,0 +1,100 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+	u := &User{ID: 15}
+
+	codes, err := GenerateRecoveryCodes(s, u)
+	require.NoError(t, err)
+	assert.Len(t, codes.Codes, recoveryCodeCount)
+
+	status, err := GetRecoveryCodesStatus(s, u)
+	require.NoError(t, err)
+	assert.Equal(t, int64(recoveryCodeCount), status.Remaining)
+
+	// The old codes should not work anymore
+	err = UseRecoveryCode(s, u, "abcde-12345")
+	require.Error(t, err)
+	assert.True(t, IsErrInvalidRecoveryCode(err))
+
+	err = UseRecoveryCode(s, u, codes.Codes[0])
+	require.NoError(t, err)
+}
+
+func TestUseRecoveryCode(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UseRecoveryCode(s, &User{ID: 15}, "abcde-12345")
+		require.NoError(t, err)
+		db.AssertMissing(t, "user_recovery_codes", map[string]interface{}{
+			"id": 1,
+		})
+	})
+	t.Run("different formatting", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UseRecoveryCode(s, &User{ID: 15}, " ABCDE 12345")
+		require.NoError(t, err)
+	})
+	t.Run("used twice", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UseRecoveryCode(s, &User{ID: 15}, "abcde-12345")
+		require.NoError(t, err)
+		err = UseRecoveryCode(s, &User{ID: 15}, "abcde-12345")
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidRecoveryCode(err))
+	})
+	t.Run("code of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UseRecoveryCode(s, &User{ID: 1}, "abcde-12345")
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidRecoveryCode(err))
+	})
+	t.Run("empty", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UseRecoveryCode(s, &User{ID: 15}, "")
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidRecoveryCode(err))
+	})
+}
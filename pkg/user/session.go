@@ -0,0 +1,260 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/cron"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/log"
+	"code.vikunja.io/api/pkg/utils"
+
+	"xorm.io/xorm"
+)
+
+const (
+	refreshTokenLength = 64
+	// sessionActivityUpdateInterval is how often the last activity of a session is saved at most.
+	sessionActivityUpdateInterval = time.Minute
+	// recentLoginInterval is how long after logging in users without a password can make security relevant changes.
+	recentLoginInterval = 10 * time.Minute
+)
+
+// Session is a login of a user on one device. All jwt tokens of a user belong to a session and are only valid
+// as long as the session exists, which makes it possible to log out a device.
+type Session struct {
+	// The unique, numeric id of this session.
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"session"`
+	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// The refresh token is only returned once when creating the session or refreshing it.
+	RefreshToken     string `xorm:"-" json:"-"`
+	RefreshTokenHash string `xorm:"varchar(64) not null unique" json:"-"`
+	// The user agent of the device which created the session.
+	DeviceInfo string `xorm:"text null" json:"device_info"`
+	// The ip address the session was last used from.
+	IPAddress string `xorm:"varchar(100) null" json:"ip_address"`
+	// Whether this session was created with "stay logged in" and is valid for longer.
+	IsLongSession bool `xorm:"not null default false" json:"is_long_session"`
+	// Whether this is the session the request listing the sessions was made with.
+	IsCurrent bool `xorm:"-" json:"is_current"`
+
+	// The last time this session was used.
+	LastActive time.Time `xorm:"not null INDEX" json:"last_active"`
+	// A timestamp when this session was created. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// RefreshToken holds a refresh token which should be exchanged for a new token.
+type RefreshToken struct {
+	// The refresh token returned when logging in or the last time the token was refreshed.
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TableName returns the table name for sessions
+func (*Session) TableName() string {
+	return "sessions"
+}
+
+func hashRefreshToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+// sessionTTL returns how long a session stays valid after it was last used.
+func (sess *Session) sessionTTL() time.Duration {
+	if sess.IsLongSession {
+		return time.Duration(config.ServiceJWTTTLLong.GetInt64()) * time.Second
+	}
+	return time.Duration(config.ServiceJWTTTL.GetInt64()) * time.Second
+}
+
+// IsExpired checks if a session was not used for longer than it is valid.
+func (sess *Session) IsExpired() bool {
+	return time.Since(sess.LastActive) > sess.sessionTTL()
+}
+
+func (sess *Session) generateRefreshToken() {
+	sess.RefreshToken = utils.MakeRandomString(refreshTokenLength)
+	sess.RefreshTokenHash = hashRefreshToken(sess.RefreshToken)
+}
+
+// CreateSession creates a new session for a user logging in.
+func CreateSession(s *xorm.Session, u *User, deviceInfo, ipAddress string, long bool) (sess *Session, err error) {
+	sess = &Session{
+		UserID:        u.ID,
+		DeviceInfo:    deviceInfo,
+		IPAddress:     ipAddress,
+		IsLongSession: long,
+		LastActive:    time.Now(),
+	}
+	sess.generateRefreshToken()
+
+	_, err = s.Insert(sess)
+	return
+}
+
+// CheckRecentLogin makes sure the session a request was made with belongs to the user and was created by logging
+// in a short time ago. It is used to confirm security relevant changes of users who don't have a password.
+func CheckRecentLogin(s *xorm.Session, u *User, sessionID int64) error {
+	if sessionID == 0 {
+		return &ErrRecentLoginRequired{UserID: u.ID}
+	}
+
+	sess, err := GetSessionByID(s, sessionID)
+	if err != nil {
+		if IsErrSessionDoesNotExist(err) {
+			return &ErrRecentLoginRequired{UserID: u.ID}
+		}
+		return err
+	}
+
+	if sess.UserID != u.ID || time.Since(sess.Created) > recentLoginInterval {
+		return &ErrRecentLoginRequired{UserID: u.ID}
+	}
+
+	return nil
+}
+
+// GetSessionByID returns a session which is still valid.
+func GetSessionByID(s *xorm.Session, id int64) (sess *Session, err error) {
+	sess = &Session{}
+	has, err := s.Where("id = ?", id).Get(sess)
+	if err != nil {
+		return nil, err
+	}
+	if !has || sess.IsExpired() {
+		return nil, &ErrSessionDoesNotExist{SessionID: id}
+	}
+
+	return sess, nil
+}
+
+// RefreshSession exchanges a refresh token for a new one and marks the session as active.
+// Each refresh token can only be used once.
+func RefreshSession(s *xorm.Session, refreshToken, ipAddress string) (sess *Session, err error) {
+	sess = &Session{}
+	has, err := s.Where("refresh_token_hash = ?", hashRefreshToken(refreshToken)).Get(sess)
+	if err != nil {
+		return nil, err
+	}
+	if !has || sess.IsExpired() {
+		return nil, &ErrInvalidRefreshToken{}
+	}
+
+	sess.generateRefreshToken()
+	sess.IPAddress = ipAddress
+	sess.LastActive = time.Now()
+	_, err = s.
+		Where("id = ?", sess.ID).
+		Cols("refresh_token_hash", "ip_address", "last_active").
+		Update(sess)
+	return
+}
+
+// UpdateSessionActivity saves when and from where a session was last used. To avoid writing to the database
+// on every request, this only happens once per minute.
+func UpdateSessionActivity(s *xorm.Session, sess *Session, ipAddress string) (err error) {
+	if time.Since(sess.LastActive) < sessionActivityUpdateInterval && sess.IPAddress == ipAddress {
+		return nil
+	}
+
+	sess.IPAddress = ipAddress
+	sess.LastActive = time.Now()
+	_, err = s.
+		Where("id = ?", sess.ID).
+		Cols("ip_address", "last_active").
+		Update(sess)
+	return
+}
+
+// GetSessionsForUser returns all valid sessions of a user, the most recently used first.
+func GetSessionsForUser(s *xorm.Session, u *User) (sessions []*Session, err error) {
+	all := []*Session{}
+	err = s.
+		Where("user_id = ?", u.ID).
+		OrderBy("last_active desc").
+		Find(&all)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions = make([]*Session, 0, len(all))
+	for _, sess := range all {
+		if !sess.IsExpired() {
+			sessions = append(sessions, sess)
+		}
+	}
+
+	return sessions, nil
+}
+
+// DeleteSession logs out a single session of a user.
+func DeleteSession(s *xorm.Session, u *User, id int64) (err error) {
+	deleted, err := s.
+		Where("id = ? AND user_id = ?", id, u.ID).
+		Delete(&Session{})
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return &ErrSessionDoesNotExist{SessionID: id}
+	}
+
+	return nil
+}
+
+// DeleteAllSessions logs out a user on all devices, except the session with the id passed as exceptID.
+// Pass 0 to log out all sessions.
+func DeleteAllSessions(s *xorm.Session, userID int64, exceptID int64) (err error) {
+	_, err = s.
+		Where("user_id = ? AND id != ?", userID, exceptID).
+		Delete(&Session{})
+	return
+}
+
+// RegisterSessionCleanupCron registers a cron function to remove all expired sessions.
+func RegisterSessionCleanupCron() {
+	const logPrefix = "[User Session Cleanup Cron] "
+
+	err := cron.Schedule("30 * * * *", func() {
+		s := db.NewSession()
+		defer s.Close()
+
+		// Long sessions are valid the longest, everything older is expired regardless of the kind of session.
+		deleted, err := s.
+			Where("last_active < ? AND (is_long_session = ? OR last_active < ?)",
+				time.Now().Add(-time.Duration(config.ServiceJWTTTL.GetInt64())*time.Second),
+				false,
+				time.Now().Add(-time.Duration(config.ServiceJWTTTLLong.GetInt64())*time.Second),
+			).
+			Delete(&Session{})
+		if err != nil {
+			log.Errorf(logPrefix+"Error removing expired sessions: %s", err)
+			return
+		}
+		if deleted > 0 {
+			log.Debugf(logPrefix+"Deleted %d expired sessions", deleted)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not register session cleanup cron: %s", err)
+	}
+}
@@ -0,0 +1,226 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSession(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	sess, err := CreateSession(s, &User{ID: 1}, "test agent", "127.0.0.1", true)
+	require.NoError(t, err)
+	assert.NotEmpty(t, sess.RefreshToken)
+	assert.NotEqual(t, sess.RefreshToken, sess.RefreshTokenHash)
+
+	db.AssertExists(t, "sessions", map[string]interface{}{
+		"id":                 sess.ID,
+		"user_id":            1,
+		"refresh_token_hash": hashRefreshToken(sess.RefreshToken),
+		"device_info":        "test agent",
+		"is_long_session":    true,
+	}, false)
+}
+
+func TestGetSessionByID(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sess, err := GetSessionByID(s, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), sess.UserID)
+	})
+	t.Run("expired", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := GetSessionByID(s, 3)
+		require.Error(t, err)
+		assert.True(t, IsErrSessionDoesNotExist(err))
+	})
+	t.Run("nonexistent", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := GetSessionByID(s, 9999)
+		require.Error(t, err)
+		assert.True(t, IsErrSessionDoesNotExist(err))
+	})
+}
+
+func TestCheckRecentLogin(t *testing.T) {
+	t.Run("recent login", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sess, err := CreateSession(s, &User{ID: 1}, "test agent", "127.0.0.1", false)
+		require.NoError(t, err)
+
+		err = CheckRecentLogin(s, &User{ID: 1}, sess.ID)
+		require.NoError(t, err)
+	})
+	t.Run("old login", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := CheckRecentLogin(s, &User{ID: 1}, 1)
+		require.Error(t, err)
+		assert.True(t, IsErrRecentLoginRequired(err))
+	})
+	t.Run("session of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sess, err := CreateSession(s, &User{ID: 2}, "test agent", "127.0.0.1", false)
+		require.NoError(t, err)
+
+		err = CheckRecentLogin(s, &User{ID: 1}, sess.ID)
+		require.Error(t, err)
+		assert.True(t, IsErrRecentLoginRequired(err))
+	})
+	t.Run("no session", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := CheckRecentLogin(s, &User{ID: 1}, 0)
+		require.Error(t, err)
+		assert.True(t, IsErrRecentLoginRequired(err))
+	})
+}
+
+func TestRefreshSession(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		sess, err := RefreshSession(s, "sessionrefreshtoken1", "10.0.0.1")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), sess.ID)
+		assert.NotEqual(t, "sessionrefreshtoken1", sess.RefreshToken)
+
+		// Refresh tokens can only be used once
+		_, err = RefreshSession(s, "sessionrefreshtoken1", "10.0.0.1")
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidRefreshToken(err))
+
+		_, err = RefreshSession(s, sess.RefreshToken, "10.0.0.1")
+		require.NoError(t, err)
+	})
+	t.Run("expired", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := RefreshSession(s, "sessionrefreshtoken3", "10.0.0.1")
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidRefreshToken(err))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := RefreshSession(s, "invalid", "10.0.0.1")
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidRefreshToken(err))
+	})
+}
+
+func TestGetSessionsForUser(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	sessions, err := GetSessionsForUser(s, &User{ID: 1})
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	for _, sess := range sessions {
+		assert.NotEqual(t, int64(3), sess.ID)
+	}
+}
+
+func TestDeleteSession(t *testing.T) {
+	t.Run("own session", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteSession(s, &User{ID: 1}, 1)
+		require.NoError(t, err)
+		db.AssertMissing(t, "sessions", map[string]interface{}{"id": 1})
+	})
+	t.Run("session of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteSession(s, &User{ID: 1}, 4)
+		require.Error(t, err)
+		assert.True(t, IsErrSessionDoesNotExist(err))
+		db.AssertExists(t, "sessions", map[string]interface{}{"id": 4}, false)
+	})
+}
+
+func TestDeleteAllSessions(t *testing.T) {
+	t.Run("except current", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := DeleteAllSessions(s, 1, 2)
+		require.NoError(t, err)
+		db.AssertMissing(t, "sessions", map[string]interface{}{"id": 1})
+		db.AssertExists(t, "sessions", map[string]interface{}{"id": 2}, false)
+		db.AssertExists(t, "sessions", map[string]interface{}{"id": 4}, false)
+	})
+	t.Run("on password change", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := UpdateUserPassword(s, &User{ID: 1}, "12345678")
+		require.NoError(t, err)
+		db.AssertMissing(t, "sessions", map[string]interface{}{"user_id": 1})
+		db.AssertExists(t, "sessions", map[string]interface{}{"id": 4}, false)
+	})
+	t.Run("on status change", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		err := SetUserStatus(s, &User{ID: 1}, StatusDisabled)
+		require.NoError(t, err)
+		db.AssertMissing(t, "sessions", map[string]interface{}{"user_id": 1})
+	})
+}
@@ -35,7 +35,7 @@ func InitTests() {
 		log.Fatal(err)
 	}
 
-	err = db.InitTestFixtures("users", "user_tokens")
+	err = db.InitTestFixtures("users", "user_tokens", "sessions", "user_webauthn_credentials", "user_recovery_codes", "user_audit_log")
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -104,7 +104,8 @@ func EnrollTOTP(s *xorm.Session, user *User) (t *TOTP, err error) {
 }
 
 // EnableTOTP enables totp for a user. The provided passcode is used to verify the user has a working totp setup.
-func EnableTOTP(s *xorm.Session, passcode *TOTPPasscode) (err error) {
+// Returns new recovery codes the user can log in with if they lose their totp device.
+func EnableTOTP(s *xorm.Session, passcode *TOTPPasscode) (codes *RecoveryCodes, err error) {
 	t, err := ValidateTOTPPasscode(s, passcode)
 	if err != nil {
 		return
@@ -114,15 +115,40 @@ func EnableTOTP(s *xorm.Session, passcode *TOTPPasscode) (err error) {
 		Where("id = ?", t.ID).
 		Cols("enabled").
 		Update(&TOTP{Enabled: true})
-	return
+	if err != nil {
+		return
+	}
+
+	codes, err = GenerateRecoveryCodes(s, passcode.User)
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, DeleteAllSessions(s, passcode.User.ID, 0)
 }
 
-// DisableTOTP removes all totp settings for a user.
+// DisableTOTP removes all totp settings for a user. The recovery codes are removed as well, unless the user
+// still has security keys they could be used for.
 func DisableTOTP(s *xorm.Session, user *User) (err error) {
 	_, err = s.
 		Where("user_id = ?", user.ID).
 		Delete(&TOTP{})
-	return
+	if err != nil {
+		return
+	}
+
+	hasSecurityKeys, err := s.Where("user_id = ?", user.ID).Exist(&WebAuthnCredential{})
+	if err != nil {
+		return
+	}
+	if !hasSecurityKeys {
+		err = DeleteRecoveryCodes(s, user)
+		if err != nil {
+			return
+		}
+	}
+
+	return DeleteAllSessions(s, user.ID, 0)
 }
 
 // ValidateTOTPPasscode validated totp codes of users.
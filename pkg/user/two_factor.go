@@ -0,0 +1,61 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"xorm.io/xorm"
+)
+
+// ResetTwoFactor removes all second factors of a user - their totp setting, security keys and recovery codes -
+// so that they can log in with only their password again. This is meant for admins to help users who lost access
+// to all of their second factors. All sessions of the user are logged out.
+// The details are recorded in the user's audit log to show who reset it.
+func ResetTwoFactor(s *xorm.Session, u *User, details string) (err error) {
+	_, err = s.Where("user_id = ?", u.ID).Delete(&TOTP{})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Where("user_id = ?", u.ID).Delete(&WebAuthnCredential{})
+	if err != nil {
+		return err
+	}
+
+	err = DeleteRecoveryCodes(s, u)
+	if err != nil {
+		return err
+	}
+
+	err = DeleteAllSessions(s, u.ID, 0)
+	if err != nil {
+		return err
+	}
+
+	return AddAuditLogEntry(s, &AuditLogEntry{
+		UserID:  u.ID,
+		Action:  AuditActionTwoFactorReset,
+		Details: details,
+	})
+}
+
+// CheckUserIsAdmin returns an error if a user is not an instance admin.
+func CheckUserIsAdmin(u *User) error {
+	if !u.IsAdmin {
+		return &ErrUserIsNotAdmin{UserID: u.ID}
+	}
+	return nil
+}
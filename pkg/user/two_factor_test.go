@@ -0,0 +1,70 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/db"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnableTOTP(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+	u := &User{ID: 1, Username: "user1"}
+
+	enrolled, err := EnrollTOTP(s, u)
+	require.NoError(t, err)
+	passcode, err := totp.GenerateCode(enrolled.Secret, time.Now())
+	require.NoError(t, err)
+
+	codes, err := EnableTOTP(s, &TOTPPasscode{User: u, Passcode: passcode})
+	require.NoError(t, err)
+	assert.Len(t, codes.Codes, recoveryCodeCount)
+
+	err = UseRecoveryCode(s, u, codes.Codes[0])
+	require.NoError(t, err)
+
+	err = DisableTOTP(s, u)
+	require.NoError(t, err)
+	status, err := GetRecoveryCodesStatus(s, u)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), status.Remaining)
+}
+
+func TestResetTwoFactor(t *testing.T) {
+	db.LoadAndAssertFixtures(t)
+	s := db.NewSession()
+	defer s.Close()
+
+	err := ResetTwoFactor(s, &User{ID: 15}, "Reset by test")
+	require.NoError(t, err)
+
+	db.AssertMissing(t, "user_webauthn_credentials", map[string]interface{}{"user_id": 15})
+	db.AssertMissing(t, "user_recovery_codes", map[string]interface{}{"user_id": 15})
+	db.AssertExists(t, "user_audit_log", map[string]interface{}{
+		"user_id": 15,
+		"action":  AuditActionTwoFactorReset,
+		"details": "Reset by test",
+	}, false)
+}
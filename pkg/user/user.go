@@ -46,6 +46,11 @@ type Login struct {
 	Password string `json:"password"`
 	// The totp passcode of a user. Only needs to be provided when enabled.
 	TOTPPasscode string `json:"totp_passcode"`
+	// The PublicKeyCredential returned by navigator.credentials.get() after starting the login at /login/webauthn.
+	// Can be provided instead of a totp passcode when the user registered a security key.
+	WebAuthn json.RawMessage `json:"webauthn,omitempty" swaggertype:"object"`
+	// A recovery code which can be used once instead of the totp passcode or a security key.
+	RecoveryCode string `json:"recovery_code,omitempty"`
 	// If true, the token returned will be valid a lot longer than default. Useful for "remember me" style logins.
 	LongToken bool `json:"long_token"`
 }
@@ -84,6 +89,12 @@ type User struct {
 	Email string `xorm:"varchar(250) null" json:"email,omitempty" valid:"email,length(0|250)" maxLength:"250"`
 
 	Status Status `xorm:"default 0" json:"-"`
+	// Instance admins can manage other users through the api.
+	IsAdmin bool `xorm:"bool default false" json:"-"`
+	// Service accounts are used by integrations. They cannot log in and only make requests with api tokens.
+	IsBot bool `xorm:"bool default false index" json:"is_bot"`
+	// The team managing a service account. If 0, the service account is managed by instance admins.
+	BotOwnerTeamID int64 `xorm:"bigint null index" json:"-"`
 
 	AvatarProvider string `xorm:"varchar(255) null" json:"-"`
 	AvatarFileID   int64  `xorm:"null" json:"-"`
@@ -114,9 +125,20 @@ type User struct {
 	// A timestamp when this task was last updated. You cannot change this value.
 	Updated time.Time `xorm:"updated not null" json:"updated"`
 
+	// Set when the current request was made with an api token which is limited to some projects or read-only.
+	APITokenScope *APITokenScope `xorm:"-" json:"-"`
+
 	web.Auth `xorm:"-" json:"-"`
 }
 
+// APITokenScope limits what a request made with an api token can access on behalf of its owner.
+type APITokenScope struct {
+	// The projects the token can access, including their child projects. If empty, all projects can be accessed.
+	ProjectIDs []int64
+	// If true, projects can only be read.
+	ReadOnly bool
+}
+
 // RouteForMail routes all notifications for a user to its email address
 func (u *User) RouteForMail() (string, error) {
 
@@ -146,7 +168,7 @@ func (u *User) ShouldNotify() (bool, error) {
 		return false, err
 	}
 
-	return user.Status != StatusDisabled, err
+	return user.Status != StatusDisabled && !user.IsBot, err
 }
 
 // GetID implements the Auth interface
@@ -552,7 +574,11 @@ func SetUserStatus(s *xorm.Session, user *User, status Status) (err error) {
 	_, err = s.Where("id = ?", user.ID).
 		Cols("status").
 		Update(&User{Status: status})
-	return
+	if err != nil {
+		return err
+	}
+
+	return DeleteAllSessions(s, user.ID, 0)
 }
 
 // UpdateUserPassword updates the password of a user
@@ -583,7 +609,8 @@ func UpdateUserPassword(s *xorm.Session, user *User, newPassword string) (err er
 		return err
 	}
 
-	return err
+	// Log out everywhere so that someone who knew the old password can't keep using the account.
+	return DeleteAllSessions(s, user.ID, 0)
 }
 
 // SetStatus sets a users status in the database
@@ -593,5 +620,19 @@ func (u *User) SetStatus(s *xorm.Session, status Status) (err error) {
 		Where("id = ?", u.ID).
 		Cols("status").
 		Update(u)
+	if err != nil {
+		return err
+	}
+
+	return DeleteAllSessions(s, u.ID, 0)
+}
+
+// SetIsAdmin makes a user an instance admin or revokes it.
+func (u *User) SetIsAdmin(s *xorm.Session, isAdmin bool) (err error) {
+	u.IsAdmin = isAdmin
+	_, err = s.
+		Where("id = ?", u.ID).
+		Cols("is_admin").
+		Update(u)
 	return
 }
@@ -28,6 +28,15 @@ import (
 
 const IssuerLocal = `local`
 
+// IssuerLDAP is the issuer of all users who log in through the ldap directory.
+const IssuerLDAP = `ldap`
+
+// IssuerSCIM is the issuer of all users provisioned via scim if no other issuer is configured.
+const IssuerSCIM = `scim`
+
+// IssuerBot is the issuer of all service accounts. They cannot log in anywhere.
+const IssuerBot = `bot`
+
 // CreateUser creates a new user and inserts it into the database
 func CreateUser(s *xorm.Session, user *User) (newUser *User, err error) {
 
@@ -116,6 +125,18 @@ func CreateUser(s *xorm.Session, user *User) (newUser *User, err error) {
 	return newUserOut, err
 }
 
+// CreateBot creates a new service account. Service accounts have no password or email address,
+// the only way to use them is through their api tokens.
+func CreateBot(s *xorm.Session, bot *User) (newBot *User, err error) {
+	bot.IsBot = true
+	bot.Issuer = IssuerBot
+	bot.Subject = bot.Username
+	bot.Password = ""
+	bot.Email = ""
+
+	return CreateUser(s, bot)
+}
+
 // HashPassword hashes a password
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 11)
@@ -123,7 +144,7 @@ func HashPassword(password string) (string, error) {
 }
 
 func checkIfUserIsValid(user *User) error {
-	if user.Email == "" ||
+	if (user.Email == "" && !user.IsBot) ||
 		(user.Issuer != IssuerLocal && user.Subject == "") ||
 		(user.Issuer == IssuerLocal && (user.Password == "" ||
 			user.Username == "")) {
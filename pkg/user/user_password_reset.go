@@ -76,6 +76,11 @@ func ResetPassword(s *xorm.Session, reset *PasswordReset) (err error) {
 		return
 	}
 
+	err = DeleteAllSessions(s, user.ID, 0)
+	if err != nil {
+		return
+	}
+
 	// Dont send a mail if no mailer is configured
 	if !config.MailerEnabled.GetBool() {
 		return
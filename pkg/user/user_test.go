@@ -147,6 +147,26 @@ func TestCreateUser(t *testing.T) {
 		require.Error(t, err)
 		assert.True(t, IsErrUsernameMustNotContainSpaces(err))
 	})
+	t.Run("bot", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		bot, err := CreateBot(s, &User{
+			Username: "newbot",
+			Password: "12345",
+			Email:    "bot@example.com",
+		})
+		require.NoError(t, err)
+		assert.True(t, bot.IsBot)
+		assert.Equal(t, IssuerBot, bot.Issuer)
+		db.AssertExists(t, "users", map[string]interface{}{
+			"username": "newbot",
+			"is_bot":   true,
+			"email":    "",
+			"password": "",
+		}, false)
+	})
 }
 
 func TestGetUser(t *testing.T) {
@@ -294,6 +314,15 @@ func TestCheckUserCredentials(t *testing.T) {
 		_, err := CheckUserCredentials(s, &Login{Username: "user1@example.com", Password: "1234"})
 		require.NoError(t, err)
 	})
+	t.Run("bot", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := CheckUserCredentials(s, &Login{Username: "bot17", Password: "1234"})
+		require.Error(t, err)
+		assert.True(t, IsErrAccountIsNotLocal(err))
+	})
 }
 
 func TestUpdateUser(t *testing.T) {
@@ -400,7 +429,7 @@ func TestListUsers(t *testing.T) {
 
 		all, err := ListAllUsers(s)
 		require.NoError(t, err)
-		assert.Len(t, all, 16)
+		assert.Len(t, all, 17)
 	})
 	t.Run("no search term", func(t *testing.T) {
 		db.LoadAndAssertFixtures(t)
@@ -0,0 +1,492 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/modules/keyvalue"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"xorm.io/xorm"
+)
+
+const (
+	webAuthnRegistrationKeyPrefix = "webauthn_registration_"
+	webAuthnLoginKeyPrefix        = "webauthn_login_"
+	webAuthnTimeout               = 5 * time.Minute
+)
+
+// WebAuthnCredential is a security key or passkey a user registered to log in with.
+type WebAuthnCredential struct {
+	// The unique, numeric id of this credential.
+	ID     int64 `xorm:"bigint autoincr not null unique pk" json:"id" param:"credential"`
+	UserID int64 `xorm:"bigint not null INDEX" json:"-"`
+	// A name for the security key so that the user can tell their keys apart.
+	Name string `xorm:"varchar(250) not null" json:"name" valid:"required,runelength(1|250)" minLength:"1" maxLength:"250"`
+
+	CredentialID    []byte                            `xorm:"blob not null" json:"-"`
+	PublicKey       []byte                            `xorm:"blob not null" json:"-"`
+	AttestationType string                            `xorm:"varchar(50) null" json:"-"`
+	Transports      []protocol.AuthenticatorTransport `xorm:"json null" json:"-"`
+	AAGUID          []byte                            `xorm:"blob null" json:"-"`
+	SignCount       uint32                            `xorm:"bigint not null default 0" json:"-"`
+	// Set if the signature counter of the key went backwards, which is a sign the key was cloned.
+	CloneWarning bool `xorm:"not null default false" json:"clone_warning"`
+	// Whether the credential can be synced between devices, like passkeys stored in a password manager.
+	BackupEligible bool `xorm:"not null default false" json:"backup_eligible"`
+	BackupState    bool `xorm:"not null default false" json:"-"`
+
+	// The last time the credential was used to log in.
+	LastUsed *time.Time `xorm:"null" json:"last_used"`
+	// A timestamp when this credential was registered. You cannot change this value.
+	Created time.Time `xorm:"created not null" json:"created"`
+}
+
+// TableName returns the table name for webauthn credentials
+func (*WebAuthnCredential) TableName() string {
+	return "user_webauthn_credentials"
+}
+
+func (c *WebAuthnCredential) toWebAuthn() webauthn.Credential {
+	return webauthn.Credential{
+		ID:              c.CredentialID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		Transport:       c.Transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: c.BackupEligible,
+			BackupState:    c.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:       c.AAGUID,
+			SignCount:    c.SignCount,
+			CloneWarning: c.CloneWarning,
+		},
+	}
+}
+
+// WebAuthnRegistration holds the response of a security key after creating a new credential.
+type WebAuthnRegistration struct {
+	// The name of the new security key.
+	Name string `json:"name"`
+	// The PublicKeyCredential returned by navigator.credentials.create().
+	Credential json.RawMessage `json:"credential" swaggertype:"object"`
+}
+
+// webAuthnUser wraps a user to be used by the webauthn library.
+type webAuthnUser struct {
+	user        *User
+	credentials []*WebAuthnCredential
+}
+
+func (w *webAuthnUser) WebAuthnID() []byte {
+	return webAuthnUserHandle(w.user.ID)
+}
+
+func (w *webAuthnUser) WebAuthnName() string {
+	return w.user.Username
+}
+
+func (w *webAuthnUser) WebAuthnDisplayName() string {
+	return w.user.GetName()
+}
+
+func (w *webAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (w *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	creds := make([]webauthn.Credential, 0, len(w.credentials))
+	for _, c := range w.credentials {
+		creds = append(creds, c.toWebAuthn())
+	}
+	return creds
+}
+
+func (w *webAuthnUser) credentialDescriptors() []protocol.CredentialDescriptor {
+	descriptors := make([]protocol.CredentialDescriptor, 0, len(w.credentials))
+	for _, c := range w.credentials {
+		descriptors = append(descriptors, c.toWebAuthn().Descriptor())
+	}
+	return descriptors
+}
+
+// webAuthnUserHandle returns the opaque id security keys store for a user. It is returned when logging in
+// with a passkey to find the user.
+func webAuthnUserHandle(userID int64) []byte {
+	return []byte(strconv.FormatInt(userID, 10))
+}
+
+// WebAuthnEnabled checks if security keys can be used on this instance. They are bound to the domain
+// Vikunja runs on, which is why this needs the public url.
+func WebAuthnEnabled() bool {
+	return config.ServiceEnableWebAuthn.GetBool() && config.ServicePublicURL.GetString() != ""
+}
+
+func getWebAuthn() (*webauthn.WebAuthn, error) {
+	publicURL, err := url.Parse(config.ServicePublicURL.GetString())
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := webauthn.TimeoutConfig{
+		Enforce:    true,
+		Timeout:    webAuthnTimeout,
+		TimeoutUVD: webAuthnTimeout,
+	}
+
+	return webauthn.New(&webauthn.Config{
+		RPID:          publicURL.Hostname(),
+		RPDisplayName: "Vikunja",
+		RPOrigins:     []string{publicURL.Scheme + "://" + publicURL.Host},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        timeout,
+			Registration: timeout,
+		},
+	})
+}
+
+func getWebAuthnUser(s *xorm.Session, u *User) (*webAuthnUser, error) {
+	credentials, err := GetWebAuthnCredentials(s, u)
+	if err != nil {
+		return nil, err
+	}
+	return &webAuthnUser{user: u, credentials: credentials}, nil
+}
+
+// toWebAuthnError converts errors of the webauthn library to an error which can be shown to the user.
+func toWebAuthnError(err error) error {
+	var protocolErr *protocol.Error
+	if errors.As(err, &protocolErr) {
+		return &ErrInvalidWebAuthnResponse{Reason: protocolErr.Details}
+	}
+	return err
+}
+
+// putWebAuthnSession saves the session data of a registration or login until the user responds to it.
+// The session is removed once the challenge timed out, so abandoned logins don't fill up the storage.
+func putWebAuthnSession(prefix string, session *webauthn.SessionData) error {
+	return keyvalue.PutWithExpiry(prefix+session.Challenge, session, webAuthnTimeout)
+}
+
+// getWebAuthnSession returns the session data of a registration or login started earlier.
+// Each session can only be used once.
+func getWebAuthnSession(prefix, challenge string) (*webauthn.SessionData, error) {
+	session := &webauthn.SessionData{}
+	exists, err := keyvalue.GetWithValue(prefix+challenge, session)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, &ErrInvalidWebAuthnResponse{Reason: "unknown challenge"}
+	}
+
+	err = keyvalue.Del(prefix + challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Expires.IsZero() || session.Expires.Before(time.Now()) {
+		return nil, &ErrInvalidWebAuthnResponse{Reason: "the challenge has expired"}
+	}
+
+	return session, nil
+}
+
+// GetWebAuthnCredentials returns all security keys of a user.
+func GetWebAuthnCredentials(s *xorm.Session, u *User) (credentials []*WebAuthnCredential, err error) {
+	credentials = []*WebAuthnCredential{}
+	err = s.
+		Where("user_id = ?", u.ID).
+		OrderBy("id asc").
+		Find(&credentials)
+	return
+}
+
+// WebAuthnEnabledForUser checks if a user registered any security keys.
+func WebAuthnEnabledForUser(s *xorm.Session, u *User) (bool, error) {
+	if !WebAuthnEnabled() {
+		return false, nil
+	}
+	return s.Where("user_id = ?", u.ID).Exist(&WebAuthnCredential{})
+}
+
+// BeginWebAuthnRegistration starts registering a new security key and returns the options which need to be
+// passed to navigator.credentials.create().
+func BeginWebAuthnRegistration(s *xorm.Session, u *User) (creation *protocol.CredentialCreation, err error) {
+	wa, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	wu, err := getWebAuthnUser(s, u)
+	if err != nil {
+		return nil, err
+	}
+
+	creation, session, err := wa.BeginRegistration(
+		wu,
+		webauthn.WithExclusions(wu.credentialDescriptors()),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = putWebAuthnSession(webAuthnRegistrationKeyPrefix, session)
+	return creation, err
+}
+
+// FinishWebAuthnRegistration verifies the response of a security key to a registration started with
+// BeginWebAuthnRegistration and saves the new credential.
+func FinishWebAuthnRegistration(s *xorm.Session, u *User, registration *WebAuthnRegistration) (credential *WebAuthnCredential, err error) {
+	if registration.Name == "" {
+		registration.Name = "Security key"
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(registration.Credential))
+	if err != nil {
+		return nil, toWebAuthnError(err)
+	}
+
+	session, err := getWebAuthnSession(webAuthnRegistrationKeyPrefix, parsed.Response.CollectedClientData.Challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	wa, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	wu, err := getWebAuthnUser(s, u)
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := wa.CreateCredential(wu, *session, parsed)
+	if err != nil {
+		return nil, toWebAuthnError(err)
+	}
+
+	credential = &WebAuthnCredential{
+		UserID:          u.ID,
+		Name:            registration.Name,
+		CredentialID:    cred.ID,
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		Transports:      cred.Transport,
+		AAGUID:          cred.Authenticator.AAGUID,
+		SignCount:       cred.Authenticator.SignCount,
+		BackupEligible:  cred.Flags.BackupEligible,
+		BackupState:     cred.Flags.BackupState,
+	}
+	_, err = s.Insert(credential)
+	return credential, err
+}
+
+// BeginWebAuthnLogin starts confirming the login of a user with one of their security keys as a second factor.
+// The returned options need to be passed to navigator.credentials.get().
+func BeginWebAuthnLogin(s *xorm.Session, u *User) (assertion *protocol.CredentialAssertion, err error) {
+	wa, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	wu, err := getWebAuthnUser(s, u)
+	if err != nil {
+		return nil, err
+	}
+	if len(wu.credentials) == 0 {
+		return nil, &ErrWebAuthnCredentialDoesNotExist{}
+	}
+
+	assertion, session, err := wa.BeginLogin(wu)
+	if err != nil {
+		return nil, err
+	}
+
+	err = putWebAuthnSession(webAuthnLoginKeyPrefix, session)
+	return assertion, err
+}
+
+// BeginPasskeyLogin starts a login without a password. The security key decides which user logs in, which only
+// works with passkeys - credentials which are stored on the security key together with the user.
+func BeginPasskeyLogin() (assertion *protocol.CredentialAssertion, err error) {
+	wa, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	assertion, session, err := wa.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		return nil, err
+	}
+
+	err = putWebAuthnSession(webAuthnLoginKeyPrefix, session)
+	return assertion, err
+}
+
+// ValidateWebAuthnLogin verifies the response of a security key to a login started with BeginWebAuthnLogin.
+// The response is the PublicKeyCredential returned by navigator.credentials.get().
+func ValidateWebAuthnLogin(s *xorm.Session, u *User, response json.RawMessage) (err error) {
+	parsed, session, err := parseWebAuthnLoginResponse(response)
+	if err != nil {
+		return err
+	}
+
+	wa, err := getWebAuthn()
+	if err != nil {
+		return err
+	}
+
+	wu, err := getWebAuthnUser(s, u)
+	if err != nil {
+		return err
+	}
+
+	cred, err := wa.ValidateLogin(wu, *session, parsed)
+	if err != nil {
+		return toWebAuthnError(err)
+	}
+
+	return updateWebAuthnCredentialAfterLogin(s, u, cred)
+}
+
+// ValidatePasskeyLogin verifies the response of a security key to a login started with BeginPasskeyLogin and returns
+// the user the passkey belongs to.
+func ValidatePasskeyLogin(s *xorm.Session, response json.RawMessage) (u *User, err error) {
+	parsed, session, err := parseWebAuthnLoginResponse(response)
+	if err != nil {
+		return nil, err
+	}
+
+	wa, err := getWebAuthn()
+	if err != nil {
+		return nil, err
+	}
+
+	cred, err := wa.ValidateDiscoverableLogin(func(_, userHandle []byte) (webauthn.User, error) {
+		userID, err := strconv.ParseInt(string(userHandle), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		u, err = GetUserByID(s, userID)
+		if err != nil {
+			return nil, err
+		}
+		return getWebAuthnUser(s, u)
+	}, *session, parsed)
+	if err != nil {
+		return nil, toWebAuthnError(err)
+	}
+
+	if u.Status == StatusEmailConfirmationRequired {
+		return nil, ErrEmailNotConfirmed{UserID: u.ID}
+	}
+
+	return u, updateWebAuthnCredentialAfterLogin(s, u, cred)
+}
+
+func parseWebAuthnLoginResponse(response json.RawMessage) (parsed *protocol.ParsedCredentialAssertionData, session *webauthn.SessionData, err error) {
+	parsed, err = protocol.ParseCredentialRequestResponseBody(bytes.NewReader(response))
+	if err != nil {
+		return nil, nil, toWebAuthnError(err)
+	}
+
+	session, err = getWebAuthnSession(webAuthnLoginKeyPrefix, parsed.Response.CollectedClientData.Challenge)
+	return
+}
+
+// updateWebAuthnCredentialAfterLogin saves the new signature counter of a credential used to log in.
+func updateWebAuthnCredentialAfterLogin(s *xorm.Session, u *User, cred *webauthn.Credential) (err error) {
+	credentials, err := GetWebAuthnCredentials(s, u)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, c := range credentials {
+		if !bytes.Equal(c.CredentialID, cred.ID) {
+			continue
+		}
+
+		c.SignCount = cred.Authenticator.SignCount
+		c.CloneWarning = cred.Authenticator.CloneWarning
+		c.BackupState = cred.Flags.BackupState
+		c.LastUsed = &now
+		_, err = s.
+			Where("id = ?", c.ID).
+			Cols("sign_count", "clone_warning", "backup_state", "last_used").
+			Update(c)
+		return err
+	}
+
+	return &ErrWebAuthnCredentialDoesNotExist{}
+}
+
+// GetWebAuthnCredentialByID returns a security key of a user.
+func GetWebAuthnCredentialByID(s *xorm.Session, u *User, id int64) (credential *WebAuthnCredential, err error) {
+	credential = &WebAuthnCredential{}
+	has, err := s.Where("id = ? AND user_id = ?", id, u.ID).Get(credential)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, &ErrWebAuthnCredentialDoesNotExist{CredentialID: id}
+	}
+	return credential, nil
+}
+
+// RenameWebAuthnCredential changes the name of a security key.
+func RenameWebAuthnCredential(s *xorm.Session, u *User, credential *WebAuthnCredential) (err error) {
+	existing, err := GetWebAuthnCredentialByID(s, u, credential.ID)
+	if err != nil {
+		return err
+	}
+
+	existing.Name = credential.Name
+	_, err = s.
+		Where("id = ?", existing.ID).
+		Cols("name").
+		Update(existing)
+	if err != nil {
+		return err
+	}
+
+	*credential = *existing
+	return nil
+}
+
+// DeleteWebAuthnCredential removes a security key of a user and returns it.
+func DeleteWebAuthnCredential(s *xorm.Session, u *User, id int64) (credential *WebAuthnCredential, err error) {
+	credential, err = GetWebAuthnCredentialByID(s, u, id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.Where("id = ?", id).Delete(&WebAuthnCredential{})
+	return credential, err
+}
@@ -0,0 +1,341 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package user
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"code.vikunja.io/api/pkg/config"
+	"code.vikunja.io/api/pkg/db"
+	"code.vikunja.io/api/pkg/modules/keyvalue"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testWebAuthnOrigin = "https://vikunja.example.com"
+
+// softwareAuthenticator is a security key which only exists in memory to test the webauthn flows.
+type softwareAuthenticator struct {
+	t            *testing.T
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+}
+
+func newSoftwareAuthenticator(t *testing.T) *softwareAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+
+	return &softwareAuthenticator{t: t, key: key, credentialID: credentialID}
+}
+
+func (a *softwareAuthenticator) clientData(typ string, challenge protocol.URLEncodedBase64) []byte {
+	clientData, err := json.Marshal(map[string]string{
+		"type":      typ,
+		"challenge": base64.RawURLEncoding.EncodeToString(challenge),
+		"origin":    testWebAuthnOrigin,
+	})
+	require.NoError(a.t, err)
+	return clientData
+}
+
+func (a *softwareAuthenticator) authenticatorData(flags protocol.AuthenticatorFlags, attestedCredentialData []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte("vikunja.example.com"))
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, byte(flags))
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	return append(data, attestedCredentialData...)
+}
+
+// create returns the response of navigator.credentials.create() for the given options.
+func (a *softwareAuthenticator) create(options *protocol.CredentialCreation) json.RawMessage {
+	a.userHandle = options.Response.User.ID.(protocol.URLEncodedBase64)
+
+	publicKey, err := webauthncbor.Marshal(map[int]interface{}{
+		1:  2,  // key type: EC2
+		3:  -7, // algorithm: ES256
+		-1: 1,  // curve: P-256
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(a.t, err)
+
+	attestedCredentialData := make([]byte, 16) // empty aaguid
+	attestedCredentialData = binary.BigEndian.AppendUint16(attestedCredentialData, uint16(len(a.credentialID)))
+	attestedCredentialData = append(attestedCredentialData, a.credentialID...)
+	attestedCredentialData = append(attestedCredentialData, publicKey...)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authenticatorData(protocol.FlagUserPresent|protocol.FlagUserVerified|protocol.FlagAttestedCredentialData, attestedCredentialData),
+	})
+	require.NoError(a.t, err)
+
+	return a.marshalResponse(map[string]interface{}{
+		"clientDataJSON":    protocol.URLEncodedBase64(a.clientData("webauthn.create", options.Response.Challenge)),
+		"attestationObject": protocol.URLEncodedBase64(attestationObject),
+	})
+}
+
+// get returns the response of navigator.credentials.get() for the given options.
+func (a *softwareAuthenticator) get(options *protocol.CredentialAssertion) json.RawMessage {
+	a.signCount++
+	clientData := a.clientData("webauthn.get", options.Response.Challenge)
+	authenticatorData := a.authenticatorData(protocol.FlagUserPresent|protocol.FlagUserVerified, nil)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authenticatorData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(a.t, err)
+
+	return a.marshalResponse(map[string]interface{}{
+		"clientDataJSON":    protocol.URLEncodedBase64(clientData),
+		"authenticatorData": protocol.URLEncodedBase64(authenticatorData),
+		"signature":         protocol.URLEncodedBase64(signature),
+		"userHandle":        protocol.URLEncodedBase64(a.userHandle),
+	})
+}
+
+func (a *softwareAuthenticator) marshalResponse(response map[string]interface{}) json.RawMessage {
+	raw, err := json.Marshal(map[string]interface{}{
+		"id":       base64.RawURLEncoding.EncodeToString(a.credentialID),
+		"rawId":    protocol.URLEncodedBase64(a.credentialID),
+		"type":     "public-key",
+		"response": response,
+	})
+	require.NoError(a.t, err)
+	return raw
+}
+
+func setupWebAuthnConfig(t *testing.T) {
+	config.ServiceEnableWebAuthn.Set(true)
+	config.ServicePublicURL.Set(testWebAuthnOrigin + "/")
+	t.Cleanup(func() {
+		config.ServicePublicURL.Set("")
+	})
+}
+
+// registerSoftwareAuthenticator registers a new software authenticator for a user.
+func registerSoftwareAuthenticator(t *testing.T, u *User) (*softwareAuthenticator, *WebAuthnCredential) {
+	s := db.NewSession()
+	defer s.Close()
+
+	authenticator := newSoftwareAuthenticator(t)
+	options, err := BeginWebAuthnRegistration(s, u)
+	require.NoError(t, err)
+	credential, err := FinishWebAuthnRegistration(s, u, &WebAuthnRegistration{
+		Name:       "My key",
+		Credential: authenticator.create(options),
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Commit())
+
+	return authenticator, credential
+}
+
+func TestWebAuthnRegistration(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupWebAuthnConfig(t)
+		u := &User{ID: 1, Username: "user1"}
+
+		_, credential := registerSoftwareAuthenticator(t, u)
+		assert.Equal(t, "My key", credential.Name)
+		db.AssertExists(t, "user_webauthn_credentials", map[string]interface{}{
+			"id":      credential.ID,
+			"user_id": 1,
+			"name":    "My key",
+		}, false)
+
+		s := db.NewSession()
+		defer s.Close()
+		enabled, err := WebAuthnEnabledForUser(s, u)
+		require.NoError(t, err)
+		assert.True(t, enabled)
+	})
+	t.Run("challenge can only be used once", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupWebAuthnConfig(t)
+		s := db.NewSession()
+		defer s.Close()
+		u := &User{ID: 1, Username: "user1"}
+
+		authenticator := newSoftwareAuthenticator(t)
+		options, err := BeginWebAuthnRegistration(s, u)
+		require.NoError(t, err)
+		response := authenticator.create(options)
+		_, err = FinishWebAuthnRegistration(s, u, &WebAuthnRegistration{Credential: response})
+		require.NoError(t, err)
+
+		_, err = FinishWebAuthnRegistration(s, u, &WebAuthnRegistration{Credential: response})
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidWebAuthnResponse(err))
+	})
+	t.Run("invalid response", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupWebAuthnConfig(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := FinishWebAuthnRegistration(s, &User{ID: 1}, &WebAuthnRegistration{Credential: json.RawMessage(`{}`)})
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidWebAuthnResponse(err))
+	})
+}
+
+func TestWebAuthnLogin(t *testing.T) {
+	t.Run("second factor", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupWebAuthnConfig(t)
+		u := &User{ID: 1, Username: "user1"}
+		authenticator, credential := registerSoftwareAuthenticator(t, u)
+
+		s := db.NewSession()
+		defer s.Close()
+		options, err := BeginWebAuthnLogin(s, u)
+		require.NoError(t, err)
+		err = ValidateWebAuthnLogin(s, u, authenticator.get(options))
+		require.NoError(t, err)
+		require.NoError(t, s.Commit())
+
+		updated, err := GetWebAuthnCredentialByID(s, u, credential.ID)
+		require.NoError(t, err)
+		assert.Equal(t, uint32(1), updated.SignCount)
+		assert.NotNil(t, updated.LastUsed)
+	})
+	t.Run("key of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupWebAuthnConfig(t)
+		authenticator, _ := registerSoftwareAuthenticator(t, &User{ID: 1, Username: "user1"})
+		u2 := &User{ID: 2, Username: "user2"}
+		registerSoftwareAuthenticator(t, u2)
+
+		s := db.NewSession()
+		defer s.Close()
+		options, err := BeginWebAuthnLogin(s, u2)
+		require.NoError(t, err)
+		err = ValidateWebAuthnLogin(s, u2, authenticator.get(options))
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidWebAuthnResponse(err))
+	})
+	t.Run("no keys registered", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupWebAuthnConfig(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := BeginWebAuthnLogin(s, &User{ID: 1, Username: "user1"})
+		require.Error(t, err)
+		assert.True(t, IsErrWebAuthnCredentialDoesNotExist(err))
+	})
+	t.Run("passkey", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupWebAuthnConfig(t)
+		authenticator, _ := registerSoftwareAuthenticator(t, &User{ID: 1, Username: "user1"})
+
+		s := db.NewSession()
+		defer s.Close()
+		options, err := BeginPasskeyLogin()
+		require.NoError(t, err)
+		assert.Empty(t, options.Response.AllowedCredentials)
+		u, err := ValidatePasskeyLogin(s, authenticator.get(options))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), u.ID)
+	})
+	t.Run("replayed response", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupWebAuthnConfig(t)
+		authenticator, _ := registerSoftwareAuthenticator(t, &User{ID: 1, Username: "user1"})
+
+		s := db.NewSession()
+		defer s.Close()
+		options, err := BeginPasskeyLogin()
+		require.NoError(t, err)
+		response := authenticator.get(options)
+		_, err = ValidatePasskeyLogin(s, response)
+		require.NoError(t, err)
+
+		_, err = ValidatePasskeyLogin(s, response)
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidWebAuthnResponse(err))
+	})
+	t.Run("expired challenge", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		setupWebAuthnConfig(t)
+		authenticator, _ := registerSoftwareAuthenticator(t, &User{ID: 1, Username: "user1"})
+
+		s := db.NewSession()
+		defer s.Close()
+		options, err := BeginPasskeyLogin()
+		require.NoError(t, err)
+
+		key := webAuthnLoginKeyPrefix + options.Response.Challenge.String()
+		session := &webauthn.SessionData{}
+		exists, err := keyvalue.GetWithValue(key, session)
+		require.NoError(t, err)
+		require.True(t, exists)
+		assert.WithinDuration(t, time.Now().Add(webAuthnTimeout), session.Expires, time.Minute)
+
+		session.Expires = time.Now().Add(-time.Second)
+		require.NoError(t, keyvalue.Put(key, session))
+
+		_, err = ValidatePasskeyLogin(s, authenticator.get(options))
+		require.Error(t, err)
+		assert.True(t, IsErrInvalidWebAuthnResponse(err))
+	})
+}
+
+func TestDeleteWebAuthnCredential(t *testing.T) {
+	t.Run("normal", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		credential, err := DeleteWebAuthnCredential(s, &User{ID: 15}, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "Test key", credential.Name)
+		db.AssertMissing(t, "user_webauthn_credentials", map[string]interface{}{
+			"id": 1,
+		})
+	})
+	t.Run("credential of another user", func(t *testing.T) {
+		db.LoadAndAssertFixtures(t)
+		s := db.NewSession()
+		defer s.Close()
+
+		_, err := DeleteWebAuthnCredential(s, &User{ID: 1}, 1)
+		require.Error(t, err)
+		assert.True(t, IsErrWebAuthnCredentialDoesNotExist(err))
+	})
+}
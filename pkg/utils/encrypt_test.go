@@ -0,0 +1,44 @@
+// Vikunja is a to-do list application to facilitate your life.
+// Copyright 2018-present Vikunja and contributors. All rights reserved.
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Affero General Public Licensee as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU Affero General Public Licensee for more details.
+//
+// You should have received a copy of the GNU Affero General Public Licensee
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptString(t *testing.T) {
+	encrypted, err := EncryptString("secret", "password")
+	require.NoError(t, err)
+	assert.NotContains(t, encrypted, "password")
+
+	again, err := EncryptString("secret", "password")
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted, again)
+
+	decrypted, err := DecryptString("secret", encrypted)
+	require.NoError(t, err)
+	assert.Equal(t, "password", decrypted)
+
+	_, err = DecryptString("other secret", encrypted)
+	require.ErrorIs(t, err, ErrCannotDecrypt)
+
+	_, err = DecryptString("secret", "password")
+	require.ErrorIs(t, err, ErrCannotDecrypt)
+}